@@ -0,0 +1,10 @@
+// the init of the firecracker executor's microvms, build it static with
+// CGO_ENABLED=0 go build ./cmd/firecracker-init and point
+// FIRECRACKER_INIT at it
+package main
+
+import "github.com/lilypad-tech/lilypad/pkg/executor/firecracker/guest"
+
+func main() {
+	guest.Run()
+}
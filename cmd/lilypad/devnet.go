@@ -0,0 +1,77 @@
+package lilypad
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lilypad-tech/lilypad/pkg/devnet"
+	optionsfactory "github.com/lilypad-tech/lilypad/pkg/options"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+type devnetOptions struct {
+	devnet devnet.Options
+	name   string
+}
+
+func newDevnetCmd() *cobra.Command {
+	devnetCmd := &cobra.Command{
+		Use:   "devnet",
+		Short: "Run a local network to develop against",
+		Long:  "Run a local network to develop against.",
+	}
+	devnetCmd.AddCommand(newDevnetUpCmd())
+	return devnetCmd
+}
+
+func newDevnetUpCmd() *cobra.Command {
+	options := devnetOptions{devnet: devnet.DefaultOptions()}
+	if adminKey := os.Getenv("ADMIN_PRIVATE_KEY"); adminKey != "" {
+		options.devnet.AdminKey = adminKey
+	}
+	upCmd := &cobra.Command{
+		Use:   "up",
+		Short: "Deploy the contracts to a local chain and write a network profile for them",
+		Long: fmt.Sprintf(`Deploy the contracts to a local chain, fund the test accounts with ether and tokens and write a network profile to %s.
+
+The chain has to be running already, ./stack chain-boot or docker compose will start one.`, optionsfactory.NetworksDir()),
+		Example: "lilypad devnet up\nlilypad --network local solver",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDevnetUp(cmd, options)
+		},
+	}
+	upCmd.Flags().StringVar(&options.devnet.RpcURL, "rpc-url", options.devnet.RpcURL, "The rpc url of the local chain")
+	upCmd.Flags().StringVar(&options.devnet.AdminKey, "admin-key", options.devnet.AdminKey, "The private key the contracts are deployed and the accounts funded from (ADMIN_PRIVATE_KEY).")
+	upCmd.Flags().StringVar(&options.name, "name", "local", "The name of the network profile to write")
+	upCmd.Flags().Float64Var(&options.devnet.Ether, "ether", options.devnet.Ether, "The ether to send each test account")
+	upCmd.Flags().Float64Var(&options.devnet.Tokens, "tokens", options.devnet.Tokens, "The tokens to send each test account")
+	return upCmd
+}
+
+func runDevnetUp(cmd *cobra.Command, options devnetOptions) error {
+	if strings.ContainsAny(options.name, `/\`) || strings.HasSuffix(options.name, ".toml") {
+		return fmt.Errorf("the network name %s should be a name rather than a path", options.name)
+	}
+	dir := optionsfactory.NetworksDir()
+	if dir == "" {
+		return fmt.Errorf("no directory to write network profiles to, set LILYPAD_NETWORKS_DIR")
+	}
+
+	commandCtx := system.NewCommandContext(cmd)
+	defer commandCtx.Cleanup()
+
+	deployment, err := devnet.Up(commandCtx.Ctx, options.devnet)
+	if err != nil {
+		return err
+	}
+	path, err := deployment.WriteProfile(dir, options.name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote the network profile to %s\n", path)
+	fmt.Printf("lilypad --network %s is ready to use\n", options.name)
+	return nil
+}
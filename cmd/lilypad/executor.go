@@ -0,0 +1,28 @@
+package lilypad
+
+import (
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
+	"github.com/lilypad-tech/lilypad/pkg/executor/container"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker"
+	"github.com/lilypad-tech/lilypad/pkg/executor/kubernetes"
+)
+
+func getExecutor(executorType string, bacalhauOptions bacalhau.BacalhauExecutorOptions, containerOptions container.ContainerExecutorOptions, kubernetesOptions kubernetes.KubernetesExecutorOptions, firecrackerOptions firecracker.FirecrackerExecutorOptions) (executor.Executor, error) {
+	switch executorType {
+	case "bacalhau":
+		return bacalhau.NewBacalhauExecutor(bacalhauOptions)
+	case "podman":
+		return container.NewPodmanExecutor(containerOptions)
+	case "containerd":
+		return container.NewContainerdExecutor(containerOptions)
+	case "kubernetes":
+		return kubernetes.NewKubernetesExecutor(kubernetesOptions)
+	case "firecracker":
+		return firecracker.NewFirecrackerExecutor(firecrackerOptions)
+	default:
+		return nil, fmt.Errorf("expected executor type bacalhau, podman, containerd, kubernetes or firecracker, but received: %s", executorType)
+	}
+}
@@ -3,6 +3,7 @@ package lilypad
 import (
 	"github.com/lilypad-tech/lilypad/pkg/jobcreator"
 	optionsfactory "github.com/lilypad-tech/lilypad/pkg/options"
+	"github.com/lilypad-tech/lilypad/pkg/signatures"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
 	"github.com/rs/zerolog/log"
@@ -48,6 +49,12 @@ func runJobCreator(cmd *cobra.Command, options jobcreator.JobCreatorOptions, net
 	if err != nil {
 		return err
 	}
+	meter := telemetry.MeterProvider.Meter(system.GetOTelServiceName(system.JobCreatorService))
+	if err := web3SDK.RegisterMetrics(meter); err != nil {
+		log.Warn().Msgf("failed to start web3 metrics: %s", err)
+	}
+	// smart contract wallets sign requests through EIP-1271
+	signatures.SetDefault(signatures.NewVerifier(web3SDK.Client))
 
 	// create the job creator and start it's control loop
 	jobCreatorService, err := jobcreator.NewOnChainJobCreator(options, web3SDK, tracer)
@@ -1,7 +1,6 @@
 package lilypad
 
 import (
-	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
 	"github.com/lilypad-tech/lilypad/pkg/mediator"
 	optionsfactory "github.com/lilypad-tech/lilypad/pkg/options"
 	"github.com/lilypad-tech/lilypad/pkg/system"
@@ -46,7 +45,7 @@ func runMediator(cmd *cobra.Command, options mediator.MediatorOptions) error {
 	}
 
 
-	executor, err := bacalhau.NewBacalhauExecutor(options.Bacalhau)
+	executor, err := getExecutor(options.Executor, options.Bacalhau, options.Container, options.Kubernetes, options.Firecracker)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,57 @@
+package lilypad
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	optionsfactory "github.com/lilypad-tech/lilypad/pkg/options"
+)
+
+func newNetworksCmd() *cobra.Command {
+	networksCmd := &cobra.Command{
+		Use:     "networks",
+		Short:   "List the network profiles",
+		Long:    fmt.Sprintf("List the network profiles --network can be set to, custom profiles are read from %s (LILYPAD_NETWORKS_DIR).", optionsfactory.NetworksDir()),
+		Example: "lilypad networks",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runNetworks()
+		},
+	}
+	return networksCmd
+}
+
+func runNetworks() error {
+	networks, err := optionsfactory.ListNetworks()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCHAIN ID\tTOKEN\tRPC\tSOURCE\tDESCRIPTION")
+	for _, network := range networks {
+		config := network.Config
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			network.Name,
+			config.Web3.ChainID,
+			config.Token.Symbol,
+			rpcSummary(config.Web3.RpcURLList()),
+			network.Source,
+			config.Network.Description,
+		)
+	}
+	return w.Flush()
+}
+
+// the first url and how many fallbacks there are after it
+func rpcSummary(urls []string) string {
+	switch len(urls) {
+	case 0:
+		return ""
+	case 1:
+		return urls[0]
+	default:
+		return fmt.Sprintf("%s (+%d)", urls[0], len(urls)-1)
+	}
+}
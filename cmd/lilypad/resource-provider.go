@@ -1,7 +1,11 @@
 package lilypad
 
 import (
-	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
 	optionsfactory "github.com/lilypad-tech/lilypad/pkg/options"
 	"github.com/lilypad-tech/lilypad/pkg/resourceprovider"
 	"github.com/lilypad-tech/lilypad/pkg/system"
@@ -21,6 +25,9 @@ func newResourceProviderCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 
 			network, _ := cmd.Flags().GetString("network")
+			if cmd.Flags().Changed("offer-gpu") {
+				options.Offers.GPUSet = true
+			}
 			options, err := optionsfactory.ProcessResourceProviderOptions(options, network)
 			if err != nil {
 				return err
@@ -30,10 +37,77 @@ func newResourceProviderCmd() *cobra.Command {
 	}
 
 	optionsfactory.AddResourceProviderCliFlags(resourceProviderCmd, &options)
+	resourceProviderCmd.AddCommand(newResourceProviderBenchmarkCmd(&options))
 
 	return resourceProviderCmd
 }
 
+// the benchmark takes the resource provider's flags so the prices it
+// suggests start from the ones we would offer at
+func newResourceProviderBenchmarkCmd(options *resourceprovider.ResourceProviderOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "benchmark",
+		Short: "Measure this machine and suggest what to offer.",
+		Long: `Run standard cpu, memory, disk and gpu workloads, write the results to BENCHMARK_PATH and print the offer flags they suggest.
+
+The resource prices are scaled from PRICING_CPU_HOUR, PRICING_RAM_GB_HOUR and PRICING_GPU_HOUR by how this machine compares to a reference one. Start the resource provider with OFFER_USE_BENCHMARK=true to offer them.`,
+		Example: "lilypad resource-provider benchmark --pricing-gpu-hour 100",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := optionsfactory.CheckResourceProviderBenchmarkOptions(options.Benchmark); err != nil {
+				return err
+			}
+			return runResourceProviderBenchmark(cmd, *options)
+		},
+	}
+}
+
+func runResourceProviderBenchmark(cmd *cobra.Command, options resourceprovider.ResourceProviderOptions) error {
+	commandCtx := system.NewCommandContext(cmd)
+	defer commandCtx.Cleanup()
+
+	fmt.Printf("benchmarking for about %ds a resource, the gpus take longer\n", options.Benchmark.Duration)
+	result, err := resourceprovider.RunBenchmark(commandCtx.Ctx, options.Benchmark)
+	if err != nil {
+		return err
+	}
+	if err := resourceprovider.SaveBenchmark(options.Benchmark.Path, result); err != nil {
+		return fmt.Errorf("error writing the benchmark to %s: %w", options.Benchmark.Path, err)
+	}
+
+	fmt.Printf("cpu:  %d cpus at %.2f million pow hashes/s\n", result.CPUs, result.CPU)
+	fmt.Printf("ram:  %d MB free, %.2f GB/s copied\n", result.RAM, result.RAMGB)
+	fmt.Printf("disk: %.0f MB/s written\n", result.Disk)
+	for _, gpu := range result.GPUs {
+		fmt.Printf("gpu:  %s with %d MB at %.0f GFLOP/s\n", gpu.Name, gpu.VRAM, gpu.GFLOPS)
+	}
+	for resource, reason := range result.Skipped {
+		fmt.Printf("skipped %s: %s\n", resource, reason)
+	}
+
+	suggestion := result.Suggest(options.Offers.ResourcePricing)
+	fmt.Printf("\nsuggested offer, written to %s for OFFER_USE_BENCHMARK:\n", options.Benchmark.Path)
+	fmt.Printf("OFFER_CPU=%d\n", suggestion.CPU)
+	if suggestion.GPU > 0 {
+		fmt.Printf("OFFER_GPU=%d\n", suggestion.GPU)
+	}
+	if suggestion.RAM > 0 {
+		fmt.Printf("OFFER_RAM=%d\n", suggestion.RAM)
+	}
+	attributes := []string{}
+	for name, value := range suggestion.Attributes {
+		attributes = append(attributes, name+"="+value)
+	}
+	sort.Strings(attributes)
+	fmt.Printf("OFFER_ATTRIBUTES=%s\n", strings.Join(attributes, ","))
+	fmt.Printf("PRICING_GPU_HOUR=%d\n", suggestion.ResourcePricing.GPUHour)
+	fmt.Printf("PRICING_CPU_HOUR=%d\n", suggestion.ResourcePricing.CPUHour)
+	fmt.Printf("PRICING_RAM_GB_HOUR=%d\n", suggestion.ResourcePricing.RAMGBHour)
+	if suggestion.ResourcePricing == (data.ResourcePricing{}) {
+		fmt.Println("set PRICING_GPU_HOUR, PRICING_CPU_HOUR or PRICING_RAM_GB_HOUR for the reference machine to have them scaled")
+	}
+	return nil
+}
+
 func runResourceProvider(cmd *cobra.Command, options resourceprovider.ResourceProviderOptions, network string) error {
 	commandCtx := system.NewCommandContext(cmd)
 	defer commandCtx.Cleanup()
@@ -49,8 +123,12 @@ func runResourceProvider(cmd *cobra.Command, options resourceprovider.ResourcePr
 	if err != nil {
 		return err
 	}
+	meter := telemetry.MeterProvider.Meter(system.GetOTelServiceName(system.ResourceProviderService))
+	if err := web3SDK.RegisterMetrics(meter); err != nil {
+		log.Warn().Msgf("failed to start web3 metrics: %s", err)
+	}
 
-	executor, err := bacalhau.NewBacalhauExecutor(options.Bacalhau)
+	executor, err := getExecutor(options.Executor, options.Bacalhau, options.Container, options.Kubernetes, options.Firecracker)
 	if err != nil {
 		return err
 	}
@@ -67,11 +145,15 @@ func runResourceProvider(cmd *cobra.Command, options resourceprovider.ResourcePr
 	}
 
 	resourecProviderErrors := resourceProviderService.Start(commandCtx.Ctx, commandCtx.Cm)
+	handleResourceProviderSignals(commandCtx.Ctx, resourceProviderService)
 	for {
 		select {
 		case err := <-resourecProviderErrors:
 			commandCtx.Cleanup()
 			return err
+		case <-resourceProviderService.Drained():
+			log.Info().Msgf("resource provider drained, stopping")
+			return nil
 		case <-commandCtx.Ctx.Done():
 			return nil
 		}
@@ -0,0 +1,47 @@
+//go:build !windows
+
+package lilypad
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lilypad-tech/lilypad/pkg/resourceprovider"
+	"github.com/rs/zerolog/log"
+)
+
+// SIGUSR1 drains the resource provider so it stops once its deals are
+// done and SIGUSR2 pauses it, another SIGUSR2 resumes it or calls off the
+// drain
+func handleResourceProviderSignals(ctx context.Context, resourceProvider *resourceprovider.ResourceProvider) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(signals)
+		pausedBySignal := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-signals:
+				var err error
+				switch {
+				case sig == syscall.SIGUSR1:
+					err = resourceProvider.Drain()
+					pausedBySignal = err == nil
+				case pausedBySignal:
+					resourceProvider.Resume()
+					pausedBySignal = false
+				default:
+					err = resourceProvider.Pause()
+					pausedBySignal = err == nil
+				}
+				if err != nil {
+					log.Error().Err(err).Msgf("error handling %s", sig)
+				}
+			}
+		}
+	}()
+}
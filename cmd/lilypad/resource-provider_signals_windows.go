@@ -0,0 +1,11 @@
+package lilypad
+
+import (
+	"context"
+
+	"github.com/lilypad-tech/lilypad/pkg/resourceprovider"
+)
+
+// there is no SIGUSR1 or SIGUSR2 to drain or pause with on windows
+func handleResourceProviderSignals(ctx context.Context, resourceProvider *resourceprovider.ResourceProvider) {
+}
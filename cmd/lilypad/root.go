@@ -24,7 +24,7 @@ func NewRootCmd() *cobra.Command {
 	}
 
 	var network string
-	RootCmd.PersistentFlags().StringVarP(&network, "network", "n", "testnet", "Sets a target network configuration")
+	RootCmd.PersistentFlags().StringVarP(&network, "network", "n", "testnet", "Sets a target network configuration, a profile name from `lilypad networks` or a path to a toml file")
 
 	RootCmd.AddCommand(newSolverCmd())
 	RootCmd.AddCommand(newResourceProviderCmd())
@@ -33,6 +33,9 @@ func NewRootCmd() *cobra.Command {
 	RootCmd.AddCommand(newMediatorCmd())
 	RootCmd.AddCommand(newJobCreatorCmd())
 	RootCmd.AddCommand(newVersionCmd())
+	RootCmd.AddCommand(newNetworksCmd())
+	RootCmd.AddCommand(newTransactionsCmd())
+	RootCmd.AddCommand(newDevnetCmd())
 	return RootCmd
 }
 
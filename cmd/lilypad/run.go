@@ -16,6 +16,7 @@ import (
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/theckman/yacspin"
 )
@@ -92,6 +93,10 @@ func runJob(cmd *cobra.Command, options jobcreator.JobCreatorOptions, network st
 	commandCtx.Cm.RegisterCallbackWithContext(telemetry.Shutdown)
 	tracer := telemetry.TracerProvider.Tracer(system.GetOTelServiceName(system.JobCreatorService))
 
+	if options.Offer.Schedule != "" {
+		return runRecurringJob(commandCtx, options, tracer)
+	}
+
 	result, err := jobcreator.RunJob(commandCtx, options, tracer, func(evOffer data.JobOfferContainer) {
 		spinner.Stop()
 		st := data.GetAgreementStateString(evOffer.State)
@@ -141,6 +146,7 @@ func runJob(cmd *cobra.Command, options jobcreator.JobCreatorOptions, network st
 	}
 	spinner.Stop()
 	fmt.Printf("🆔  Data ID: %s\n", result.Result.DataID)
+	printUsage(result.Result.Usage)
 	fmt.Printf("\n🍂 Lilypad job completed, try 👇\n    open %s\n    cat %s/stdout\n    cat %s/stderr\n",
 		solver.GetDownloadsFilePath(result.JobOffer.DealID),
 		solver.GetDownloadsFilePath(result.JobOffer.DealID),
@@ -149,6 +155,22 @@ func runJob(cmd *cobra.Command, options jobcreator.JobCreatorOptions, network st
 	return err
 }
 
+// recurring jobs run until interrupted so print a line per update instead of a spinner
+func runRecurringJob(commandCtx *system.CommandContext, options jobcreator.JobCreatorOptions, tracer trace.Tracer) error {
+	fmt.Printf("🔁 Running job on schedule %q, press Ctrl+C to stop\n", options.Offer.Schedule)
+	return jobcreator.RunRecurringJob(commandCtx, options, tracer, func(evOffer data.JobOfferContainer) {
+		fmt.Printf("🌟 %s %s\n", evOffer.ID, data.GetAgreementStateString(evOffer.State))
+	}, func(result *jobcreator.RunJobResults, err error) {
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return
+		}
+		fmt.Printf("🆔  Data ID: %s\n", result.Result.DataID)
+		printUsage(result.Result.Usage)
+		fmt.Printf("🍂 Run completed, see %s\n", solver.GetDownloadsFilePath(result.JobOffer.DealID))
+	})
+}
+
 func createSpinner(message string, emoji string) (*yacspin.Spinner, error) {
 	// build the configuration, each field is documented
 	cfg := yacspin.Config{
@@ -192,3 +214,15 @@ func stopOnSignal(spinner *yacspin.Spinner) {
 		os.Exit(0)
 	}()
 }
+
+// what the resource provider says the job used, older ones do not say
+func printUsage(usage *data.ResourceUsage) {
+	if usage == nil {
+		return
+	}
+	line := fmt.Sprintf("📊 Usage: %.1fs cpu, %dMB peak memory", usage.CPUTime, usage.PeakMemory)
+	if usage.GPUUtilization > 0 {
+		line += fmt.Sprintf(", %.0f%% gpu", usage.GPUUtilization)
+	}
+	fmt.Printf("%s, ran for %.1fs\n", line, usage.Duration)
+}
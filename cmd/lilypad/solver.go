@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	optionsfactory "github.com/lilypad-tech/lilypad/pkg/options"
+	"github.com/lilypad-tech/lilypad/pkg/signatures"
 	"github.com/lilypad-tech/lilypad/pkg/solver"
 	"github.com/lilypad-tech/lilypad/pkg/solver/store"
 	db "github.com/lilypad-tech/lilypad/pkg/solver/store/db"
@@ -59,6 +60,11 @@ func runSolver(cmd *cobra.Command, options solver.SolverOptions, network string)
 	if err != nil {
 		return err
 	}
+	if err := web3SDK.RegisterMetrics(meter); err != nil {
+		log.Warn().Msgf("failed to start web3 metrics: %s", err)
+	}
+	// smart contract wallets sign requests through EIP-1271
+	signatures.SetDefault(signatures.NewVerifier(web3SDK.Client))
 
 	solverStore, err := getSolverStore(options.Store)
 	if err != nil {
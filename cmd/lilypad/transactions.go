@@ -0,0 +1,122 @@
+package lilypad
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+type transactionsOptions struct {
+	since    time.Duration
+	query    web3.JournalQuery
+	asJSON   bool
+	byMethod bool
+}
+
+func newTransactionsCmd() *cobra.Command {
+	options := transactionsOptions{}
+	transactionsCmd := &cobra.Command{
+		Use:     "transactions",
+		Short:   "List the transactions this host sent and the gas they used",
+		Long:    fmt.Sprintf("List the transactions every service on this host sent, read from the journals in %s.", web3.JournalDir()),
+		Example: "lilypad transactions --since 168h --status reverted\nlilypad transactions --by-method",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runTransactions(options)
+		},
+	}
+	transactionsCmd.Flags().DurationVar(&options.since, "since", 7*24*time.Hour, "Only list transactions sent within this long, 0 for all of them")
+	transactionsCmd.Flags().StringVar(&options.query.DealID, "deal", "", "Only list the transactions for this deal")
+	transactionsCmd.Flags().StringVar(&options.query.Method, "method", "", "Only list calls to this method e.g. controller.agree")
+	transactionsCmd.Flags().StringVar(&options.query.Status, "status", "", "Only list transactions that are pending, success, reverted or failed")
+	transactionsCmd.Flags().BoolVar(&options.asJSON, "json", false, "Print the journal entries as json")
+	transactionsCmd.Flags().BoolVar(&options.byMethod, "by-method", false, "Print the gas used by each service and contract method instead of each transaction")
+	return transactionsCmd
+}
+
+func runTransactions(options transactionsOptions) error {
+	if options.since > 0 {
+		options.query.Since = time.Now().Add(-options.since)
+	}
+	paths, err := filepath.Glob(filepath.Join(web3.JournalDir(), "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	entries := []web3.JournalEntry{}
+	for _, path := range paths {
+		journalEntries, err := web3.OpenTransactionJournal(path).Entries(options.query)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		entries = append(entries, journalEntries...)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].SentAt < entries[j].SentAt
+	})
+
+	if options.byMethod {
+		return printGasUsage(web3.SummarizeGasUsage(entries), options.asJSON)
+	}
+	if options.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SENT\tSERVICE\tMETHOD\tDEAL\tSTATUS\tGAS\tCOST (ETH)\tHASH")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			time.UnixMilli(entry.SentAt).Format(time.DateTime),
+			entry.Service,
+			entry.Method,
+			entry.DealID,
+			entry.Status,
+			entry.GasUsed,
+			web3.WeiToEther(entry.GasCost()).Text('f', 6),
+			entry.Hash,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	summary := web3.SummarizeJournal(entries)
+	fmt.Printf("\n%d transactions, %d failed, %d gas, %s ETH\n",
+		summary.Transactions,
+		summary.Failed,
+		summary.GasUsed,
+		web3.WeiToEther(summary.GasCost).Text('f', 6),
+	)
+	return nil
+}
+
+func printGasUsage(usages []web3.GasUsage, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(usages)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tMETHOD\tCONTRACT\tTXS\tREVERTED\tGAS\tAVG GAS\tMAX GAS\tCOST (ETH)")
+	for _, usage := range usages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			usage.Service,
+			usage.Method,
+			usage.Contract,
+			usage.Transactions,
+			usage.Reverted,
+			usage.GasUsed,
+			usage.AverageGasUsed(),
+			usage.MaxGasUsed,
+			web3.WeiToEther(usage.GasCost).Text('f', 6),
+		)
+	}
+	return w.Flush()
+}
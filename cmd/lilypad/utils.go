@@ -47,11 +47,11 @@ func configureTelemetry(ctx context.Context,
 	metricsOptions *system.MetricsOptions,
 	web3Options web3.Web3Options,
 ) (*system.Telemetry, error) {
-	privateKey, err := web3.ParsePrivateKey(web3Options.PrivateKey)
+	signer, err := web3.NewSigner(ctx, web3Options)
 	if err != nil {
 		return nil, err
 	}
-	address := web3.GetAddress(privateKey)
+	address := signer.Address()
 
 	tc := system.TelemetryConfig{
 		TelemetryURL:   options.URL,
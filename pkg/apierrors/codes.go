@@ -0,0 +1,98 @@
+package apierrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// a machine readable reason for an error response, clients and the cli
+// branch on these instead of matching the message text
+type Code string
+
+const (
+	OfferNotFound  Code = "OFFER_NOT_FOUND"
+	DealNotFound   Code = "DEAL_NOT_FOUND"
+	ResultNotFound Code = "RESULT_NOT_FOUND"
+	// the signer is not the party the resource belongs to
+	UnauthorizedParty Code = "UNAUTHORIZED_PARTY"
+	// the offer pricing is outside the bounds the solver accepts
+	PriceRejected Code = "PRICE_REJECTED"
+	// the job offer wants paying in a token the payments contract cannot settle in
+	UnsupportedPaymentToken Code = "UNSUPPORTED_PAYMENT_TOKEN"
+	// the EIP-712 signature is missing or was not made over this offer or result
+	InvalidTypedSignature Code = "INVALID_TYPED_SIGNATURE"
+
+	// used when nothing more specific was given, one for each status we send
+	BadRequest       Code = "BAD_REQUEST"
+	Unauthorized     Code = "UNAUTHORIZED"
+	Forbidden        Code = "FORBIDDEN"
+	NotFound         Code = "NOT_FOUND"
+	MethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	NotAcceptable    Code = "NOT_ACCEPTABLE"
+	Conflict         Code = "CONFLICT"
+	PayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	Unprocessable    Code = "UNPROCESSABLE"
+	RateLimited      Code = "RATE_LIMITED"
+	Unavailable      Code = "UNAVAILABLE"
+	Internal         Code = "INTERNAL"
+)
+
+// the body of every error response
+type ErrorEnvelope struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	// extra fields that depend on the code e.g. the id that was not found
+	Details map[string]any `json:"details,omitempty"`
+	// the X-Request-Id of the call so it can be found in the server logs
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// the code for an error status when the handler did not give one
+func ForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusNotAcceptable:
+		return NotAcceptable
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return Unprocessable
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	}
+	if status >= 400 && status < 500 {
+		return BadRequest
+	}
+	return Internal
+}
+
+// errors that carry a code, pkg/http.HTTPError is one
+type coder interface {
+	ErrorCode() Code
+}
+
+// the code of the first error in the chain that has one, empty otherwise
+func CodeOf(err error) Code {
+	var withCode coder
+	if errors.As(err, &withCode) {
+		return withCode.ErrorCode()
+	}
+	return ""
+}
+
+func Is(err error, code Code) bool {
+	return CodeOf(err) == code
+}
@@ -0,0 +1,54 @@
+//go:build unit
+
+package apierrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestForStatus(t *testing.T) {
+	cases := []struct {
+		status int
+		code   Code
+	}{
+		{http.StatusBadRequest, BadRequest},
+		{http.StatusUnauthorized, Unauthorized},
+		{http.StatusForbidden, Forbidden},
+		{http.StatusNotFound, NotFound},
+		{http.StatusMethodNotAllowed, MethodNotAllowed},
+		{http.StatusNotAcceptable, NotAcceptable},
+		{http.StatusConflict, Conflict},
+		{http.StatusRequestEntityTooLarge, PayloadTooLarge},
+		{http.StatusUnprocessableEntity, Unprocessable},
+		{http.StatusTooManyRequests, RateLimited},
+		{http.StatusServiceUnavailable, Unavailable},
+		// other client errors are the client's fault all the same
+		{http.StatusTeapot, BadRequest},
+		{http.StatusGone, BadRequest},
+		{http.StatusInternalServerError, Internal},
+		{http.StatusBadGateway, Internal},
+		{0, Internal},
+	}
+	for _, tc := range cases {
+		if code := ForStatus(tc.status); code != tc.code {
+			t.Errorf("expected %d to be %s, got %s", tc.status, tc.code, code)
+		}
+	}
+}
+
+type codedError struct{ code Code }
+
+func (err codedError) Error() string   { return string(err.code) }
+func (err codedError) ErrorCode() Code { return err.code }
+
+func TestCodeOf(t *testing.T) {
+	if code := CodeOf(fmt.Errorf("wrapped: %w", codedError{DealNotFound})); code != DealNotFound {
+		t.Errorf("expected the wrapped code, got %s", code)
+	}
+	if code := CodeOf(errors.New("plain")); code != "" {
+		t.Errorf("expected no code, got %s", code)
+	}
+}
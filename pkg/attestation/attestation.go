@@ -0,0 +1,169 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+const (
+	TypeSEVSNP = "sev-snp"
+	TypeTDX    = "tdx"
+)
+
+type AttestationOptions struct {
+	// a PEM file of the roots quotes are checked against, the AMD ARKs
+	// and the Intel SGX root CA
+	Roots string
+}
+
+// what a quote says once it is parsed, nothing in it is trusted until
+// Verify has checked the signatures
+type Report struct {
+	Type string
+	// the launch measurement of the VM image
+	Measurement []byte
+	ReportData  [64]byte
+	// the VM's memory can be read by the host
+	Debug bool
+	// the bytes the signature is over and the signature itself, the
+	// formats differ between the TEE types so they check them
+	signed []byte
+	quote  []byte
+}
+
+// the 64 bytes of report data a resource provider asks its TEE to sign,
+// they tie the quote to the address that posts the offers and to the key
+// job secrets are encrypted to so neither can be swapped out
+func ReportData(resourceProvider string, secretsKey string) [64]byte {
+	return sha512.Sum512([]byte("lilypad-attestation-v1\n" + strings.ToLower(resourceProvider) + "\n" + secretsKey))
+}
+
+func Parse(attestation data.Attestation) (*Report, error) {
+	quote, err := base64.StdEncoding.DecodeString(attestation.Quote)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s quote: %w", attestation.Type, err)
+	}
+	switch attestation.Type {
+	case TypeSEVSNP:
+		return parseSEVSNP(quote)
+	case TypeTDX:
+		return parseTDX(quote)
+	default:
+		return nil, fmt.Errorf("unknown attestation type %q", attestation.Type)
+	}
+}
+
+// what the solver can check without trusting anything, the quote is for
+// this offer and meets the requirement, the signatures are left to the
+// job creator and the mediator
+func Check(offer data.ResourceOffer, requirement *data.AttestationRequirement) (*Report, error) {
+	if offer.Attestation == nil {
+		return nil, fmt.Errorf("resource offer has no attestation")
+	}
+	report, err := Parse(*offer.Attestation)
+	if err != nil {
+		return nil, err
+	}
+	if report.Debug {
+		return nil, fmt.Errorf("the %s VM has debugging enabled", report.Type)
+	}
+	if report.ReportData != ReportData(offer.ResourceProvider, offer.SecretsKey) {
+		return nil, fmt.Errorf("the %s quote is not for resource provider %s", report.Type, offer.ResourceProvider)
+	}
+	if requirement == nil {
+		return report, nil
+	}
+	if len(requirement.Types) > 0 && !contains(requirement.Types, report.Type) {
+		return nil, fmt.Errorf("attestation type %s is not one of %s", report.Type, strings.Join(requirement.Types, ", "))
+	}
+	if len(requirement.Measurements) > 0 && !contains(requirement.Measurements, hex.EncodeToString(report.Measurement)) {
+		return nil, fmt.Errorf("measurement %x is not one of the accepted measurements", report.Measurement)
+	}
+	return report, nil
+}
+
+type Verifier struct {
+	roots []*x509.Certificate
+}
+
+func NewVerifier(options AttestationOptions) (*Verifier, error) {
+	verifier := &Verifier{}
+	if options.Roots == "" {
+		return verifier, nil
+	}
+	rootsPEM, err := os.ReadFile(options.Roots)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ATTESTATION_ROOTS %s: %w", options.Roots, err)
+	}
+	for block, rest := pem.Decode(rootsPEM); block != nil; block, rest = pem.Decode(rest) {
+		root, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ATTESTATION_ROOTS %s: %w", options.Roots, err)
+		}
+		verifier.roots = append(verifier.roots, root)
+	}
+	if len(verifier.roots) == 0 {
+		return nil, fmt.Errorf("ATTESTATION_ROOTS %s has no certificates", options.Roots)
+	}
+	return verifier, nil
+}
+
+// Check and then that the quote was signed by genuine hardware, the
+// chain of the signing key goes up to one of ATTESTATION_ROOTS
+func (verifier *Verifier) Verify(offer data.ResourceOffer, requirement *data.AttestationRequirement) error {
+	report, err := Check(offer, requirement)
+	if err != nil {
+		return err
+	}
+	if len(verifier.roots) == 0 {
+		return fmt.Errorf("ATTESTATION_ROOTS is needed to verify %s quotes", report.Type)
+	}
+	switch report.Type {
+	case TypeSEVSNP:
+		return verifier.verifySEVSNP(report, offer.Attestation.Certificates)
+	case TypeTDX:
+		return verifier.verifyTDX(report)
+	}
+	return fmt.Errorf("unknown attestation type %q", report.Type)
+}
+
+// each certificate is signed by the next and the last is one of the roots
+// or signed by one, the TCB level and revocation are not checked
+func (verifier *Verifier) checkChain(chain []*x509.Certificate) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("there are no certificates for the quote")
+	}
+	for i := 0; i+1 < len(chain); i++ {
+		if err := chain[i].CheckSignatureFrom(chain[i+1]); err != nil {
+			return fmt.Errorf("certificate %s is not signed by %s: %w", chain[i].Subject, chain[i+1].Subject, err)
+		}
+	}
+	last := chain[len(chain)-1]
+	for _, root := range verifier.roots {
+		if bytes.Equal(last.Raw, root.Raw) {
+			return nil
+		}
+		if last.CheckSignatureFrom(root) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate %s does not chain to ATTESTATION_ROOTS", last.Subject)
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
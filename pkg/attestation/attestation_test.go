@@ -0,0 +1,273 @@
+//go:build unit
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testResourceProvider = "0xA1b2C3d4E5f60718293a4B5c6D7e8F9012345678"
+
+type testCertificate struct {
+	key         *ecdsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func newTestCertificate(t *testing.T, name string, curve elliptic.Curve, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCertificate{key: key, certificate: certificate}
+}
+
+func writeRoots(t *testing.T, roots ...testCertificate) *Verifier {
+	path := filepath.Join(t.TempDir(), "roots.pem")
+	rootsPEM := []byte{}
+	for _, root := range roots {
+		rootsPEM = append(rootsPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.certificate.Raw})...)
+	}
+	require.NoError(t, os.WriteFile(path, rootsPEM, 0600))
+	verifier, err := NewVerifier(AttestationOptions{Roots: path})
+	require.NoError(t, err)
+	return verifier
+}
+
+func littleEndianField(value *big.Int) []byte {
+	field := make([]byte, snpSignatureField)
+	bigEndian := value.Bytes()
+	for i, b := range bigEndian {
+		field[len(bigEndian)-1-i] = b
+	}
+	return field
+}
+
+func snpOffer(t *testing.T, vcek testCertificate, chain []testCertificate, measurement []byte, policy uint64) data.ResourceOffer {
+	reportData := ReportData(testResourceProvider, "0x02abcdef")
+	report := make([]byte, snpReportSize)
+	binary.LittleEndian.PutUint64(report[snpPolicyOffset:], policy)
+	binary.LittleEndian.PutUint32(report[snpSigAlgoOffset:], snpSigAlgoECDSAP384)
+	copy(report[snpDataOffset:], reportData[:])
+	copy(report[snpMeasurement:], measurement)
+	digest := sha512.Sum384(report[:snpSignatureOffset])
+	r, s, err := ecdsa.Sign(rand.Reader, vcek.key, digest[:])
+	require.NoError(t, err)
+	copy(report[snpSignatureOffset:], littleEndianField(r))
+	copy(report[snpSignatureOffset+snpSignatureField:], littleEndianField(s))
+
+	certificates := []string{}
+	for _, certificate := range chain {
+		certificates = append(certificates, base64.StdEncoding.EncodeToString(certificate.certificate.Raw))
+	}
+	return data.ResourceOffer{
+		ResourceProvider: testResourceProvider,
+		SecretsKey:       "0x02abcdef",
+		Attestation: &data.Attestation{
+			Type:         TypeSEVSNP,
+			Quote:        base64.StdEncoding.EncodeToString(report),
+			Certificates: certificates,
+		},
+	}
+}
+
+func rawSignature(t *testing.T, key *ecdsa.PrivateKey, signed []byte) []byte {
+	digest := sha256.Sum256(signed)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signature
+}
+
+func tdxOffer(t *testing.T, pck testCertificate, chain []testCertificate, measurement []byte, qeAuthData []byte) data.ResourceOffer {
+	reportData := ReportData(testResourceProvider, "")
+	quote := make([]byte, tdxHeaderSize+tdxBodySize)
+	binary.LittleEndian.PutUint16(quote, tdxQuoteVersion)
+	binary.LittleEndian.PutUint16(quote[2:], tdxAttKeyECDSA256)
+	binary.LittleEndian.PutUint32(quote[4:], tdxTeeType)
+	copy(quote[tdxHeaderSize+tdxMRTDOffset:], measurement)
+	copy(quote[tdxHeaderSize+tdxDataOffset:], reportData[:])
+
+	attestKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rawKey := make([]byte, 64)
+	attestKey.X.FillBytes(rawKey[:32])
+	attestKey.Y.FillBytes(rawKey[32:])
+
+	qeReport := make([]byte, sgxReportSize)
+	binding := sha256.Sum256(append(append([]byte{}, rawKey...), []byte("auth")...))
+	copy(qeReport[sgxDataOffset:], binding[:])
+
+	pckChain := []byte{}
+	for _, certificate := range chain {
+		pckChain = append(pckChain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.certificate.Raw})...)
+	}
+	qeData := append([]byte{}, qeReport...)
+	qeData = append(qeData, rawSignature(t, pck.key, qeReport)...)
+	qeData = binary.LittleEndian.AppendUint16(qeData, uint16(len(qeAuthData)))
+	qeData = append(qeData, qeAuthData...)
+	qeData = binary.LittleEndian.AppendUint16(qeData, tdxCertPCKChain)
+	qeData = binary.LittleEndian.AppendUint32(qeData, uint32(len(pckChain)))
+	qeData = append(qeData, pckChain...)
+
+	sigData := rawSignature(t, attestKey, quote)
+	sigData = append(sigData, rawKey...)
+	sigData = binary.LittleEndian.AppendUint16(sigData, tdxCertQEReport)
+	sigData = binary.LittleEndian.AppendUint32(sigData, uint32(len(qeData)))
+	sigData = append(sigData, qeData...)
+
+	quote = binary.LittleEndian.AppendUint32(quote, uint32(len(sigData)))
+	quote = append(quote, sigData...)
+	return data.ResourceOffer{
+		ResourceProvider: testResourceProvider,
+		Attestation: &data.Attestation{
+			Type:  TypeTDX,
+			Quote: base64.StdEncoding.EncodeToString(quote),
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ark := newTestCertificate(t, "ARK", elliptic.P384(), nil)
+	measurement := []byte{0xab, 0xcd}
+	offer := snpOffer(t, ark, []testCertificate{ark}, measurement, 0)
+	expected := hex.EncodeToString(append(measurement, make([]byte, snpMeasurementSize-len(measurement))...))
+
+	report, err := Check(offer, nil)
+	require.NoError(t, err)
+	assert.Equal(t, TypeSEVSNP, report.Type)
+	assert.Equal(t, expected, hex.EncodeToString(report.Measurement))
+
+	_, err = Check(offer, &data.AttestationRequirement{Types: []string{TypeSEVSNP}, Measurements: []string{expected}})
+	assert.NoError(t, err)
+	_, err = Check(offer, &data.AttestationRequirement{Types: []string{TypeTDX}})
+	assert.Error(t, err)
+	_, err = Check(offer, &data.AttestationRequirement{Measurements: []string{"00"}})
+	assert.Error(t, err)
+
+	// the quote is tied to the resource provider and its secrets key
+	swapped := offer
+	swapped.SecretsKey = "0x03abcdef"
+	_, err = Check(swapped, nil)
+	assert.Error(t, err)
+	swapped = offer
+	swapped.ResourceProvider = "0x0000000000000000000000000000000000000001"
+	_, err = Check(swapped, nil)
+	assert.Error(t, err)
+
+	_, err = Check(snpOffer(t, ark, []testCertificate{ark}, measurement, snpPolicyDebug), nil)
+	assert.Error(t, err)
+	_, err = Check(data.ResourceOffer{ResourceProvider: testResourceProvider}, nil)
+	assert.Error(t, err)
+}
+
+func TestVerifySEVSNP(t *testing.T) {
+	ark := newTestCertificate(t, "ARK", elliptic.P384(), nil)
+	ask := newTestCertificate(t, "ASK", elliptic.P384(), &ark)
+	vcek := newTestCertificate(t, "VCEK", elliptic.P384(), &ask)
+	offer := snpOffer(t, vcek, []testCertificate{vcek, ask, ark}, []byte{1}, 0)
+
+	verifier := writeRoots(t, ark)
+	assert.NoError(t, verifier.Verify(offer, nil))
+	// the chain can stop at the ASK when the ARK is a root
+	offer.Attestation.Certificates = offer.Attestation.Certificates[:2]
+	assert.NoError(t, verifier.Verify(offer, nil))
+
+	other := newTestCertificate(t, "other ARK", elliptic.P384(), nil)
+	assert.Error(t, writeRoots(t, other).Verify(offer, nil))
+	assert.Error(t, (&Verifier{}).Verify(offer, nil))
+
+	// a report signed by a key the chain does not vouch for
+	forged := snpOffer(t, newTestCertificate(t, "VCEK", elliptic.P384(), nil), []testCertificate{vcek, ask}, []byte{1}, 0)
+	assert.Error(t, verifier.Verify(forged, nil))
+}
+
+func TestVerifyTDX(t *testing.T) {
+	root := newTestCertificate(t, "Intel SGX Root CA", elliptic.P256(), nil)
+	intermediate := newTestCertificate(t, "Intel SGX PCK Platform CA", elliptic.P256(), &root)
+	pck := newTestCertificate(t, "Intel SGX PCK Certificate", elliptic.P256(), &intermediate)
+	measurement := []byte{0x12, 0x34}
+	offer := tdxOffer(t, pck, []testCertificate{pck, intermediate, root}, measurement, []byte("auth"))
+
+	report, err := Check(offer, &data.AttestationRequirement{Types: []string{TypeTDX}})
+	require.NoError(t, err)
+	assert.Equal(t, measurement, report.Measurement[:2])
+
+	verifier := writeRoots(t, root)
+	assert.NoError(t, verifier.Verify(offer, nil))
+
+	// the attestation key is not the one the QE report vouches for
+	assert.Error(t, verifier.Verify(tdxOffer(t, pck, []testCertificate{pck, intermediate, root}, measurement, []byte("other")), nil))
+	// the QE report is signed by a key that is not the PCK certificate's
+	stranger := newTestCertificate(t, "stranger", elliptic.P256(), nil)
+	assert.Error(t, verifier.Verify(tdxOffer(t, stranger, []testCertificate{pck, intermediate, root}, measurement, []byte("auth")), nil))
+
+	truncated := offer
+	quote, _ := base64.StdEncoding.DecodeString(offer.Attestation.Quote)
+	truncated.Attestation = &data.Attestation{Type: TypeTDX, Quote: base64.StdEncoding.EncodeToString(quote[:len(quote)-10])}
+	assert.Error(t, verifier.Verify(truncated, nil))
+}
+
+func TestParseSNPCertificateTable(t *testing.T) {
+	guid := func(value string) []byte {
+		raw, err := hex.DecodeString(value[0:8] + value[9:13] + value[14:18] + value[19:23] + value[24:])
+		require.NoError(t, err)
+		return raw
+	}
+	const entries = 3
+	table := []byte{}
+	certificates := [][]byte{[]byte("ask"), []byte("vcek")}
+	offset := entries * 24
+	for i, id := range []string{"4ab7b379-bbac-4fe4-a02f-05aef327c782", "63da758d-e664-4564-adc5-f4b93be8accd"} {
+		table = append(table, guid(id)...)
+		table = binary.LittleEndian.AppendUint32(table, uint32(offset))
+		table = binary.LittleEndian.AppendUint32(table, uint32(len(certificates[i])))
+		offset += len(certificates[i])
+	}
+	table = append(table, make([]byte, 24)...)
+	table = append(table, []byte("askvcek")...)
+
+	found, err := parseSNPCertificateTable(table)
+	assert.NoError(t, err)
+	// VCEK first whatever order the host put them in
+	assert.Equal(t, [][]byte{[]byte("vcek"), []byte("ask")}, found)
+
+	found, err = parseSNPCertificateTable(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
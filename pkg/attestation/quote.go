@@ -0,0 +1,119 @@
+package attestation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// the kernel's configfs-tsm interface, linux 6.7 and later have it for
+// both SEV-SNP and TDX guests
+const TSM_REPORT_DIR = "/sys/kernel/config/tsm/report"
+
+// the GUIDs of the SEV-SNP extended report certificate table
+var snpCertificateGUIDs = map[string]int{
+	"63da758d-e664-4564-adc5-f4b93be8accd": 0, // VCEK
+	"4ab7b379-bbac-4fe4-a02f-05aef327c782": 1, // ASK
+	"c0b406a4-a803-4952-9743-3fb6014cd0ae": 2, // ARK
+}
+
+// asks the TEE we run in for a quote over reportData, certificates is a
+// PEM file of the VCEK, ASK and ARK for SEV-SNP hosts that do not give
+// them with the report
+func GetQuote(reportData [64]byte, certificates string) (*data.Attestation, error) {
+	report, err := os.MkdirTemp(TSM_REPORT_DIR, "lilypad-")
+	if err != nil {
+		return nil, fmt.Errorf("error making a tsm report in %s, is this a confidential VM: %w", TSM_REPORT_DIR, err)
+	}
+	// configfs entries are removed with rmdir
+	defer os.Remove(report)
+
+	if err := os.WriteFile(filepath.Join(report, "inblob"), reportData[:], 0600); err != nil { //nolint:gomnd
+		return nil, fmt.Errorf("error writing the report data: %w", err)
+	}
+	provider, err := os.ReadFile(filepath.Join(report, "provider"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the tsm provider: %w", err)
+	}
+	quote, err := os.ReadFile(filepath.Join(report, "outblob"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the quote: %w", err)
+	}
+	attestation := &data.Attestation{Quote: base64.StdEncoding.EncodeToString(quote)}
+	switch strings.TrimSpace(string(provider)) {
+	case "tdx_guest":
+		attestation.Type = TypeTDX
+		return attestation, nil
+	case "sev_guest":
+		attestation.Type = TypeSEVSNP
+	default:
+		return nil, fmt.Errorf("tsm provider %s is not supported", strings.TrimSpace(string(provider)))
+	}
+
+	var ders [][]byte
+	if certificates != "" {
+		certificatesPEM, err := os.ReadFile(certificates)
+		if err != nil {
+			return nil, fmt.Errorf("error reading OFFER_ATTESTATION_CERTIFICATES %s: %w", certificates, err)
+		}
+		for block, rest := pem.Decode(certificatesPEM); block != nil; block, rest = pem.Decode(rest) {
+			ders = append(ders, block.Bytes)
+		}
+	} else {
+		// the host only fills this in when it has the certificates
+		auxblob, err := os.ReadFile(filepath.Join(report, "auxblob"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading the sev-snp certificates: %w", err)
+		}
+		ders, err = parseSNPCertificateTable(auxblob)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(ders) == 0 {
+		return nil, fmt.Errorf("the host gave no sev-snp certificates, set OFFER_ATTESTATION_CERTIFICATES")
+	}
+	for _, der := range ders {
+		attestation.Certificates = append(attestation.Certificates, base64.StdEncoding.EncodeToString(der))
+	}
+	return attestation, nil
+}
+
+// the table is GUID, offset and length entries ending in a zero entry,
+// the offsets are from the start of the table
+func parseSNPCertificateTable(table []byte) ([][]byte, error) {
+	const entrySize = 24
+	certificates := make([][]byte, len(snpCertificateGUIDs))
+	for offset := 0; offset+entrySize <= len(table); offset += entrySize {
+		entry := table[offset : offset+entrySize]
+		if bytes.Equal(entry, make([]byte, entrySize)) {
+			break
+		}
+		start := int(binary.LittleEndian.Uint32(entry[16:]))
+		length := int(binary.LittleEndian.Uint32(entry[20:]))
+		if start < 0 || length < 0 || start+length > len(table) {
+			return nil, fmt.Errorf("sev-snp certificate table entry is out of range")
+		}
+		if position, ok := snpCertificateGUIDs[guidString(entry[:16])]; ok {
+			certificates[position] = table[start : start+length]
+		}
+	}
+	found := [][]byte{}
+	for _, certificate := range certificates {
+		if certificate != nil {
+			found = append(found, certificate)
+		}
+	}
+	return found, nil
+}
+
+func guidString(guid []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", guid[0:4], guid[4:6], guid[6:8], guid[8:10], guid[10:16])
+}
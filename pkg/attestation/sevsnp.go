@@ -0,0 +1,87 @@
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// the ATTESTATION_REPORT of the SEV-SNP firmware ABI
+const (
+	snpReportSize      = 0x4A0
+	snpPolicyOffset    = 0x08
+	snpSigAlgoOffset   = 0x34
+	snpDataOffset      = 0x50
+	snpMeasurement     = 0x90
+	snpMeasurementSize = 48
+	snpSignatureOffset = 0x2A0
+	// r and s are little endian and zero padded to 72 bytes each
+	snpSignatureField = 72
+	// ECDSA P-384 with SHA-384
+	snpSigAlgoECDSAP384 = 1
+	// the guest policy bit that lets the host debug the VM
+	snpPolicyDebug = 1 << 19
+)
+
+func parseSEVSNP(quote []byte) (*Report, error) {
+	if len(quote) < snpReportSize {
+		return nil, fmt.Errorf("sev-snp report is %d bytes, it should be %d", len(quote), snpReportSize)
+	}
+	report := &Report{
+		Type:        TypeSEVSNP,
+		Measurement: append([]byte{}, quote[snpMeasurement:snpMeasurement+snpMeasurementSize]...),
+		Debug:       binary.LittleEndian.Uint64(quote[snpPolicyOffset:])&snpPolicyDebug != 0,
+		signed:      quote[:snpSignatureOffset],
+		quote:       quote[:snpReportSize],
+	}
+	copy(report.ReportData[:], quote[snpDataOffset:snpDataOffset+64])
+	return report, nil
+}
+
+// the report is signed by the chip's VCEK, which the AMD ASK signs, which
+// the AMD ARK signs, the certificates come VCEK first
+func (verifier *Verifier) verifySEVSNP(report *Report, certificates []string) error {
+	if algo := binary.LittleEndian.Uint32(report.quote[snpSigAlgoOffset:]); algo != snpSigAlgoECDSAP384 {
+		return fmt.Errorf("sev-snp report signature algorithm %d is not supported", algo)
+	}
+	chain := []*x509.Certificate{}
+	for _, encoded := range certificates {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("error decoding sev-snp certificate: %w", err)
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("error parsing sev-snp certificate: %w", err)
+		}
+		chain = append(chain, certificate)
+	}
+	if err := verifier.checkChain(chain); err != nil {
+		return err
+	}
+	vcek, ok := chain[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok || vcek.Curve != elliptic.P384() {
+		return fmt.Errorf("the sev-snp VCEK is not a P-384 key")
+	}
+	signature := report.quote[snpSignatureOffset:]
+	r := littleEndianInt(signature[:snpSignatureField])
+	s := littleEndianInt(signature[snpSignatureField : 2*snpSignatureField])
+	digest := sha512.Sum384(report.signed)
+	if !ecdsa.Verify(vcek, digest[:], r, s) {
+		return fmt.Errorf("the sev-snp report signature does not match the VCEK")
+	}
+	return nil
+}
+
+func littleEndianInt(value []byte) *big.Int {
+	reversed := make([]byte, len(value))
+	for i, b := range value {
+		reversed[len(value)-1-i] = b
+	}
+	return new(big.Int).SetBytes(reversed)
+}
@@ -0,0 +1,192 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// a version 4 TDX quote as the DCAP quote generation library makes it
+const (
+	tdxHeaderSize     = 48
+	tdxBodySize       = 584
+	tdxQuoteVersion   = 4
+	tdxAttKeyECDSA256 = 2
+	tdxTeeType        = 0x81
+	// offsets into the TD report body
+	tdxAttributesOffset = 120
+	tdxMRTDOffset       = 136
+	tdxMRTDSize         = 48
+	tdxDataOffset       = 520
+	// the attributes bit that lets the host debug the TD
+	tdxAttributesDebug = 1
+	// the certification data is the QE report, its signature and auth
+	// data followed by the PCK certificate chain
+	tdxCertQEReport = 6
+	tdxCertPCKChain = 5
+	sgxReportSize   = 384
+	sgxDataOffset   = 320
+)
+
+type tdxSignature struct {
+	signature   []byte
+	attestKey   []byte
+	qeReport    []byte
+	qeReportSig []byte
+	qeAuthData  []byte
+	pckChainPEM []byte
+}
+
+func parseTDX(quote []byte) (*Report, error) {
+	signedSize := tdxHeaderSize + tdxBodySize
+	if len(quote) < signedSize+4 {
+		return nil, fmt.Errorf("tdx quote is %d bytes, too short for a quote", len(quote))
+	}
+	if version := binary.LittleEndian.Uint16(quote); version != tdxQuoteVersion {
+		return nil, fmt.Errorf("tdx quote version %d is not supported", version)
+	}
+	if teeType := binary.LittleEndian.Uint32(quote[4:]); teeType != tdxTeeType {
+		return nil, fmt.Errorf("quote tee type %#x is not tdx", teeType)
+	}
+	body := quote[tdxHeaderSize:signedSize]
+	report := &Report{
+		Type:        TypeTDX,
+		Measurement: append([]byte{}, body[tdxMRTDOffset:tdxMRTDOffset+tdxMRTDSize]...),
+		Debug:       body[tdxAttributesOffset]&tdxAttributesDebug != 0,
+		signed:      quote[:signedSize],
+		quote:       quote,
+	}
+	copy(report.ReportData[:], body[tdxDataOffset:tdxDataOffset+64])
+	return report, nil
+}
+
+// reads the fields of the signature data that follows the body, every
+// length is checked against what is left of the quote
+func parseTDXSignature(quote []byte) (*tdxSignature, error) {
+	reader := &quoteReader{data: quote, offset: tdxHeaderSize + tdxBodySize}
+	sigSize := int(reader.uint32())
+	sigData := &quoteReader{data: reader.bytes(sigSize)}
+	signature := &tdxSignature{
+		signature: sigData.bytes(64),
+		attestKey: sigData.bytes(64),
+	}
+	if certType := sigData.uint16(); reader.err == nil && sigData.err == nil && certType != tdxCertQEReport {
+		return nil, fmt.Errorf("tdx certification data type %d is not supported", certType)
+	}
+	qeData := &quoteReader{data: sigData.bytes(int(sigData.uint32()))}
+	signature.qeReport = qeData.bytes(sgxReportSize)
+	signature.qeReportSig = qeData.bytes(64)
+	signature.qeAuthData = qeData.bytes(int(qeData.uint16()))
+	if certType := qeData.uint16(); qeData.err == nil && certType != tdxCertPCKChain {
+		return nil, fmt.Errorf("tdx QE certification data type %d is not supported", certType)
+	}
+	signature.pckChainPEM = qeData.bytes(int(qeData.uint32()))
+	for _, parsed := range []*quoteReader{reader, sigData, qeData} {
+		if parsed.err != nil {
+			return nil, parsed.err
+		}
+	}
+	return signature, nil
+}
+
+// the quote is signed by an attestation key that the quoting enclave
+// vouches for in its own report, which is signed by the platform's PCK
+// certificate, which chains up to the Intel SGX root CA
+func (verifier *Verifier) verifyTDX(report *Report) error {
+	if keyType := binary.LittleEndian.Uint16(report.quote[2:]); keyType != tdxAttKeyECDSA256 {
+		return fmt.Errorf("tdx attestation key type %d is not supported", keyType)
+	}
+	signature, err := parseTDXSignature(report.quote)
+	if err != nil {
+		return err
+	}
+	attestKey := p256Key(signature.attestKey)
+	digest := sha256.Sum256(report.signed)
+	if !verifyP256(attestKey, digest[:], signature.signature) {
+		return fmt.Errorf("the tdx quote signature does not match its attestation key")
+	}
+
+	chain := []*x509.Certificate{}
+	for block, rest := pem.Decode(signature.pckChainPEM); block != nil; block, rest = pem.Decode(rest) {
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("error parsing tdx PCK certificate: %w", err)
+		}
+		chain = append(chain, certificate)
+	}
+	if err := verifier.checkChain(chain); err != nil {
+		return err
+	}
+	pck, ok := chain[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok || pck.Curve != elliptic.P256() {
+		return fmt.Errorf("the tdx PCK certificate is not a P-256 key")
+	}
+	digest = sha256.Sum256(signature.qeReport)
+	if !verifyP256(pck, digest[:], signature.qeReportSig) {
+		return fmt.Errorf("the tdx QE report signature does not match the PCK certificate")
+	}
+
+	// the QE report data is the hash of the attestation key and auth data
+	// so the key that signed the quote is the one the enclave made
+	binding := sha256.Sum256(append(append([]byte{}, signature.attestKey...), signature.qeAuthData...))
+	if !bytes.Equal(signature.qeReport[sgxDataOffset:sgxDataOffset+sha256.Size], binding[:]) {
+		return fmt.Errorf("the tdx attestation key is not the one in the QE report")
+	}
+	return nil
+}
+
+// the raw x and y of a P-256 point
+func p256Key(raw []byte) *ecdsa.PublicKey {
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(raw[:32]),
+		Y:     new(big.Int).SetBytes(raw[32:64]),
+	}
+}
+
+// a raw big endian r and s
+func verifyP256(key *ecdsa.PublicKey, digest []byte, signature []byte) bool {
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	return ecdsa.Verify(key, digest, r, s)
+}
+
+// little endian fields off the front of a quote, the first read past the
+// end sets err and every read after it returns nothing
+type quoteReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (reader *quoteReader) bytes(size int) []byte {
+	if reader.err == nil && (size < 0 || reader.offset+size > len(reader.data)) {
+		reader.err = fmt.Errorf("tdx quote is truncated")
+	}
+	if reader.err != nil {
+		return nil
+	}
+	value := reader.data[reader.offset : reader.offset+size]
+	reader.offset += size
+	return value
+}
+
+func (reader *quoteReader) uint16() uint16 {
+	if value := reader.bytes(2); value != nil {
+		return binary.LittleEndian.Uint16(value)
+	}
+	return 0
+}
+
+func (reader *quoteReader) uint32() uint32 {
+	if value := reader.bytes(4); value != nil {
+		return binary.LittleEndian.Uint32(value)
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the type of value an attribute holds, used by the schema
+// to check the attributes a resource provider describes itself with
+type AttributeType string
+
+const (
+	StringAttribute AttributeType = "string"
+	NumberAttribute AttributeType = "number"
+	BoolAttribute   AttributeType = "bool"
+)
+
+// maps attribute keys onto the type of value they hold
+// an empty schema allows any well formed key with any value
+type AttributeSchema map[string]AttributeType
+
+func NewAttributeSchema(types map[string]string) (AttributeSchema, error) {
+	schema := AttributeSchema{}
+	for key, value := range types {
+		if !attributeKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid attribute key %q", key)
+		}
+		attributeType := AttributeType(value)
+		switch attributeType {
+		case StringAttribute, NumberAttribute, BoolAttribute:
+			schema[key] = attributeType
+		default:
+			return nil, fmt.Errorf("attribute %q has unknown type %q", key, value)
+		}
+	}
+	return schema, nil
+}
+
+// keys are lower case and can use dots and dashes e.g. "tpu-v5e" or "gpu.nvlink"
+var attributeKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.\-_]*$`)
+
+type RequirementOperator string
+
+const (
+	RequireExists    RequirementOperator = "exists"
+	RequireNotExists RequirementOperator = "!exists"
+	RequireEqual     RequirementOperator = "="
+	RequireNotEqual  RequirementOperator = "!="
+	RequireGreater   RequirementOperator = ">"
+	RequireGreaterEq RequirementOperator = ">="
+	RequireLess      RequirementOperator = "<"
+	RequireLessEq    RequirementOperator = "<="
+)
+
+// a condition a job offer places on the attributes of a resource offer
+type AttributeRequirement struct {
+	Key      string              `json:"key"`
+	Operator RequirementOperator `json:"operator"`
+	Value    string              `json:"value,omitempty"`
+}
+
+func (requirement AttributeRequirement) String() string {
+	switch requirement.Operator {
+	case RequireExists:
+		return requirement.Key
+	case RequireNotExists:
+		return "!" + requirement.Key
+	}
+	return requirement.Key + string(requirement.Operator) + requirement.Value
+}
+
+// the order matters so the two character operators are found first
+var requirementOperators = []RequirementOperator{
+	RequireNotEqual,
+	RequireGreaterEq,
+	RequireLessEq,
+	RequireEqual,
+	RequireGreater,
+	RequireLess,
+}
+
+// parse a requirement expression such as "infiniband", "!nvlink",
+// "tpu=v5e" or "gpu.count>=2"
+func ParseAttributeRequirement(expression string) (AttributeRequirement, error) {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "!") && !strings.Contains(expression, "=") {
+		requirement := AttributeRequirement{Key: strings.TrimPrefix(expression, "!"), Operator: RequireNotExists}
+		return requirement, requirement.validate()
+	}
+	for _, operator := range requirementOperators {
+		key, value, found := strings.Cut(expression, string(operator))
+		if !found {
+			continue
+		}
+		requirement := AttributeRequirement{
+			Key:      strings.TrimSpace(key),
+			Operator: operator,
+			Value:    strings.TrimSpace(value),
+		}
+		return requirement, requirement.validate()
+	}
+	requirement := AttributeRequirement{Key: expression, Operator: RequireExists}
+	return requirement, requirement.validate()
+}
+
+func (requirement AttributeRequirement) validate() error {
+	if !attributeKeyPattern.MatchString(requirement.Key) {
+		return fmt.Errorf("invalid attribute key %q", requirement.Key)
+	}
+	switch requirement.Operator {
+	case RequireExists, RequireNotExists, RequireEqual, RequireNotEqual:
+		return nil
+	case RequireGreater, RequireGreaterEq, RequireLess, RequireLessEq:
+		if _, err := strconv.ParseFloat(requirement.Value, 64); err != nil {
+			return fmt.Errorf("requirement %q compares against a value that is not a number", requirement)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown requirement operator %q", requirement.Operator)
+}
+
+// check the attributes of a resource offer against the schema
+func (schema AttributeSchema) ValidateAttributes(attributes map[string]string) error {
+	for key, value := range attributes {
+		if !attributeKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid attribute key %q", key)
+		}
+		if len(schema) == 0 {
+			continue
+		}
+		attributeType, ok := schema[key]
+		if !ok {
+			return fmt.Errorf("attribute %q is not in the schema", key)
+		}
+		if err := checkAttributeValue(attributeType, value); err != nil {
+			return fmt.Errorf("attribute %q: %s", key, err.Error())
+		}
+	}
+	return nil
+}
+
+// check the requirements of a job offer against the schema
+// so a typo in a key fails loudly rather than never matching
+func (schema AttributeSchema) ValidateRequirements(requirements []AttributeRequirement) error {
+	for _, requirement := range requirements {
+		if err := requirement.validate(); err != nil {
+			return err
+		}
+		if len(schema) == 0 {
+			continue
+		}
+		attributeType, ok := schema[requirement.Key]
+		if !ok {
+			return fmt.Errorf("requirement %q uses attribute that is not in the schema", requirement)
+		}
+		if requirement.Operator == RequireExists || requirement.Operator == RequireNotExists {
+			continue
+		}
+		if err := checkAttributeValue(attributeType, requirement.Value); err != nil {
+			return fmt.Errorf("requirement %q: %s", requirement, err.Error())
+		}
+	}
+	return nil
+}
+
+func checkAttributeValue(attributeType AttributeType, value string) error {
+	switch attributeType {
+	case StringAttribute:
+		return nil
+	case NumberAttribute:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("value %q is not a number", value)
+		}
+		return nil
+	case BoolAttribute:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value %q is not a bool", value)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown attribute type %q", attributeType)
+}
+
+// does this set of attributes satisfy the requirement
+func (requirement AttributeRequirement) Matches(attributes map[string]string) bool {
+	value, ok := attributes[requirement.Key]
+	switch requirement.Operator {
+	case RequireExists:
+		// a bool attribute set to false is the same as not having it
+		return ok && value != "false"
+	case RequireNotExists:
+		return !ok || value == "false"
+	case RequireEqual:
+		return ok && value == requirement.Value
+	case RequireNotEqual:
+		return !ok || value != requirement.Value
+	}
+
+	if !ok {
+		return false
+	}
+	have, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	want, err := strconv.ParseFloat(requirement.Value, 64)
+	if err != nil {
+		return false
+	}
+	switch requirement.Operator {
+	case RequireGreater:
+		return have > want
+	case RequireGreaterEq:
+		return have >= want
+	case RequireLess:
+		return have < want
+	case RequireLessEq:
+		return have <= want
+	}
+	return false
+}
+
+// returns the first requirement the attributes do not satisfy
+func UnmetRequirement(attributes map[string]string, requirements []AttributeRequirement) (AttributeRequirement, bool) {
+	for _, requirement := range requirements {
+		if !requirement.Matches(attributes) {
+			return requirement, true
+		}
+	}
+	return AttributeRequirement{}, false
+}
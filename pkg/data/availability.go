@@ -0,0 +1,170 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// a recurring window of time a resource offer can be used in
+// e.g. a university cluster that is only free at the weekend
+type AvailabilityWindow struct {
+	// the days of the week the window starts on
+	Days []time.Weekday `json:"days"`
+	// the start and end of the window in "15:04" format
+	// an end at or before the start means the window runs past midnight
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// the IANA timezone the window is in, UTC if empty
+	Timezone string `json:"timezone,omitempty"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parse a window in the form "sat,sun 00:00-24:00 Europe/London"
+// the timezone is optional
+func ParseAvailabilityWindow(value string) (AvailabilityWindow, error) {
+	parts := strings.Fields(value)
+	if len(parts) < 2 || len(parts) > 3 {
+		return AvailabilityWindow{}, fmt.Errorf("invalid availability window %q, expected \"days start-end [timezone]\"", value)
+	}
+
+	window := AvailabilityWindow{}
+	for _, day := range strings.Split(parts[0], ",") {
+		weekday, ok := weekdayNames[strings.ToLower(day)]
+		if !ok {
+			return AvailabilityWindow{}, fmt.Errorf("invalid day %q in availability window %q", day, value)
+		}
+		window.Days = append(window.Days, weekday)
+	}
+
+	times := strings.Split(parts[1], "-")
+	if len(times) != 2 {
+		return AvailabilityWindow{}, fmt.Errorf("invalid time range %q in availability window %q", parts[1], value)
+	}
+	window.Start = times[0]
+	window.End = times[1]
+
+	if len(parts) == 3 {
+		window.Timezone = parts[2]
+	}
+
+	return window, window.Validate()
+}
+
+func (window AvailabilityWindow) Validate() error {
+	if len(window.Days) == 0 {
+		return fmt.Errorf("availability window must have at least one day")
+	}
+	if _, err := parseClock(window.Start); err != nil {
+		return err
+	}
+	if _, err := parseClock(window.End); err != nil {
+		return err
+	}
+	if _, err := window.location(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// returns the start of the next occurrence of the window that contains
+// or begins after the given time, along with when that occurrence ends
+func (window AvailabilityWindow) Next(from time.Time) (time.Time, time.Time, bool) {
+	loc, err := window.location()
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	start, err := parseClock(window.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := parseClock(window.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	length := end - start
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+
+	local := from.In(loc)
+	// start a day back so we catch a window that started yesterday and runs past midnight
+	for offset := -1; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		if !window.onDay(day.Weekday()) {
+			continue
+		}
+		opens := day.Add(start)
+		closes := opens.Add(length)
+		if from.Before(closes) {
+			return opens, closes, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func (window AvailabilityWindow) onDay(weekday time.Weekday) bool {
+	for _, day := range window.Days {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func (window AvailabilityWindow) location() (*time.Location, error) {
+	if window.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(window.Timezone)
+}
+
+// parse "15:04" into a duration since midnight, allowing "24:00" for the end of the day
+func parseClock(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// returns when the resource offer can next be used
+// an offer without any windows is always available
+func NextAvailability(windows []AvailabilityWindow, from time.Time) (time.Time, bool) {
+	if len(windows) == 0 {
+		return from, true
+	}
+	var earliest time.Time
+	found := false
+	for _, window := range windows {
+		opens, _, ok := window.Next(from)
+		if !ok {
+			continue
+		}
+		if opens.Before(from) {
+			opens = from
+		}
+		if !found || opens.Before(earliest) {
+			earliest = opens
+			found = true
+		}
+	}
+	return earliest, found
+}
+
+func IsAvailable(windows []AvailabilityWindow, at time.Time) bool {
+	next, ok := NextAvailability(windows, at)
+	return ok && !next.After(at)
+}
@@ -0,0 +1,141 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// a standard five field cron schedule: minute hour day-of-month month day-of-week
+// each field can be "*", a value, a range "1-5", a list "1,15" or a step "*/10"
+type CronSchedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// cron treats day of month and day of week as either-or when both are set
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+func ParseCronSchedule(expression string) (CronSchedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return CronSchedule{}, fmt.Errorf("cron schedule %q must have %d fields", expression, len(cronFields))
+	}
+	sets := make([]map[int]bool, len(cronFields))
+	for i, field := range cronFields {
+		set, err := parseCronField(parts[i], field)
+		if err != nil {
+			return CronSchedule{}, fmt.Errorf("cron schedule %q: %s", expression, err.Error())
+		}
+		sets[i] = set
+	}
+	return CronSchedule{
+		minutes:       sets[0],
+		hours:         sets[1],
+		daysOfMonth:   sets[2],
+		months:        sets[3],
+		daysOfWeek:    sets[4],
+		anyDayOfMonth: parts[2] == "*",
+		anyDayOfWeek:  parts[4] == "*",
+	}, nil
+}
+
+func parseCronField(value string, field cronField) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid %s step %q", field.name, stepPart)
+			}
+			step = parsed
+		}
+
+		start, end := field.min, field.max
+		if rangePart != "*" {
+			low, high, isRange := strings.Cut(rangePart, "-")
+			parsed, err := strconv.Atoi(low)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", field.name, item)
+			}
+			start, end = parsed, parsed
+			if isRange {
+				end, err = strconv.Atoi(high)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s %q", field.name, item)
+				}
+			} else if hasStep {
+				// "5/15" means every 15 starting at 5
+				end = field.max
+			}
+		}
+		if start < field.min || end > field.max || start > end {
+			return nil, fmt.Errorf("%s %q is outside %d-%d", field.name, item, field.min, field.max)
+		}
+		for i := start; i <= end; i += step {
+			set[i] = true
+		}
+	}
+	return set, nil
+}
+
+func (schedule CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := schedule.daysOfMonth[t.Day()]
+	dayOfWeek := schedule.daysOfWeek[int(t.Weekday())]
+	switch {
+	case schedule.anyDayOfMonth && schedule.anyDayOfWeek:
+		return true
+	case schedule.anyDayOfMonth:
+		return dayOfWeek
+	case schedule.anyDayOfWeek:
+		return dayOfMonth
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// the first minute after from that the schedule fires, in UTC
+func (schedule CronSchedule) Next(from time.Time) time.Time {
+	t := from.UTC().Truncate(time.Minute).Add(time.Minute)
+	// a schedule that can never fire, like the 30th of february, gives up after a leap cycle
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !schedule.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !schedule.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !schedule.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return limit
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
 )
@@ -34,6 +35,11 @@ type GPUSpec struct {
 	Vendor string `json:"vendor"`
 
 	VRAM int `json:"vram"`
+
+	// the driver version and the highest CUDA version it supports, only
+	// set for GPUs the resource provider detected itself
+	Driver string `json:"driver,omitempty"`
+	CUDA   string `json:"cuda,omitempty"`
 }
 
 // this is what is loaded from the template file in the git repo
@@ -47,6 +53,21 @@ type Module struct {
 
 	// the bacalhau job spec
 	Job bacalhau.Job `json:"job"`
+
+	// the inputs the job reads as files rather than from its template,
+	// the input's value is the ipfs cid or url that is mounted in
+	Volumes []ModuleVolume `json:"volumes,omitempty"`
+}
+
+// an input the module wants mounted read only into the job's container
+type ModuleVolume struct {
+	// the name of the input that says what to mount
+	Input string `json:"input"`
+	// where in the container it is mounted, a directory for a cid and the
+	// file itself for a url
+	Path string `json:"path"`
+	// the job cannot be run without it
+	Required bool `json:"required,omitempty"`
 }
 
 // describes a workload to be run
@@ -81,6 +102,21 @@ type Result struct {
 	DataID           string `json:"data_id"`
 	Error            string `json:"error"`
 	InstructionCount uint64 `json:"instruction_count"`
+	// what the job used, nil when the resource provider did not measure it
+	Usage *ResourceUsage `json:"usage,omitempty"`
+}
+
+// what a job used of the machine, measured by the resource provider
+// while the job ran
+type ResourceUsage struct {
+	// cpu seconds across all the cores
+	CPUTime float64 `json:"cpu_time"`
+	// megabytes
+	PeakMemory int `json:"peak_memory"`
+	// the mean utilization of the job's gpus in percent
+	GPUUtilization float64 `json:"gpu_utilization,omitempty"`
+	// seconds from the job being started to it finishing
+	Duration float64 `json:"duration"`
 }
 
 // Provides compatibility for older clients that expect the results_id field
@@ -187,6 +223,57 @@ type JobOffer struct {
 
 	// which node(s) (if any) to target
 	Target TargetConfig `json:"target"`
+
+	// which regions we would like the job to run in
+	Locality *LocalityPreference `json:"locality,omitempty"`
+
+	// conditions on the attributes of the resource offer
+	// e.g. "infiniband" or "gpu.count>=2"
+	Requirements []AttributeRequirement `json:"requirements,omitempty"`
+
+	// how likely the job creator is to have the results checked by a mediator
+	// this travels with the deal so everyone can see the policy that applied
+	Mediation *MediationPolicy `json:"mediation,omitempty"`
+
+	// how many seconds we are willing to wait for a resource offer
+	// that is not available right now, zero means we only want
+	// resource offers that can run the job straight away
+	MaxDeferral int `json:"max_deferral,omitempty"`
+
+	// links the runs of a recurring job together
+	Series string `json:"series,omitempty"`
+
+	// the ERC-20 token the deal is paid in, empty is the network's token
+	PaymentToken string `json:"payment_token,omitempty"`
+
+	// the names of the secrets the job needs, their values are only sent
+	// encrypted to the resource provider once the deal is matched
+	Secrets []string `json:"secrets,omitempty"`
+
+	// how many seconds we expect the job to run for, resource offers with
+	// resource pricing charge for this long and stop the job after it,
+	// zero is the resource offer's submit results timeout
+	Duration int `json:"duration,omitempty"`
+	// how many MB we expect the job to send out e.g. its results
+	Egress int `json:"egress,omitempty"`
+
+	// only run on resource providers whose attestation meets this
+	// nil means any resource provider
+	Attestation *AttestationRequirement `json:"attestation,omitempty"`
+}
+
+type LocalityPreference struct {
+	// the regions we would like to run in e.g. where the input data lives
+	Regions []string `json:"regions,omitempty"`
+	// only match resource offers in one of the regions
+	Strict bool `json:"strict,omitempty"`
+}
+
+type MediationPolicy struct {
+	// always check the results of the first N deals with a resource provider
+	AlwaysCheckFirst int `json:"always_check_first"`
+	// out of 100 chance we check results once we have done N deals
+	CheckResultsPercentage int `json:"check_results_percentage"`
 }
 
 // this is what the solver keeps track of so we can know
@@ -197,6 +284,23 @@ type JobOfferContainer struct {
 	JobCreator string   `json:"job_creator"`
 	State      uint8    `json:"state"`
 	JobOffer   JobOffer `json:"job_offer"`
+	// the address of the solver that forwarded this job offer to us
+	Origin string `json:"origin,omitempty"`
+	// the url of the peer solver we forwarded this job offer to
+	// the peer does the matching and we mirror the deal it makes
+	ForwardedTo string `json:"forwarded_to,omitempty"`
+	// when the solver stored the job offer in milliseconds, unlike the
+	// offer's created at it is not up to the job creator
+	ReceivedAt int64 `json:"received_at,omitempty"`
+}
+
+// when the solver received the job offer, offers stored before we
+// recorded the time fall back to their created at
+func (container JobOfferContainer) Received() time.Time {
+	if container.ReceivedAt == 0 {
+		return time.UnixMilli(int64(container.JobOffer.CreatedAt))
+	}
+	return time.UnixMilli(container.ReceivedAt)
 }
 
 // posted to the solver by a resource provider
@@ -215,6 +319,9 @@ type ResourceOffer struct {
 	// the module ID's that this resource provider can run
 	// an empty list means ALL modules
 	Modules []string `json:"modules"`
+	// the modules this resource provider will not run
+	// even when they are in Modules
+	DeniedModules []string `json:"denied_modules,omitempty"`
 	// tells the solver how to match these prices
 	// for RP this will normally be FixedPrice
 	// we expect the default pricing to be filled in
@@ -228,9 +335,57 @@ type ResourceOffer struct {
 	// for certain modules
 	ModulePricing  map[string]DealPricing  `json:"module_pricing"`
 	ModuleTimeouts map[string]DealTimeouts `json:"module_timeouts"`
+	// the default pricing in other ERC-20 tokens by token address
+	// DefaultPricing is what we charge in the network's token
+	TokenPricing map[string]DealPricing `json:"token_pricing,omitempty"`
+	// prices for the spec a job uses on top of the instruction price
+	// in the network's token, nil charges per instruction only
+	ResourcePricing *ResourcePricing `json:"resource_pricing,omitempty"`
 
 	// which parties are trusted by the resource provider
 	Services ServiceConfig `json:"trusted_parties"`
+
+	// where the machine is e.g. "eu-west" so job offers can prefer nearby resources
+	Region string `json:"region,omitempty"`
+
+	// capabilities that are not part of the machine spec
+	// e.g. "infiniband": "true" or "tpu": "v5e"
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// the recurring windows this offer can be used in
+	// an empty list means it is always available
+	Availability []AvailabilityWindow `json:"availability,omitempty"`
+
+	// the spec is the total capacity of the machine and the solver
+	// can pack several concurrent deals onto this one offer
+	Packing bool `json:"packing,omitempty"`
+
+	// the compressed secp256k1 public key job creators encrypt the secrets
+	// of a job to, empty when we do not take jobs with secrets
+	SecretsKey string `json:"secrets_key,omitempty"`
+
+	// a TEE quote that binds ResourceProvider and SecretsKey to the
+	// confidential VM we run in, nil when we are not in one
+	Attestation *Attestation `json:"attestation,omitempty"`
+}
+
+// the quote a confidential VM's hardware made, pkg/attestation checks it
+type Attestation struct {
+	// sev-snp or tdx
+	Type string `json:"type"`
+	// base64 of the SEV-SNP attestation report or the TDX quote
+	Quote string `json:"quote"`
+	// base64 DER certificates that sign a SEV-SNP report, VCEK first
+	// a TDX quote carries its own
+	Certificates []string `json:"certificates,omitempty"`
+}
+
+type AttestationRequirement struct {
+	// the TEE types we accept e.g. sev-snp, empty is any of them
+	Types []string `json:"types,omitempty"`
+	// the hex launch measurements we accept, the SEV-SNP MEASUREMENT or
+	// the TDX MRTD, empty is any image
+	Measurements []string `json:"measurements,omitempty"`
 }
 
 // this is what the solver keeps track of so we can know
@@ -269,6 +424,15 @@ type DealPricing struct {
 	MediationFee              uint64 `json:"mediation_fee"`
 }
 
+// what the resource offer charges for the machine a job uses, a whole gpu
+// or cpu for an hour, a GB of ram for an hour and a GB sent out
+type ResourcePricing struct {
+	GPUHour   uint64 `json:"gpu_hour,omitempty"`
+	CPUHour   uint64 `json:"cpu_hour,omitempty"`
+	RAMGBHour uint64 `json:"ram_gb_hour,omitempty"`
+	EgressGB  uint64 `json:"egress_gb,omitempty"`
+}
+
 // represents a solver decision
 // the solver keeps track of "no" decisions to avoid trying to repeatedly match
 // things it's already decided it can't match
@@ -277,6 +441,188 @@ type MatchDecision struct {
 	ResourceOffer string `json:"resource_offer"`
 	Deal          string `json:"deal"`
 	Result        bool   `json:"result"`
+	// why the solver decided what it did
+	Reason string `json:"reason,omitempty"`
+}
+
+// a job offer that has been scheduled onto a resource offer's next availability window
+// the solver turns this into a deal once the window opens
+type ScheduledMatch struct {
+	JobOffer      string `json:"job_offer"`
+	ResourceOffer string `json:"resource_offer"`
+	// millisecond timestamp of when the window opens
+	StartsAt int64 `json:"starts_at"`
+}
+
+// a resource offer that matched a job offer while its auction window was open
+// the solver keeps the full bid set so anyone can check how the winner was picked
+type AuctionBid struct {
+	JobOffer         string `json:"job_offer"`
+	ResourceOffer    string `json:"resource_offer"`
+	ResourceProvider string `json:"resource_provider"`
+	InstructionPrice uint64 `json:"instruction_price"`
+	// the position of this bid once the bids have been ranked, 0 is the winner
+	Rank   int  `json:"rank"`
+	Winner bool `json:"winner"`
+}
+
+// a key that dashboards and monitoring systems send to read from the solver
+// without a wallet, only the hash is kept so the key itself is shown once
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// hex sha256 of the key, never sent back to clients
+	Hash string `json:"-"`
+	// the admin address that made the key
+	CreatedBy string `json:"created_by"`
+	// millisecond timestamp
+	CreatedAt int64 `json:"created_at"`
+}
+
+// what an admin gets back when they make a key
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// a url the solver POSTs deal lifecycle events to
+type Webhook struct {
+	ID string `json:"id"`
+	// the address that registered the webhook, it only hears
+	// about the deals that address is a party to
+	Owner string `json:"owner"`
+	URL   string `json:"url"`
+	// the hmac key for the signature header, only sent back when
+	// the webhook is made since we need the key itself to sign
+	Secret string `json:"-"`
+	// the events to send, every event when empty
+	Events []string `json:"events"`
+	// millisecond timestamp
+	CreatedAt int64 `json:"created_at"`
+}
+
+// what the owner gets back when they make a webhook
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// one attempt at sending an event to a webhook
+type WebhookDelivery struct {
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	// the id in the payload, the same for every attempt at sending it
+	EventID string `json:"event_id"`
+	Event   string `json:"event"`
+	Attempt int    `json:"attempt"`
+	// zero when the request did not get a response
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	Delivered  bool   `json:"delivered"`
+	// millisecond timestamp
+	CreatedAt int64 `json:"created_at"`
+}
+
+// the response to a POST sent with an Idempotency-Key, sent again
+// instead of running the handler when a retry uses the same key
+type IdempotentResponse struct {
+	// hex sha256 of the signer, the path and the key so
+	// clients cannot replay each others responses
+	Key string `json:"key"`
+	// hex sha256 of the request body, a retry has to send the same one
+	RequestHash string `json:"request_hash"`
+	StatusCode  int    `json:"status_code"`
+	Body        string `json:"body"`
+	// millisecond timestamp
+	CreatedAt int64 `json:"created_at"`
+}
+
+// a signed call that changed something on the solver, kept so operators
+// can work out who posted or cancelled what after an incident
+type AuditEntry struct {
+	// time ordered so pages come back in the order the calls were made
+	ID string `json:"id"`
+	// the lower case address that signed the request
+	Address string `json:"address"`
+	// the http method, or GRPC for the gRPC api
+	Method string `json:"method"`
+	// the route template e.g. /api/v1/deals/{id}/result or the gRPC method
+	Route string `json:"route"`
+	Path  string `json:"path"`
+	// hex sha256 of the request body the solver read
+	PayloadHash string `json:"payload_hash"`
+	StatusCode  int    `json:"status_code"`
+	RequestID   string `json:"request_id,omitempty"`
+	IP          string `json:"ip,omitempty"`
+	// millisecond timestamp
+	CreatedAt int64 `json:"created_at"`
+}
+
+// one event emitted by a Lilypad contract as the indexer stored it
+type ChainEvent struct {
+	// the block and log index zero padded so ids sort in chain order
+	ID string `json:"id"`
+	// e.g. storage or payments
+	Contract string `json:"contract"`
+	// the contract's address
+	Address  string `json:"address"`
+	Event    string `json:"event"`
+	Block    uint64 `json:"block"`
+	TxHash   string `json:"tx_hash"`
+	LogIndex uint   `json:"log_index"`
+	// empty for events that are not about a deal
+	DealID string `json:"deal_id,omitempty"`
+	// the lower case addresses in the event's arguments
+	Addresses []string `json:"addresses"`
+	// every argument by name, numbers and addresses as strings
+	Args map[string]string `json:"args"`
+}
+
+// the outcome of matching a hypothetical job offer against one resource offer
+type SimulatedMatch struct {
+	ResourceOffer    string `json:"resource_offer"`
+	ResourceProvider string `json:"resource_provider"`
+	InstructionPrice uint64 `json:"instruction_price"`
+	Matched          bool   `json:"matched"`
+	Reason           string `json:"reason"`
+}
+
+// what the solver would do with a job offer if it were posted right now
+// nothing is recorded so this can be used to work out why a job is not matching
+type MatchSimulation struct {
+	JobOffer JobOffer `json:"job_offer"`
+	// the resource offer that would win the deal, empty if there is none
+	Selected string `json:"selected,omitempty"`
+	// matching resource offers ordered by price, cheapest first
+	Matches    []SimulatedMatch `json:"matches"`
+	Rejections []SimulatedMatch `json:"rejections"`
+}
+
+// what a job offer is expected to cost its job creator if it were posted now
+// the token amounts are whole tokens of the payment token and gas is in wei
+type CostEstimate struct {
+	JobOffer JobOffer `json:"job_offer"`
+	// the resource offer the deal would most likely be made with
+	// empty when nothing matches and the job offer's own pricing is used
+	ResourceOffer string      `json:"resource_offer,omitempty"`
+	PaymentToken  string      `json:"payment_token,omitempty"`
+	Pricing       DealPricing `json:"pricing"`
+	// the instructions the estimate assumes the job will report
+	InstructionCount uint64 `json:"instruction_count"`
+	// the instruction price for every instruction, capped at the payment
+	// collateral the way the payments contract caps it
+	JobCost uint64 `json:"job_cost"`
+	// escrowed when the deal is agreed and refunded less the job cost
+	Collateral uint64 `json:"collateral"`
+	// only charged when the results are checked by a mediator
+	MediationFee uint64 `json:"mediation_fee"`
+	// the job cost plus the mediation fee
+	MaxCost uint64 `json:"max_cost"`
+	// what the job creator's transactions for the deal should use
+	GasLimit uint64 `json:"gas_limit"`
+	// the gas limit at the current max fee, 0 when the solver has no fee estimate yet
+	GasCost uint64 `json:"gas_cost"`
+	MaxFee  uint64 `json:"max_fee"`
 }
 
 // this is the struct that will have it's ID taken and used
@@ -290,6 +636,9 @@ type Deal struct {
 	Timeouts      DealTimeouts  `json:"timeouts"`
 	JobOffer      JobOffer      `json:"job_offer"`
 	ResourceOffer ResourceOffer `json:"resource_offer"`
+	// the ERC-20 token the pricing and collateral are in
+	// empty is the network's token
+	PaymentToken string `json:"payment_token,omitempty"`
 }
 
 // we keep track of tx ids on behalf of resource providers
@@ -298,25 +647,47 @@ type Deal struct {
 // in a block - therefore, we let the job creator & resource provider
 // update these at will
 type DealTransactionsJobCreator struct {
-	Agree                string `json:"agree"`
-	AcceptResult         string `json:"accept_result"`
-	CheckResult          string `json:"check_result"`
-	TimeoutAgree         string `json:"timeout_agree"`
-	TimeoutSubmitResult  string `json:"timeout_submit_result"`
-	TimeoutMediateResult string `json:"timeout_mediate_result"`
+	Agree                string                  `json:"agree"`
+	AcceptResult         string                  `json:"accept_result"`
+	CheckResult          string                  `json:"check_result"`
+	TimeoutAgree         string                  `json:"timeout_agree"`
+	TimeoutSubmitResult  string                  `json:"timeout_submit_result"`
+	TimeoutMediateResult string                  `json:"timeout_mediate_result"`
+	Stuck                []DealTransactionStatus `json:"stuck,omitempty"`
 }
 
 type DealTransactionsResourceProvider struct {
-	Agree                string `json:"agree"`
-	AddResult            string `json:"add_result"`
-	TimeoutAgree         string `json:"timeout_agree"`
-	TimeoutJudgeResult   string `json:"timeout_judge_result"`
-	TimeoutMediateResult string `json:"timeout_mediate_result"`
+	Agree                string                  `json:"agree"`
+	AddResult            string                  `json:"add_result"`
+	TimeoutAgree         string                  `json:"timeout_agree"`
+	TimeoutJudgeResult   string                  `json:"timeout_judge_result"`
+	TimeoutMediateResult string                  `json:"timeout_mediate_result"`
+	Stuck                []DealTransactionStatus `json:"stuck,omitempty"`
 }
 
 type DealTransactionsMediator struct {
-	MediationAcceptResult string `json:"mediation_accept_result"`
-	MediationRejectResult string `json:"mediation_reject_result"`
+	MediationAcceptResult string                  `json:"mediation_accept_result"`
+	MediationRejectResult string                  `json:"mediation_reject_result"`
+	Stuck                 []DealTransactionStatus `json:"stuck,omitempty"`
+}
+
+const (
+	// sent again with a higher fee, ReplacedBy is the one that went next
+	DealTransactionReplaced = "replaced"
+	// still not mined when its fee could not go any higher
+	DealTransactionAbandoned = "abandoned"
+	// mined in a block a reorg dropped, it can still be mined again
+	DealTransactionReorged = "reorged"
+)
+
+// a transaction for a deal that did not make it on chain as it was sent,
+// the hashes above are always the ones that did
+type DealTransactionStatus struct {
+	// the transaction it was for e.g. agree or timeout_submit_result
+	Action     string `json:"action"`
+	Hash       string `json:"hash"`
+	Status     string `json:"status"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
 }
 
 type DealTransactions struct {
@@ -335,6 +706,14 @@ type DealContainer struct {
 	Deal             Deal             `json:"deal"`
 	Transactions     DealTransactions `json:"transactions"`
 	Mediator         string           `json:"mediator"`
+	// the job's secrets encrypted to the resource provider's secrets key
+	Secrets string `json:"secrets,omitempty"`
+}
+
+// what the job creator posts once its deal is matched
+type DealSecrets struct {
+	// base64 ECIES ciphertext of the secrets as a JSON object of name to value
+	Secrets string `json:"secrets"`
 }
 
 type MinerHashRate struct {
@@ -4,7 +4,9 @@ package data
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/mr-tron/base58"
 	"pgregory.net/rapid"
@@ -100,7 +102,206 @@ func TestResultJSONBackwardsCompatibility(t *testing.T) {
 
 // Generators
 
+func TestAvailabilityWindows(t *testing.T) {
+	weekend, err := ParseAvailabilityWindow("sat,sun 00:00-24:00")
+	if err != nil {
+		t.Fatalf("unexpected error parsing window: %v", err)
+	}
+	overnight, err := ParseAvailabilityWindow("fri 22:00-06:00")
+	if err != nil {
+		t.Fatalf("unexpected error parsing window: %v", err)
+	}
+
+	// 2024-06-05 is a Wednesday
+	wednesday := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC)
+	earlySaturday := time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		windows   []AvailabilityWindow
+		at        time.Time
+		available bool
+		next      time.Time
+	}{
+		{"No windows is always available", nil, wednesday, true, wednesday},
+		{"Inside the weekend", []AvailabilityWindow{weekend}, saturday, true, saturday},
+		{"Waiting for the weekend", []AvailabilityWindow{weekend}, wednesday, false, time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)},
+		{"Inside a window that started yesterday", []AvailabilityWindow{overnight}, earlySaturday, true, earlySaturday},
+		{"Soonest of several windows", []AvailabilityWindow{weekend, overnight}, wednesday, false, time.Date(2024, 6, 7, 22, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsAvailable(tc.windows, tc.at); got != tc.available {
+				t.Errorf("expected available=%t, got %t", tc.available, got)
+			}
+			next, ok := NextAvailability(tc.windows, tc.at)
+			if !ok || !next.Equal(tc.next) {
+				t.Errorf("expected next availability %s, got %s", tc.next, next)
+			}
+		})
+	}
+
+	if _, err := ParseAvailabilityWindow("someday 00:00-24:00"); err == nil {
+		t.Error("expected an error for an invalid day")
+	}
+}
+
+func TestAttributeRequirements(t *testing.T) {
+	schema, err := NewAttributeSchema(map[string]string{"infiniband": "bool", "gpu.count": "number"})
+	if err != nil {
+		t.Fatalf("unexpected error building schema: %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		operator   RequirementOperator
+		valid      bool
+	}{
+		{expression: "infiniband", operator: RequireExists, valid: true},
+		{expression: "!infiniband", operator: RequireNotExists, valid: true},
+		{expression: "gpu.count>=2", operator: RequireGreaterEq, valid: true},
+		{expression: "gpu.count!=2", operator: RequireNotEqual, valid: true},
+		{expression: "gpu.count=lots", operator: RequireEqual, valid: false},
+		{expression: "nvlink", operator: RequireExists, valid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expression, func(t *testing.T) {
+			requirement, err := ParseAttributeRequirement(tc.expression)
+			if err != nil {
+				t.Fatalf("unexpected error parsing: %v", err)
+			}
+			if requirement.Operator != tc.operator {
+				t.Errorf("expected operator %s, got %s", tc.operator, requirement.Operator)
+			}
+			err = schema.ValidateRequirements([]AttributeRequirement{requirement})
+			if (err == nil) != tc.valid {
+				t.Errorf("expected valid to be %v, got error %v", tc.valid, err)
+			}
+		})
+	}
+
+	if err := schema.ValidateAttributes(map[string]string{"infiniband": "maybe"}); err == nil {
+		t.Error("expected a bool attribute with a bad value to fail validation")
+	}
+}
+
 func generateCID(t *rapid.T) string {
 	bytes := rapid.SliceOfN(rapid.Byte(), 32, 32).Draw(t, "bytes")
 	return "Qm" + base58.Encode(bytes)
 }
+
+func TestCronSchedule(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 10, 7, 30, 0, time.UTC) // a friday
+	testCases := []struct {
+		schedule string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.March, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2024, time.March, 4, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		schedule, err := ParseCronSchedule(tc.schedule)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.schedule, err)
+		}
+		if next := schedule.Next(from); !next.Equal(tc.expected) {
+			t.Errorf("%s: expected %s, got %s", tc.schedule, tc.expected, next)
+		}
+	}
+
+	for _, invalid := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCronSchedule(invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestDealTransactionsReorged(t *testing.T) {
+	txs := DealTransactions{
+		JobCreator:       DealTransactionsJobCreator{Agree: "0xaa"},
+		ResourceProvider: DealTransactionsResourceProvider{Agree: "0xbb", AddResult: "0xcc"},
+	}
+	update, found := txs.Reorged("0xCC")
+	if !found {
+		t.Fatal("expected the add result transaction to be found")
+	}
+	expected := []DealTransactionStatus{{Action: "add_result", Hash: "0xcc", Status: DealTransactionReorged}}
+	if len(update.ResourceProvider.Stuck) != 1 || update.ResourceProvider.Stuck[0] != expected[0] {
+		t.Errorf("expected %+v, got %+v", expected, update.ResourceProvider.Stuck)
+	}
+	if len(update.JobCreator.Stuck) != 0 || len(update.Mediator.Stuck) != 0 {
+		t.Errorf("expected only the resource provider to be updated, got %+v", update)
+	}
+	if _, found := txs.Reorged("0xdd"); found {
+		t.Error("expected no transaction for an unknown hash")
+	}
+}
+
+func TestTokenUnits(t *testing.T) {
+	if got := TokenUnits(3, DefaultTokenDecimals).String(); got != "3000000000000000000" {
+		t.Errorf("expected 3 tokens with 18 decimals to be 3e18, got %s", got)
+	}
+	if got := TokenUnits(3, 6).String(); got != "3000000" {
+		t.Errorf("expected 3 tokens with 6 decimals to be 3e6, got %s", got)
+	}
+}
+
+func TestJobPricing(t *testing.T) {
+	offer := ResourceOffer{
+		DefaultPricing: DealPricing{InstructionPrice: 1, PaymentCollateral: 100},
+		ResourcePricing: &ResourcePricing{
+			GPUHour:   100,
+			CPUHour:   10,
+			RAMGBHour: 2,
+			EgressGB:  5,
+		},
+		TokenPricing: map[string]DealPricing{"0xabc": {InstructionPrice: 7}},
+	}
+	offer.DefaultTimeouts.SubmitResults.Timeout = 3600
+	job := JobOffer{
+		Spec:     MachineSpec{GPU: 1000, CPU: 2000, RAM: 4096},
+		Duration: 1800,
+		Egress:   2048,
+	}
+
+	// half an hour of (100 + 2 * 10 + 4 * 2) plus 2 GB out at 5 and the
+	// instruction price
+	pricing, ok := offer.JobPricing(job)
+	if !ok || pricing.InstructionPrice != 75 || pricing.PaymentCollateral != 100 {
+		t.Errorf("expected the job to cost 75 with the default collateral, got %+v", pricing)
+	}
+
+	// no duration is charged for the whole submit results timeout
+	job.Duration = 0
+	job.Egress = 0
+	if pricing, _ := offer.JobPricing(job); pricing.InstructionPrice != 129 {
+		t.Errorf("expected an hour of the spec to cost 129, got %d", pricing.InstructionPrice)
+	}
+
+	// part of a unit is rounded up
+	if cost := (ResourcePricing{CPUHour: 1}).Cost(MachineSpec{CPU: 1}, 1, 0); cost != 1 {
+		t.Errorf("expected a milli-cpu second to cost 1, got %d", cost)
+	}
+	if cost := (ResourcePricing{GPUHour: math.MaxUint64}).Cost(MachineSpec{GPU: 2000}, 3600, 0); cost != math.MaxUint64 {
+		t.Errorf("expected an overflowing cost to be capped, got %d", cost)
+	}
+
+	// resource prices are in the network's token
+	job.PaymentToken = "0xABC"
+	if pricing, ok := offer.JobPricing(job); !ok || pricing.InstructionPrice != 7 {
+		t.Errorf("expected only the token's instruction price, got %+v", pricing)
+	}
+
+	offer.ResourcePricing = nil
+	job.PaymentToken = ""
+	if pricing, _ := offer.JobPricing(job); pricing.InstructionPrice != 1 {
+		t.Errorf("expected an offer without resource pricing to charge per instruction, got %d", pricing.InstructionPrice)
+	}
+}
@@ -23,6 +23,7 @@ var AgreementState = []string{
 	"ResultsChecked",
 	"MediationAccepted",
 	"MediationRejected",
+	"TimeoutAgree",
 	"TimeoutSubmitResults",
 	"TimeoutJudgeResults",
 	"TimeoutMediateResults",
@@ -83,8 +84,12 @@ func IsActiveAgreementState(itemType uint8) bool {
 	return itemType == GetAgreementStateIndex("DealNegotiating") || itemType == GetAgreementStateIndex("DealAgreed")
 }
 
+func IsTimeoutAgreementState(itemType uint8) bool {
+	return itemType == GetAgreementStateIndex("TimeoutAgree") || itemType == GetAgreementStateIndex("TimeoutSubmitResults") || itemType == GetAgreementStateIndex("TimeoutJudgeResults") || itemType == GetAgreementStateIndex("TimeoutMediateResults")
+}
+
 func IsTerminalAgreementState(itemType uint8) bool {
-	return itemType == GetAgreementStateIndex("JobOfferCancelled") || itemType == GetAgreementStateIndex("ResultsAccepted") || itemType == GetAgreementStateIndex("MediationAccepted") || itemType == GetAgreementStateIndex("MediationRejected")
+	return IsTimeoutAgreementState(itemType) || itemType == GetAgreementStateIndex("JobOfferCancelled") || itemType == GetAgreementStateIndex("ResultsAccepted") || itemType == GetAgreementStateIndex("MediationAccepted") || itemType == GetAgreementStateIndex("MediationRejected")
 }
 
 // GetPaymentReason corresponds to getPaymentReason in TypeScript
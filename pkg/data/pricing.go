@@ -0,0 +1,59 @@
+package data
+
+import (
+	"math"
+	"math/big"
+)
+
+// how many seconds the resource offer charges the job for, the job offer's
+// duration or the whole submit results timeout when it gives none
+func JobDuration(resourceOffer ResourceOffer, jobOffer JobOffer) int {
+	if jobOffer.Duration > 0 {
+		return jobOffer.Duration
+	}
+	return int(resourceOffer.DefaultTimeouts.SubmitResults.Timeout)
+}
+
+// what the machine the job offer asks for costs over seconds, rounded up
+// so a small job is never free, the spec is in milli-cpus, milli-gpus and
+// MB and egress is in MB
+func (pricing ResourcePricing) Cost(spec MachineSpec, seconds int, egress int) uint64 {
+	hours := big.NewRat(int64(max(seconds, 0)), 3600) //nolint:gomnd
+	perHour := func(amount int, unit int64, price uint64) *big.Rat {
+		used := big.NewRat(int64(max(amount, 0)), unit)
+		return used.Mul(used, new(big.Rat).SetInt(new(big.Int).SetUint64(price)))
+	}
+	total := new(big.Rat)
+	total.Add(total, perHour(spec.GPU, 1000, pricing.GPUHour))   //nolint:gomnd
+	total.Add(total, perHour(spec.CPU, 1000, pricing.CPUHour))   //nolint:gomnd
+	total.Add(total, perHour(spec.RAM, 1024, pricing.RAMGBHour)) //nolint:gomnd
+	total.Mul(total, hours)
+	total.Add(total, perHour(egress, 1024, pricing.EgressGB)) //nolint:gomnd
+
+	cost, remainder := new(big.Int).QuoRem(total.Num(), total.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		cost.Add(cost, big.NewInt(1))
+	}
+	if !cost.IsUint64() {
+		return math.MaxUint64
+	}
+	return cost.Uint64()
+}
+
+// what the resource offer charges for the job offer in its payment token,
+// with resource pricing the instruction price is the whole job's price as
+// jobs are one instruction, resource pricing is in the network's token so
+// jobs paid in other tokens only pay the instruction price
+func (offer ResourceOffer) JobPricing(jobOffer JobOffer) (DealPricing, bool) {
+	pricing, ok := offer.PricingIn(jobOffer.PaymentToken)
+	if !ok || offer.ResourcePricing == nil || jobOffer.PaymentToken != "" {
+		return pricing, ok
+	}
+	cost := offer.ResourcePricing.Cost(jobOffer.Spec, JobDuration(offer, jobOffer), jobOffer.Egress)
+	if cost > math.MaxUint64-pricing.InstructionPrice {
+		pricing.InstructionPrice = math.MaxUint64
+	} else {
+		pricing.InstructionPrice += cost
+	}
+	return pricing, true
+}
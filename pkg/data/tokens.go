@@ -0,0 +1,35 @@
+package data
+
+import (
+	"math/big"
+	"strings"
+)
+
+// the network's token is an 18 decimal ERC-20 like ether
+const DefaultTokenDecimals uint8 = 18
+
+// token addresses are compared without their checksum case, empty is the
+// network's token
+func SamePaymentToken(a string, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// what the resource offer charges when paid in the token, false when it has
+// no pricing in that token
+func (offer ResourceOffer) PricingIn(token string) (DealPricing, bool) {
+	if token == "" {
+		return offer.DefaultPricing, true
+	}
+	for address, pricing := range offer.TokenPricing {
+		if SamePaymentToken(address, token) {
+			return pricing, true
+		}
+	}
+	return DealPricing{}, false
+}
+
+// a whole number of tokens in the token's smallest unit
+func TokenUnits(amount uint64, decimals uint8) *big.Int {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Int).Mul(new(big.Int).SetUint64(amount), scale)
+}
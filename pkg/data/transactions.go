@@ -0,0 +1,97 @@
+package data
+
+import (
+	"slices"
+	"strings"
+)
+
+// an update only sets the hashes it has, the others are left as they were
+func mergeHash(current string, update string) string {
+	if update != "" {
+		return update
+	}
+	return current
+}
+
+// the stuck transactions are only ever added to, one entry per hash
+func mergeStuck(current []DealTransactionStatus, update []DealTransactionStatus) []DealTransactionStatus {
+	merged := slices.Clone(current)
+	for _, status := range update {
+		index := slices.IndexFunc(merged, func(existing DealTransactionStatus) bool { return existing.Hash == status.Hash })
+		if index >= 0 {
+			merged[index] = status
+		} else {
+			merged = append(merged, status)
+		}
+	}
+	return merged
+}
+
+func (txs DealTransactionsJobCreator) Merge(update DealTransactionsJobCreator) DealTransactionsJobCreator {
+	return DealTransactionsJobCreator{
+		Agree:                mergeHash(txs.Agree, update.Agree),
+		AcceptResult:         mergeHash(txs.AcceptResult, update.AcceptResult),
+		CheckResult:          mergeHash(txs.CheckResult, update.CheckResult),
+		TimeoutAgree:         mergeHash(txs.TimeoutAgree, update.TimeoutAgree),
+		TimeoutSubmitResult:  mergeHash(txs.TimeoutSubmitResult, update.TimeoutSubmitResult),
+		TimeoutMediateResult: mergeHash(txs.TimeoutMediateResult, update.TimeoutMediateResult),
+		Stuck:                mergeStuck(txs.Stuck, update.Stuck),
+	}
+}
+
+func (txs DealTransactionsResourceProvider) Merge(update DealTransactionsResourceProvider) DealTransactionsResourceProvider {
+	return DealTransactionsResourceProvider{
+		Agree:                mergeHash(txs.Agree, update.Agree),
+		AddResult:            mergeHash(txs.AddResult, update.AddResult),
+		TimeoutAgree:         mergeHash(txs.TimeoutAgree, update.TimeoutAgree),
+		TimeoutJudgeResult:   mergeHash(txs.TimeoutJudgeResult, update.TimeoutJudgeResult),
+		TimeoutMediateResult: mergeHash(txs.TimeoutMediateResult, update.TimeoutMediateResult),
+		Stuck:                mergeStuck(txs.Stuck, update.Stuck),
+	}
+}
+
+func (txs DealTransactionsMediator) Merge(update DealTransactionsMediator) DealTransactionsMediator {
+	return DealTransactionsMediator{
+		MediationAcceptResult: mergeHash(txs.MediationAcceptResult, update.MediationAcceptResult),
+		MediationRejectResult: mergeHash(txs.MediationRejectResult, update.MediationRejectResult),
+		Stuck:                 mergeStuck(txs.Stuck, update.Stuck),
+	}
+}
+
+// a stuck entry for the action whose hash it is, nil when none of them are
+func reorgedStatus(actions map[string]string, hash string) []DealTransactionStatus {
+	for action, actionHash := range actions {
+		if actionHash != "" && strings.EqualFold(actionHash, hash) {
+			return []DealTransactionStatus{{Action: action, Hash: actionHash, Status: DealTransactionReorged}}
+		}
+	}
+	return nil
+}
+
+// updates that mark the transaction as dropped by a reorg for the role that
+// sent it, false when it is not one of the deal's
+func (txs DealTransactions) Reorged(hash string) (DealTransactions, bool) {
+	update := DealTransactions{
+		JobCreator: DealTransactionsJobCreator{Stuck: reorgedStatus(map[string]string{
+			"agree":                  txs.JobCreator.Agree,
+			"accept_result":          txs.JobCreator.AcceptResult,
+			"check_result":           txs.JobCreator.CheckResult,
+			"timeout_agree":          txs.JobCreator.TimeoutAgree,
+			"timeout_submit_result":  txs.JobCreator.TimeoutSubmitResult,
+			"timeout_mediate_result": txs.JobCreator.TimeoutMediateResult,
+		}, hash)},
+		ResourceProvider: DealTransactionsResourceProvider{Stuck: reorgedStatus(map[string]string{
+			"agree":                  txs.ResourceProvider.Agree,
+			"add_result":             txs.ResourceProvider.AddResult,
+			"timeout_agree":          txs.ResourceProvider.TimeoutAgree,
+			"timeout_judge_result":   txs.ResourceProvider.TimeoutJudgeResult,
+			"timeout_mediate_result": txs.ResourceProvider.TimeoutMediateResult,
+		}, hash)},
+		Mediator: DealTransactionsMediator{Stuck: reorgedStatus(map[string]string{
+			"mediation_accept_result": txs.Mediator.MediationAcceptResult,
+			"mediation_reject_result": txs.Mediator.MediationRejectResult,
+		}, hash)},
+	}
+	found := len(update.JobCreator.Stuck) > 0 || len(update.ResourceProvider.Stuck) > 0 || len(update.Mediator.Stuck) > 0
+	return update, found
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"path"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
@@ -33,6 +34,24 @@ func GetJobOfferID(offer JobOffer) (string, error) {
 	return CalculateCID(offer)
 }
 
+// the ID shared by every run of a recurring job
+// the first run's timestamp keeps two series of the same job apart
+func GetSeriesID(offer JobOffer, schedule string) (string, error) {
+	return CalculateCID(struct {
+		JobCreator string            `json:"job_creator"`
+		Module     ModuleConfig      `json:"module"`
+		Inputs     map[string]string `json:"inputs"`
+		Schedule   string            `json:"schedule"`
+		CreatedAt  int               `json:"created_at"`
+	}{
+		JobCreator: offer.JobCreator,
+		Module:     offer.Module,
+		Inputs:     offer.Inputs,
+		Schedule:   schedule,
+		CreatedAt:  offer.CreatedAt,
+	})
+}
+
 func GetJobOfferContainerIDs(jobOffers []JobOfferContainer) []string {
 	var ids []string
 	for _, offer := range jobOffers {
@@ -79,6 +98,49 @@ func GetModuleID(module ModuleConfig) (string, error) {
 	return CalculateCID(module)
 }
 
+// check a module against a resource provider's allowlist
+// entries can be a module ID, a repo to allow every version
+// or "repo@hash" to allow a single version
+// any of them can be a pattern as path.Match takes them
+// e.g. "https://github.com/acme/*" or "https://github.com/acme/sdxl@v1.*"
+// an empty allowlist means ALL modules
+func IsModuleAllowed(allowlist []string, module ModuleConfig, moduleID string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	return matchesModule(allowlist, module, moduleID)
+}
+
+// check a module against a resource provider's denylist
+// the entries are the same as the allowlist's
+// and a module that is denied is never run even when it is allowed
+func IsModuleDenied(denylist []string, module ModuleConfig, moduleID string) bool {
+	return matchesModule(denylist, module, moduleID)
+}
+
+func matchesModule(patterns []string, module ModuleConfig, moduleID string) bool {
+	names := []string{moduleID, module.Repo, module.Repo + "@" + module.Hash}
+	for _, pattern := range patterns {
+		for _, name := range names {
+			// a bad pattern is caught when the options are checked
+			if matched, _ := path.Match(pattern, name); matched || pattern == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// an error for an allowlist or denylist entry that is not a valid pattern
+func CheckModulePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%q is not a valid module pattern: %s", pattern, err.Error())
+		}
+	}
+	return nil
+}
+
 func GetMutualServices(a []string, b []string) []string {
 	mutual := []string{}
 	for _, aParty := range a {
@@ -104,6 +166,11 @@ func GetDeal(
 		return Deal{}, fmt.Errorf("no mutual solver")
 	}
 
+	pricing, ok := resourceOffer.JobPricing(jobOffer)
+	if !ok {
+		return Deal{}, fmt.Errorf("resource offer is not priced in %s", jobOffer.PaymentToken)
+	}
+
 	dealData := Deal{
 		Members: DealMembers{
 			Solver:           jobOffer.Services.Solver,
@@ -113,12 +180,13 @@ func GetDeal(
 		},
 		// TODO: this assumes marketing pricing for the client
 		// this should be configurable
-		Pricing: resourceOffer.DefaultPricing,
+		Pricing: pricing,
 		// TODO: this assumes resource provider timeouts
 		// this should be configurable
 		Timeouts:      resourceOffer.DefaultTimeouts,
 		JobOffer:      jobOffer,
 		ResourceOffer: resourceOffer,
+		PaymentToken:  jobOffer.PaymentToken,
 	}
 
 	id, err := GetDealID(dealData)
@@ -182,6 +250,12 @@ func CheckResourceOffer(resourceOffer ResourceOffer) error {
 		return fmt.Errorf("resource offer must have at least one trusted mediator")
 	}
 
+	for token := range resourceOffer.TokenPricing {
+		if !common.IsHexAddress(token) {
+			return fmt.Errorf("resource offer token pricing %s is not a token address", token)
+		}
+	}
+
 	return nil
 }
 
@@ -194,6 +268,10 @@ func CheckJobOffer(jobOffer JobOffer) error {
 		return fmt.Errorf("job offer must have at least one trusted mediator")
 	}
 
+	if jobOffer.PaymentToken != "" && !common.IsHexAddress(jobOffer.PaymentToken) {
+		return fmt.Errorf("job offer payment token %s is not a token address", jobOffer.PaymentToken)
+	}
+
 	return nil
 }
 
@@ -233,10 +311,11 @@ func EtherToWei(etherAmount float64) *big.Int {
 func ConvertDealTimeout(
 	timeout DealTimeout,
 	withCollateral bool,
+	decimals uint8,
 ) controller.SharedStructsDealTimeout {
-	collateral := EtherToWei(0)
+	collateral := big.NewInt(0)
 	if withCollateral {
-		collateral = EtherToWei(float64(timeout.Collateral))
+		collateral = TokenUnits(timeout.Collateral, decimals)
 	}
 	return controller.SharedStructsDealTimeout{
 		Timeout:    big.NewInt(int64(timeout.Timeout)),
@@ -246,22 +325,25 @@ func ConvertDealTimeout(
 
 func ConvertDealTimeouts(
 	timeouts DealTimeouts,
+	decimals uint8,
 ) controller.SharedStructsDealTimeouts {
 	return controller.SharedStructsDealTimeouts{
-		Agree:          ConvertDealTimeout(timeouts.Agree, false),
-		SubmitResults:  ConvertDealTimeout(timeouts.SubmitResults, true),
-		JudgeResults:   ConvertDealTimeout(timeouts.JudgeResults, true),
-		MediateResults: ConvertDealTimeout(timeouts.MediateResults, false),
+		Agree:          ConvertDealTimeout(timeouts.Agree, false, decimals),
+		SubmitResults:  ConvertDealTimeout(timeouts.SubmitResults, true, decimals),
+		JudgeResults:   ConvertDealTimeout(timeouts.JudgeResults, true, decimals),
+		MediateResults: ConvertDealTimeout(timeouts.MediateResults, false, decimals),
 	}
 }
 
+// the prices are in whole tokens and go on chain in the token's smallest unit
 func ConvertDealPricing(
 	pricing DealPricing,
+	decimals uint8,
 ) controller.SharedStructsDealPricing {
 	return controller.SharedStructsDealPricing{
-		InstructionPrice:          EtherToWei(float64(pricing.InstructionPrice)),
-		PaymentCollateral:         EtherToWei(float64(pricing.PaymentCollateral)),
+		InstructionPrice:          TokenUnits(pricing.InstructionPrice, decimals),
+		PaymentCollateral:         TokenUnits(pricing.PaymentCollateral, decimals),
 		ResultsCollateralMultiple: big.NewInt(int64(pricing.ResultsCollateralMultiple)),
-		MediationFee:              EtherToWei(float64(pricing.MediationFee)),
+		MediationFee:              TokenUnits(pricing.MediationFee, decimals),
 	}
 }
@@ -0,0 +1,405 @@
+package devnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/jobcreator"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/mediation"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/payments"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/pow"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/storage"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/token"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/users"
+	"github.com/rs/zerolog/log"
+)
+
+// the hardhat test key hardhat/utils/accounts.ts deploys with, the local
+// chain funds it at startup
+const DefaultAdminKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// the ws port the chain in docker/docker-compose.base.yml serves on
+const DefaultRpcURL = "ws://localhost:8548"
+
+// the accounts from hardhat/utils/accounts.ts other than the admin, all
+// of them hardhat test keys so the stack scripts and this agree
+var DefaultAccounts = []Account{
+	{Name: "faucet", Address: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"},
+	{Name: "solver", Address: "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"},
+	{Name: "mediator", Address: "0x90F79bf6EB2c4f870365E785982E1f101E93b906"},
+	{Name: "resource_provider", Address: "0x15d34AAf54267DB7D7c367839AAf71A00a2C6A65"},
+	{Name: "job_creator", Address: "0x9965507D1a55bcC2695C58ba16FB37d819B0A4dc"},
+	{Name: "directory", Address: "0x976EA74026E726554dB657fA54763abd0C3a0aa9"},
+	{Name: "user", Address: "0x1da99b9e884C9e7B15361957577978c1fa66AfBb"},
+}
+
+type Account struct {
+	Name    string
+	Address string
+}
+
+type Options struct {
+	RpcURL string
+	// hex, with or without 0x
+	AdminKey string
+	// funded with Ether of the chain's coin and Tokens of the token each,
+	// the one named solver and the one named mediator go in the profile
+	Accounts []Account
+	Ether    float64
+	Tokens   float64
+	// a billion tokens go to the admin when the token is deployed
+	TokenSupply float64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		RpcURL:      DefaultRpcURL,
+		AdminKey:    DefaultAdminKey,
+		Accounts:    DefaultAccounts,
+		Ether:       10,         //nolint:gomnd
+		Tokens:      100000,     //nolint:gomnd
+		TokenSupply: 1000000000, //nolint:gomnd
+	}
+}
+
+type Deployment struct {
+	RpcURL    string
+	ChainID   uint64
+	Addresses web3.ContractAddresses
+	Solver    string
+	Mediators []string
+}
+
+// deploys and links the contracts the same way hardhat/deploy does and
+// funds the accounts, the proxies hardhat puts in front of pow are left
+// out as nothing on a local chain is ever upgraded
+func Up(ctx context.Context, options Options) (*Deployment, error) {
+	client, err := ethclient.DialContext(ctx, options.RpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", options.RpcURL, err)
+	}
+	defer client.Close()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading chain id: %w", err)
+	}
+	adminKey, err := web3.ParsePrivateKey(options.AdminKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing admin key: %w", err)
+	}
+	deployer, err := newDeployer(ctx, client, adminKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msgf("deploying the contracts to chain %d from %s", chainID, deployer.opts.From.Hex())
+
+	addresses, err := deployer.deployContracts(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := deployer.fundAccounts(options, addresses.Token); err != nil {
+		return nil, err
+	}
+
+	deployment := &Deployment{
+		RpcURL:    options.RpcURL,
+		ChainID:   chainID.Uint64(),
+		Addresses: addresses,
+	}
+	for _, account := range options.Accounts {
+		switch account.Name {
+		case "solver":
+			deployment.Solver = account.Address
+		case "mediator":
+			deployment.Mediators = append(deployment.Mediators, account.Address)
+		}
+	}
+	return deployment, nil
+}
+
+type deployer struct {
+	ctx    context.Context
+	client *ethclient.Client
+	opts   *bind.TransactOpts
+}
+
+func newDeployer(ctx context.Context, client *ethclient.Client, adminKey *ecdsa.PrivateKey, chainID *big.Int) (*deployer, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(adminKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	return &deployer{ctx: ctx, client: client, opts: opts}, nil
+}
+
+// each transaction is waited for so the bindings get the next nonce
+func (deployer *deployer) wait(name string, tx *types.Transaction, err error) error {
+	if err != nil {
+		return fmt.Errorf("error sending %s: %w", name, err)
+	}
+	receipt, err := bind.WaitMined(deployer.ctx, deployer.client, tx)
+	if err != nil {
+		return fmt.Errorf("error waiting for %s: %w", name, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%s reverted in %s", name, tx.Hash().Hex())
+	}
+	log.Debug().Str("tx", tx.Hash().Hex()).Msgf("%s", name)
+	return nil
+}
+
+func (deployer *deployer) deployed(name string, address common.Address, tx *types.Transaction, err error) error {
+	if err := deployer.wait("deploy "+name, tx, err); err != nil {
+		return err
+	}
+	log.Info().Msgf("deployed %s at %s", name, address.Hex())
+	return nil
+}
+
+func (deployer *deployer) deployContracts(options Options) (web3.ContractAddresses, error) {
+	opts := deployer.opts
+	addresses := web3.ContractAddresses{}
+
+	var (
+		tokenContract      *token.Token
+		paymentsContract   *payments.Payments
+		storageContract    *storage.Storage
+		usersContract      *users.Users
+		mediationContract  *mediation.Mediation
+		jobCreatorContract *jobcreator.Jobcreator
+		powContract        *pow.Pow
+		controllerContract *controller.Controller
+		tx                 *types.Transaction
+		err                error
+	)
+	solver := common.Address{}
+	for _, account := range options.Accounts {
+		if account.Name == "solver" {
+			solver = common.HexToAddress(account.Address)
+		}
+	}
+
+	addresses.Token, tx, tokenContract, err = token.DeployToken(opts, deployer.client, "Lilypad Token", "LP", tokenUnits(options.TokenSupply))
+	if err := deployer.deployed("LilypadToken", addresses.Token, tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.Payments, tx, paymentsContract, err = payments.DeployPayments(opts, deployer.client)
+	if err := deployer.deployed("LilypadPayments", addresses.Payments, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = paymentsContract.Initialize(opts, addresses.Token)
+	if err := deployer.wait("LilypadPayments.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = tokenContract.SetControllerAddress(opts, addresses.Payments)
+	if err := deployer.wait("LilypadToken.setControllerAddress", tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.Storage, tx, storageContract, err = storage.DeployStorage(opts, deployer.client)
+	if err := deployer.deployed("LilypadStorage", addresses.Storage, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = storageContract.Initialize(opts)
+	if err := deployer.wait("LilypadStorage.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.Users, tx, usersContract, err = users.DeployUsers(opts, deployer.client)
+	if err := deployer.deployed("LilypadUsers", addresses.Users, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = usersContract.Initialize(opts)
+	if err := deployer.wait("LilypadUsers.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.Mediation, tx, mediationContract, err = mediation.DeployMediation(opts, deployer.client)
+	if err := deployer.deployed("LilypadMediationRandom", addresses.Mediation, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = mediationContract.Initialize(opts)
+	if err := deployer.wait("LilypadMediationRandom.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.JobCreator, tx, jobCreatorContract, err = jobcreator.DeployJobcreator(opts, deployer.client)
+	if err := deployer.deployed("LilypadOnChainJobCreator", addresses.JobCreator, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = jobCreatorContract.Initialize(opts, addresses.Token)
+	if err := deployer.wait("LilypadOnChainJobCreator.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+	// the solver pulls the on chain jobs so it is the job creator's controller
+	tx, err = jobCreatorContract.SetControllerAddress(opts, solver)
+	if err := deployer.wait("LilypadOnChainJobCreator.setControllerAddress", tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.Pow, tx, powContract, err = pow.DeployPow(opts, deployer.client)
+	if err := deployer.deployed("LilypadPow", addresses.Pow, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = powContract.Initialize(opts)
+	if err := deployer.wait("LilypadPow.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+
+	addresses.Controller, tx, controllerContract, err = controller.DeployController(opts, deployer.client)
+	if err := deployer.deployed("LilypadController", addresses.Controller, tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = controllerContract.Initialize(opts, addresses.Storage, addresses.Users, addresses.Payments, addresses.Mediation, addresses.JobCreator, addresses.Pow)
+	if err := deployer.wait("LilypadController.initialize", tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = storageContract.SetControllerAddress(opts, addresses.Controller)
+	if err := deployer.wait("LilypadStorage.setControllerAddress", tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = paymentsContract.SetControllerAddress(opts, addresses.Controller)
+	if err := deployer.wait("LilypadPayments.setControllerAddress", tx, err); err != nil {
+		return addresses, err
+	}
+	tx, err = mediationContract.SetControllerAddress(opts, addresses.Controller)
+	if err := deployer.wait("LilypadMediationRandom.setControllerAddress", tx, err); err != nil {
+		return addresses, err
+	}
+	return addresses, nil
+}
+
+func (deployer *deployer) fundAccounts(options Options, tokenAddress common.Address) error {
+	tokenContract, err := token.NewToken(tokenAddress, deployer.client)
+	if err != nil {
+		return err
+	}
+	ether := web3.EtherToWei(options.Ether)
+	tokens := tokenUnits(options.Tokens)
+	for _, account := range options.Accounts {
+		address := common.HexToAddress(account.Address)
+		if options.Ether > 0 {
+			// a transfer to an account with no code is a plain send
+			opts := *deployer.opts
+			opts.Value = ether
+			tx, err := bind.NewBoundContract(address, abi.ABI{}, nil, deployer.client, nil).Transfer(&opts)
+			if err := deployer.wait("ether for "+account.Name, tx, err); err != nil {
+				return err
+			}
+		}
+		if options.Tokens > 0 {
+			tx, err := tokenContract.Transfer(deployer.opts, address, tokens)
+			if err := deployer.wait("tokens for "+account.Name, tx, err); err != nil {
+				return err
+			}
+		}
+		log.Info().Msgf("funded %s (%s) with %g ether and %g tokens", account.Name, account.Address, options.Ether, options.Tokens)
+	}
+	return nil
+}
+
+// the token has 18 decimals like ether, worked out at a precision that
+// keeps the billion token supply exact where EtherToWei would round it
+func tokenUnits(amount float64) *big.Int {
+	decimals := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) //nolint:gomnd
+	units := new(big.Float).SetPrec(256).SetFloat64(amount)           //nolint:gomnd
+	units.Mul(units, new(big.Float).SetPrec(256).SetInt(decimals))    //nolint:gomnd
+	result, _ := units.Int(nil)
+	return result
+}
+
+// only the parts of a network profile the deployment decides, everything
+// else is left to the defaults
+type profile struct {
+	Network  profileNetwork  `toml:"network"`
+	Token    profileToken    `toml:"token"`
+	Services profileServices `toml:"services"`
+	Web3     profileWeb3     `toml:"web3"`
+	IPFS     profileIPFS     `toml:"ipfs"`
+}
+
+type profileNetwork struct {
+	Description string `toml:"description"`
+}
+
+type profileToken struct {
+	Name     string `toml:"name"`
+	Symbol   string `toml:"symbol"`
+	Decimals int    `toml:"decimals"`
+}
+
+type profileServices struct {
+	Solver   string   `toml:"solver"`
+	Mediator []string `toml:"mediator"`
+	APIHost  string   `toml:"api_host"`
+}
+
+type profileWeb3 struct {
+	RpcURL            string `toml:"rpc_url"`
+	ChainID           uint64 `toml:"chain_id"`
+	ControllerAddress string `toml:"controller_address"`
+	PaymentsAddress   string `toml:"payments_address"`
+	StorageAddress    string `toml:"storage_address"`
+	UsersAddress      string `toml:"users_address"`
+	TokenAddress      string `toml:"token_address"`
+	MediationAddress  string `toml:"mediation_address"`
+	JobCreatorAddress string `toml:"jobcreator_address"`
+	PowAddress        string `toml:"pow_address"`
+}
+
+type profileIPFS struct {
+	Addr string `toml:"addr"`
+}
+
+// writes the deployment as the network profile name in dir so every
+// command can be pointed at it with --network name
+func (deployment *Deployment) WriteProfile(dir string, name string) (string, error) {
+	addresses := deployment.Addresses
+	config := profile{
+		Network: profileNetwork{Description: fmt.Sprintf("a local devnet from lilypad devnet up on %s", deployment.RpcURL)},
+		Token:   profileToken{Name: "Lilypad", Symbol: "LP", Decimals: 18}, //nolint:gomnd
+		Services: profileServices{
+			Solver:   deployment.Solver,
+			Mediator: deployment.Mediators,
+		},
+		Web3: profileWeb3{
+			RpcURL:            deployment.RpcURL,
+			ChainID:           deployment.ChainID,
+			ControllerAddress: addresses.Controller.Hex(),
+			PaymentsAddress:   addresses.Payments.Hex(),
+			StorageAddress:    addresses.Storage.Hex(),
+			UsersAddress:      addresses.Users.Hex(),
+			TokenAddress:      addresses.Token.Hex(),
+			MediationAddress:  addresses.Mediation.Hex(),
+			JobCreatorAddress: addresses.JobCreator.Hex(),
+			PowAddress:        addresses.Pow.Hex(),
+		},
+		IPFS: profileIPFS{Addr: "/ip4/127.0.0.1/tcp/5001"},
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gomnd
+		return "", err
+	}
+	path := filepath.Join(dir, name+".toml")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if err := toml.NewEncoder(file).Encode(config); err != nil {
+		return "", fmt.Errorf("error writing network profile %s: %w", path, err)
+	}
+	return path, nil
+}
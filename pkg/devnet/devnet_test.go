@@ -0,0 +1,52 @@
+//go:build unit
+
+package devnet
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lilypad-tech/lilypad/pkg/options"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LILYPAD_NETWORKS_DIR", dir)
+	deployment := &Deployment{
+		RpcURL:  DefaultRpcURL,
+		ChainID: 412346,
+		Addresses: web3.ContractAddresses{
+			Controller: common.HexToAddress("0xa85233C63b9Ee964Add6F2cffe00Fd84eb32338f"),
+			Token:      common.HexToAddress("0xa513E6E4b8f2a923D98304ec87F64353C4D5C853"),
+		},
+		Solver:    DefaultAccounts[1].Address,
+		Mediators: []string{DefaultAccounts[2].Address},
+	}
+	_, err := deployment.WriteProfile(dir, "local")
+	require.NoError(t, err)
+
+	// the profile is read back the same way --network local would read it
+	networks, err := options.ListNetworks()
+	require.NoError(t, err)
+	var local *options.Network
+	for i := range networks {
+		if networks[i].Name == "local" {
+			local = &networks[i]
+		}
+	}
+	require.NotNil(t, local)
+	config := local.Config
+	assert.Equal(t, DefaultRpcURL, config.Web3.RpcURL)
+	assert.Equal(t, 412346, config.Web3.ChainID)
+	assert.Equal(t, "0xa85233C63b9Ee964Add6F2cffe00Fd84eb32338f", config.Web3.ControllerAddress)
+	assert.Equal(t, DefaultAccounts[1].Address, config.ServiceConfig.Solver)
+	assert.Equal(t, []string{DefaultAccounts[2].Address}, config.ServiceConfig.Mediator)
+}
+
+func TestTokenUnits(t *testing.T) {
+	assert.Equal(t, "1000000000000000000000000000", tokenUnits(1000000000).String())
+	assert.Equal(t, "1500000000000000000", tokenUnits(1.5).String())
+}
@@ -12,10 +12,6 @@ import (
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/models"
-	"github.com/ipfs/boxo/files"
-	"github.com/ipfs/kubo/core"
-	"github.com/ipfs/kubo/core/coreapi"
-	"github.com/ipfs/kubo/core/coreiface/options"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
 	"github.com/lilypad-tech/lilypad/pkg/system"
@@ -27,12 +23,32 @@ type BacalhauExecutorOptions struct {
 	ApiHost               string
 	ApiPort               string
 	JobStatusPollInterval uint64
+	// run the job's containers with the cpu, memory and disk from the deal
+	EnforceLimits bool
+	// sample what the job's containers use while they run
+	MeasureUsage bool
+	// user:password credentials by image prefix e.g. ghcr.io/acme or a
+	// whole registry like 123456789012.dkr.ecr.eu-west-1.amazonaws.com
+	RegistryAuth map[string]string
+	// the directory of a docker config.json to pull other private images
+	// with, its credential helpers work as well so ECR can use ecr-login
+	DockerConfig string
+	// the MB of images to keep, the least recently used go first once they
+	// are over it, 0 never removes any
+	ImageCacheBudget int
+	// images to pull at startup along with those of the offered modules
+	PrepullImages []string
+	// where the files that give jobs their secrets are written, it has to
+	// be on a tmpfs of the compute node's host and in its allow listed
+	// local paths as they are mounted into the job
+	SecretsDir string
 }
 
 type BacalhauExecutor struct {
 	Options        BacalhauExecutorOptions
 	bacalhauEnv    []string
 	bacalhauClient BacalhauClient
+	images         *imageCache
 }
 
 func NewBacalhauExecutor(options BacalhauExecutorOptions) (*BacalhauExecutor, error) {
@@ -44,10 +60,19 @@ func NewBacalhauExecutor(options BacalhauExecutorOptions) (*BacalhauExecutor, er
 		return nil, err
 	}
 
-	return &BacalhauExecutor{
+	executor := &BacalhauExecutor{
 		Options:        options,
 		bacalhauClient: *client,
-	}, nil
+	}
+	executor.images = newImageCache(dockerImageStore{executor: executor}, int64(options.ImageCacheBudget)<<20, imageCachePath())
+	return executor, nil
+}
+
+// pulls the images before their first job so it does not wait for them,
+// then removes old ones if that took the cache over its budget
+func (executor *BacalhauExecutor) WarmImages(images []string) error {
+	executor.images.warm(append(append([]string{}, images...), executor.Options.PrepullImages...))
+	return nil
 }
 
 func (executor *BacalhauExecutor) Id() (string, error) {
@@ -106,10 +131,55 @@ func (executor *BacalhauExecutor) RunJob(
 	deal data.DealContainer,
 	module data.Module,
 ) (*executorlib.ExecutorResults, error) {
+	return executor.runJob(deal, module, nil)
+}
+
+// bacalhau keeps the job spec, so the secrets are in a file mounted into
+// the job that its entrypoint reads into the env before it starts
+func (executor *BacalhauExecutor) RunJobWithSecrets(
+	deal data.DealContainer,
+	module data.Module,
+	secrets map[string]string,
+) (*executorlib.ExecutorResults, error) {
+	return executor.runJob(deal, module, secrets)
+}
+
+func (executor *BacalhauExecutor) runJob(
+	deal data.DealContainer,
+	module data.Module,
+	secrets map[string]string,
+) (*executorlib.ExecutorResults, error) {
+	started := time.Now()
+	if len(secrets) > 0 {
+		secretsDir, err := writeSecretsDir(executor.Options.SecretsDir, secrets)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(secretsDir)
+		module.Job, err = withSecretsMount(module.Job, secretsDir, secrets)
+		if err != nil {
+			return nil, err
+		}
+	}
 	jobID, err := executor.getJobID(deal, module)
 	if err != nil {
 		return nil, err
 	}
+	defer executor.images.evict()
+
+	meter := newUsageMeter(started)
+	if executor.Options.MeasureUsage {
+		ctx, stopMeter := context.WithCancel(context.Background())
+		defer stopMeter()
+		go meter.run(ctx, jobID, jobGPUs(module))
+	}
+
+	// bacalhau only times the execution itself, a job still waiting for
+	// its node is stopped here so it does not hold the deal up either
+	var deadline time.Time
+	if module.Job.Spec.Timeout > 0 {
+		deadline = time.Now().Add(time.Duration(module.Job.Spec.Timeout) * time.Second)
+	}
 
 	var jobExecutions []*models.Execution
 	for {
@@ -124,14 +194,24 @@ func (executor *BacalhauExecutor) RunJob(
 
 		jobExecutions = jobInfo.Executions.Items
 
-		if len(jobExecutions) > 0 {
-			if jobInfo.Job.State.StateType == models.JobStateTypeCompleted {
-				break
+		state := jobInfo.Job.State.StateType
+		if len(jobExecutions) > 0 && state.IsTerminal() {
+			if err := executionError(jobID, state, jobExecutions); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			if err := executor.bacalhauClient.stopJob(jobID, "lilypad execution timeout"); err != nil {
+				return nil, fmt.Errorf("error stopping job %s after its timeout: %s", jobID, err.Error())
 			}
+			return nil, fmt.Errorf("%w: job %s stopped after %ds", executorlib.ErrExecutionTimeout, jobID, module.Job.Spec.Timeout)
 		}
 
 		time.Sleep(time.Duration(executor.Options.JobStatusPollInterval) * time.Second)
 	}
+	usage := meter.report(time.Now())
 
 	resultsDir, err := system.EnsureDataDir(filepath.Join(RESULTS_DIR, deal.ID))
 	if err != nil {
@@ -151,6 +231,7 @@ func (executor *BacalhauExecutor) RunJob(
 		ResultsDir:       outputDir,
 		ResultsCID:       cid,
 		InstructionCount: 1,
+		Usage:            usage,
 	}
 
 	return results, nil
@@ -206,7 +287,7 @@ func (executor *BacalhauExecutor) fetchResults(jobID string, resultsDir string)
 }
 
 func (executor *BacalhauExecutor) prepareResults(resultsDir string) (string, error) {
-	cid, err := generateCID(resultsDir)
+	cid, err := executorlib.GenerateCID(resultsDir)
 	if err != nil {
 		return "", fmt.Errorf("error generating CID: %s", err.Error())
 	}
@@ -214,44 +295,19 @@ func (executor *BacalhauExecutor) prepareResults(resultsDir string) (string, err
 	return cid, nil
 }
 
-// generateCID generates a CID for a given path
-// This mimics the behavior of `ipfs add -Qrn /path`
-func generateCID(path string) (string, error) {
-	ctx := context.Background()
-
-	stat, err := os.Stat(path)
-	if err != nil {
-		return "", fmt.Errorf("error getting file info: %s", err.Error())
-	}
-	fsNode, err := files.NewSerialFile(path, false, stat)
-	if err != nil {
-		return "", fmt.Errorf("error creating serial file: %s", err.Error())
-	}
-
-	node, err := core.NewNode(ctx, &core.BuildCfg{
-		Online: false,
-	})
-	api, err := coreapi.NewCoreAPI(node)
-	if err != nil {
-		return "", fmt.Errorf("error creating core API: %s", err.Error())
-	}
-
-	opts := []options.UnixfsAddOption{
-		options.Unixfs.HashOnly(true),
-	}
-	root, err := api.Unixfs().Add(ctx, fsNode, opts...)
-	if err != nil {
-		return "", fmt.Errorf("error adding to UnixFS: %s", err.Error())
-	}
-
-	return root.RootCid().String(), nil
-}
-
 func (executor *BacalhauExecutor) getJobID(
 	deal data.DealContainer,
 	module data.Module,
 ) (string, error) {
-	putJobResponse, err := executor.bacalhauClient.postJob(module.Job)
+	job := module.Job
+	if err := executor.pullPrivateImage(job.Spec.Docker.Image); err != nil {
+		return "", err
+	}
+	executor.images.touch(job.Spec.Docker.Image)
+	if executor.Options.EnforceLimits {
+		applyResourceLimits(&job, deal.Deal.JobOffer.Spec)
+	}
+	putJobResponse, err := executor.bacalhauClient.postJob(job)
 	if err != nil {
 		return "", fmt.Errorf("error creating job %s -> %s", deal.ID, err.Error())
 	}
@@ -263,3 +319,5 @@ func (executor *BacalhauExecutor) getJobID(
 
 // Compile-time interface check:
 var _ executorlib.Executor = (*BacalhauExecutor)(nil)
+var _ executorlib.ImageWarmer = (*BacalhauExecutor)(nil)
+var _ executorlib.SecretsRunner = (*BacalhauExecutor)(nil)
@@ -77,6 +77,16 @@ func (c *BacalhauClient) getJob(jobID string) (*apimodels.GetJobResponse, error)
 	return response, nil
 }
 
+func (c *BacalhauClient) stopJob(jobID string, reason string) error {
+	stopJobRequest := apimodels.StopJobRequest{
+		JobID:  jobID,
+		Reason: reason,
+	}
+
+	_, err := c.api.Jobs().Stop(context.Background(), &stopJobRequest)
+	return err
+}
+
 func (c *BacalhauClient) getJobResult(jobId string) (string, error) {
 	getJobResultsRequest := apimodels.ListJobResultsRequest{
 		JobID: jobId,
@@ -167,7 +177,8 @@ func translateJob(job bacalhau.Job) *models.Job {
 					ExecutionTimeout: job.Spec.Timeout,
 					// TODO: add queue timeout and total timeout
 				},
-				ResultPaths: translateOutputSources(job.Spec.Outputs),
+				InputSources: translateInputSources(job.Spec.Inputs),
+				ResultPaths:  translateOutputSources(job.Spec.Outputs),
 			},
 		},
 		State:      models.NewJobState(models.JobStateTypePending),
@@ -203,6 +214,16 @@ func translateConstraints(constraints []bacalhau.LabelSelectorRequirement) []*mo
 	return translated
 }
 
+// bacalhau names its storage sources differently to our legacy types
+var inputSourceTypes = map[bacalhau.StorageSourceType]string{
+	bacalhau.StorageSourceIPFS:           models.StorageSourceIPFS,
+	bacalhau.StorageSourceURLDownload:    models.StorageSourceURL,
+	bacalhau.StorageSourceLocalDirectory: models.StorageSourceLocalDirectory,
+	bacalhau.StorageSourceS3:             models.StorageSourceS3,
+}
+
+// the job's inputs as bacalhau mounts them, everything but a local
+// directory asked to be writable is mounted read only
 func translateInputSources(sources []bacalhau.StorageSpec) []*models.InputSource {
 	if len(sources) == 0 {
 		return nil
@@ -210,15 +231,20 @@ func translateInputSources(sources []bacalhau.StorageSpec) []*models.InputSource
 
 	translated := make([]*models.InputSource, len(sources))
 	for i, source := range sources {
+		sourceType, ok := inputSourceTypes[source.StorageSource]
+		if !ok {
+			sourceType = source.StorageSource.String()
+		}
 		translated[i] = &models.InputSource{
 			Source: &models.SpecConfig{
-				Type: source.StorageSource.String(),
+				Type: sourceType,
 			},
+			Alias:  source.Name,
 			Target: source.Path,
 		}
 		if source.StorageSource == bacalhau.StorageSourceIPFS {
 			translated[i].Source.Params = map[string]interface{}{
-				"cid": source.CID,
+				"CID": source.CID,
 			}
 		}
 		if source.StorageSource == bacalhau.StorageSourceLocalDirectory {
@@ -227,7 +253,7 @@ func translateInputSources(sources []bacalhau.StorageSpec) []*models.InputSource
 				"ReadWrite":  source.ReadWrite,
 			}
 		}
-		if source.StorageSource == bacalhau.StorageSourceS3 {
+		if source.StorageSource == bacalhau.StorageSourceS3 && source.S3 != nil {
 			translated[i].Source.Params = map[string]interface{}{
 				"Bucket":         source.S3.Bucket,
 				"Key":            source.S3.Key,
@@ -0,0 +1,25 @@
+//go:build unit
+
+package bacalhau
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateInputSources(t *testing.T) {
+	sources := translateInputSources([]bacalhau.StorageSpec{
+		{StorageSource: bacalhau.StorageSourceIPFS, Name: "dataset", CID: "QmTVmC7JBD2ES2qGPqBNVWnX1KeEPNrPGb7rJ8cpFgtefe", Path: "/inputs/dataset"},
+		{StorageSource: bacalhau.StorageSourceURLDownload, Name: "weights", URL: "https://example.com/weights.bin", Path: "/inputs/weights.bin"},
+	})
+	assert.Len(t, sources, 2)
+	assert.True(t, sources[0].Source.IsType(models.StorageSourceIPFS))
+	assert.Equal(t, "dataset", sources[0].Alias)
+	assert.Equal(t, "/inputs/dataset", sources[0].Target)
+	assert.Equal(t, "QmTVmC7JBD2ES2qGPqBNVWnX1KeEPNrPGb7rJ8cpFgtefe", sources[0].Source.Params["CID"])
+	assert.Equal(t, models.StorageSourceURL, sources[1].Source.Type)
+	assert.Equal(t, "https://example.com/weights.bin", sources[1].Source.Params["URL"])
+}
@@ -0,0 +1,258 @@
+package bacalhau
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+const IMAGE_CACHE_FILE = "image-cache.json"
+
+// what the cache knows about an image it pulled or a job ran
+type cachedImage struct {
+	Image    string    `json:"image"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// the docker side of the cache so it can be tested without docker
+type imageStore interface {
+	pull(image string) error
+	// the bytes the image takes, false when it is not on the machine
+	size(image string) (int64, bool, error)
+	remove(image string) error
+}
+
+// keeps the images of the modules we offer pulled so their first job does
+// not wait for them, and the images jobs run under a disk budget by
+// removing the least recently used, only images the cache has seen are
+// ever removed so the rest of the machine's images are left alone
+type imageCache struct {
+	mutex  sync.Mutex
+	store  imageStore
+	budget int64
+	// where the last use of each image is kept across restarts, no file
+	// when empty
+	path   string
+	images map[string]*cachedImage
+	// the images of the modules we offer, they go after the others
+	pinned map[string]bool
+	now    func() time.Time
+}
+
+func newImageCache(store imageStore, budget int64, path string) *imageCache {
+	cache := &imageCache{
+		store:  store,
+		budget: budget,
+		path:   path,
+		images: map[string]*cachedImage{},
+		pinned: map[string]bool{},
+		now:    time.Now,
+	}
+	cache.load()
+	return cache
+}
+
+func (cache *imageCache) load() {
+	if cache.path == "" {
+		return
+	}
+	contents, err := os.ReadFile(cache.path)
+	if err != nil {
+		return
+	}
+	images := []*cachedImage{}
+	if err := json.Unmarshal(contents, &images); err != nil {
+		log.Warn().Err(err).Msgf("ignoring the image cache in %s", cache.path)
+		return
+	}
+	for _, image := range images {
+		cache.images[image.Image] = image
+	}
+}
+
+// called with the mutex held
+func (cache *imageCache) save() {
+	if cache.path == "" {
+		return
+	}
+	images := make([]*cachedImage, 0, len(cache.images))
+	for _, image := range cache.images {
+		images = append(images, image)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Image < images[j].Image })
+	contents, err := json.Marshal(images)
+	if err == nil {
+		err = os.WriteFile(cache.path, contents, 0644) //nolint:gomnd
+	}
+	if err != nil {
+		log.Warn().Err(err).Msgf("error saving the image cache to %s", cache.path)
+	}
+}
+
+// pulls the images that are not on the machine yet, an image that will
+// not pull is logged and the rest still go ahead
+func (cache *imageCache) warm(images []string) {
+	cache.mutex.Lock()
+	for _, image := range images {
+		cache.pinned[image] = true
+	}
+	cache.mutex.Unlock()
+
+	for _, image := range images {
+		size, present, err := cache.store.size(image)
+		if err == nil && !present {
+			started := cache.now()
+			err = cache.store.pull(image)
+			if err == nil {
+				log.Info().Str("image", image).Msgf("pulled image in %s", cache.now().Sub(started).Round(time.Second))
+				size, present, err = cache.store.size(image)
+			}
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("image", image).Msg("error warming image")
+			continue
+		}
+		if present {
+			cache.record(image, size, false)
+		}
+	}
+	cache.evict()
+}
+
+// a job is about to run the image
+func (cache *imageCache) touch(image string) {
+	size, present, err := cache.store.size(image)
+	if err != nil || !present {
+		// bacalhau pulls it, the size is picked up next time
+		size = 0
+	}
+	cache.record(image, size, true)
+}
+
+func (cache *imageCache) record(image string, size int64, used bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cached, ok := cache.images[image]
+	if !ok {
+		cached = &cachedImage{Image: image, LastUsed: cache.now()}
+		cache.images[image] = cached
+	}
+	if size > 0 {
+		cached.Size = size
+	}
+	if used {
+		cached.LastUsed = cache.now()
+	}
+	cache.save()
+}
+
+// the bytes the cache's images take up
+func (cache *imageCache) usage() int64 {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	total := int64(0)
+	for _, image := range cache.images {
+		total += image.Size
+	}
+	return total
+}
+
+// removes the least recently used images until the cache is under its
+// budget, the images of the modules we offer only once the others are
+// gone and never the one a job has just used, an image a container is
+// still using will not remove and is skipped
+func (cache *imageCache) evict() []string {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.budget <= 0 {
+		return nil
+	}
+	total := int64(0)
+	candidates := []*cachedImage{}
+	for _, image := range cache.images {
+		total += image.Size
+		candidates = append(candidates, image)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if cache.pinned[candidates[i].Image] != cache.pinned[candidates[j].Image] {
+			return !cache.pinned[candidates[i].Image]
+		}
+		return candidates[i].LastUsed.Before(candidates[j].LastUsed)
+	})
+	removed := []string{}
+	// the newest is what is running now
+	for _, image := range candidates[:max(0, len(candidates)-1)] {
+		if total <= cache.budget {
+			break
+		}
+		if err := cache.store.remove(image.Image); err != nil {
+			log.Debug().Err(err).Str("image", image.Image).Msg("could not evict image")
+			continue
+		}
+		total -= image.Size
+		delete(cache.images, image.Image)
+		removed = append(removed, image.Image)
+	}
+	if len(removed) > 0 {
+		log.Info().Strs("images", removed).Msgf("evicted images, the cache is using %dMB of its %dMB", total>>20, cache.budget>>20)
+		cache.save()
+	}
+	return removed
+}
+
+// the docker cli on this machine, pulls go through the executor so they
+// get the registry credentials
+type dockerImageStore struct {
+	executor *BacalhauExecutor
+}
+
+func (store dockerImageStore) pull(image string) error {
+	return store.executor.pullImage(image)
+}
+
+func (store dockerImageStore) size(image string) (int64, bool, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", "image", "inspect", "--format", "{{.Size}}", image)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(strings.ToLower(stderr.String()), "no such image") {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("error inspecting image %s: %s %s", image, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("docker gave %q as the size of %s", strings.TrimSpace(string(output)), image)
+	}
+	return size, true, nil
+}
+
+func (store dockerImageStore) remove(image string) error {
+	output, err := exec.Command("docker", "image", "rm", image).CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func imageCachePath() string {
+	dir, err := system.EnsureDataDir("")
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, IMAGE_CACHE_FILE)
+}
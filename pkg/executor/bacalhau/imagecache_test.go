@@ -0,0 +1,100 @@
+//go:build unit
+
+package bacalhau
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeImageStore struct {
+	sizes   map[string]int64
+	pulled  []string
+	inUse   map[string]bool
+	removed []string
+}
+
+func (store *fakeImageStore) pull(image string) error {
+	store.pulled = append(store.pulled, image)
+	store.sizes[image] = 100
+	return nil
+}
+
+func (store *fakeImageStore) size(image string) (int64, bool, error) {
+	size, ok := store.sizes[image]
+	return size, ok, nil
+}
+
+func (store *fakeImageStore) remove(image string) error {
+	if store.inUse[image] {
+		return errors.New("image is being used by a container")
+	}
+	store.removed = append(store.removed, image)
+	delete(store.sizes, image)
+	return nil
+}
+
+func testImageCache(store *fakeImageStore, budget int64, path string) (*imageCache, *time.Time) {
+	cache := newImageCache(store, budget, path)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return cache, &now
+}
+
+func TestImageCacheWarm(t *testing.T) {
+	store := &fakeImageStore{sizes: map[string]int64{"cowsay:v1": 50}}
+	cache, _ := testImageCache(store, 0, "")
+	cache.warm([]string{"cowsay:v1", "sdxl:v1"})
+	assert.Equal(t, []string{"sdxl:v1"}, store.pulled)
+	assert.Equal(t, int64(150), cache.usage())
+}
+
+func TestImageCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	store := &fakeImageStore{sizes: map[string]int64{"a": 100, "b": 100, "c": 100, "d": 100}}
+	cache, _ := testImageCache(store, 250, "")
+	cache.warm([]string{"a"})
+	cache.touch("b")
+	cache.touch("c")
+	cache.touch("d")
+	// a is the oldest but its module is offered so b and c go first
+	assert.Equal(t, []string{"b", "c"}, cache.evict())
+	assert.Equal(t, int64(200), cache.usage())
+	assert.Empty(t, cache.evict())
+}
+
+func TestImageCacheSkipsImagesInUse(t *testing.T) {
+	store := &fakeImageStore{sizes: map[string]int64{"a": 100, "b": 100, "c": 100}, inUse: map[string]bool{"a": true}}
+	cache, _ := testImageCache(store, 100, "")
+	cache.touch("a")
+	cache.touch("b")
+	cache.touch("c")
+	assert.Equal(t, []string{"b"}, cache.evict())
+	assert.Equal(t, int64(200), cache.usage())
+}
+
+func TestImageCacheNoBudget(t *testing.T) {
+	store := &fakeImageStore{sizes: map[string]int64{"a": 100, "b": 100}}
+	cache, _ := testImageCache(store, 0, "")
+	cache.touch("a")
+	cache.touch("b")
+	assert.Empty(t, cache.evict())
+}
+
+func TestImageCacheKeepsLastUseAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), IMAGE_CACHE_FILE)
+	store := &fakeImageStore{sizes: map[string]int64{"a": 100, "b": 100}}
+	cache, _ := testImageCache(store, 0, path)
+	cache.touch("b")
+	cache.touch("a")
+
+	restarted, _ := testImageCache(store, 100, path)
+	assert.Equal(t, int64(200), restarted.usage())
+	assert.Equal(t, []string{"b"}, restarted.evict())
+}
@@ -0,0 +1,63 @@
+package bacalhau
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+)
+
+// what bacalhau's docker executor says when the kernel kills a container
+// for going over its memory limit
+const memoryLimitExceeded = "memory limit exceeded"
+
+// how bacalhau's compute node starts the message of an execution it
+// stopped for running past the job's timeout
+const executionTimedOut = "execution timed out"
+
+// the job offer's spec is the part of the machine the deal paid for, so
+// the job's containers are given that rather than whatever the module
+// template asks for, bacalhau turns these into the container's cgroup
+// limits and will not run a job that asks for more than the node has
+func applyResourceLimits(job *bacalhau.Job, spec data.MachineSpec) {
+	if spec.CPU > 0 {
+		// milli-cpus
+		job.Spec.Resources.CPU = fmt.Sprintf("%dm", spec.CPU)
+	}
+	if spec.RAM > 0 {
+		job.Spec.Resources.Memory = fmt.Sprintf("%dMiB", spec.RAM)
+	}
+	if spec.Disk > 0 {
+		job.Spec.Resources.Disk = fmt.Sprintf("%dMiB", spec.Disk)
+	}
+}
+
+// why the job did not finish, nil when it did, a job killed for going
+// over its limits or its timeout is told apart so the provider can
+// report it as the job's fault
+func executionError(jobID string, state models.JobStateType, executions []*models.Execution) error {
+	for _, execution := range executions {
+		if execution.RunOutput == nil || execution.RunOutput.ErrorMsg == "" {
+			continue
+		}
+		if strings.Contains(execution.RunOutput.ErrorMsg, memoryLimitExceeded) {
+			return fmt.Errorf("%w: job %s went over its memory limit", executorlib.ErrResourceLimitExceeded, jobID)
+		}
+	}
+	if state == models.JobStateTypeCompleted {
+		return nil
+	}
+	message := ""
+	for _, execution := range executions {
+		if execution.ComputeState.Message != "" {
+			message = execution.ComputeState.Message
+		}
+		if strings.HasPrefix(strings.ToLower(execution.ComputeState.Message), executionTimedOut) {
+			return fmt.Errorf("%w: job %s %s", executorlib.ErrExecutionTimeout, jobID, strings.ToLower(execution.ComputeState.Message))
+		}
+	}
+	return fmt.Errorf("job %s %s: %s", jobID, strings.ToLower(state.String()), message)
+}
@@ -0,0 +1,43 @@
+//go:build unit
+
+package bacalhau
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyResourceLimits(t *testing.T) {
+	job := bacalhau.Job{}
+	job.Spec.Resources = bacalhau.ResourceUsageConfig{CPU: "8", Memory: "64gb", GPU: "1"}
+	applyResourceLimits(&job, data.MachineSpec{CPU: 1500, RAM: 8000})
+	assert.Equal(t, "1500m", job.Spec.Resources.CPU)
+	assert.Equal(t, "8000MiB", job.Spec.Resources.Memory)
+	// what the deal does not say is left to the module
+	assert.Equal(t, "", job.Spec.Resources.Disk)
+	assert.Equal(t, "1", job.Spec.Resources.GPU)
+}
+
+func TestExecutionError(t *testing.T) {
+	completed := []*models.Execution{{RunOutput: &models.RunCommandResult{ExitCode: 0}}}
+	assert.NoError(t, executionError("job", models.JobStateTypeCompleted, completed))
+
+	killed := []*models.Execution{{RunOutput: &models.RunCommandResult{ExitCode: 137, ErrorMsg: "memory limit exceeded. Please refer to the docs"}}}
+	err := executionError("job", models.JobStateTypeCompleted, killed)
+	assert.True(t, errors.Is(err, executorlib.ErrResourceLimitExceeded))
+
+	failed := []*models.Execution{{ComputeState: models.State[models.ExecutionStateType]{Message: "image not found"}}}
+	err = executionError("job", models.JobStateTypeFailed, failed)
+	assert.ErrorContains(t, err, "image not found")
+	assert.False(t, errors.Is(err, executorlib.ErrResourceLimitExceeded))
+
+	timedOut := []*models.Execution{{ComputeState: models.State[models.ExecutionStateType]{Message: "Execution timed out after 10m0s"}}}
+	err = executionError("job", models.JobStateTypeFailed, timedOut)
+	assert.True(t, errors.Is(err, executorlib.ErrExecutionTimeout))
+}
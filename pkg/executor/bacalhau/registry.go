@@ -0,0 +1,124 @@
+package bacalhau
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// a private image can be large, this only has to stop a pull that has hung
+const imagePullTimeout = 30 * time.Minute
+
+// docker keeps the docker hub credentials under this rather than its host
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// the registry an image is pulled from, an image without one is on
+// docker hub
+func imageRegistry(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
+
+// the image with its registry in front so it can be matched against the
+// credential prefixes, nginx is docker.io/library/nginx
+func qualifiedImage(image string) string {
+	if imageRegistry(image) != "docker.io" || strings.HasPrefix(image, "docker.io/") {
+		return image
+	}
+	if !strings.Contains(image, "/") {
+		return "docker.io/library/" + image
+	}
+	return "docker.io/" + image
+}
+
+// the user:password for the longest prefix that covers the image, so a
+// module's image can have credentials of its own and the rest of the
+// registry share some
+func registryCredentials(auth map[string]string, image string) (string, bool) {
+	qualified := qualifiedImage(image)
+	best := ""
+	credentials := ""
+	for prefix, value := range auth {
+		prefix = strings.TrimSuffix(prefix, "/")
+		covers := qualified == prefix || strings.HasPrefix(qualified, prefix+"/") || strings.HasPrefix(qualified, prefix+":") || strings.HasPrefix(qualified, prefix+"@")
+		if covers && len(prefix) > len(best) {
+			best = prefix
+			credentials = value
+		}
+	}
+	return credentials, best != ""
+}
+
+// a docker config with only the one registry's credentials in it
+func registryAuthConfig(image string, credentials string) ([]byte, error) {
+	key := imageRegistry(image)
+	if key == "docker.io" {
+		key = dockerHubAuthKey
+	}
+	return json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			key: map[string]string{
+				"auth": base64.StdEncoding.EncodeToString([]byte(credentials)),
+			},
+		},
+	})
+}
+
+// bacalhau can only log in to docker hub so an image in a private
+// registry is pulled here first with the credentials that cover it or
+// else the docker config we were given, bacalhau's compute node then needs
+// SKIP_IMAGE_PULL set to use the image it finds on the machine, an image
+// with no credentials is left to bacalhau
+func (executor *BacalhauExecutor) pullPrivateImage(image string) error {
+	_, ok := registryCredentials(executor.Options.RegistryAuth, image)
+	if !ok && executor.Options.DockerConfig == "" {
+		return nil
+	}
+	return executor.pullImage(image)
+}
+
+// pulls the image with the credentials that cover it or the docker config
+// we were given, or docker's own config when there are neither
+func (executor *BacalhauExecutor) pullImage(image string) error {
+	args := []string{"pull", "--quiet", image}
+	credentials, ok := registryCredentials(executor.Options.RegistryAuth, image)
+	configDir := executor.Options.DockerConfig
+	if ok {
+		dir, err := os.MkdirTemp("", "lilypad-docker-config-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		config, err := registryAuthConfig(image, credentials)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), config, 0600); err != nil { //nolint:gomnd
+			return err
+		}
+		configDir = dir
+	}
+	if configDir != "" {
+		args = append([]string{"--config", configDir}, args...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imagePullTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error pulling image %s from %s: %s %s", image, imageRegistry(image), err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
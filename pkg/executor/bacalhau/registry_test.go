@@ -0,0 +1,54 @@
+//go:build unit
+
+package bacalhau
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualifiedImage(t *testing.T) {
+	assert.Equal(t, "docker.io/library/nginx:1.27", qualifiedImage("nginx:1.27"))
+	assert.Equal(t, "docker.io/acme/model", qualifiedImage("acme/model"))
+	assert.Equal(t, "ghcr.io/acme/model:v1", qualifiedImage("ghcr.io/acme/model:v1"))
+	assert.Equal(t, "localhost:5000/model", qualifiedImage("localhost:5000/model"))
+	assert.Equal(t, "localhost:5000", imageRegistry("localhost:5000/model"))
+}
+
+func TestRegistryCredentials(t *testing.T) {
+	auth := map[string]string{
+		"ghcr.io":                   "shared:one",
+		"ghcr.io/acme/model":        "model:two",
+		"docker.io/acme":            "hub:three",
+		"123.dkr.ecr.amazonaws.com": "AWS:four",
+	}
+	credentials, ok := registryCredentials(auth, "ghcr.io/acme/model:v1")
+	assert.True(t, ok)
+	assert.Equal(t, "model:two", credentials)
+	credentials, _ = registryCredentials(auth, "ghcr.io/acme/model-two:v1")
+	assert.Equal(t, "shared:one", credentials)
+	credentials, _ = registryCredentials(auth, "acme/private")
+	assert.Equal(t, "hub:three", credentials)
+	_, ok = registryCredentials(auth, "nginx")
+	assert.False(t, ok)
+	_, ok = registryCredentials(auth, "ghcr.io.example.com/model")
+	assert.False(t, ok)
+}
+
+func TestRegistryAuthConfig(t *testing.T) {
+	config := struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}{}
+	raw, err := registryAuthConfig("ghcr.io/acme/model", "bot:token")
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(raw, &config))
+	assert.Equal(t, "Ym90OnRva2Vu", config.Auths["ghcr.io"].Auth)
+
+	raw, _ = registryAuthConfig("acme/model", "bot:token")
+	assert.NoError(t, json.Unmarshal(raw, &config))
+	assert.Contains(t, config.Auths, dockerHubAuthKey)
+}
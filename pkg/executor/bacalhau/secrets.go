@@ -0,0 +1,69 @@
+package bacalhau
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+)
+
+// where the job finds the file of its secrets
+const secretsMountPath = "/lilypad/secrets"
+
+// sources the secrets into the env and then runs the module's entrypoint
+// with them, so they are only ever in the file and the process' env
+const secretsScript = `set -a; . ` + secretsMountPath + `/env; set +a; exec "$@"`
+
+// a directory of its own for the job with an env file of its secrets,
+// only the provider's user can read them so the job's container has to
+// run as root or as that user
+func writeSecretsDir(parent string, secrets map[string]string) (string, error) {
+	if parent == "" {
+		return "", fmt.Errorf("BACALHAU_SECRETS_DIR is not set so there is nowhere to keep the job's secrets")
+	}
+	if err := os.MkdirAll(parent, 0700); err != nil {
+		return "", fmt.Errorf("error creating the secrets directory %s: %s", parent, err.Error())
+	}
+	dir, err := os.MkdirTemp(parent, "job-")
+	if err != nil {
+		return "", fmt.Errorf("error creating the job's secrets directory: %s", err.Error())
+	}
+	var env strings.Builder
+	for _, name := range executorlib.SecretNames(secrets) {
+		env.WriteString(name + "=" + shellQuote(secrets[name]) + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "env"), []byte(env.String()), 0600); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error writing the job's secrets: %s", err.Error())
+	}
+	return dir, nil
+}
+
+// single quotes keep everything but a single quote, which is closed,
+// escaped and reopened
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// the job with the secrets directory mounted read only and its
+// entrypoint run through the script that loads them, images without a
+// shell or modules that rely on the image's entrypoint cannot have them
+func withSecretsMount(job bacalhau.Job, dir string, secrets map[string]string) (bacalhau.Job, error) {
+	docker := job.Spec.Docker
+	if len(docker.Entrypoint) == 0 {
+		return job, fmt.Errorf("the module has no entrypoint to load the job's secrets before")
+	}
+	docker.Entrypoint = append([]string{"/bin/sh", "-c", secretsScript, "sh"}, docker.Entrypoint...)
+	docker.EnvironmentVariables = executorlib.WithoutSecrets(docker.EnvironmentVariables, secrets)
+	job.Spec.Docker = docker
+	job.Spec.Inputs = append(append([]bacalhau.StorageSpec{}, job.Spec.Inputs...), bacalhau.StorageSpec{
+		StorageSource: bacalhau.StorageSourceLocalDirectory,
+		Name:          "lilypad-secrets",
+		SourcePath:    dir,
+		Path:          secretsMountPath,
+	})
+	return job, nil
+}
@@ -0,0 +1,55 @@
+//go:build unit
+
+package bacalhau
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretsMount(t *testing.T) {
+	secrets := map[string]string{"API_KEY": "sk=123", "QUOTED": "it's $HOME"}
+	dir, err := writeSecretsDir(filepath.Join(t.TempDir(), "secrets"), secrets)
+	assert.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, "env"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	job := bacalhau.Job{}
+	job.Spec.Docker = bacalhau.JobSpecDocker{
+		Image:                "ghcr.io/acme/model:v1",
+		Entrypoint:           []string{"python", "run.py"},
+		EnvironmentVariables: []string{"MODEL=sdxl", "API_KEY=placeholder"},
+	}
+	withSecrets, err := withSecretsMount(job, dir, secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/bin/sh", "-c", secretsScript, "sh", "python", "run.py"}, withSecrets.Spec.Docker.Entrypoint)
+	assert.Equal(t, []string{"MODEL=sdxl"}, withSecrets.Spec.Docker.EnvironmentVariables)
+	assert.Equal(t, dir, withSecrets.Spec.Inputs[0].SourcePath)
+	assert.False(t, withSecrets.Spec.Inputs[0].ReadWrite)
+	// the job the secrets were added to is left as it was
+	assert.Empty(t, job.Spec.Inputs)
+
+	body, err := json.Marshal(translateJob(withSecrets))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "sk=123")
+	assert.NotContains(t, string(body), "placeholder")
+
+	// the env file gives the values back as they were
+	if _, err := exec.LookPath("sh"); err == nil {
+		script := `set -a; . "` + filepath.Join(dir, "env") + `"; set +a; printf '%s|%s' "$API_KEY" "$QUOTED"`
+		output, err := exec.Command("sh", "-c", script).Output()
+		assert.NoError(t, err)
+		assert.Equal(t, "sk=123|it's $HOME", string(output))
+	}
+
+	job.Spec.Docker.Entrypoint = nil
+	_, err = withSecretsMount(job, dir, secrets)
+	assert.Error(t, err)
+}
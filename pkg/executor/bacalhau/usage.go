@@ -0,0 +1,203 @@
+package bacalhau
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// how often the job's container and gpus are sampled while it runs, the
+// cpu time is the cpu use between samples added up so this is its
+// resolution
+const usageSampleInterval = 2 * time.Second
+
+// what one sample saw, cpu in percent of a core so 200 is two cores
+type usageSample struct {
+	cpuPercent float64
+	memory     int
+	// the mean of the job's gpus, gpus is false when it has none
+	gpuPercent float64
+	gpus       bool
+}
+
+// adds the samples of one job up into its usage
+type usageMeter struct {
+	mutex      sync.Mutex
+	started    time.Time
+	last       time.Time
+	cpuTime    float64
+	peakMemory int
+	gpuTotal   float64
+	gpuSamples int
+}
+
+func newUsageMeter(started time.Time) *usageMeter {
+	return &usageMeter{started: started, last: started}
+}
+
+// the cpu in the sample is taken to have held since the sample before
+func (meter *usageMeter) add(sample usageSample, at time.Time) {
+	meter.mutex.Lock()
+	defer meter.mutex.Unlock()
+	meter.cpuTime += sample.cpuPercent / 100 * at.Sub(meter.last).Seconds() //nolint:gomnd
+	meter.last = at
+	meter.peakMemory = max(meter.peakMemory, sample.memory)
+	if sample.gpus {
+		meter.gpuTotal += sample.gpuPercent
+		meter.gpuSamples++
+	}
+}
+
+func (meter *usageMeter) report(finished time.Time) *data.ResourceUsage {
+	meter.mutex.Lock()
+	defer meter.mutex.Unlock()
+	usage := &data.ResourceUsage{
+		CPUTime:    meter.cpuTime,
+		PeakMemory: meter.peakMemory,
+		Duration:   finished.Sub(meter.started).Seconds(),
+	}
+	if meter.gpuSamples > 0 {
+		usage.GPUUtilization = meter.gpuTotal / float64(meter.gpuSamples)
+	}
+	return usage
+}
+
+// samples the job until the context is done, bacalhau does not say what
+// a job used so this asks docker and nvidia-smi on this machine, a job
+// bacalhau runs somewhere else only gets its duration
+func (meter *usageMeter) run(ctx context.Context, jobID string, gpus []string) {
+	ticker := time.NewTicker(usageSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			sample, ok := sampleUsage(ctx, jobID, gpus)
+			if !ok {
+				// before the container starts or after it stops
+				meter.mutex.Lock()
+				meter.last = at
+				meter.mutex.Unlock()
+				continue
+			}
+			meter.add(sample, at)
+		}
+	}
+}
+
+func sampleUsage(ctx context.Context, jobID string, gpus []string) (usageSample, bool) {
+	// bacalhau names its containers bacalhau-<node>-<job>-<execution>-executor
+	ids, err := exec.CommandContext(ctx, "docker", "ps", "-q", "--filter", fmt.Sprintf("name=-%s-", jobID)).Output()
+	if err != nil || len(strings.Fields(string(ids))) == 0 {
+		return usageSample{}, false
+	}
+	args := append([]string{"stats", "--no-stream", "--format", "{{.CPUPerc}}|{{.MemUsage}}"}, strings.Fields(string(ids))...)
+	stats, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return usageSample{}, false
+	}
+	sample, err := parseDockerStats(string(stats))
+	if err != nil {
+		return usageSample{}, false
+	}
+	if len(gpus) > 0 {
+		output, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=index,utilization.gpu", "--format=csv,noheader,nounits").Output()
+		if err == nil {
+			sample.gpuPercent, sample.gpus = parseGPUUtilization(string(output), gpus)
+		}
+	}
+	return sample, true
+}
+
+// a "cpu%|used / limit" line per container, a job normally has one
+func parseDockerStats(output string) (usageSample, error) {
+	sample := usageSample{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) != 2 { //nolint:gomnd
+			return sample, fmt.Errorf("unexpected docker stats line %q", line)
+		}
+		cpu, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(parts[0]), "%"), 64)
+		if err != nil {
+			return sample, fmt.Errorf("unexpected docker stats cpu %q", parts[0])
+		}
+		used := strings.TrimSpace(strings.Split(parts[1], "/")[0])
+		memory, err := parseMemory(used)
+		if err != nil {
+			return sample, err
+		}
+		sample.cpuPercent += cpu
+		sample.memory += memory
+	}
+	return sample, nil
+}
+
+var memoryUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	// the longer suffixes first so MiB is not read as B
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"kB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// docker's human readable sizes in megabytes
+func parseMemory(value string) (int, error) {
+	for _, unit := range memoryUnits {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), 64)
+		if err != nil {
+			break
+		}
+		return int(amount * unit.bytes / (1 << 20)), nil
+	}
+	return 0, fmt.Errorf("unexpected docker stats memory %q", value)
+}
+
+// the mean utilization of the listed gpu indexes
+func parseGPUUtilization(output string, gpus []string) (float64, bool) {
+	wanted := map[string]bool{}
+	for _, gpu := range gpus {
+		wanted[strings.TrimSpace(gpu)] = true
+	}
+	total, count := 0.0, 0
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(line, ",")
+		if len(parts) != 2 || !wanted[strings.TrimSpace(parts[0])] { //nolint:gomnd
+			continue
+		}
+		utilization, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			continue
+		}
+		total += utilization
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
+
+// the gpus the job was given in NVIDIA_VISIBLE_DEVICES, none when it was
+// not given any of its own
+func jobGPUs(module data.Module) []string {
+	for _, variable := range module.Job.Spec.Docker.EnvironmentVariables {
+		value, ok := strings.CutPrefix(variable, "NVIDIA_VISIBLE_DEVICES=")
+		if !ok || value == "" || value == "all" || value == "none" {
+			continue
+		}
+		return strings.Split(value, ",")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//go:build unit
+
+package bacalhau
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageMeter(t *testing.T) {
+	started := time.Unix(1000, 0)
+	meter := newUsageMeter(started)
+	// two cores for two seconds then half a core for four
+	meter.add(usageSample{cpuPercent: 200, memory: 100}, started.Add(2*time.Second))
+	meter.add(usageSample{cpuPercent: 50, memory: 300, gpuPercent: 80, gpus: true}, started.Add(6*time.Second))
+	meter.add(usageSample{cpuPercent: 0, memory: 50, gpuPercent: 20, gpus: true}, started.Add(8*time.Second))
+
+	usage := meter.report(started.Add(10 * time.Second))
+	assert.InDelta(t, 6, usage.CPUTime, 0.001)
+	assert.Equal(t, 300, usage.PeakMemory)
+	assert.InDelta(t, 50, usage.GPUUtilization, 0.001)
+	assert.InDelta(t, 10, usage.Duration, 0.001)
+
+	// without a sample there is only the duration
+	usage = newUsageMeter(started).report(started.Add(time.Minute))
+	assert.Equal(t, &data.ResourceUsage{Duration: 60}, usage)
+}
+
+func TestParseDockerStats(t *testing.T) {
+	sample, err := parseDockerStats("150.25%|512MiB / 1GiB\n")
+	assert.NoError(t, err)
+	assert.InDelta(t, 150.25, sample.cpuPercent, 0.001)
+	assert.Equal(t, 512, sample.memory)
+
+	sample, err = parseDockerStats("10%|1.5GiB / 4GiB\n5%|2MB / 4GiB")
+	assert.NoError(t, err)
+	assert.InDelta(t, 15, sample.cpuPercent, 0.001)
+	assert.Equal(t, 1537, sample.memory)
+
+	_, err = parseDockerStats("--|512MiB / 1GiB")
+	assert.Error(t, err)
+	_, err = parseDockerStats("10%|lots / 1GiB")
+	assert.Error(t, err)
+}
+
+func TestParseGPUUtilization(t *testing.T) {
+	output := "0, 90\n1, 10\n2, 40\n"
+	utilization, ok := parseGPUUtilization(output, []string{"1", "2"})
+	assert.True(t, ok)
+	assert.InDelta(t, 25, utilization, 0.001)
+	_, ok = parseGPUUtilization(output, []string{"3"})
+	assert.False(t, ok)
+}
+
+func TestJobGPUs(t *testing.T) {
+	module := data.Module{}
+	assert.Nil(t, jobGPUs(module))
+	module.Job.Spec.Docker.EnvironmentVariables = []string{"A=1", "NVIDIA_VISIBLE_DEVICES=0,2"}
+	assert.Equal(t, []string{"0", "2"}, jobGPUs(module))
+	module.Job.Spec.Docker.EnvironmentVariables = []string{"NVIDIA_VISIBLE_DEVICES=all"}
+	assert.Nil(t, jobGPUs(module))
+}
@@ -0,0 +1,156 @@
+package container
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+)
+
+// the cpus and memory the container is limited to, 0 is no limit
+type limits struct {
+	cpus   float64
+	memory uint64
+}
+
+// the same cpu and memory strings bacalhau takes, cpus as k8s quantities
+// like 500m or 2 and memory as sizes like 512MiB or 8gb
+func parseLimits(resources bacalhau.ResourceUsageConfig) (limits, error) {
+	result := limits{}
+	if cpu := strings.TrimSpace(resources.CPU); cpu != "" {
+		value, milli := strings.CutSuffix(cpu, "m")
+		cpus, err := strconv.ParseFloat(value, 64)
+		if err != nil || cpus < 0 {
+			return limits{}, fmt.Errorf("the job's cpu %q is not a number of cpus", resources.CPU)
+		}
+		if milli {
+			cpus /= 1000 //nolint:gomnd
+		}
+		result.cpus = cpus
+	}
+	if memory := strings.TrimSpace(resources.Memory); memory != "" {
+		size, err := parseMemory(memory)
+		if err != nil {
+			return limits{}, fmt.Errorf("the job's memory %q is not a size", resources.Memory)
+		}
+		result.memory = size
+	}
+	return result, nil
+}
+
+var memoryUnits = map[string]uint64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "ki": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mi": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gi": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "ti": 1 << 40, "tib": 1 << 40,
+}
+
+// bytes, the units are all powers of 1024 whether or not they have the i
+func parseMemory(memory string) (uint64, error) {
+	number := strings.TrimRightFunc(memory, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	unit, ok := memoryUnits[strings.ToLower(strings.TrimSpace(memory[len(number):]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in %s", memory)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s is not a size", memory)
+	}
+	return uint64(value * float64(unit)), nil
+}
+
+// the deal's spec is the part of the machine it paid for, it replaces
+// what the module asks for like the bacalhau executor does
+func dealLimits(resources bacalhau.ResourceUsageConfig, spec data.MachineSpec) bacalhau.ResourceUsageConfig {
+	if spec.CPU > 0 {
+		// milli-cpus
+		resources.CPU = fmt.Sprintf("%dm", spec.CPU)
+	}
+	if spec.RAM > 0 {
+		resources.Memory = fmt.Sprintf("%dMiB", spec.RAM)
+	}
+	return resources
+}
+
+// the devices the job gets, the resource provider hands each job its own
+// in NVIDIA_VISIBLE_DEVICES, without that it gets all of them
+func gpuDevices(job bacalhau.Job) ([]string, error) {
+	gpu := strings.TrimSpace(job.Spec.Resources.GPU)
+	if gpu == "" || gpu == "0" {
+		return nil, nil
+	}
+	if count, err := strconv.Atoi(gpu); err != nil || count < 0 {
+		return nil, fmt.Errorf("the job's gpu %q is not a number of gpus", job.Spec.Resources.GPU)
+	}
+	for _, variable := range job.Spec.Docker.EnvironmentVariables {
+		if visible, ok := strings.CutPrefix(variable, "NVIDIA_VISIBLE_DEVICES="); ok && visible != "" {
+			return strings.Split(visible, ","), nil
+		}
+	}
+	return []string{"all"}, nil
+}
+
+// the `run` args for the job, everything after the image is the command,
+// docker's --entrypoint only takes the program so the rest of the
+// module's entrypoint goes before its parameters, envFile holds the job's
+// secrets when it has any
+func runArgs(runtime containerRuntime, name string, job bacalhau.Job, jobLimits limits, outputs string, mounts []executorlib.InputMount, envFile string) ([]string, error) {
+	docker := job.Spec.Docker
+	if docker.Image == "" {
+		return nil, fmt.Errorf("the job has no image to run")
+	}
+	args := []string{"run", "--name", name, "--label", "lilypad.job=" + name}
+
+	switch job.Spec.Network.Type {
+	case bacalhau.NetworkNone:
+		args = append(args, "--network", "none")
+	case bacalhau.NetworkFull:
+	default:
+		// there is no proxy limiting the job to its domains like bacalhau
+		// has so it is refused rather than given the whole network
+		return nil, fmt.Errorf("the %s executor cannot limit a job to %s networking", runtime.name, job.Spec.Network.Type)
+	}
+
+	if jobLimits.cpus > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(jobLimits.cpus, 'f', -1, 64))
+	}
+	if jobLimits.memory > 0 {
+		args = append(args, "--memory", strconv.FormatUint(jobLimits.memory, 10))
+	}
+	devices, err := gpuDevices(job)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) > 0 {
+		args = append(args, runtime.gpuArgs(devices)...)
+	}
+
+	for _, variable := range docker.EnvironmentVariables {
+		args = append(args, "--env", variable)
+	}
+	if envFile != "" {
+		args = append(args, "--env-file", envFile)
+	}
+	args = append(args, "--volume", outputs+":/outputs")
+	for _, mount := range mounts {
+		args = append(args, "--volume", mount.Source+":"+mount.Target+":ro")
+	}
+	if docker.WorkingDirectory != "" {
+		args = append(args, "--workdir", docker.WorkingDirectory)
+	}
+	if len(docker.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", docker.Entrypoint[0])
+	}
+
+	args = append(args, docker.Image)
+	if len(docker.Entrypoint) > 1 {
+		args = append(args, docker.Entrypoint[1:]...)
+	}
+	return append(args, docker.Parameters...), nil
+}
@@ -0,0 +1,136 @@
+//go:build unit
+
+package container
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimits(t *testing.T) {
+	jobLimits, err := parseLimits(bacalhau.ResourceUsageConfig{CPU: "1500m", Memory: "512MiB"})
+	assert.NoError(t, err)
+	assert.Equal(t, limits{cpus: 1.5, memory: 512 << 20}, jobLimits)
+
+	jobLimits, err = parseLimits(bacalhau.ResourceUsageConfig{CPU: "2", Memory: "8gb"})
+	assert.NoError(t, err)
+	assert.Equal(t, limits{cpus: 2, memory: 8 << 30}, jobLimits)
+
+	jobLimits, err = parseLimits(bacalhau.ResourceUsageConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, limits{}, jobLimits)
+
+	_, err = parseLimits(bacalhau.ResourceUsageConfig{CPU: "lots"})
+	assert.Error(t, err)
+	_, err = parseLimits(bacalhau.ResourceUsageConfig{Memory: "lots"})
+	assert.Error(t, err)
+}
+
+func TestDealLimits(t *testing.T) {
+	resources := dealLimits(bacalhau.ResourceUsageConfig{CPU: "8", Memory: "64gb", GPU: "1"}, data.MachineSpec{CPU: 1500})
+	assert.Equal(t, bacalhau.ResourceUsageConfig{CPU: "1500m", Memory: "64gb", GPU: "1"}, resources)
+}
+
+func TestRunArgs(t *testing.T) {
+	job := bacalhau.Job{}
+	job.Spec.Docker = bacalhau.JobSpecDocker{
+		Image:                "ghcr.io/acme/model:v1",
+		Entrypoint:           []string{"python", "run.py"},
+		Parameters:           []string{"--steps", "10"},
+		EnvironmentVariables: []string{"PROMPT=hello"},
+		WorkingDirectory:     "/app",
+	}
+	mounts := []executorlib.InputMount{{Source: "/data/inputs/0", Target: "/inputs/prompt"}}
+	args, err := runArgs(podmanRuntime, "lilypad-deal", job, limits{cpus: 1.5, memory: 1024}, "/data/outputs", mounts, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"run", "--name", "lilypad-deal", "--label", "lilypad.job=lilypad-deal",
+		"--network", "none",
+		"--cpus", "1.5", "--memory", "1024",
+		"--env", "PROMPT=hello",
+		"--volume", "/data/outputs:/outputs",
+		"--volume", "/data/inputs/0:/inputs/prompt:ro",
+		"--workdir", "/app",
+		"--entrypoint", "python",
+		"ghcr.io/acme/model:v1", "run.py", "--steps", "10",
+	}, args)
+
+	// the image's own entrypoint is kept when the module has none
+	job.Spec.Docker.Entrypoint = nil
+	job.Spec.Network.Type = bacalhau.NetworkFull
+	args, err = runArgs(podmanRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, "")
+	assert.NoError(t, err)
+	assert.NotContains(t, args, "--entrypoint")
+	assert.NotContains(t, args, "--network")
+	assert.Equal(t, []string{"ghcr.io/acme/model:v1", "--steps", "10"}, args[len(args)-3:])
+
+	job.Spec.Network.Type = bacalhau.NetworkHTTP
+	_, err = runArgs(podmanRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, "")
+	assert.Error(t, err)
+}
+
+func TestGPUArgs(t *testing.T) {
+	job := bacalhau.Job{}
+	job.Spec.Docker.Image = "ghcr.io/acme/model:v1"
+	job.Spec.Resources.GPU = "2"
+	job.Spec.Docker.EnvironmentVariables = []string{"NVIDIA_VISIBLE_DEVICES=1,3"}
+
+	args, err := runArgs(podmanRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, "")
+	assert.NoError(t, err)
+	assert.Subset(t, args, []string{"--device", "nvidia.com/gpu=1", "nvidia.com/gpu=3"})
+
+	args, err = runArgs(containerdRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, "")
+	assert.NoError(t, err)
+	assert.Subset(t, args, []string{"--gpus", `"device=1,3"`})
+
+	// without the provider's devices the job gets all of them
+	job.Spec.Docker.EnvironmentVariables = nil
+	args, err = runArgs(containerdRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, "")
+	assert.NoError(t, err)
+	assert.Subset(t, args, []string{"--gpus", "all"})
+
+	job.Spec.Resources.GPU = "0"
+	args, err = runArgs(podmanRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, "")
+	assert.NoError(t, err)
+	assert.NotContains(t, args, "--device")
+}
+
+func TestSecretsEnvFile(t *testing.T) {
+	if _, err := executorlib.SecretsDir(); err != nil {
+		t.Skip(err.Error())
+	}
+	secrets := map[string]string{"API_KEY": "sk=123", "HF_TOKEN": "hf_abc"}
+	envFile, err := writeSecretsEnvFile(secrets)
+	assert.NoError(t, err)
+	defer os.Remove(envFile)
+
+	info, err := os.Stat(envFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	contents, err := os.ReadFile(envFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "API_KEY=sk=123\nHF_TOKEN=hf_abc\n", string(contents))
+
+	// the module's own API_KEY gives way to the secret and no value is in the args
+	job := bacalhau.Job{}
+	job.Spec.Docker = bacalhau.JobSpecDocker{
+		Image:                "ghcr.io/acme/model:v1",
+		EnvironmentVariables: executorlib.WithoutSecrets([]string{"MODEL=sdxl", "API_KEY=placeholder"}, secrets),
+	}
+	args, err := runArgs(podmanRuntime, "lilypad-deal", job, limits{}, "/data/outputs", nil, envFile)
+	assert.NoError(t, err)
+	assert.Subset(t, args, []string{"--env", "MODEL=sdxl", "--env-file", envFile})
+	for _, arg := range args {
+		assert.NotContains(t, arg, "sk=123")
+		assert.NotContains(t, arg, "hf_abc")
+		assert.NotContains(t, arg, "API_KEY")
+	}
+
+	_, err = writeSecretsEnvFile(map[string]string{"CERT": "line\nbreak"})
+	assert.Error(t, err)
+}
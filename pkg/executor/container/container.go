@@ -0,0 +1,274 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+const RESULTS_DIR = "container-results"
+const INPUTS_DIR = "container-inputs"
+
+// docker, podman and nerdctl all exit with 125 when they could not start
+// the container at all, any other code is the job's own
+const runtimeFailedExitCode = 125
+
+type ContainerExecutorOptions struct {
+	// the podman or nerdctl binary, the runtime's usual one on the PATH
+	// when it is empty
+	Binary string
+	// the containerd namespace jobs run in, podman ignores it
+	Namespace string
+	// where ipfs inputs are downloaded from
+	IPFSGateway string
+	// run the job's container with the cpu and memory from the deal
+	EnforceLimits bool
+}
+
+// runs jobs straight on this machine with a docker compatible cli, for
+// providers that cannot or would rather not run docker and bacalhau
+type ContainerExecutor struct {
+	Options ContainerExecutorOptions
+	runtime containerRuntime
+}
+
+func newContainerExecutor(runtime containerRuntime, options ContainerExecutorOptions) (*ContainerExecutor, error) {
+	if options.Binary == "" {
+		options.Binary = runtime.binary
+	}
+	return &ContainerExecutor{
+		Options: options,
+		runtime: runtime,
+	}, nil
+}
+
+func (executor *ContainerExecutor) command(ctx context.Context, args ...string) *exec.Cmd {
+	if executor.runtime.name == containerdRuntime.name && executor.Options.Namespace != "" {
+		args = append([]string{"--namespace", executor.Options.Namespace}, args...)
+	}
+	return exec.CommandContext(ctx, executor.Options.Binary, args...)
+}
+
+// the output of the command, with its stderr in the error when it fails
+func (executor *ContainerExecutor) output(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := executor.command(context.Background(), args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running %s %s: %s %s", executor.Options.Binary, args[0], err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// there is no node id like bacalhau has, the host name is as stable
+func (executor *ContainerExecutor) Id() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("error getting the host name %s", err.Error())
+	}
+	return fmt.Sprintf("%s-%s", executor.runtime.name, hostname), nil
+}
+
+func (executor *ContainerExecutor) IsAvailable() (bool, error) {
+	if _, err := exec.LookPath(executor.Options.Binary); err != nil {
+		return false, fmt.Errorf("%s is not installed, please install it or point the executor at its binary. %w", executor.Options.Binary, err)
+	}
+	if _, err := executor.output("version"); err != nil {
+		return false, fmt.Errorf("%s is not currently available. Please ensure that %s is running, then try again. %w", executor.runtime.name, executor.runtime.name, err)
+	}
+	return true, nil
+}
+
+// the whole machine, the gpus are added by the resource provider from
+// nvidia-smi
+func (executor *ContainerExecutor) GetMachineSpecs() ([]data.MachineSpec, error) {
+	info, err := executor.output("info", "--format", executor.runtime.infoFormat)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(info)
+	if len(fields) != 2 { //nolint:gomnd
+		return nil, fmt.Errorf("%s info gave %q rather than its cpus and memory", executor.Options.Binary, info)
+	}
+	cpus, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s info gave %q as its cpus", executor.Options.Binary, fields[0])
+	}
+	memory, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s info gave %q as its memory", executor.Options.Binary, fields[1])
+	}
+	return []data.MachineSpec{{
+		CPU: cpus * 1000, //nolint:gomnd
+		RAM: int(memory >> 20),
+	}}, nil
+}
+
+func (executor *ContainerExecutor) RunJob(
+	deal data.DealContainer,
+	module data.Module,
+) (*executorlib.ExecutorResults, error) {
+	return executor.runJob(deal, module, nil)
+}
+
+// the secrets go to the cli in an env file, as --env they would be in the
+// process list and the container's config
+func (executor *ContainerExecutor) RunJobWithSecrets(
+	deal data.DealContainer,
+	module data.Module,
+	secrets map[string]string,
+) (*executorlib.ExecutorResults, error) {
+	return executor.runJob(deal, module, secrets)
+}
+
+func (executor *ContainerExecutor) runJob(
+	deal data.DealContainer,
+	module data.Module,
+	secrets map[string]string,
+) (*executorlib.ExecutorResults, error) {
+	job := module.Job
+	job.Spec.Docker.EnvironmentVariables = executorlib.WithoutSecrets(job.Spec.Docker.EnvironmentVariables, secrets)
+	if executor.Options.EnforceLimits {
+		job.Spec.Resources = dealLimits(job.Spec.Resources, deal.Deal.JobOffer.Spec)
+	}
+	jobLimits, err := parseLimits(job.Spec.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsDir, err := system.EnsureDataDir(filepath.Join(RESULTS_DIR, deal.ID))
+	if err != nil {
+		return nil, fmt.Errorf("error creating results directory: %s", err.Error())
+	}
+	outputsDir := filepath.Join(resultsDir, "outputs")
+	if err := os.MkdirAll(outputsDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating outputs directory: %s", err.Error())
+	}
+	inputsDir, err := system.EnsureDataDir(filepath.Join(INPUTS_DIR, deal.ID))
+	if err != nil {
+		return nil, fmt.Errorf("error creating inputs directory: %s", err.Error())
+	}
+	defer os.RemoveAll(inputsDir)
+	mounts, err := executorlib.FetchInputs(job.Spec.Inputs, inputsDir, executor.Options.IPFSGateway)
+	if err != nil {
+		return nil, err
+	}
+
+	envFile := ""
+	if len(secrets) > 0 {
+		envFile, err = writeSecretsEnvFile(secrets)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(envFile)
+	}
+
+	name := "lilypad-" + deal.ID
+	args, err := runArgs(executor.runtime, name, job, jobLimits, outputsDir, mounts, envFile)
+	if err != nil {
+		return nil, err
+	}
+	// a container left from an earlier attempt at the deal has the name
+	executor.remove(name)
+	defer executor.remove(name)
+
+	ctx := context.Background()
+	if job.Spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.Spec.Timeout)*time.Second)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := executor.command(ctx, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		// killing the cli does not always stop the container, the deferred
+		// remove does
+		return nil, fmt.Errorf("%w: job %s stopped after %ds", executorlib.ErrExecutionTimeout, name, job.Spec.Timeout)
+	}
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		return nil, fmt.Errorf("error running job %s: %s", name, err.Error())
+	}
+	if exitCode == runtimeFailedExitCode {
+		return nil, fmt.Errorf("%s could not run job %s: %s", executor.runtime.name, name, strings.TrimSpace(stderr.String()))
+	}
+	if oomKilled, _ := executor.output("inspect", "--format", "{{.State.OOMKilled}}", name); oomKilled == "true" {
+		return nil, fmt.Errorf("%w: job %s went over its memory limit", executorlib.ErrResourceLimitExceeded, name)
+	}
+
+	files := map[string][]byte{
+		"stdout":   stdout.Bytes(),
+		"stderr":   stderr.Bytes(),
+		"exitCode": []byte(strconv.Itoa(exitCode)),
+	}
+	for file, content := range files {
+		if err := system.WriteFile(filepath.Join(resultsDir, file), content); err != nil {
+			return nil, fmt.Errorf("error creating %s file %s -> %s", file, deal.ID, err.Error())
+		}
+	}
+
+	cid, err := executorlib.GenerateCID(resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing results: %s", err.Error())
+	}
+	return &executorlib.ExecutorResults{
+		ResultsDir:       resultsDir,
+		ResultsCID:       cid,
+		InstructionCount: 1,
+	}, nil
+}
+
+// NAME=value lines in a file only we can read on a tmpfs, the cli reads it
+// and the values are never in its args
+func writeSecretsEnvFile(secrets map[string]string) (string, error) {
+	dir, err := executorlib.SecretsDir()
+	if err != nil {
+		return "", err
+	}
+	var env strings.Builder
+	for _, name := range executorlib.SecretNames(secrets) {
+		// an env file has no way to quote a line break
+		if strings.ContainsAny(secrets[name], "\r\n") {
+			return "", fmt.Errorf("secret %s has a line break, which an env file cannot hold", name)
+		}
+		env.WriteString(name + "=" + secrets[name] + "\n")
+	}
+	// CreateTemp makes the file 0600
+	file, err := os.CreateTemp(dir, "lilypad-secrets-")
+	if err != nil {
+		return "", fmt.Errorf("error creating the job's env file: %s", err.Error())
+	}
+	defer file.Close()
+	if _, err := file.WriteString(env.String()); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing the job's env file: %s", err.Error())
+	}
+	return file.Name(), nil
+}
+
+// a container that is not there is not an error
+func (executor *ContainerExecutor) remove(name string) {
+	_ = executor.command(context.Background(), "rm", "--force", name).Run()
+}
+
+// Compile-time interface check:
+var _ executorlib.Executor = (*ContainerExecutor)(nil)
+var _ executorlib.SecretsRunner = (*ContainerExecutor)(nil)
@@ -0,0 +1,60 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+)
+
+// what differs between the runtimes, they all take docker's run, inspect
+// and rm so the rest of a job is the same
+type containerRuntime struct {
+	// podman or containerd, the name in errors and the executor id
+	name string
+	// the cli used when the options do not give one
+	binary string
+	// go template for the cpu count and the memory in bytes of `info`
+	infoFormat string
+	// the args that give the container the host's gpus, devices is "all"
+	// or the host indexes the executor was given
+	gpuArgs func(devices []string) []string
+}
+
+// podman finds the nvidia devices through CDI, `nvidia-ctk cdi generate`
+// has to have written their spec for this to work
+var podmanRuntime = containerRuntime{
+	name:       "podman",
+	binary:     "podman",
+	infoFormat: "{{.Host.CPUs}} {{.Host.MemTotal}}",
+	gpuArgs: func(devices []string) []string {
+		args := []string{}
+		for _, device := range devices {
+			args = append(args, "--device", "nvidia.com/gpu="+device)
+		}
+		return args
+	},
+}
+
+// containerd has no cli of its own that runs containers the docker way,
+// nerdctl is the one it ships
+var containerdRuntime = containerRuntime{
+	name:       "containerd",
+	binary:     "nerdctl",
+	infoFormat: "{{.NCPU}} {{.MemTotal}}",
+	gpuArgs: func(devices []string) []string {
+		if len(devices) == 1 && devices[0] == "all" {
+			return []string{"--gpus", "all"}
+		}
+		// quoted as the flag is csv and the device list has commas in it
+		return []string{"--gpus", fmt.Sprintf(`"device=%s"`, strings.Join(devices, ","))}
+	},
+}
+
+func NewPodmanExecutor(options ContainerExecutorOptions) (*ContainerExecutor, error) {
+	return newContainerExecutor(podmanRuntime, options)
+}
+
+// the containerd namespace in the options keeps the jobs apart from the
+// containers of anything else on the node, kubernetes uses k8s.io
+func NewContainerdExecutor(options ContainerExecutorOptions) (*ContainerExecutor, error) {
+	return newContainerExecutor(containerdRuntime, options)
+}
@@ -0,0 +1,329 @@
+package firecracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker/guest"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+const RESULTS_DIR = "firecracker-results"
+const JOBS_DIR = "firecracker-jobs"
+
+// how much of the vm's console is kept for the error when the job did not
+// finish
+const consoleTail = 4096
+
+type FirecrackerExecutorOptions struct {
+	// the firecracker and jailer binaries, the ones on the PATH when empty
+	Firecracker string
+	Jailer      string
+	// the uncompressed kernel the vms boot
+	Kernel string
+	// a static build of cmd/firecracker-init, copied into every rootfs to
+	// run the job
+	Init string
+	// where the jailer makes each vm's chroot
+	ChrootBase string
+	// the unprivileged user and group firecracker runs as in the jail
+	UID int
+	GID int
+	// the docker or podman that module images are pulled and exported with
+	ImageTool string
+	// where ipfs inputs are downloaded from
+	IPFSGateway string
+	// the megabytes of free space in the rootfs and job drive for the job
+	// to write to
+	ScratchSize int
+	// the megabytes of memory a vm gets when the deal does not say
+	DefaultMemory int
+}
+
+// runs each job in a firecracker microvm started by the jailer, for
+// providers who want the job kept from their host and from each other by
+// more than a container
+type FirecrackerExecutor struct {
+	Options FirecrackerExecutorOptions
+	rootfs  *rootfsCache
+}
+
+func NewFirecrackerExecutor(options FirecrackerExecutorOptions) (*FirecrackerExecutor, error) {
+	if options.Firecracker == "" {
+		options.Firecracker = "firecracker"
+	}
+	if options.Jailer == "" {
+		options.Jailer = "jailer"
+	}
+	if options.ImageTool == "" {
+		options.ImageTool = "docker"
+	}
+	return &FirecrackerExecutor{
+		Options: options,
+		rootfs:  &rootfsCache{options: options},
+	}, nil
+}
+
+// there is no node id like bacalhau has, the host name is as stable
+func (executor *FirecrackerExecutor) Id() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("error getting the host name %s", err.Error())
+	}
+	return "firecracker-" + hostname, nil
+}
+
+func (executor *FirecrackerExecutor) IsAvailable() (bool, error) {
+	if _, err := os.Stat("/dev/kvm"); err != nil {
+		return false, fmt.Errorf("firecracker needs kvm, please check virtualization is enabled and /dev/kvm is there. %w", err)
+	}
+	binaries := []string{executor.Options.Firecracker, executor.Options.Jailer, executor.Options.ImageTool, "mkfs.ext4", "debugfs", "tar", "cp"}
+	for _, binary := range binaries {
+		if _, err := exec.LookPath(binary); err != nil {
+			return false, fmt.Errorf("%s is not installed, please install it or point the executor at its binary. %w", binary, err)
+		}
+	}
+	for _, file := range []string{executor.Options.Kernel, executor.Options.Init} {
+		if _, err := os.Stat(file); err != nil {
+			return false, fmt.Errorf("the firecracker executor cannot find %s. %w", file, err)
+		}
+	}
+	return true, nil
+}
+
+// the whole machine, the vms share it with nothing else of lilypad's
+func (executor *FirecrackerExecutor) GetMachineSpecs() ([]data.MachineSpec, error) {
+	meminfo, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return nil, fmt.Errorf("error reading the machine's memory: %s", err.Error())
+	}
+	memory, err := parseMemTotal(meminfo)
+	if err != nil {
+		return nil, err
+	}
+	return []data.MachineSpec{{
+		CPU: runtime.NumCPU() * 1000, //nolint:gomnd
+		RAM: memory,
+	}}, nil
+}
+
+func (executor *FirecrackerExecutor) RunJob(
+	deal data.DealContainer,
+	module data.Module,
+) (*executorlib.ExecutorResults, error) {
+	job := module.Job
+	spec := deal.Deal.JobOffer.Spec
+	if err := checkJob(job, spec); err != nil {
+		return nil, err
+	}
+	firecracker, err := exec.LookPath(executor.Options.Firecracker)
+	if err != nil {
+		return nil, fmt.Errorf("error finding firecracker: %s", err.Error())
+	}
+	// the jailer wants the path as it is, not a link to it
+	if firecracker, err = filepath.EvalSymlinks(firecracker); err != nil {
+		return nil, fmt.Errorf("error finding firecracker: %s", err.Error())
+	}
+	rootfs, image, err := executor.rootfs.get(job.Spec.Docker.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	id := jailID(deal.ID)
+	jailDir := filepath.Join(executor.Options.ChrootBase, filepath.Base(firecracker), id)
+	// a jail left from an earlier attempt at the deal has the id
+	os.RemoveAll(jailDir)
+	defer os.RemoveAll(jailDir)
+	jailRoot := filepath.Join(jailDir, "root")
+	if err := os.MkdirAll(jailRoot, 0755); err != nil {
+		return nil, fmt.Errorf("error creating the jail: %s", err.Error())
+	}
+	if err := executor.makeJobDrive(deal.ID, resolveJob(job.Spec.Docker, image), job, filepath.Join(jailRoot, jobDriveFile)); err != nil {
+		return nil, err
+	}
+	if err := executor.fillJail(jailRoot, rootfs, buildVMConfig(spec, executor.Options.DefaultMemory)); err != nil {
+		return nil, fmt.Errorf("error preparing the jail for job %s: %s", id, err.Error())
+	}
+
+	ctx := context.Background()
+	if job.Spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.Spec.Timeout)*time.Second)
+		defer cancel()
+	}
+	// the jailer execs firecracker so killing it stops the vm
+	var console bytes.Buffer
+	cmd := exec.CommandContext(ctx, executor.Options.Jailer,
+		"--id", id,
+		"--exec-file", firecracker,
+		"--uid", strconv.Itoa(executor.Options.UID),
+		"--gid", strconv.Itoa(executor.Options.GID),
+		"--chroot-base-dir", executor.Options.ChrootBase,
+		"--",
+		"--config-file", configFile,
+		"--no-api",
+	)
+	cmd.Stdout = &console
+	cmd.Stderr = &console
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: job %s stopped after %ds", executorlib.ErrExecutionTimeout, id, job.Spec.Timeout)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("error running job %s: %s", id, err.Error())
+	}
+
+	resultsDir, err := system.EnsureDataDir(filepath.Join(RESULTS_DIR, deal.ID))
+	if err != nil {
+		return nil, fmt.Errorf("error creating results directory: %s", err.Error())
+	}
+	if err := readJobDrive(filepath.Join(jailRoot, jobDriveFile), resultsDir); err != nil {
+		return nil, fmt.Errorf("error fetching results of job %s: %s", id, err.Error())
+	}
+	exitCode, err := os.ReadFile(filepath.Join(resultsDir, guest.ExitCodeFile))
+	if err == nil {
+		_, err = parseExitCode(exitCode)
+	}
+	if err != nil {
+		// the guest writes the exit code last, without it the vm did not
+		// get to the end of the job and its console says why
+		tail := console.Bytes()
+		if len(tail) > consoleTail {
+			tail = tail[len(tail)-consoleTail:]
+		}
+		return nil, fmt.Errorf("the vm of job %s stopped before the job finished: %s", id, strings.TrimSpace(string(tail)))
+	}
+
+	cid, err := executorlib.GenerateCID(resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing results: %s", err.Error())
+	}
+	return &executorlib.ExecutorResults{
+		ResultsDir:       resultsDir,
+		ResultsCID:       cid,
+		InstructionCount: 1,
+	}, nil
+}
+
+// the job, its inputs and an empty outputs dir on the drive the guest
+// mounts, the guest writes the results onto it as well
+func (executor *FirecrackerExecutor) makeJobDrive(dealID string, vmJob guest.Job, job bacalhau.Job, path string) error {
+	dir, err := system.EnsureDataDir(filepath.Join(JOBS_DIR, dealID))
+	if err != nil {
+		return fmt.Errorf("error creating job directory: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	inputsDir := filepath.Join(dir, guest.InputsDir)
+	for _, sub := range []string{inputsDir, filepath.Join(dir, guest.OutputsDir)} {
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			return fmt.Errorf("error creating job directory: %s", err.Error())
+		}
+	}
+	mounts, err := executorlib.FetchInputs(job.Spec.Inputs, inputsDir, executor.Options.IPFSGateway)
+	if err != nil {
+		return err
+	}
+	for _, mount := range mounts {
+		source, err := filepath.Rel(inputsDir, mount.Source)
+		if err != nil {
+			return err
+		}
+		vmJob.Mounts = append(vmJob.Mounts, guest.Mount{Source: source, Target: mount.Target})
+	}
+	body, err := json.Marshal(vmJob)
+	if err != nil {
+		return fmt.Errorf("error encoding job %s: %s", dealID, err.Error())
+	}
+	if err := system.WriteFile(filepath.Join(dir, guest.JobFile), body); err != nil {
+		return fmt.Errorf("error writing job %s: %s", dealID, err.Error())
+	}
+	size, err := dirSize(dir)
+	if err != nil {
+		return err
+	}
+	if err := makeExt4(dir, path, size+executor.Options.ScratchSize); err != nil {
+		return fmt.Errorf("error making the job drive: %s", err.Error())
+	}
+	return nil
+}
+
+// the kernel, a copy of the image's rootfs and the config next to the job
+// drive, all owned by the user firecracker runs as
+func (executor *FirecrackerExecutor) fillJail(root string, rootfs string, config vmConfig) error {
+	kernel := filepath.Join(root, kernelFile)
+	if err := os.Link(executor.Options.Kernel, kernel); err != nil {
+		if err := copyFile(executor.Options.Kernel, kernel, 0644); err != nil {
+			return err
+		}
+	}
+	if _, err := run("cp", "--sparse=always", rootfs, filepath.Join(root, rootfsFile)); err != nil {
+		return err
+	}
+	body, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := system.WriteFile(filepath.Join(root, configFile), body); err != nil {
+		return err
+	}
+	// the kernel may be a link to the shared one, it only has to be read
+	for _, file := range []string{rootfsFile, jobDriveFile, configFile} {
+		if err := os.Chown(filepath.Join(root, file), executor.Options.UID, executor.Options.GID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the outputs, logs and exit code the guest left on the job drive, read
+// with debugfs so the drive is never mounted on the host
+func readJobDrive(drive string, resultsDir string) error {
+	// debugfs will not write over the results of an earlier attempt
+	for _, file := range []string{guest.OutputsDir, guest.StdoutFile, guest.StderrFile, guest.ExitCodeFile} {
+		if err := os.RemoveAll(filepath.Join(resultsDir, file)); err != nil {
+			return err
+		}
+	}
+	if _, err := run("debugfs", "-R", fmt.Sprintf("rdump /%s %s", guest.OutputsDir, resultsDir), drive); err != nil {
+		return err
+	}
+	for _, file := range []string{guest.StdoutFile, guest.StderrFile, guest.ExitCodeFile} {
+		if _, err := run("debugfs", "-R", fmt.Sprintf("dump /%s %s", file, filepath.Join(resultsDir, file)), drive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the MemTotal of /proc/meminfo in megabytes
+func parseMemTotal(meminfo []byte) (int, error) {
+	for _, line := range strings.Split(string(meminfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "MemTotal:" { //nolint:gomnd
+			continue
+		}
+		kilobytes, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("/proc/meminfo gave %q as the machine's memory", fields[1])
+		}
+		return kilobytes >> 10, nil
+	}
+	return 0, fmt.Errorf("/proc/meminfo does not have the machine's memory")
+}
+
+// Compile-time interface check:
+var _ executorlib.Executor = (*FirecrackerExecutor)(nil)
@@ -0,0 +1,36 @@
+// the init the firecracker executor boots its microvms with, it runs the
+// job the host wrote to the job drive and powers the vm off again, it is
+// kept to the standard library so it builds as a small static binary
+package guest
+
+// where the host puts the job drive's files in the vm
+const JobMount = "/lilypad"
+
+// the job drive's files, relative to its root
+const (
+	JobFile      = "job.json"
+	StdoutFile   = "stdout"
+	StderrFile   = "stderr"
+	ExitCodeFile = "exitCode"
+	OutputsDir   = "outputs"
+	InputsDir    = "inputs"
+)
+
+// how the vm resolved the image's entrypoint, cmd, env and working
+// directory with the module's, so the guest only has to run it
+type Job struct {
+	Args       []string `json:"args"`
+	Env        []string `json:"env"`
+	WorkingDir string   `json:"working_dir"`
+	// bind mounted read only, the source is relative to the inputs dir
+	Mounts []Mount `json:"mounts"`
+}
+
+type Mount struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// the exit code when the job could not be started at all, the same one
+// container runtimes use
+const FailedExitCode = 125
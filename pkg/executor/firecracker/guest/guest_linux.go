@@ -0,0 +1,158 @@
+package guest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// runs as pid 1, whatever happens the vm is rebooted at the end which is
+// what makes firecracker exit, the host reads the exit code off the
+// job drive so a job that never ran is told apart by FailedExitCode
+func Run() {
+	exitCode, err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lilypad init: %s\n", err.Error())
+		_ = os.WriteFile(filepath.Join(JobMount, StderrFile), []byte(err.Error()), 0644)
+	}
+	_ = os.WriteFile(filepath.Join(JobMount, ExitCodeFile), []byte(strconv.Itoa(exitCode)), 0644)
+	syscall.Sync()
+	_ = syscall.Unmount(JobMount, 0)
+	_ = syscall.Reboot(syscall.LINUX_REBOOT_CMD_RESTART)
+}
+
+func run() (int, error) {
+	mounts := []struct {
+		source string
+		target string
+		fstype string
+	}{
+		{"proc", "/proc", "proc"},
+		{"sysfs", "/sys", "sysfs"},
+		{"devtmpfs", "/dev", "devtmpfs"},
+		{"tmpfs", "/tmp", "tmpfs"},
+		{"/dev/vdb", JobMount, "ext4"},
+	}
+	for _, mount := range mounts {
+		if err := os.MkdirAll(mount.target, 0755); err != nil {
+			return FailedExitCode, err
+		}
+		err := syscall.Mount(mount.source, mount.target, mount.fstype, 0, "")
+		// the kernel may have mounted devtmpfs already
+		if err != nil && !errors.Is(err, syscall.EBUSY) {
+			return FailedExitCode, fmt.Errorf("error mounting %s: %w", mount.target, err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(JobMount, JobFile))
+	if err != nil {
+		return FailedExitCode, err
+	}
+	var job Job
+	if err := json.Unmarshal(content, &job); err != nil {
+		return FailedExitCode, fmt.Errorf("error decoding the job: %w", err)
+	}
+	if len(job.Args) == 0 {
+		return FailedExitCode, fmt.Errorf("the job has nothing to run")
+	}
+
+	binds := append([]Mount{{Source: OutputsDir, Target: "/outputs"}}, job.Mounts...)
+	for i, bind := range binds {
+		source := filepath.Join(JobMount, bind.Source)
+		if i > 0 {
+			source = filepath.Join(JobMount, InputsDir, bind.Source)
+		}
+		if err := bindMount(source, bind.Target, i > 0); err != nil {
+			return FailedExitCode, err
+		}
+	}
+
+	stdout, err := os.Create(filepath.Join(JobMount, StdoutFile))
+	if err != nil {
+		return FailedExitCode, err
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(JobMount, StderrFile))
+	if err != nil {
+		return FailedExitCode, err
+	}
+	defer stderr.Close()
+
+	path, err := lookPath(job.Args[0], job.Env)
+	if err != nil {
+		return FailedExitCode, err
+	}
+	cmd := exec.Command(path, job.Args[1:]...)
+	cmd.Env = job.Env
+	cmd.Dir = job.WorkingDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			// the way a shell reports a process killed by a signal
+			return 128 + int(status.Signal()), nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return FailedExitCode, err
+	}
+	return 0, nil
+}
+
+// the target is made to match the source, a file for a file
+func bindMount(source string, target string, readOnly bool) error {
+	stat, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		err = os.MkdirAll(target, 0755)
+	} else if err = os.MkdirAll(filepath.Dir(target), 0755); err == nil {
+		var file *os.File
+		file, err = os.OpenFile(target, os.O_CREATE, 0644)
+		if err == nil {
+			file.Close()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error making the mount point %s: %w", target, err)
+	}
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("error mounting %s: %w", target, err)
+	}
+	if readOnly {
+		if err := syscall.Mount("", target, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY, ""); err != nil {
+			return fmt.Errorf("error making %s read only: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// exec.LookPath uses our own PATH, the job's is the one from its image
+func lookPath(name string, env []string) (string, error) {
+	if filepath.IsAbs(name) || filepath.Base(name) != name {
+		return name, nil
+	}
+	path := "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	for _, variable := range env {
+		if value, ok := strings.CutPrefix(variable, "PATH="); ok {
+			path = value
+		}
+	}
+	for _, dir := range filepath.SplitList(path) {
+		candidate := filepath.Join(dir, name)
+		if stat, err := os.Stat(candidate); err == nil && !stat.IsDir() && stat.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not in the job's PATH", name)
+}
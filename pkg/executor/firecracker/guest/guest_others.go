@@ -0,0 +1,14 @@
+//go:build !linux
+
+package guest
+
+import (
+	"fmt"
+	"os"
+)
+
+// firecracker only runs linux guests
+func Run() {
+	fmt.Fprintln(os.Stderr, "lilypad init only runs inside a linux microvm")
+	os.Exit(FailedExitCode)
+}
@@ -0,0 +1,178 @@
+package firecracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+const ROOTFS_DIR = "firecracker-rootfs"
+
+// the root filesystems made from module images, one per image id so a
+// tag that moves gets a new one
+type rootfsCache struct {
+	options FirecrackerExecutorOptions
+	mutex   sync.Mutex
+}
+
+// the output of the command, with its stderr in the error when it fails
+func run(binary string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(binary, args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running %s %s: %s %s", binary, args[0], err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// the image's id and config, pulling it first when it is not here yet
+func (cache *rootfsCache) inspect(image string) (string, imageConfig, error) {
+	tool := cache.options.ImageTool
+	output, err := run(tool, "image", "inspect", "--format", "{{.Id}} {{json .Config}}", image)
+	if err != nil {
+		if _, err := run(tool, "pull", image); err != nil {
+			return "", imageConfig{}, err
+		}
+		if output, err = run(tool, "image", "inspect", "--format", "{{.Id}} {{json .Config}}", image); err != nil {
+			return "", imageConfig{}, err
+		}
+	}
+	id, config, found := strings.Cut(output, " ")
+	if !found {
+		return "", imageConfig{}, fmt.Errorf("%s image inspect gave %q for %s", tool, output, image)
+	}
+	var imageConfig imageConfig
+	if err := json.Unmarshal([]byte(config), &imageConfig); err != nil {
+		return "", imageConfig, fmt.Errorf("error decoding the config of %s: %s", image, err.Error())
+	}
+	return strings.TrimPrefix(id, "sha256:"), imageConfig, nil
+}
+
+// the path of the image's root filesystem, made the first time a job
+// runs the image, the vm gets a copy so the cached one is never written
+func (cache *rootfsCache) get(image string) (string, imageConfig, error) {
+	id, config, err := cache.inspect(image)
+	if err != nil {
+		return "", config, err
+	}
+	dir, err := system.EnsureDataDir(ROOTFS_DIR)
+	if err != nil {
+		return "", config, fmt.Errorf("error creating rootfs directory: %s", err.Error())
+	}
+	path := filepath.Join(dir, id+".ext4")
+
+	// the same image is only exported once when jobs for it come together
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if _, err := os.Stat(path); err == nil {
+		return path, config, nil
+	}
+	if err := cache.build(image, path); err != nil {
+		return "", config, fmt.Errorf("error making a root filesystem of %s: %s", image, err.Error())
+	}
+	return path, config, nil
+}
+
+// the image's files and the init as an ext4 image with room for the job to
+// write to, made under a temporary name so a failed build is not cached
+func (cache *rootfsCache) build(image string, path string) error {
+	tool := cache.options.ImageTool
+	dir, err := os.MkdirTemp(filepath.Dir(path), "build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// the image is exported from a container as that flattens its layers,
+	// the container is never started so its command does not matter
+	container, err := run(tool, "create", image, initPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = run(tool, "rm", "--force", container) }()
+	export := exec.Command(tool, "export", container)
+	untar := exec.Command("tar", "--extract", "--numeric-owner", "--directory", dir)
+	var stderr bytes.Buffer
+	export.Stderr = &stderr
+	untar.Stderr = &stderr
+	if untar.Stdin, err = export.StdoutPipe(); err != nil {
+		return err
+	}
+	if err := untar.Start(); err != nil {
+		return err
+	}
+	if err := export.Run(); err != nil {
+		_ = untar.Wait()
+		return fmt.Errorf("error exporting %s: %s %s", image, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	if err := untar.Wait(); err != nil {
+		return fmt.Errorf("error unpacking %s: %s %s", image, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	if err := copyFile(cache.options.Init, filepath.Join(dir, initPath), 0755); err != nil {
+		return fmt.Errorf("error copying the init: %s", err.Error())
+	}
+
+	size, err := dirSize(dir)
+	if err != nil {
+		return err
+	}
+	building := path + ".building"
+	defer os.Remove(building)
+	if err := makeExt4(dir, building, size+cache.options.ScratchSize); err != nil {
+		return err
+	}
+	return os.Rename(building, path)
+}
+
+// an ext4 image of the dir's files of the given size in megabytes
+func makeExt4(dir string, path string, size int) error {
+	_, err := run("mkfs.ext4", "-q", "-F", "-d", dir, path, fmt.Sprintf("%dM", size))
+	return err
+}
+
+// the megabytes the dir's files take, rounded up with some over for the
+// filesystem's own use
+func dirSize(dir string) (int, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error sizing %s: %s", dir, err.Error())
+	}
+	return int(total>>20) + int(total>>20)/10 + 64, nil //nolint:gomnd
+}
+
+func copyFile(source string, destination string, mode os.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,156 @@
+package firecracker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker/guest"
+)
+
+// the files in the jail, firecracker is chrooted into it so its config
+// names them relative to the jail's root
+const (
+	kernelFile   = "vmlinux"
+	rootfsFile   = "rootfs.ext4"
+	jobDriveFile = "job.ext4"
+	configFile   = "config.json"
+	// where the init is copied into each rootfs
+	initPath = "/lilypad-init"
+)
+
+// firecracker will not boot a vm with more vcpus than this
+const maxVCPUs = 32
+
+// reboot=k makes the guest's reboot end firecracker, panic=1 does the same
+// for a kernel panic rather than leaving the vm hanging
+const bootArgs = "console=ttyS0 reboot=k panic=1 pci=off quiet init=" + initPath
+
+// the config file firecracker is started with in place of its api
+type vmConfig struct {
+	BootSource    bootSource    `json:"boot-source"`
+	Drives        []drive       `json:"drives"`
+	MachineConfig machineConfig `json:"machine-config"`
+}
+
+type bootSource struct {
+	KernelImagePath string `json:"kernel_image_path"`
+	BootArgs        string `json:"boot_args"`
+}
+
+type drive struct {
+	DriveID      string `json:"drive_id"`
+	PathOnHost   string `json:"path_on_host"`
+	IsRootDevice bool   `json:"is_root_device"`
+	IsReadOnly   bool   `json:"is_read_only"`
+}
+
+type machineConfig struct {
+	VCPUCount  int `json:"vcpu_count"`
+	MemSizeMib int `json:"mem_size_mib"`
+}
+
+// the vm is the part of the machine the deal paid for, whole vcpus as
+// firecracker has no smaller share of a cpu
+func buildVMConfig(spec data.MachineSpec, defaultMemory int) vmConfig {
+	vcpus := (spec.CPU + 999) / 1000 //nolint:gomnd
+	vcpus = min(max(vcpus, 1), maxVCPUs)
+	memory := spec.RAM
+	if memory <= 0 {
+		memory = defaultMemory
+	}
+	return vmConfig{
+		BootSource: bootSource{KernelImagePath: kernelFile, BootArgs: bootArgs},
+		// the job drive is the guest's /dev/vdb as it is the second one
+		Drives: []drive{
+			{DriveID: "rootfs", PathOnHost: rootfsFile, IsRootDevice: true},
+			{DriveID: "job", PathOnHost: jobDriveFile},
+		},
+		MachineConfig: machineConfig{VCPUCount: vcpus, MemSizeMib: memory},
+	}
+}
+
+// what a vm cannot give a job, there is no gpu passthrough and no network
+// device so only jobs that ask for neither are run
+func checkJob(job bacalhau.Job, spec data.MachineSpec) error {
+	if job.Spec.Docker.Image == "" {
+		return fmt.Errorf("the job has no image to run")
+	}
+	if gpu := strings.TrimSpace(job.Spec.Resources.GPU); (gpu != "" && gpu != "0") || spec.GPU > 0 || len(spec.GPUs) > 0 {
+		return fmt.Errorf("the firecracker executor cannot give a job gpus")
+	}
+	if job.Spec.Network.Type != bacalhau.NetworkNone {
+		return fmt.Errorf("the firecracker executor runs jobs without network, the job asks for %s networking", job.Spec.Network.Type)
+	}
+	return nil
+}
+
+// what the image runs when the module does not say
+type imageConfig struct {
+	Entrypoint []string `json:"Entrypoint"`
+	Cmd        []string `json:"Cmd"`
+	Env        []string `json:"Env"`
+	WorkingDir string   `json:"WorkingDir"`
+}
+
+// the module's entrypoint, parameters, env and working directory over the
+// image's the way docker run does it, a new entrypoint drops the image's
+// cmd as well
+func resolveJob(docker bacalhau.JobSpecDocker, image imageConfig) guest.Job {
+	args := append([]string{}, image.Entrypoint...)
+	command := image.Cmd
+	if len(docker.Entrypoint) > 0 {
+		args = append([]string{}, docker.Entrypoint...)
+		command = nil
+	}
+	if len(docker.Parameters) > 0 {
+		command = docker.Parameters
+	}
+	args = append(args, command...)
+
+	env := []string{}
+	overridden := map[string]bool{}
+	for _, variable := range docker.EnvironmentVariables {
+		name, _, _ := strings.Cut(variable, "=")
+		overridden[name] = true
+	}
+	for _, variable := range image.Env {
+		if name, _, _ := strings.Cut(variable, "="); !overridden[name] {
+			env = append(env, variable)
+		}
+	}
+	env = append(env, docker.EnvironmentVariables...)
+
+	workingDir := image.WorkingDir
+	if docker.WorkingDirectory != "" {
+		workingDir = docker.WorkingDirectory
+	}
+	if workingDir == "" {
+		workingDir = "/"
+	}
+	return guest.Job{Args: args, Env: env, WorkingDir: workingDir, Mounts: []guest.Mount{}}
+}
+
+var invalidJailCharacters = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// the jailer takes ids of up to 64 letters, digits and dashes
+func jailID(dealID string) string {
+	id := "lilypad-" + invalidJailCharacters.ReplaceAllString(dealID, "-")
+	if len(id) > 64 { //nolint:gomnd
+		id = id[:64]
+	}
+	return strings.TrimRight(id, "-")
+}
+
+// the exit code the guest wrote, what to do when there is none is up to
+// the caller as it means the vm died before the job could finish
+func parseExitCode(content []byte) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("the vm wrote %q as the job's exit code", string(content))
+	}
+	return code, nil
+}
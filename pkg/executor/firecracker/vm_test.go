@@ -0,0 +1,102 @@
+//go:build unit
+
+package firecracker
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker/guest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVMConfig(t *testing.T) {
+	config := buildVMConfig(data.MachineSpec{CPU: 1500, RAM: 2048}, 1024)
+	assert.Equal(t, machineConfig{VCPUCount: 2, MemSizeMib: 2048}, config.MachineConfig)
+	assert.Equal(t, kernelFile, config.BootSource.KernelImagePath)
+	assert.Contains(t, config.BootSource.BootArgs, "init="+initPath)
+	assert.Equal(t, []drive{
+		{DriveID: "rootfs", PathOnHost: rootfsFile, IsRootDevice: true},
+		{DriveID: "job", PathOnHost: jobDriveFile},
+	}, config.Drives)
+
+	config = buildVMConfig(data.MachineSpec{}, 1024)
+	assert.Equal(t, machineConfig{VCPUCount: 1, MemSizeMib: 1024}, config.MachineConfig)
+
+	config = buildVMConfig(data.MachineSpec{CPU: 64000}, 1024)
+	assert.Equal(t, maxVCPUs, config.MachineConfig.VCPUCount)
+}
+
+func TestCheckJob(t *testing.T) {
+	job := bacalhau.Job{}
+	assert.Error(t, checkJob(job, data.MachineSpec{}))
+
+	job.Spec.Docker.Image = "ghcr.io/acme/model:v1"
+	assert.NoError(t, checkJob(job, data.MachineSpec{CPU: 1000}))
+
+	job.Spec.Resources.GPU = "1"
+	assert.Error(t, checkJob(job, data.MachineSpec{}))
+	job.Spec.Resources.GPU = "0"
+	assert.NoError(t, checkJob(job, data.MachineSpec{}))
+	assert.Error(t, checkJob(job, data.MachineSpec{GPU: 1000}))
+
+	job.Spec.Network.Type = bacalhau.NetworkFull
+	assert.Error(t, checkJob(job, data.MachineSpec{}))
+}
+
+func TestResolveJob(t *testing.T) {
+	image := imageConfig{
+		Entrypoint: []string{"/entrypoint.sh"},
+		Cmd:        []string{"serve"},
+		Env:        []string{"PATH=/usr/bin", "PROMPT=default"},
+		WorkingDir: "/app",
+	}
+	assert.Equal(t, guest.Job{
+		Args:       []string{"/entrypoint.sh", "serve"},
+		Env:        []string{"PATH=/usr/bin", "PROMPT=default"},
+		WorkingDir: "/app",
+		Mounts:     []guest.Mount{},
+	}, resolveJob(bacalhau.JobSpecDocker{}, image))
+
+	// the module's parameters replace the image's cmd after its entrypoint
+	vmJob := resolveJob(bacalhau.JobSpecDocker{
+		Parameters:           []string{"--steps", "10"},
+		EnvironmentVariables: []string{"PROMPT=hello"},
+	}, image)
+	assert.Equal(t, []string{"/entrypoint.sh", "--steps", "10"}, vmJob.Args)
+	assert.Equal(t, []string{"PATH=/usr/bin", "PROMPT=hello"}, vmJob.Env)
+
+	// and a new entrypoint drops the image's cmd
+	vmJob = resolveJob(bacalhau.JobSpecDocker{
+		Entrypoint:       []string{"python", "run.py"},
+		WorkingDirectory: "/work",
+	}, image)
+	assert.Equal(t, []string{"python", "run.py"}, vmJob.Args)
+	assert.Equal(t, "/work", vmJob.WorkingDir)
+
+	assert.Equal(t, "/", resolveJob(bacalhau.JobSpecDocker{}, imageConfig{}).WorkingDir)
+}
+
+func TestJailID(t *testing.T) {
+	assert.Equal(t, "lilypad-abc-1", jailID("abc_1"))
+	assert.LessOrEqual(t, len(jailID("0x9f3b1c2d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9")), 64)
+}
+
+func TestParseMemTotal(t *testing.T) {
+	memory, err := parseMemTotal([]byte("MemTotal:       32617900 kB\nMemFree:        10240000 kB\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 31853, memory)
+
+	_, err = parseMemTotal([]byte("MemFree:        10240000 kB\n"))
+	assert.Error(t, err)
+}
+
+func TestParseExitCode(t *testing.T) {
+	code, err := parseExitCode([]byte("137\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 137, code)
+
+	_, err = parseExitCode([]byte{})
+	assert.Error(t, err)
+}
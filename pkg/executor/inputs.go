@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+)
+
+// a downloaded input, the file or directory on this machine and the path
+// the job sees it at
+type InputMount struct {
+	Source string
+	Target string
+}
+
+// bacalhau fetches the inputs itself, executors that run jobs on this
+// machine download them into the job's input directory with this and
+// mount them at their paths, ipfs inputs come from the gateway as a
+// tarball so a cid of a directory works as well
+func FetchInputs(inputs []bacalhau.StorageSpec, dir string, ipfsGateway string) ([]InputMount, error) {
+	mounts := []InputMount{}
+	for i, input := range inputs {
+		if input.Path == "" {
+			return nil, fmt.Errorf("input %s has no path to mount it at", inputName(input, i))
+		}
+		target := filepath.Join(dir, strconv.Itoa(i))
+		var err error
+		switch input.StorageSource {
+		case bacalhau.StorageSourceIPFS:
+			err = fetchIPFS(ipfsGateway, input.CID, target)
+			// the tarball's root is named after the cid
+			target = filepath.Join(target, input.CID)
+		case bacalhau.StorageSourceURLDownload:
+			err = download(input.URL, target)
+		default:
+			err = fmt.Errorf("cannot fetch %s inputs", input.StorageSource)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error fetching input %s: %w", inputName(input, i), err)
+		}
+		mounts = append(mounts, InputMount{Source: target, Target: input.Path})
+	}
+	return mounts, nil
+}
+
+func fetchIPFS(ipfsGateway string, cid string, dir string) error {
+	if cid == "" {
+		return fmt.Errorf("no cid")
+	}
+	gateway, err := url.Parse(ipfsGateway)
+	if err != nil {
+		return fmt.Errorf("error parsing ipfs gateway %s: %s", ipfsGateway, err.Error())
+	}
+	gateway = gateway.JoinPath("ipfs", cid)
+	gateway.RawQuery = "format=tar"
+	request, err := http.NewRequest(http.MethodGet, gateway.String(), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/x-tar")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status from %s: %s", gateway.Redacted(), response.Status)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ExtractTar(response.Body, dir)
+}
+
+func download(source string, path string) error {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return fmt.Errorf("%s is not an http url", source)
+	}
+	response, err := http.Get(source)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status: %s", response.Status)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, response.Body)
+	return err
+}
+
+func inputName(input bacalhau.StorageSpec, index int) string {
+	if input.Name != "" {
+		return input.Name
+	}
+	return strconv.Itoa(index)
+}
@@ -0,0 +1,268 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+const RESULTS_DIR = "kubernetes-results"
+
+type KubernetesExecutorOptions struct {
+	// the kubectl binary, the one on the PATH when empty
+	Kubectl string
+	// the kubeconfig to use, kubectl's own default or the pod's service
+	// account when empty
+	Kubeconfig string
+	// the namespace the jobs run in
+	Namespace string
+	// has the sh, wget and tar that fetch inputs and keep the outputs
+	// until they are copied off the pod
+	HelperImage string
+	// where the ipfs inputs are downloaded from
+	IPFSGateway string
+	// the secrets in the namespace that private module images are pulled
+	// with
+	ImagePullSecrets []string
+	// the seconds between looking at the job's pod
+	JobStatusPollInterval uint64
+}
+
+// runs each job as a kubernetes Job so spare cluster capacity can be
+// offered without hosts of its own
+type KubernetesExecutor struct {
+	Options KubernetesExecutorOptions
+	// the network policy only has to be applied once
+	networkMutex   sync.Mutex
+	networkApplied bool
+}
+
+func NewKubernetesExecutor(options KubernetesExecutorOptions) (*KubernetesExecutor, error) {
+	if options.Kubectl == "" {
+		options.Kubectl = "kubectl"
+	}
+	return &KubernetesExecutor{
+		Options: options,
+	}, nil
+}
+
+func (executor *KubernetesExecutor) kubectl(stdin []byte, args ...string) (string, error) {
+	global := []string{"--namespace", executor.Options.Namespace}
+	if executor.Options.Kubeconfig != "" {
+		global = append(global, "--kubeconfig", executor.Options.Kubeconfig)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(executor.Options.Kubectl, append(global, args...)...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error running kubectl %s: %s %s", strings.Join(args, " "), err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// the cluster's id, kube-system is there from the start and keeps its uid
+// for as long as the cluster does
+func (executor *KubernetesExecutor) Id() (string, error) {
+	uid, err := executor.kubectl(nil, "get", "namespace", "kube-system", "--output", "jsonpath={.metadata.uid}")
+	if err != nil {
+		return "", fmt.Errorf("error getting the cluster id %s", err.Error())
+	}
+	return "kubernetes-" + strings.TrimSpace(uid), nil
+}
+
+func (executor *KubernetesExecutor) IsAvailable() (bool, error) {
+	if _, err := exec.LookPath(executor.Options.Kubectl); err != nil {
+		return false, fmt.Errorf("%s is not installed, please install kubectl or point the executor at it. %w", executor.Options.Kubectl, err)
+	}
+	for _, resource := range []string{"jobs", "pods/exec"} {
+		allowed, err := executor.kubectl(nil, "auth", "can-i", "create", resource)
+		if err != nil || strings.TrimSpace(allowed) != "yes" {
+			return false, fmt.Errorf("the kubernetes executor cannot create %s in namespace %s. Please check the cluster is reachable and the executor's role, then try again. %v", resource, executor.Options.Namespace, err)
+		}
+	}
+	return true, nil
+}
+
+// one spec per node the jobs could be scheduled on
+func (executor *KubernetesExecutor) GetMachineSpecs() ([]data.MachineSpec, error) {
+	output, err := executor.kubectl(nil, "get", "nodes", "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseNodeSpecs([]byte(output))
+}
+
+func (executor *KubernetesExecutor) RunJob(
+	deal data.DealContainer,
+	module data.Module,
+) (*executorlib.ExecutorResults, error) {
+	return executor.runJob(deal, module, nil)
+}
+
+// the secrets go in a Secret of their own that the job's env refers to,
+// it is created before the job and deleted with it
+func (executor *KubernetesExecutor) RunJobWithSecrets(
+	deal data.DealContainer,
+	module data.Module,
+	secrets map[string]string,
+) (*executorlib.ExecutorResults, error) {
+	return executor.runJob(deal, module, secrets)
+}
+
+func (executor *KubernetesExecutor) runJob(
+	deal data.DealContainer,
+	module data.Module,
+	secrets map[string]string,
+) (*executorlib.ExecutorResults, error) {
+	manifest, err := buildJobManifest(executor.Options, deal, module.Job, secrets)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Spec.Template.Metadata.Labels[networkLabel] != "" {
+		if err := executor.applyNetworkPolicy(); err != nil {
+			return nil, err
+		}
+	}
+	name := manifest.Metadata.Name
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding job %s: %s", name, err.Error())
+	}
+	// a job left from an earlier attempt at the deal has the name
+	executor.delete(name)
+	defer executor.delete(name)
+	if len(secrets) > 0 {
+		secretBody, err := json.Marshal(buildSecretManifest(executor.Options, deal, secrets))
+		if err != nil {
+			return nil, fmt.Errorf("error encoding the secrets of job %s: %s", name, err.Error())
+		}
+		// over stdin so the values are not in kubectl's args
+		if _, err := executor.kubectl(secretBody, "create", "--filename", "-"); err != nil {
+			return nil, fmt.Errorf("error creating the secrets of job %s -> %s", deal.ID, err.Error())
+		}
+	}
+	if _, err := executor.kubectl(body, "create", "--filename", "-"); err != nil {
+		return nil, fmt.Errorf("error creating job %s -> %s", deal.ID, err.Error())
+	}
+
+	// like bacalhau the timeout covers the pod waiting to be scheduled
+	// and pulling its image as well as running
+	var deadline time.Time
+	if module.Job.Spec.Timeout > 0 {
+		deadline = time.Now().Add(time.Duration(module.Job.Spec.Timeout) * time.Second)
+	}
+	var pod *podStatus
+	for {
+		pod, err = executor.jobPod(name)
+		if err != nil {
+			return nil, err
+		}
+		if pod != nil {
+			if finished, err := pod.finished(); finished || err != nil {
+				if err != nil {
+					return nil, fmt.Errorf("job %s %s", name, err.Error())
+				}
+				break
+			}
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: job %s stopped after %ds", executorlib.ErrExecutionTimeout, name, module.Job.Spec.Timeout)
+		}
+		time.Sleep(time.Duration(executor.Options.JobStatusPollInterval) * time.Second)
+	}
+	terminated := pod.jobTerminated()
+	if terminated.Reason == "OOMKilled" {
+		return nil, fmt.Errorf("%w: job %s went over its memory limit", executorlib.ErrResourceLimitExceeded, name)
+	}
+
+	resultsDir, err := system.EnsureDataDir(filepath.Join(RESULTS_DIR, deal.ID))
+	if err != nil {
+		return nil, fmt.Errorf("error creating results directory: %s", err.Error())
+	}
+	if _, err := executor.kubectl(nil, "cp", "--container", collectContainer, pod.Metadata.Name+":/outputs", filepath.Join(resultsDir, "outputs")); err != nil {
+		return nil, fmt.Errorf("error fetching results: %s", err.Error())
+	}
+	// kubernetes keeps a single log of both streams
+	logs, err := executor.kubectl(nil, "logs", pod.Metadata.Name, "--container", jobContainer)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching logs: %s", err.Error())
+	}
+	files := map[string][]byte{
+		"stdout":   []byte(logs),
+		"stderr":   {},
+		"exitCode": []byte(strconv.Itoa(terminated.ExitCode)),
+	}
+	for file, content := range files {
+		if err := system.WriteFile(filepath.Join(resultsDir, file), content); err != nil {
+			return nil, fmt.Errorf("error creating %s file %s -> %s", file, deal.ID, err.Error())
+		}
+	}
+
+	cid, err := executorlib.GenerateCID(resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing results: %s", err.Error())
+	}
+	return &executorlib.ExecutorResults{
+		ResultsDir:       resultsDir,
+		ResultsCID:       cid,
+		InstructionCount: 1,
+	}, nil
+}
+
+// the job's pod, nil before kubernetes has made it
+func (executor *KubernetesExecutor) jobPod(name string) (*podStatus, error) {
+	output, err := executor.kubectl(nil, "get", "pods", "--selector", jobLabel+"="+name, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	var pods podList
+	if err := json.Unmarshal([]byte(output), &pods); err != nil {
+		return nil, fmt.Errorf("error decoding the pods of job %s: %s", name, err.Error())
+	}
+	if len(pods.Items) == 0 {
+		return nil, nil
+	}
+	return &pods.Items[0], nil
+}
+
+func (executor *KubernetesExecutor) applyNetworkPolicy() error {
+	executor.networkMutex.Lock()
+	defer executor.networkMutex.Unlock()
+	if executor.networkApplied {
+		return nil
+	}
+	body, err := json.Marshal(networkPolicyManifest(executor.Options.Namespace))
+	if err != nil {
+		return err
+	}
+	if _, err := executor.kubectl(body, "apply", "--filename", "-"); err != nil {
+		return fmt.Errorf("error applying the network policy for jobs without network: %s", err.Error())
+	}
+	executor.networkApplied = true
+	return nil
+}
+
+// a job that is not there is not an error, its pods go with it and its
+// secret, when it has one, goes after it
+func (executor *KubernetesExecutor) delete(name string) {
+	_, _ = executor.kubectl(nil, "delete", "job", name, "--ignore-not-found", "--wait=false")
+	_, _ = executor.kubectl(nil, "delete", "secret", name, "--ignore-not-found", "--wait=false")
+}
+
+// Compile-time interface check:
+var _ executorlib.Executor = (*KubernetesExecutor)(nil)
+var _ executorlib.SecretsRunner = (*KubernetesExecutor)(nil)
@@ -0,0 +1,364 @@
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	executorlib "github.com/lilypad-tech/lilypad/pkg/executor"
+)
+
+// only the parts of the batch/v1 Job the executor sets, the k8s api types
+// are not worth the dependency for this
+type jobManifest struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   objectMeta `json:"metadata"`
+	Spec       jobSpec    `json:"spec"`
+}
+
+type objectMeta struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type jobSpec struct {
+	BackoffLimit            int         `json:"backoffLimit"`
+	ActiveDeadlineSeconds   int64       `json:"activeDeadlineSeconds,omitempty"`
+	TTLSecondsAfterFinished int         `json:"ttlSecondsAfterFinished"`
+	Template                podTemplate `json:"template"`
+}
+
+type podTemplate struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     podSpec    `json:"spec"`
+}
+
+type podSpec struct {
+	RestartPolicy    string            `json:"restartPolicy"`
+	InitContainers   []containerSpec   `json:"initContainers,omitempty"`
+	Containers       []containerSpec   `json:"containers"`
+	Volumes          []volume          `json:"volumes"`
+	ImagePullSecrets []objectReference `json:"imagePullSecrets,omitempty"`
+}
+
+type containerSpec struct {
+	Name         string                `json:"name"`
+	Image        string                `json:"image"`
+	Command      []string              `json:"command,omitempty"`
+	Args         []string              `json:"args,omitempty"`
+	Env          []envVar              `json:"env,omitempty"`
+	WorkingDir   string                `json:"workingDir,omitempty"`
+	Resources    *resourceRequirements `json:"resources,omitempty"`
+	VolumeMounts []volumeMount         `json:"volumeMounts,omitempty"`
+}
+
+type envVar struct {
+	Name      string        `json:"name"`
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *envVarSource `json:"valueFrom,omitempty"`
+}
+
+type envVarSource struct {
+	SecretKeyRef *secretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+type secretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// a v1 Secret, the data is base64 encoded as it is marshalled
+type secretManifest struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   objectMeta        `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string][]byte `json:"data"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+type volume struct {
+	Name     string   `json:"name"`
+	EmptyDir struct{} `json:"emptyDir"`
+}
+
+type volumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+type objectReference struct {
+	Name string `json:"name"`
+}
+
+const (
+	jobContainer     = "job"
+	collectContainer = "collect"
+	outputsVolume    = "outputs"
+	inputsVolume     = "inputs"
+	jobLabel         = "lilypad.job"
+	dealAnnotation   = "lilypad.deal"
+	// pods with it lose their egress to the executor's network policy
+	networkLabel = "lilypad.network"
+	gpuResource  = "nvidia.com/gpu"
+)
+
+// the pod waits this long past the job's timeout for its outputs to be
+// copied before kubernetes stops it anyway
+const collectSeconds = 600
+
+// finished jobs the executor did not get to delete are removed after this
+const finishedJobSeconds = 3600
+
+// a job that cannot start is not retried, the deal has its own timeouts
+const noRetries = 0
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// the deal ids are longer than the 63 characters a label value can be,
+// the pod is labelled with a prefix of it and the full id annotated
+func jobName(dealID string) string {
+	name := invalidNameCharacters.ReplaceAllString(strings.ToLower(dealID), "-")
+	if len(name) > 40 { //nolint:gomnd
+		name = name[:40]
+	}
+	return strings.TrimRight("lilypad-"+name, "-")
+}
+
+// the deal's spec is what it paid for so it is both what the pod asks
+// the scheduler for and the most it can use, gpus are whole devices
+func dealResources(spec data.MachineSpec, job bacalhau.Job) (*resourceRequirements, error) {
+	quantities := map[string]string{}
+	if spec.CPU > 0 {
+		// milli-cpus
+		quantities["cpu"] = fmt.Sprintf("%dm", spec.CPU)
+	}
+	if spec.RAM > 0 {
+		quantities["memory"] = fmt.Sprintf("%dMi", spec.RAM)
+	}
+	if spec.Disk > 0 {
+		quantities["ephemeral-storage"] = fmt.Sprintf("%dMi", spec.Disk)
+	}
+	gpus := (spec.GPU + 999) / 1000 //nolint:gomnd
+	gpus = max(gpus, len(spec.GPUs))
+	if gpu := strings.TrimSpace(job.Spec.Resources.GPU); gpu != "" {
+		count, err := strconv.Atoi(gpu)
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("the job's gpu %q is not a number of gpus", job.Spec.Resources.GPU)
+		}
+		gpus = max(gpus, count)
+	}
+	if gpus > 0 {
+		quantities[gpuResource] = strconv.Itoa(gpus)
+	}
+	if len(quantities) == 0 {
+		return nil, nil
+	}
+	// requests equal to the limits give the pod the guaranteed qos class,
+	// gpus cannot be asked for any other way
+	return &resourceRequirements{Requests: quantities, Limits: quantities}, nil
+}
+
+// NVIDIA_VISIBLE_DEVICES is left out, the resource provider sets it for
+// its own gpus and the device plugin sets it for the ones the pod gets
+func jobEnv(variables []string) []envVar {
+	env := []envVar{}
+	for _, variable := range variables {
+		name, value, _ := strings.Cut(variable, "=")
+		if name == "" || name == "NVIDIA_VISIBLE_DEVICES" {
+			continue
+		}
+		env = append(env, envVar{Name: name, Value: value})
+	}
+	return env
+}
+
+// the env of a job with secrets points at the keys of the Secret named
+// after it, so the values are never in the Job that etcd keeps
+func secretEnv(name string, secretNames []string) []envVar {
+	env := []envVar{}
+	for _, secret := range secretNames {
+		env = append(env, envVar{
+			Name:      secret,
+			ValueFrom: &envVarSource{SecretKeyRef: &secretKeySelector{Name: name, Key: secret}},
+		})
+	}
+	return env
+}
+
+// the deal's secrets for the job's env, deleted along with the job
+func buildSecretManifest(options KubernetesExecutorOptions, deal data.DealContainer, secrets map[string]string) secretManifest {
+	name := jobName(deal.ID)
+	values := map[string][]byte{}
+	for secret, value := range secrets {
+		values[secret] = []byte(value)
+	}
+	return secretManifest{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: objectMeta{
+			Name:        name,
+			Namespace:   options.Namespace,
+			Labels:      map[string]string{jobLabel: name},
+			Annotations: map[string]string{dealAnnotation: deal.ID},
+		},
+		Type: "Opaque",
+		Data: values,
+	}
+}
+
+// one init container per input downloads it into the inputs volume, the
+// source is passed in the env so it never ends up in the shell script
+func inputContainers(inputs []bacalhau.StorageSpec, helperImage string, gateway string) ([]containerSpec, []volumeMount, error) {
+	containers := []containerSpec{}
+	mounts := []volumeMount{}
+	for i, input := range inputs {
+		if input.Path == "" {
+			return nil, nil, fmt.Errorf("input %d has no path to mount it at", i)
+		}
+		dir := strconv.Itoa(i)
+		container := containerSpec{
+			Name:         fmt.Sprintf("input-%d", i),
+			Image:        helperImage,
+			Command:      []string{"sh", "-c"},
+			VolumeMounts: []volumeMount{{Name: inputsVolume, MountPath: "/inputs"}},
+		}
+		switch input.StorageSource {
+		case bacalhau.StorageSourceIPFS:
+			if input.CID == "" {
+				return nil, nil, fmt.Errorf("input %d has no cid", i)
+			}
+			container.Args = []string{`mkdir -p "/inputs/$DIR" && wget -q -O - "$GATEWAY/ipfs/$CID?format=tar" | tar -x -C "/inputs/$DIR"`}
+			container.Env = []envVar{{Name: "DIR", Value: dir}, {Name: "GATEWAY", Value: strings.TrimRight(gateway, "/")}, {Name: "CID", Value: input.CID}}
+			// the tarball's root is named after the cid
+			mounts = append(mounts, volumeMount{Name: inputsVolume, MountPath: input.Path, SubPath: dir + "/" + input.CID, ReadOnly: true})
+		case bacalhau.StorageSourceURLDownload:
+			if !strings.HasPrefix(input.URL, "http://") && !strings.HasPrefix(input.URL, "https://") {
+				return nil, nil, fmt.Errorf("input %d url %s is not an http url", i, input.URL)
+			}
+			container.Args = []string{`wget -q -O "/inputs/$DIR" "$URL"`}
+			container.Env = []envVar{{Name: "DIR", Value: dir}, {Name: "URL", Value: input.URL}}
+			mounts = append(mounts, volumeMount{Name: inputsVolume, MountPath: input.Path, SubPath: dir, ReadOnly: true})
+		default:
+			return nil, nil, fmt.Errorf("the kubernetes executor cannot fetch %s inputs", input.StorageSource)
+		}
+		containers = append(containers, container)
+	}
+	return containers, mounts, nil
+}
+
+// the job runs next to a container that only keeps the pod alive once
+// the job is done, so its outputs can be copied out of the shared volume
+// whatever the job's exit code was, only the names of the secrets are
+// used, the values go in the job's Secret
+func buildJobManifest(options KubernetesExecutorOptions, deal data.DealContainer, job bacalhau.Job, secrets map[string]string) (jobManifest, error) {
+	name := jobName(deal.ID)
+	docker := job.Spec.Docker
+	if docker.Image == "" {
+		return jobManifest{}, fmt.Errorf("the job has no image to run")
+	}
+	podLabels := map[string]string{jobLabel: name}
+	switch job.Spec.Network.Type {
+	case bacalhau.NetworkNone:
+		// the pod needs egress to download its inputs
+		if len(job.Spec.Inputs) == 0 {
+			podLabels[networkLabel] = "none"
+		}
+	case bacalhau.NetworkFull:
+	default:
+		return jobManifest{}, fmt.Errorf("the kubernetes executor cannot limit a job to %s networking", job.Spec.Network.Type)
+	}
+
+	resources, err := dealResources(deal.Deal.JobOffer.Spec, job)
+	if err != nil {
+		return jobManifest{}, err
+	}
+	initContainers, inputMounts, err := inputContainers(job.Spec.Inputs, options.HelperImage, options.IPFSGateway)
+	if err != nil {
+		return jobManifest{}, err
+	}
+	volumes := []volume{{Name: outputsVolume}}
+	if len(inputMounts) > 0 {
+		volumes = append(volumes, volume{Name: inputsVolume})
+	}
+	env := append(jobEnv(executorlib.WithoutSecrets(docker.EnvironmentVariables, secrets)), secretEnv(name, executorlib.SecretNames(secrets))...)
+	pullSecrets := []objectReference{}
+	for _, secret := range options.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, objectReference{Name: secret})
+	}
+
+	spec := jobSpec{
+		BackoffLimit:            noRetries,
+		TTLSecondsAfterFinished: finishedJobSeconds,
+		Template: podTemplate{
+			Metadata: objectMeta{Labels: podLabels},
+			Spec: podSpec{
+				RestartPolicy:  "Never",
+				InitContainers: initContainers,
+				Containers: []containerSpec{
+					{
+						Name:         jobContainer,
+						Image:        docker.Image,
+						Command:      docker.Entrypoint,
+						Args:         docker.Parameters,
+						Env:          env,
+						WorkingDir:   docker.WorkingDirectory,
+						Resources:    resources,
+						VolumeMounts: append([]volumeMount{{Name: outputsVolume, MountPath: "/outputs"}}, inputMounts...),
+					},
+					{
+						Name:         collectContainer,
+						Image:        options.HelperImage,
+						Command:      []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 1; done"},
+						VolumeMounts: []volumeMount{{Name: outputsVolume, MountPath: "/outputs", ReadOnly: true}},
+					},
+				},
+				Volumes:          volumes,
+				ImagePullSecrets: pullSecrets,
+			},
+		},
+	}
+	if job.Spec.Timeout > 0 {
+		spec.ActiveDeadlineSeconds = job.Spec.Timeout + collectSeconds
+	}
+	return jobManifest{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata: objectMeta{
+			Name:        name,
+			Namespace:   options.Namespace,
+			Labels:      map[string]string{jobLabel: name},
+			Annotations: map[string]string{dealAnnotation: deal.ID},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// denies all egress to the pods of jobs that asked for no network, the
+// cluster's network plugin has to enforce network policies for it to
+func networkPolicyManifest(namespace string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "NetworkPolicy",
+		"metadata":   objectMeta{Name: "lilypad-no-network", Namespace: namespace},
+		"spec": map[string]interface{}{
+			"podSelector": map[string]interface{}{
+				"matchLabels": map[string]string{networkLabel: "none"},
+			},
+			"policyTypes": []string{"Egress"},
+			"egress":      []interface{}{},
+		},
+	}
+}
@@ -0,0 +1,167 @@
+//go:build unit
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+	"github.com/stretchr/testify/assert"
+)
+
+func testOptions() KubernetesExecutorOptions {
+	return KubernetesExecutorOptions{
+		Namespace:        "lilypad",
+		HelperImage:      "busybox:1.36",
+		IPFSGateway:      "https://ipfs.io/",
+		ImagePullSecrets: []string{"ghcr"},
+	}
+}
+
+func TestJobName(t *testing.T) {
+	assert.Equal(t, "lilypad-qmtvmc7jbd2es2qgpqbnvwnx1keepnrpgb7rj8cp", jobName("QmTVmC7JBD2ES2qGPqBNVWnX1KeEPNrPGb7rJ8cpFgtefe"))
+	assert.Equal(t, "lilypad-abc-1", jobName("abc_1"))
+	assert.LessOrEqual(t, len(jobName("0x9f3b1c2d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9")), 63)
+}
+
+func TestBuildJobManifest(t *testing.T) {
+	deal := data.DealContainer{ID: "deal1"}
+	deal.Deal.JobOffer.Spec = data.MachineSpec{CPU: 1500, RAM: 2048, GPU: 1000}
+	job := bacalhau.Job{}
+	job.Spec.Docker = bacalhau.JobSpecDocker{
+		Image:                "ghcr.io/acme/model:v1",
+		Entrypoint:           []string{"python", "run.py"},
+		Parameters:           []string{"--steps", "10"},
+		EnvironmentVariables: []string{"PROMPT=a=b", "NVIDIA_VISIBLE_DEVICES=3"},
+	}
+	job.Spec.Timeout = 300
+	job.Spec.Inputs = []bacalhau.StorageSpec{
+		{StorageSource: bacalhau.StorageSourceIPFS, CID: "QmData", Path: "/inputs/data"},
+		{StorageSource: bacalhau.StorageSourceURLDownload, URL: "https://example.com/w.bin", Path: "/inputs/w.bin"},
+	}
+
+	manifest, err := buildJobManifest(testOptions(), deal, job, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "lilypad-deal1", manifest.Metadata.Name)
+	assert.Equal(t, "deal1", manifest.Metadata.Annotations[dealAnnotation])
+	assert.Equal(t, int64(300+collectSeconds), manifest.Spec.ActiveDeadlineSeconds)
+
+	pod := manifest.Spec.Template.Spec
+	assert.Equal(t, "Never", pod.RestartPolicy)
+	assert.Equal(t, []objectReference{{Name: "ghcr"}}, pod.ImagePullSecrets)
+	// a job with inputs needs the network to fetch them
+	assert.NotContains(t, manifest.Spec.Template.Metadata.Labels, networkLabel)
+
+	container := pod.Containers[0]
+	assert.Equal(t, []string{"python", "run.py"}, container.Command)
+	assert.Equal(t, []string{"--steps", "10"}, container.Args)
+	assert.Equal(t, []envVar{{Name: "PROMPT", Value: "a=b"}}, container.Env)
+	assert.Equal(t, map[string]string{"cpu": "1500m", "memory": "2048Mi", gpuResource: "1"}, container.Resources.Limits)
+	assert.Equal(t, container.Resources.Limits, container.Resources.Requests)
+	assert.Equal(t, []volumeMount{
+		{Name: outputsVolume, MountPath: "/outputs"},
+		{Name: inputsVolume, MountPath: "/inputs/data", SubPath: "0/QmData", ReadOnly: true},
+		{Name: inputsVolume, MountPath: "/inputs/w.bin", SubPath: "1", ReadOnly: true},
+	}, container.VolumeMounts)
+
+	assert.Len(t, pod.InitContainers, 2)
+	assert.Contains(t, pod.InitContainers[0].Env, envVar{Name: "GATEWAY", Value: "https://ipfs.io"})
+	assert.Contains(t, pod.InitContainers[1].Env, envVar{Name: "URL", Value: "https://example.com/w.bin"})
+}
+
+func TestBuildJobManifestNetwork(t *testing.T) {
+	job := bacalhau.Job{}
+	job.Spec.Docker.Image = "ghcr.io/acme/model:v1"
+	manifest, err := buildJobManifest(testOptions(), data.DealContainer{ID: "deal1"}, job, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "none", manifest.Spec.Template.Metadata.Labels[networkLabel])
+	assert.Nil(t, manifest.Spec.Template.Spec.Containers[0].Resources)
+	assert.Zero(t, manifest.Spec.ActiveDeadlineSeconds)
+
+	job.Spec.Network.Type = bacalhau.NetworkFull
+	manifest, err = buildJobManifest(testOptions(), data.DealContainer{ID: "deal1"}, job, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, manifest.Spec.Template.Metadata.Labels, networkLabel)
+
+	job.Spec.Network.Type = bacalhau.NetworkHTTP
+	_, err = buildJobManifest(testOptions(), data.DealContainer{ID: "deal1"}, job, nil)
+	assert.Error(t, err)
+}
+
+func TestBuildJobManifestSecrets(t *testing.T) {
+	deal := data.DealContainer{ID: "deal1"}
+	job := bacalhau.Job{}
+	job.Spec.Docker.Image = "ghcr.io/acme/model:v1"
+	job.Spec.Docker.EnvironmentVariables = []string{"MODEL=sdxl", "API_KEY=placeholder"}
+	secrets := map[string]string{"API_KEY": "sk=123"}
+
+	manifest, err := buildJobManifest(testOptions(), deal, job, secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, []envVar{
+		{Name: "MODEL", Value: "sdxl"},
+		{Name: "API_KEY", ValueFrom: &envVarSource{SecretKeyRef: &secretKeySelector{Name: "lilypad-deal1", Key: "API_KEY"}}},
+	}, manifest.Spec.Template.Spec.Containers[0].Env)
+	body, err := json.Marshal(manifest)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "sk=123")
+	assert.NotContains(t, string(body), "placeholder")
+
+	secret := buildSecretManifest(testOptions(), deal, secrets)
+	assert.Equal(t, "lilypad-deal1", secret.Metadata.Name)
+	assert.Equal(t, "lilypad", secret.Metadata.Namespace)
+	body, err = json.Marshal(secret)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"API_KEY":"c2s9MTIz"`)
+}
+
+func TestPodFinished(t *testing.T) {
+	pod := podStatus{}
+	pod.Status.Phase = "Pending"
+	finished, err := pod.finished()
+	assert.False(t, finished)
+	assert.NoError(t, err)
+
+	running := containerStatus{Name: jobContainer}
+	running.State.Terminated = &terminatedState{ExitCode: 1}
+	pod.Status.Phase = "Running"
+	pod.Status.ContainerStatuses = []containerStatus{running}
+	finished, err = pod.finished()
+	assert.True(t, finished)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pod.jobTerminated().ExitCode)
+
+	input := containerStatus{Name: "input-0"}
+	input.State.Terminated = &terminatedState{ExitCode: 1, Message: "wget: server returned error: HTTP/1.1 404"}
+	pod = podStatus{}
+	pod.Status.InitContainerStatuses = []containerStatus{input}
+	_, err = pod.finished()
+	assert.ErrorContains(t, err, "inputs")
+
+	pulling := containerStatus{Name: jobContainer}
+	pulling.State.Waiting = &waitingState{Reason: "ImagePullBackOff"}
+	pod = podStatus{}
+	pod.Status.ContainerStatuses = []containerStatus{pulling}
+	_, err = pod.finished()
+	assert.ErrorContains(t, err, "ImagePullBackOff")
+}
+
+func TestParseNodeSpecs(t *testing.T) {
+	nodes := `{"items": [
+		{"metadata": {"labels": {"nvidia.com/gpu.product": "NVIDIA-A100-SXM4-40GB", "nvidia.com/gpu.memory": "40960"}},
+		 "status": {"allocatable": {"cpu": "7910m", "memory": "32617900Ki", "ephemeral-storage": "94998177120", "nvidia.com/gpu": "2", "pods": "110"}}},
+		{"spec": {"unschedulable": true}, "status": {"allocatable": {"cpu": "4", "memory": "16Gi"}}}
+	]}`
+	specs, err := parseNodeSpecs([]byte(nodes))
+	assert.NoError(t, err)
+	assert.Len(t, specs, 1)
+	assert.Equal(t, 7910, specs[0].CPU)
+	assert.Equal(t, 31853, specs[0].RAM)
+	assert.Equal(t, 90597, specs[0].Disk)
+	assert.Equal(t, 2000, specs[0].GPU)
+	assert.Equal(t, []data.GPUSpec{
+		{Name: "NVIDIA-A100-SXM4-40GB", Vendor: "NVIDIA", VRAM: 40960},
+		{Name: "NVIDIA-A100-SXM4-40GB", Vendor: "NVIDIA", VRAM: 40960},
+	}, specs[0].GPUs)
+}
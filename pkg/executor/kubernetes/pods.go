@@ -0,0 +1,145 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// the parts of the pod list kubectl prints that say how the job is doing
+type podList struct {
+	Items []podStatus `json:"items"`
+}
+
+type podStatus struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Phase                 string            `json:"phase"`
+		Reason                string            `json:"reason"`
+		Message               string            `json:"message"`
+		InitContainerStatuses []containerStatus `json:"initContainerStatuses"`
+		ContainerStatuses     []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Name  string `json:"name"`
+	State struct {
+		Waiting    *waitingState    `json:"waiting"`
+		Terminated *terminatedState `json:"terminated"`
+	} `json:"state"`
+}
+
+type waitingState struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type terminatedState struct {
+	ExitCode int    `json:"exitCode"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+}
+
+// reasons a container waits with that it will not get past by itself
+var stuckReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// true once the job's container has exited whatever its exit code, an
+// error when the pod will never get that far
+func (pod *podStatus) finished() (bool, error) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return false, fmt.Errorf("could not fetch its inputs: %s exited with %d %s", status.Name, terminated.ExitCode, terminated.Message)
+		}
+	}
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if waiting := status.State.Waiting; waiting != nil && stuckReasons[waiting.Reason] {
+			return false, fmt.Errorf("cannot start %s: %s %s", status.Name, waiting.Reason, waiting.Message)
+		}
+	}
+	if pod.jobTerminated() != nil {
+		return true, nil
+	}
+	if pod.Status.Phase == "Failed" {
+		return false, fmt.Errorf("failed: %s %s", pod.Status.Reason, pod.Status.Message)
+	}
+	return false, nil
+}
+
+func (pod *podStatus) jobTerminated() *terminatedState {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == jobContainer {
+			return status.State.Terminated
+		}
+	}
+	return nil
+}
+
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Unschedulable bool `json:"unschedulable"`
+		} `json:"spec"`
+		Status struct {
+			Allocatable map[string]string `json:"allocatable"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// what each schedulable node has left for pods, the gpu model and memory
+// come from the labels nvidia's gpu feature discovery puts on nodes
+func parseNodeSpecs(output []byte) ([]data.MachineSpec, error) {
+	var nodes nodeList
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return nil, fmt.Errorf("error decoding the cluster's nodes: %s", err.Error())
+	}
+	specs := []data.MachineSpec{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		spec := data.MachineSpec{}
+		for name, value := range node.Status.Allocatable {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("a node has %q of %s", value, name)
+			}
+			switch name {
+			case "cpu":
+				spec.CPU = int(quantity.MilliValue())
+			case "memory":
+				spec.RAM = int(quantity.Value() >> 20)
+			case "ephemeral-storage":
+				spec.Disk = int(quantity.Value() >> 20)
+			case gpuResource:
+				gpus := int(quantity.Value())
+				// milli-gpus
+				spec.GPU = gpus * 1000 //nolint:gomnd
+				vram, _ := strconv.Atoi(node.Metadata.Labels["nvidia.com/gpu.memory"])
+				for i := 0; i < gpus; i++ {
+					spec.GPUs = append(spec.GPUs, data.GPUSpec{
+						Name:   node.Metadata.Labels["nvidia.com/gpu.product"],
+						Vendor: "NVIDIA",
+						VRAM:   vram,
+					})
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// an executor that can give a job its secrets without putting them in the
+// job's spec, which the tools that run jobs store and show in the clear
+type SecretsRunner interface {
+	RunJobWithSecrets(
+		deal data.DealContainer,
+		module data.Module,
+		secrets map[string]string,
+	) (*ExecutorResults, error)
+}
+
+// tmpfs mounts files holding secrets can go in so they never reach a disk
+var secretsDirs = []string{"/dev/shm", os.Getenv("XDG_RUNTIME_DIR")}
+
+// a directory on a tmpfs for the files that hold a job's secrets
+func SecretsDir() (string, error) {
+	for _, dir := range secretsDirs {
+		if info, err := os.Stat(dir); dir != "" && err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("there is no tmpfs such as /dev/shm to keep the job's secrets in")
+}
+
+// the module's own env vars less the ones a secret replaces
+func WithoutSecrets(env []string, secrets map[string]string) []string {
+	kept := []string{}
+	for _, variable := range env {
+		name, _, _ := strings.Cut(variable, "=")
+		if _, ok := secrets[name]; !ok {
+			kept = append(kept, variable)
+		}
+	}
+	return kept
+}
+
+func SecretNames(secrets map[string]string) []string {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,13 +1,23 @@
 package executor
 
 import (
+	"errors"
+
 	"github.com/lilypad-tech/lilypad/pkg/data"
 )
 
+// the job was stopped for using more of the machine than its deal paid for
+var ErrResourceLimitExceeded = errors.New("job exceeded its resource limits")
+
+// the job was stopped for running longer than its module's timeout
+var ErrExecutionTimeout = errors.New("job ran past its execution timeout")
+
 type ExecutorResults struct {
 	ResultsDir       string
 	ResultsCID       string
 	InstructionCount int
+	// nil when the executor does not measure what jobs use
+	Usage *data.ResourceUsage
 }
 
 type Executor interface {
@@ -21,3 +31,9 @@ type Executor interface {
 		module data.Module,
 	) (*ExecutorResults, error)
 }
+
+// an executor that can pull the images of the modules a provider offers
+// before any job for them arrives
+type ImageWarmer interface {
+	WarmImages(images []string) error
+}
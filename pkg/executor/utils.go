@@ -3,9 +3,17 @@ package executor
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/ipfs/boxo/files"
+	"github.com/ipfs/kubo/core"
+	"github.com/ipfs/kubo/core/coreapi"
+	"github.com/ipfs/kubo/core/coreiface/options"
 )
 
 func ExtractTarGz(src, dst string) error {
@@ -21,7 +29,13 @@ func ExtractTarGz(src, dst string) error {
 	}
 	defer gzr.Close()
 
-	tr := tar.NewReader(gzr)
+	return ExtractTar(gzr, dst)
+}
+
+// entries that would land outside of dst are an error rather than being
+// written there, the tarball can come from a gateway we do not run
+func ExtractTar(reader io.Reader, dst string) error {
+	tr := tar.NewReader(reader)
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -32,6 +46,9 @@ func ExtractTarGz(src, dst string) error {
 		}
 
 		target := filepath.Join(dst, header.Name)
+		if target != filepath.Clean(dst) && !strings.HasPrefix(target, filepath.Clean(dst)+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %s is outside of %s", header.Name, dst)
+		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
@@ -52,3 +69,36 @@ func ExtractTarGz(src, dst string) error {
 	}
 	return nil
 }
+
+// GenerateCID generates a CID for a given path
+// This mimics the behavior of `ipfs add -Qrn /path`
+func GenerateCID(path string) (string, error) {
+	ctx := context.Background()
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("error getting file info: %s", err.Error())
+	}
+	fsNode, err := files.NewSerialFile(path, false, stat)
+	if err != nil {
+		return "", fmt.Errorf("error creating serial file: %s", err.Error())
+	}
+
+	node, err := core.NewNode(ctx, &core.BuildCfg{
+		Online: false,
+	})
+	api, err := coreapi.NewCoreAPI(node)
+	if err != nil {
+		return "", fmt.Errorf("error creating core API: %s", err.Error())
+	}
+
+	opts := []options.UnixfsAddOption{
+		options.Unixfs.HashOnly(true),
+	}
+	root, err := api.Unixfs().Add(ctx, fsNode, opts...)
+	if err != nil {
+		return "", fmt.Errorf("error adding to UnixFS: %s", err.Error())
+	}
+
+	return root.RootCid().String(), nil
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// dashboards and monitoring systems send this instead of signing with a wallet
+const X_LILYPAD_API_KEY_HEADER = "X-Lilypad-Api-Key"
+
+// so a key is easy to spot if it ends up somewhere it should not
+const API_KEY_PREFIX = "lpk_"
+
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return API_KEY_PREFIX + hex.EncodeToString(secret), nil
+}
+
+// keys are random enough that a plain sha256 is all the store needs
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// check the api key header or, when there is none, the wallet signature headers
+// lookup is given the hash of the key and says if the key is known
+func CheckAPIKeyOrSignature(req *http.Request, lookup func(hash string) (bool, error)) error {
+	key := req.Header.Get(X_LILYPAD_API_KEY_HEADER)
+	if key == "" {
+		_, err := CheckSignature(req)
+		return err
+	}
+	ok, err := lookup(HashAPIKey(key))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return HTTPError{
+			Message:    "invalid api key",
+			StatusCode: http.StatusUnauthorized,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"::1/128",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+}
+
+var DefaultTrustedProxyHeaders = []string{
+	"X-Forwarded-For",
+	"X-Real-IP",
+}
+
+// works out the address of the client behind any proxies we trust,
+// a request straight from the internet can set whatever headers it
+// likes so they are ignored unless a trusted proxy sent it
+type ClientIPResolver struct {
+	proxies []netip.Prefix
+	headers []string
+}
+
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := []netip.Prefix{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+func NewClientIPResolver(options ProxyOptions) (*ClientIPResolver, error) {
+	proxies, err := ParseTrustedProxies(options.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	headers := []string{}
+	for _, header := range options.TrustedHeaders {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, http.CanonicalHeaderKey(header))
+		}
+	}
+	return &ClientIPResolver{
+		proxies: proxies,
+		headers: headers,
+	}, nil
+}
+
+func (resolver *ClientIPResolver) trusted(addr netip.Addr) bool {
+	for _, proxy := range resolver.proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIP(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func (resolver *ClientIPResolver) ClientIP(req *http.Request) string {
+	remote, ok := parseIP(req.RemoteAddr)
+	if !ok {
+		return req.RemoteAddr
+	}
+	if !resolver.trusted(remote) {
+		return remote.String()
+	}
+	for _, header := range resolver.headers {
+		values := req.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		if header == "X-Forwarded-For" {
+			if addr, ok := resolver.forwardedFor(values); ok {
+				return addr.String()
+			}
+			continue
+		}
+		if addr, ok := parseIP(values[0]); ok {
+			return addr.String()
+		}
+	}
+	return remote.String()
+}
+
+// each proxy appends the address it got the request from so we walk back
+// from the right and the first address that is not one of ours is the
+// client, anything to the left of it could have been made up
+func (resolver *ClientIPResolver) forwardedFor(values []string) (netip.Addr, bool) {
+	hops := []netip.Addr{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			addr, ok := parseIP(part)
+			if !ok {
+				// a hop we cannot read means we cannot trust what is left of it
+				hops = hops[:0]
+				continue
+			}
+			hops = append(hops, addr)
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !resolver.trusted(hops[i]) {
+			return hops[i], true
+		}
+	}
+	// every hop is one of ours so the first is as close to the client as we get
+	if len(hops) > 0 {
+		return hops[0], true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,89 @@
+//go:build unit
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewClientIPResolver(ProxyOptions{
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"},
+		TrustedHeaders: DefaultTrustedProxyHeaders,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		remote   string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "straight from the internet",
+			remote:   "203.0.113.7:4000",
+			expected: "203.0.113.7",
+		},
+		{
+			name:     "spoofed X-Forwarded-For from an untrusted peer",
+			remote:   "203.0.113.7:4000",
+			headers:  map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			expected: "203.0.113.7",
+		},
+		{
+			name:     "spoofed X-Real-IP from an untrusted peer",
+			remote:   "203.0.113.7:4000",
+			headers:  map[string]string{"X-Real-IP": "1.2.3.4"},
+			expected: "203.0.113.7",
+		},
+		{
+			name:     "behind a trusted proxy",
+			remote:   "10.0.0.2:4000",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.9"},
+			expected: "198.51.100.9",
+		},
+		{
+			// the client made up the first hop, our proxies added the rest
+			name:     "spoofed hops to the left of the client are skipped",
+			remote:   "10.0.0.2:4000",
+			headers:  map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 192.168.1.1"},
+			expected: "198.51.100.9",
+		},
+		{
+			name:     "an unreadable hop stops the walk",
+			remote:   "10.0.0.2:4000",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.9, junk, 10.0.0.3"},
+			expected: "10.0.0.3",
+		},
+		{
+			name:     "ipv6 peer",
+			remote:   "[2001:db8::1]:4000",
+			headers:  map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			expected: "2001:db8::1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remote
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+			require.Equal(t, test.expected, resolver.ClientIP(req))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"not an ip"})
+	require.Error(t, err)
+	prefixes, err := ParseTrustedProxies([]string{" 10.1.2.3/8 ", "", "::ffff:192.168.0.1"})
+	require.NoError(t, err)
+	require.Len(t, prefixes, 2)
+	require.Equal(t, "10.0.0.0/8", prefixes[0].String())
+	require.Equal(t, "192.168.0.1/32", prefixes[1].String())
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// compresses json responses of at least options.MinSize bytes for clients
+// that accept gzip or deflate, smaller responses are not worth the cpu and
+// anything else e.g. file downloads and event streams is left alone
+func CompressHandler(options CompressionOptions, handler http.Handler) http.Handler {
+	if !options.Enabled {
+		return handler
+	}
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		encoding := acceptedEncoding(req.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			handler.ServeHTTP(res, req)
+			return
+		}
+		res.Header().Add("Vary", "Accept-Encoding")
+		writer := &compressWriter{
+			ResponseWriter: res,
+			encoding:       encoding,
+			minSize:        options.MinSize,
+			status:         http.StatusOK,
+		}
+		defer writer.Close()
+		handler.ServeHTTP(writer, req)
+	})
+}
+
+// gzip is preferred when the client takes both
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// holds the body back until it reaches the threshold or the handler is
+// done, by then the content type is known and we can pick whether to compress
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	minSize     int
+	status      int
+	wroteHeader bool
+	buffer      []byte
+	decided     bool
+	encoder     io.WriteCloser
+	hijacked    bool
+}
+
+func (writer *compressWriter) WriteHeader(status int) {
+	if writer.wroteHeader {
+		return
+	}
+	writer.wroteHeader = true
+	writer.status = status
+}
+
+func (writer *compressWriter) Write(data []byte) (int, error) {
+	writer.wroteHeader = true
+	if writer.decided {
+		if writer.encoder != nil {
+			return writer.encoder.Write(data)
+		}
+		return writer.ResponseWriter.Write(data)
+	}
+	writer.buffer = append(writer.buffer, data...)
+	if len(writer.buffer) >= writer.minSize {
+		if err := writer.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (writer *compressWriter) decide() error {
+	if writer.decided {
+		return nil
+	}
+	writer.decided = true
+	header := writer.Header()
+	compress := len(writer.buffer) >= writer.minSize &&
+		len(writer.buffer) > 0 &&
+		header.Get("Content-Encoding") == "" &&
+		isJSONContentType(header.Get("Content-Type"))
+	if compress {
+		header.Set("Content-Encoding", writer.encoding)
+		header.Del("Content-Length")
+		switch writer.encoding {
+		case "gzip":
+			writer.encoder = gzip.NewWriter(writer.ResponseWriter)
+		case "deflate":
+			// deflate in http means zlib wrapped, not the raw stream
+			writer.encoder = zlib.NewWriter(writer.ResponseWriter)
+		}
+	}
+	writer.ResponseWriter.WriteHeader(writer.status)
+	buffer := writer.buffer
+	writer.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+	if writer.encoder != nil {
+		_, err := writer.encoder.Write(buffer)
+		return err
+	}
+	_, err := writer.ResponseWriter.Write(buffer)
+	return err
+}
+
+// a flush means the handler is streaming so whatever is decided by then stays
+func (writer *compressWriter) Flush() {
+	if err := writer.decide(); err != nil {
+		return
+	}
+	if flusher, ok := writer.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (writer *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := writer.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer cannot be hijacked")
+	}
+	writer.hijacked = true
+	return hijacker.Hijack()
+}
+
+func (writer *compressWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *compressWriter) Close() error {
+	if writer.hijacked {
+		return nil
+	}
+	if !writer.wroteHeader && !writer.decided {
+		// the handler wrote nothing, let net/http send its default response
+		return nil
+	}
+	if err := writer.decide(); err != nil {
+		return err
+	}
+	if writer.encoder != nil {
+		return writer.encoder.Close()
+	}
+	return nil
+}
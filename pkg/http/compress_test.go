@@ -0,0 +1,132 @@
+//go:build unit
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var largeJSON = `{"data":"` + strings.Repeat("lilypad", 200) + `"}`
+
+func compressed(t *testing.T, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	res := httptest.NewRecorder()
+	CompressHandler(CompressionOptions{Enabled: true, MinSize: 1024}, handler).ServeHTTP(res, req)
+	return res
+}
+
+func writeJSON(body string) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(body))
+	}
+}
+
+func TestAcceptedEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                       "",
+		"gzip":                   "gzip",
+		"deflate":                "deflate",
+		"deflate, gzip":          "gzip",
+		"GZIP;q=0.5":             "gzip",
+		"gzip;q=0, deflate":      "deflate",
+		"gzip; q=0, deflate;q=0": "",
+		"br, identity":           "",
+	}
+	for header, expected := range cases {
+		require.Equal(t, expected, acceptedEncoding(header), header)
+	}
+}
+
+func TestCompressGzip(t *testing.T) {
+	res := compressed(t, "gzip, deflate", writeJSON(largeJSON))
+	require.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", res.Header().Get("Vary"))
+
+	reader, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, largeJSON, string(body))
+}
+
+func TestCompressDeflate(t *testing.T) {
+	res := compressed(t, "deflate", writeJSON(largeJSON))
+	require.Equal(t, "deflate", res.Header().Get("Content-Encoding"))
+
+	reader, err := zlib.NewReader(res.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, largeJSON, string(body))
+}
+
+func TestCompressSkipped(t *testing.T) {
+	// the client did not ask so it does not vary either
+	res := compressed(t, "", writeJSON(largeJSON))
+	require.Empty(t, res.Header().Get("Content-Encoding"))
+	require.Empty(t, res.Header().Get("Vary"))
+	require.Equal(t, largeJSON, res.Body.String())
+
+	res = compressed(t, "gzip", writeJSON(`{"small":true}`))
+	require.Empty(t, res.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", res.Header().Get("Vary"))
+	require.Equal(t, `{"small":true}`, res.Body.String())
+
+	res = compressed(t, "gzip", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = res.Write([]byte(largeJSON))
+	})
+	require.Empty(t, res.Header().Get("Content-Encoding"))
+	require.Equal(t, largeJSON, res.Body.String())
+}
+
+func TestCompressAlreadyEncoded(t *testing.T) {
+	var encoded bytes.Buffer
+	writer := gzip.NewWriter(&encoded)
+	_, _ = writer.Write([]byte(largeJSON))
+	require.NoError(t, writer.Close())
+
+	res := compressed(t, "gzip", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("Content-Encoding", "gzip")
+		_, _ = res.Write(encoded.Bytes())
+	})
+	require.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	require.Equal(t, encoded.Bytes(), res.Body.Bytes())
+}
+
+func TestCompressStreamed(t *testing.T) {
+	// a flush before the threshold settles it, the stream goes out as written
+	res := compressed(t, "gzip", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(`{"first":true}`))
+		res.(http.Flusher).Flush()
+		_, _ = res.Write([]byte(largeJSON))
+	})
+	require.Empty(t, res.Header().Get("Content-Encoding"))
+	require.Equal(t, `{"first":true}`+largeJSON, res.Body.String())
+	require.True(t, res.Flushed)
+}
+
+func TestCompressKeepsStatus(t *testing.T) {
+	res := compressed(t, "gzip", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusTeapot)
+		_, _ = res.Write([]byte(largeJSON))
+	})
+	require.Equal(t, http.StatusTeapot, res.Code)
+	require.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+)
+
+// writes err as the json error envelope, an HTTPError gives the status,
+// code and details and anything else is an internal error
+func WriteError(res http.ResponseWriter, req *http.Request, err error) {
+	var httpError HTTPError
+	if !errors.As(err, &httpError) {
+		httpError = HTTPError{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	if httpError.StatusCode == 0 {
+		httpError.StatusCode = http.StatusInternalServerError
+	}
+	envelope := apierrors.ErrorEnvelope{
+		Code:      httpError.ErrorCode(),
+		Message:   httpError.Message,
+		Details:   httpError.Details,
+		RequestID: RequestIDFromContext(req.Context()),
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.WriteHeader(httpError.StatusCode)
+	json.NewEncoder(res).Encode(envelope)
+}
+
+// the envelope version of http.Error for errors that are only a message
+func WriteErrorMessage(res http.ResponseWriter, req *http.Request, message string, statusCode int) {
+	WriteError(res, req, HTTPError{
+		Message:    message,
+		StatusCode: statusCode,
+	})
+}
+
+// turns an error response back into the HTTPError the server wrote,
+// servers from before the envelope send the message as plain text
+func responseError(resp *http.Response, body []byte) error {
+	var envelope apierrors.ErrorEnvelope
+	err := json.Unmarshal(body, &envelope)
+	if err != nil || envelope.Code == "" {
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = fmt.Sprintf("%s %s returned %s", resp.Request.Method, resp.Request.URL, resp.Status)
+		}
+		return HTTPError{
+			Message:    message,
+			StatusCode: resp.StatusCode,
+			RequestID:  resp.Header.Get(X_REQUEST_ID_HEADER),
+		}
+	}
+	return HTTPError{
+		Message:    envelope.Message,
+		StatusCode: resp.StatusCode,
+		Code:       envelope.Code,
+		Details:    envelope.Details,
+		RequestID:  envelope.RequestID,
+	}
+}
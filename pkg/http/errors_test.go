@@ -0,0 +1,84 @@
+//go:build unit
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseError(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected HTTPError
+		code     apierrors.Code
+	}{
+		{
+			name:   "envelope",
+			status: http.StatusNotFound,
+			body:   `{"code":"DEAL_NOT_FOUND","message":"deal not found","details":{"id":"deal"},"request_id":"from-body"}`,
+			expected: HTTPError{
+				Message:    "deal not found",
+				StatusCode: http.StatusNotFound,
+				Code:       apierrors.DealNotFound,
+				Details:    map[string]any{"id": "deal"},
+				RequestID:  "from-body",
+			},
+			code: apierrors.DealNotFound,
+		},
+		{
+			name:   "plain text from an older server",
+			status: http.StatusBadRequest,
+			body:   "bad offer\n",
+			expected: HTTPError{
+				Message:    "bad offer",
+				StatusCode: http.StatusBadRequest,
+				RequestID:  "from-header",
+			},
+			code: apierrors.BadRequest,
+		},
+		{
+			name:   "json without a code",
+			status: http.StatusConflict,
+			body:   `{"message":"no code"}`,
+			expected: HTTPError{
+				Message:    `{"message":"no code"}`,
+				StatusCode: http.StatusConflict,
+				RequestID:  "from-header",
+			},
+			code: apierrors.Conflict,
+		},
+		{
+			name:   "empty body",
+			status: http.StatusBadGateway,
+			body:   "",
+			expected: HTTPError{
+				Message:    "GET http://solver/api/v1/deals returned 502 Bad Gateway",
+				StatusCode: http.StatusBadGateway,
+				RequestID:  "from-header",
+			},
+			code: apierrors.Internal,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				Status:     fmt.Sprintf("%d %s", tc.status, http.StatusText(tc.status)),
+				StatusCode: tc.status,
+				Header:     http.Header{X_REQUEST_ID_HEADER: []string{"from-header"}},
+				Request:    &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "http", Host: "solver", Path: "/api/v1/deals"}},
+			}
+			err := responseError(resp, []byte(tc.body))
+			require.Equal(t, tc.expected, err)
+			// the generic code for the status fills in for a missing one
+			require.Equal(t, tc.code, apierrors.CodeOf(err))
+		})
+	}
+}
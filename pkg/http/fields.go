@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// the query param a GET takes to only return some of the fields of the response
+// e.g. fields=id,deal.job_offer.id,state with dots to reach into nested objects
+const FIELDS_QUERY_PARAM = "fields"
+
+// a nil subtree means the whole value of that field is kept
+type fieldTree map[string]fieldTree
+
+func parseFields(fields string) fieldTree {
+	tree := fieldTree{}
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		node := tree
+		parts := strings.Split(field, ".")
+		for i, part := range parts {
+			child, ok := node[part]
+			if i == len(parts)-1 {
+				// asking for the whole field wins over asking for part of it
+				node[part] = nil
+				break
+			}
+			if ok && child == nil {
+				break
+			}
+			if !ok {
+				child = fieldTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// shape a response down to the fields that were asked for, lists are shaped
+// element by element and fields that do not exist are left out rather than
+// failing because the elements of a list do not always have the same ones
+func SelectFields(data any, fields string) (any, error) {
+	tree := parseFields(fields)
+	if len(tree) == 0 {
+		return data, nil
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	// numbers stay as they were written so big ints do not lose precision
+	decoder := json.NewDecoder(bytes.NewReader(dataBytes))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return pruneFields(value, tree), nil
+}
+
+func pruneFields(value any, tree fieldTree) any {
+	switch typed := value.(type) {
+	case []any:
+		for i := range typed {
+			typed[i] = pruneFields(typed[i], tree)
+		}
+		return typed
+	case map[string]any:
+		ret := map[string]any{}
+		for name, subtree := range tree {
+			field, ok := typed[name]
+			if !ok {
+				continue
+			}
+			if subtree == nil {
+				ret[name] = field
+			} else {
+				ret[name] = pruneFields(field, subtree)
+			}
+		}
+		return ret
+	}
+	return value
+}
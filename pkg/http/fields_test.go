@@ -0,0 +1,78 @@
+//go:build unit
+
+package http
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fieldsDeal struct {
+	ID       string         `json:"id"`
+	State    uint8          `json:"state"`
+	Price    uint64         `json:"price"`
+	JobOffer map[string]any `json:"job_offer"`
+}
+
+func selectedJSON(t *testing.T, data any, fields string) string {
+	selected, err := SelectFields(data, fields)
+	require.NoError(t, err)
+	out, err := json.Marshal(selected)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestSelectFields(t *testing.T) {
+	deal := fieldsDeal{
+		ID:       "deal",
+		State:    1,
+		Price:    18446744073709551615,
+		JobOffer: map[string]any{"id": "offer", "module": map[string]any{"repo": "cowsay", "hash": "v1"}},
+	}
+
+	require.JSONEq(t, `{"id":"deal","state":1}`, selectedJSON(t, deal, "id,state"))
+	// big numbers come back as they were written
+	require.JSONEq(t, `{"price":18446744073709551615}`, selectedJSON(t, deal, "price"))
+	require.Contains(t, selectedJSON(t, deal, "price"), "18446744073709551615")
+}
+
+func TestSelectNestedFields(t *testing.T) {
+	deal := fieldsDeal{
+		ID:       "deal",
+		JobOffer: map[string]any{"id": "offer", "module": map[string]any{"repo": "cowsay", "hash": "v1"}},
+	}
+
+	require.JSONEq(t, `{"job_offer":{"module":{"repo":"cowsay"}}}`, selectedJSON(t, deal, "job_offer.module.repo"))
+	require.JSONEq(t, `{"id":"deal","job_offer":{"id":"offer"}}`, selectedJSON(t, deal, " id , job_offer.id "))
+	// the whole field wins over part of it whichever comes first
+	whole := `{"job_offer":{"id":"offer","module":{"hash":"v1","repo":"cowsay"}}}`
+	require.JSONEq(t, whole, selectedJSON(t, deal, "job_offer.id,job_offer"))
+	require.JSONEq(t, whole, selectedJSON(t, deal, "job_offer,job_offer.id"))
+	// a path into a value that is not an object keeps the value
+	require.JSONEq(t, `{"id":"deal"}`, selectedJSON(t, deal, "id.nothing"))
+
+	// lists are shaped element by element
+	deals := []fieldsDeal{deal, {ID: "other"}}
+	require.JSONEq(t, `[{"id":"deal","job_offer":{"id":"offer"}},{"id":"other","job_offer":null}]`, selectedJSON(t, deals, "id,job_offer.id"))
+}
+
+func TestSelectUnknownFields(t *testing.T) {
+	deal := fieldsDeal{ID: "deal", JobOffer: map[string]any{"id": "offer"}}
+
+	require.JSONEq(t, `{}`, selectedJSON(t, deal, "nothing"))
+	require.JSONEq(t, `{"id":"deal"}`, selectedJSON(t, deal, "id,nothing"))
+	require.JSONEq(t, `{"job_offer":{}}`, selectedJSON(t, deal, "job_offer.nothing"))
+}
+
+func TestSelectEmptyFields(t *testing.T) {
+	deal := fieldsDeal{ID: "deal"}
+
+	// nothing asked for means everything comes back as it is
+	for _, fields := range []string{"", ",", " , "} {
+		selected, err := SelectFields(deal, fields)
+		require.NoError(t, err)
+		require.Equal(t, deal, selected)
+	}
+}
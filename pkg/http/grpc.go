@@ -0,0 +1,145 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serve gRPC on its own port alongside the REST api
+// register is given the server to add its services to before we start listening
+// the tls config is shared with the REST server so autocert only runs once
+// and so is the replay guard so a nonce used over REST cannot be used here,
+// the service's own interceptors run after ours
+func ListenAndServeGRPC(ctx context.Context, options ServerOptions, tlsConfig *tls.Config, replay *ReplayGuard, register func(*grpc.Server), interceptors ...grpc.ServerOption) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", options.Host, options.GRPCPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for grpc: %w", err)
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(grpcUnaryErrorInterceptor, grpcSignatureUnaryInterceptor, replay.grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(grpcStreamErrorInterceptor, grpcSignatureStreamInterceptor, replay.grpcStreamInterceptor),
+		// the same cap as a REST request body
+		grpc.MaxRecvMsgSize(int(options.Limits.MaxBodySize)),
+	}
+	serverOptions = append(serverOptions, interceptors...)
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(serverOptions...)
+	register(server)
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		log.Info().Msgf("grpc server listening on %s", listener.Addr().String())
+		serverErrors <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serverErrors:
+		return err
+	case <-ctx.Done():
+		// streams only end when the client goes away so we give
+		// them as long as the REST server gets and then cut them off
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			server.Stop()
+		}
+	}
+
+	return nil
+}
+
+// the gRPC version of CheckSignature, the same values are sent as metadata
+func CheckGRPCSignature(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return checkUserSignature(ctx, firstMetadata(md, X_LILYPAD_USER_HEADER), firstMetadata(md, X_LILYPAD_SIGNATURE_HEADER))
+}
+
+// the gRPC version of TypedSignature
+func GRPCTypedSignature(ctx context.Context) ([]byte, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return decodeTypedSignature(firstMetadata(md, X_LILYPAD_TYPED_SIGNATURE_HEADER))
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	values := md.Get(strings.ToLower(key))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// handlers return the same errors for REST and gRPC so we translate
+// the status code of an HTTPError into the matching gRPC code
+func GRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var httpError HTTPError
+	if !errors.As(err, &httpError) {
+		return status.Error(codes.Unknown, err.Error())
+	}
+	code := codes.Unknown
+	switch httpError.StatusCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusInternalServerError:
+		code = codes.Internal
+	}
+	return status.Error(code, httpError.Message)
+}
+
+func grpcUnaryErrorInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	res, err := handler(ctx, req)
+	if err != nil {
+		log.Error().
+			Str("method GRPC", info.FullMethod).
+			Err(err).
+			Msgf("")
+	}
+	return res, GRPCError(err)
+}
+
+func grpcStreamErrorInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, stream)
+	if err != nil {
+		log.Error().
+			Str("method GRPC", info.FullMethod).
+			Err(err).
+			Msgf("")
+	}
+	return GRPCError(err)
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// a check that takes longer than this counts as failed
+const readinessCheckTimeout = 5 * time.Second
+
+// something /readyz needs to be working before the server takes traffic
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type ReadinessResult struct {
+	// ok or unavailable
+	Status string `json:"status"`
+	// ok or the error for each check
+	Checks map[string]string `json:"checks"`
+}
+
+// /healthz says the process is up and /readyz says if every check passes,
+// a failed readiness check is a 503 so load balancers stop routing to us
+// while a failed liveness check means the process has to be restarted
+func ServeHealth(router *mux.Router, checks []ReadinessCheck) {
+	router.HandleFunc("/healthz", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/plain")
+		_, _ = res.Write([]byte("ok"))
+	}).Methods("GET")
+	router.HandleFunc("/readyz", func(res http.ResponseWriter, req *http.Request) {
+		result := runReadinessChecks(req.Context(), checks)
+		res.Header().Set("Content-Type", "application/json")
+		if result.Status != "ok" {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(res).Encode(result); err != nil {
+			log.Error().Err(err).Msgf("error writing readiness result")
+		}
+	}).Methods("GET")
+}
+
+func runReadinessChecks(ctx context.Context, checks []ReadinessCheck) ReadinessResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	result := ReadinessResult{
+		Status: "ok",
+		Checks: map[string]string{},
+	}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check ReadinessCheck) {
+			defer wg.Done()
+			err := check.Check(ctx)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				log.Warn().Err(err).Str("check", check.Name).Msgf("readiness check failed")
+				result.Status = "unavailable"
+				result.Checks[check.Name] = err.Error()
+				return
+			}
+			result.Checks[check.Name] = "ok"
+		}(check)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,11 @@
+package http
+
+// clients send a key of their own choosing with a POST and a retry with the
+// same key gets the first response back instead of running the handler again
+const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+
+// set on a response that was replayed for a repeated idempotency key
+const IDEMPOTENT_REPLAYED_HEADER = "Idempotent-Replayed"
+
+// longer keys than this are turned away
+const MAX_IDEMPOTENCY_KEY_LENGTH = 255
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// our own registry rather than the global one so only the metrics
+// registered here end up on /metrics
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lilypad",
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "lilypad",
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "How long HTTP requests took by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	httpRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lilypad",
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "HTTP requests turned away by the rate limiter by route.",
+	}, []string{"route"})
+	httpConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "lilypad",
+		Subsystem: "http",
+		Name:      "connections",
+		Help:      "Open HTTP connections by state.",
+	}, []string{"state"})
+	httpConnectionsOpened = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "lilypad",
+		Subsystem: "http",
+		Name:      "connections_opened_total",
+		Help:      "HTTP connections accepted.",
+	})
+	httpConnectionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lilypad",
+		Subsystem: "http",
+		Name:      "connection_requests_total",
+		Help:      "HTTP requests by protocol and whether they reused an open connection.",
+	}, []string{"protocol", "reused"})
+	websocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "lilypad",
+		Subsystem: "websocket",
+		Name:      "connections",
+		Help:      "Open websocket connections.",
+	})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		httpRateLimited,
+		httpConnections,
+		httpConnectionsOpened,
+		httpConnectionRequests,
+		websocketConnections,
+	)
+}
+
+// add metrics that only one server has e.g. the solver store counts
+func RegisterMetrics(metrics ...prometheus.Collector) error {
+	for _, metric := range metrics {
+		if err := metricsRegistry.Register(metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the route template keeps the number of label values down,
+// the path has ids in it and would make a series per deal
+func routeLabel(req *http.Request) string {
+	if route := mux.CurrentRoute(req); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
+
+// router middleware that counts and times every request
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+		route := routeLabel(req)
+		httpRequests.WithLabelValues(route, req.Method, strconv.Itoa(recorder.status)).Inc()
+		httpRequestDuration.WithLabelValues(route, req.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+func RecordRateLimited(req *http.Request) {
+	httpRateLimited.WithLabelValues(routeLabel(req)).Inc()
+}
+
+// keeps the status code for the metrics, it has to pass flushes through
+// for server-sent events and hijacks through for websockets
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (recorder *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := recorder.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer cannot be hijacked")
+	}
+	recorder.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// serve the metrics at /metrics, with a token prometheus has to send
+// it as a bearer token in the Authorization header
+func ServeMetrics(router *mux.Router, options PrometheusOptions) {
+	handler := promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+	router.HandleFunc("/metrics", func(res http.ResponseWriter, req *http.Request) {
+		if options.Token != "" {
+			expected := []byte("Bearer " + options.Token)
+			if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), expected) != 1 {
+				WriteErrorMessage(res, req, "invalid metrics token", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(res, req)
+	}).Methods("GET")
+}
@@ -0,0 +1,382 @@
+package http
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+)
+
+// describes what one route takes and returns so we can document it,
+// the paths and methods themselves come from the router
+type APIOperation struct {
+	Summary string
+	// a zero value of the request body, nil when there is no body
+	Request any
+	// a zero value of the response body, nil when there is no body
+	Response any
+	// the content type when the body is not json e.g. a tar of files
+	RequestContentType  string
+	ResponseContentType string
+	Query               []APIParam
+	// the route checks the X-Lilypad-User and X-Lilypad-Signature headers
+	Signed bool
+	// the route replays its response for a repeated Idempotency-Key
+	Idempotent bool
+}
+
+type APIParam struct {
+	Name        string
+	Description string
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       OpenAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                      `json:"components"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	OperationID string                     `json:"operationId"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+}
+
+// gorilla lets a path variable carry a pattern e.g. {id:[0-9]+}
+var routeVariablePattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// build an OpenAPI 3 document from the routes registered on the router
+// operations are keyed by "METHOD /path/template" as the router reports them
+func GenerateOpenAPI(router *mux.Router, info OpenAPIInfo, operations map[string]APIOperation) (*OpenAPIDocument, error) {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]map[string]OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]*OpenAPISchema{},
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				"LilypadUser": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        X_LILYPAD_USER_HEADER,
+					Description: "base64 encoded json with the address of the signer, a random nonce and the millisecond timestamp it was signed at",
+				},
+				"LilypadSignature": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        X_LILYPAD_SIGNATURE_HEADER,
+					Description: "the user header signed by the private key of the address",
+				},
+				"LilypadApiKey": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        X_LILYPAD_API_KEY_HEADER,
+					Description: "a read only key from an admin, GET requests can send it instead of signing",
+				},
+			},
+		},
+	}
+	schemas := &schemaBuilder{schemas: doc.Components.Schemas}
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// routes without a method are prefixes for subrouters
+			return nil
+		}
+		path := routeVariablePattern.ReplaceAllString(template, "{$1}")
+		for _, method := range methods {
+			operation := operations[fmt.Sprintf("%s %s", method, template)]
+			if _, ok := doc.Paths[path]; !ok {
+				doc.Paths[path] = map[string]OpenAPIOperation{}
+			}
+			doc.Paths[path][strings.ToLower(method)] = schemas.operation(method, path, operation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func operationID(method string, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '_' }) {
+		if strings.HasPrefix(part, "{") {
+			part = "by_" + strings.Trim(part, "{}")
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "_")
+}
+
+func (builder *schemaBuilder) operation(method string, path string, operation APIOperation) OpenAPIOperation {
+	// every error is the same envelope, clients branch on its code
+	errorContent := builder.content(apierrors.ErrorEnvelope{}, "")
+	ret := OpenAPIOperation{
+		Summary:     operation.Summary,
+		OperationID: operationID(method, path),
+		Responses: map[string]OpenAPIResponse{
+			"200":     {Description: "OK"},
+			"default": {Description: "an error, the code says what went wrong", Content: errorContent},
+		},
+	}
+	for _, match := range routeVariablePattern.FindAllStringSubmatch(path, -1) {
+		ret.Parameters = append(ret.Parameters, OpenAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &OpenAPISchema{Type: "string"},
+		})
+	}
+	for _, param := range operation.Query {
+		ret.Parameters = append(ret.Parameters, OpenAPIParameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Schema:      &OpenAPISchema{Type: "string"},
+		})
+	}
+	// every json GET goes through GetHandler so they can all be shaped
+	if method == http.MethodGet && operation.Response != nil && operation.ResponseContentType == "" {
+		ret.Parameters = append(ret.Parameters, OpenAPIParameter{
+			Name:        FIELDS_QUERY_PARAM,
+			In:          "query",
+			Description: "comma separated fields to return e.g. id,deal.job_offer.id, all of them when not set",
+			Schema:      &OpenAPISchema{Type: "string"},
+		})
+	}
+	if operation.Idempotent {
+		ret.Parameters = append(ret.Parameters, OpenAPIParameter{
+			Name:        IDEMPOTENCY_KEY_HEADER,
+			In:          "header",
+			Description: "a retry with the same key and body gets the first response back",
+			Schema:      &OpenAPISchema{Type: "string"},
+		})
+		ret.Responses["409"] = OpenAPIResponse{Description: "a request with the same idempotency key is still running", Content: errorContent}
+		ret.Responses["422"] = OpenAPIResponse{Description: "the idempotency key was used with a different body", Content: errorContent}
+	}
+	if operation.Request != nil || operation.RequestContentType != "" {
+		ret.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  builder.content(operation.Request, operation.RequestContentType),
+		}
+	}
+	if operation.Response != nil || operation.ResponseContentType != "" {
+		ret.Responses["200"] = OpenAPIResponse{
+			Description: "OK",
+			Content:     builder.content(operation.Response, operation.ResponseContentType),
+		}
+	}
+	if operation.Signed {
+		ret.Security = []map[string][]string{{"LilypadUser": {}, "LilypadSignature": {}}}
+		ret.Responses["401"] = OpenAPIResponse{Description: "the signature is missing, does not match or has been used before", Content: errorContent}
+	}
+	return ret
+}
+
+func (builder *schemaBuilder) content(value any, contentType string) map[string]OpenAPIMediaType {
+	if contentType != "" {
+		return map[string]OpenAPIMediaType{
+			contentType: {Schema: &OpenAPISchema{Type: "string", Format: "binary"}},
+		}
+	}
+	return map[string]OpenAPIMediaType{
+		"application/json": {Schema: builder.schema(reflect.TypeOf(value))},
+	}
+}
+
+// turns go types into json schemas using the same json tags encoding/json does
+// named structs go into the components so they are only described once
+type schemaBuilder struct {
+	schemas map[string]*OpenAPISchema
+}
+
+func (builder *schemaBuilder) schema(t reflect.Type) *OpenAPISchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := builder.schema(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: builder.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: builder.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return builder.structSchema(t)
+		}
+		if _, ok := builder.schemas[t.Name()]; !ok {
+			// placeholder first so types that refer to themselves terminate
+			builder.schemas[t.Name()] = &OpenAPISchema{}
+			*builder.schemas[t.Name()] = *builder.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + t.Name()}
+	}
+	// interfaces and anything else can hold any json value
+	return &OpenAPISchema{}
+}
+
+func (builder *schemaBuilder) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: map[string]*OpenAPISchema{},
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		// embedded structs without a name are flattened like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := builder.structSchema(field.Type)
+			for key, value := range embedded.Properties {
+				schema.Properties[key] = value
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = builder.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+//go:embed swagger.html
+var swaggerHTML []byte
+
+// the only files the page loads from the assets directory
+var swaggerAssets = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+}
+
+// serve the document at /openapi.json and a swagger ui to browse it at /docs
+func ServeOpenAPI(router *mux.Router, doc *OpenAPIDocument, options DocsOptions) error {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/openapi.json", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write(docBytes)
+	}).Methods("GET")
+	if options.AssetsDir == "" {
+		return nil
+	}
+	router.HandleFunc("/docs", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = res.Write(swaggerHTML)
+	}).Methods("GET")
+	router.HandleFunc("/docs/{asset}", func(res http.ResponseWriter, req *http.Request) {
+		asset := mux.Vars(req)["asset"]
+		contentType, ok := swaggerAssets[asset]
+		if !ok {
+			http.NotFound(res, req)
+			return
+		}
+		res.Header().Set("Content-Type", contentType)
+		http.ServeFile(res, req, filepath.Join(options.AssetsDir, asset))
+	}).Methods("GET")
+	return nil
+}
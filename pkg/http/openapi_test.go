@@ -0,0 +1,43 @@
+//go:build unit
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func serveDocs(t *testing.T, options DocsOptions, path string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	require.NoError(t, ServeOpenAPI(router, &OpenAPIDocument{}, options))
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+	return res
+}
+
+func TestServeDocsAssets(t *testing.T) {
+	require.Equal(t, http.StatusOK, serveDocs(t, DocsOptions{}, "/openapi.json").Code)
+	require.Equal(t, http.StatusNotFound, serveDocs(t, DocsOptions{}, "/docs").Code)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "swagger-ui.css"), []byte("body{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644))
+	options := DocsOptions{AssetsDir: dir}
+
+	page := serveDocs(t, options, "/docs")
+	require.Equal(t, http.StatusOK, page.Code)
+	require.NotContains(t, page.Body.String(), "https://")
+
+	css := serveDocs(t, options, "/docs/swagger-ui.css")
+	require.Equal(t, http.StatusOK, css.Code)
+	require.Equal(t, "body{}", css.Body.String())
+
+	// only the files the page loads are served
+	require.Equal(t, http.StatusNotFound, serveDocs(t, options, "/docs/secret.txt").Code)
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// off by default until the clients that do not send a nonce have upgraded
+var DefaultReplayOptions = ReplayOptions{
+	Enabled:   false,
+	ClockSkew: 300,
+	MaxNonces: 100000,
+}
+
+// remembers the nonces of the signed requests we have let through,
+// the cache is in memory so each solver instance keeps its own
+type ReplayGuard struct {
+	options   ReplayOptions
+	mutex     sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func NewReplayGuard(options ReplayOptions) *ReplayGuard {
+	return &ReplayGuard{
+		options:   options,
+		seen:      map[string]time.Time{},
+		lastPrune: time.Now(),
+	}
+}
+
+// requests without signature headers are left to the handlers,
+// the ones that need a signature turn them away themselves
+func (guard *ReplayGuard) check(ctx context.Context, userHeader string, signatureHeader string) error {
+	if guard == nil || !guard.options.Enabled || (userHeader == "" && signatureHeader == "") {
+		return nil
+	}
+	// the signature covers the nonce and timestamp so we check it first,
+	// otherwise anyone could fill the cache with nonces for other addresses
+	authUser, err := verifyUserSignature(ctx, userHeader, signatureHeader)
+	if err != nil {
+		return err
+	}
+	if authUser.Nonce == "" || authUser.Timestamp == 0 {
+		return HTTPError{
+			Message:    "signed requests need a nonce and a timestamp, please upgrade the client",
+			StatusCode: http.StatusUnauthorized,
+		}
+	}
+	skew := time.Duration(guard.options.ClockSkew) * time.Second
+	signedAt := time.UnixMilli(authUser.Timestamp)
+	now := time.Now()
+	if signedAt.Before(now.Add(-skew)) || signedAt.After(now.Add(skew)) {
+		return HTTPError{
+			Message:    fmt.Sprintf("the request was signed at %s which is more than %s from the server time", signedAt.UTC().Format(time.RFC3339), skew),
+			StatusCode: http.StatusUnauthorized,
+		}
+	}
+
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+	if now.Sub(guard.lastPrune) > skew || (guard.options.MaxNonces > 0 && len(guard.seen) >= guard.options.MaxNonces) {
+		guard.prune(now)
+	}
+	key := authUser.Address + ":" + authUser.Nonce
+	if _, ok := guard.seen[key]; ok {
+		return HTTPError{
+			Message:    "the request nonce has already been used",
+			StatusCode: http.StatusUnauthorized,
+		}
+	}
+	if guard.options.MaxNonces > 0 && len(guard.seen) >= guard.options.MaxNonces {
+		return HTTPError{
+			Message:    "too many signed requests, please try again shortly",
+			StatusCode: http.StatusServiceUnavailable,
+		}
+	}
+	// past this the timestamp check turns the request away by itself
+	guard.seen[key] = signedAt.Add(skew)
+	return nil
+}
+
+func (guard *ReplayGuard) prune(now time.Time) {
+	for key, expires := range guard.seen {
+		if now.After(expires) {
+			delete(guard.seen, key)
+		}
+	}
+	guard.lastPrune = now
+}
+
+func (guard *ReplayGuard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		err := guard.check(req.Context(), req.Header.Get(X_LILYPAD_USER_HEADER), req.Header.Get(X_LILYPAD_SIGNATURE_HEADER))
+		if err != nil {
+			RequestLogger(req).Warn().Err(err).Msgf("rejected signed request")
+			httpError, ok := err.(HTTPError)
+			if !ok {
+				httpError = HTTPError{Message: err.Error(), StatusCode: http.StatusUnauthorized}
+			}
+			WriteError(res, req, httpError)
+			return
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+func (guard *ReplayGuard) checkGRPC(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return guard.check(ctx, firstMetadata(md, X_LILYPAD_USER_HEADER), firstMetadata(md, X_LILYPAD_SIGNATURE_HEADER))
+}
+
+func (guard *ReplayGuard) grpcUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := guard.checkGRPC(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// a stream is checked once when it opens
+func (guard *ReplayGuard) grpcStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := guard.checkGRPC(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
@@ -0,0 +1,90 @@
+//go:build unit
+
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+// the headers encodeUserAddress would send but with the nonce and
+// timestamp picked by the test
+func signedHeaders(t *testing.T, signer web3.Signer, nonce string, signedAt time.Time) (string, string) {
+	userBytes, err := json.Marshal(AuthUser{
+		Address:   signer.Address().String(),
+		Nonce:     nonce,
+		Timestamp: signedAt.UnixMilli(),
+	})
+	require.NoError(t, err)
+	signature, err := signer.Sign(context.Background(), userBytes)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(userBytes), base64.StdEncoding.EncodeToString(signature)
+}
+
+func requireStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	httpError, ok := err.(HTTPError)
+	require.True(t, ok, "expected an HTTPError, got %v", err)
+	require.Equal(t, status, httpError.StatusCode, httpError.Message)
+}
+
+func TestReplayGuard(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := web3.NewKeySigner(key)
+	guard := NewReplayGuard(ReplayOptions{Enabled: true, ClockSkew: 60, MaxNonces: 100})
+
+	t.Run("a nonce can only be used once", func(t *testing.T) {
+		user, signature := signedHeaders(t, signer, "once", time.Now())
+		require.NoError(t, guard.check(ctx, user, signature))
+		requireStatus(t, guard.check(ctx, user, signature), http.StatusUnauthorized)
+
+		// another address can pick the same nonce
+		otherKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		user, signature = signedHeaders(t, web3.NewKeySigner(otherKey), "once", time.Now())
+		require.NoError(t, guard.check(ctx, user, signature))
+	})
+
+	t.Run("stale and future timestamps are turned away", func(t *testing.T) {
+		user, signature := signedHeaders(t, signer, "stale", time.Now().Add(-2*time.Minute))
+		requireStatus(t, guard.check(ctx, user, signature), http.StatusUnauthorized)
+		user, signature = signedHeaders(t, signer, "future", time.Now().Add(2*time.Minute))
+		requireStatus(t, guard.check(ctx, user, signature), http.StatusUnauthorized)
+		// inside the skew is fine
+		user, signature = signedHeaders(t, signer, "skewed", time.Now().Add(-30*time.Second))
+		require.NoError(t, guard.check(ctx, user, signature))
+	})
+
+	t.Run("old clients without a nonce are turned away", func(t *testing.T) {
+		user, signature := signedHeaders(t, signer, "", time.Now())
+		requireStatus(t, guard.check(ctx, user, signature), http.StatusUnauthorized)
+	})
+
+	t.Run("a bad signature does not use up the nonce", func(t *testing.T) {
+		user, _ := signedHeaders(t, signer, "forged", time.Now())
+		otherKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		_, forged := signedHeaders(t, web3.NewKeySigner(otherKey), "forged", time.Now())
+		requireStatus(t, guard.check(ctx, user, forged), http.StatusUnauthorized)
+
+		user, signature := signedHeaders(t, signer, "forged", time.Now())
+		require.NoError(t, guard.check(ctx, user, signature))
+	})
+
+	t.Run("nothing is checked when it is off", func(t *testing.T) {
+		off := NewReplayGuard(ReplayOptions{Enabled: false, ClockSkew: 60})
+		user, signature := signedHeaders(t, signer, "", time.Now().Add(-time.Hour))
+		require.NoError(t, off.check(ctx, user, signature))
+		require.NoError(t, off.check(ctx, user, signature))
+	})
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// the id of a request, a client can send one so its logs and ours line up
+// and we send it back either way
+const X_REQUEST_ID_HEADER = "X-Request-Id"
+
+// longer ids than this from clients are replaced so they cannot bloat the logs
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// wraps the whole router so every request has an id, the context logger
+// carries it so log.Ctx(req.Context()) lines can be tied to the request
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(X_REQUEST_ID_HEADER)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		res.Header().Set(X_REQUEST_ID_HEADER, id)
+		ctx := context.WithValue(req.Context(), requestIDKey{}, id)
+		ctx = log.With().Str("request_id", id).Logger().WithContext(ctx)
+		next.ServeHTTP(res, req.WithContext(ctx))
+	})
+}
+
+// the logger for a request, it has the request id when the middleware ran
+func RequestLogger(req *http.Request) *zerolog.Logger {
+	if RequestIDFromContext(req.Context()) != "" {
+		return log.Ctx(req.Context())
+	}
+	return &log.Logger
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// the id we send with a call, a new one unless the options already have one
+func requestIDFor(options ClientOptions) string {
+	if options.RequestID != "" {
+		return options.RequestID
+	}
+	return uuid.New().String()
+}
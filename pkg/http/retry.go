@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog/log"
+)
+
+var DefaultClientRetryOptions = ClientRetryOptions{
+	MaxRetries:      10,
+	MinBackoff:      1000,
+	MaxBackoff:      30000,
+	BreakerFailures: 5,
+	BreakerCooldown: 30,
+}
+
+// calls to a host with an open circuit fail with this straight away
+var ErrCircuitOpen = errors.New("circuit open")
+
+func (options ClientRetryOptions) withDefaults() ClientRetryOptions {
+	if options == (ClientRetryOptions{}) {
+		return DefaultClientRetryOptions
+	}
+	return options
+}
+
+// the wait doubles each attempt and is then picked at random from its upper
+// half so clients that failed together do not all come back together,
+// a Retry-After from the server is used as it is
+func jitteredBackoff(min, max time.Duration, attempt int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	wait := max
+	if attempt < 30 {
+		wait = min << attempt
+	}
+	if wait <= 0 || wait > max {
+		wait = max
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// a call that is not safe to repeat, e.g. a POST without an idempotency key,
+// is only tried once since the server may have handled it before failing
+func retryPolicy(idempotent bool) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if errors.Is(err, ErrCircuitOpen) {
+			return false, err
+		}
+		if !idempotent {
+			return false, nil
+		}
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+}
+
+// one per host, shared by every client in the process
+type circuitBreaker struct {
+	mutex    sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	// a trial call is running while the circuit is half open
+	trial bool
+}
+
+var circuitBreakers sync.Map
+
+func circuitBreakerFor(host string) *circuitBreaker {
+	breaker, _ := circuitBreakers.LoadOrStore(host, &circuitBreaker{})
+	return breaker.(*circuitBreaker)
+}
+
+func (breaker *circuitBreaker) allow(host string, options ClientRetryOptions) error {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	if !breaker.open {
+		return nil
+	}
+	cooldown := time.Duration(options.BreakerCooldown) * time.Second
+	if wait := cooldown - time.Since(breaker.openedAt); wait > 0 {
+		return fmt.Errorf("%w for %s, calls resume in %s", ErrCircuitOpen, host, wait.Round(time.Second))
+	}
+	if breaker.trial {
+		return fmt.Errorf("%w for %s, a trial call is running", ErrCircuitOpen, host)
+	}
+	breaker.trial = true
+	return nil
+}
+
+func (breaker *circuitBreaker) record(host string, options ClientRetryOptions, failed bool) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	breaker.trial = false
+	if !failed {
+		if breaker.open {
+			log.Info().Str("host", host).Msgf("circuit closed")
+		}
+		breaker.failures = 0
+		breaker.open = false
+		return
+	}
+	breaker.failures++
+	if breaker.open {
+		// the trial failed so we wait out another cooldown
+		breaker.openedAt = time.Now()
+		return
+	}
+	if breaker.failures >= options.BreakerFailures {
+		log.Warn().Str("host", host).Int("failures", breaker.failures).Msgf("circuit opened")
+		breaker.open = true
+		breaker.openedAt = time.Now()
+	}
+}
+
+// counts connection errors and 5xx responses against the host, every
+// attempt the retry client makes goes through here
+type circuitBreakerTransport struct {
+	next    http.RoundTripper
+	options ClientRetryOptions
+}
+
+func (transport *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	breaker := circuitBreakerFor(host)
+	if err := breaker.allow(host, transport.options); err != nil {
+		return nil, err
+	}
+	resp, err := transport.next.RoundTrip(req)
+	if errors.Is(err, context.Canceled) {
+		// the caller gave up, that says nothing about the host
+		breaker.mutex.Lock()
+		breaker.trial = false
+		breaker.mutex.Unlock()
+		return resp, err
+	}
+	breaker.record(host, transport.options, err != nil || resp.StatusCode >= 500)
+	return resp, err
+}
@@ -0,0 +1,92 @@
+//go:build unit
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJitteredBackoff(t *testing.T) {
+	min := 100 * time.Millisecond
+	max := 2 * time.Second
+	for attempt := 0; attempt < 40; attempt++ {
+		ceiling := max
+		if attempt < 5 {
+			ceiling = min << attempt
+		}
+		for range 20 {
+			wait := jitteredBackoff(min, max, attempt, nil)
+			require.GreaterOrEqual(t, wait, ceiling/2, "attempt %d", attempt)
+			require.LessOrEqual(t, wait, ceiling, "attempt %d", attempt)
+		}
+	}
+
+	// the server's Retry-After wins over our own backoff
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "7")
+	require.Equal(t, 7*time.Second, jitteredBackoff(min, max, 0, resp))
+	resp.Header.Set("Retry-After", "soon")
+	require.LessOrEqual(t, jitteredBackoff(min, max, 0, resp), min)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	options := ClientRetryOptions{BreakerFailures: 3, BreakerCooldown: 30}
+	breaker := &circuitBreaker{}
+
+	// closed, failures under the limit still let calls through
+	for range options.BreakerFailures - 1 {
+		require.NoError(t, breaker.allow("host", options))
+		breaker.record("host", options, true)
+	}
+	require.NoError(t, breaker.allow("host", options))
+	breaker.record("host", options, true)
+
+	// open, calls fail without reaching the host
+	require.ErrorIs(t, breaker.allow("host", options), ErrCircuitOpen)
+
+	// half open after the cooldown, one trial call at a time
+	breaker.openedAt = time.Now().Add(-time.Duration(options.BreakerCooldown) * time.Second)
+	require.NoError(t, breaker.allow("host", options))
+	require.ErrorIs(t, breaker.allow("host", options), ErrCircuitOpen)
+
+	// a failed trial waits out another cooldown
+	breaker.record("host", options, true)
+	require.ErrorIs(t, breaker.allow("host", options), ErrCircuitOpen)
+
+	// a good trial closes it and the failures start again from zero
+	breaker.openedAt = time.Now().Add(-time.Duration(options.BreakerCooldown) * time.Second)
+	require.NoError(t, breaker.allow("host", options))
+	breaker.record("host", options, false)
+	require.False(t, breaker.open)
+	require.Zero(t, breaker.failures)
+	require.NoError(t, breaker.allow("host", options))
+}
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		res.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	options := ClientRetryOptions{BreakerFailures: 2, BreakerCooldown: 30}
+	client := &http.Client{Transport: &circuitBreakerTransport{next: http.DefaultTransport, options: options}}
+	for range 4 {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		} else {
+			require.True(t, errors.Is(err, ErrCircuitOpen), err)
+		}
+	}
+	// the calls after the circuit opened never reached the server
+	require.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// sets up HTTP/2 and keep-alives on srv and counts its connections,
+// call it once the handler and tls config are in place
+func ConfigureServer(srv *http.Server, options ConnectionOptions) error {
+	// the config is shared with the grpc server and HTTP/2 adds to its protocols
+	srv.TLSConfig = srv.TLSConfig.Clone()
+	srv.SetKeepAlivesEnabled(options.KeepAlive)
+	trackConnections(srv)
+
+	if !options.HTTP2 {
+		// an empty map rather than nil stops net/http turning HTTP/2 on itself
+		srv.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		return nil
+	}
+	h2 := &http2.Server{
+		MaxConcurrentStreams: uint32(options.MaxConcurrentStreams),
+		IdleTimeout:          srv.IdleTimeout,
+		ReadIdleTimeout:      seconds(options.HTTP2PingInterval),
+		PingTimeout:          seconds(options.HTTP2PingTimeout),
+	}
+	if options.HTTP2Cleartext {
+		srv.Handler = h2c.NewHandler(srv.Handler, h2)
+	}
+	if srv.TLSConfig == nil {
+		return nil
+	}
+	if err := http2.ConfigureServer(srv, h2); err != nil {
+		return fmt.Errorf("failed to configure HTTP/2: %w", err)
+	}
+	return nil
+}
+
+// listens on the server address with the tcp keep-alive period we want,
+// net/http turns probes on every 15 seconds if we leave it to them
+func Listen(srv *http.Server, options ConnectionOptions) (net.Listener, error) {
+	config := net.ListenConfig{
+		KeepAlive: seconds(options.TCPKeepAlive),
+	}
+	if options.TCPKeepAlive == 0 {
+		config.KeepAlive = -1
+	}
+	listener, err := config.Listen(context.Background(), "tcp", srv.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	return listener, nil
+}
+
+type connectionRequestsKey struct{}
+
+// how many requests a connection has served so we can tell
+// the first one apart from the ones reusing it
+type connectionRequests struct {
+	count atomic.Int64
+}
+
+func trackConnections(srv *http.Server) {
+	// the last state of each open connection so the gauge can move it on
+	var states sync.Map
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			httpConnectionsOpened.Inc()
+		}
+		if previous, ok := states.Load(conn); ok {
+			httpConnections.WithLabelValues(previous.(http.ConnState).String()).Dec()
+		}
+		// a hijacked connection is the websocket's to count now
+		if state == http.StateClosed || state == http.StateHijacked {
+			states.Delete(conn)
+			return
+		}
+		states.Store(conn, state)
+		httpConnections.WithLabelValues(state.String()).Inc()
+	}
+	srv.ConnContext = func(ctx context.Context, conn net.Conn) context.Context {
+		return context.WithValue(ctx, connectionRequestsKey{}, &connectionRequests{})
+	}
+	next := srv.Handler
+	srv.Handler = http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if requests, ok := req.Context().Value(connectionRequestsKey{}).(*connectionRequests); ok {
+			reused := requests.count.Add(1) > 1
+			httpConnectionRequests.WithLabelValues(req.Proto, strconv.FormatBool(reused)).Inc()
+		}
+		next.ServeHTTP(res, req)
+	})
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+type signatureKey struct{}
+
+// the outcome of checking a request's signature headers, the replay guard,
+// the rate limiter, the audit log and the handler all want it so the first
+// to ask checks the signature and the rest get the same answer
+type verifiedSignature struct {
+	once     sync.Once
+	authUser AuthUser
+	err      error
+}
+
+func withSignatureCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(signatureKey{}).(*verifiedSignature); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, signatureKey{}, &verifiedSignature{})
+}
+
+// the headers are the same for the whole request so the context only
+// ever holds the answer for one pair of them
+func verifyUserSignature(ctx context.Context, userHeader string, userSignature string) (AuthUser, error) {
+	verified, ok := ctx.Value(signatureKey{}).(*verifiedSignature)
+	if !ok {
+		return verifySignatureHeaders(ctx, userHeader, userSignature)
+	}
+	verified.once.Do(func() {
+		verified.authUser, verified.err = verifySignatureHeaders(ctx, userHeader, userSignature)
+	})
+	return verified.authUser, verified.err
+}
+
+// goes in front of everything that looks at the signature headers so
+// the signature is only checked once however many of them do
+func SignatureMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(res, req.WithContext(withSignatureCache(req.Context())))
+	})
+}
+
+type signedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *signedServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+func grpcSignatureUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withSignatureCache(ctx), req)
+}
+
+func grpcSignatureStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &signedServerStream{ServerStream: stream, ctx: withSignatureCache(stream.Context())})
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package http
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lilypad-tech/lilypad/pkg/signatures"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+// a smart wallet that takes any signature, it counts how often it is asked
+type countingWalletBackend struct {
+	calls int
+}
+
+func (backend *countingWalletBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60, 0x80}, nil
+}
+
+func (backend *countingWalletBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	backend.calls++
+	bytes4, err := abi.NewType("bytes4", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return abi.Arguments{{Type: bytes4}}.Pack([4]byte{0x16, 0x26, 0xba, 0x7e})
+}
+
+func TestSignatureCheckedOnce(t *testing.T) {
+	backend := &countingWalletBackend{}
+	signatures.SetDefault(signatures.NewVerifier(backend))
+	defer signatures.SetDefault(signatures.NewVerifier(nil))
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	wallet := common.HexToAddress("0x1271")
+
+	replay := DefaultReplayOptions
+	replay.Enabled = true
+	handler := SignatureMiddleware(NewReplayGuard(replay).Middleware(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// the audit log and the handler both look at the signer
+		for range 2 {
+			address, err := CheckSignature(req)
+			require.NoError(t, err)
+			require.Equal(t, wallet.String(), address)
+		}
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/job_offers", nil)
+	require.NoError(t, setSignatureHeaders(context.Background(), req.Header, web3.NewKeySigner(key), wallet.String()))
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, 1, backend.calls)
+
+	// without the middleware each look checks the signature again
+	require.NoError(t, setSignatureHeaders(context.Background(), req.Header, web3.NewKeySigner(key), wallet.String()))
+	_, err = CheckSignature(req)
+	require.NoError(t, err)
+	require.Equal(t, 2, backend.calls)
+}
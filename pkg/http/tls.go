@@ -0,0 +1,130 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func (options TLSOptions) Enabled() bool {
+	return options.CertFile != "" || len(options.AutocertHosts) > 0
+}
+
+// the tls config the REST and gRPC servers listen with, nil means plain http
+// autocert answers the TLS-ALPN-01 challenge so the server has to be
+// reachable on port 443 for the hosts it gets certificates for
+func ServerTLSConfig(options TLSOptions) (*tls.Config, error) {
+	if !options.Enabled() {
+		return nil, nil
+	}
+	if options.CertFile != "" && len(options.AutocertHosts) > 0 {
+		return nil, fmt.Errorf("use either a tls cert file or autocert hosts, not both")
+	}
+
+	if options.CertFile != "" {
+		if options.KeyFile == "" {
+			return nil, fmt.Errorf("a tls key file is needed with the cert file")
+		}
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls key pair: %w", err)
+		}
+		config := &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		return config, addClientAuth(config, options)
+	}
+
+	cacheDir := options.AutocertCacheDir
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find a cache dir for autocert: %w", err)
+		}
+		cacheDir = filepath.Join(userCacheDir, "lilypad", "autocert")
+	}
+	log.Info().Msgf("getting tls certificates for %v from let's encrypt, cached in %s", options.AutocertHosts, cacheDir)
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(options.AutocertHosts...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      options.AutocertEmail,
+	}
+	config := manager.TLSConfig()
+	config.MinVersion = tls.VersionTLS12
+	return config, addClientAuth(config, options)
+}
+
+func addClientAuth(config *tls.Config, options TLSOptions) error {
+	if options.ClientCAFile == "" {
+		if options.RequireClientCert {
+			return fmt.Errorf("a client CA file is needed to require client certificates")
+		}
+		return nil
+	}
+	pool, err := loadCertPool(options.ClientCAFile)
+	if err != nil {
+		return err
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if options.RequireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", file)
+	}
+	return pool, nil
+}
+
+// the same options are used for every request a client makes
+// so we only read the files once
+var clientTLSConfigs sync.Map
+
+// the tls config a client dials with, nil means the go defaults
+func ClientTLSConfig(options ClientTLSOptions) (*tls.Config, error) {
+	if options == (ClientTLSOptions{}) {
+		return nil, nil
+	}
+	if config, ok := clientTLSConfigs.Load(options); ok {
+		return config.(*tls.Config), nil
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if (options.CertFile == "") != (options.KeyFile == "") {
+		return nil, fmt.Errorf("the client tls cert and key files have to be set together")
+	}
+	if options.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client tls key pair: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if options.CAFile != "" {
+		pool, err := loadCertPool(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	clientTLSConfigs.Store(options, config)
+	return config, nil
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var DefaultClientConnectionOptions = ClientConnectionOptions{
+	DialTimeout:           30,
+	TLSHandshakeTimeout:   10,
+	ResponseHeaderTimeout: 60,
+	RequestTimeout:        600,
+	KeepAlive:             30,
+	IdleConnTimeout:       90,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   10,
+	MaxConnsPerHost:       0,
+}
+
+func (options ClientConnectionOptions) withDefaults() ClientConnectionOptions {
+	if options == (ClientConnectionOptions{}) {
+		return DefaultClientConnectionOptions
+	}
+	return options
+}
+
+func seconds(value int) time.Duration {
+	return time.Duration(value) * time.Second
+}
+
+type clientTransportKey struct {
+	connection ClientConnectionOptions
+	tls        ClientTLSOptions
+}
+
+// a client is made for every call so the transports are kept here,
+// otherwise nothing would reuse the pooled connections
+var clientTransports sync.Map
+
+func clientTransport(connection ClientConnectionOptions, tlsOptions ClientTLSOptions) (*http.Transport, error) {
+	key := clientTransportKey{connection: connection, tls: tlsOptions}
+	if transport, ok := clientTransports.Load(key); ok {
+		return transport.(*http.Transport), nil
+	}
+	tlsConfig, err := ClientTLSConfig(tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{
+		Timeout:   seconds(connection.DialTimeout),
+		KeepAlive: seconds(connection.KeepAlive),
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   seconds(connection.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: seconds(connection.ResponseHeaderTimeout),
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       seconds(connection.IdleConnTimeout),
+		MaxIdleConns:          connection.MaxIdleConns,
+		MaxIdleConnsPerHost:   connection.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       connection.MaxConnsPerHost,
+	}
+	actual, _ := clientTransports.LoadOrStore(key, transport)
+	return actual.(*http.Transport), nil
+}
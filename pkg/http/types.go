@@ -1,31 +1,214 @@
 package http
 
+import (
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
 type ServerOptions struct {
 	URL           string
 	Host          string
 	Port          int
+	GRPCPort      int
 	AccessControl AccessControlOptions
 	RateLimiter   RateLimiterOptions
+	Cors          CorsOptions
+	TLS           TLSOptions
+	Prometheus    PrometheusOptions
+	Compression   CompressionOptions
+	Limits        LimitOptions
+	Replay        ReplayOptions
+	Proxy         ProxyOptions
+	Connection    ConnectionOptions
+	Docs          DocsOptions
+}
+
+// the swagger ui at /docs is served from our own origin rather than
+// a cdn so nobody else gets to run scripts on the page
+type DocsOptions struct {
+	// a directory with the swagger-ui-dist package's files, /docs is off without one
+	AssetsDir string
+}
+
+// how the server shares connections between requests, the providers and
+// creators keep theirs open for hours so this is tuned for many idle ones
+type ConnectionOptions struct {
+	// negotiated with ALPN over tls, clients without it keep using HTTP/1.1
+	HTTP2 bool
+	// also take HTTP/2 without tls from clients that know to send it, for
+	// when a proxy in front of us ends the tls
+	HTTP2Cleartext bool
+	// requests each HTTP/2 connection can have in flight at once
+	MaxConcurrentStreams int
+	// seconds an HTTP/2 connection can be quiet before we ping it, 0 means no pings
+	HTTP2PingInterval int
+	// seconds to wait for the ping to be answered before closing the connection
+	HTTP2PingTimeout int
+	// turning this off closes each HTTP/1.1 connection after one request
+	KeepAlive bool
+	// seconds between tcp keep-alive probes on idle connections, 0 means no probes
+	TCPKeepAlive int
+}
+
+// the load balancers and proxies in front of the server, their
+// headers are only believed when the request comes from one of them
+type ProxyOptions struct {
+	// cidr ranges or single ips
+	TrustedProxies []string
+	// checked in order, X-Forwarded-For has the proxies we trust skipped
+	TrustedHeaders []string
 }
 
 type AccessControlOptions struct {
 	ValidationTokenSecret     string
 	ValidationTokenExpiration int
 	ValidationTokenKid        string
+	// the addresses that can manage api keys
+	Admins []string
+	// make GET requests send an api key or a wallet signature
+	RequireReadAuth bool
+}
+
+// signed requests carry a nonce and a timestamp so one that is captured
+// cannot be sent again, nonces are remembered for as long as the
+// timestamp would still be inside the clock skew window
+type ReplayOptions struct {
+	Enabled bool
+	// seconds a request timestamp can be off from our clock either way
+	ClockSkew int
+	// the most nonces we remember, signed requests are turned away once it is full
+	MaxNonces int
 }
 
 type ValidationToken struct {
 	JWT string
 }
 
+// which browser origins can call the api and what they can send
+type CorsOptions struct {
+	// "*" allows every origin, a * inside one e.g. https://*.lilypad.tech matches subdomains
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// seconds a browser can cache a preflight response for
+	MaxAge int
+}
+
+// serve https with either a cert and key from disk or certificates
+// that autocert gets from let's encrypt for the hosts listed
+type TLSOptions struct {
+	CertFile         string
+	KeyFile          string
+	AutocertHosts    []string
+	AutocertCacheDir string
+	AutocertEmail    string
+	// verify client certificates against this CA, the wallet signature
+	// checks still apply to clients that send a certificate
+	ClientCAFile string
+	// turn client certificates from optional into required
+	RequireClientCert bool
+}
+
+// the /metrics endpoint for prometheus to scrape
+type PrometheusOptions struct {
+	Enabled bool
+	// when set a scrape has to send it as a bearer token
+	Token string
+}
+
+// gzip or deflate for json responses
+type CompressionOptions struct {
+	Enabled bool
+	// responses smaller than this many bytes are sent as they are
+	MinSize int
+}
+
+// caps on requests so oversized bodies or slow clients cannot
+// use up the memory or connections of the server
+type LimitOptions struct {
+	// bytes, the result file uploads have their own limit
+	MaxBodySize uint64
+	// bytes for each result file upload, 0 means no limit
+	MaxUploadSize uint64
+	// the timeouts are in seconds
+	ReadTimeout       int
+	ReadHeaderTimeout int
+	WriteTimeout      int
+	IdleTimeout       int
+}
+
 type RateLimiterOptions struct {
 	RequestLimit int
 	WindowLength int
+	// limits for the reads, writes and offers route groups
+	// that replace the request limit for the routes in them
+	GroupLimits map[string]int64
+	// count signed requests against the signer as well as the ip
+	PerAddress bool
+	// limits for single addresses that replace the other limits for
+	// the signer's count, the ip's count keeps its own limit
+	AddressLimits map[string]int64
 }
 
 type ClientOptions struct {
-	URL           string
-	PrivateKey    string
+	URL        string
+	PrivateKey string
+	// signs the requests instead of PrivateKey when it is set, for keys
+	// held by a remote signer
+	Signer        web3.Signer
 	PublicAddress string
+	// offers and results are sent with an EIP-712 signature for this domain,
+	// when it is unset only the request itself is signed
+	SigningDomain *apitypes.TypedDataDomain
 	Type          string
+	// the api version to call, empty means v1
+	APIVersion string
+	TLS        ClientTLSOptions
+	Retry      ClientRetryOptions
+	Connection ClientConnectionOptions
+	// sent as X-Request-Id so the call can be followed through the
+	// server logs, a new id is made for each call when this is empty
+	RequestID string
+}
+
+// how the client retries failed calls and when it stops calling a host
+// that keeps failing, the zero value means DefaultClientRetryOptions
+type ClientRetryOptions struct {
+	// retries after the first attempt, only calls that are safe to repeat are retried
+	MaxRetries int
+	// milliseconds, the wait doubles from the min up to the max with jitter
+	MinBackoff int
+	MaxBackoff int
+	// failures in a row that open the circuit for a host, 0 turns the breaker off
+	BreakerFailures int
+	// seconds an open circuit waits before letting a trial call through
+	BreakerCooldown int
+}
+
+// timeouts and pool sizes for the connections a client makes, timeouts are
+// seconds and 0 means no limit, the zero value means DefaultClientConnectionOptions
+type ClientConnectionOptions struct {
+	DialTimeout         int
+	TLSHandshakeTimeout int
+	// how long to wait for the response headers once the request is sent
+	ResponseHeaderTimeout int
+	// each attempt including reading the body, retries get their own
+	RequestTimeout int
+	// how often idle connections are probed to keep them open
+	KeepAlive       int
+	IdleConnTimeout int
+	MaxIdleConns    int
+	// idle connections kept open to each host
+	MaxIdleConnsPerHost int
+	// 0 means no limit
+	MaxConnsPerHost int
+}
+
+// the certificate a client presents to servers that use mutual tls
+// and the CA to trust the server with when it is not a public one
+type ClientTLSOptions struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
 }
@@ -2,7 +2,7 @@ package http
 
 import (
 	"bytes"
-	"crypto/ecdsa"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -12,10 +12,17 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+	"github.com/lilypad-tech/lilypad/pkg/signatures"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/rs/cors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -26,6 +33,9 @@ const X_LILYPAD_USER_HEADER = "X-Lilypad-User"
 // this is the signature of the message
 const X_LILYPAD_SIGNATURE_HEADER = "X-Lilypad-Signature"
 
+// the EIP-712 signature of the offer or result in the body
+const X_LILYPAD_TYPED_SIGNATURE_HEADER = "X-Lilypad-Typed-Signature"
+
 // the version run by the client or service
 const X_LILYPAD_VERSION_HEADER = "X-Lilypad-Version"
 
@@ -33,7 +43,7 @@ const X_LILYPAD_VERSION_HEADER = "X-Lilypad-Version"
 const CONTEXT_ADDRESS = "address"
 
 // the sub path any API's are served over
-const API_SUB_PATH = "/api/v1"
+const API_SUB_PATH = "/api/" + API_VERSION_1
 
 // the sub path the websocket server is mounted on
 const WEBSOCKET_SUB_PATH = "/ws"
@@ -41,16 +51,32 @@ const WEBSOCKET_SUB_PATH = "/ws"
 type HTTPError struct {
 	Message    string
 	StatusCode int
+	// empty means the generic code for the status
+	Code    apierrors.Code
+	Details map[string]any
+	// set on the client side from the error response
+	RequestID string
 }
 
 type AuthUser struct {
 	Address string `json:"address"`
+	// random for every request and the millisecond time it was signed,
+	// the server uses them to turn away a signed request sent twice
+	Nonce     string `json:"nonce,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
 }
 
 func (e HTTPError) Error() string {
 	return e.Message
 }
 
+func (e HTTPError) ErrorCode() apierrors.Code {
+	if e.Code != "" {
+		return e.Code
+	}
+	return apierrors.ForStatus(e.StatusCode)
+}
+
 func getWsURL(url string) string {
 	// replace http(s) with ws(s)
 	// e.g. return strings.Replace(s, old, new, n)
@@ -59,34 +85,19 @@ func getWsURL(url string) string {
 	return url
 }
 
-// we assume both userPayload and signature are base64 encoded
-func decodeUserAddress(userPayload string, signature string) (string, error) {
-	decodedUserPayload, err := base64.StdEncoding.DecodeString(userPayload)
-	if err != nil {
-		return "", err
-	}
-	decodedSignature, err := base64.StdEncoding.DecodeString(signature)
-	if err != nil {
-		return "", err
-	}
-	address, err := web3.GetAddressFromSignedMessage(decodedUserPayload, decodedSignature)
-	if err != nil {
-		return "", err
-	}
-	return address.String(), nil
-}
-
 // returns userPayload and signature as strings ready to be written into request headers
 // we encode these both as base64 so they can be included in http headers
-func encodeUserAddress(privateKey *ecdsa.PrivateKey, address string) (string, string, error) {
+func encodeUserAddress(ctx context.Context, signer web3.Signer, address string) (string, string, error) {
 	user := AuthUser{
-		Address: address,
+		Address:   address,
+		Nonce:     uuid.New().String(),
+		Timestamp: time.Now().UnixMilli(),
 	}
 	userBytes, err := json.Marshal(user)
 	if err != nil {
 		return "", "", err
 	}
-	userSignature, err := web3.SignMessage(privateKey, userBytes)
+	userSignature, err := signer.Sign(ctx, userBytes)
 	if err != nil {
 		return "", "", err
 	}
@@ -96,16 +107,21 @@ func encodeUserAddress(privateKey *ecdsa.PrivateKey, address string) (string, st
 
 func AddHeaders(
 	req *retryablehttp.Request,
-	privateKey *ecdsa.PrivateKey,
+	signer web3.Signer,
 	address string,
 ) error {
-	userPayload, userSignature, err := encodeUserAddress(privateKey, address)
+	return setSignatureHeaders(req.Context(), req.Header, signer, address)
+}
+
+// every call gets a new nonce so this is run again before each retry
+func setSignatureHeaders(ctx context.Context, header http.Header, signer web3.Signer, address string) error {
+	userPayload, userSignature, err := encodeUserAddress(ctx, signer, address)
 	if err != nil {
 		return err
 	}
-	req.Header.Add(X_LILYPAD_USER_HEADER, userPayload)
-	req.Header.Add(X_LILYPAD_SIGNATURE_HEADER, userSignature)
-	req.Header.Add(X_LILYPAD_VERSION_HEADER, system.Version)
+	header.Set(X_LILYPAD_USER_HEADER, userPayload)
+	header.Set(X_LILYPAD_SIGNATURE_HEADER, userSignature)
+	header.Set(X_LILYPAD_VERSION_HEADER, system.Version)
 	return nil
 }
 
@@ -114,17 +130,49 @@ func AddHeaders(
 // The "X-Lilypad-User" header contains the address.
 // The "X-Lilypad-Signature" header contains the signature.
 // We use the signature to verify that the message was signed by the private key.
+// Smart contract wallets are checked through EIP-1271 when the service
+// has set a signatures.Default verifier that can reach the chain.
 func CheckSignature(req *http.Request) (string, error) {
-	userHeader := req.Header.Get(X_LILYPAD_USER_HEADER)
+	return checkUserSignature(req.Context(), req.Header.Get(X_LILYPAD_USER_HEADER), req.Header.Get(X_LILYPAD_SIGNATURE_HEADER))
+}
+
+// the typed signature sent with the request, nil when the client did not
+// send one because it only signs the request
+func TypedSignature(req *http.Request) ([]byte, error) {
+	return decodeTypedSignature(req.Header.Get(X_LILYPAD_TYPED_SIGNATURE_HEADER))
+}
+
+func decodeTypedSignature(header string) ([]byte, error) {
+	if header == "" {
+		return nil, nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return nil, HTTPError{
+			Message:    "typed signature is not base64",
+			StatusCode: http.StatusUnauthorized,
+		}
+	}
+	return signature, nil
+}
+
+func checkUserSignature(ctx context.Context, userHeader string, userSignature string) (string, error) {
+	authUser, err := verifyUserSignature(ctx, userHeader, userSignature)
+	if err != nil {
+		return "", err
+	}
+	return authUser.Address, nil
+}
+
+func verifySignatureHeaders(ctx context.Context, userHeader string, userSignature string) (AuthUser, error) {
 	if userHeader == "" {
-		return "", HTTPError{
+		return AuthUser{}, HTTPError{
 			Message:    "missing user header",
 			StatusCode: http.StatusUnauthorized,
 		}
 	}
-	userSignature := req.Header.Get(X_LILYPAD_SIGNATURE_HEADER)
 	if userSignature == "" {
-		return "", HTTPError{
+		return AuthUser{}, HTTPError{
 			Message:    "missing signature header",
 			StatusCode: http.StatusUnauthorized,
 		}
@@ -134,7 +182,7 @@ func CheckSignature(req *http.Request) (string, error) {
 	// let's remember this is in base64 format
 	decodedUserHeader, err := base64.StdEncoding.DecodeString(userHeader)
 	if err != nil {
-		return "", HTTPError{
+		return AuthUser{}, HTTPError{
 			Message:    fmt.Sprintf("invalid user header %s", err.Error()),
 			StatusCode: http.StatusUnauthorized,
 		}
@@ -143,28 +191,43 @@ func CheckSignature(req *http.Request) (string, error) {
 	var authUser AuthUser
 	err = json.Unmarshal(decodedUserHeader, &authUser)
 	if err != nil {
-		return "", HTTPError{
+		return AuthUser{}, HTTPError{
 			Message:    fmt.Sprintf("invalid user header %s", err.Error()),
 			StatusCode: http.StatusUnauthorized,
 		}
 	}
 
-	signatureAddress, err := decodeUserAddress(userHeader, userSignature)
+	decodedSignature, err := base64.StdEncoding.DecodeString(userSignature)
 	if err != nil {
-		return "", HTTPError{
-			Message:    fmt.Sprintf("invalid user header or signature %s", err.Error()),
+		return AuthUser{}, HTTPError{
+			Message:    fmt.Sprintf("invalid signature header %s", err.Error()),
+			StatusCode: http.StatusUnauthorized,
+		}
+	}
+	if !common.IsHexAddress(authUser.Address) {
+		return AuthUser{}, HTTPError{
+			Message:    "invalid user header address",
 			StatusCode: http.StatusUnauthorized,
 		}
 	}
 
-	if signatureAddress != authUser.Address {
-		return "", HTTPError{
+	err = signatures.Default().VerifyMessage(ctx, common.HexToAddress(authUser.Address), decodedUserHeader, decodedSignature)
+	if errors.Is(err, signatures.ErrInvalidSignature) {
+		return AuthUser{}, HTTPError{
 			Message:    "invalid signature",
 			StatusCode: http.StatusUnauthorized,
 		}
 	}
+	if err != nil {
+		return AuthUser{}, HTTPError{
+			Message:    fmt.Sprintf("error checking signature %s", err.Error()),
+			StatusCode: http.StatusServiceUnavailable,
+		}
+	}
+	// the handlers compare addresses in their checksummed form
+	authUser.Address = common.HexToAddress(authUser.Address).String()
 
-	return signatureAddress, nil
+	return authUser, nil
 }
 
 func GetVersionFromHeaders(req *http.Request) (string, error) {
@@ -175,15 +238,35 @@ func GetVersionFromHeaders(req *http.Request) (string, error) {
 	return versionHeader, nil
 }
 
-func CorsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
-		res.Header().Set("Access-Control-Allow-Origin", "*")
-		next.ServeHTTP(res, req)
-	})
+// the headers the api sets that browser clients need to be able to read
+var corsExposedHeaders = []string{
+	X_LILYPAD_API_VERSION_HEADER,
+	X_REQUEST_ID_HEADER,
+	IDEMPOTENT_REPLAYED_HEADER,
+	"Deprecation",
+	"X-Total-Count",
+	"X-Next-Cursor",
+}
+
+// wraps the whole router rather than being router middleware because
+// preflight OPTIONS requests do not match any of the routes
+func CorsHandler(options CorsOptions, handler http.Handler) http.Handler {
+	return cors.New(cors.Options{
+		AllowedOrigins:   options.AllowedOrigins,
+		AllowedMethods:   options.AllowedMethods,
+		AllowedHeaders:   options.AllowedHeaders,
+		ExposedHeaders:   corsExposedHeaders,
+		AllowCredentials: options.AllowCredentials,
+		MaxAge:           options.MaxAge,
+	}).Handler(handler)
 }
 
 func URL(options ClientOptions, path string) string {
-	return fmt.Sprintf("%s%s%s", options.URL, API_SUB_PATH, path)
+	version := options.APIVersion
+	if version == "" {
+		version = API_VERSION_1
+	}
+	return fmt.Sprintf("%s%s%s", options.URL, APIPath(version), path)
 }
 
 func WebsocketURL(options ClientOptions, path string) string {
@@ -193,6 +276,13 @@ func WebsocketURL(options ClientOptions, path string) string {
 type httpGetWrapper[ResultType any] func(res http.ResponseWriter, req *http.Request) (ResultType, error)
 type httpPostWrapper[RequestType any, ResultType any] func(data RequestType, res http.ResponseWriter, req *http.Request) (ResultType, error)
 
+// stop a handler reading more than limit bytes of the body, 0 means no limit
+func LimitBody(res http.ResponseWriter, req *http.Request, limit uint64) {
+	if limit > 0 {
+		req.Body = http.MaxBytesReader(res, req.Body, int64(limit))
+	}
+}
+
 func ReadBody[T any](req *http.Request) (T, error) {
 	var data T
 	err := json.NewDecoder(req.Body).Decode(&data)
@@ -208,27 +298,32 @@ func GetHandler[T any](handler httpGetWrapper[T]) func(res http.ResponseWriter,
 	ret := func(res http.ResponseWriter, req *http.Request) {
 		data, err := handler(res, req)
 		if err != nil {
-			log.Error().
+			RequestLogger(req).Error().
 				Str("method GET", req.URL.String()).
 				Err(err).
 				Msgf("")
-			httpError, ok := err.(HTTPError)
-			if ok {
-				http.Error(res, httpError.Error(), httpError.StatusCode)
-			} else {
-				http.Error(res, err.Error(), http.StatusInternalServerError)
-			}
+			WriteError(res, req, err)
 			return
 		} else {
 			// get is trace because it does not mutate
-			log.Trace().
+			RequestLogger(req).Trace().
 				Str("method GET", req.URL.String()).
 				Str("res", fmt.Sprintf("%+v", data)).
 				Msgf("")
-			err = json.NewEncoder(res).Encode(data)
+			var body any = data
+			if fields := req.URL.Query().Get(FIELDS_QUERY_PARAM); fields != "" {
+				body, err = SelectFields(data, fields)
+				if err != nil {
+					RequestLogger(req).Error().Msgf("error selecting fields: %s", err.Error())
+					WriteError(res, req, err)
+					return
+				}
+			}
+			res.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(res).Encode(body)
 			if err != nil {
-				log.Ctx(req.Context()).Error().Msgf("error for json encoding: %s", err.Error())
-				http.Error(res, err.Error(), http.StatusInternalServerError)
+				RequestLogger(req).Error().Msgf("error for json encoding: %s", err.Error())
+				WriteError(res, req, err)
 				return
 			}
 		}
@@ -240,33 +335,34 @@ func PostHandler[RequestType any, ResultType any](handler httpPostWrapper[Reques
 	ret := func(res http.ResponseWriter, req *http.Request) {
 		requestBody, err := ReadBody[RequestType](req)
 		if err != nil {
-			http.Error(res, fmt.Sprintf("Error parsing request body"), http.StatusBadRequest)
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				WriteErrorMessage(res, req, fmt.Sprintf("request body is larger than %d bytes", maxBytesError.Limit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			WriteErrorMessage(res, req, fmt.Sprintf("Error parsing request body"), http.StatusBadRequest)
 			return
 		}
 		data, err := handler(requestBody, res, req)
 		if err != nil {
-			log.Error().
+			RequestLogger(req).Error().
 				Str("method POST", req.URL.String()).
 				Err(err).
 				Msgf("")
-			httpError, ok := err.(HTTPError)
-			if ok {
-				http.Error(res, httpError.Error(), httpError.StatusCode)
-			} else {
-				http.Error(res, err.Error(), http.StatusInternalServerError)
-			}
+			WriteError(res, req, err)
 			return
 		} else {
 			// post is debug because it does mutate
-			log.Debug().
+			RequestLogger(req).Debug().
 				Str("method POST", req.URL.String()).
 				Str("req", fmt.Sprintf("%+v", requestBody)).
 				Str("res", fmt.Sprintf("%+v", data)).
 				Msgf("")
+			res.Header().Set("Content-Type", "application/json")
 			err = json.NewEncoder(res).Encode(data)
 			if err != nil {
-				log.Ctx(req.Context()).Error().Msgf("error for json encoding: %s", err.Error())
-				http.Error(res, err.Error(), http.StatusInternalServerError)
+				RequestLogger(req).Error().Msgf("error for json encoding: %s", err.Error())
+				WriteError(res, req, err)
 				return
 			}
 		}
@@ -302,7 +398,36 @@ func GetRequestBuffer(
 	path string,
 	queryParams map[string]string,
 ) (*bytes.Buffer, error) {
-	client := newRetryClient()
+	return requestBuffer(options, "GET", path, queryParams)
+}
+
+// a delete has no body and is safe to retry like a get
+func DeleteRequest[ResultType any](
+	options ClientOptions,
+	path string,
+) (ResultType, error) {
+	var result ResultType
+	buf, err := requestBuffer(options, "DELETE", path, nil)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(buf.Bytes(), &result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func requestBuffer(
+	options ClientOptions,
+	method string,
+	path string,
+	queryParams map[string]string,
+) (*bytes.Buffer, error) {
+	client, err := newRetryClient(options, true)
+	if err != nil {
+		return nil, err
+	}
 
 	parsedURL, err := url.Parse(URL(options, path))
 	if err != nil {
@@ -315,12 +440,22 @@ func GetRequestBuffer(
 	}
 	parsedURL.RawQuery = urlValues.Encode()
 
-	req, err := retryablehttp.NewRequest("GET", parsedURL.String(), nil)
+	req, err := retryablehttp.NewRequest(method, parsedURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	privateKey, err := web3.ParsePrivateKey(options.PrivateKey)
-	AddHeaders(req, privateKey, web3.GetAddress(privateKey).String())
+	signer, err := clientSigner(options)
+	if err != nil {
+		return nil, err
+	}
+	err = AddHeaders(req, signer, signer.Address().String())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(X_REQUEST_ID_HEADER, requestIDFor(options))
+	client.PrepareRetry = func(retry *http.Request) error {
+		return setSignatureHeaders(retry.Context(), retry.Header, signer, signer.Address().String())
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -333,13 +468,32 @@ func GetRequestBuffer(
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, responseError(resp, buf.Bytes())
+	}
 
 	return &buf, nil
 }
 
+// the Signer when there is one, otherwise a signer for the PrivateKey
+func clientSigner(options ClientOptions) (web3.Signer, error) {
+	if options.Signer != nil {
+		return options.Signer, nil
+	}
+	privateKey, err := web3.ParsePrivateKey(options.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return web3.NewKeySigner(privateKey), nil
+}
+
 func GenericJSONPostClient(url string, json string) (*http.Response, error) {
 	data := []byte(json)
-	client := newRetryClient()
+	// nothing tells the receiver this is a repeat so it is only sent once
+	client, err := newRetryClient(ClientOptions{}, false)
+	if err != nil {
+		return nil, err
+	}
 	req, err := retryablehttp.NewRequest("POST", url, bytes.NewBuffer(data))
 	if err != nil {
 		fmt.Printf("error setting up the request: %s", err)
@@ -374,14 +528,52 @@ func PostRequest[RequestType any, ResultType any](
 	)
 }
 
+// posts the data with its EIP-712 signature so the server can tell the
+// signer attested to these exact fields and not just to making a request
+func PostTypedRequest[RequestType any, ResultType any](
+	options ClientOptions,
+	path string,
+	data RequestType,
+	typedData apitypes.TypedData,
+) (ResultType, error) {
+	var result ResultType
+	signer, err := clientSigner(options)
+	if err != nil {
+		return result, err
+	}
+	signature, err := web3.SignTypedData(context.Background(), signer, typedData)
+	if err != nil {
+		return result, err
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return result, err
+	}
+	header := http.Header{}
+	header.Set(X_LILYPAD_TYPED_SIGNATURE_HEADER, base64.StdEncoding.EncodeToString(signature))
+	return postRequestBuffer[ResultType](options, path, bytes.NewBuffer(dataBytes), header)
+}
+
 func PostRequestBuffer[ResultType any](
 	options ClientOptions,
 	path string,
 	data *bytes.Buffer,
+) (ResultType, error) {
+	return postRequestBuffer[ResultType](options, path, data, nil)
+}
+
+func postRequestBuffer[ResultType any](
+	options ClientOptions,
+	path string,
+	data *bytes.Buffer,
+	header http.Header,
 ) (ResultType, error) {
 	var result ResultType
-	client := newRetryClient()
-	privateKey, err := web3.ParsePrivateKey(options.PrivateKey)
+	client, err := newRetryClient(options, true)
+	if err != nil {
+		return result, err
+	}
+	signer, err := clientSigner(options)
 	if err != nil {
 		return result, err
 	}
@@ -389,7 +581,20 @@ func PostRequestBuffer[ResultType any](
 	if err != nil {
 		return result, err
 	}
-	AddHeaders(req, privateKey, web3.GetAddress(privateKey).String())
+	err = AddHeaders(req, signer, signer.Address().String())
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set(X_REQUEST_ID_HEADER, requestIDFor(options))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	client.PrepareRetry = func(retry *http.Request) error {
+		return setSignatureHeaders(retry.Context(), retry.Header, signer, signer.Address().String())
+	}
+	// the retry client sends this request again when the connection drops,
+	// the key lets the server spot a retry of a POST it already handled
+	req.Header.Set(IDEMPOTENCY_KEY_HEADER, uuid.New().String())
 	resp, err := client.Do(req)
 	if err != nil {
 		return result, err
@@ -401,6 +606,9 @@ func PostRequestBuffer[ResultType any](
 		log.Debug().Msgf("[debug] error while reading. response body: %s", body)
 		return result, err
 	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return result, responseError(resp, body)
+	}
 
 	// parse body as json into result
 	err = json.Unmarshal(body, &result)
@@ -412,34 +620,63 @@ func PostRequestBuffer[ResultType any](
 	return result, nil
 }
 
-func newRetryClient() *retryablehttp.Client {
+// a retry client that presents the client certificate from the options,
+// retries with the backoff and breaker from options.Retry and gives up on
+// a slow server after the options.Connection timeouts
+func newRetryClient(options ClientOptions, idempotent bool) (*retryablehttp.Client, error) {
+	retry := options.Retry.withDefaults()
+	connection := options.Connection.withDefaults()
+	transport, err := clientTransport(connection, options.TLS)
+	if err != nil {
+		return nil, err
+	}
 	retryClient := retryablehttp.NewClient()
-	retryClient.RetryMax = 10
+	retryClient.HTTPClient = &http.Client{
+		Transport: transport,
+		Timeout:   seconds(connection.RequestTimeout),
+	}
+	if retry.BreakerFailures > 0 {
+		retryClient.HTTPClient.Transport = &circuitBreakerTransport{
+			next:    transport,
+			options: retry,
+		}
+	}
+	retryClient.RetryMax = retry.MaxRetries
+	retryClient.RetryWaitMin = time.Duration(retry.MinBackoff) * time.Millisecond
+	retryClient.RetryWaitMax = time.Duration(retry.MaxBackoff) * time.Millisecond
+	retryClient.Backoff = jitteredBackoff
+	retryClient.CheckRetry = retryPolicy(idempotent)
 	retryClient.Logger = stdlog.New(io.Discard, "", stdlog.LstdFlags)
 	retryClient.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
 		switch {
 		case req.Method == "POST":
 			log.Debug().
 				Str(req.Method, req.URL.String()).
+				Str("request_id", req.Header.Get(X_REQUEST_ID_HEADER)).
 				Int("attempt", attempt).
 				Msgf("")
 		default:
 			// GET, PUT, DELETE, etc.
 			log.Trace().
 				Str(req.Method, req.URL.String()).
+				Str("request_id", req.Header.Get(X_REQUEST_ID_HEADER)).
 				Int("attempt", attempt).
 				Msgf("")
 		}
 	}
 	// Return custom error with response body
 	retryClient.ErrorHandler = func(resp *http.Response, err error, numTries int) (*http.Response, error) {
+		if resp == nil {
+			// the last attempt never got a response
+			return nil, fmt.Errorf("gave up after %d attempt(s): %w", numTries, err)
+		}
 		body, err := io.ReadAll(resp.Body)
 		defer resp.Body.Close()
 		if err != nil {
 			return nil, fmt.Errorf("%s %s gave up after %d attempt(s): %s", resp.Request.Method, resp.Request.URL, numTries, err)
 		}
 
-		return nil, fmt.Errorf("%s %s gave up after %d attempt(s): %s", resp.Request.Method, resp.Request.URL, numTries, string(body))
+		return nil, fmt.Errorf("%s %s gave up after %d attempt(s): %w", resp.Request.Method, resp.Request.URL, numTries, responseError(resp, body))
 	}
-	return retryClient
+	return retryClient, nil
 }
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// the version of the api a client wants when it calls a path without one
+// and the version that served a request in the response
+const X_LILYPAD_API_VERSION_HEADER = "X-Lilypad-Api-Version"
+
+const API_VERSION_1 = "v1"
+
+// the path the routes for an api version are served under
+func APIPath(version string) string {
+	return "/api/" + version
+}
+
+type APIVersion struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	// the version still works but clients should move to a newer one
+	Deprecated bool `json:"deprecated"`
+}
+
+// keeps the routes for each api version apart so a breaking change to the
+// request or response types can ship as a new version while old clients
+// keep calling the version they were built against
+type APIRouter struct {
+	router   *mux.Router
+	versions []APIVersion
+	routers  map[string]*mux.Router
+}
+
+func NewAPIRouter(router *mux.Router) *APIRouter {
+	return &APIRouter{
+		router:   router,
+		versions: []APIVersion{},
+		routers:  map[string]*mux.Router{},
+	}
+}
+
+// the subrouter for a version, versions should be added oldest first
+// so the last one added is what clients get when they do not ask
+func (api *APIRouter) Version(version string) *mux.Router {
+	if subrouter, ok := api.routers[version]; ok {
+		return subrouter
+	}
+	subrouter := api.router.PathPrefix(APIPath(version)).Subrouter()
+	subrouter.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Set(X_LILYPAD_API_VERSION_HEADER, version)
+			if api.isDeprecated(version) {
+				res.Header().Set("Deprecation", "true")
+			}
+			next.ServeHTTP(res, req)
+		})
+	})
+	api.versions = append(api.versions, APIVersion{
+		Version: version,
+		Path:    APIPath(version),
+	})
+	api.routers[version] = subrouter
+	return subrouter
+}
+
+func (api *APIRouter) Deprecate(version string) {
+	for i := range api.versions {
+		if api.versions[i].Version == version {
+			api.versions[i].Deprecated = true
+		}
+	}
+}
+
+func (api *APIRouter) isDeprecated(version string) bool {
+	for _, v := range api.versions {
+		if v.Version == version {
+			return v.Deprecated
+		}
+	}
+	return false
+}
+
+func (api *APIRouter) latest() string {
+	if len(api.versions) == 0 {
+		return ""
+	}
+	return api.versions[len(api.versions)-1].Version
+}
+
+// adds GET /api/versions and sends calls to /api/... without a version
+// to the one in the X-Lilypad-Api-Version header or the latest one
+// this has to be called after the versions have been added
+func (api *APIRouter) ServeVersions() {
+	api.router.HandleFunc("/api/versions", GetHandler(func(res http.ResponseWriter, req *http.Request) ([]APIVersion, error) {
+		res.Header().Set("Content-Type", "application/json")
+		return api.versions, nil
+	})).Methods("GET")
+
+	api.router.PathPrefix("/api/").HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		rest := strings.TrimPrefix(req.URL.Path, "/api")
+		first, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+		// the path already names a version so the route just does not exist
+		if _, ok := api.routers[first]; ok {
+			WriteErrorMessage(res, req, "no such route", http.StatusNotFound)
+			return
+		}
+		version := req.Header.Get(X_LILYPAD_API_VERSION_HEADER)
+		if version == "" {
+			version = api.latest()
+		}
+		if _, ok := api.routers[version]; !ok {
+			WriteErrorMessage(res, req, fmt.Sprintf("unsupported api version %q", version), http.StatusNotAcceptable)
+			return
+		}
+		req.URL.Path = APIPath(version) + rest
+		req.URL.RawPath = ""
+		api.router.ServeHTTP(res, req)
+	})
+}
@@ -0,0 +1,79 @@
+//go:build unit
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func versionedRouter() *mux.Router {
+	router := mux.NewRouter()
+	api := NewAPIRouter(router)
+	for _, version := range []string{"v1", "v2"} {
+		version := version
+		api.Version(version).HandleFunc("/ping", func(res http.ResponseWriter, req *http.Request) {
+			_, _ = res.Write([]byte(version))
+		}).Methods("GET")
+	}
+	api.Deprecate("v1")
+	api.ServeVersions()
+	return router
+}
+
+func callVersioned(router *mux.Router, path string, version string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if version != "" {
+		req.Header.Set(X_LILYPAD_API_VERSION_HEADER, version)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func TestAPIVersionHeader(t *testing.T) {
+	router := versionedRouter()
+
+	// no version anywhere gets the latest
+	res := callVersioned(router, "/api/ping", "")
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, "v2", res.Body.String())
+	require.Equal(t, "v2", res.Header().Get(X_LILYPAD_API_VERSION_HEADER))
+	require.Empty(t, res.Header().Get("Deprecation"))
+
+	res = callVersioned(router, "/api/ping", "v1")
+	require.Equal(t, "v1", res.Body.String())
+	require.Equal(t, "true", res.Header().Get("Deprecation"))
+
+	// the path wins over the header
+	res = callVersioned(router, "/api/v2/ping", "v1")
+	require.Equal(t, "v2", res.Body.String())
+}
+
+func TestAPIVersionRejected(t *testing.T) {
+	router := versionedRouter()
+
+	res := callVersioned(router, "/api/ping", "v9")
+	require.Equal(t, http.StatusNotAcceptable, res.Code)
+	require.Contains(t, res.Body.String(), `unsupported api version \"v9\"`)
+
+	// a version in the path with no such route is not retried as another version
+	res = callVersioned(router, "/api/v1/nothing", "")
+	require.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestAPIVersions(t *testing.T) {
+	res := callVersioned(versionedRouter(), "/api/versions", "")
+	require.Equal(t, http.StatusOK, res.Code)
+	versions := []APIVersion{}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &versions))
+	require.Equal(t, []APIVersion{
+		{Version: "v1", Path: "/api/v1", Deprecated: true},
+		{Version: "v2", Path: "/api/v2"},
+	}, versions)
+}
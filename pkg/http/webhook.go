@@ -0,0 +1,64 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// the headers on the events we POST to webhooks
+const X_LILYPAD_WEBHOOK_EVENT_HEADER = "X-Lilypad-Webhook-Event"
+const X_LILYPAD_WEBHOOK_ID_HEADER = "X-Lilypad-Webhook-Id"
+const X_LILYPAD_WEBHOOK_TIMESTAMP_HEADER = "X-Lilypad-Webhook-Timestamp"
+const X_LILYPAD_WEBHOOK_SIGNATURE_HEADER = "X-Lilypad-Webhook-Signature"
+
+const WEBHOOK_SECRET_PREFIX = "whsec_"
+
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return WEBHOOK_SECRET_PREFIX + hex.EncodeToString(secret), nil
+}
+
+// hex hmac sha256 of the millisecond timestamp, a dot and the body,
+// the timestamp is signed so an old event cannot be sent again later
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func AddWebhookHeaders(req *http.Request, secret string, event string, id string, body []byte) {
+	timestamp := time.Now().UnixMilli()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(X_LILYPAD_WEBHOOK_EVENT_HEADER, event)
+	req.Header.Set(X_LILYPAD_WEBHOOK_ID_HEADER, id)
+	req.Header.Set(X_LILYPAD_WEBHOOK_TIMESTAMP_HEADER, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(X_LILYPAD_WEBHOOK_SIGNATURE_HEADER, SignWebhook(secret, timestamp, body))
+}
+
+// for receivers written in go, checks the signature and that the
+// event was signed no more than maxAge ago
+func CheckWebhookSignature(req *http.Request, secret string, body []byte, maxAge time.Duration) error {
+	timestamp, err := strconv.ParseInt(req.Header.Get(X_LILYPAD_WEBHOOK_TIMESTAMP_HEADER), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header: %w", X_LILYPAD_WEBHOOK_TIMESTAMP_HEADER, err)
+	}
+	if time.Since(time.UnixMilli(timestamp)) > maxAge {
+		return fmt.Errorf("the webhook event was signed more than %s ago", maxAge)
+	}
+	expected := SignWebhook(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(req.Header.Get(X_LILYPAD_WEBHOOK_SIGNATURE_HEADER))) {
+		return fmt.Errorf("the webhook signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"id":"event"}`)
+	signature := SignWebhook("secret", 1000, body)
+	require.Len(t, signature, 64)
+	require.Equal(t, signature, SignWebhook("secret", 1000, body))
+	require.NotEqual(t, signature, SignWebhook("other", 1000, body))
+	require.NotEqual(t, signature, SignWebhook("secret", 1001, body))
+	require.NotEqual(t, signature, SignWebhook("secret", 1000, []byte(`{"id":"other"}`)))
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	secret, err := GenerateWebhookSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, WEBHOOK_SECRET_PREFIX))
+	other, err := GenerateWebhookSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestAddWebhookHeaders(t *testing.T) {
+	body := []byte(`{"id":"event"}`)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	AddWebhookHeaders(req, "secret", "deal.created", "event", body)
+	require.Equal(t, "deal.created", req.Header.Get(X_LILYPAD_WEBHOOK_EVENT_HEADER))
+	require.Equal(t, "event", req.Header.Get(X_LILYPAD_WEBHOOK_ID_HEADER))
+	require.NoError(t, CheckWebhookSignature(req, "secret", body, time.Minute))
+}
+
+func TestCheckWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"event"}`)
+	signed := func(timestamp int64, signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(X_LILYPAD_WEBHOOK_TIMESTAMP_HEADER, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(X_LILYPAD_WEBHOOK_SIGNATURE_HEADER, signature)
+		return req
+	}
+	now := time.Now().UnixMilli()
+
+	require.NoError(t, CheckWebhookSignature(signed(now, SignWebhook("secret", now, body)), "secret", body, time.Minute))
+
+	t.Run("wrong secret", func(t *testing.T) {
+		require.Error(t, CheckWebhookSignature(signed(now, SignWebhook("other", now, body)), "secret", body, time.Minute))
+	})
+	t.Run("changed body", func(t *testing.T) {
+		require.Error(t, CheckWebhookSignature(signed(now, SignWebhook("secret", now, body)), "secret", []byte(`{}`), time.Minute))
+	})
+	t.Run("too old", func(t *testing.T) {
+		old := time.Now().Add(-2 * time.Minute).UnixMilli()
+		require.Error(t, CheckWebhookSignature(signed(old, SignWebhook("secret", old, body)), "secret", body, time.Minute))
+	})
+	t.Run("missing timestamp", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(X_LILYPAD_WEBHOOK_SIGNATURE_HEADER, SignWebhook("secret", now, body))
+		require.Error(t, CheckWebhookSignature(req, "secret", body, time.Minute))
+	})
+}
@@ -2,6 +2,8 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
 	"sync"
 	"time"
 
@@ -12,12 +14,25 @@ import (
 // ConnectWebSocket establishes a new WebSocket connection
 func ConnectWebSocket(
 	url string,
+	tlsConfig *tls.Config,
+	connection ClientConnectionOptions,
 	ctx context.Context,
 ) chan []byte {
+	connection = connection.withDefaults()
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConfig
+	dialer.NetDialContext = (&net.Dialer{
+		Timeout:   seconds(connection.DialTimeout),
+		KeepAlive: seconds(connection.KeepAlive),
+	}).DialContext
+	// the upgrade response is the websocket's response headers
+	if connection.ResponseHeaderTimeout > 0 {
+		dialer.HandshakeTimeout = seconds(connection.TLSHandshakeTimeout + connection.ResponseHeaderTimeout)
+	}
 	connectFactory := func() *websocket.Conn {
 		for {
 			log.Debug().Msgf("WebSocket connection connecting: %s", url)
-			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			conn, _, err := dialer.Dial(url, nil)
 			if err != nil {
 				log.Error().Msgf("WebSocket connection failed: %s\nReconnecting in 2 seconds...", err)
 				time.Sleep(2 * time.Second)
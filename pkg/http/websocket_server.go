@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/gorilla/mux"
@@ -17,8 +18,9 @@ var upgrader = websocket.Upgrader{
 }
 
 type ConnectionWrapper struct {
-	conn *websocket.Conn
-	mu   sync.Mutex
+	conn   *websocket.Conn
+	params WSConnectionParams
+	mu     sync.Mutex
 }
 
 type WSConnectionParams struct {
@@ -26,13 +28,53 @@ type WSConnectionParams struct {
 	Type        string
 	CountryCode string
 	IP          string
+	// the extra addresses the client wants to hear about
+	// given as a comma separated address query param
+	Addresses []string
+}
+
+// the addresses a connection hears messages for
+// a connection that gives none hears nothing, the upgrade is not
+// signed so not saying who you are cannot be a way to hear everyone
+func (params WSConnectionParams) subscribedAddresses() []string {
+	addresses := append([]string{}, params.Addresses...)
+	if params.ID != "" {
+		addresses = append(addresses, params.ID)
+	}
+	return addresses
+}
+
+// a message for the websocket clients
+// only clients subscribed to one of the addresses will hear it
+// an empty list of addresses goes to every subscribed client
+type WSMessage struct {
+	Payload   []byte
+	Addresses []string
+}
+
+func (message WSMessage) isFor(params WSConnectionParams) bool {
+	subscribed := params.subscribedAddresses()
+	if len(subscribed) == 0 {
+		return false
+	}
+	if len(message.Addresses) == 0 {
+		return true
+	}
+	for _, address := range message.Addresses {
+		for _, subscribedAddress := range subscribed {
+			if strings.EqualFold(address, subscribedAddress) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // StartWebSocketServer starts a WebSocket server
 func StartWebSocketServer(
 	r *mux.Router,
 	path string,
-	messageChan chan []byte,
+	messageChan chan WSMessage,
 	ctx context.Context,
 	connectCB func(params WSConnectionParams),
 	disconnectCB func(params WSConnectionParams),
@@ -41,30 +83,32 @@ func StartWebSocketServer(
 
 	connections := map[*websocket.Conn]*ConnectionWrapper{}
 
-	addConnection := func(conn *websocket.Conn) {
+	addConnection := func(conn *websocket.Conn, params WSConnectionParams) {
 		mutex.Lock()
 		defer mutex.Unlock()
-		connections[conn] = &ConnectionWrapper{conn: conn}
+		connections[conn] = &ConnectionWrapper{conn: conn, params: params}
+		websocketConnections.Inc()
 	}
 
 	removeConnection := func(conn *websocket.Conn) {
 		mutex.Lock()
 		defer mutex.Unlock()
-		delete(connections, conn)
+		if _, ok := connections[conn]; ok {
+			delete(connections, conn)
+			websocketConnections.Dec()
+		}
 	}
 
 	// spawn a reader from the incoming message channel
-	// each message we get we fan out to all the currently connected websocket clients
-
-	// TODO: we should add some subscription channels here because right now we are
-	// splatting a lot of bytes down the write because everyone is hearing everything
+	// each message we get we fan out to the connected websocket clients
+	// that are subscribed to one of its addresses
 	go func() {
 		for {
 			select {
 			case message := <-messageChan:
 				log.Debug().
 					Str("action", fmt.Sprintf("ws WRITE: %d", len(connections))).
-					Str("payload", string(message)).
+					Str("payload", string(message.Payload)).
 					Msgf("")
 				func() {
 					// hold the mutex while we iterate over connections because
@@ -73,12 +117,15 @@ func StartWebSocketServer(
 					mutex.Lock()
 					defer mutex.Unlock()
 					for _, connWrapper := range connections {
+						if !message.isFor(connWrapper.params) {
+							continue
+						}
 						// wrap in a func so that we can defer the unlock so we can
 						// unlock the mutex on panics as well as errors
 						func() {
 							connWrapper.mu.Lock()
 							defer connWrapper.mu.Unlock()
-							if err := connWrapper.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+							if err := connWrapper.conn.WriteMessage(websocket.TextMessage, message.Payload); err != nil {
 								log.Error().Msgf("Error writing to websocket: %s", err.Error())
 								// don't stop reading from messageChan just because one write failed
 							}
@@ -106,9 +153,12 @@ func StartWebSocketServer(
 			CountryCode: r.Header.Get("Cf-Ipcountry"),
 			IP:          r.Header.Get("Cf-Connecting-Ip"),
 		}
+		if addresses := params.Get("address"); addresses != "" {
+			connParams.Addresses = strings.Split(addresses, ",")
+		}
 		defer conn.Close()
 		connectCB(connParams)
-		addConnection(conn)
+		addConnection(conn, connParams)
 
 		log.Debug().
 			Str("action", "⚪⚪⚪⚪⚪⚪⚪⚪⚪⚪ ws CONNECT").
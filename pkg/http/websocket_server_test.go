@@ -0,0 +1,33 @@
+//go:build unit
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWSMessageIsFor(t *testing.T) {
+	message := WSMessage{Addresses: []string{"0xAbC", "0xdef"}}
+	broadcast := WSMessage{}
+
+	tests := []struct {
+		name     string
+		message  WSMessage
+		params   WSConnectionParams
+		expected bool
+	}{
+		{"no subscription", message, WSConnectionParams{}, false},
+		{"no subscription and no addresses", broadcast, WSConnectionParams{}, false},
+		{"connected as a party", message, WSConnectionParams{ID: "0xabc"}, true},
+		{"subscribed to a party", message, WSConnectionParams{ID: "0x123", Addresses: []string{"0xDEF"}}, true},
+		{"not a party", message, WSConnectionParams{ID: "0x123", Addresses: []string{"0x456"}}, false},
+		{"no addresses", broadcast, WSConnectionParams{ID: "0x123"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.message.isFor(test.params))
+		})
+	}
+}
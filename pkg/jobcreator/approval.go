@@ -0,0 +1,330 @@
+package jobcreator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	corehttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+type ApprovalState string
+
+const (
+	ApprovalPending  ApprovalState = "Pending"
+	ApprovalApproved ApprovalState = "Approved"
+	ApprovalRejected ApprovalState = "Rejected"
+	ApprovalExpired  ApprovalState = "Expired"
+)
+
+var ErrJobOfferRejected = errors.New("job offer was rejected by an approver")
+var ErrJobOfferApprovalExpired = errors.New("job offer was not approved in time")
+
+// a job offer that is being held back from the solver
+// until one of the configured approvers makes a decision
+type PendingJobOffer struct {
+	ID        string        `json:"id"`
+	JobOffer  data.JobOffer `json:"job_offer"`
+	Reason    string        `json:"reason"`
+	State     ApprovalState `json:"state"`
+	CreatedAt int64         `json:"created_at"`
+	DecidedAt int64         `json:"decided_at,omitempty"`
+	DecidedBy string        `json:"decided_by,omitempty"`
+	Note      string        `json:"note,omitempty"`
+}
+
+type ApprovalAuditEntry struct {
+	Timestamp int64         `json:"timestamp"`
+	ID        string        `json:"id"`
+	Action    ApprovalState `json:"action"`
+	Actor     string        `json:"actor"`
+	Note      string        `json:"note,omitempty"`
+}
+
+type ApprovalDecision struct {
+	Note string `json:"note"`
+}
+
+type approvalQueue struct {
+	mtx     sync.Mutex
+	options JobCreatorApprovalOptions
+	pending map[string]*PendingJobOffer
+	waiters map[string]chan ApprovalState
+	audit   []ApprovalAuditEntry
+	log     *system.ServiceLogger
+	// tells the approvers about a held offer, the tests swap it out to
+	// know when an offer is waiting
+	notify func(PendingJobOffer)
+}
+
+func newApprovalQueue(options JobCreatorApprovalOptions) *approvalQueue {
+	queue := &approvalQueue{
+		options: options,
+		pending: map[string]*PendingJobOffer{},
+		waiters: map[string]chan ApprovalState{},
+		audit:   []ApprovalAuditEntry{},
+		log:     system.NewServiceLogger(system.JobCreatorService),
+	}
+	queue.notify = queue.postNotification
+	return queue
+}
+
+func (queue *approvalQueue) enabled() bool {
+	return queue.options.InstructionPriceThreshold > 0 || queue.options.PaymentCollateralThreshold > 0
+}
+
+// work out if this offer is above any of the thresholds
+// if it is we return the reason it needs approval
+func (queue *approvalQueue) requiresApproval(offer data.JobOffer) (bool, string) {
+	if queue.options.InstructionPriceThreshold > 0 && offer.Pricing.InstructionPrice > queue.options.InstructionPriceThreshold {
+		return true, fmt.Sprintf("instruction price %d exceeds threshold %d", offer.Pricing.InstructionPrice, queue.options.InstructionPriceThreshold)
+	}
+	if queue.options.PaymentCollateralThreshold > 0 && offer.Pricing.PaymentCollateral > queue.options.PaymentCollateralThreshold {
+		return true, fmt.Sprintf("payment collateral %d exceeds threshold %d", offer.Pricing.PaymentCollateral, queue.options.PaymentCollateralThreshold)
+	}
+	return false, ""
+}
+
+func (queue *approvalQueue) isApprover(address string) bool {
+	for _, approver := range queue.options.Approvers {
+		if strings.EqualFold(approver, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// put the offer into the queue and block until it is approved or rejected,
+// the ctx ending or the ttl running out gives up on it
+func (queue *approvalQueue) hold(ctx context.Context, offer data.JobOffer, reason string) error {
+	pending := &PendingJobOffer{
+		ID:        uuid.New().String(),
+		JobOffer:  offer,
+		Reason:    reason,
+		State:     ApprovalPending,
+		CreatedAt: time.Now().Unix(),
+	}
+	waiter := make(chan ApprovalState, 1)
+
+	queue.mtx.Lock()
+	queue.pending[pending.ID] = pending
+	queue.waiters[pending.ID] = waiter
+	queue.recordLocked(pending.ID, ApprovalPending, offer.JobCreator, reason)
+	queue.mtx.Unlock()
+
+	queue.log.Info("job offer held for approval", fmt.Sprintf("%s: %s", pending.ID, reason))
+	queue.notify(*pending)
+
+	var expired <-chan time.Time
+	if queue.options.TTL > 0 {
+		timer := time.NewTimer(time.Duration(queue.options.TTL) * time.Second)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var err error
+	select {
+	case state := <-waiter:
+		if state == ApprovalRejected {
+			return ErrJobOfferRejected
+		}
+		return nil
+	case <-expired:
+		err = ErrJobOfferApprovalExpired
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+	if _, ok := queue.pending[pending.ID]; !ok {
+		// an approver decided as we gave up, their decision stands
+		if <-waiter == ApprovalRejected {
+			return ErrJobOfferRejected
+		}
+		return nil
+	}
+	delete(queue.pending, pending.ID)
+	delete(queue.waiters, pending.ID)
+	queue.recordLocked(pending.ID, ApprovalExpired, offer.JobCreator, err.Error())
+	queue.log.Info("job offer approval given up", fmt.Sprintf("%s: %s", pending.ID, err.Error()))
+	return err
+}
+
+func (queue *approvalQueue) decide(id string, approver string, state ApprovalState, note string) (PendingJobOffer, error) {
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+
+	// decided offers leave the queue, the audit log keeps them
+	pending, ok := queue.pending[id]
+	if !ok {
+		return PendingJobOffer{}, fmt.Errorf("pending job offer not found: %s", id)
+	}
+	delete(queue.pending, id)
+
+	pending.State = state
+	pending.DecidedAt = time.Now().Unix()
+	pending.DecidedBy = approver
+	pending.Note = note
+	queue.recordLocked(id, state, approver, note)
+
+	// the waiter is buffered so this never blocks
+	if waiter, ok := queue.waiters[id]; ok {
+		waiter <- state
+		delete(queue.waiters, id)
+	}
+
+	queue.log.Info("job offer approval decision", fmt.Sprintf("%s: %s by %s", id, state, approver))
+	return *pending, nil
+}
+
+func (queue *approvalQueue) list() []PendingJobOffer {
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+	offers := []PendingJobOffer{}
+	for _, pending := range queue.pending {
+		offers = append(offers, *pending)
+	}
+	return offers
+}
+
+func (queue *approvalQueue) auditLog() []ApprovalAuditEntry {
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+	return append([]ApprovalAuditEntry{}, queue.audit...)
+}
+
+func (queue *approvalQueue) recordLocked(id string, action ApprovalState, actor string, note string) {
+	queue.audit = append(queue.audit, ApprovalAuditEntry{
+		Timestamp: time.Now().Unix(),
+		ID:        id,
+		Action:    action,
+		Actor:     actor,
+		Note:      note,
+	})
+}
+
+// tell the approvers there is something waiting for them
+func (queue *approvalQueue) postNotification(pending PendingJobOffer) {
+	if queue.options.NotifyURL == "" {
+		return
+	}
+	go func() {
+		body, err := json.Marshal(pending)
+		if err != nil {
+			queue.log.Error("error encoding approval notification", err)
+			return
+		}
+		_, err = http.GenericJSONPostClient(queue.options.NotifyURL, string(body))
+		if err != nil {
+			queue.log.Error("error sending approval notification", err)
+		}
+	}()
+}
+
+/*
+ *
+ *
+ *
+
+ Approval API
+
+ *
+ *
+ *
+*/
+
+func (queue *approvalQueue) ListenAndServe(ctx context.Context) error {
+	router := mux.NewRouter()
+	subrouter := router.PathPrefix(http.API_SUB_PATH).Subrouter()
+
+	subrouter.HandleFunc("/approvals", http.GetHandler(queue.getApprovals)).Methods("GET")
+	subrouter.HandleFunc("/approvals/audit", http.GetHandler(queue.getAuditLog)).Methods("GET")
+	subrouter.HandleFunc("/approvals/{id}/approve", http.PostHandler(queue.approve)).Methods("POST")
+	subrouter.HandleFunc("/approvals/{id}/reject", http.PostHandler(queue.reject)).Methods("POST")
+
+	// the approvers sign with the current client so there are no old
+	// ones to wait for before turning replays away
+	replay := http.DefaultReplayOptions
+	replay.Enabled = true
+	srv := &corehttp.Server{
+		Addr:              fmt.Sprintf("%s:%d", queue.options.Host, queue.options.Port),
+		ReadHeaderTimeout: time.Minute,
+		Handler:           http.RequestIDMiddleware(http.SignatureMiddleware(http.NewReplayGuard(replay).Middleware(router))),
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErrors:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (queue *approvalQueue) checkApprover(req *corehttp.Request) (string, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		return "", err
+	}
+	if !queue.isApprover(signerAddress) {
+		return "", http.HTTPError{
+			Message:    fmt.Sprintf("%s is not an approver", signerAddress),
+			StatusCode: corehttp.StatusForbidden,
+		}
+	}
+	return signerAddress, nil
+}
+
+func (queue *approvalQueue) getApprovals(res corehttp.ResponseWriter, req *corehttp.Request) ([]PendingJobOffer, error) {
+	_, err := queue.checkApprover(req)
+	if err != nil {
+		return nil, err
+	}
+	return queue.list(), nil
+}
+
+func (queue *approvalQueue) getAuditLog(res corehttp.ResponseWriter, req *corehttp.Request) ([]ApprovalAuditEntry, error) {
+	_, err := queue.checkApprover(req)
+	if err != nil {
+		return nil, err
+	}
+	return queue.auditLog(), nil
+}
+
+func (queue *approvalQueue) approve(decision ApprovalDecision, res corehttp.ResponseWriter, req *corehttp.Request) (PendingJobOffer, error) {
+	return queue.handleDecision(decision, req, ApprovalApproved)
+}
+
+func (queue *approvalQueue) reject(decision ApprovalDecision, res corehttp.ResponseWriter, req *corehttp.Request) (PendingJobOffer, error) {
+	return queue.handleDecision(decision, req, ApprovalRejected)
+}
+
+func (queue *approvalQueue) handleDecision(decision ApprovalDecision, req *corehttp.Request, state ApprovalState) (PendingJobOffer, error) {
+	approver, err := queue.checkApprover(req)
+	if err != nil {
+		return PendingJobOffer{}, err
+	}
+	pending, err := queue.decide(mux.Vars(req)["id"], approver, state, decision.Note)
+	if err != nil {
+		return PendingJobOffer{}, http.HTTPError{
+			Message:    err.Error(),
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	return pending, nil
+}
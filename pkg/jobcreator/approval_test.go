@@ -0,0 +1,110 @@
+//go:build unit
+
+package jobcreator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+func TestApprovalQueue(t *testing.T) {
+	queue := newApprovalQueue(JobCreatorApprovalOptions{
+		InstructionPriceThreshold: 10,
+		Approvers:                 []string{"0xApprover"},
+	})
+
+	cheap := data.JobOffer{Pricing: data.DealPricing{InstructionPrice: 5}}
+	if held, _ := queue.requiresApproval(cheap); held {
+		t.Fatal("expected offer under the threshold to pass through")
+	}
+
+	expensive := data.JobOffer{Pricing: data.DealPricing{InstructionPrice: 50}}
+	held, reason := queue.requiresApproval(expensive)
+	if !held || reason == "" {
+		t.Fatal("expected offer over the threshold to be held")
+	}
+
+	tests := []struct {
+		name    string
+		state   ApprovalState
+		wantErr error
+	}{
+		{name: "approved offers are released", state: ApprovalApproved, wantErr: nil},
+		{name: "rejected offers are cancelled", state: ApprovalRejected, wantErr: ErrJobOfferRejected},
+	}
+
+	holds := make(chan string, 1)
+	queue.notify = func(pending PendingJobOffer) { holds <- pending.ID }
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() {
+				done <- queue.hold(context.Background(), expensive, reason)
+			}()
+			id := <-holds
+
+			if !queue.isApprover("0xapprover") {
+				t.Fatal("expected approver match to be case insensitive")
+			}
+			_, err := queue.decide(id, "0xApprover", tc.state, "")
+			if err != nil {
+				t.Fatalf("unexpected error deciding: %v", err)
+			}
+			if err := <-done; err != tc.wantErr {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			if len(queue.list()) != 0 || len(queue.waiters) != 0 {
+				t.Fatal("expected the decided offer to leave the queue")
+			}
+
+			_, err = queue.decide(id, "0xApprover", ApprovalApproved, "")
+			if err == nil {
+				t.Fatal("expected an error deciding twice")
+			}
+		})
+	}
+
+	// held, approved and rejected for each case
+	if got := len(queue.auditLog()); got != 4 {
+		t.Errorf("expected 4 audit entries, got %d", got)
+	}
+}
+
+func TestApprovalQueueGivesUp(t *testing.T) {
+	queue := newApprovalQueue(JobCreatorApprovalOptions{
+		InstructionPriceThreshold: 10,
+		Approvers:                 []string{"0xApprover"},
+		TTL:                       1,
+	})
+	held := make(chan string, 1)
+	queue.notify = func(pending PendingJobOffer) { held <- pending.ID }
+	expensive := data.JobOffer{Pricing: data.DealPricing{InstructionPrice: 50}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- queue.hold(ctx, expensive, "too expensive")
+	}()
+	id := <-held
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected the cancelled ctx to give up, got %v", err)
+	}
+	if _, err := queue.decide(id, "0xApprover", ApprovalApproved, ""); err == nil {
+		t.Fatal("expected an offer that was given up on to be gone")
+	}
+
+	go func() {
+		done <- queue.hold(context.Background(), expensive, "too expensive")
+	}()
+	<-held
+	if err := <-done; err != ErrJobOfferApprovalExpired {
+		t.Fatalf("expected the ttl to give up, got %v", err)
+	}
+	if len(queue.list()) != 0 || len(queue.waiters) != 0 {
+		t.Fatal("expected the expired offer to leave the queue")
+	}
+}
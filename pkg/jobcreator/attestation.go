@@ -0,0 +1,23 @@
+package jobcreator
+
+import (
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// the solver only checked that the quote is for the resource offer, we
+// check it was signed by genuine hardware before we agree or send secrets
+func (controller *JobCreatorController) checkAttestation(deal data.DealContainer) error {
+	requirement := deal.Deal.JobOffer.Attestation
+	if requirement == nil {
+		return nil
+	}
+	if controller.verifier == nil {
+		return fmt.Errorf("deal %s requires attestation but ATTESTATION_ROOTS is not set", deal.ID)
+	}
+	if err := controller.verifier.Verify(deal.Deal.ResourceOffer, requirement); err != nil {
+		return fmt.Errorf("resource provider %s failed attestation: %w", deal.Deal.ResourceOffer.ResourceProvider, err)
+	}
+	return nil
+}
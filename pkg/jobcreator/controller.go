@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math/rand"
 	"os"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/http"
 	"github.com/lilypad-tech/lilypad/pkg/metricsDashboard"
@@ -29,7 +32,11 @@ type JobCreatorController struct {
 	loop                  *system.ControlLoop
 	log                   *system.ServiceLogger
 	jobOfferSubscriptions []JobOfferSubscriber
+	approvals             *approvalQueue
 	tracer                trace.Tracer
+	// checks the quotes of attested resource providers, nil without
+	// ATTESTATION_ROOTS
+	verifier *attestation.Verifier
 }
 
 // the background "even if we have not heard of an event" loop
@@ -49,12 +56,17 @@ func NewJobCreatorController(
 		return nil, err
 	}
 
+	signingDomain := web3SDK.TypedDataDomain()
 	solverClient, err := solver.NewSolverClient(
 		http.ClientOptions{
 			URL:           solverUrl,
-			PrivateKey:    options.Web3.PrivateKey,
+			Signer:        web3SDK.Signer,
 			Type:          "JobCreator",
 			PublicAddress: web3SDK.GetAddress().String(),
+			SigningDomain: &signingDomain,
+			TLS:           options.ClientTLS,
+			Retry:         options.ClientRetry,
+			Connection:    options.ClientConnection,
 		})
 	if err != nil {
 		return nil, err
@@ -69,8 +81,15 @@ func NewJobCreatorController(
 		web3Events:            web3.NewEventChannels(),
 		log:                   system.NewServiceLogger(system.JobCreatorService),
 		jobOfferSubscriptions: []JobOfferSubscriber{},
+		approvals:             newApprovalQueue(options.Approval),
 		tracer:                tracer,
 	}
+	if options.Attestation.Roots != "" {
+		controller.verifier, err = attestation.NewVerifier(options.Attestation)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return controller, nil
 }
 
@@ -86,9 +105,28 @@ func NewJobCreatorController(
  *
 */
 
-func (controller *JobCreatorController) AddJobOffer(offer data.JobOffer) (data.JobOfferContainer, error) {
+func (controller *JobCreatorController) AddJobOffer(ctx context.Context, offer data.JobOffer) (data.JobOfferContainer, error) {
 	controller.log.Debug("add job offer", offer)
-	return controller.solverClient.AddJobOffer(offer)
+	// offers that don't carry their own mediation policy get ours
+	if offer.Mediation == nil {
+		offer.Mediation = &data.MediationPolicy{
+			AlwaysCheckFirst:       controller.options.Mediation.AlwaysCheckFirst,
+			CheckResultsPercentage: controller.options.Mediation.CheckResultsPercentage,
+		}
+	}
+	// offers above the spending thresholds wait here until an approver decides
+	if controller.approvals.enabled() {
+		if held, reason := controller.approvals.requiresApproval(offer); held {
+			err := controller.approvals.hold(ctx, offer, reason)
+			if err != nil {
+				return data.JobOfferContainer{}, err
+			}
+		}
+	}
+	// one id for the submission so it can be followed into the solver logs
+	requestID := uuid.New().String()
+	controller.log.Info("adding job offer with request id", requestID)
+	return controller.solverClient.WithRequestID(requestID).AddJobOffer(offer)
 }
 
 func (controller *JobCreatorController) SubscribeToJobOfferUpdates(sub JobOfferSubscriber) {
@@ -106,7 +144,7 @@ func (controller *JobCreatorController) SubscribeToJobOfferUpdates(sub JobOfferS
 *
 *
 */
-func (controller *JobCreatorController) subscribeToSolver() error {
+func (controller *JobCreatorController) subscribeToSolver(ctx context.Context) error {
 	controller.solverClient.SubscribeEvents(func(ev solver.SolverEvent) {
 		if ev.EventType == "DealStateUpdated" {
 			metricsDashboard.TrackDeal(metricsDashboard.DealPayload{
@@ -132,6 +170,13 @@ func (controller *JobCreatorController) subscribeToSolver() error {
 
 			// trigger the solver
 			controller.loop.Trigger()
+		case solver.DealStateUpdated, solver.ResultAdded:
+			// the solver only sends us events for our own deals
+			// so we can act on them straight away rather than wait to poll
+			if ev.Deal == nil || ev.Deal.JobCreator != controller.web3SDK.GetAddress().String() {
+				return
+			}
+			controller.loop.Trigger()
 		case solver.JobOfferStateUpdated:
 			if ev.JobOffer == nil {
 				controller.log.Error("solver event", fmt.Errorf("RP received nil job offer"))
@@ -141,11 +186,79 @@ func (controller *JobCreatorController) subscribeToSolver() error {
 			for _, sub := range controller.jobOfferSubscriptions {
 				go sub(*ev.JobOffer)
 			}
+		case solver.DealTimedOut:
+			// the solver has given up waiting on the deal so we claim the timeout
+			if ev.Deal == nil || ev.Deal.JobCreator != controller.web3SDK.GetAddress().String() {
+				return
+			}
+			go controller.timeoutDeal(ctx, *ev.Deal)
 		}
 	})
 	return nil
 }
 
+// call the on chain timeout that refunds us for a stale deal
+func (controller *JobCreatorController) timeoutDeal(ctx context.Context, deal data.DealContainer) {
+	txs := deal.Transactions.JobCreator
+	var err error
+	var txHash string
+	var action string
+	var payload data.DealTransactionsJobCreator
+	switch data.GetAgreementStateString(deal.State) {
+	case "TimeoutAgree":
+		// only the party that agreed has collateral to get back
+		if txs.Agree == "" || txs.TimeoutAgree != "" {
+			return
+		}
+		action = "timeout_agree"
+		txHash, err = controller.web3SDK.TimeoutAgree(ctx, deal.ID)
+		payload.TimeoutAgree = txHash
+	case "TimeoutSubmitResults":
+		if txs.TimeoutSubmitResult != "" {
+			return
+		}
+		action = "timeout_submit_result"
+		txHash, err = controller.web3SDK.TimeoutSubmitResult(ctx, deal.ID)
+		payload.TimeoutSubmitResult = txHash
+	case "TimeoutMediateResults":
+		if txs.TimeoutMediateResult != "" {
+			return
+		}
+		action = "timeout_mediate_result"
+		txHash, err = controller.web3SDK.TimeoutMediateResult(ctx, deal.ID)
+		payload.TimeoutMediateResult = txHash
+	default:
+		return
+	}
+	if err != nil {
+		controller.log.Error("error calling timeout tx for deal", err)
+		controller.reportAbandoned(deal.ID, action, err)
+		return
+	}
+	controller.log.Debug("timeout tx", txHash)
+	payload.Stuck = controller.web3SDK.StuckTransactions(action, txHash, nil)
+
+	_, err = controller.solverClient.UpdateTransactionsJobCreator(deal.ID, payload)
+	if err != nil {
+		controller.log.Error("error adding timeout tx hash for deal", err)
+	}
+}
+
+// a deal transaction that was given up on has no hash to record but the
+// deal should still show what happened to it
+func (controller *JobCreatorController) reportAbandoned(dealID string, action string, err error) {
+	stuck := controller.web3SDK.StuckTransactions(action, "", err)
+	if len(stuck) == 0 {
+		return
+	}
+	_, err = controller.solverClient.UpdateTransactionsJobCreator(dealID, data.DealTransactionsJobCreator{
+		Stuck: stuck,
+	})
+	if err != nil {
+		controller.log.Error("error adding stuck txs for deal", err)
+	}
+}
+
 func (controller *JobCreatorController) subscribeToWeb3() error {
 	controller.web3Events.Storage.SubscribeDealStateChange(func(ev storage.StorageDealStateChange) {
 		deal, err := controller.solverClient.GetDeal(ev.DealId)
@@ -165,7 +278,7 @@ func (controller *JobCreatorController) subscribeToWeb3() error {
 
 func (controller *JobCreatorController) Start(ctx context.Context, cm *system.CleanupManager) chan error {
 	errorChan := make(chan error, 1)
-	err := controller.subscribeToSolver()
+	err := controller.subscribeToSolver(ctx)
 	if err != nil {
 		errorChan <- err
 		return errorChan
@@ -188,6 +301,15 @@ func (controller *JobCreatorController) Start(ctx context.Context, cm *system.Cl
 		return errorChan
 	}
 
+	if controller.approvals.enabled() {
+		go func() {
+			err := controller.approvals.ListenAndServe(ctx)
+			if err != nil {
+				controller.log.Error("approval api stopped", err)
+			}
+		}()
+	}
+
 	controller.loop = system.NewControlLoop(
 		system.JobCreatorService,
 		ctx,
@@ -268,13 +390,38 @@ func (controller *JobCreatorController) agreeToDeals() error {
 		return nil
 	}
 
-	// map over the deals and agree to them
+	// a deal we cannot send the secrets for is tried again next time round
+	// and one that fails attestation is left to time out
+	readyDeals := []data.DealContainer{}
 	for _, dealContainer := range matchedDeals {
+		if err := controller.checkAttestation(dealContainer); err != nil {
+			controller.log.Error(fmt.Sprintf("not agreeing to deal %s", dealContainer.ID), err)
+			continue
+		}
+		if err := controller.sendSecrets(dealContainer); err != nil {
+			controller.log.Error("error sending secrets for deal", err)
+			continue
+		}
+		readyDeals = append(readyDeals, dealContainer)
+	}
+	matchedDeals = readyDeals
+	if len(matchedDeals) <= 0 {
+		return nil
+	}
+
+	// agree to the deals, batched into as few transactions as WEB3_TX_BATCH_SIZE allows
+	deals := make([]data.Deal, len(matchedDeals))
+	for i, dealContainer := range matchedDeals {
 		controller.log.Debug("agree", dealContainer)
-		txHash, err := controller.web3SDK.Agree(dealContainer.Deal)
+		deals[i] = dealContainer.Deal
+	}
+	for i, agreed := range controller.web3SDK.BatchAgree(deals) {
+		dealContainer := matchedDeals[i]
+		txHash, err := agreed.TxHash, agreed.Err
 		if err != nil {
 			// TODO: error handling - is it terminal or retryable?
 			controller.log.Error("error calling agree tx for deal", err)
+			controller.reportAbandoned(dealContainer.ID, "agree", err)
 			continue
 		}
 		controller.log.Debug("agree tx", txHash)
@@ -282,6 +429,7 @@ func (controller *JobCreatorController) agreeToDeals() error {
 		// we have agreed to the deal so we need to update the tx in the solver
 		_, err = controller.solverClient.UpdateTransactionsJobCreator(dealContainer.ID, data.DealTransactionsJobCreator{
 			Agree: txHash,
+			Stuck: controller.web3SDK.StuckTransactions("agree", txHash, nil),
 		})
 		if err != nil {
 			// TODO: error handling - is it terminal or retryable?
@@ -353,42 +501,82 @@ func (controller *JobCreatorController) downloadResult(dealContainer data.DealCo
 
 	controller.log.Debug("Downloaded results for job", solver.GetDownloadsFilePath(dealContainer.ID))
 
-	// TODO: activate the mediation check here
+	// work out if we should check or accept the results
+	check, reason, err := controller.shouldCheckResult(dealContainer)
+	if err != nil {
+		return err
+	}
+	controller.log.Debug("mediation decision", fmt.Sprintf("%s check=%t %s", dealContainer.ID, check, reason))
+
+	if check {
+		err = controller.checkResult(dealContainer)
+		if err != nil {
+			controller.log.Error("failed to check results", err)
+			return err
+		}
+		controller.log.Debug("Checked results for job", dealContainer.ID)
+		return nil
+	}
+
 	err = controller.acceptResult(dealContainer)
 	if err != nil {
 		controller.log.Error("failed to accept results", err)
 		return err
 	}
-
-	// work out if we should check or accept the results
-	// if controller.options.Mediation.CheckResultsPercentage >= rand.Intn(100) {
-	// 	err = controller.checkResult(dealContainer)
-
-	// 	if err != nil {
-	// 		// TODO: error handling - is it terminal or retryable?
-	// 		controller.log.Error("error checking deal results", err)
-	// 		return nil
-	// 	}
-
-	// 	controller.log.Debug("Checked results for job", dealContainer.ID)
-	// } else {
-	// 	err = controller.acceptResult(dealContainer)
-
-	// 	if err != nil {
-	// 		// TODO: error handling - is it terminal or retryable?
-	// 		controller.log.Error("error accepting deal results", err)
-	// 		return nil
-	// 	}
-
-	// 	controller.log.Debug("Accepted results for job", dealContainer.ID)
-	// }
 	return nil
 }
 
+// decide whether to ask a mediator to check the results of this deal
+// the first N deals with a resource provider are always checked
+// and after that we check a percentage of them
+func (controller *JobCreatorController) shouldCheckResult(dealContainer data.DealContainer) (bool, string, error) {
+	policy := dealContainer.Deal.JobOffer.Mediation
+	if policy == nil {
+		policy = &data.MediationPolicy{
+			AlwaysCheckFirst:       controller.options.Mediation.AlwaysCheckFirst,
+			CheckResultsPercentage: controller.options.Mediation.CheckResultsPercentage,
+		}
+	}
+
+	if policy.AlwaysCheckFirst > 0 {
+		previousDeals, err := controller.solverClient.GetDealsWithFilter(
+			store.GetDealsQuery{
+				JobCreator:       controller.web3SDK.GetAddress().String(),
+				ResourceProvider: dealContainer.ResourceProvider,
+			},
+			func(deal data.DealContainer) bool {
+				return deal.ID != dealContainer.ID && hasJudgedResults(deal.State)
+			},
+		)
+		if err != nil {
+			return false, "", err
+		}
+		if len(previousDeals) < policy.AlwaysCheckFirst {
+			return true, fmt.Sprintf("deal %d of the first %d with this resource provider", len(previousDeals)+1, policy.AlwaysCheckFirst), nil
+		}
+	}
+
+	if policy.CheckResultsPercentage > rand.Intn(100) {
+		return true, fmt.Sprintf("selected by %d%% check chance", policy.CheckResultsPercentage), nil
+	}
+	return false, fmt.Sprintf("not selected by %d%% check chance", policy.CheckResultsPercentage), nil
+}
+
+// the deal's results were accepted, rejected or mediated, results that have
+// only been submitted could still be checked so they do not count yet
+func hasJudgedResults(state uint8) bool {
+	switch data.GetAgreementStateString(state) {
+	case "ResultsAccepted", "ResultsChecked", "MediationAccepted", "MediationRejected":
+		return true
+	}
+	return false
+}
+
 func (controller *JobCreatorController) acceptResult(deal data.DealContainer) error {
 	controller.log.Debug("Accepting results for job", deal.ID)
 	txHash, err := controller.web3SDK.AcceptResult(deal.ID)
 	if err != nil {
+		controller.reportAbandoned(deal.ID, "accept_result", err)
 		return fmt.Errorf("error calling accept result tx for deal: %s", err.Error())
 	}
 	controller.log.Debug("accept result tx", txHash)
@@ -396,6 +584,7 @@ func (controller *JobCreatorController) acceptResult(deal data.DealContainer) er
 	// we have agreed to the deal so we need to update the tx in the solver
 	_, err = controller.solverClient.UpdateTransactionsJobCreator(deal.ID, data.DealTransactionsJobCreator{
 		AcceptResult: txHash,
+		Stuck:        controller.web3SDK.StuckTransactions("accept_result", txHash, nil),
 	})
 	if err != nil {
 		return fmt.Errorf("error adding AcceptResult tx hash for deal: %s", err.Error())
@@ -407,6 +596,7 @@ func (controller *JobCreatorController) checkResult(deal data.DealContainer) err
 	controller.log.Debug("Checking results for job", deal.ID)
 	txHash, err := controller.web3SDK.CheckResult(deal.ID)
 	if err != nil {
+		controller.reportAbandoned(deal.ID, "check_result", err)
 		return fmt.Errorf("error calling check result tx for deal: %s", err.Error())
 	}
 	controller.log.Debug("check result tx", txHash)
@@ -414,6 +604,7 @@ func (controller *JobCreatorController) checkResult(deal data.DealContainer) err
 	// we have agreed to the deal so we need to update the tx in the solver
 	_, err = controller.solverClient.UpdateTransactionsJobCreator(deal.ID, data.DealTransactionsJobCreator{
 		CheckResult: txHash,
+		Stuck:       controller.web3SDK.StuckTransactions("check_result", txHash, nil),
 	})
 	if err != nil {
 		return fmt.Errorf("error adding CheckResult tx hash for deal: %s", err.Error())
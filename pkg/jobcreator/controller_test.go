@@ -0,0 +1,26 @@
+//go:build unit
+
+package jobcreator
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+func TestHasJudgedResults(t *testing.T) {
+	judged := map[string]bool{
+		"DealAgreed":        false,
+		"ResultsSubmitted":  false,
+		"ResultsAccepted":   true,
+		"ResultsChecked":    true,
+		"MediationAccepted": true,
+		"MediationRejected": true,
+		"TimeoutAgree":      false,
+	}
+	for state, expected := range judged {
+		if hasJudgedResults(data.GetAgreementStateIndex(state)) != expected {
+			t.Errorf("expected %s judged to be %v", state, expected)
+		}
+	}
+}
@@ -3,17 +3,40 @@ package jobcreator
 import (
 	"context"
 
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type JobCreatorMediationOptions struct {
+	// always check results for the first N deals with a new resource provider
+	AlwaysCheckFirst int
 	// out of 100 chance we will check results
 	CheckResultsPercentage int
 }
 
+type JobCreatorApprovalOptions struct {
+	// offers with an instruction price above this are held for approval
+	// zero means no threshold
+	InstructionPriceThreshold uint64
+	// offers with a payment collateral above this are held for approval
+	// zero means no threshold
+	PaymentCollateralThreshold uint64
+	// the addresses allowed to approve or reject held offers
+	Approvers []string
+	// an optional webhook we POST held offers to so approvers are notified
+	NotifyURL string
+	// the seconds an offer is held for before it is given up on
+	// zero means it waits for as long as the job creator runs
+	TTL int
+	// where the approval api is served
+	Host string
+	Port int
+}
+
 type JobCreatorOfferOptions struct {
 	// the module that is wanting to be run
 	// this contains the spec that is required to run the module
@@ -34,13 +57,46 @@ type JobCreatorOfferOptions struct {
 	Services data.ServiceConfig
 	// which node(s) (if any) to target
 	Target data.TargetConfig
+	// how many seconds we will wait for a resource offer's availability window
+	MaxDeferral int
+	// how many seconds and MB out we expect the job to use, resource
+	// offers with resource pricing charge for these
+	Duration int
+	Egress   int
+	// the requirement expressions as they were given on the command line
+	// e.g. "infiniband" or "gpu.count>=2"
+	RequirementSpec []string
+	// the parsed requirements we put on the job offer
+	Requirements []data.AttributeRequirement
+	// the regions we would like the job to run in
+	Locality data.LocalityPreference
+	// a cron schedule to run the job on e.g. "0 * * * *"
+	// empty means the job runs once
+	Schedule string
+	// the ERC-20 token to pay in, empty for the network's token
+	PaymentToken string
+	// the secrets the job needs as NAME or NAME=ENV_VAR, the value is
+	// read from the environment so it is never on the command line
+	SecretSpec []string
+	// the values read for them, they only leave this process encrypted to
+	// the matched resource provider's secrets key
+	Secrets map[string]string
+	// only run on resource providers in a confidential VM whose quote
+	// meets AttestationRequirement and checks out against the roots
+	RequireAttestation     bool
+	AttestationRequirement data.AttestationRequirement
 }
 
 type JobCreatorOptions struct {
-	Mediation JobCreatorMediationOptions
-	Offer     JobCreatorOfferOptions
-	Web3      web3.Web3Options
-	Telemetry system.TelemetryOptions
+	Mediation        JobCreatorMediationOptions
+	Approval         JobCreatorApprovalOptions
+	Offer            JobCreatorOfferOptions
+	Web3             web3.Web3Options
+	Attestation      attestation.AttestationOptions
+	Telemetry        system.TelemetryOptions
+	ClientTLS        http.ClientTLSOptions
+	ClientRetry      http.ClientRetryOptions
+	ClientConnection http.ClientConnectionOptions
 }
 
 type JobCreator struct {
@@ -75,8 +131,13 @@ func (jobCreator *JobCreator) GetJobOfferFromOptions(options JobCreatorOfferOpti
 }
 
 // adds the job offer to the solver
-func (jobCreator *JobCreator) AddJobOffer(offer data.JobOffer) (data.JobOfferContainer, error) {
-	return jobCreator.controller.AddJobOffer(offer)
+func (jobCreator *JobCreator) AddJobOffer(ctx context.Context, offer data.JobOffer) (data.JobOfferContainer, error) {
+	return jobCreator.controller.AddJobOffer(ctx, offer)
+}
+
+// asks the solver what the offer would cost before anything is escrowed
+func (jobCreator *JobCreator) EstimateJobOffer(offer data.JobOffer, instructionCount uint64) (data.CostEstimate, error) {
+	return jobCreator.controller.solverClient.EstimateJobOffer(offer, instructionCount)
 }
 
 func (jobCreator *JobCreator) SubscribeToJobOfferUpdates(sub JobOfferSubscriber) {
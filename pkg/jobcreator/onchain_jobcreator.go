@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
@@ -47,7 +49,9 @@ func (jobCreator *OnChainJobCreator) Start(ctx context.Context, cm *system.Clean
 	errorChan := jobCreator.controller.Start(ctx, cm)
 
 	// TODO: work out how to do dynamic pricing
-	tx, err := jobCreator.web3SDK.Contracts.JobCreator.SetRequiredDeposit(jobCreator.web3SDK.TransactOpts, web3.EtherToWei(JOB_PRICE))
+	tx, err := jobCreator.web3SDK.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return jobCreator.web3SDK.Contracts().JobCreator.SetRequiredDeposit(opts, web3.EtherToWei(JOB_PRICE))
+	})
 	if err != nil {
 		errorChan <- err
 		return errorChan
@@ -84,7 +88,9 @@ func (jobCreator *OnChainJobCreator) Start(ctx context.Context, cm *system.Clean
 		spew.Dump(result)
 		spew.Dump(int64(onChainID))
 
-		tx, err := jobCreator.web3SDK.Contracts.JobCreator.SubmitResults(jobCreator.web3SDK.TransactOpts, big.NewInt(int64(onChainID)), evOffer.DealID, result.DataID)
+		tx, err := jobCreator.web3SDK.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return jobCreator.web3SDK.Contracts().JobCreator.SubmitResults(opts, big.NewInt(int64(onChainID)), evOffer.DealID, result.DataID)
+		})
 		if err != nil {
 			return
 		}
@@ -96,9 +102,17 @@ func (jobCreator *OnChainJobCreator) Start(ctx context.Context, cm *system.Clean
 	})
 
 	jobCreator.web3Events.JobCreator.SubscribeJobAdded(func(ev jobcreatorweb3.JobcreatorJobAdded) {
+		// a reorg dropped the block the job was added in, the job is run
+		// if the new chain adds it again
+		if ev.Raw.Removed {
+			fmt.Printf("job added in tx %s was dropped by a reorg\n", ev.Raw.TxHash.Hex())
+			return
+		}
 
 		// first we need to move the tokens into our account
-		tx, err := jobCreator.web3SDK.Contracts.Token.TransferFrom(jobCreator.web3SDK.TransactOpts, ev.Payee, jobCreator.web3SDK.GetAddress(), web3.EtherToWei(JOB_PRICE))
+		tx, err := jobCreator.web3SDK.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return jobCreator.web3SDK.Contracts().Token.TransferFrom(opts, ev.Payee, jobCreator.web3SDK.GetAddress(), web3.EtherToWei(JOB_PRICE))
+		})
 		if err != nil {
 			fmt.Printf("error creating job offer: %s\n", err.Error())
 			return
@@ -126,7 +140,7 @@ func (jobCreator *OnChainJobCreator) Start(ctx context.Context, cm *system.Clean
 			return
 		}
 
-		container, err := jobCreator.controller.AddJobOffer(offer)
+		container, err := jobCreator.controller.AddJobOffer(ctx, offer)
 		if err != nil {
 			fmt.Printf("error creating job offer: %s\n", err.Error())
 			return
@@ -143,8 +157,8 @@ func (jobCreator *OnChainJobCreator) GetJobOfferFromOptions(options JobCreatorOf
 }
 
 // adds the job offer to the solver
-func (jobCreator *OnChainJobCreator) AddJobOffer(offer data.JobOffer) (data.JobOfferContainer, error) {
-	return jobCreator.controller.AddJobOffer(offer)
+func (jobCreator *OnChainJobCreator) AddJobOffer(ctx context.Context, offer data.JobOffer) (data.JobOfferContainer, error) {
+	return jobCreator.controller.AddJobOffer(ctx, offer)
 }
 
 func (jobCreator *OnChainJobCreator) SubscribeToJobOfferUpdates(sub JobOfferSubscriber) {
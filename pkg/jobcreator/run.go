@@ -1,6 +1,7 @@
 package jobcreator
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -49,19 +50,84 @@ func RunJob(
 	// wait a short period because we've just started the job creator service
 	time.Sleep(100 * time.Millisecond)
 
+	return runJobOffer(ctx.Ctx, jobCreatorService, jobCreatorErrors, offer)
+}
+
+// runs the job every time the schedule fires until the context is done
+// every run is posted as a new job offer linked to the others by a series ID
+func RunRecurringJob(
+	ctx *system.CommandContext,
+	options JobCreatorOptions,
+	tracer trace.Tracer,
+	eventSub JobOfferSubscriber,
+	runSub func(*RunJobResults, error),
+) error {
+	schedule, err := data.ParseCronSchedule(options.Offer.Schedule)
+	if err != nil {
+		return err
+	}
+
+	web3SDK, err := web3.NewContractSDK(ctx.Ctx, options.Web3, tracer)
+	if err != nil {
+		return err
+	}
+	jobCreatorService, err := NewJobCreator(options, web3SDK, tracer)
+	if err != nil {
+		return err
+	}
+	jobCreatorService.SubscribeToJobOfferUpdates(eventSub)
+	jobCreatorErrors := jobCreatorService.Start(ctx.Ctx, ctx.Cm)
+
+	// the first offer validates the module and starts the series
+	offer, err := jobCreatorService.GetJobOfferFromOptions(options.Offer)
+	if err != nil {
+		return err
+	}
+	series, err := data.GetSeriesID(offer, options.Offer.Schedule)
+	if err != nil {
+		return err
+	}
+	jobCreatorService.controller.log.Info("job series", series)
+
+	for {
+		next := schedule.Next(time.Now())
+		select {
+		case err := <-jobCreatorErrors:
+			return err
+		case <-ctx.Ctx.Done():
+			return nil
+		case <-time.After(time.Until(next)):
+		}
+
+		offer, err := jobCreatorService.GetJobOfferFromOptions(options.Offer)
+		if err != nil {
+			return err
+		}
+		offer.Series = series
+		runSub(runJobOffer(ctx.Ctx, jobCreatorService, jobCreatorErrors, offer))
+	}
+}
+
+// post the job offer and wait for it to finish
+func runJobOffer(
+	ctx context.Context,
+	jobCreatorService *JobCreator,
+	jobCreatorErrors chan error,
+	offer data.JobOffer,
+) (*RunJobResults, error) {
 	// Start run job trace
-	c, span := jobCreatorService.controller.tracer.Start(ctx.Ctx, "run_job",
+	ctx, span := jobCreatorService.controller.tracer.Start(ctx, "run_job",
 		trace.WithAttributes(
 			attribute.String("job_offer.job_creator", offer.JobCreator),
 			attribute.String("job_offer.module.repo", offer.Module.Repo),
 			attribute.String("job_offer.module.hash", offer.Module.Hash),
 			attribute.String("job_offer.mode", string(offer.Mode)),
+			attribute.String("job_offer.series", offer.Series),
 		))
-	ctx.Ctx = c
 	defer span.End()
 
 	span.AddEvent("add_job_offer.start")
-	jobOfferContainer, err := jobCreatorService.AddJobOffer(offer)
+	jobOfferContainer, err := jobCreatorService.AddJobOffer(ctx, offer)
 	if err != nil {
 		jobCreatorService.controller.log.Error("failed to add job offer", err)
 		span.SetStatus(codes.Error, "failed to add job offer")
@@ -99,7 +165,7 @@ waitloop:
 			span.SetStatus(codes.Error, "job cancelled")
 			span.RecordError(err)
 			return nil, err
-		case <-ctx.Ctx.Done():
+		case <-ctx.Done():
 			err = errors.New("job cancelled by closed context")
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
@@ -118,6 +184,14 @@ waitloop:
 		return nil, fmt.Errorf("job was cancelled")
 	}
 
+	// the deal went stale and was timed out
+	if data.IsTimeoutAgreementState(finalJobOffer.State) {
+		err = fmt.Errorf("job timed out: %s", data.GetAgreementStateString(finalJobOffer.State))
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
 	span.AddEvent("get_result.start")
 	result, err := jobCreatorService.GetResult(finalJobOffer.DealID)
 	if err != nil {
@@ -0,0 +1,45 @@
+package jobcreator
+
+import (
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+// the values of the secrets the job offer names
+func dealSecrets(names []string, secrets map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, ok := secrets[name]
+		if !ok {
+			return nil, fmt.Errorf("the job needs secret %s which we do not have", name)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// encrypts the job's secrets to the matched resource provider and attaches
+// them to the deal, this has to happen before we agree as the resource
+// provider starts the job as soon as both sides have
+func (controller *JobCreatorController) sendSecrets(deal data.DealContainer) error {
+	names := deal.Deal.JobOffer.Secrets
+	if len(names) == 0 || deal.Secrets != "" {
+		return nil
+	}
+	key := deal.Deal.ResourceOffer.SecretsKey
+	if key == "" {
+		return fmt.Errorf("resource provider %s has no secrets key for deal %s", deal.ResourceProvider, deal.ID)
+	}
+	secrets, err := dealSecrets(names, controller.options.Offer.Secrets)
+	if err != nil {
+		return err
+	}
+	encrypted, err := web3.EncryptSecrets(key, deal.ID, secrets)
+	if err != nil {
+		return err
+	}
+	_, err = controller.solverClient.UpdateDealSecrets(deal.ID, data.DealSecrets{Secrets: encrypted})
+	return err
+}
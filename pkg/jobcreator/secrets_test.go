@@ -0,0 +1,19 @@
+//go:build unit
+
+package jobcreator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDealSecrets(t *testing.T) {
+	secrets := map[string]string{"HF_TOKEN": "hf_abc", "OPENAI_API_KEY": "sk-123"}
+	values, err := dealSecrets([]string{"HF_TOKEN"}, secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"HF_TOKEN": "hf_abc"}, values)
+
+	_, err = dealSecrets([]string{"HF_TOKEN", "WANDB_API_KEY"}, secrets)
+	assert.ErrorContains(t, err, "WANDB_API_KEY")
+}
@@ -2,6 +2,7 @@ package jobcreator
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/lilypad-tech/lilypad/pkg/data"
@@ -15,20 +16,49 @@ func getJobOfferFromOptions(options JobCreatorOfferOptions, jobCreatorAddress st
 	// this will also validate the module the user is asking for
 	loadedModule, err := module.LoadModule(options.Module, options.Inputs)
 	if err != nil {
+		// the error is printed so the secrets stay out of it
+		options.Secrets = nil
 		return data.JobOffer{}, fmt.Errorf("error loading module: %s opts=%+v", err.Error(), options)
 	}
 
+	// the names go on the offer so it is only matched with resource
+	// providers that can be sent the values
+	secrets := make([]string, 0, len(options.Secrets))
+	for name := range options.Secrets {
+		secrets = append(secrets, name)
+	}
+	sort.Strings(secrets)
+
+	// only put a preference on the offer if we have one
+	var locality *data.LocalityPreference
+	if len(options.Locality.Regions) > 0 {
+		locality = &options.Locality
+	}
+
+	var attestationRequirement *data.AttestationRequirement
+	if options.RequireAttestation {
+		attestationRequirement = &options.AttestationRequirement
+	}
+
 	return data.JobOffer{
 		// assign CreatedAt to the current millisecond timestamp
-		CreatedAt:  int(time.Now().UnixNano() / int64(time.Millisecond)),
-		JobCreator: jobCreatorAddress,
-		Module:     options.Module,
-		Spec:       loadedModule.Machine,
-		Inputs:     options.Inputs,
-		Mode:       options.Mode,
-		Pricing:    options.Pricing,
-		Timeouts:   options.Timeouts,
-		Services:   options.Services,
-		Target:     options.Target,
+		CreatedAt:    int(time.Now().UnixNano() / int64(time.Millisecond)),
+		JobCreator:   jobCreatorAddress,
+		Module:       options.Module,
+		Spec:         loadedModule.Machine,
+		Inputs:       options.Inputs,
+		Mode:         options.Mode,
+		Pricing:      options.Pricing,
+		Timeouts:     options.Timeouts,
+		Services:     options.Services,
+		Target:       options.Target,
+		MaxDeferral:  options.MaxDeferral,
+		Duration:     options.Duration,
+		Egress:       options.Egress,
+		Requirements: options.Requirements,
+		Locality:     locality,
+		PaymentToken: options.PaymentToken,
+		Secrets:      secrets,
+		Attestation:  attestationRequirement,
 	}, nil
 }
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/executor"
 	"github.com/lilypad-tech/lilypad/pkg/http"
@@ -30,6 +31,9 @@ type MediatorController struct {
 	// whilst we are actually running a job
 	runningJobsMutex sync.RWMutex
 	runningJobs      map[string]bool
+	// checks the quotes of attested resource providers, nil without
+	// ATTESTATION_ROOTS
+	verifier *attestation.Verifier
 }
 
 // the background "even if we have not heard of an event" loop
@@ -54,9 +58,12 @@ func NewMediatorController(
 	solverClient, err := solver.NewSolverClient(
 		http.ClientOptions{
 			URL:           solverUrl,
-			PrivateKey:    options.Web3.PrivateKey,
+			Signer:        web3SDK.Signer,
 			Type:          "Mediator",
 			PublicAddress: web3SDK.GetAddress().String(),
+			TLS:           options.ClientTLS,
+			Retry:         options.ClientRetry,
+			Connection:    options.ClientConnection,
 		})
 	if err != nil {
 		log.Error().Msgf("error NewSolverClient")
@@ -72,6 +79,14 @@ func NewMediatorController(
 		executor:     executor,
 		runningJobs:  map[string]bool{},
 	}
+	if options.Attestation.Roots != "" {
+		controller.verifier, err = attestation.NewVerifier(options.Attestation)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		controller.log.Info("ATTESTATION_ROOTS is not set, the signatures of attestations will not be checked", "")
+	}
 	return controller, nil
 }
 
@@ -239,6 +254,13 @@ func (controller *MediatorController) runJobs() error {
 
 func (controller *MediatorController) runJob(deal data.DealContainer) {
 	controller.log.Info("mediator run job", deal)
+	// there is no point running the job again for a result from a
+	// resource provider that was never attested
+	if err := controller.checkAttestation(deal); err != nil {
+		controller.log.Info("mediation attestation failed", fmt.Sprintf("deal %s, rp: %s, %s", deal.ID, deal.Deal.ResourceOffer.ResourceProvider, err.Error()))
+		controller.rejectResult(deal)
+		return
+	}
 	mediatorResult := data.Result{
 		DealID: deal.ID,
 		Error:  "",
@@ -283,36 +305,78 @@ func (controller *MediatorController) runJob(deal data.DealContainer) {
 	}
 
 	if isResultCorrect {
-		txHash, err := controller.web3SDK.MediationAcceptResult(
-			deal.Deal.ID,
-		)
-		if err != nil {
-			controller.log.Error("error calling mediation accept result tx for job", err)
-			return
-		}
-
-		_, err = controller.solverClient.UpdateTransactionsMediator(deal.ID, data.DealTransactionsMediator{
-			MediationAcceptResult: txHash,
-		})
-		if err != nil {
-			controller.log.Error("error adding mediation accept result tx hash for deal", err)
-			return
-		}
+		controller.acceptResult(deal)
 	} else {
-		txHash, err := controller.web3SDK.MediationRejectResult(
-			deal.Deal.ID,
-		)
-		if err != nil {
-			controller.log.Error("error calling mediation reject result tx for job", err)
-			return
-		}
+		controller.rejectResult(deal)
+	}
+}
 
-		_, err = controller.solverClient.UpdateTransactionsMediator(deal.ID, data.DealTransactionsMediator{
-			MediationRejectResult: txHash,
-		})
-		if err != nil {
-			controller.log.Error("error adding mediation reject result tx hash for deal", err)
-			return
-		}
+func (controller *MediatorController) acceptResult(deal data.DealContainer) {
+	txHash, err := controller.web3SDK.MediationAcceptResult(
+		deal.Deal.ID,
+	)
+	if err != nil {
+		controller.log.Error("error calling mediation accept result tx for job", err)
+		controller.reportAbandoned(deal.ID, "mediation_accept_result", err)
+		return
+	}
+
+	_, err = controller.solverClient.UpdateTransactionsMediator(deal.ID, data.DealTransactionsMediator{
+		MediationAcceptResult: txHash,
+		Stuck:                 controller.web3SDK.StuckTransactions("mediation_accept_result", txHash, nil),
+	})
+	if err != nil {
+		controller.log.Error("error adding mediation accept result tx hash for deal", err)
+		return
+	}
+}
+
+func (controller *MediatorController) rejectResult(deal data.DealContainer) {
+	txHash, err := controller.web3SDK.MediationRejectResult(
+		deal.Deal.ID,
+	)
+	if err != nil {
+		controller.log.Error("error calling mediation reject result tx for job", err)
+		controller.reportAbandoned(deal.ID, "mediation_reject_result", err)
+		return
+	}
+
+	_, err = controller.solverClient.UpdateTransactionsMediator(deal.ID, data.DealTransactionsMediator{
+		MediationRejectResult: txHash,
+		Stuck:                 controller.web3SDK.StuckTransactions("mediation_reject_result", txHash, nil),
+	})
+	if err != nil {
+		controller.log.Error("error adding mediation reject result tx hash for deal", err)
+		return
+	}
+}
+
+// a job that required attestation is only as good as the quote of the
+// resource provider that ran it, without ATTESTATION_ROOTS we can check
+// the quote is for this resource provider but not its signatures
+func (controller *MediatorController) checkAttestation(deal data.DealContainer) error {
+	requirement := deal.Deal.JobOffer.Attestation
+	if requirement == nil {
+		return nil
+	}
+	if controller.verifier == nil {
+		_, err := attestation.Check(deal.Deal.ResourceOffer, requirement)
+		return err
+	}
+	return controller.verifier.Verify(deal.Deal.ResourceOffer, requirement)
+}
+
+// a deal transaction that was given up on has no hash to record but the
+// deal should still show what happened to it
+func (controller *MediatorController) reportAbandoned(dealID string, action string, err error) {
+	stuck := controller.web3SDK.StuckTransactions(action, "", err)
+	if len(stuck) == 0 {
+		return
+	}
+	_, err = controller.solverClient.UpdateTransactionsMediator(dealID, data.DealTransactionsMediator{
+		Stuck: stuck,
+	})
+	if err != nil {
+		controller.log.Error("error adding stuck txs for deal", err)
 	}
 }
@@ -3,9 +3,14 @@ package mediator
 import (
 	"context"
 
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/executor"
 	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
+	"github.com/lilypad-tech/lilypad/pkg/executor/container"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker"
+	"github.com/lilypad-tech/lilypad/pkg/executor/kubernetes"
+	"github.com/lilypad-tech/lilypad/pkg/http"
 	"github.com/lilypad-tech/lilypad/pkg/ipfs"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
@@ -13,10 +18,19 @@ import (
 )
 
 type MediatorOptions struct {
-	Bacalhau bacalhau.BacalhauExecutorOptions
-	Services data.ServiceConfig
-	Web3     web3.Web3Options
-	IPFS     ipfs.IPFSOptions
+	// the executor the mediator runs jobs again with to check results
+	Executor         string
+	Bacalhau         bacalhau.BacalhauExecutorOptions
+	Container        container.ContainerExecutorOptions
+	Kubernetes       kubernetes.KubernetesExecutorOptions
+	Firecracker      firecracker.FirecrackerExecutorOptions
+	Attestation      attestation.AttestationOptions
+	Services         data.ServiceConfig
+	Web3             web3.Web3Options
+	IPFS             ipfs.IPFSOptions
+	ClientTLS        http.ClientTLSOptions
+	ClientRetry      http.ClientRetryOptions
+	ClientConnection http.ClientConnectionOptions
 }
 
 type Mediator struct {
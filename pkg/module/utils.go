@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
 
@@ -188,6 +189,32 @@ func PrepareModule(module data.ModuleConfig) (string, error) {
 	return string(fileContents), nil
 }
 
+var templateImagePattern = regexp.MustCompile(`"Image"\s*:\s*"([^"{}]+)"`)
+
+// the docker images the module runs, read from the template text as
+// rendering it needs a job's inputs, an image that comes from the inputs
+// is left out
+func GetModuleImages(module data.ModuleConfig) ([]string, error) {
+	moduleText, err := PrepareModule(module)
+	if err != nil {
+		return nil, err
+	}
+	return templateImages(moduleText), nil
+}
+
+func templateImages(moduleText string) []string {
+	images := []string{}
+	seen := map[string]bool{}
+	for _, match := range templateImagePattern.FindAllStringSubmatch(moduleText, -1) {
+		image := strings.TrimSpace(match[1])
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
 func subst(format string, jsonEncodedInputs ...string) string {
 	jsonDecodedInputs := make([]interface{}, 0, len(jsonEncodedInputs))
 
@@ -254,6 +281,9 @@ func LoadModule(module data.ModuleConfig, inputs map[string]string) (*data.Modul
 			bs,
 		)
 	}
+	if err := attachVolumes(&moduleData, inputs); err != nil {
+		return nil, err
+	}
 
 	return &moduleData, nil
 }
@@ -57,3 +57,12 @@ func TestSubst(t *testing.T) {
 		t.Errorf("Expected output: %s, but got: %s", expectedOutput, actualOutput)
 	}
 }
+
+func TestTemplateImages(t *testing.T) {
+	text := `{
+	"Job": {"Spec": {"Docker": {"Image": "ghcr.io/acme/sdxl:v1", "Entrypoint": ["run"]}}},
+	"Other": {"Image" : "ghcr.io/acme/sdxl:v1"},
+	"FromInputs": {"Image": "{{ .Image }}"}
+}`
+	assert.Equal(t, []string{"ghcr.io/acme/sdxl:v1"}, templateImages(text))
+}
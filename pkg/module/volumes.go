@@ -0,0 +1,81 @@
+package module
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+)
+
+// v0 CIDs are base58 multihashes and v1 ones base32 with a b in front,
+// which are the two forms ipfs hands out
+var cidPattern = regexp.MustCompile(`^(Qm[1-9A-HJ-NP-Za-km-z]{44}|b[a-z2-7]{50,})$`)
+
+// the bacalhau job writes its results here so nothing is mounted over it
+const outputsPath = "/outputs"
+
+// what an input's value points at, ipfs://<cid> or a bare cid is pinned
+// content and http(s) urls are downloaded by the compute node
+func parseVolumeSource(value string) (bacalhau.StorageSpec, error) {
+	value = strings.TrimSpace(value)
+	if cid, ok := strings.CutPrefix(value, "ipfs://"); ok {
+		if !cidPattern.MatchString(cid) {
+			return bacalhau.StorageSpec{}, fmt.Errorf("%s is not an ipfs cid", cid)
+		}
+		return bacalhau.StorageSpec{StorageSource: bacalhau.StorageSourceIPFS, CID: cid}, nil
+	}
+	if strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://") {
+		return bacalhau.StorageSpec{StorageSource: bacalhau.StorageSourceURLDownload, URL: value}, nil
+	}
+	if cidPattern.MatchString(value) {
+		return bacalhau.StorageSpec{StorageSource: bacalhau.StorageSourceIPFS, CID: value}, nil
+	}
+	return bacalhau.StorageSpec{}, fmt.Errorf("%q should be an ipfs cid or an http(s) url", value)
+}
+
+func checkVolumePath(volumePath string) error {
+	if !path.IsAbs(volumePath) || path.Clean(volumePath) != volumePath {
+		return fmt.Errorf("%q should be a clean absolute path", volumePath)
+	}
+	if volumePath == "/" || volumePath == outputsPath || strings.HasPrefix(volumePath, outputsPath+"/") {
+		return fmt.Errorf("%s cannot be mounted over", volumePath)
+	}
+	return nil
+}
+
+// adds the volumes the module declares to the job's inputs from the
+// values given for them, an optional volume without a value is left out
+func attachVolumes(module *data.Module, inputs map[string]string) error {
+	paths := map[string]string{}
+	for _, volume := range module.Volumes {
+		if volume.Input == "" {
+			return fmt.Errorf("module volume at %s has no input", volume.Path)
+		}
+		if err := checkVolumePath(volume.Path); err != nil {
+			return fmt.Errorf("module volume %s: %w", volume.Input, err)
+		}
+		if other, ok := paths[volume.Path]; ok {
+			return fmt.Errorf("module volumes %s and %s are both mounted at %s", other, volume.Input, volume.Path)
+		}
+		paths[volume.Path] = volume.Input
+
+		value := inputs[volume.Input]
+		if value == "" {
+			if volume.Required {
+				return fmt.Errorf("input %s is required, it should be an ipfs cid or url to mount at %s", volume.Input, volume.Path)
+			}
+			continue
+		}
+		source, err := parseVolumeSource(value)
+		if err != nil {
+			return fmt.Errorf("input %s: %w", volume.Input, err)
+		}
+		source.Name = volume.Input
+		source.Path = volume.Path
+		module.Job.Spec.Inputs = append(module.Job.Spec.Inputs, source)
+	}
+	return nil
+}
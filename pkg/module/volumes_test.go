@@ -0,0 +1,62 @@
+//go:build unit
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/data/bacalhau"
+)
+
+const testCID = "QmTVmC7JBD2ES2qGPqBNVWnX1KeEPNrPGb7rJ8cpFgtefe"
+
+func TestParseVolumeSource(t *testing.T) {
+	source, err := parseVolumeSource("ipfs://" + testCID)
+	assert.NoError(t, err)
+	assert.Equal(t, bacalhau.StorageSpec{StorageSource: bacalhau.StorageSourceIPFS, CID: testCID}, source)
+
+	source, err = parseVolumeSource("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi")
+	assert.NoError(t, err)
+	assert.Equal(t, bacalhau.StorageSourceIPFS, source.StorageSource)
+
+	source, err = parseVolumeSource("https://example.com/weights.safetensors")
+	assert.NoError(t, err)
+	assert.Equal(t, bacalhau.StorageSpec{StorageSource: bacalhau.StorageSourceURLDownload, URL: "https://example.com/weights.safetensors"}, source)
+
+	for _, value := range []string{"ipfs://nope", "/etc/passwd", "file:///etc/passwd", "hello"} {
+		_, err = parseVolumeSource(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAttachVolumes(t *testing.T) {
+	module := data.Module{Volumes: []data.ModuleVolume{
+		{Input: "dataset", Path: "/inputs/dataset", Required: true},
+		{Input: "weights", Path: "/inputs/weights.bin"},
+	}}
+	assert.NoError(t, attachVolumes(&module, map[string]string{"dataset": testCID}))
+	assert.Equal(t, []bacalhau.StorageSpec{{
+		StorageSource: bacalhau.StorageSourceIPFS,
+		Name:          "dataset",
+		CID:           testCID,
+		Path:          "/inputs/dataset",
+	}}, module.Job.Spec.Inputs)
+
+	module.Job.Spec.Inputs = nil
+	assert.ErrorContains(t, attachVolumes(&module, map[string]string{}), "input dataset is required")
+}
+
+func TestAttachVolumesChecksPaths(t *testing.T) {
+	for _, volumePath := range []string{"inputs", "/inputs/../etc", "/outputs", "/outputs/data", "/"} {
+		module := data.Module{Volumes: []data.ModuleVolume{{Input: "dataset", Path: volumePath}}}
+		assert.Error(t, attachVolumes(&module, map[string]string{"dataset": testCID}), volumePath)
+	}
+	module := data.Module{Volumes: []data.ModuleVolume{
+		{Input: "a", Path: "/inputs"},
+		{Input: "b", Path: "/inputs"},
+	}}
+	assert.ErrorContains(t, attachVolumes(&module, map[string]string{}), "both mounted at /inputs")
+}
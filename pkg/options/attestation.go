@@ -0,0 +1,37 @@
+package options
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/spf13/cobra"
+)
+
+func GetDefaultAttestationOptions() attestation.AttestationOptions {
+	return attestation.AttestationOptions{
+		Roots: GetDefaultServeOptionString("ATTESTATION_ROOTS", ""),
+	}
+}
+
+func AddAttestationCliFlags(cmd *cobra.Command, options *attestation.AttestationOptions) {
+	cmd.PersistentFlags().StringVar(
+		&options.Roots, "attestation-roots", options.Roots,
+		`A PEM file of the AMD ARK and Intel SGX root certificates that resource provider attestations are checked against (ATTESTATION_ROOTS).`,
+	)
+}
+
+func CheckAttestationRequirement(requirement data.AttestationRequirement) error {
+	for _, teeType := range requirement.Types {
+		if teeType != attestation.TypeSEVSNP && teeType != attestation.TypeTDX {
+			return fmt.Errorf("OFFER_ATTESTATION_TYPES %s is not %s or %s", teeType, attestation.TypeSEVSNP, attestation.TypeTDX)
+		}
+	}
+	for _, measurement := range requirement.Measurements {
+		if _, err := hex.DecodeString(measurement); err != nil || measurement == "" {
+			return fmt.Errorf("OFFER_ATTESTATION_MEASUREMENTS %s is not hex", measurement)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package options
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
 	"github.com/spf13/cobra"
@@ -13,6 +14,13 @@ func GetDefaultBacalhauOptions() bacalhau.BacalhauExecutorOptions {
 		ApiHost:               GetDefaultServeOptionString("BACALHAU_API_HOST", "localhost"),
 		ApiPort:               GetDefaultServeOptionString("BACALHAU_API_PORT", "1234"),
 		JobStatusPollInterval: GetDefaultServeOptionUint64("JOB_STATUS_POLL_INTERVAL", 5),
+		EnforceLimits:         GetDefaultServeOptionBool("BACALHAU_ENFORCE_LIMITS", true),
+		MeasureUsage:          GetDefaultServeOptionBool("BACALHAU_MEASURE_USAGE", true),
+		RegistryAuth:          GetDefaultServeOptionStringMap("BACALHAU_REGISTRY_AUTH", map[string]string{}),
+		DockerConfig:          GetDefaultServeOptionString("BACALHAU_DOCKER_CONFIG", ""),
+		ImageCacheBudget:      GetDefaultServeOptionInt("BACALHAU_IMAGE_CACHE_BUDGET", 0),
+		PrepullImages:         GetDefaultServeOptionStringArray("BACALHAU_PREPULL_IMAGES", []string{}),
+		SecretsDir:            GetDefaultServeOptionString("BACALHAU_SECRETS_DIR", "/dev/shm/lilypad-secrets"),
 	}
 }
 
@@ -26,11 +34,49 @@ func AddBacalhauCliFlags(cmd *cobra.Command, bacalhauOptions *bacalhau.BacalhauE
 		&bacalhauOptions.ApiPort, "bacalhau-api-port", bacalhauOptions.ApiPort,
 		`The api port for the bacalhau cluster to run jobs`,
 	)
+
+	cmd.PersistentFlags().BoolVar(
+		&bacalhauOptions.EnforceLimits, "bacalhau-enforce-limits", bacalhauOptions.EnforceLimits,
+		`Run jobs with the cpu, memory and disk limits of their deal rather than the module's own (BACALHAU_ENFORCE_LIMITS)`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&bacalhauOptions.MeasureUsage, "bacalhau-measure-usage", bacalhauOptions.MeasureUsage,
+		`Sample each job's cpu, memory and gpu use with docker and nvidia-smi and post it with the result (BACALHAU_MEASURE_USAGE)`,
+	)
+	cmd.PersistentFlags().StringToStringVar(
+		&bacalhauOptions.RegistryAuth, "bacalhau-registry-auth", bacalhauOptions.RegistryAuth,
+		`Credentials for private images as image prefix=user:password pairs e.g. ghcr.io/acme=bot:ghp_xxx, the longest prefix that covers an image is used (BACALHAU_REGISTRY_AUTH)`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&bacalhauOptions.DockerConfig, "bacalhau-docker-config", bacalhauOptions.DockerConfig,
+		`The directory of a docker config.json, including any credential helpers, to pull every other image with (BACALHAU_DOCKER_CONFIG)`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&bacalhauOptions.ImageCacheBudget, "bacalhau-image-cache-budget", bacalhauOptions.ImageCacheBudget,
+		`The MB of module images to keep on the machine, the least recently used are removed once they are over it, 0 keeps them all (BACALHAU_IMAGE_CACHE_BUDGET)`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&bacalhauOptions.PrepullImages, "bacalhau-prepull-images", bacalhauOptions.PrepullImages,
+		`Images to pull at startup along with those of the offered modules (BACALHAU_PREPULL_IMAGES)`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&bacalhauOptions.SecretsDir, "bacalhau-secrets-dir", bacalhauOptions.SecretsDir,
+		`A directory on a tmpfs that the compute node allow lists, the files that give jobs their secrets are written and mounted from it (BACALHAU_SECRETS_DIR)`,
+	)
 }
 
 func CheckBacalhauOptions(options bacalhau.BacalhauExecutorOptions) error {
 	if options.ApiHost == "" {
 		return fmt.Errorf("No bacalhau service specified - please use BACALHAU_API_HOST or --bacalhau-api-host")
 	}
+	if options.ImageCacheBudget < 0 {
+		return fmt.Errorf("BACALHAU_IMAGE_CACHE_BUDGET cannot be negative")
+	}
+	for prefix, credentials := range options.RegistryAuth {
+		// the credentials themselves stay out of the error
+		if user, password, ok := strings.Cut(credentials, ":"); !ok || user == "" || password == "" {
+			return fmt.Errorf("BACALHAU_REGISTRY_AUTH for %s should be user:password", prefix)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,157 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/spf13/cobra"
+)
+
+func GetDefaultClientTLSOptions() http.ClientTLSOptions {
+	return http.ClientTLSOptions{
+		CertFile: GetDefaultServeOptionString("CLIENT_TLS_CERT_FILE", ""),
+		KeyFile:  GetDefaultServeOptionString("CLIENT_TLS_KEY_FILE", ""),
+		CAFile:   GetDefaultServeOptionString("CLIENT_TLS_CA_FILE", ""),
+	}
+}
+
+func AddClientTLSCliFlags(cmd *cobra.Command, tlsOptions *http.ClientTLSOptions) {
+	cmd.PersistentFlags().StringVar(
+		&tlsOptions.CertFile, "client-tls-cert-file", tlsOptions.CertFile,
+		`The certificate to present to a solver that uses mutual tls (CLIENT_TLS_CERT_FILE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&tlsOptions.KeyFile, "client-tls-key-file", tlsOptions.KeyFile,
+		`The private key for the client certificate (CLIENT_TLS_KEY_FILE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&tlsOptions.CAFile, "client-tls-ca-file", tlsOptions.CAFile,
+		`The CA to trust the solver certificate with when it is not a public one (CLIENT_TLS_CA_FILE).`,
+	)
+}
+
+func GetDefaultClientRetryOptions() http.ClientRetryOptions {
+	return http.ClientRetryOptions{
+		MaxRetries:      GetDefaultServeOptionInt("CLIENT_RETRY_MAX", http.DefaultClientRetryOptions.MaxRetries),
+		MinBackoff:      GetDefaultServeOptionInt("CLIENT_RETRY_MIN_BACKOFF", http.DefaultClientRetryOptions.MinBackoff),
+		MaxBackoff:      GetDefaultServeOptionInt("CLIENT_RETRY_MAX_BACKOFF", http.DefaultClientRetryOptions.MaxBackoff),
+		BreakerFailures: GetDefaultServeOptionInt("CLIENT_BREAKER_FAILURES", http.DefaultClientRetryOptions.BreakerFailures),
+		BreakerCooldown: GetDefaultServeOptionInt("CLIENT_BREAKER_COOLDOWN", http.DefaultClientRetryOptions.BreakerCooldown),
+	}
+}
+
+func AddClientRetryCliFlags(cmd *cobra.Command, retryOptions *http.ClientRetryOptions) {
+	cmd.PersistentFlags().IntVar(
+		&retryOptions.MaxRetries, "client-retry-max", retryOptions.MaxRetries,
+		`How many times a failed call that is safe to repeat is retried (CLIENT_RETRY_MAX).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&retryOptions.MinBackoff, "client-retry-min-backoff", retryOptions.MinBackoff,
+		`The milliseconds to wait before the first retry, the wait doubles from here with jitter (CLIENT_RETRY_MIN_BACKOFF).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&retryOptions.MaxBackoff, "client-retry-max-backoff", retryOptions.MaxBackoff,
+		`The most milliseconds to wait between retries (CLIENT_RETRY_MAX_BACKOFF).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&retryOptions.BreakerFailures, "client-breaker-failures", retryOptions.BreakerFailures,
+		`The failures in a row that stop calls to a host for a while, 0 turns the breaker off (CLIENT_BREAKER_FAILURES).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&retryOptions.BreakerCooldown, "client-breaker-cooldown", retryOptions.BreakerCooldown,
+		`The seconds to stop calling a failing host before trying it again (CLIENT_BREAKER_COOLDOWN).`,
+	)
+}
+
+func GetDefaultClientConnectionOptions() http.ClientConnectionOptions {
+	defaults := http.DefaultClientConnectionOptions
+	return http.ClientConnectionOptions{
+		DialTimeout:           GetDefaultServeOptionInt("CLIENT_DIAL_TIMEOUT", defaults.DialTimeout),
+		TLSHandshakeTimeout:   GetDefaultServeOptionInt("CLIENT_TLS_HANDSHAKE_TIMEOUT", defaults.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: GetDefaultServeOptionInt("CLIENT_RESPONSE_HEADER_TIMEOUT", defaults.ResponseHeaderTimeout),
+		RequestTimeout:        GetDefaultServeOptionInt("CLIENT_REQUEST_TIMEOUT", defaults.RequestTimeout),
+		KeepAlive:             GetDefaultServeOptionInt("CLIENT_KEEP_ALIVE", defaults.KeepAlive),
+		IdleConnTimeout:       GetDefaultServeOptionInt("CLIENT_IDLE_CONN_TIMEOUT", defaults.IdleConnTimeout),
+		MaxIdleConns:          GetDefaultServeOptionInt("CLIENT_MAX_IDLE_CONNS", defaults.MaxIdleConns),
+		MaxIdleConnsPerHost:   GetDefaultServeOptionInt("CLIENT_MAX_IDLE_CONNS_PER_HOST", defaults.MaxIdleConnsPerHost),
+		MaxConnsPerHost:       GetDefaultServeOptionInt("CLIENT_MAX_CONNS_PER_HOST", defaults.MaxConnsPerHost),
+	}
+}
+
+func AddClientConnectionCliFlags(cmd *cobra.Command, connectionOptions *http.ClientConnectionOptions) {
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.DialTimeout, "client-dial-timeout", connectionOptions.DialTimeout,
+		`The seconds to wait for a connection to the solver, 0 means no limit (CLIENT_DIAL_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.TLSHandshakeTimeout, "client-tls-handshake-timeout", connectionOptions.TLSHandshakeTimeout,
+		`The seconds to wait for the tls handshake, 0 means no limit (CLIENT_TLS_HANDSHAKE_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.ResponseHeaderTimeout, "client-response-header-timeout", connectionOptions.ResponseHeaderTimeout,
+		`The seconds to wait for response headers once a request is sent, 0 means no limit (CLIENT_RESPONSE_HEADER_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.RequestTimeout, "client-request-timeout", connectionOptions.RequestTimeout,
+		`The seconds each attempt at a request has including reading the body, 0 means no limit (CLIENT_REQUEST_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.KeepAlive, "client-keep-alive", connectionOptions.KeepAlive,
+		`The seconds between keep-alive probes on open connections (CLIENT_KEEP_ALIVE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.IdleConnTimeout, "client-idle-conn-timeout", connectionOptions.IdleConnTimeout,
+		`The seconds an idle connection is kept open for reuse, 0 means no limit (CLIENT_IDLE_CONN_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.MaxIdleConns, "client-max-idle-conns", connectionOptions.MaxIdleConns,
+		`The most idle connections kept open across all hosts, 0 means no limit (CLIENT_MAX_IDLE_CONNS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.MaxIdleConnsPerHost, "client-max-idle-conns-per-host", connectionOptions.MaxIdleConnsPerHost,
+		`The most idle connections kept open to each host (CLIENT_MAX_IDLE_CONNS_PER_HOST).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&connectionOptions.MaxConnsPerHost, "client-max-conns-per-host", connectionOptions.MaxConnsPerHost,
+		`The most connections open to each host at once, 0 means no limit (CLIENT_MAX_CONNS_PER_HOST).`,
+	)
+}
+
+func CheckClientConnectionOptions(options http.ClientConnectionOptions) error {
+	values := map[string]int{
+		"CLIENT_DIAL_TIMEOUT":            options.DialTimeout,
+		"CLIENT_TLS_HANDSHAKE_TIMEOUT":   options.TLSHandshakeTimeout,
+		"CLIENT_RESPONSE_HEADER_TIMEOUT": options.ResponseHeaderTimeout,
+		"CLIENT_REQUEST_TIMEOUT":         options.RequestTimeout,
+		"CLIENT_KEEP_ALIVE":              options.KeepAlive,
+		"CLIENT_IDLE_CONN_TIMEOUT":       options.IdleConnTimeout,
+		"CLIENT_MAX_IDLE_CONNS":          options.MaxIdleConns,
+		"CLIENT_MAX_IDLE_CONNS_PER_HOST": options.MaxIdleConnsPerHost,
+		"CLIENT_MAX_CONNS_PER_HOST":      options.MaxConnsPerHost,
+	}
+	for name, value := range values {
+		if value < 0 {
+			return fmt.Errorf("%s cannot be negative", name)
+		}
+	}
+	if options.RequestTimeout > 0 && options.ResponseHeaderTimeout > options.RequestTimeout {
+		return fmt.Errorf("CLIENT_RESPONSE_HEADER_TIMEOUT cannot be longer than CLIENT_REQUEST_TIMEOUT")
+	}
+	return nil
+}
+
+func CheckClientRetryOptions(options http.ClientRetryOptions) error {
+	if options.MaxRetries < 0 {
+		return fmt.Errorf("CLIENT_RETRY_MAX cannot be negative")
+	}
+	if options.MinBackoff <= 0 || options.MaxBackoff < options.MinBackoff {
+		return fmt.Errorf("CLIENT_RETRY_MIN_BACKOFF must be positive and no more than CLIENT_RETRY_MAX_BACKOFF")
+	}
+	if options.BreakerFailures < 0 {
+		return fmt.Errorf("CLIENT_BREAKER_FAILURES cannot be negative")
+	}
+	if options.BreakerFailures > 0 && options.BreakerCooldown <= 0 {
+		return fmt.Errorf("CLIENT_BREAKER_COOLDOWN must be positive when the breaker is on")
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package options
 
 import (
 	"embed"
-	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/lilypad-tech/lilypad/pkg/data"
@@ -15,27 +18,131 @@ import (
 //go:embed configs
 var fs embed.FS
 
+// where the built in network profiles are found in the binary
+const builtinNetworkSource = "built in"
+
 type Config struct {
+	Network          NetworkMetadata         `toml:"network"`
+	Token            TokenMetadata           `toml:"token"`
 	Web3             web3.Web3Options        `toml:"web3"`
 	ServiceConfig    data.ServiceConfig      `toml:"services"`
 	IPFSOptions      ipfs.IPFSOptions        `toml:"ipfs"`
 	TelemetryOptions system.TelemetryOptions `toml:"telemetry"`
 }
 
-// TODO(bgins) Check for user-defined config files
-func getConfig(network string) (*Config, error) {
-	var config Config
+type NetworkMetadata struct {
+	Description string `toml:"description"`
+}
 
-	path := fmt.Sprintf("configs/%s.toml", network)
-	config_toml, err := fs.ReadFile(path)
+// the token the network pays in
+type TokenMetadata struct {
+	Name     string `toml:"name"`
+	Symbol   string `toml:"symbol"`
+	Decimals int    `toml:"decimals"`
+}
+
+// a network profile and where it was loaded from
+type Network struct {
+	Name   string
+	Source string
+	Config Config
+}
+
+// the directory custom network profiles are read from, LILYPAD_NETWORKS_DIR
+// or lilypad/networks in the user's config directory
+func NetworksDir() string {
+	if dir := os.Getenv("LILYPAD_NETWORKS_DIR"); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
 	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lilypad", "networks")
+}
+
+// --network is either a path to a toml file or the name of a profile, a
+// custom profile in the networks dir is used over a built in one of the
+// same name so the built in ones can be changed without a new release
+func getConfig(network string) (*Config, error) {
+	if strings.HasSuffix(network, ".toml") {
+		return readConfigFile(network)
+	}
+	if dir := NetworksDir(); dir != "" {
+		path := filepath.Join(dir, network+".toml")
+		if _, err := os.Stat(path); err == nil {
+			return readConfigFile(path)
+		}
+	}
+
+	config, err := getBuiltinConfig(network)
+	if os.IsNotExist(err) {
 		return nil, fmt.Errorf("network %s does not exist", network)
 	}
+	return config, err
+}
 
-	_, err = toml.Decode(string(config_toml), &config)
+func readConfigFile(path string) (*Config, error) {
+	configToml, err := os.ReadFile(path)
 	if err != nil {
-		return nil, errors.New("unable to parse config file")
+		return nil, fmt.Errorf("error reading network profile %s: %w", path, err)
 	}
+	return parseConfig(path, configToml)
+}
 
+func parseConfig(name string, configToml []byte) (*Config, error) {
+	var config Config
+	if _, err := toml.Decode(string(configToml), &config); err != nil {
+		return nil, fmt.Errorf("unable to parse config for network %s: %w", name, err)
+	}
 	return &config, nil
 }
+
+// every network --network can be given by name, built in and custom
+func ListNetworks() ([]Network, error) {
+	found := map[string]Network{}
+	entries, err := fs.ReadDir("configs")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".toml")
+		config, err := getBuiltinConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		found[name] = Network{Name: name, Source: builtinNetworkSource, Config: *config}
+	}
+
+	if dir := NetworksDir(); dir != "" {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			config, err := readConfigFile(path)
+			if err != nil {
+				return nil, err
+			}
+			name := strings.TrimSuffix(filepath.Base(path), ".toml")
+			found[name] = Network{Name: name, Source: path, Config: *config}
+		}
+	}
+
+	networks := make([]Network, 0, len(found))
+	for _, network := range found {
+		networks = append(networks, network)
+	}
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Name < networks[j].Name
+	})
+	return networks, nil
+}
+
+func getBuiltinConfig(name string) (*Config, error) {
+	configToml, err := fs.ReadFile(fmt.Sprintf("configs/%s.toml", name))
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(name, configToml)
+}
@@ -0,0 +1,116 @@
+//go:build unit
+
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+func writeNetwork(t *testing.T, dir string, name string, description string) string {
+	path := filepath.Join(dir, name+".toml")
+	require.NoError(t, os.WriteFile(path, []byte("[network]\ndescription = \""+description+"\"\n"), 0o644))
+	return path
+}
+
+func TestGetConfigUnknownNetwork(t *testing.T) {
+	t.Setenv("LILYPAD_NETWORKS_DIR", t.TempDir())
+
+	_, err := getConfig("nowhere")
+	require.EqualError(t, err, "network nowhere does not exist")
+
+	_, err = getConfig(filepath.Join(t.TempDir(), "missing.toml"))
+	require.ErrorContains(t, err, "error reading network profile")
+}
+
+func TestGetConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LILYPAD_NETWORKS_DIR", dir)
+
+	// the built in profile until a custom one of the same name turns up
+	config, err := getConfig("dev")
+	require.NoError(t, err)
+	require.Equal(t, "a local devnet from the stack scripts", config.Network.Description)
+
+	writeNetwork(t, dir, "dev", "custom dev")
+	config, err = getConfig("dev")
+	require.NoError(t, err)
+	require.Equal(t, "custom dev", config.Network.Description)
+
+	// a path is read as it is whatever the networks dir holds
+	path := writeNetwork(t, t.TempDir(), "dev", "from a path")
+	config, err = getConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "from a path", config.Network.Description)
+}
+
+func TestGetConfigInvalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LILYPAD_NETWORKS_DIR", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.toml"), []byte("[network"), 0o644))
+
+	_, err := getConfig("broken")
+	require.ErrorContains(t, err, "unable to parse config for network")
+}
+
+func TestProcessWeb3OptionsPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LILYPAD_NETWORKS_DIR", dir)
+	t.Setenv("WEB3_PRIVATE_KEY", "")
+	t.Setenv("WEB3_MNEMONIC", "")
+	profile := "[web3]\nrpc_url = \"ws://profile:8548\"\nchain_id = 7\nconfirmations = 3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom.toml"), []byte(profile), 0o644))
+
+	// what the profile has fills in whatever was not set by env or flag
+	options, err := ProcessWeb3Options(web3.Web3Options{Confirmations: -1}, "custom")
+	require.NoError(t, err)
+	require.Equal(t, "ws://profile:8548", options.RpcURL)
+	require.Equal(t, 7, options.ChainID)
+	require.Equal(t, 3, options.Confirmations)
+
+	options, err = ProcessWeb3Options(web3.Web3Options{RpcURL: "ws://flag:8548", ChainID: 9, Confirmations: 0}, "custom")
+	require.NoError(t, err)
+	require.Equal(t, "ws://flag:8548", options.RpcURL)
+	require.Equal(t, 9, options.ChainID)
+	require.Equal(t, 0, options.Confirmations)
+
+	_, err = ProcessWeb3Options(web3.Web3Options{}, "nowhere")
+	require.EqualError(t, err, "network nowhere does not exist")
+}
+
+func TestListNetworks(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LILYPAD_NETWORKS_DIR", dir)
+	devPath := writeNetwork(t, dir, "dev", "custom dev")
+	writeNetwork(t, dir, "private", "a private network")
+	// only toml files are profiles
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a network"), 0o644))
+
+	networks, err := ListNetworks()
+	require.NoError(t, err)
+
+	names := []string{}
+	byName := map[string]Network{}
+	for _, network := range networks {
+		names = append(names, network.Name)
+		byName[network.Name] = network
+	}
+	require.Equal(t, []string{"demonet", "dev", "devnet", "private", "testnet"}, names)
+	require.Equal(t, devPath, byName["dev"].Source)
+	require.Equal(t, "custom dev", byName["dev"].Config.Network.Description)
+	require.Equal(t, builtinNetworkSource, byName["testnet"].Source)
+	require.Equal(t, "a private network", byName["private"].Config.Network.Description)
+}
+
+func TestListNetworksInvalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LILYPAD_NETWORKS_DIR", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.toml"), []byte("[network"), 0o644))
+
+	_, err := ListNetworks()
+	require.ErrorContains(t, err, "unable to parse config for network")
+}
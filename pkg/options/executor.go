@@ -0,0 +1,187 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
+	"github.com/lilypad-tech/lilypad/pkg/executor/container"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker"
+	"github.com/lilypad-tech/lilypad/pkg/executor/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+func GetDefaultExecutorType() string {
+	return GetDefaultServeOptionString("EXECUTOR_TYPE", "bacalhau")
+}
+
+func GetDefaultContainerOptions() container.ContainerExecutorOptions {
+	return container.ContainerExecutorOptions{
+		Binary:        GetDefaultServeOptionString("CONTAINER_BINARY", ""),
+		Namespace:     GetDefaultServeOptionString("CONTAINERD_NAMESPACE", "lilypad"),
+		IPFSGateway:   GetDefaultServeOptionString("CONTAINER_IPFS_GATEWAY", "https://ipfs.io"),
+		EnforceLimits: GetDefaultServeOptionBool("CONTAINER_ENFORCE_LIMITS", true),
+	}
+}
+
+func GetDefaultKubernetesOptions() kubernetes.KubernetesExecutorOptions {
+	return kubernetes.KubernetesExecutorOptions{
+		Kubectl:               GetDefaultServeOptionString("KUBECTL_BINARY", ""),
+		Kubeconfig:            GetDefaultServeOptionString("KUBERNETES_KUBECONFIG", ""),
+		Namespace:             GetDefaultServeOptionString("KUBERNETES_NAMESPACE", "lilypad"),
+		HelperImage:           GetDefaultServeOptionString("KUBERNETES_HELPER_IMAGE", "busybox:1.36"),
+		IPFSGateway:           GetDefaultServeOptionString("KUBERNETES_IPFS_GATEWAY", "https://ipfs.io"),
+		ImagePullSecrets:      GetDefaultServeOptionStringArray("KUBERNETES_IMAGE_PULL_SECRETS", []string{}),
+		JobStatusPollInterval: GetDefaultServeOptionUint64("JOB_STATUS_POLL_INTERVAL", 5),
+	}
+}
+
+func GetDefaultFirecrackerOptions() firecracker.FirecrackerExecutorOptions {
+	return firecracker.FirecrackerExecutorOptions{
+		Firecracker:   GetDefaultServeOptionString("FIRECRACKER_BINARY", ""),
+		Jailer:        GetDefaultServeOptionString("FIRECRACKER_JAILER", ""),
+		Kernel:        GetDefaultServeOptionString("FIRECRACKER_KERNEL", ""),
+		Init:          GetDefaultServeOptionString("FIRECRACKER_INIT", ""),
+		ChrootBase:    GetDefaultServeOptionString("FIRECRACKER_CHROOT_BASE", "/srv/jailer"),
+		UID:           GetDefaultServeOptionInt("FIRECRACKER_UID", 0),
+		GID:           GetDefaultServeOptionInt("FIRECRACKER_GID", 0),
+		ImageTool:     GetDefaultServeOptionString("FIRECRACKER_IMAGE_TOOL", "docker"),
+		IPFSGateway:   GetDefaultServeOptionString("FIRECRACKER_IPFS_GATEWAY", "https://ipfs.io"),
+		ScratchSize:   GetDefaultServeOptionInt("FIRECRACKER_SCRATCH_SIZE", 1024),   //nolint:gomnd
+		DefaultMemory: GetDefaultServeOptionInt("FIRECRACKER_DEFAULT_MEMORY", 1024), //nolint:gomnd
+	}
+}
+
+func AddExecutorCliFlags(cmd *cobra.Command, executorType *string, containerOptions *container.ContainerExecutorOptions, kubernetesOptions *kubernetes.KubernetesExecutorOptions, firecrackerOptions *firecracker.FirecrackerExecutorOptions) {
+	cmd.PersistentFlags().StringVar(
+		executorType, "executor", *executorType,
+		`What runs the jobs, one of "bacalhau", "podman", "containerd", "kubernetes" or "firecracker", podman and containerd run them on this machine without docker or bacalhau, kubernetes as Jobs in a cluster and firecracker in a microvm each (EXECUTOR_TYPE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&containerOptions.Binary, "container-binary", containerOptions.Binary,
+		`The podman or nerdctl binary to run jobs with, the one on the PATH when empty (CONTAINER_BINARY).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&containerOptions.Namespace, "containerd-namespace", containerOptions.Namespace,
+		`The containerd namespace to run jobs in (CONTAINERD_NAMESPACE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&containerOptions.IPFSGateway, "container-ipfs-gateway", containerOptions.IPFSGateway,
+		`The ipfs gateway to download the ipfs inputs of jobs from (CONTAINER_IPFS_GATEWAY).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&containerOptions.EnforceLimits, "container-enforce-limits", containerOptions.EnforceLimits,
+		`Run jobs with the cpu and memory limits of their deal rather than the module's own (CONTAINER_ENFORCE_LIMITS).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&kubernetesOptions.Kubectl, "kubectl-binary", kubernetesOptions.Kubectl,
+		`The kubectl binary to run jobs with, the one on the PATH when empty (KUBECTL_BINARY).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&kubernetesOptions.Kubeconfig, "kubernetes-kubeconfig", kubernetesOptions.Kubeconfig,
+		`The kubeconfig of the cluster to run jobs in, kubectl's default or the pod's service account when empty (KUBERNETES_KUBECONFIG).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&kubernetesOptions.Namespace, "kubernetes-namespace", kubernetesOptions.Namespace,
+		`The namespace to run jobs in (KUBERNETES_NAMESPACE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&kubernetesOptions.HelperImage, "kubernetes-helper-image", kubernetesOptions.HelperImage,
+		`The image with sh, wget and tar that downloads job inputs and holds their outputs (KUBERNETES_HELPER_IMAGE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&kubernetesOptions.IPFSGateway, "kubernetes-ipfs-gateway", kubernetesOptions.IPFSGateway,
+		`The ipfs gateway job pods download their ipfs inputs from (KUBERNETES_IPFS_GATEWAY).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&kubernetesOptions.ImagePullSecrets, "kubernetes-image-pull-secrets", kubernetesOptions.ImagePullSecrets,
+		`The secrets in the namespace to pull private module images with (KUBERNETES_IMAGE_PULL_SECRETS).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.Firecracker, "firecracker-binary", firecrackerOptions.Firecracker,
+		`The firecracker binary to run jobs with, the one on the PATH when empty (FIRECRACKER_BINARY).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.Jailer, "firecracker-jailer", firecrackerOptions.Jailer,
+		`The jailer binary that starts firecracker, the one on the PATH when empty (FIRECRACKER_JAILER).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.Kernel, "firecracker-kernel", firecrackerOptions.Kernel,
+		`The uncompressed linux kernel the job vms boot (FIRECRACKER_KERNEL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.Init, "firecracker-init", firecrackerOptions.Init,
+		`A static build of cmd/firecracker-init that runs the job in the vm (FIRECRACKER_INIT).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.ChrootBase, "firecracker-chroot-base", firecrackerOptions.ChrootBase,
+		`The directory the jailer makes the chroot of each vm under (FIRECRACKER_CHROOT_BASE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&firecrackerOptions.UID, "firecracker-uid", firecrackerOptions.UID,
+		`The unprivileged user firecracker runs as in the jail (FIRECRACKER_UID).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&firecrackerOptions.GID, "firecracker-gid", firecrackerOptions.GID,
+		`The unprivileged group firecracker runs as in the jail (FIRECRACKER_GID).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.ImageTool, "firecracker-image-tool", firecrackerOptions.ImageTool,
+		`The docker or podman that module images are pulled and turned into vm disks with (FIRECRACKER_IMAGE_TOOL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&firecrackerOptions.IPFSGateway, "firecracker-ipfs-gateway", firecrackerOptions.IPFSGateway,
+		`The ipfs gateway to download the ipfs inputs of jobs from (FIRECRACKER_IPFS_GATEWAY).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&firecrackerOptions.ScratchSize, "firecracker-scratch-size", firecrackerOptions.ScratchSize,
+		`The megabytes of free disk a vm gets for the job to write to and for its outputs (FIRECRACKER_SCRATCH_SIZE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&firecrackerOptions.DefaultMemory, "firecracker-default-memory", firecrackerOptions.DefaultMemory,
+		`The megabytes of memory a vm gets when its deal does not say (FIRECRACKER_DEFAULT_MEMORY).`,
+	)
+}
+
+// the options of an executor are only checked when it runs the jobs
+func CheckExecutorOptions(executorType string, bacalhauOptions bacalhau.BacalhauExecutorOptions, containerOptions container.ContainerExecutorOptions, kubernetesOptions kubernetes.KubernetesExecutorOptions, firecrackerOptions firecracker.FirecrackerExecutorOptions) error {
+	switch executorType {
+	case "bacalhau":
+		return CheckBacalhauOptions(bacalhauOptions)
+	case "podman", "containerd":
+		if containerOptions.IPFSGateway == "" {
+			return fmt.Errorf("CONTAINER_IPFS_GATEWAY is required to run jobs with %s", executorType)
+		}
+		return nil
+	case "kubernetes":
+		if kubernetesOptions.Namespace == "" {
+			return fmt.Errorf("KUBERNETES_NAMESPACE is required to run jobs with kubernetes")
+		}
+		if kubernetesOptions.HelperImage == "" {
+			return fmt.Errorf("KUBERNETES_HELPER_IMAGE is required to run jobs with kubernetes")
+		}
+		if kubernetesOptions.IPFSGateway == "" {
+			return fmt.Errorf("KUBERNETES_IPFS_GATEWAY is required to run jobs with kubernetes")
+		}
+		return nil
+	case "firecracker":
+		if firecrackerOptions.Kernel == "" {
+			return fmt.Errorf("FIRECRACKER_KERNEL is required to run jobs with firecracker")
+		}
+		if firecrackerOptions.Init == "" {
+			return fmt.Errorf("FIRECRACKER_INIT is required to run jobs with firecracker")
+		}
+		if firecrackerOptions.ChrootBase == "" {
+			return fmt.Errorf("FIRECRACKER_CHROOT_BASE is required to run jobs with firecracker")
+		}
+		// the point of the jailer is that the vm does not run as root
+		if firecrackerOptions.UID <= 0 || firecrackerOptions.GID <= 0 {
+			return fmt.Errorf("FIRECRACKER_UID and FIRECRACKER_GID must be an unprivileged user and group to run jobs with firecracker")
+		}
+		if firecrackerOptions.IPFSGateway == "" {
+			return fmt.Errorf("FIRECRACKER_IPFS_GATEWAY is required to run jobs with firecracker")
+		}
+		return nil
+	default:
+		return fmt.Errorf("EXECUTOR_TYPE must be \"bacalhau\", \"podman\", \"containerd\", \"kubernetes\" or \"firecracker\"")
+	}
+}
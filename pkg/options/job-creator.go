@@ -2,6 +2,9 @@ package options
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/jobcreator"
@@ -11,10 +14,15 @@ import (
 
 func NewJobCreatorOptions() jobcreator.JobCreatorOptions {
 	options := jobcreator.JobCreatorOptions{
-		Offer:     GetDefaultJobCreatorOfferOptions(),
-		Web3:      GetDefaultWeb3Options(),
-		Mediation: GetDefaultJobCreatorMediationOptions(),
-		Telemetry: GetDefaultTelemetryOptions(),
+		Offer:            GetDefaultJobCreatorOfferOptions(),
+		Web3:             GetDefaultWeb3Options(),
+		Attestation:      GetDefaultAttestationOptions(),
+		Mediation:        GetDefaultJobCreatorMediationOptions(),
+		Approval:         GetDefaultJobCreatorApprovalOptions(),
+		Telemetry:        GetDefaultTelemetryOptions(),
+		ClientTLS:        GetDefaultClientTLSOptions(),
+		ClientRetry:      GetDefaultClientRetryOptions(),
+		ClientConnection: GetDefaultClientConnectionOptions(),
 	}
 	options.Web3.Service = system.JobCreatorService
 	return options
@@ -22,10 +30,23 @@ func NewJobCreatorOptions() jobcreator.JobCreatorOptions {
 
 func GetDefaultJobCreatorMediationOptions() jobcreator.JobCreatorMediationOptions {
 	return jobcreator.JobCreatorMediationOptions{
+		AlwaysCheckFirst:       GetDefaultServeOptionInt("MEDIATION_ALWAYS_CHECK_FIRST", 0),
 		CheckResultsPercentage: GetDefaultServeOptionInt("MEDIATION_CHANCE", 0),
 	}
 }
 
+func GetDefaultJobCreatorApprovalOptions() jobcreator.JobCreatorApprovalOptions {
+	return jobcreator.JobCreatorApprovalOptions{
+		InstructionPriceThreshold:  GetDefaultServeOptionUint64("APPROVAL_INSTRUCTION_PRICE_THRESHOLD", 0),
+		PaymentCollateralThreshold: GetDefaultServeOptionUint64("APPROVAL_PAYMENT_COLLATERAL_THRESHOLD", 0),
+		Approvers:                  GetDefaultServeOptionStringArray("APPROVAL_APPROVERS", []string{}),
+		NotifyURL:                  GetDefaultServeOptionString("APPROVAL_NOTIFY_URL", ""),
+		TTL:                        GetDefaultServeOptionInt("APPROVAL_TTL", 3600), //nolint:gomnd
+		Host:                       GetDefaultServeOptionString("APPROVAL_SERVER_HOST", "127.0.0.1"),
+		Port:                       GetDefaultServeOptionInt("APPROVAL_SERVER_PORT", 8090), //nolint:gomnd
+	}
+}
+
 func GetDefaultJobCreatorOfferOptions() jobcreator.JobCreatorOfferOptions {
 	return jobcreator.JobCreatorOfferOptions{
 		Module: GetDefaultModuleOptions(),
@@ -35,6 +56,26 @@ func GetDefaultJobCreatorOfferOptions() jobcreator.JobCreatorOfferOptions {
 		Timeouts: GetDefaultTimeoutOptions(),
 		Inputs:   map[string]string{},
 		Services: GetDefaultServicesOptions(),
+		// by default we only want resource offers that can run the job now
+		MaxDeferral:     GetDefaultServeOptionInt("OFFER_MAX_DEFERRAL", 0),
+		Duration:        GetDefaultServeOptionInt("OFFER_DURATION", 0),
+		Egress:          GetDefaultServeOptionInt("OFFER_EGRESS", 0),
+		RequirementSpec: GetDefaultServeOptionStringArray("OFFER_REQUIREMENTS", []string{}),
+		Requirements:    []data.AttributeRequirement{},
+		Locality: data.LocalityPreference{
+			Regions: GetDefaultServeOptionStringArray("OFFER_REGIONS", []string{}),
+			Strict:  GetDefaultServeOptionBool("OFFER_REGION_STRICT", false),
+		},
+		Schedule:     GetDefaultServeOptionString("OFFER_SCHEDULE", ""),
+		PaymentToken: GetDefaultServeOptionString("OFFER_PAYMENT_TOKEN", ""),
+		SecretSpec:   GetDefaultServeOptionStringArray("OFFER_SECRETS", []string{}),
+		Secrets:      map[string]string{},
+		// setting the types or measurements requires attestation too
+		RequireAttestation: GetDefaultServeOptionBool("OFFER_REQUIRE_ATTESTATION", false),
+		AttestationRequirement: data.AttestationRequirement{
+			Types:        GetDefaultServeOptionStringArray("OFFER_ATTESTATION_TYPES", []string{}),
+			Measurements: GetDefaultServeOptionStringArray("OFFER_ATTESTATION_MEASUREMENTS", []string{}),
+		},
 	}
 }
 
@@ -45,11 +86,96 @@ func AddJobCreatorMediationCliFlags(cmd *cobra.Command, mediationOptions *jobcre
 		mediationOptions.CheckResultsPercentage,
 		"The percentage chance we will check results",
 	)
+	cmd.PersistentFlags().IntVar(
+		&mediationOptions.AlwaysCheckFirst,
+		"mediation-always-check-first",
+		mediationOptions.AlwaysCheckFirst,
+		"Always check results for the first N deals with a new resource provider",
+	)
+}
+
+func AddJobCreatorApprovalCliFlags(cmd *cobra.Command, approvalOptions *jobcreator.JobCreatorApprovalOptions) {
+	cmd.PersistentFlags().Uint64Var(
+		&approvalOptions.InstructionPriceThreshold, "approval-instruction-price-threshold", approvalOptions.InstructionPriceThreshold,
+		`Hold offers with an instruction price above this for approval, 0 disables (APPROVAL_INSTRUCTION_PRICE_THRESHOLD).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&approvalOptions.PaymentCollateralThreshold, "approval-payment-collateral-threshold", approvalOptions.PaymentCollateralThreshold,
+		`Hold offers with a payment collateral above this for approval, 0 disables (APPROVAL_PAYMENT_COLLATERAL_THRESHOLD).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&approvalOptions.Approvers, "approval-approvers", approvalOptions.Approvers,
+		`The addresses allowed to approve or reject held offers (APPROVAL_APPROVERS).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&approvalOptions.NotifyURL, "approval-notify-url", approvalOptions.NotifyURL,
+		`A webhook that is sent held offers to notify approvers (APPROVAL_NOTIFY_URL).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&approvalOptions.TTL, "approval-ttl", approvalOptions.TTL,
+		`The seconds a held offer waits for a decision before it is given up on, 0 waits forever (APPROVAL_TTL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&approvalOptions.Host, "approval-server-host", approvalOptions.Host,
+		`The host to bind the approval api to (APPROVAL_SERVER_HOST).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&approvalOptions.Port, "approval-server-port", approvalOptions.Port,
+		`The port to bind the approval api to (APPROVAL_SERVER_PORT).`,
+	)
 }
 
 func AddJobCreatorOfferCliFlags(cmd *cobra.Command, offerOptions *jobcreator.JobCreatorOfferOptions) {
 	// add the inputs that we will merge into the module template file
 	cmd.PersistentFlags().StringToStringVarP(&offerOptions.Inputs, "input", "i", offerOptions.Inputs, "Input key-value pairs")
+	cmd.PersistentFlags().IntVar(
+		&offerOptions.MaxDeferral, "offer-max-deferral", offerOptions.MaxDeferral,
+		`How many seconds to wait for a resource provider that is not available yet (OFFER_MAX_DEFERRAL).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&offerOptions.Duration, "offer-duration", offerOptions.Duration,
+		`How many seconds the job runs for, resource providers that price by the hour charge for this long and stop the job after it, 0 is their submit results timeout (OFFER_DURATION).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&offerOptions.Egress, "offer-egress", offerOptions.Egress,
+		`How many MB the job sends out, for resource providers that price egress (OFFER_EGRESS).`,
+	)
+	cmd.PersistentFlags().StringArrayVar(
+		&offerOptions.RequirementSpec, "offer-requirement", offerOptions.RequirementSpec,
+		`Conditions on resource offer attributes e.g. "infiniband" or "gpu.count>=2" (OFFER_REQUIREMENTS).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&offerOptions.Locality.Regions, "offer-regions", offerOptions.Locality.Regions,
+		`The regions we prefer to run in, e.g. where the input data lives (OFFER_REGIONS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.Locality.Strict, "offer-region-strict", offerOptions.Locality.Strict,
+		`Only match resource offers in one of the preferred regions (OFFER_REGION_STRICT).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&offerOptions.Schedule, "schedule", offerOptions.Schedule,
+		`Run the job again on a cron schedule e.g. "0 * * * *" (OFFER_SCHEDULE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&offerOptions.PaymentToken, "offer-payment-token", offerOptions.PaymentToken,
+		`The ERC-20 token to pay in, empty for the network's token (OFFER_PAYMENT_TOKEN).`,
+	)
+	cmd.PersistentFlags().StringArrayVar(
+		&offerOptions.SecretSpec, "secret", offerOptions.SecretSpec,
+		`A secret to give the job as an env var, NAME reads $NAME and NAME=ENV_VAR reads $ENV_VAR, it is only sent encrypted to the matched resource provider (OFFER_SECRETS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.RequireAttestation, "offer-require-attestation", offerOptions.RequireAttestation,
+		`Only run on resource providers in a SEV-SNP or TDX confidential VM whose attestation checks out against ATTESTATION_ROOTS (OFFER_REQUIRE_ATTESTATION).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&offerOptions.AttestationRequirement.Types, "offer-attestation-types", offerOptions.AttestationRequirement.Types,
+		`The confidential VM types to accept, sev-snp or tdx (OFFER_ATTESTATION_TYPES).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&offerOptions.AttestationRequirement.Measurements, "offer-attestation-measurements", offerOptions.AttestationRequirement.Measurements,
+		`The hex launch measurements of the VM images to accept, the SEV-SNP MEASUREMENT or TDX MRTD (OFFER_ATTESTATION_MEASUREMENTS).`,
+	)
 
 	AddPricingModeCliFlags(cmd, &offerOptions.Mode)
 	AddPricingCliFlags(cmd, &offerOptions.Pricing)
@@ -61,9 +187,14 @@ func AddJobCreatorOfferCliFlags(cmd *cobra.Command, offerOptions *jobcreator.Job
 
 func AddJobCreatorCliFlags(cmd *cobra.Command, options *jobcreator.JobCreatorOptions) {
 	AddJobCreatorMediationCliFlags(cmd, &options.Mediation)
+	AddJobCreatorApprovalCliFlags(cmd, &options.Approval)
 	AddWeb3CliFlags(cmd, &options.Web3)
+	AddAttestationCliFlags(cmd, &options.Attestation)
 	AddJobCreatorOfferCliFlags(cmd, &options.Offer)
 	AddTelemetryCliFlags(cmd, &options.Telemetry)
+	AddClientTLSCliFlags(cmd, &options.ClientTLS)
+	AddClientRetryCliFlags(cmd, &options.ClientRetry)
+	AddClientConnectionCliFlags(cmd, &options.ClientConnection)
 }
 
 func CheckJobCreatorOptions(options jobcreator.JobCreatorOptions) error {
@@ -83,10 +214,54 @@ func CheckJobCreatorOptions(options jobcreator.JobCreatorOptions) error {
 	if err != nil {
 		return err
 	}
+	err = CheckClientRetryOptions(options.ClientRetry)
+	if err != nil {
+		return err
+	}
+	err = CheckClientConnectionOptions(options.ClientConnection)
+	if err != nil {
+		return err
+	}
 
 	if options.Mediation.CheckResultsPercentage < 0 || options.Mediation.CheckResultsPercentage > 100 {
 		return fmt.Errorf("mediation-chance must be between 0 and 100")
 	}
+	if options.Mediation.AlwaysCheckFirst < 0 {
+		return fmt.Errorf("mediation-always-check-first cannot be negative")
+	}
+	if options.Offer.Duration < 0 {
+		return fmt.Errorf("OFFER_DURATION cannot be negative")
+	}
+	if options.Offer.Egress < 0 {
+		return fmt.Errorf("OFFER_EGRESS cannot be negative")
+	}
+	if options.Offer.RequireAttestation {
+		err = CheckAttestationRequirement(options.Offer.AttestationRequirement)
+		if err != nil {
+			return err
+		}
+		if options.Attestation.Roots == "" {
+			return fmt.Errorf("ATTESTATION_ROOTS is required to check the attestation of resource providers")
+		}
+	}
+	if options.Offer.Schedule != "" {
+		_, err = data.ParseCronSchedule(options.Offer.Schedule)
+		if err != nil {
+			return fmt.Errorf("OFFER_SCHEDULE %s", err.Error())
+		}
+	}
+
+	return CheckJobCreatorApprovalOptions(options.Approval)
+}
+
+func CheckJobCreatorApprovalOptions(options jobcreator.JobCreatorApprovalOptions) error {
+	thresholdSet := options.InstructionPriceThreshold > 0 || options.PaymentCollateralThreshold > 0
+	if thresholdSet && len(options.Approvers) == 0 {
+		return fmt.Errorf("APPROVAL_APPROVERS is required when an approval threshold is set")
+	}
+	if options.TTL < 0 {
+		return fmt.Errorf("APPROVAL_TTL cannot be negative")
+	}
 
 	return nil
 }
@@ -125,6 +300,22 @@ func ProcessJobCreatorOptions(options jobcreator.JobCreatorOptions, args []strin
 	}
 	options.Offer.Target = newTargetOptions
 
+	newRequirements, err := ProcessRequirementOptions(options.Offer.RequirementSpec)
+	if err != nil {
+		return options, err
+	}
+	options.Offer.Requirements = newRequirements
+
+	newSecrets, err := ProcessSecretOptions(options.Offer.SecretSpec)
+	if err != nil {
+		return options, err
+	}
+	options.Offer.Secrets = newSecrets
+
+	if len(options.Offer.AttestationRequirement.Types) > 0 || len(options.Offer.AttestationRequirement.Measurements) > 0 {
+		options.Offer.RequireAttestation = true
+	}
+
 	newTelemetryOptions, err := ProcessTelemetryOptions(options.Telemetry, network)
 	if err != nil {
 		return options, err
@@ -134,6 +325,41 @@ func ProcessJobCreatorOptions(options jobcreator.JobCreatorOptions, args []strin
 	return options, CheckJobCreatorOptions(options)
 }
 
+func ProcessRequirementOptions(specs []string) ([]data.AttributeRequirement, error) {
+	requirements := []data.AttributeRequirement{}
+	for _, spec := range specs {
+		requirement, err := data.ParseAttributeRequirement(spec)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reads each secret from the environment, a secret that is not set is an
+// error rather than the job running without it
+func ProcessSecretOptions(specs []string) (map[string]string, error) {
+	secrets := map[string]string{}
+	for _, spec := range specs {
+		name, envName, found := strings.Cut(strings.TrimSpace(spec), "=")
+		if !found {
+			envName = name
+		}
+		if !secretNamePattern.MatchString(name) || !secretNamePattern.MatchString(envName) {
+			return nil, fmt.Errorf("secret %q should be NAME or NAME=ENV_VAR", spec)
+		}
+		value := os.Getenv(envName)
+		if value == "" {
+			return nil, fmt.Errorf("secret %s is read from $%s which is not set", name, envName)
+		}
+		secrets[name] = value
+	}
+	return secrets, nil
+}
+
 func ProcessOnChainJobCreatorOptions(options jobcreator.JobCreatorOptions, args []string, network string) (jobcreator.JobCreatorOptions, error) {
 	newWeb3Options, err := ProcessWeb3Options(options.Web3, network)
 	if err != nil {
@@ -165,8 +391,13 @@ func ProcessOnChainJobCreatorOptions(options jobcreator.JobCreatorOptions, args
 	if err != nil {
 		return options, err
 	}
+	err = CheckJobCreatorApprovalOptions(options.Approval)
+	if err != nil {
+		return options, err
+	}
 
 	options.Mediation.CheckResultsPercentage = 0
+	options.Mediation.AlwaysCheckFirst = 0
 
 	return options, nil
 }
@@ -0,0 +1,82 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/matcher"
+	"github.com/spf13/cobra"
+)
+
+func GetDefaultMatcherOptions() matcher.MatcherOptions {
+	return matcher.MatcherOptions{
+		Mode:             matcher.MatchMode(GetDefaultServeOptionString("MATCHER_MODE", string(matcher.ImmediateMatch))),
+		AuctionWindow:    GetDefaultServeOptionInt("MATCHER_AUCTION_WINDOW", 5),
+		FairnessPolicy:   matcher.FairnessPolicy(GetDefaultServeOptionString("MATCHER_FAIRNESS_POLICY", string(matcher.FirstComeFirstServed))),
+		MediatorPolicy:   matcher.MediatorPolicy(GetDefaultServeOptionString("MATCHER_MEDIATOR_POLICY", string(matcher.AllMediators))),
+		MediatorFees:     GetDefaultServeOptionInt64Map("MATCHER_MEDIATOR_FEES", map[string]int64{}),
+		AttributeSchema:  GetDefaultServeOptionStringMap("MATCHER_ATTRIBUTE_SCHEMA", map[string]string{}),
+		MaxProviderShare: GetDefaultServeOptionInt("MATCHER_MAX_PROVIDER_SHARE", 0),
+		RegionPenalty:    GetDefaultServeOptionInt("MATCHER_REGION_PENALTY", 20), //nolint:gomnd
+	}
+}
+
+func AddMatcherCliFlags(cmd *cobra.Command, matcherOptions *matcher.MatcherOptions) {
+	cmd.PersistentFlags().StringVar(
+		(*string)(&matcherOptions.Mode), "matcher-mode", string(matcherOptions.Mode),
+		`How resource offers are picked for a job offer, one of "immediate" or "auction" (MATCHER_MODE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&matcherOptions.AuctionWindow, "matcher-auction-window", matcherOptions.AuctionWindow,
+		`The time in seconds an auction collects bids for a job offer (MATCHER_AUCTION_WINDOW).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		(*string)(&matcherOptions.FairnessPolicy), "matcher-fairness-policy", string(matcherOptions.FairnessPolicy),
+		`The order job creators are matched in, one of "fifo" or "round_robin" (MATCHER_FAIRNESS_POLICY).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		(*string)(&matcherOptions.MediatorPolicy), "matcher-mediator-policy", string(matcherOptions.MediatorPolicy),
+		`How a deal's mediator is chosen, one of "all", "random", "round_robin", "reputation" or "cheapest" (MATCHER_MEDIATOR_POLICY).`,
+	)
+	cmd.PersistentFlags().StringToInt64Var(
+		&matcherOptions.MediatorFees, "matcher-mediator-fees", matcherOptions.MediatorFees,
+		`The fee each mediator charges as address=fee pairs, used by the cheapest policy (MATCHER_MEDIATOR_FEES).`,
+	)
+	cmd.PersistentFlags().StringToStringVar(
+		&matcherOptions.AttributeSchema, "matcher-attribute-schema", matcherOptions.AttributeSchema,
+		`The resource offer attributes the solver accepts as name=type pairs, types are "string", "number" or "bool" (MATCHER_ATTRIBUTE_SCHEMA).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&matcherOptions.MaxProviderShare, "matcher-max-provider-share", matcherOptions.MaxProviderShare,
+		`The largest percentage of concurrent deals a single resource provider can hold, 0 disables the cap and it only applies once there are enough providers to keep under it (MATCHER_MAX_PROVIDER_SHARE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&matcherOptions.RegionPenalty, "matcher-region-penalty", matcherOptions.RegionPenalty,
+		`The percentage added to an out of region resource offer's price when ranking it for a job offer with a region preference (MATCHER_REGION_PENALTY).`,
+	)
+}
+
+func CheckMatcherOptions(options matcher.MatcherOptions) error {
+	if options.Mode != matcher.ImmediateMatch && options.Mode != matcher.AuctionMatch {
+		return fmt.Errorf("MATCHER_MODE must be \"immediate\" or \"auction\"")
+	}
+	if options.Mode == matcher.AuctionMatch && options.AuctionWindow <= 0 {
+		return fmt.Errorf("MATCHER_AUCTION_WINDOW must be greater than zero in auction mode")
+	}
+	if options.FairnessPolicy != matcher.FirstComeFirstServed && options.FairnessPolicy != matcher.RoundRobin {
+		return fmt.Errorf("MATCHER_FAIRNESS_POLICY must be \"fifo\" or \"round_robin\"")
+	}
+	if !matcher.IsMediatorPolicy(options.MediatorPolicy) {
+		return fmt.Errorf("MATCHER_MEDIATOR_POLICY %q is not a known mediator policy", options.MediatorPolicy)
+	}
+	if options.MaxProviderShare < 0 || options.MaxProviderShare > 100 {
+		return fmt.Errorf("MATCHER_MAX_PROVIDER_SHARE must be between 0 and 100")
+	}
+	if options.RegionPenalty < 0 {
+		return fmt.Errorf("MATCHER_REGION_PENALTY cannot be negative")
+	}
+	if _, err := data.NewAttributeSchema(options.AttributeSchema); err != nil {
+		return fmt.Errorf("MATCHER_ATTRIBUTE_SCHEMA is invalid: %s", err.Error())
+	}
+	return nil
+}
@@ -10,20 +10,33 @@ import (
 
 func NewMediatorOptions() mediator.MediatorOptions {
 	options := mediator.MediatorOptions{
-		Bacalhau: GetDefaultBacalhauOptions(),
-		Web3:     GetDefaultWeb3Options(),
-		Services: GetDefaultServicesOptions(),
-		IPFS:     GetDefaultIPFSOptions(),
+		Executor:         GetDefaultExecutorType(),
+		Bacalhau:         GetDefaultBacalhauOptions(),
+		Container:        GetDefaultContainerOptions(),
+		Kubernetes:       GetDefaultKubernetesOptions(),
+		Firecracker:      GetDefaultFirecrackerOptions(),
+		Attestation:      GetDefaultAttestationOptions(),
+		Web3:             GetDefaultWeb3Options(),
+		Services:         GetDefaultServicesOptions(),
+		IPFS:             GetDefaultIPFSOptions(),
+		ClientTLS:        GetDefaultClientTLSOptions(),
+		ClientRetry:      GetDefaultClientRetryOptions(),
+		ClientConnection: GetDefaultClientConnectionOptions(),
 	}
 	options.Web3.Service = system.MediatorService
 	return options
 }
 
 func AddMediatorCliFlags(cmd *cobra.Command, options *mediator.MediatorOptions) {
+	AddExecutorCliFlags(cmd, &options.Executor, &options.Container, &options.Kubernetes, &options.Firecracker)
 	AddBacalhauCliFlags(cmd, &options.Bacalhau)
 	AddWeb3CliFlags(cmd, &options.Web3)
+	AddAttestationCliFlags(cmd, &options.Attestation)
 	AddServicesCliFlags(cmd, &options.Services)
 	AddIPFSCliFlags(cmd, &options.IPFS)
+	AddClientTLSCliFlags(cmd, &options.ClientTLS)
+	AddClientRetryCliFlags(cmd, &options.ClientRetry)
+	AddClientConnectionCliFlags(cmd, &options.ClientConnection)
 }
 
 func CheckMediatorOptions(options mediator.MediatorOptions) error {
@@ -31,7 +44,7 @@ func CheckMediatorOptions(options mediator.MediatorOptions) error {
 	if err != nil {
 		return err
 	}
-	err = CheckBacalhauOptions(options.Bacalhau)
+	err = CheckExecutorOptions(options.Executor, options.Bacalhau, options.Container, options.Kubernetes, options.Firecracker)
 	if err != nil {
 		return err
 	}
@@ -39,6 +52,14 @@ func CheckMediatorOptions(options mediator.MediatorOptions) error {
 	if err != nil {
 		return err
 	}
+	err = CheckClientRetryOptions(options.ClientRetry)
+	if err != nil {
+		return err
+	}
+	err = CheckClientConnectionOptions(options.ClientConnection)
+	if err != nil {
+		return err
+	}
 	// only check the solver because we are the mediator
 	if options.Services.Solver == "" {
 		return fmt.Errorf("No solver service specified - please use SERVICE_SOLVER or --service-solver")
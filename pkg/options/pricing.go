@@ -47,3 +47,31 @@ func AddPricingCliFlags(cmd *cobra.Command, pricingConfig *data.DealPricing) {
 		`The mediation fee (PRICING_MEDIATION_FEE)`,
 	)
 }
+
+func GetDefaultResourcePricingOptions() data.ResourcePricing {
+	return data.ResourcePricing{
+		GPUHour:   GetDefaultServeOptionUint64("PRICING_GPU_HOUR", 0),
+		CPUHour:   GetDefaultServeOptionUint64("PRICING_CPU_HOUR", 0),
+		RAMGBHour: GetDefaultServeOptionUint64("PRICING_RAM_GB_HOUR", 0),
+		EgressGB:  GetDefaultServeOptionUint64("PRICING_EGRESS_GB", 0),
+	}
+}
+
+func AddResourcePricingCliFlags(cmd *cobra.Command, pricingConfig *data.ResourcePricing) {
+	cmd.PersistentFlags().Uint64Var(
+		&pricingConfig.GPUHour, "pricing-gpu-hour", pricingConfig.GPUHour,
+		`The price of a whole GPU for an hour of the job's duration, added to the instruction price (PRICING_GPU_HOUR).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&pricingConfig.CPUHour, "pricing-cpu-hour", pricingConfig.CPUHour,
+		`The price of a whole CPU for an hour of the job's duration, added to the instruction price (PRICING_CPU_HOUR).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&pricingConfig.RAMGBHour, "pricing-ram-gb-hour", pricingConfig.RAMGBHour,
+		`The price of a GB of RAM for an hour of the job's duration, added to the instruction price (PRICING_RAM_GB_HOUR).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&pricingConfig.EgressGB, "pricing-egress-gb", pricingConfig.EgressGB,
+		`The price of each GB the job says it sends out, added to the instruction price (PRICING_EGRESS_GB).`,
+	)
+}
@@ -3,21 +3,34 @@ package options
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/resourceprovider"
 	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
 	"github.com/spf13/cobra"
 )
 
 func NewResourceProviderOptions() resourceprovider.ResourceProviderOptions {
 	options := resourceprovider.ResourceProviderOptions{
-		Bacalhau:  GetDefaultBacalhauOptions(),
-		Offers:    GetDefaultResourceProviderOfferOptions(),
-		Web3:      GetDefaultWeb3Options(),
-		Pow:       GetDefaultResourceProviderPowOptions(),
-		IPFS:      GetDefaultIPFSOptions(),
-		Telemetry: GetDefaultTelemetryOptions(),
+		Executor:         GetDefaultExecutorType(),
+		Bacalhau:         GetDefaultBacalhauOptions(),
+		Container:        GetDefaultContainerOptions(),
+		Kubernetes:       GetDefaultKubernetesOptions(),
+		Firecracker:      GetDefaultFirecrackerOptions(),
+		Offers:           GetDefaultResourceProviderOfferOptions(),
+		Jobs:             GetDefaultResourceProviderJobOptions(),
+		Web3:             GetDefaultWeb3Options(),
+		Pow:              GetDefaultResourceProviderPowOptions(),
+		Benchmark:        GetDefaultResourceProviderBenchmarkOptions(),
+		IPFS:             GetDefaultIPFSOptions(),
+		Telemetry:        GetDefaultTelemetryOptions(),
+		ClientTLS:        GetDefaultClientTLSOptions(),
+		ClientRetry:      GetDefaultClientRetryOptions(),
+		ClientConnection: GetDefaultClientConnectionOptions(),
 	}
 	options.Web3.Service = system.ResourceProviderService
 	return options
@@ -34,6 +47,22 @@ func GetDefaultResourceProviderPowOptions() resourceprovider.ResourceProviderPow
 	}
 }
 
+func GetDefaultResourceProviderBenchmarkOptions() resourceprovider.ResourceProviderBenchmarkOptions {
+	return resourceprovider.ResourceProviderBenchmarkOptions{
+		Path:     GetDefaultServeOptionString("BENCHMARK_PATH", system.GetDataDir("benchmark.json")),
+		Duration: GetDefaultServeOptionInt("BENCHMARK_DURATION", 10), //nolint:gomnd
+		DiskMB:   GetDefaultServeOptionInt("BENCHMARK_DISK_MB", 256), //nolint:gomnd
+		GPUImage: GetDefaultServeOptionString("BENCHMARK_GPU_IMAGE", "nvcr.io/nvidia/k8s/cuda-sample:nbody"),
+	}
+}
+
+func GetDefaultResourceProviderJobOptions() resourceprovider.ResourceProviderJobOptions {
+	return resourceprovider.ResourceProviderJobOptions{
+		MaxConcurrentJobs: GetDefaultServeOptionInt("MAX_CONCURRENT_JOBS", 0),
+		ExecutionTimeout:  GetDefaultServeOptionInt("JOB_EXECUTION_TIMEOUT", 0),
+	}
+}
+
 func GetDefaultResourceProviderOfferOptions() resourceprovider.ResourceProviderOfferOptions {
 	return resourceprovider.ResourceProviderOfferOptions{
 		// by default let's offer 1 CPU, 0 GPU and 1GB RAM
@@ -43,11 +72,14 @@ func GetDefaultResourceProviderOfferOptions() resourceprovider.ResourceProviderO
 			RAM: GetDefaultServeOptionInt("OFFER_RAM", 1024), //nolint:gomnd
 		},
 		OfferCount: GetDefaultServeOptionInt("OFFER_COUNT", 1), //nolint:gomnd
-		// this can be populated by a config file
-		Specs: []data.MachineSpec{},
+		// this is populated from the flags above or OFFER_TEMPLATES
+		Specs:         []data.MachineSpec{},
+		TemplatesPath: GetDefaultServeOptionString("OFFER_TEMPLATES", ""),
+		Templates:     []resourceprovider.OfferTemplate{},
 		// if an RP wants to only run certain modules they list them here
 		// XXX SECURITY: enforce that they are specified by CID
-		Modules: GetDefaultServeOptionStringArray("OFFER_MODULES", []string{}),
+		Modules:       GetDefaultServeOptionStringArray("OFFER_MODULES", []string{}),
+		DeniedModules: GetDefaultServeOptionStringArray("OFFER_DENIED_MODULES", []string{}),
 		// this is the default pricing mode for an RP
 		Mode: GetDefaultPricingMode(data.FixedPrice),
 		// this is the default pricing for a module unless it has a specific price
@@ -57,7 +89,48 @@ func GetDefaultResourceProviderOfferOptions() resourceprovider.ResourceProviderO
 		ModulePricing:  map[string]data.DealPricing{},
 		ModuleTimeouts: map[string]data.DealTimeouts{},
 		Services:       GetDefaultServicesOptions(),
+		// a semicolon separated list because the windows themselves contain commas
+		AvailabilitySpec: GetDefaultServeOptionAvailability("OFFER_AVAILABILITY"),
+		Availability:     []data.AvailabilityWindow{},
+		Packing:          GetDefaultServeOptionBool("OFFER_PACKING", false),
+		Attributes:       GetDefaultServeOptionStringMap("OFFER_ATTRIBUTES", map[string]string{}),
+		Region:           GetDefaultServeOptionString("OFFER_REGION", ""),
+		TokenPrices:      GetDefaultServeOptionStringMap("OFFER_TOKEN_PRICES", map[string]string{}),
+		TokenPricing:     map[string]data.DealPricing{},
+		ResourcePricing:  GetDefaultResourcePricingOptions(),
+		UseBenchmark:     GetDefaultServeOptionBool("OFFER_USE_BENCHMARK", false),
+		DetectGPUs:       GetDefaultServeOptionBool("OFFER_DETECT_GPUS", true),
+		GPUSet:           os.Getenv("OFFER_GPU") != "",
+		PricingStrategy:  GetDefaultResourceProviderPricingStrategyOptions(),
+		AcceptSecrets:    GetDefaultServeOptionBool("OFFER_ACCEPT_SECRETS", true),
+		SecretsKeyPath:   GetDefaultServeOptionString("SECRETS_KEY_PATH", system.GetDataDir("secrets.key")),
+		Attestation:      GetDefaultServeOptionBool("OFFER_ATTESTATION", false),
+		// the VCEK, ASK and ARK from the AMD KDS
+		AttestationCertificates: GetDefaultServeOptionString("OFFER_ATTESTATION_CERTIFICATES", ""),
+	}
+}
+
+func GetDefaultResourceProviderPricingStrategyOptions() resourceprovider.ResourceProviderPricingStrategyOptions {
+	return resourceprovider.ResourceProviderPricingStrategyOptions{
+		Strategy:          GetDefaultServeOptionString("PRICING_STRATEGY", resourceprovider.PricingStatic),
+		UtilizationMarkup: GetDefaultServeOptionInt("PRICING_UTILIZATION_MARKUP", 100), //nolint:gomnd
+		// semicolon separated like the availability windows
+		TimeOfDaySpec:   GetDefaultServeOptionAvailability("PRICING_TIME_OF_DAY"),
+		TimeOfDay:       []resourceprovider.PricedWindow{},
+		PegPrice:        GetDefaultServeOptionFloat64("PRICING_PEG_PRICE", 0),
+		TokenPriceURL:   GetDefaultServeOptionString("PRICING_TOKEN_PRICE_URL", ""),
+		TokenPriceField: GetDefaultServeOptionString("PRICING_TOKEN_PRICE_FIELD", ""),
+	}
+}
+
+func GetDefaultServeOptionAvailability(envName string) []string {
+	windows := []string{}
+	for _, window := range strings.Split(os.Getenv(envName), ";") {
+		if strings.TrimSpace(window) != "" {
+			windows = append(windows, strings.TrimSpace(window))
+		}
 	}
+	return windows
 }
 
 func AddResourceProviderOfferCliFlags(cmd *cobra.Command, offerOptions *resourceprovider.ResourceProviderOfferOptions) {
@@ -77,16 +150,127 @@ func AddResourceProviderOfferCliFlags(cmd *cobra.Command, offerOptions *resource
 		&offerOptions.OfferCount, "offer-count", offerOptions.OfferCount,
 		`How many machines will we offer using the cpu, ram and gpu settings (OFFER_COUNT).`,
 	)
+	cmd.PersistentFlags().StringVar(
+		&offerOptions.TemplatesPath, "offer-templates", offerOptions.TemplatesPath,
+		`A toml file of [[offer]] templates, each with its own spec, count, pricing and modules, to offer instead of the cpu, ram and gpu settings (OFFER_TEMPLATES).`,
+	)
 	cmd.PersistentFlags().StringArrayVar(
 		&offerOptions.Modules, "offer-modules", offerOptions.Modules,
-		`The modules you are willing to run (OFFER_MODULES).`,
+		`The modules you are willing to run as module IDs, repos or repo@hash, any of which can be a pattern like "https://github.com/acme/*" (OFFER_MODULES).`,
+	)
+	cmd.PersistentFlags().StringArrayVar(
+		&offerOptions.DeniedModules, "offer-denied-modules", offerOptions.DeniedModules,
+		`The modules you will not run even when OFFER_MODULES allows them, written the same way (OFFER_DENIED_MODULES).`,
+	)
+	cmd.PersistentFlags().StringArrayVar(
+		&offerOptions.AvailabilitySpec, "offer-availability", offerOptions.AvailabilitySpec,
+		`Recurring windows the offer is available in e.g. "sat,sun 00:00-24:00 Europe/London" (OFFER_AVAILABILITY).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.Packing, "offer-packing", offerOptions.Packing,
+		`Offer each machine's total capacity so several jobs can run on it at once, each GPU job on devices of its own (OFFER_PACKING).`,
+	)
+	cmd.PersistentFlags().StringToStringVar(
+		&offerOptions.Attributes, "offer-attribute", offerOptions.Attributes,
+		`Capabilities beyond the machine spec as name=value pairs e.g. infiniband=true (OFFER_ATTRIBUTES).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&offerOptions.Region, "offer-region", offerOptions.Region,
+		`The region the machines are in e.g. eu-west (OFFER_REGION).`,
+	)
+	cmd.PersistentFlags().StringToStringVar(
+		&offerOptions.TokenPrices, "offer-token-price", offerOptions.TokenPrices,
+		`The instruction price to charge in other ERC-20 tokens as token=price pairs, the collateral is the same as the default pricing (OFFER_TOKEN_PRICES).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.DetectGPUs, "offer-detect-gpus", offerOptions.DetectGPUs,
+		`Detect the NVIDIA GPUs with nvidia-smi at startup and list their model, memory, driver and CUDA version in the offers, they are offered in full unless OFFER_GPU is set (OFFER_DETECT_GPUS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.AcceptSecrets, "offer-accept-secrets", offerOptions.AcceptSecrets,
+		`Take jobs that need secrets, they are sent encrypted to a key only this resource provider has (OFFER_ACCEPT_SECRETS).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&offerOptions.SecretsKeyPath, "secrets-key-path", offerOptions.SecretsKeyPath,
+		`The file of the key job secrets are encrypted to, it is made if it does not exist (SECRETS_KEY_PATH).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.Attestation, "offer-attestation", offerOptions.Attestation,
+		`Put a quote from the SEV-SNP or TDX confidential VM we run in on the offers so we can take jobs that require attestation (OFFER_ATTESTATION).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&offerOptions.AttestationCertificates, "offer-attestation-certificates", offerOptions.AttestationCertificates,
+		`A PEM file of the VCEK, ASK and ARK for a SEV-SNP host that does not give them with the report (OFFER_ATTESTATION_CERTIFICATES).`,
 	)
 	AddPricingModeCliFlags(cmd, &offerOptions.Mode)
 	AddPricingCliFlags(cmd, &offerOptions.DefaultPricing)
+	AddResourcePricingCliFlags(cmd, &offerOptions.ResourcePricing)
+	cmd.PersistentFlags().BoolVar(
+		&offerOptions.UseBenchmark, "offer-use-benchmark", offerOptions.UseBenchmark,
+		`Offer the cpu, gpu and ram the last resource-provider benchmark found, with its results as attributes and the resource prices scaled by them (OFFER_USE_BENCHMARK).`,
+	)
+	AddResourceProviderPricingStrategyCliFlags(cmd, &offerOptions.PricingStrategy)
 	AddTimeoutCliFlags(cmd, &offerOptions.DefaultTimeouts)
 	AddServicesCliFlags(cmd, &offerOptions.Services)
 }
 
+func AddResourceProviderPricingStrategyCliFlags(cmd *cobra.Command, strategyOptions *resourceprovider.ResourceProviderPricingStrategyOptions) {
+	cmd.PersistentFlags().StringVar(
+		&strategyOptions.Strategy, "pricing-strategy", strategyOptions.Strategy,
+		fmt.Sprintf(`How the instruction price is worked out each time an offer is posted, one of %s (PRICING_STRATEGY).`, strings.Join(resourceprovider.PricingStrategies, ", ")),
+	)
+	cmd.PersistentFlags().IntVar(
+		&strategyOptions.UtilizationMarkup, "pricing-utilization-markup", strategyOptions.UtilizationMarkup,
+		`The percent the utilization strategy adds to the instruction price when every job slot is busy (PRICING_UTILIZATION_MARKUP).`,
+	)
+	cmd.PersistentFlags().StringArrayVar(
+		&strategyOptions.TimeOfDaySpec, "pricing-time-of-day", strategyOptions.TimeOfDaySpec,
+		`Windows the time-of-day strategy charges a percent of the instruction price in e.g. "mon,tue,wed,thu,fri 09:00-17:00 Europe/London=150" (PRICING_TIME_OF_DAY).`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&strategyOptions.PegPrice, "pricing-peg-price", strategyOptions.PegPrice,
+		`What the token-peg strategy keeps an instruction costing in the currency the token price is quoted in (PRICING_PEG_PRICE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&strategyOptions.TokenPriceURL, "pricing-token-price-url", strategyOptions.TokenPriceURL,
+		`A url returning the token price as json for the token-peg strategy (PRICING_TOKEN_PRICE_URL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&strategyOptions.TokenPriceField, "pricing-token-price-field", strategyOptions.TokenPriceField,
+		`The dot separated path to the price in the token price json e.g. lilypad.usd (PRICING_TOKEN_PRICE_FIELD).`,
+	)
+}
+
+func AddResourceProviderJobCliFlags(cmd *cobra.Command, jobOptions *resourceprovider.ResourceProviderJobOptions) {
+	cmd.PersistentFlags().IntVar(
+		&jobOptions.MaxConcurrentJobs, "max-concurrent-jobs", jobOptions.MaxConcurrentJobs,
+		`How many jobs to run at once, the others wait in a queue for a free slot, 0 for no limit (MAX_CONCURRENT_JOBS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&jobOptions.ExecutionTimeout, "job-execution-timeout", jobOptions.ExecutionTimeout,
+		`The most seconds a job can run before it is killed and an errored result posted, jobs are always stopped in time to post a result before their deal's submit results timeout (JOB_EXECUTION_TIMEOUT).`,
+	)
+}
+
+func AddResourceProviderBenchmarkCliFlags(cmd *cobra.Command, options *resourceprovider.ResourceProviderBenchmarkOptions) {
+	cmd.PersistentFlags().StringVar(
+		&options.Path, "benchmark-path", options.Path,
+		`The file the benchmark results are written to and OFFER_USE_BENCHMARK reads (BENCHMARK_PATH).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&options.Duration, "benchmark-duration", options.Duration,
+		`How many seconds the cpu and memory benchmarks run for (BENCHMARK_DURATION).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&options.DiskMB, "benchmark-disk-mb", options.DiskMB,
+		`How many MB the disk benchmark writes (BENCHMARK_DISK_MB).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&options.GPUImage, "benchmark-gpu-image", options.GPUImage,
+		`The CUDA nbody sample image each GPU is benchmarked with using docker (BENCHMARK_GPU_IMAGE).`,
+	)
+}
+
 func AddResourceProviderPowCliFlags(cmd *cobra.Command, options *resourceprovider.ResourceProviderPowOptions) {
 	cmd.PersistentFlags().BoolVar(
 		&options.DisablePow, "disable-pow", options.DisablePow,
@@ -114,18 +298,37 @@ func AddResourceProviderPowCliFlags(cmd *cobra.Command, options *resourceprovide
 }
 
 func AddResourceProviderCliFlags(cmd *cobra.Command, options *resourceprovider.ResourceProviderOptions) {
+	AddExecutorCliFlags(cmd, &options.Executor, &options.Container, &options.Kubernetes, &options.Firecracker)
 	AddBacalhauCliFlags(cmd, &options.Bacalhau)
 	AddWeb3CliFlags(cmd, &options.Web3)
 	AddResourceProviderOfferCliFlags(cmd, &options.Offers)
+	AddResourceProviderJobCliFlags(cmd, &options.Jobs)
 	AddResourceProviderPowCliFlags(cmd, &options.Pow)
+	AddResourceProviderBenchmarkCliFlags(cmd, &options.Benchmark)
 	AddIPFSCliFlags(cmd, &options.IPFS)
 	AddTelemetryCliFlags(cmd, &options.Telemetry)
+	AddClientTLSCliFlags(cmd, &options.ClientTLS)
+	AddClientRetryCliFlags(cmd, &options.ClientRetry)
+	AddClientConnectionCliFlags(cmd, &options.ClientConnection)
 }
 
 func AddPowSignalCliFlags(cmd *cobra.Command, options *PowSignalOptions) {
 	AddWeb3CliFlags(cmd, &options.Web3)
 }
 
+func CheckResourceProviderBenchmarkOptions(options resourceprovider.ResourceProviderBenchmarkOptions) error {
+	if options.Path == "" {
+		return fmt.Errorf("BENCHMARK_PATH is required")
+	}
+	if options.Duration <= 0 {
+		return fmt.Errorf("BENCHMARK_DURATION has to be at least a second")
+	}
+	if options.DiskMB < 0 {
+		return fmt.Errorf("BENCHMARK_DISK_MB cannot be negative")
+	}
+	return nil
+}
+
 func CheckResourceProviderOfferOptions(options resourceprovider.ResourceProviderOfferOptions) error {
 	// loop over all specs and add up the total number of cpus
 	totalCPU := 0
@@ -137,6 +340,17 @@ func CheckResourceProviderOfferOptions(options resourceprovider.ResourceProvider
 		return fmt.Errorf("OFFER_CPU cannot be zero")
 	}
 
+	if err := data.CheckModulePatterns(options.Modules); err != nil {
+		return fmt.Errorf("OFFER_MODULES: %s", err.Error())
+	}
+	if err := data.CheckModulePatterns(options.DeniedModules); err != nil {
+		return fmt.Errorf("OFFER_DENIED_MODULES: %s", err.Error())
+	}
+
+	if options.AcceptSecrets && options.SecretsKeyPath == "" {
+		return fmt.Errorf("SECRETS_KEY_PATH is required to accept jobs with secrets")
+	}
+
 	// do the same for memory
 	totalRAM := 0
 	for _, spec := range options.Specs {
@@ -155,15 +369,32 @@ func CheckResourceProviderOptions(options resourceprovider.ResourceProviderOptio
 	if err != nil {
 		return err
 	}
+	// the pow miner is a separate binary that is handed the private key
+	if options.Web3.Signer != web3.SignerKey && !options.Pow.DisablePow {
+		return fmt.Errorf("DISABLE_POW has to be set when WEB3_SIGNER is %s, the pow miner needs a local private key", options.Web3.Signer)
+	}
 	err = CheckResourceProviderOfferOptions(options.Offers)
 	if err != nil {
 		return err
 	}
+	_, err = resourceprovider.NewPricingStrategy(options.Offers.PricingStrategy)
+	if err != nil {
+		return err
+	}
+	if options.Offers.PricingStrategy.UtilizationMarkup < 0 {
+		return fmt.Errorf("PRICING_UTILIZATION_MARKUP cannot be negative")
+	}
+	if options.Jobs.MaxConcurrentJobs < 0 {
+		return fmt.Errorf("MAX_CONCURRENT_JOBS cannot be negative")
+	}
+	if options.Jobs.ExecutionTimeout < 0 {
+		return fmt.Errorf("JOB_EXECUTION_TIMEOUT cannot be negative")
+	}
 	err = CheckServicesOptions(options.Offers.Services)
 	if err != nil {
 		return err
 	}
-	err = CheckBacalhauOptions(options.Bacalhau)
+	err = CheckExecutorOptions(options.Executor, options.Bacalhau, options.Container, options.Kubernetes, options.Firecracker)
 	if err != nil {
 		return err
 	}
@@ -175,6 +406,14 @@ func CheckResourceProviderOptions(options resourceprovider.ResourceProviderOptio
 	if err != nil {
 		return err
 	}
+	err = CheckClientRetryOptions(options.ClientRetry)
+	if err != nil {
+		return err
+	}
+	err = CheckClientConnectionOptions(options.ClientConnection)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -185,6 +424,55 @@ func ProcessResourceProviderOfferOptions(options resourceprovider.ResourceProvid
 	}
 	options.Services = newServicesOptions
 
+	options.Availability = []data.AvailabilityWindow{}
+	for _, spec := range options.AvailabilitySpec {
+		window, err := data.ParseAvailabilityWindow(spec)
+		if err != nil {
+			return options, err
+		}
+		options.Availability = append(options.Availability, window)
+	}
+
+	err = data.AttributeSchema{}.ValidateAttributes(options.Attributes)
+	if err != nil {
+		return options, err
+	}
+
+	options.PricingStrategy.TimeOfDay = []resourceprovider.PricedWindow{}
+	for _, spec := range options.PricingStrategy.TimeOfDaySpec {
+		window, err := resourceprovider.ParsePricedWindow(spec)
+		if err != nil {
+			return options, err
+		}
+		options.PricingStrategy.TimeOfDay = append(options.PricingStrategy.TimeOfDay, window)
+	}
+
+	options.TokenPricing = map[string]data.DealPricing{}
+	for token, price := range options.TokenPrices {
+		if !common.IsHexAddress(token) {
+			return options, fmt.Errorf("OFFER_TOKEN_PRICES %s is not a token address", token)
+		}
+		instructionPrice, err := strconv.ParseUint(price, 10, 64)
+		if err != nil {
+			return options, fmt.Errorf("OFFER_TOKEN_PRICES price for %s is not a whole number: %s", token, price)
+		}
+		pricing := options.DefaultPricing
+		pricing.InstructionPrice = instructionPrice
+		options.TokenPricing[common.HexToAddress(token).Hex()] = pricing
+	}
+
+	if options.TemplatesPath != "" {
+		templates, err := resourceprovider.LoadOfferTemplates(options.TemplatesPath)
+		if err != nil {
+			return options, err
+		}
+		options.Templates = templates
+		options.Specs = []data.MachineSpec{}
+		for _, template := range resourceprovider.ExpandOfferTemplates(templates) {
+			options.Specs = append(options.Specs, template.Spec())
+		}
+	}
+
 	// if there are no specs then populate with the single spec
 	if len(options.Specs) == 0 {
 		// loop the number of machines we want to offer
@@ -196,6 +484,13 @@ func ProcessResourceProviderOfferOptions(options resourceprovider.ResourceProvid
 }
 
 func ProcessResourceProviderOptions(options resourceprovider.ResourceProviderOptions, network string) (resourceprovider.ResourceProviderOptions, error) {
+	if options.Offers.UseBenchmark {
+		benchmark, err := resourceprovider.LoadBenchmark(options.Benchmark.Path)
+		if err != nil {
+			return options, fmt.Errorf("OFFER_USE_BENCHMARK %w", err)
+		}
+		options.Offers = benchmark.Suggest(options.Offers.ResourcePricing).Apply(options.Offers)
+	}
 	newOfferOptions, err := ProcessResourceProviderOfferOptions(options.Offers, network)
 	if err != nil {
 		return options, err
@@ -12,8 +12,88 @@ func GetDefaultServerOptions() http.ServerOptions {
 		URL:           GetDefaultServeOptionString("SERVER_URL", ""),
 		Host:          GetDefaultServeOptionString("SERVER_HOST", "0.0.0.0"),
 		Port:          GetDefaultServeOptionInt("SERVER_PORT", 8080), //nolint:gomnd
+		GRPCPort:      GetDefaultServeOptionInt("SERVER_GRPC_PORT", 0),
 		AccessControl: GetDefaultAccessControlOptions(),
 		RateLimiter:   GetDefaultRateLimiterOptions(),
+		Cors:          GetDefaultCorsOptions(),
+		TLS:           GetDefaultTLSOptions(),
+		Prometheus:    GetDefaultPrometheusOptions(),
+		Compression:   GetDefaultCompressionOptions(),
+		Limits:        GetDefaultLimitOptions(),
+		Replay:        GetDefaultReplayOptions(),
+		Proxy:         GetDefaultProxyOptions(),
+		Connection:    GetDefaultConnectionOptions(),
+		Docs:          GetDefaultDocsOptions(),
+	}
+}
+
+func GetDefaultDocsOptions() http.DocsOptions {
+	return http.DocsOptions{
+		AssetsDir: GetDefaultServeOptionString("SERVER_DOCS_ASSETS_DIR", ""),
+	}
+}
+
+func GetDefaultConnectionOptions() http.ConnectionOptions {
+	return http.ConnectionOptions{
+		HTTP2:                GetDefaultServeOptionBool("SERVER_HTTP2_ENABLED", true),
+		HTTP2Cleartext:       GetDefaultServeOptionBool("SERVER_HTTP2_CLEARTEXT", false),
+		MaxConcurrentStreams: GetDefaultServeOptionInt("SERVER_HTTP2_MAX_CONCURRENT_STREAMS", 250), //nolint:gomnd
+		HTTP2PingInterval:    GetDefaultServeOptionInt("SERVER_HTTP2_PING_INTERVAL", 0),
+		HTTP2PingTimeout:     GetDefaultServeOptionInt("SERVER_HTTP2_PING_TIMEOUT", 15), //nolint:gomnd
+		KeepAlive:            GetDefaultServeOptionBool("SERVER_KEEP_ALIVE_ENABLED", true),
+		TCPKeepAlive:         GetDefaultServeOptionInt("SERVER_TCP_KEEP_ALIVE", 15), //nolint:gomnd
+	}
+}
+
+func GetDefaultProxyOptions() http.ProxyOptions {
+	return http.ProxyOptions{
+		TrustedProxies: GetDefaultServeOptionStringArray("SERVER_TRUSTED_PROXIES", http.DefaultTrustedProxies),
+		TrustedHeaders: GetDefaultServeOptionStringArray("SERVER_TRUSTED_PROXY_HEADERS", http.DefaultTrustedProxyHeaders),
+	}
+}
+
+func GetDefaultReplayOptions() http.ReplayOptions {
+	return http.ReplayOptions{
+		Enabled:   GetDefaultServeOptionBool("SERVER_REPLAY_PROTECTION_ENABLED", http.DefaultReplayOptions.Enabled),
+		ClockSkew: GetDefaultServeOptionInt("SERVER_REPLAY_CLOCK_SKEW", http.DefaultReplayOptions.ClockSkew),
+		MaxNonces: GetDefaultServeOptionInt("SERVER_REPLAY_MAX_NONCES", http.DefaultReplayOptions.MaxNonces),
+	}
+}
+
+func GetDefaultLimitOptions() http.LimitOptions {
+	return http.LimitOptions{
+		MaxBodySize:       GetDefaultServeOptionUint64("SERVER_MAX_BODY_SIZE", 4<<20), //nolint:gomnd
+		MaxUploadSize:     GetDefaultServeOptionUint64("SERVER_MAX_UPLOAD_SIZE", 0),
+		ReadTimeout:       GetDefaultServeOptionInt("SERVER_READ_TIMEOUT", 900),       //nolint:gomnd
+		ReadHeaderTimeout: GetDefaultServeOptionInt("SERVER_READ_HEADER_TIMEOUT", 30), //nolint:gomnd
+		WriteTimeout:      GetDefaultServeOptionInt("SERVER_WRITE_TIMEOUT", 900),      //nolint:gomnd
+		IdleTimeout:       GetDefaultServeOptionInt("SERVER_IDLE_TIMEOUT", 3600),      //nolint:gomnd
+	}
+}
+
+func GetDefaultCompressionOptions() http.CompressionOptions {
+	return http.CompressionOptions{
+		Enabled: GetDefaultServeOptionBool("SERVER_COMPRESSION_ENABLED", false),
+		MinSize: GetDefaultServeOptionInt("SERVER_COMPRESSION_MIN_SIZE", 1024), //nolint:gomnd
+	}
+}
+
+func GetDefaultPrometheusOptions() http.PrometheusOptions {
+	return http.PrometheusOptions{
+		Enabled: GetDefaultServeOptionBool("SERVER_PROMETHEUS_ENABLED", true),
+		Token:   GetDefaultServeOptionString("SERVER_PROMETHEUS_TOKEN", ""),
+	}
+}
+
+func GetDefaultTLSOptions() http.TLSOptions {
+	return http.TLSOptions{
+		CertFile:          GetDefaultServeOptionString("SERVER_TLS_CERT_FILE", ""),
+		KeyFile:           GetDefaultServeOptionString("SERVER_TLS_KEY_FILE", ""),
+		AutocertHosts:     GetDefaultServeOptionStringArray("SERVER_TLS_AUTOCERT_HOSTS", []string{}),
+		AutocertCacheDir:  GetDefaultServeOptionString("SERVER_TLS_AUTOCERT_CACHE_DIR", ""),
+		AutocertEmail:     GetDefaultServeOptionString("SERVER_TLS_AUTOCERT_EMAIL", ""),
+		ClientCAFile:      GetDefaultServeOptionString("SERVER_TLS_CLIENT_CA_FILE", ""),
+		RequireClientCert: GetDefaultServeOptionBool("SERVER_TLS_REQUIRE_CLIENT_CERT", false),
 	}
 }
 
@@ -22,13 +102,39 @@ func GetDefaultAccessControlOptions() http.AccessControlOptions {
 		ValidationTokenSecret:     GetDefaultServeOptionString("SERVER_VALIDATION_TOKEN_SECRET", ""),
 		ValidationTokenExpiration: GetDefaultServeOptionInt("SERVER_VALIDATION_TOKEN_EXPIRATION", 604800), // one week
 		ValidationTokenKid:        GetDefaultServeOptionString("SERVER_VALIDATION_TOKEN_KID", ""),
+		Admins:                    GetDefaultServeOptionStringArray("SERVER_ADMINS", []string{}),
+		RequireReadAuth:           GetDefaultServeOptionBool("SERVER_REQUIRE_READ_AUTH", false),
 	}
 }
 
 func GetDefaultRateLimiterOptions() http.RateLimiterOptions {
 	return http.RateLimiterOptions{
-		RequestLimit: GetDefaultServeOptionInt("SERVER_RATE_REQUEST_LIMIT", 5),
-		WindowLength: GetDefaultServeOptionInt("SERVER_RATE_WINDOW_LENGTH", 10),
+		RequestLimit:  GetDefaultServeOptionInt("SERVER_RATE_REQUEST_LIMIT", 5),
+		WindowLength:  GetDefaultServeOptionInt("SERVER_RATE_WINDOW_LENGTH", 10),
+		GroupLimits:   GetDefaultServeOptionInt64Map("SERVER_RATE_GROUP_LIMITS", map[string]int64{}),
+		PerAddress:    GetDefaultServeOptionBool("SERVER_RATE_PER_ADDRESS", false),
+		AddressLimits: GetDefaultServeOptionInt64Map("SERVER_RATE_ADDRESS_LIMITS", map[string]int64{}),
+	}
+}
+
+func GetDefaultCorsOptions() http.CorsOptions {
+	return http.CorsOptions{
+		AllowedOrigins: GetDefaultServeOptionStringArray("SERVER_CORS_ALLOWED_ORIGINS", []string{"*"}),
+		AllowedMethods: GetDefaultServeOptionStringArray("SERVER_CORS_ALLOWED_METHODS", []string{"GET", "POST", "DELETE", "OPTIONS"}),
+		AllowedHeaders: GetDefaultServeOptionStringArray("SERVER_CORS_ALLOWED_HEADERS", []string{
+			"Content-Type",
+			"Last-Event-ID",
+			http.X_LILYPAD_USER_HEADER,
+			http.X_LILYPAD_SIGNATURE_HEADER,
+			http.X_LILYPAD_TYPED_SIGNATURE_HEADER,
+			http.X_LILYPAD_VERSION_HEADER,
+			http.X_LILYPAD_API_VERSION_HEADER,
+			http.X_LILYPAD_API_KEY_HEADER,
+			http.X_REQUEST_ID_HEADER,
+			http.IDEMPOTENCY_KEY_HEADER,
+		}),
+		AllowCredentials: GetDefaultServeOptionBool("SERVER_CORS_ALLOW_CREDENTIALS", false),
+		MaxAge:           GetDefaultServeOptionInt("SERVER_CORS_MAX_AGE", 600), // ten minutes
 	}
 }
 
@@ -45,6 +151,10 @@ func AddServerCliFlags(cmd *cobra.Command, serverOptions *http.ServerOptions) {
 		&serverOptions.Port, "server-port", serverOptions.Port,
 		`The port to bind the api server to (SERVER_PORT).`,
 	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.GRPCPort, "server-grpc-port", serverOptions.GRPCPort,
+		`The port to bind the gRPC api server to, zero turns it off (SERVER_GRPC_PORT).`,
+	)
 	cmd.PersistentFlags().StringVar(
 		&serverOptions.AccessControl.ValidationTokenSecret, "server-validation-token-secret",
 		serverOptions.AccessControl.ValidationTokenSecret,
@@ -60,6 +170,14 @@ func AddServerCliFlags(cmd *cobra.Command, serverOptions *http.ServerOptions) {
 		serverOptions.AccessControl.ValidationTokenKid,
 		`Key ID header for validation service JWTs (SERVER_VALIDATION_TOKEN_KID).`,
 	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.AccessControl.Admins, "server-admins", serverOptions.AccessControl.Admins,
+		`The addresses that can manage api keys (SERVER_ADMINS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.AccessControl.RequireReadAuth, "server-require-read-auth", serverOptions.AccessControl.RequireReadAuth,
+		`Only answer GET requests that send an api key or a wallet signature (SERVER_REQUIRE_READ_AUTH).`,
+	)
 	cmd.PersistentFlags().IntVar(
 		&serverOptions.RateLimiter.RequestLimit, "server-rate-request-limit", serverOptions.RateLimiter.RequestLimit,
 		`The max requests over the rate window length (SERVER_RATE_REQUEST_LIMIT).`,
@@ -68,6 +186,158 @@ func AddServerCliFlags(cmd *cobra.Command, serverOptions *http.ServerOptions) {
 		&serverOptions.RateLimiter.WindowLength, "server-rate-window-length", serverOptions.RateLimiter.WindowLength,
 		`The time window over which to limit in seconds (SERVER_RATE_WINDOW_LENGTH).`,
 	)
+	cmd.PersistentFlags().StringToInt64Var(
+		&serverOptions.RateLimiter.GroupLimits, "server-rate-group-limits", serverOptions.RateLimiter.GroupLimits,
+		`The max requests over the window for the reads, writes and offers route groups e.g. offers=2,reads=50 (SERVER_RATE_GROUP_LIMITS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.RateLimiter.PerAddress, "server-rate-per-address", serverOptions.RateLimiter.PerAddress,
+		`Limit signed requests by the signer address as well as the ip (SERVER_RATE_PER_ADDRESS).`,
+	)
+	cmd.PersistentFlags().StringToInt64Var(
+		&serverOptions.RateLimiter.AddressLimits, "server-rate-address-limits", serverOptions.RateLimiter.AddressLimits,
+		`The max requests over the window for single addresses, needs --server-rate-per-address (SERVER_RATE_ADDRESS_LIMITS).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.Cors.AllowedOrigins, "server-cors-allowed-origins", serverOptions.Cors.AllowedOrigins,
+		`The origins browsers can call the api from, * for any (SERVER_CORS_ALLOWED_ORIGINS).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.Cors.AllowedMethods, "server-cors-allowed-methods", serverOptions.Cors.AllowedMethods,
+		`The methods browsers can call the api with (SERVER_CORS_ALLOWED_METHODS).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.Cors.AllowedHeaders, "server-cors-allowed-headers", serverOptions.Cors.AllowedHeaders,
+		`The headers browsers can send to the api (SERVER_CORS_ALLOWED_HEADERS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Cors.AllowCredentials, "server-cors-allow-credentials", serverOptions.Cors.AllowCredentials,
+		`Let browsers send cookies and auth headers with cross origin calls (SERVER_CORS_ALLOW_CREDENTIALS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Cors.MaxAge, "server-cors-max-age", serverOptions.Cors.MaxAge,
+		`How long browsers can cache a preflight response in seconds (SERVER_CORS_MAX_AGE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.TLS.CertFile, "server-tls-cert-file", serverOptions.TLS.CertFile,
+		`The certificate to serve https with (SERVER_TLS_CERT_FILE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.TLS.KeyFile, "server-tls-key-file", serverOptions.TLS.KeyFile,
+		`The private key for the certificate (SERVER_TLS_KEY_FILE).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.TLS.AutocertHosts, "server-tls-autocert-hosts", serverOptions.TLS.AutocertHosts,
+		`The hostnames to get let's encrypt certificates for instead of using a cert file (SERVER_TLS_AUTOCERT_HOSTS).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.TLS.AutocertCacheDir, "server-tls-autocert-cache-dir", serverOptions.TLS.AutocertCacheDir,
+		`Where autocert keeps its certificates, defaults to the user cache dir (SERVER_TLS_AUTOCERT_CACHE_DIR).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.TLS.AutocertEmail, "server-tls-autocert-email", serverOptions.TLS.AutocertEmail,
+		`The contact email let's encrypt sends certificate notices to (SERVER_TLS_AUTOCERT_EMAIL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.TLS.ClientCAFile, "server-tls-client-ca-file", serverOptions.TLS.ClientCAFile,
+		`The CA to verify client certificates with for mutual tls (SERVER_TLS_CLIENT_CA_FILE).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.TLS.RequireClientCert, "server-tls-require-client-cert", serverOptions.TLS.RequireClientCert,
+		`Turn away clients without a certificate from the client CA (SERVER_TLS_REQUIRE_CLIENT_CERT).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Prometheus.Enabled, "server-prometheus-enabled", serverOptions.Prometheus.Enabled,
+		`Serve prometheus metrics at /metrics (SERVER_PROMETHEUS_ENABLED).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.Prometheus.Token, "server-prometheus-token", serverOptions.Prometheus.Token,
+		`The bearer token prometheus has to send to scrape /metrics (SERVER_PROMETHEUS_TOKEN).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Compression.Enabled, "server-compression-enabled", serverOptions.Compression.Enabled,
+		`Compress json responses for clients that accept gzip or deflate (SERVER_COMPRESSION_ENABLED).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Compression.MinSize, "server-compression-min-size", serverOptions.Compression.MinSize,
+		`Only compress responses of at least this many bytes (SERVER_COMPRESSION_MIN_SIZE).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&serverOptions.Limits.MaxBodySize, "server-max-body-size", serverOptions.Limits.MaxBodySize,
+		`The largest request body in bytes, larger ones get a 413 (SERVER_MAX_BODY_SIZE).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&serverOptions.Limits.MaxUploadSize, "server-max-upload-size", serverOptions.Limits.MaxUploadSize,
+		`The largest result file upload in bytes, 0 for no limit (SERVER_MAX_UPLOAD_SIZE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Limits.ReadTimeout, "server-read-timeout", serverOptions.Limits.ReadTimeout,
+		`Seconds to read a whole request in, body included (SERVER_READ_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Limits.ReadHeaderTimeout, "server-read-header-timeout", serverOptions.Limits.ReadHeaderTimeout,
+		`Seconds to read the request headers in (SERVER_READ_HEADER_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Limits.WriteTimeout, "server-write-timeout", serverOptions.Limits.WriteTimeout,
+		`Seconds to write a response in (SERVER_WRITE_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Limits.IdleTimeout, "server-idle-timeout", serverOptions.Limits.IdleTimeout,
+		`Seconds to keep an idle keep-alive connection open (SERVER_IDLE_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Replay.Enabled, "server-replay-protection-enabled", serverOptions.Replay.Enabled,
+		`Turn away signed requests without a fresh nonce and timestamp (SERVER_REPLAY_PROTECTION_ENABLED).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Replay.ClockSkew, "server-replay-clock-skew", serverOptions.Replay.ClockSkew,
+		`Seconds a signed request timestamp can be off from the server clock (SERVER_REPLAY_CLOCK_SKEW).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Replay.MaxNonces, "server-replay-max-nonces", serverOptions.Replay.MaxNonces,
+		`The most request nonces to remember, 0 means no limit (SERVER_REPLAY_MAX_NONCES).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.Proxy.TrustedProxies, "server-trusted-proxies", serverOptions.Proxy.TrustedProxies,
+		`The cidr ranges or ips of the proxies whose client ip headers are believed (SERVER_TRUSTED_PROXIES).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&serverOptions.Proxy.TrustedHeaders, "server-trusted-proxy-headers", serverOptions.Proxy.TrustedHeaders,
+		`The headers trusted proxies put the client ip in, checked in order (SERVER_TRUSTED_PROXY_HEADERS).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&serverOptions.Docs.AssetsDir, "server-docs-assets-dir", serverOptions.Docs.AssetsDir,
+		`The swagger-ui-dist directory the api docs at /docs are served from, empty turns them off (SERVER_DOCS_ASSETS_DIR).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Connection.HTTP2, "server-http2-enabled", serverOptions.Connection.HTTP2,
+		`Serve HTTP/2 to clients that ask for it over tls (SERVER_HTTP2_ENABLED).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Connection.HTTP2Cleartext, "server-http2-cleartext", serverOptions.Connection.HTTP2Cleartext,
+		`Also serve HTTP/2 without tls, for when a proxy ends the tls (SERVER_HTTP2_CLEARTEXT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Connection.MaxConcurrentStreams, "server-http2-max-concurrent-streams", serverOptions.Connection.MaxConcurrentStreams,
+		`The most requests one HTTP/2 connection can have in flight (SERVER_HTTP2_MAX_CONCURRENT_STREAMS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Connection.HTTP2PingInterval, "server-http2-ping-interval", serverOptions.Connection.HTTP2PingInterval,
+		`Seconds an HTTP/2 connection can be quiet before it is pinged, 0 for no pings (SERVER_HTTP2_PING_INTERVAL).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Connection.HTTP2PingTimeout, "server-http2-ping-timeout", serverOptions.Connection.HTTP2PingTimeout,
+		`Seconds to wait for a ping answer before closing the connection (SERVER_HTTP2_PING_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&serverOptions.Connection.KeepAlive, "server-keep-alive-enabled", serverOptions.Connection.KeepAlive,
+		`Keep connections open between requests (SERVER_KEEP_ALIVE_ENABLED).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&serverOptions.Connection.TCPKeepAlive, "server-tcp-keep-alive", serverOptions.Connection.TCPKeepAlive,
+		`Seconds between tcp keep-alive probes on idle connections, 0 for no probes (SERVER_TCP_KEEP_ALIVE).`,
+	)
 }
 
 func CheckServerOptions(options http.ServerOptions) error {
@@ -80,5 +350,45 @@ func CheckServerOptions(options http.ServerOptions) error {
 	if options.AccessControl.ValidationTokenKid == "" {
 		return fmt.Errorf("SERVER_VALIDATION_TOKEN_KID is required")
 	}
+	if (options.TLS.CertFile == "") != (options.TLS.KeyFile == "") {
+		return fmt.Errorf("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE have to be set together")
+	}
+	if options.TLS.CertFile != "" && len(options.TLS.AutocertHosts) > 0 {
+		return fmt.Errorf("SERVER_TLS_CERT_FILE and SERVER_TLS_AUTOCERT_HOSTS cannot both be set")
+	}
+	if options.TLS.ClientCAFile != "" && !options.TLS.Enabled() {
+		return fmt.Errorf("SERVER_TLS_CLIENT_CA_FILE needs SERVER_TLS_CERT_FILE or SERVER_TLS_AUTOCERT_HOSTS")
+	}
+	if options.TLS.RequireClientCert && options.TLS.ClientCAFile == "" {
+		return fmt.Errorf("SERVER_TLS_REQUIRE_CLIENT_CERT needs SERVER_TLS_CLIENT_CA_FILE")
+	}
+	if options.Compression.MinSize < 0 {
+		return fmt.Errorf("SERVER_COMPRESSION_MIN_SIZE cannot be negative")
+	}
+	if options.Limits.MaxBodySize == 0 {
+		return fmt.Errorf("SERVER_MAX_BODY_SIZE has to be more than 0")
+	}
+	if options.Limits.ReadTimeout <= 0 || options.Limits.ReadHeaderTimeout <= 0 ||
+		options.Limits.WriteTimeout <= 0 || options.Limits.IdleTimeout <= 0 {
+		return fmt.Errorf("SERVER_READ_TIMEOUT, SERVER_READ_HEADER_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT have to be more than 0")
+	}
+	if options.Replay.Enabled && options.Replay.ClockSkew <= 0 {
+		return fmt.Errorf("SERVER_REPLAY_CLOCK_SKEW has to be more than 0")
+	}
+	if options.Replay.MaxNonces < 0 {
+		return fmt.Errorf("SERVER_REPLAY_MAX_NONCES cannot be negative")
+	}
+	if _, err := http.ParseTrustedProxies(options.Proxy.TrustedProxies); err != nil {
+		return fmt.Errorf("SERVER_TRUSTED_PROXIES %s", err.Error())
+	}
+	if options.Connection.HTTP2 && options.Connection.MaxConcurrentStreams <= 0 {
+		return fmt.Errorf("SERVER_HTTP2_MAX_CONCURRENT_STREAMS has to be more than 0")
+	}
+	if options.Connection.HTTP2Cleartext && !options.Connection.HTTP2 {
+		return fmt.Errorf("SERVER_HTTP2_CLEARTEXT needs SERVER_HTTP2_ENABLED")
+	}
+	if options.Connection.HTTP2PingInterval < 0 || options.Connection.HTTP2PingTimeout < 0 || options.Connection.TCPKeepAlive < 0 {
+		return fmt.Errorf("SERVER_HTTP2_PING_INTERVAL, SERVER_HTTP2_PING_TIMEOUT and SERVER_TCP_KEEP_ALIVE cannot be negative")
+	}
 	return nil
 }
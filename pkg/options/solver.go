@@ -1,6 +1,8 @@
 package options
 
 import (
+	"fmt"
+
 	"github.com/lilypad-tech/lilypad/pkg/solver"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/spf13/cobra"
@@ -8,20 +10,263 @@ import (
 
 func NewSolverOptions() solver.SolverOptions {
 	options := solver.SolverOptions{
-		Server:    GetDefaultServerOptions(),
-		Store:     GetDefaultStoreOptions(),
-		Web3:      GetDefaultWeb3Options(),
-		Services:  GetDefaultServicesOptions(),
-		Telemetry: GetDefaultTelemetryOptions(),
-		Metrics:   GetDefaultMetricsOptions(),
+		Loop:       GetDefaultSolverLoopOptions(),
+		Pricing:    GetDefaultSolverPricingOptions(),
+		Federation: GetDefaultSolverFederationOptions(),
+		Reaper:     GetDefaultSolverReaperOptions(),
+		Indexer:    GetDefaultSolverIndexerOptions(),
+		Webhooks:   GetDefaultSolverWebhookOptions(),
+		Audit:      GetDefaultSolverAuditOptions(),
+		Signatures: GetDefaultSolverSignatureOptions(),
+		Server:     GetDefaultServerOptions(),
+		Store:      GetDefaultStoreOptions(),
+		Matcher:    GetDefaultMatcherOptions(),
+		Web3:       GetDefaultWeb3Options(),
+		Services:   GetDefaultServicesOptions(),
+		Telemetry:  GetDefaultTelemetryOptions(),
+		Metrics:    GetDefaultMetricsOptions(),
 	}
 	options.Web3.Service = system.SolverService
 	return options
 }
 
+func GetDefaultSolverLoopOptions() solver.SolverLoopOptions {
+	return solver.SolverLoopOptions{
+		Interval: GetDefaultServeOptionInt("SOLVER_LOOP_INTERVAL", 10),   //nolint:gomnd
+		Debounce: GetDefaultServeOptionInt("SOLVER_MATCH_DEBOUNCE", 100), //nolint:gomnd
+	}
+}
+
+func AddSolverLoopCliFlags(cmd *cobra.Command, loopOptions *solver.SolverLoopOptions) {
+	cmd.PersistentFlags().IntVar(
+		&loopOptions.Interval, "solver-loop-interval", loopOptions.Interval,
+		`The seconds between matching passes when no offers arrive (SOLVER_LOOP_INTERVAL).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&loopOptions.Debounce, "solver-match-debounce", loopOptions.Debounce,
+		`The milliseconds to wait after an offer arrives before matching (SOLVER_MATCH_DEBOUNCE).`,
+	)
+}
+
+func CheckSolverLoopOptions(options solver.SolverLoopOptions) error {
+	if options.Interval <= 0 {
+		return fmt.Errorf("SOLVER_LOOP_INTERVAL must be greater than zero")
+	}
+	if options.Debounce < 0 {
+		return fmt.Errorf("SOLVER_MATCH_DEBOUNCE cannot be negative")
+	}
+	return nil
+}
+
+func GetDefaultSolverPricingOptions() solver.SolverPricingOptions {
+	return solver.SolverPricingOptions{
+		MinInstructionPrice:       GetDefaultServeOptionUint64("SOLVER_MIN_INSTRUCTION_PRICE", 0),
+		MaxInstructionPrice:       GetDefaultServeOptionUint64("SOLVER_MAX_INSTRUCTION_PRICE", 0),
+		ModuleMinInstructionPrice: GetDefaultServeOptionInt64Map("SOLVER_MODULE_MIN_INSTRUCTION_PRICE", map[string]int64{}),
+		ModuleMaxInstructionPrice: GetDefaultServeOptionInt64Map("SOLVER_MODULE_MAX_INSTRUCTION_PRICE", map[string]int64{}),
+	}
+}
+
+func AddSolverPricingCliFlags(cmd *cobra.Command, pricingOptions *solver.SolverPricingOptions) {
+	cmd.PersistentFlags().Uint64Var(
+		&pricingOptions.MinInstructionPrice, "solver-min-instruction-price", pricingOptions.MinInstructionPrice,
+		`Reject offers priced below this per instruction, 0 disables (SOLVER_MIN_INSTRUCTION_PRICE).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&pricingOptions.MaxInstructionPrice, "solver-max-instruction-price", pricingOptions.MaxInstructionPrice,
+		`Reject offers priced above this per instruction, 0 disables (SOLVER_MAX_INSTRUCTION_PRICE).`,
+	)
+	cmd.PersistentFlags().StringToInt64Var(
+		&pricingOptions.ModuleMinInstructionPrice, "solver-module-min-instruction-price", pricingOptions.ModuleMinInstructionPrice,
+		`Minimum instruction prices for specific modules as module=price pairs, the most specific one for a module applies (SOLVER_MODULE_MIN_INSTRUCTION_PRICE).`,
+	)
+	cmd.PersistentFlags().StringToInt64Var(
+		&pricingOptions.ModuleMaxInstructionPrice, "solver-module-max-instruction-price", pricingOptions.ModuleMaxInstructionPrice,
+		`Maximum instruction prices for specific modules as module=price pairs, a module ID beats repo@hash, which beats the repo, which beats the longest pattern (SOLVER_MODULE_MAX_INSTRUCTION_PRICE).`,
+	)
+}
+
+func CheckSolverPricingOptions(options solver.SolverPricingOptions) error {
+	if options.MaxInstructionPrice > 0 && options.MinInstructionPrice > options.MaxInstructionPrice {
+		return fmt.Errorf("SOLVER_MIN_INSTRUCTION_PRICE cannot be more than SOLVER_MAX_INSTRUCTION_PRICE")
+	}
+	for module, price := range options.ModuleMinInstructionPrice {
+		if price < 0 {
+			return fmt.Errorf("SOLVER_MODULE_MIN_INSTRUCTION_PRICE for %s cannot be negative", module)
+		}
+	}
+	for module, price := range options.ModuleMaxInstructionPrice {
+		if price < 0 {
+			return fmt.Errorf("SOLVER_MODULE_MAX_INSTRUCTION_PRICE for %s cannot be negative", module)
+		}
+	}
+	return nil
+}
+
+func GetDefaultSolverFederationOptions() solver.SolverFederationOptions {
+	return solver.SolverFederationOptions{
+		Peers:        GetDefaultServeOptionStringArray("SOLVER_FEDERATION_PEERS", []string{}),
+		TrustedPeers: GetDefaultServeOptionStringArray("SOLVER_FEDERATION_TRUSTED_PEERS", []string{}),
+		ForwardAfter: GetDefaultServeOptionInt("SOLVER_FEDERATION_FORWARD_AFTER", 60), //nolint:gomnd
+	}
+}
+
+func AddSolverFederationCliFlags(cmd *cobra.Command, federationOptions *solver.SolverFederationOptions) {
+	cmd.PersistentFlags().StringSliceVar(
+		&federationOptions.Peers, "solver-federation-peer", federationOptions.Peers,
+		`The URLs of peer solvers to forward unmatched job offers to (SOLVER_FEDERATION_PEERS).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&federationOptions.TrustedPeers, "solver-federation-trusted-peer", federationOptions.TrustedPeers,
+		`The addresses of peer solvers allowed to forward job offers to us (SOLVER_FEDERATION_TRUSTED_PEERS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&federationOptions.ForwardAfter, "solver-federation-forward-after", federationOptions.ForwardAfter,
+		`The seconds a job offer can go unmatched before it is forwarded to a peer (SOLVER_FEDERATION_FORWARD_AFTER).`,
+	)
+}
+
+func CheckSolverFederationOptions(options solver.SolverFederationOptions) error {
+	if len(options.Peers) > 0 && options.ForwardAfter <= 0 {
+		return fmt.Errorf("SOLVER_FEDERATION_FORWARD_AFTER must be greater than zero")
+	}
+	return nil
+}
+
+func GetDefaultSolverReaperOptions() solver.SolverReaperOptions {
+	return solver.SolverReaperOptions{
+		Interval: GetDefaultServeOptionInt("SOLVER_REAPER_INTERVAL", 60), //nolint:gomnd
+		Grace:    GetDefaultServeOptionInt("SOLVER_REAPER_GRACE", 60),    //nolint:gomnd
+	}
+}
+
+func AddSolverReaperCliFlags(cmd *cobra.Command, reaperOptions *solver.SolverReaperOptions) {
+	cmd.PersistentFlags().IntVar(
+		&reaperOptions.Interval, "solver-reaper-interval", reaperOptions.Interval,
+		`The seconds between checks for stale deals, 0 disables (SOLVER_REAPER_INTERVAL).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&reaperOptions.Grace, "solver-reaper-grace", reaperOptions.Grace,
+		`The seconds past a deal's timeout before the solver asks its parties to time it out on chain (SOLVER_REAPER_GRACE).`,
+	)
+}
+
+func CheckSolverReaperOptions(options solver.SolverReaperOptions) error {
+	if options.Interval < 0 {
+		return fmt.Errorf("SOLVER_REAPER_INTERVAL cannot be negative")
+	}
+	if options.Grace < 0 {
+		return fmt.Errorf("SOLVER_REAPER_GRACE cannot be negative")
+	}
+	return nil
+}
+
+func GetDefaultSolverIndexerOptions() solver.SolverIndexerOptions {
+	return solver.SolverIndexerOptions{
+		Interval:   GetDefaultServeOptionInt("SOLVER_INDEXER_INTERVAL", 0),
+		StartBlock: GetDefaultServeOptionUint64("SOLVER_INDEXER_START_BLOCK", 0),
+	}
+}
+
+func AddSolverIndexerCliFlags(cmd *cobra.Command, indexerOptions *solver.SolverIndexerOptions) {
+	cmd.PersistentFlags().IntVar(
+		&indexerOptions.Interval, "solver-indexer-interval", indexerOptions.Interval,
+		`The seconds between reads of new blocks for contract events to store, 0 disables (SOLVER_INDEXER_INTERVAL).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&indexerOptions.StartBlock, "solver-indexer-start-block", indexerOptions.StartBlock,
+		`The block to index from when no events are stored yet, 0 for the newest (SOLVER_INDEXER_START_BLOCK).`,
+	)
+}
+
+func CheckSolverIndexerOptions(options solver.SolverIndexerOptions) error {
+	if options.Interval < 0 {
+		return fmt.Errorf("SOLVER_INDEXER_INTERVAL cannot be negative")
+	}
+	return nil
+}
+
+func GetDefaultSolverWebhookOptions() solver.SolverWebhookOptions {
+	return solver.SolverWebhookOptions{
+		MaxAttempts: GetDefaultServeOptionInt("SOLVER_WEBHOOK_MAX_ATTEMPTS", 5),   //nolint:gomnd
+		Timeout:     GetDefaultServeOptionInt("SOLVER_WEBHOOK_TIMEOUT", 10),       //nolint:gomnd
+		MaxPerOwner: GetDefaultServeOptionInt("SOLVER_WEBHOOK_MAX_PER_OWNER", 10), //nolint:gomnd
+	}
+}
+
+func AddSolverWebhookCliFlags(cmd *cobra.Command, webhookOptions *solver.SolverWebhookOptions) {
+	cmd.PersistentFlags().IntVar(
+		&webhookOptions.MaxAttempts, "solver-webhook-max-attempts", webhookOptions.MaxAttempts,
+		`How many times an event is sent to a webhook before giving up (SOLVER_WEBHOOK_MAX_ATTEMPTS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&webhookOptions.Timeout, "solver-webhook-timeout", webhookOptions.Timeout,
+		`The seconds a webhook has to respond to each attempt (SOLVER_WEBHOOK_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&webhookOptions.MaxPerOwner, "solver-webhook-max-per-owner", webhookOptions.MaxPerOwner,
+		`How many webhooks each address can register (SOLVER_WEBHOOK_MAX_PER_OWNER).`,
+	)
+}
+
+func CheckSolverWebhookOptions(options solver.SolverWebhookOptions) error {
+	if options.MaxAttempts < 1 {
+		return fmt.Errorf("SOLVER_WEBHOOK_MAX_ATTEMPTS has to be at least 1")
+	}
+	if options.Timeout < 1 {
+		return fmt.Errorf("SOLVER_WEBHOOK_TIMEOUT has to be at least 1")
+	}
+	if options.MaxPerOwner < 0 {
+		return fmt.Errorf("SOLVER_WEBHOOK_MAX_PER_OWNER cannot be negative")
+	}
+	return nil
+}
+
+func GetDefaultSolverAuditOptions() solver.SolverAuditOptions {
+	return solver.SolverAuditOptions{
+		Retention: GetDefaultServeOptionInt("SOLVER_AUDIT_RETENTION", 90), //nolint:gomnd
+	}
+}
+
+func AddSolverAuditCliFlags(cmd *cobra.Command, auditOptions *solver.SolverAuditOptions) {
+	cmd.PersistentFlags().IntVar(
+		&auditOptions.Retention, "solver-audit-retention", auditOptions.Retention,
+		`The days audit entries for signed writes are kept, 0 keeps them forever (SOLVER_AUDIT_RETENTION).`,
+	)
+}
+
+func CheckSolverAuditOptions(options solver.SolverAuditOptions) error {
+	if options.Retention < 0 {
+		return fmt.Errorf("SOLVER_AUDIT_RETENTION cannot be negative")
+	}
+	return nil
+}
+
+func GetDefaultSolverSignatureOptions() solver.SolverSignatureOptions {
+	return solver.SolverSignatureOptions{
+		RequireTyped: GetDefaultServeOptionBool("SOLVER_REQUIRE_TYPED_SIGNATURES", false),
+	}
+}
+
+func AddSolverSignatureCliFlags(cmd *cobra.Command, signatureOptions *solver.SolverSignatureOptions) {
+	cmd.PersistentFlags().BoolVar(
+		&signatureOptions.RequireTyped, "solver-require-typed-signatures", signatureOptions.RequireTyped,
+		`Reject offers and results that are not sent with an EIP-712 signature (SOLVER_REQUIRE_TYPED_SIGNATURES).`,
+	)
+}
+
 func AddSolverCliFlags(cmd *cobra.Command, options *solver.SolverOptions) {
+	AddSolverLoopCliFlags(cmd, &options.Loop)
+	AddSolverPricingCliFlags(cmd, &options.Pricing)
+	AddSolverFederationCliFlags(cmd, &options.Federation)
+	AddSolverReaperCliFlags(cmd, &options.Reaper)
+	AddSolverIndexerCliFlags(cmd, &options.Indexer)
+	AddSolverWebhookCliFlags(cmd, &options.Webhooks)
+	AddSolverAuditCliFlags(cmd, &options.Audit)
+	AddSolverSignatureCliFlags(cmd, &options.Signatures)
 	AddServerCliFlags(cmd, &options.Server)
 	AddStoreCliFlags(cmd, &options.Store)
+	AddMatcherCliFlags(cmd, &options.Matcher)
 	AddWeb3CliFlags(cmd, &options.Web3)
 	AddServicesCliFlags(cmd, &options.Services)
 	AddTelemetryCliFlags(cmd, &options.Telemetry)
@@ -29,7 +274,35 @@ func AddSolverCliFlags(cmd *cobra.Command, options *solver.SolverOptions) {
 }
 
 func CheckSolverOptions(options solver.SolverOptions) error {
-	err := CheckServerOptions(options.Server)
+	err := CheckSolverLoopOptions(options.Loop)
+	if err != nil {
+		return err
+	}
+	err = CheckSolverPricingOptions(options.Pricing)
+	if err != nil {
+		return err
+	}
+	err = CheckSolverFederationOptions(options.Federation)
+	if err != nil {
+		return err
+	}
+	err = CheckSolverReaperOptions(options.Reaper)
+	if err != nil {
+		return err
+	}
+	err = CheckSolverIndexerOptions(options.Indexer)
+	if err != nil {
+		return err
+	}
+	err = CheckSolverWebhookOptions(options.Webhooks)
+	if err != nil {
+		return err
+	}
+	err = CheckSolverAuditOptions(options.Audit)
+	if err != nil {
+		return err
+	}
+	err = CheckServerOptions(options.Server)
 	if err != nil {
 		return err
 	}
@@ -37,6 +310,10 @@ func CheckSolverOptions(options solver.SolverOptions) error {
 	if err != nil {
 		return err
 	}
+	err = CheckMatcherOptions(options.Matcher)
+	if err != nil {
+		return err
+	}
 	err = CheckWeb3Options(options.Web3)
 	if err != nil {
 		return err
@@ -46,6 +46,17 @@ func GetDefaultServeOptionInt(envName string, defaultValue int) int {
 	return defaultValue
 }
 
+func GetDefaultServeOptionFloat64(envName string, defaultValue float64) float64 {
+	envValue := os.Getenv(envName)
+	if envValue != "" {
+		f, err := strconv.ParseFloat(envValue, 64)
+		if err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
 func GetDefaultServeOptionBool(envName string, defaultValue bool) bool {
 	envValue := os.Getenv(envName)
 	if envValue != "" {
@@ -56,3 +67,41 @@ func GetDefaultServeOptionBool(envName string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// parse a comma separated list of key=value pairs with string values
+func GetDefaultServeOptionStringMap(envName string, defaultValue map[string]string) map[string]string {
+	envValue := os.Getenv(envName)
+	if envValue == "" {
+		return defaultValue
+	}
+	values := map[string]string{}
+	for _, pair := range strings.Split(envValue, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return values
+}
+
+// parse a comma separated list of key=value pairs
+// pairs whose value is not an integer are skipped
+func GetDefaultServeOptionInt64Map(envName string, defaultValue map[string]int64) map[string]int64 {
+	envValue := os.Getenv(envName)
+	if envValue == "" {
+		return defaultValue
+	}
+	values := map[string]int64{}
+	for _, pair := range strings.Split(envValue, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err == nil {
+			values[strings.TrimSpace(parts[0])] = i
+		}
+	}
+	return values
+}
@@ -1,12 +1,17 @@
 package options
 
 import (
+	"crypto/ecdsa"
+	"encoding/hex"
 	"fmt"
 	"os"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 func GetDefaultWeb3Options() web3.Web3Options {
@@ -17,6 +22,63 @@ func GetDefaultWeb3Options() web3.Web3Options {
 		PrivateKey: GetDefaultServeOptionString("WEB3_PRIVATE_KEY", ""),
 		ChainID:    GetDefaultServeOptionInt("WEB3_CHAIN_ID", 0), //nolint:gomnd
 
+		// key sources
+		KeystoreFile:   GetDefaultServeOptionString("WEB3_KEYSTORE_FILE", ""),
+		Mnemonic:       GetDefaultServeOptionString("WEB3_MNEMONIC", ""),
+		DerivationPath: GetDefaultServeOptionString("WEB3_DERIVATION_PATH", web3.DefaultDerivationPath),
+
+		// remote signers
+		Signer:       GetDefaultServeOptionString("WEB3_SIGNER", web3.SignerKey),
+		SignerURL:    GetDefaultServeOptionString("WEB3_SIGNER_URL", ""),
+		SignerKeyID:  GetDefaultServeOptionString("WEB3_SIGNER_KEY_ID", ""),
+		SignerRegion: GetDefaultServeOptionString("WEB3_SIGNER_REGION", ""),
+
+		// rpc failover
+		RpcURLs:                GetDefaultServeOptionStringArray("WEB3_RPC_URLS", []string{}),
+		RpcTimeout:             GetDefaultServeOptionInt("WEB3_RPC_TIMEOUT", 30),               //nolint:gomnd
+		RpcHealthCheckInterval: GetDefaultServeOptionInt("WEB3_RPC_HEALTH_CHECK_INTERVAL", 30), //nolint:gomnd
+
+		// event listeners
+		EventBackfillLimit: GetDefaultServeOptionInt("WEB3_EVENT_BACKFILL_LIMIT", 100000), //nolint:gomnd
+		ReorgDepth:         GetDefaultServeOptionInt("WEB3_REORG_DEPTH", 64),              //nolint:gomnd
+		ReorgCheckInterval: GetDefaultServeOptionInt("WEB3_REORG_CHECK_INTERVAL", 30),     //nolint:gomnd
+		Confirmations:      GetDefaultServeOptionInt("WEB3_CONFIRMATIONS", -1),
+
+		// batched contract reads
+		MulticallAddress:   GetDefaultServeOptionString("WEB3_MULTICALL_ADDRESS", web3.DefaultMulticallAddress),
+		MulticallBatchSize: GetDefaultServeOptionInt("WEB3_MULTICALL_BATCH_SIZE", 100), //nolint:gomnd
+
+		// contracts linked from the controller
+		ContractRefreshInterval: GetDefaultServeOptionInt("WEB3_CONTRACT_REFRESH_INTERVAL", 300), //nolint:gomnd
+
+		// transaction fees
+		FeeMode:              GetDefaultServeOptionString("WEB3_FEE_MODE", web3.FeeModeAuto),
+		MaxFeePerGas:         GetDefaultServeOptionUint64("WEB3_MAX_FEE_PER_GAS", 0),
+		MaxPriorityFeePerGas: GetDefaultServeOptionUint64("WEB3_MAX_PRIORITY_FEE_PER_GAS", 0),
+		FeeHistoryBlocks:     GetDefaultServeOptionInt("WEB3_FEE_HISTORY_BLOCKS", 20),     //nolint:gomnd
+		FeeHistoryPercentile: GetDefaultServeOptionInt("WEB3_FEE_HISTORY_PERCENTILE", 50), //nolint:gomnd
+
+		// gas oracle
+		GasOracleInterval: GetDefaultServeOptionInt("WEB3_GAS_ORACLE_INTERVAL", 15), //nolint:gomnd
+		GasCaps:           GetDefaultServeOptionInt64Map("WEB3_GAS_CAPS", map[string]int64{}),
+		GasSpikeThreshold: GetDefaultServeOptionInt("WEB3_GAS_SPIKE_THRESHOLD", 200), //nolint:gomnd
+		GasMaxDefer:       GetDefaultServeOptionInt("WEB3_GAS_MAX_DEFER", 600),       //nolint:gomnd
+
+		// stuck transactions
+		TxReplaceTimeout:     GetDefaultServeOptionInt("WEB3_TX_REPLACE_TIMEOUT", 180), //nolint:gomnd
+		TxFeeBump:            GetDefaultServeOptionInt("WEB3_TX_FEE_BUMP", 20),         //nolint:gomnd
+		TxMaxReplacements:    GetDefaultServeOptionInt("WEB3_TX_MAX_REPLACEMENTS", 5),  //nolint:gomnd
+		TxJournal:            GetDefaultServeOptionBool("WEB3_TX_JOURNAL_ENABLED", true),
+		SimulateTransactions: GetDefaultServeOptionBool("WEB3_SIMULATE_TRANSACTIONS", true),
+		TxBatchSize:          GetDefaultServeOptionInt("WEB3_TX_BATCH_SIZE", 1),
+		UsePermit:            GetDefaultServeOptionBool("WEB3_USE_PERMIT", true),
+
+		// balance alerts
+		BalanceCheckInterval: GetDefaultServeOptionInt("WEB3_BALANCE_CHECK_INTERVAL", 300), //nolint:gomnd
+		MinNativeBalance:     GetDefaultServeOptionFloat64("WEB3_MIN_NATIVE_BALANCE", 0),
+		MinTokenBalance:      GetDefaultServeOptionFloat64("WEB3_MIN_TOKEN_BALANCE", 0),
+		BalanceAlertWebhook:  GetDefaultServeOptionString("WEB3_BALANCE_ALERT_WEBHOOK", ""),
+
 		// contract addresses
 		ControllerAddress: GetDefaultServeOptionString("WEB3_CONTROLLER_ADDRESS", ""),
 		PaymentsAddress:   GetDefaultServeOptionString("WEB3_PAYMENTS_ADDRESS", ""),
@@ -37,6 +99,126 @@ func AddWeb3CliFlags(cmd *cobra.Command, web3Options *web3.Web3Options) {
 		&web3Options.RpcURL, "web3-rpc-url", web3Options.RpcURL,
 		`The URL of the web3 RPC server (WEB3_RPC_URL).`,
 	)
+	cmd.PersistentFlags().StringSliceVar(
+		&web3Options.RpcURLs, "web3-rpc-urls", web3Options.RpcURLs,
+		`More web3 RPC URLs to fail over to, tried in order after the RPC URL (WEB3_RPC_URLS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.RpcTimeout, "web3-rpc-timeout", web3Options.RpcTimeout,
+		`Seconds an RPC call can take before the next URL is tried, 0 for no limit (WEB3_RPC_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.RpcHealthCheckInterval, "web3-rpc-health-check-interval", web3Options.RpcHealthCheckInterval,
+		`Seconds between checks on failed RPC URLs to fail back to them, 0 to never check (WEB3_RPC_HEALTH_CHECK_INTERVAL).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.EventBackfillLimit, "web3-event-backfill-limit", web3Options.EventBackfillLimit,
+		`The most blocks to read missed events back through after a reconnect or restart, 0 for no limit (WEB3_EVENT_BACKFILL_LIMIT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.ReorgDepth, "web3-reorg-depth", web3Options.ReorgDepth,
+		`How many blocks back handled events are checked for being dropped by a reorg, 0 to not check (WEB3_REORG_DEPTH).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.Confirmations, "web3-confirmations", web3Options.Confirmations,
+		`How many blocks deep events have to be before they are acted on, -1 for the network's setting (WEB3_CONFIRMATIONS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.ReorgCheckInterval, "web3-reorg-check-interval", web3Options.ReorgCheckInterval,
+		`Seconds between the checks for reorgs (WEB3_REORG_CHECK_INTERVAL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.MulticallAddress, "web3-multicall-address", web3Options.MulticallAddress,
+		`The Multicall3 contract to batch contract reads through, empty to not batch them (WEB3_MULTICALL_ADDRESS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.MulticallBatchSize, "web3-multicall-batch-size", web3Options.MulticallBatchSize,
+		`The most contract reads to put in one multicall (WEB3_MULTICALL_BATCH_SIZE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.ContractRefreshInterval, "web3-contract-refresh-interval", web3Options.ContractRefreshInterval,
+		`Seconds between checks for the controller linking new contract addresses, 0 to read them once at startup (WEB3_CONTRACT_REFRESH_INTERVAL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.FeeMode, "web3-fee-mode", web3Options.FeeMode,
+		`How the priority fee is picked, auto from recent blocks or fixed (WEB3_FEE_MODE).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&web3Options.MaxFeePerGas, "web3-max-fee-per-gas", web3Options.MaxFeePerGas,
+		`The most wei a transaction pays per gas, 0 for twice the base fee plus the priority fee (WEB3_MAX_FEE_PER_GAS).`,
+	)
+	cmd.PersistentFlags().Uint64Var(
+		&web3Options.MaxPriorityFeePerGas, "web3-max-priority-fee-per-gas", web3Options.MaxPriorityFeePerGas,
+		`The priority fee in wei in fixed mode and the most auto mode picks, 0 for no limit (WEB3_MAX_PRIORITY_FEE_PER_GAS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.FeeHistoryBlocks, "web3-fee-history-blocks", web3Options.FeeHistoryBlocks,
+		`How many recent blocks auto mode takes the priority fee from (WEB3_FEE_HISTORY_BLOCKS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.FeeHistoryPercentile, "web3-fee-history-percentile", web3Options.FeeHistoryPercentile,
+		`The percentile of the priority fees paid in those blocks auto mode picks (WEB3_FEE_HISTORY_PERCENTILE).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.GasOracleInterval, "web3-gas-oracle-interval", web3Options.GasOracleInterval,
+		`Seconds between gas fee checks, 0 turns the gas oracle off (WEB3_GAS_ORACLE_INTERVAL).`,
+	)
+	cmd.PersistentFlags().StringToInt64Var(
+		&web3Options.GasCaps, "web3-gas-caps", web3Options.GasCaps,
+		`The most wei per gas each role pays e.g. solver=50000000000,job-creator=20000000000 (WEB3_GAS_CAPS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.GasSpikeThreshold, "web3-gas-spike-threshold", web3Options.GasSpikeThreshold,
+		`The base fee as a percentage of its recent median that defers transactions that can wait, 0 to never defer (WEB3_GAS_SPIKE_THRESHOLD).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.GasMaxDefer, "web3-gas-max-defer", web3Options.GasMaxDefer,
+		`The most seconds a deferred transaction waits for fees to come down (WEB3_GAS_MAX_DEFER).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.TxReplaceTimeout, "web3-tx-replace-timeout", web3Options.TxReplaceTimeout,
+		`Seconds a transaction can be pending before it is resent with a higher fee, 0 to never resend (WEB3_TX_REPLACE_TIMEOUT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.TxFeeBump, "web3-tx-fee-bump", web3Options.TxFeeBump,
+		`The percentage each resend raises the fees of a stuck transaction by (WEB3_TX_FEE_BUMP).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.TxMaxReplacements, "web3-tx-max-replacements", web3Options.TxMaxReplacements,
+		`How many times a stuck transaction is resent before it is given up on (WEB3_TX_MAX_REPLACEMENTS).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&web3Options.TxJournal, "web3-tx-journal-enabled", web3Options.TxJournal,
+		`Keep a journal of the transactions sent and the gas they used, see lilypad transactions (WEB3_TX_JOURNAL_ENABLED).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&web3Options.SimulateTransactions, "web3-simulate-transactions", web3Options.SimulateTransactions,
+		`Run deal transactions as an eth_call first and do not send the ones that would revert (WEB3_SIMULATE_TRANSACTIONS).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.TxBatchSize, "web3-tx-batch-size", web3Options.TxBatchSize,
+		`The most deals to agree to in one transaction through the multicall contract, 1 to send each on its own (WEB3_TX_BATCH_SIZE).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&web3Options.UsePermit, "web3-use-permit", web3Options.UsePermit,
+		`Send a signed permit with the agree for payment tokens that support EIP-2612 rather than approving them first (WEB3_USE_PERMIT).`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&web3Options.BalanceCheckInterval, "web3-balance-check-interval", web3Options.BalanceCheckInterval,
+		`Seconds between checks of the address's balances, 0 to not check them (WEB3_BALANCE_CHECK_INTERVAL).`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&web3Options.MinNativeBalance, "web3-min-native-balance", web3Options.MinNativeBalance,
+		`Warn when the balance for gas falls under this many coins, 0 to never warn (WEB3_MIN_NATIVE_BALANCE).`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&web3Options.MinTokenBalance, "web3-min-token-balance", web3Options.MinTokenBalance,
+		`Warn when the token balance for collateral falls under this many tokens, 0 to never warn (WEB3_MIN_TOKEN_BALANCE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.BalanceAlertWebhook, "web3-balance-alert-webhook", web3Options.BalanceAlertWebhook,
+		`A url to post to when a balance falls under or goes back over its threshold (WEB3_BALANCE_ALERT_WEBHOOK).`,
+	)
 
 	// don't use the env as the default here because otherwise it will show when --help is used
 	// instead we inject the env value into the options after boot if needed
@@ -44,6 +226,34 @@ func AddWeb3CliFlags(cmd *cobra.Command, web3Options *web3.Web3Options) {
 		&web3Options.PrivateKey, "web3-private-key", "",
 		`The private key to use for signing web3 transactions (WEB3_PRIVATE_KEY).`,
 	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.Mnemonic, "web3-mnemonic", "",
+		`A BIP-39 mnemonic to derive the private key from instead (WEB3_MNEMONIC).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.DerivationPath, "web3-derivation-path", web3Options.DerivationPath,
+		`The derivation path of the key to use from the mnemonic (WEB3_DERIVATION_PATH).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.KeystoreFile, "web3-keystore-file", web3Options.KeystoreFile,
+		`An encrypted JSON keystore file to load the private key from instead, unlocked with WEB3_KEYSTORE_PASSPHRASE or a prompt (WEB3_KEYSTORE_FILE).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.Signer, "web3-signer", web3Options.Signer,
+		fmt.Sprintf(`What signs transactions and requests, %s for a local private key or %s, %s or %s to keep the key on a remote signer (WEB3_SIGNER).`, web3.SignerKey, web3.SignerWeb3Signer, web3.SignerAWSKMS, web3.SignerGCPKMS),
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.SignerURL, "web3-signer-url", web3Options.SignerURL,
+		`The Web3Signer URL, or an endpoint to use for the KMS API instead of the default (WEB3_SIGNER_URL).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.SignerKeyID, "web3-signer-key-id", web3Options.SignerKeyID,
+		`The Web3Signer public key or address, AWS KMS key ID or ARN, or GCP KMS key version name to sign with (WEB3_SIGNER_KEY_ID).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&web3Options.SignerRegion, "web3-signer-region", web3Options.SignerRegion,
+		`The AWS region of the KMS key, AWS_REGION is used when empty (WEB3_SIGNER_REGION).`,
+	)
 	cmd.PersistentFlags().IntVar(
 		&web3Options.ChainID, "web3-chain-id", web3Options.ChainID,
 		`The chain id for the web3 RPC server (WEB3_CHAIN_ID).`,
@@ -76,11 +286,87 @@ func AddWeb3CliFlags(cmd *cobra.Command, web3Options *web3.Web3Options) {
 
 func CheckWeb3Options(options web3.Web3Options) error {
 	// core settings
-	if options.RpcURL == "" {
-		return fmt.Errorf("WEB3_RPC_URL is required")
+	if len(options.RpcURLList()) == 0 {
+		return fmt.Errorf("WEB3_RPC_URL or WEB3_RPC_URLS is required")
 	}
-	if options.PrivateKey == "" {
-		return fmt.Errorf("WEB3_PRIVATE_KEY is required")
+	if options.RpcTimeout < 0 || options.RpcHealthCheckInterval < 0 || options.EventBackfillLimit < 0 {
+		return fmt.Errorf("WEB3_RPC_TIMEOUT, WEB3_RPC_HEALTH_CHECK_INTERVAL and WEB3_EVENT_BACKFILL_LIMIT cannot be negative")
+	}
+	if options.ReorgDepth < 0 {
+		return fmt.Errorf("WEB3_REORG_DEPTH cannot be negative")
+	}
+	if options.ReorgDepth > 0 && options.ReorgCheckInterval <= 0 {
+		return fmt.Errorf("WEB3_REORG_CHECK_INTERVAL has to be more than 0 when WEB3_REORG_DEPTH is set")
+	}
+	if options.TxBatchSize < 1 {
+		return fmt.Errorf("WEB3_TX_BATCH_SIZE has to be at least 1")
+	}
+	if options.Confirmations < 0 {
+		return fmt.Errorf("WEB3_CONFIRMATIONS cannot be negative")
+	}
+	if options.MulticallAddress != "" && !common.IsHexAddress(options.MulticallAddress) {
+		return fmt.Errorf("WEB3_MULTICALL_ADDRESS is not an address")
+	}
+	if options.MulticallBatchSize <= 0 {
+		return fmt.Errorf("WEB3_MULTICALL_BATCH_SIZE has to be more than 0")
+	}
+	if options.ContractRefreshInterval < 0 {
+		return fmt.Errorf("WEB3_CONTRACT_REFRESH_INTERVAL cannot be negative")
+	}
+	if options.BalanceCheckInterval < 0 || options.MinNativeBalance < 0 || options.MinTokenBalance < 0 {
+		return fmt.Errorf("WEB3_BALANCE_CHECK_INTERVAL, WEB3_MIN_NATIVE_BALANCE and WEB3_MIN_TOKEN_BALANCE cannot be negative")
+	}
+	switch options.Signer {
+	case web3.SignerKey:
+		if options.PrivateKey == "" {
+			return fmt.Errorf("WEB3_PRIVATE_KEY, WEB3_KEYSTORE_FILE or WEB3_MNEMONIC is required")
+		}
+	case web3.SignerWeb3Signer:
+		if options.SignerURL == "" {
+			return fmt.Errorf("WEB3_SIGNER_URL is required when WEB3_SIGNER is %s", web3.SignerWeb3Signer)
+		}
+	case web3.SignerAWSKMS, web3.SignerGCPKMS:
+		if options.SignerKeyID == "" {
+			return fmt.Errorf("WEB3_SIGNER_KEY_ID is required when WEB3_SIGNER is %s", options.Signer)
+		}
+	default:
+		return fmt.Errorf("WEB3_SIGNER has to be %s, %s, %s or %s", web3.SignerKey, web3.SignerWeb3Signer, web3.SignerAWSKMS, web3.SignerGCPKMS)
+	}
+	if options.Signer != web3.SignerKey && options.PrivateKey != "" {
+		return fmt.Errorf("WEB3_PRIVATE_KEY, WEB3_KEYSTORE_FILE and WEB3_MNEMONIC cannot be used when WEB3_SIGNER is %s", options.Signer)
+	}
+
+	switch options.FeeMode {
+	case web3.FeeModeAuto:
+		if options.FeeHistoryBlocks <= 0 {
+			return fmt.Errorf("WEB3_FEE_HISTORY_BLOCKS has to be more than 0")
+		}
+		if options.FeeHistoryPercentile < 0 || options.FeeHistoryPercentile > 100 {
+			return fmt.Errorf("WEB3_FEE_HISTORY_PERCENTILE has to be between 0 and 100")
+		}
+	case web3.FeeModeFixed:
+		if options.MaxPriorityFeePerGas == 0 {
+			return fmt.Errorf("WEB3_MAX_PRIORITY_FEE_PER_GAS is required when WEB3_FEE_MODE is %s", web3.FeeModeFixed)
+		}
+	default:
+		return fmt.Errorf("WEB3_FEE_MODE has to be %s or %s", web3.FeeModeAuto, web3.FeeModeFixed)
+	}
+	if options.MaxFeePerGas > 0 && options.MaxPriorityFeePerGas > options.MaxFeePerGas {
+		return fmt.Errorf("WEB3_MAX_PRIORITY_FEE_PER_GAS cannot be more than WEB3_MAX_FEE_PER_GAS")
+	}
+	if options.GasOracleInterval < 0 || options.GasSpikeThreshold < 0 || options.GasMaxDefer < 0 {
+		return fmt.Errorf("WEB3_GAS_ORACLE_INTERVAL, WEB3_GAS_SPIKE_THRESHOLD and WEB3_GAS_MAX_DEFER cannot be negative")
+	}
+	if options.TxReplaceTimeout < 0 || options.TxMaxReplacements < 0 {
+		return fmt.Errorf("WEB3_TX_REPLACE_TIMEOUT and WEB3_TX_MAX_REPLACEMENTS cannot be negative")
+	}
+	if options.TxReplaceTimeout > 0 && options.TxFeeBump < 10 {
+		return fmt.Errorf("WEB3_TX_FEE_BUMP has to be at least 10 for nodes to accept the replacements")
+	}
+	for role, gasCap := range options.GasCaps {
+		if gasCap <= 0 {
+			return fmt.Errorf("WEB3_GAS_CAPS has to be more than 0 for %s", role)
+		}
 	}
 
 	// this is the only address we actually need
@@ -99,8 +385,9 @@ func ProcessWeb3Options(options web3.Web3Options, network string) (web3.Web3Opti
 	}
 
 	// Apply configs when environment variables or command line options are not used
-	if options.RpcURL == "" {
+	if options.RpcURL == "" && len(options.RpcURLs) == 0 {
 		options.RpcURL = config.Web3.RpcURL
+		options.RpcURLs = config.Web3.RpcURLs
 	}
 	if options.ChainID == 0 {
 		options.ChainID = config.Web3.ChainID
@@ -129,9 +416,68 @@ func ProcessWeb3Options(options web3.Web3Options, network string) (web3.Web3Opti
 	if options.PowAddress == "" {
 		options.PowAddress = config.Web3.PowAddress
 	}
+	if options.Confirmations < 0 {
+		options.Confirmations = config.Web3.Confirmations
+	}
 
 	if options.PrivateKey == "" {
 		options.PrivateKey = os.Getenv("WEB3_PRIVATE_KEY")
 	}
+	if options.Mnemonic == "" {
+		options.Mnemonic = os.Getenv("WEB3_MNEMONIC")
+	}
+	return loadWeb3PrivateKey(options)
+}
+
+// everything downstream signs with the hex private key so a keystore or
+// mnemonic is turned into one here
+func loadWeb3PrivateKey(options web3.Web3Options) (web3.Web3Options, error) {
+	sources := 0
+	for _, source := range []string{options.PrivateKey, options.KeystoreFile, options.Mnemonic} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return options, fmt.Errorf("only one of WEB3_PRIVATE_KEY, WEB3_KEYSTORE_FILE and WEB3_MNEMONIC can be used")
+	}
+
+	var privateKey *ecdsa.PrivateKey
+	var err error
+	switch {
+	case options.KeystoreFile != "":
+		passphrase, ok := os.LookupEnv("WEB3_KEYSTORE_PASSPHRASE")
+		if !ok {
+			passphrase, err = promptPassphrase(fmt.Sprintf("Passphrase for %s: ", options.KeystoreFile))
+			if err != nil {
+				return options, err
+			}
+		}
+		privateKey, err = web3.LoadKeystore(options.KeystoreFile, passphrase)
+	case options.Mnemonic != "":
+		privateKey, err = web3.DeriveMnemonicKey(options.Mnemonic, options.DerivationPath)
+	default:
+		return options, nil
+	}
+	if err != nil {
+		return options, err
+	}
+	options.PrivateKey = hex.EncodeToString(crypto.FromECDSA(privateKey))
+	// the key is all that is needed from here on
+	options.Mnemonic = ""
 	return options, nil
 }
+
+// daemons have no one to ask so they have to set WEB3_KEYSTORE_PASSPHRASE
+func promptPassphrase(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("WEB3_KEYSTORE_PASSPHRASE is required to unlock the keystore when not run from a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
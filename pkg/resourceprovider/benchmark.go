@@ -0,0 +1,360 @@
+package resourceprovider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// what one of each resource does on a machine we think of as standard, the
+// resource prices are for it and a faster machine charges more for the
+// same resource
+const (
+	// keccak hashes of the pow workload on one cpu
+	referenceCPUMHps = 0.5
+	// copying memory with every worker at it
+	referenceRAMGBps = 10
+	// the single precision GFLOP/s of the CUDA nbody sample
+	referenceGPUGFLOPS = 10000
+)
+
+// the most goroutines copying memory at once, a few are enough to use up
+// the memory bandwidth and each has buffers of its own
+const benchmarkMemoryWorkers = 8
+const benchmarkMemoryBuffer = 32 << 20
+
+// the attributes the benchmark puts on the offers so job creators can ask
+// for e.g. benchmark.gpu.gflops>=20000
+const (
+	BenchmarkCPUAttribute  = "benchmark.cpu.mhps"
+	BenchmarkRAMAttribute  = "benchmark.ram.gbps"
+	BenchmarkDiskAttribute = "benchmark.disk.mbps"
+	BenchmarkGPUAttribute  = "benchmark.gpu.gflops"
+)
+
+var nbodyGFLOPSPattern = regexp.MustCompile(`([0-9.]+)\s+single-precision GFLOP/s`)
+
+type ResourceProviderBenchmarkOptions struct {
+	// where the results are kept for OFFER_USE_BENCHMARK
+	Path string
+	// how many seconds the cpu and memory workloads run for
+	Duration int
+	// how many MB are written to measure the disk
+	DiskMB int
+	// the image of the CUDA nbody sample the GPUs are measured with, it is
+	// run with docker
+	GPUImage string
+}
+
+type BenchmarkResult struct {
+	RanAt time.Time `json:"ran_at"`
+	// how many cpus the machine has and the pow hash rate over all of them
+	CPUs  int            `json:"cpus"`
+	CPU   float64        `json:"cpu_mhps"`
+	RAM   int            `json:"ram_mb,omitempty"`
+	RAMGB float64        `json:"ram_gbps"`
+	Disk  float64        `json:"disk_mbps,omitempty"`
+	GPUs  []GPUBenchmark `json:"gpus,omitempty"`
+	// the resources that were not measured and why
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+type GPUBenchmark struct {
+	Name   string  `json:"name"`
+	VRAM   int     `json:"vram"`
+	GFLOPS float64 `json:"gflops"`
+}
+
+// what the benchmark says to offer, the spec values are only set for the
+// resources it measured
+type BenchmarkSuggestion struct {
+	CPU             int
+	GPU             int
+	RAM             int
+	Attributes      map[string]string
+	ResourcePricing data.ResourcePricing
+}
+
+// runs the standard workloads one resource at a time so they do not slow
+// each other down, a resource that cannot be measured here is skipped
+// rather than failing the rest
+func RunBenchmark(ctx context.Context, options ResourceProviderBenchmarkOptions) (BenchmarkResult, error) {
+	duration := time.Duration(options.Duration) * time.Second
+	result := BenchmarkResult{
+		RanAt:   time.Now().UTC(),
+		CPUs:    runtime.NumCPU(),
+		Skipped: map[string]string{},
+	}
+	result.CPU = benchmarkCPU(ctx, duration)
+	result.RAMGB = benchmarkMemory(ctx, duration)
+	if ram, err := availableMemory(); err == nil {
+		result.RAM = ram
+	} else {
+		result.Skipped["ram_mb"] = err.Error()
+	}
+	if disk, err := benchmarkDisk(filepath.Dir(options.Path), options.DiskMB); err == nil {
+		result.Disk = disk
+	} else {
+		result.Skipped["disk"] = err.Error()
+	}
+	gpus, err := benchmarkGPUs(ctx, options.GPUImage)
+	if err != nil {
+		result.Skipped["gpu"] = err.Error()
+	}
+	result.GPUs = gpus
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
+
+// the pow hash on every cpu at once, in millions of hashes a second
+func benchmarkCPU(ctx context.Context, duration time.Duration) float64 {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	var hashes atomic.Uint64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for worker := 0; worker < runtime.NumCPU(); worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			challenge := [32]byte{byte(worker)}
+			nonce := new(big.Int)
+			for ctx.Err() == nil {
+				if _, err := calculateHashNumber(challenge, nonce); err != nil {
+					return
+				}
+				nonce.Add(nonce, big.NewInt(1))
+				hashes.Add(1)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	return float64(hashes.Load()) / time.Since(start).Seconds() / 1e6 //nolint:gomnd
+}
+
+// GB a second copied between buffers that are far bigger than the caches
+func benchmarkMemory(ctx context.Context, duration time.Duration) float64 {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	workers := min(runtime.NumCPU(), benchmarkMemoryWorkers)
+	var copied atomic.Uint64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			from := make([]byte, benchmarkMemoryBuffer)
+			to := make([]byte, benchmarkMemoryBuffer)
+			for ctx.Err() == nil {
+				copied.Add(uint64(copy(to, from)))
+				from, to = to, from
+			}
+		}()
+	}
+	wg.Wait()
+	return float64(copied.Load()) / time.Since(start).Seconds() / 1e9 //nolint:gomnd
+}
+
+// MB a second written and synced to a file in dir, which is where the
+// resource provider keeps its data and so near where jobs write theirs
+func benchmarkDisk(dir string, sizeMB int) (float64, error) {
+	if sizeMB <= 0 {
+		return 0, fmt.Errorf("BENCHMARK_DISK_MB is 0")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gomnd
+		return 0, err
+	}
+	file, err := os.CreateTemp(dir, "benchmark-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	chunk := make([]byte, 1<<20)
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+	start := time.Now()
+	for written := 0; written < sizeMB; written++ {
+		if _, err := file.Write(chunk); err != nil {
+			return 0, fmt.Errorf("error writing the disk benchmark: %w", err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return 0, fmt.Errorf("error syncing the disk benchmark: %w", err)
+	}
+	return float64(sizeMB) / time.Since(start).Seconds(), nil
+}
+
+// each GPU runs the nbody sample on its own, the GPUs are the ones
+// nvidia-smi lists so this is the same view the offers have
+func benchmarkGPUs(ctx context.Context, image string) ([]GPUBenchmark, error) {
+	gpus, err := DetectGPUs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(gpus) == 0 {
+		return nil, fmt.Errorf("nvidia-smi found no gpus")
+	}
+	docker, err := exec.LookPath("docker")
+	if err != nil {
+		return nil, fmt.Errorf("the gpus are benchmarked with docker: %w", err)
+	}
+	results := []GPUBenchmark{}
+	for index, gpu := range gpus {
+		output, err := exec.CommandContext(ctx, docker, "run", "--rm", "--gpus", fmt.Sprintf("device=%d", index), image, "nbody", "-gpu", "-benchmark").CombinedOutput()
+		if err != nil {
+			return results, fmt.Errorf("error running %s on gpu %d: %w: %s", image, index, err, strings.TrimSpace(string(output)))
+		}
+		gflops, err := parseNbodyGFLOPS(string(output))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, GPUBenchmark{Name: gpu.Name, VRAM: gpu.VRAM, GFLOPS: gflops})
+	}
+	return results, nil
+}
+
+// the nbody sample ends with e.g.
+// = 9751.362 single-precision GFLOP/s at 20 flops per interaction
+func parseNbodyGFLOPS(output string) (float64, error) {
+	match := nbodyGFLOPSPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("the nbody benchmark did not print its GFLOP/s")
+	}
+	return strconv.ParseFloat(match[1], 64)
+}
+
+// the MemAvailable of /proc/meminfo, which only linux has
+func availableMemory() (int, error) {
+	meminfo, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("cannot read the free memory: %w", err)
+	}
+	defer meminfo.Close()
+	scanner := bufio.NewScanner(meminfo)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return 0, fmt.Errorf("/proc/meminfo gave %q as the free memory", fields[1])
+			}
+			return kb / 1024, nil //nolint:gomnd
+		}
+	}
+	return 0, fmt.Errorf("/proc/meminfo has no MemAvailable")
+}
+
+// the capacity the benchmark found and the resource prices scaled from
+// pricing by how the machine compares to the reference, a price that is 0
+// stays 0 and egress is not measured so it is kept as it is
+func (result BenchmarkResult) Suggest(pricing data.ResourcePricing) BenchmarkSuggestion {
+	suggestion := BenchmarkSuggestion{
+		CPU:             result.CPUs * 1000, //nolint:gomnd
+		RAM:             result.RAM,
+		Attributes:      map[string]string{},
+		ResourcePricing: pricing,
+	}
+	if result.CPU > 0 && result.CPUs > 0 {
+		suggestion.Attributes[BenchmarkCPUAttribute] = strconv.FormatFloat(result.CPU, 'f', 2, 64)
+		suggestion.ResourcePricing.CPUHour = scalePrice(pricing.CPUHour, result.CPU/float64(result.CPUs)/referenceCPUMHps)
+	}
+	if result.RAMGB > 0 {
+		suggestion.Attributes[BenchmarkRAMAttribute] = strconv.FormatFloat(result.RAMGB, 'f', 2, 64)
+		suggestion.ResourcePricing.RAMGBHour = scalePrice(pricing.RAMGBHour, result.RAMGB/referenceRAMGBps)
+	}
+	if result.Disk > 0 {
+		suggestion.Attributes[BenchmarkDiskAttribute] = strconv.FormatFloat(result.Disk, 'f', 0, 64)
+	}
+	if len(result.GPUs) > 0 {
+		// a deal can land on any of the GPUs so they are all as good as
+		// the slowest one
+		slowest := result.GPUs[0].GFLOPS
+		for _, gpu := range result.GPUs {
+			slowest = math.Min(slowest, gpu.GFLOPS)
+		}
+		suggestion.GPU = len(result.GPUs) * 1000 //nolint:gomnd
+		suggestion.Attributes[BenchmarkGPUAttribute] = strconv.FormatFloat(slowest, 'f', 0, 64)
+		suggestion.ResourcePricing.GPUHour = scalePrice(pricing.GPUHour, slowest/referenceGPUGFLOPS)
+	}
+	return suggestion
+}
+
+// never below 1 so a slow machine still charges for what it was priced at
+func scalePrice(price uint64, ratio float64) uint64 {
+	if price == 0 {
+		return 0
+	}
+	return uint64(math.Max(1, math.Round(float64(price)*ratio)))
+}
+
+// the offer options with what the benchmark suggests, OFFER_ATTRIBUTES
+// given on the command line win over ours
+func (suggestion BenchmarkSuggestion) Apply(options ResourceProviderOfferOptions) ResourceProviderOfferOptions {
+	if suggestion.CPU > 0 {
+		options.OfferSpec.CPU = suggestion.CPU
+	}
+	if suggestion.GPU > 0 {
+		options.OfferSpec.GPU = suggestion.GPU
+	}
+	if suggestion.RAM > 0 {
+		options.OfferSpec.RAM = suggestion.RAM
+	}
+	options.ResourcePricing = suggestion.ResourcePricing
+	attributes := map[string]string{}
+	for name, value := range suggestion.Attributes {
+		attributes[name] = value
+	}
+	for name, value := range options.Attributes {
+		attributes[name] = value
+	}
+	options.Attributes = attributes
+	return options
+}
+
+func SaveBenchmark(path string, result BenchmarkResult) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gomnd
+		return err
+	}
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, encoded, 0644) //nolint:gomnd
+}
+
+func LoadBenchmark(path string) (BenchmarkResult, error) {
+	result := BenchmarkResult{}
+	encoded, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, fmt.Errorf("there is no benchmark at %s, run lilypad resource-provider benchmark first", path)
+	}
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		return result, fmt.Errorf("error reading the benchmark at %s: %w", path, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNbodyGFLOPS(t *testing.T) {
+	output := `> Windowed mode
+> Simulation data stored in video memory
+number of bodies = 1048576
+1048576 bodies, total time for 10 iterations: 11270.777 ms
+= 975.562 billion interactions per second
+= 19511.243 single-precision GFLOP/s at 20 flops per interaction
+`
+	gflops, err := parseNbodyGFLOPS(output)
+	assert.NoError(t, err)
+	assert.Equal(t, 19511.243, gflops)
+
+	_, err = parseNbodyGFLOPS("CUDA error: no CUDA-capable device is detected")
+	assert.Error(t, err)
+}
+
+func TestBenchmarkSuggest(t *testing.T) {
+	result := BenchmarkResult{
+		CPUs:  8,
+		CPU:   8,
+		RAM:   30000,
+		RAMGB: 20,
+		Disk:  900,
+		GPUs: []GPUBenchmark{
+			{Name: "NVIDIA RTX 4090", GFLOPS: 30000},
+			{Name: "NVIDIA RTX 3090", GFLOPS: 20000},
+		},
+	}
+	suggestion := result.Suggest(data.ResourcePricing{GPUHour: 100, CPUHour: 10, EgressGB: 3})
+	assert.Equal(t, 8000, suggestion.CPU)
+	assert.Equal(t, 2000, suggestion.GPU)
+	assert.Equal(t, 30000, suggestion.RAM)
+	// twice the reference cpu and the slowest gpu at twice the reference,
+	// what was not priced stays free and egress is not measured
+	assert.Equal(t, data.ResourcePricing{GPUHour: 200, CPUHour: 20, EgressGB: 3}, suggestion.ResourcePricing)
+	assert.Equal(t, map[string]string{
+		BenchmarkCPUAttribute:  "8.00",
+		BenchmarkRAMAttribute:  "20.00",
+		BenchmarkDiskAttribute: "900",
+		BenchmarkGPUAttribute:  "20000",
+	}, suggestion.Attributes)
+
+	// a slow machine still charges something
+	slow := BenchmarkResult{CPUs: 1, CPU: 0.001}.Suggest(data.ResourcePricing{CPUHour: 10})
+	assert.Equal(t, uint64(1), slow.ResourcePricing.CPUHour)
+	assert.Equal(t, 0, slow.GPU)
+}
+
+func TestBenchmarkApply(t *testing.T) {
+	options := ResourceProviderOfferOptions{
+		OfferSpec:  data.MachineSpec{CPU: 1000, RAM: 1024},
+		Attributes: map[string]string{BenchmarkDiskAttribute: "100", "infiniband": "true"},
+	}
+	suggestion := BenchmarkSuggestion{
+		CPU:             4000,
+		Attributes:      map[string]string{BenchmarkDiskAttribute: "900", BenchmarkCPUAttribute: "2.00"},
+		ResourcePricing: data.ResourcePricing{CPUHour: 5},
+	}
+	applied := suggestion.Apply(options)
+	assert.Equal(t, data.MachineSpec{CPU: 4000, RAM: 1024}, applied.OfferSpec)
+	assert.Equal(t, data.ResourcePricing{CPUHour: 5}, applied.ResourcePricing)
+	// the attributes we were given win
+	assert.Equal(t, map[string]string{
+		BenchmarkDiskAttribute: "100",
+		BenchmarkCPUAttribute:  "2.00",
+		"infiniband":           "true",
+	}, applied.Attributes)
+	assert.Len(t, options.Attributes, 2)
+}
+
+func TestSaveBenchmark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "benchmark.json")
+	_, err := LoadBenchmark(path)
+	assert.ErrorContains(t, err, "run lilypad resource-provider benchmark first")
+
+	result := BenchmarkResult{CPUs: 2, CPU: 1.5, GPUs: []GPUBenchmark{{Name: "NVIDIA A100", VRAM: 81920, GFLOPS: 25000}}}
+	assert.NoError(t, SaveBenchmark(path, result))
+	loaded, err := LoadBenchmark(path)
+	assert.NoError(t, err)
+	assert.Equal(t, result.GPUs, loaded.GPUs)
+	assert.Equal(t, 1.5, loaded.CPU)
+}
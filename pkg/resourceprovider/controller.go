@@ -2,10 +2,16 @@ package resourceprovider
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/executor"
 	"github.com/lilypad-tech/lilypad/pkg/http"
@@ -29,11 +35,30 @@ type ResourceProviderController struct {
 	log          *system.ServiceLogger
 	tracer       trace.Tracer
 	executor     executor.Executor
+	// what DetectGPUs found at startup
+	gpus []data.GPUSpec
+	// which deal is using which of those gpus, nil when there are none
+	devices *deviceAllocator
 	// keep track of which jobs are running
 	// this is because no remote state will change
 	// whilst we are actually running a job
 	runningJobsMutex sync.RWMutex
 	runningJobs      map[string]bool
+	// the agreed deals waiting for one of the MaxConcurrentJobs slots
+	jobs *jobQueue
+	// prices each offer just before it is posted
+	pricing PricingStrategy
+	// decrypts the secrets job creators send, nil when we do not take
+	// jobs with secrets
+	secretsKey *ecdsa.PrivateKey
+	// paused or draining stops new work
+	drain *drainControl
+	// an offer was used up so the offers are checked on the next loop
+	offersDue atomic.Bool
+	// OFFER_TEMPLATES with one entry per offer, by offer index
+	templateOffers []OfferTemplate
+	// the quote of the confidential VM we run in, nil without OFFER_ATTESTATION
+	attestation *data.Attestation
 }
 
 // the background "even if we have not heard of an event" loop
@@ -60,17 +85,27 @@ func NewResourceProviderController(
 		return nil, err
 	}
 
+	signingDomain := web3SDK.TypedDataDomain()
 	solverClient, err := solver.NewSolverClient(
 		http.ClientOptions{
 			URL:           solverUrl,
-			PrivateKey:    options.Web3.PrivateKey,
+			Signer:        web3SDK.Signer,
 			Type:          "ResourceProvider",
 			PublicAddress: web3SDK.GetAddress().String(),
+			SigningDomain: &signingDomain,
+			TLS:           options.ClientTLS,
+			Retry:         options.ClientRetry,
+			Connection:    options.ClientConnection,
 		})
 	if err != nil {
 		return nil, err
 	}
 
+	pricing, err := NewPricingStrategy(options.Offers.PricingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	controller := &ResourceProviderController{
 		solverClient: solverClient,
 		options:      options,
@@ -80,6 +115,39 @@ func NewResourceProviderController(
 		tracer:       tracer,
 		executor:     executor,
 		runningJobs:  map[string]bool{},
+		jobs:         newJobQueue(options.Jobs.MaxConcurrentJobs),
+		pricing:      pricing,
+		drain:        newDrainControl(),
+		// empty without OFFER_TEMPLATES
+		templateOffers: ExpandOfferTemplates(options.Offers.Templates),
+	}
+	if options.Offers.DetectGPUs {
+		gpus, err := DetectGPUs(context.Background())
+		if err != nil {
+			// the offers still go out with what the executor reports
+			controller.log.Error("error detecting gpus", err)
+		} else if len(gpus) > 0 {
+			controller.log.Info("detected gpus", gpus)
+		}
+		controller.gpus = gpus
+	}
+	if len(controller.gpus) > 0 {
+		controller.devices = newDeviceAllocator(len(controller.gpus))
+	}
+	if options.Offers.AcceptSecrets && !canRunWithSecrets(controller.executor) {
+		// without a key the offers publish none and get no jobs with secrets
+		controller.log.Info("not accepting secrets", "the executor can only pass them in the job spec")
+	} else if options.Offers.AcceptSecrets {
+		controller.secretsKey, err = web3.LoadSecretsKey(options.Offers.SecretsKeyPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if options.Offers.Attestation {
+		controller.attestation, err = controller.getAttestation()
+		if err != nil {
+			return nil, err
+		}
 	}
 	return controller, nil
 }
@@ -95,7 +163,7 @@ func NewResourceProviderController(
 *
 *
 */
-func (controller *ResourceProviderController) subscribeToSolver() error {
+func (controller *ResourceProviderController) subscribeToSolver(ctx context.Context) error {
 	controller.solverClient.SubscribeEvents(func(ev solver.SolverEvent) {
 		// we need to agree to the deal now we've heard about it
 		if ev.EventType == solver.DealAdded {
@@ -114,10 +182,95 @@ func (controller *ResourceProviderController) subscribeToSolver() error {
 			// trigger the solver
 			controller.loop.Trigger()
 		}
+		// the job creator agreed so we can start the job straight away
+		if ev.EventType == solver.DealStateUpdated {
+			if ev.Deal == nil || ev.Deal.ResourceProvider != controller.web3SDK.GetAddress().String() {
+				return
+			}
+			controller.loop.Trigger()
+		}
+		// one of our offers is done with its deal so the machine needs a new one
+		if ev.EventType == solver.ResourceOfferStateUpdated {
+			if ev.ResourceOffer == nil || ev.ResourceOffer.ResourceProvider != controller.web3SDK.GetAddress().String() {
+				return
+			}
+			if !data.IsActiveAgreementState(ev.ResourceOffer.State) {
+				controller.replenishOffers()
+			}
+		}
+		// the solver has given up waiting on the deal so we claim the timeout
+		if ev.EventType == solver.DealTimedOut {
+			if ev.Deal == nil || ev.Deal.ResourceProvider != controller.web3SDK.GetAddress().String() {
+				return
+			}
+			go controller.timeoutDeal(ctx, *ev.Deal)
+		}
 	})
 	return nil
 }
 
+// call the on chain timeout that refunds or pays us for a stale deal
+func (controller *ResourceProviderController) timeoutDeal(ctx context.Context, deal data.DealContainer) {
+	txs := deal.Transactions.ResourceProvider
+	var err error
+	var txHash string
+	var action string
+	var payload data.DealTransactionsResourceProvider
+	switch data.GetAgreementStateString(deal.State) {
+	case "TimeoutAgree":
+		// only the party that agreed has collateral to get back
+		if txs.Agree == "" || txs.TimeoutAgree != "" {
+			return
+		}
+		action = "timeout_agree"
+		txHash, err = controller.web3SDK.TimeoutAgree(ctx, deal.ID)
+		payload.TimeoutAgree = txHash
+	case "TimeoutJudgeResults":
+		if txs.TimeoutJudgeResult != "" {
+			return
+		}
+		action = "timeout_judge_result"
+		txHash, err = controller.web3SDK.TimeoutJudgeResult(ctx, deal.ID)
+		payload.TimeoutJudgeResult = txHash
+	case "TimeoutMediateResults":
+		if txs.TimeoutMediateResult != "" {
+			return
+		}
+		action = "timeout_mediate_result"
+		txHash, err = controller.web3SDK.TimeoutMediateResult(ctx, deal.ID)
+		payload.TimeoutMediateResult = txHash
+	default:
+		return
+	}
+	if err != nil {
+		controller.log.Error("error calling timeout tx for deal", err)
+		controller.reportAbandoned(deal.ID, action, err)
+		return
+	}
+	controller.log.Info("timeout tx", txHash)
+	payload.Stuck = controller.web3SDK.StuckTransactions(action, txHash, nil)
+
+	_, err = controller.solverClient.UpdateTransactionsResourceProvider(deal.ID, payload)
+	if err != nil {
+		controller.log.Error("error adding timeout tx hash for deal", err)
+	}
+}
+
+// a deal transaction that was given up on has no hash to record but the
+// deal should still show what happened to it
+func (controller *ResourceProviderController) reportAbandoned(dealID string, action string, err error) {
+	stuck := controller.web3SDK.StuckTransactions(action, "", err)
+	if len(stuck) == 0 {
+		return
+	}
+	_, err = controller.solverClient.UpdateTransactionsResourceProvider(dealID, data.DealTransactionsResourceProvider{
+		Stuck: stuck,
+	})
+	if err != nil {
+		controller.log.Error("error adding stuck txs for deal", err)
+	}
+}
+
 func (controller *ResourceProviderController) subscribeToWeb3() error {
 	controller.web3Events.Storage.SubscribeDealStateChange(func(ev storage.StorageDealStateChange) {
 		deal, err := controller.solverClient.GetDeal(ev.DealId)
@@ -137,7 +290,7 @@ func (controller *ResourceProviderController) subscribeToWeb3() error {
 
 func (controller *ResourceProviderController) Start(ctx context.Context, cm *system.CleanupManager) chan error {
 	errorChan := make(chan error, 1)
-	err := controller.subscribeToSolver()
+	err := controller.subscribeToSolver(ctx)
 	if err != nil {
 		errorChan <- err
 		return errorChan
@@ -158,6 +311,10 @@ func (controller *ResourceProviderController) Start(ctx context.Context, cm *sys
 		return errorChan
 	}
 
+	if warmer, ok := controller.executor.(executor.ImageWarmer); ok {
+		go controller.warmImages(warmer)
+	}
+
 	controller.loop = system.NewControlLoop(
 		system.ResourceProviderService,
 		ctx,
@@ -212,7 +369,7 @@ func (controller *ResourceProviderController) solve(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	return controller.checkDrained()
 }
 
 /*
@@ -239,23 +396,82 @@ func (controller *ResourceProviderController) getResourceOffer(index int, spec d
 		Index:            index,
 		Spec:             spec,
 		Modules:          controller.options.Offers.Modules,
+		DeniedModules:    controller.options.Offers.DeniedModules,
 		Mode:             controller.options.Offers.Mode,
 		DefaultPricing:   controller.options.Offers.DefaultPricing,
 		DefaultTimeouts:  controller.options.Offers.DefaultTimeouts,
 		ModulePricing:    map[string]data.DealPricing{},
 		ModuleTimeouts:   map[string]data.DealTimeouts{},
+		TokenPricing:     controller.options.Offers.TokenPricing,
+		ResourcePricing:  resourcePricing(controller.options.Offers.ResourcePricing),
 		Services:         controller.options.Offers.Services,
+		Availability:     controller.options.Offers.Availability,
+		Packing:          controller.options.Offers.Packing,
+		Attributes:       controller.options.Offers.Attributes,
+		Region:           controller.options.Offers.Region,
+		SecretsKey:       controller.secretsPublicKey(),
+		Attestation:      controller.attestation,
 	}
 }
 
+// nil when nothing is priced so the offer stays priced per instruction
+func resourcePricing(pricing data.ResourcePricing) *data.ResourcePricing {
+	if pricing == (data.ResourcePricing{}) {
+		return nil
+	}
+	return &pricing
+}
+
+func (controller *ResourceProviderController) secretsPublicKey() string {
+	if controller.secretsKey == nil {
+		return ""
+	}
+	return web3.SecretsPublicKey(controller.secretsKey)
+}
+
+// the quote is made once, it covers our address and secrets key which
+// do not change while we run
+func (controller *ResourceProviderController) getAttestation() (*data.Attestation, error) {
+	reportData := attestation.ReportData(controller.web3SDK.GetAddress().String(), controller.secretsPublicKey())
+	quote, err := attestation.GetQuote(reportData, controller.options.Offers.AttestationCertificates)
+	if err != nil {
+		return nil, fmt.Errorf("error getting an attestation for OFFER_ATTESTATION: %w", err)
+	}
+	controller.log.Info(fmt.Sprintf("attested as a %s confidential VM", quote.Type), "")
+	return quote, nil
+}
+
+// the share of the job slots that are busy, with no MaxConcurrentJobs
+// each machine counts as a slot
+func (controller *ResourceProviderController) utilization(machines int) float64 {
+	slots := controller.options.Jobs.MaxConcurrentJobs
+	if slots == 0 {
+		slots = machines
+	}
+	if slots == 0 {
+		return 0
+	}
+	running, _ := controller.jobs.length()
+	return float64(running) / float64(slots)
+}
+
 func (controller *ResourceProviderController) checkResourceoffers() error {
+	// the offers were taken down when we paused or started draining
+	if !controller.acceptingWork() {
+		return nil
+	}
 	// We only want to run this every RESOURCE_OFFER_INTERVAL
-	if !lastResourceOfferPost.IsZero() && time.Since(lastResourceOfferPost) < RESOURCE_OFFER_INTERVAL {
+	// or when a deal has freed up one of our offers
+	due := controller.offersDue.Swap(false)
+	if !due && !lastResourceOfferPost.IsZero() && time.Since(lastResourceOfferPost) < RESOURCE_OFFER_INTERVAL {
 		return nil
 	}
 
 	err := controller.ensureResourceOffers()
 	if err != nil {
+		if due {
+			controller.offersDue.Store(true)
+		}
 		return err
 	}
 
@@ -284,29 +500,65 @@ func (controller *ResourceProviderController) ensureResourceOffers() error {
 
 	addResourceOffers := []data.ResourceOffer{}
 
-	// get the specs from our available compute node(s)
-	computeNodes, err := controller.executor.GetMachineSpecs()
-	if err != nil {
-		controller.log.Error("error getting machine specs", err)
-		return err
+	// with OFFER_TEMPLATES we keep up one offer per template instance
+	// otherwise one per machine the executor has
+	offerCount := len(controller.templateOffers)
+	computeNodes := []data.MachineSpec{}
+	if offerCount == 0 {
+		// get the specs from our available compute node(s)
+		computeNodes, err = controller.executor.GetMachineSpecs()
+		if err != nil {
+			controller.log.Error("error getting machine specs", err)
+			return err
+		}
+		offerCount = len(computeNodes)
 	}
 
-	// map over the specs we have
-	for index, spec := range computeNodes {
+	pricingState := PricingState{
+		Now:         time.Now(),
+		Utilization: controller.utilization(offerCount),
+	}
+
+	// map over the offers we want
+	for index := 0; index < offerCount; index++ {
 
 		// check if the resource offer already exists
 		// if it does then we need to update it
 		// if it doesn't then we need to add it
 		_, ok := existingResourceOffersMap[index]
 		if !ok {
-			addResourceOffers = append(addResourceOffers, controller.getResourceOffer(index, spec))
+			var resourceOffer data.ResourceOffer
+			if template, isTemplate := controller.offerTemplate(index); isTemplate {
+				resourceOffer = controller.getTemplateResourceOffer(index, template)
+			} else {
+				resourceOffer = controller.getResourceOffer(index, withDetectedGPUs(computeNodes[index], controller.gpus, controller.options.Offers.GPUSet))
+			}
+			priced, err := controller.pricing.Price(context.Background(), resourceOffer, pricingState)
+			if err != nil {
+				// an offer at the configured prices beats no offer at all
+				controller.log.Error("error pricing resource offer, posting it with the configured prices", err)
+			} else {
+				resourceOffer = priced
+			}
+			addResourceOffers = append(addResourceOffers, resourceOffer)
 		}
 	}
 
+	// the job slots that are free, an offer over them would only
+	// give the job creator a deal that waits in our queue
+	if allowed := offersAllowed(controller.options.Jobs.MaxConcurrentJobs, len(activeResourceOffers), len(addResourceOffers)); allowed < len(addResourceOffers) {
+		controller.log.Debug(fmt.Sprintf("posting %d of %d resource offers, MAX_CONCURRENT_JOBS is %d", allowed, len(addResourceOffers), controller.options.Jobs.MaxConcurrentJobs), "")
+		addResourceOffers = addResourceOffers[:allowed]
+	}
+
 	// add the resource offers we need to add
 	for _, resourceOffer := range addResourceOffers {
 		controller.log.Info("add resource offer", resourceOffer)
 		_, err := controller.solverClient.AddResourceOffer(resourceOffer)
+		if apierrors.Is(err, apierrors.PriceRejected) {
+			// sending it again will not help until the pricing options change
+			return fmt.Errorf("solver rejected the offer pricing, check the pricing options: %w", err)
+		}
 		if err != nil {
 			return err
 		}
@@ -329,6 +581,10 @@ func (controller *ResourceProviderController) ensureResourceOffers() error {
 
 // list the deals we have been assigned to that we have not yet posted and agree tx to the contract for
 func (controller *ResourceProviderController) agreeToDeals() error {
+	// a deal matched just before our offers came down is left to time out
+	if !controller.acceptingWork() {
+		return nil
+	}
 	// load all deals that are in DealAgreed state and are for us
 	matchedDeals, err := controller.solverClient.GetDealsWithFilter(
 		store.GetDealsQuery{
@@ -343,19 +599,36 @@ func (controller *ResourceProviderController) agreeToDeals() error {
 	if err != nil {
 		return err
 	}
+	// the solver should not have matched us with a module we do not offer
+	// but we never agree to run one if it has
+	offered := []data.DealContainer{}
+	for _, dealContainer := range matchedDeals {
+		if err := controller.checkModule(dealContainer.Deal.ResourceOffer.Index, dealContainer.Deal.JobOffer.Module); err != nil {
+			controller.log.Error(fmt.Sprintf("not agreeing to deal %s", dealContainer.ID), err)
+			continue
+		}
+		offered = append(offered, dealContainer)
+	}
+	matchedDeals = offered
 	if len(matchedDeals) <= 0 {
 		return nil
 	}
 
-	// map over the deals and agree to them
-	for _, dealContainer := range matchedDeals {
+	// agree to the deals, batched into as few transactions as WEB3_TX_BATCH_SIZE allows
+	deals := make([]data.Deal, len(matchedDeals))
+	for i, dealContainer := range matchedDeals {
 		controller.log.Info("agree", dealContainer)
-		txHash, err := controller.web3SDK.Agree(dealContainer.Deal)
+		deals[i] = dealContainer.Deal
+	}
+	for i, agreed := range controller.web3SDK.BatchAgree(deals) {
+		dealContainer := matchedDeals[i]
+		txHash, err := agreed.TxHash, agreed.Err
 		if err != nil {
 			// TODO: we need a way of deciding based on certain classes of error what happens
 			// some will be retryable - otherwise will be fatal
 			// we need a way to exit a job loop as a baseline
 			controller.log.Error("error calling agree tx for deal", err)
+			controller.reportAbandoned(dealContainer.ID, "agree", err)
 			continue
 		}
 		controller.log.Info("agree tx", txHash)
@@ -363,6 +636,7 @@ func (controller *ResourceProviderController) agreeToDeals() error {
 		// we have agreed to the deal so we need to update the tx in the solver
 		_, err = controller.solverClient.UpdateTransactionsResourceProvider(dealContainer.ID, data.DealTransactionsResourceProvider{
 			Agree: txHash,
+			Stuck: controller.web3SDK.StuckTransactions("agree", txHash, nil),
 		})
 		if err != nil {
 			// TODO: we need a way of deciding based on certain classes of error what happens
@@ -378,6 +652,32 @@ func (controller *ResourceProviderController) agreeToDeals() error {
 
 }
 
+// an error when the module is not one the offer at index allows or it is
+// denied, the lists are ours rather than the ones on the deal's offer
+func (controller *ResourceProviderController) checkModule(index int, module data.ModuleConfig) error {
+	moduleID, err := data.GetModuleID(module)
+	if err != nil {
+		return fmt.Errorf("error getting module id: %s", err.Error())
+	}
+	allowed, allowedFrom := controller.options.Offers.Modules, "OFFER_MODULES"
+	denied, deniedFrom := controller.options.Offers.DeniedModules, "OFFER_DENIED_MODULES"
+	if template, ok := controller.offerTemplate(index); ok {
+		if template.Modules != nil {
+			allowed, allowedFrom = template.Modules, "the modules of offer template "+template.Name
+		}
+		if template.DeniedModules != nil {
+			denied, deniedFrom = template.DeniedModules, "the denied_modules of offer template "+template.Name
+		}
+	}
+	if !data.IsModuleAllowed(allowed, module, moduleID) {
+		return fmt.Errorf("module %s@%s is not in %s", module.Repo, module.Hash, allowedFrom)
+	}
+	if data.IsModuleDenied(denied, module, moduleID) {
+		return fmt.Errorf("module %s@%s is in %s", module.Repo, module.Hash, deniedFrom)
+	}
+	return nil
+}
+
 /*
  *
  *
@@ -412,30 +712,73 @@ func (controller *ResourceProviderController) runJobs(ctx context.Context) error
 		return nil
 	}
 
-	// TODO - we are relying on the rate at which we post resource offers
-	// as our capacity manager right now
-	// this will work because we only post resource offers as fast we handle jobs
-	// but it would be worth putting some kind of queue here that is also aware
-	// of the underlying capacity of the machine
-
-	// map over the deals and run them
+	// the deals wait in the job queue for a slot, and a gpu job for the
+	// gpus it needs as well
 	for _, dealContainer := range agreedDeals {
 		func() {
 			controller.runningJobsMutex.Lock()
 			defer controller.runningJobsMutex.Unlock()
 			controller.runningJobs[dealContainer.ID] = true
 		}()
-
-		go controller.runJob(ctx, dealContainer)
+		controller.jobs.push(dealContainer)
 	}
+	controller.startJobs(ctx)
 
 	return err
 }
 
+// starts the queued jobs there are slots and gpus for, it is called again
+// as each job finishes so the next one does not wait for the control loop
+func (controller *ResourceProviderController) startJobs(ctx context.Context) {
+	started := controller.jobs.next(func(dealContainer data.DealContainer) bool {
+		_, ok := controller.acquireDevices(dealContainer)
+		if !ok {
+			controller.log.Debug(fmt.Sprintf("waiting for gpus for deal %s, %d free", dealContainer.ID, controller.devices.free()), dealContainer.Deal.JobOffer.Spec)
+		}
+		return ok
+	})
+	for _, job := range started {
+		// the devices were acquired above, asking again hands back the same ones
+		devices, _ := controller.acquireDevices(job.deal)
+		go func(job queuedJob) {
+			defer func() {
+				controller.jobs.done()
+				controller.startJobs(ctx)
+			}()
+			controller.runJob(ctx, job.deal, devices, time.Since(job.queued))
+		}(job)
+	}
+	if running, waiting := controller.jobs.length(); waiting > 0 {
+		controller.log.Debug(fmt.Sprintf("%d jobs running and %d waiting for a slot", running, waiting), controller.options.Jobs)
+	}
+}
+
+// the gpus the deal's job runs on, none when it does not need any or we
+// have none to hand out
+func (controller *ResourceProviderController) acquireDevices(deal data.DealContainer) ([]int, bool) {
+	if controller.devices == nil {
+		return nil, true
+	}
+	// the solver should not match a job that needs more gpus than we have
+	// but if it does the job gets all of them rather than never running
+	needed := min(devicesNeeded(deal.Deal.JobOffer.Spec), len(controller.gpus))
+	if needed == 0 {
+		return nil, true
+	}
+	return controller.devices.acquire(deal.ID, needed)
+}
+
+func (controller *ResourceProviderController) releaseDevices(dealID string) {
+	if controller.devices != nil {
+		controller.devices.release(dealID)
+	}
+}
+
 // this is run in it's own go-routine
 // we've already updated controller.runningJobs so we know this will only
 // run once
-func (controller *ResourceProviderController) runJob(ctx context.Context, deal data.DealContainer) {
+func (controller *ResourceProviderController) runJob(ctx context.Context, deal data.DealContainer, devices []int, waited time.Duration) {
+	defer controller.releaseDevices(deal.ID)
 	controller.log.Info("run job", deal)
 	controller.log.Info("deal ID", deal.Deal.ID)
 
@@ -464,6 +807,21 @@ func (controller *ResourceProviderController) runJob(ctx context.Context, deal d
 		Error:  "",
 	}
 	err := func() error {
+		timeout, expired := executionTimeout(deal, waited, pricedExecutionLimit(deal, time.Duration(controller.options.Jobs.ExecutionTimeout)*time.Second))
+		if expired {
+			// a result saying so is still better than leaving the job
+			// creator to time the deal out
+			span.SetStatus(codes.Error, "deal expired in the job queue")
+			return fmt.Errorf("%w: the deal's time to submit results ran out after %s waiting for a job slot", executor.ErrExecutionTimeout, waited.Round(time.Second))
+		}
+
+		// the lists may have changed since we agreed to the deal
+		if err := controller.checkModule(deal.Deal.ResourceOffer.Index, deal.Deal.JobOffer.Module); err != nil {
+			span.SetStatus(codes.Error, "module not offered")
+			span.RecordError(err)
+			return err
+		}
+
 		controller.log.Info("loading module", "")
 		span.AddEvent("module.load")
 		module, err := module.LoadModule(deal.Deal.JobOffer.Module, deal.Deal.JobOffer.Inputs)
@@ -475,16 +833,70 @@ func (controller *ResourceProviderController) runJob(ctx context.Context, deal d
 		controller.log.Info("module loaded", module)
 		span.AddEvent("module.loaded")
 
+		if len(devices) > 0 {
+			assignDevices(module, devices)
+			controller.log.Info(fmt.Sprintf("assigned gpus to deal %s", deal.ID), devices)
+			span.SetAttributes(attribute.IntSlice("deal.gpu_devices", devices))
+		}
+		if timeout > 0 {
+			limitExecution(module, timeout)
+			span.SetAttributes(attribute.Int64("deal.execution_timeout", module.Job.Spec.Timeout))
+		}
+
+		// after the module is logged so the values stay out of the logs
+		secrets, err := controller.jobSecrets(deal)
+		if err != nil {
+			span.SetStatus(codes.Error, "load secrets failed")
+			span.RecordError(err)
+			return fmt.Errorf("error loading secrets: %s", err.Error())
+		}
+		if len(secrets) > 0 {
+			span.SetAttributes(attribute.StringSlice("deal.job_offer.secrets", deal.Deal.JobOffer.Secrets))
+		}
+
 		span.AddEvent("executor.job.start")
-		executorResult, err := controller.executor.RunJob(deal, *module)
+		executorResult, err := controller.executeJob(deal, *module, secrets)
+		// the gpus are free for the next job as soon as this one is done
+		// with them rather than once the results are posted
+		controller.releaseDevices(deal.ID)
+		if errors.Is(err, executor.ErrExecutionTimeout) {
+			// the job was killed and the errored result goes on chain
+			// before the job creator can take the deal back
+			controller.log.Error("job ran past its execution timeout", err)
+			span.SetStatus(codes.Error, "job timed out")
+			span.RecordError(err)
+			return err
+		}
+		if errors.Is(err, executor.ErrResourceLimitExceeded) {
+			// the job's fault rather than ours, the result says so and the
+			// mediator would see the same
+			controller.log.Error("job exceeded the resource limits of its deal", err)
+			span.SetStatus(codes.Error, "job exceeded resource limits")
+			span.RecordError(err)
+			return err
+		}
 		if err != nil {
 			controller.log.Error("error running job", err)
 			span.SetStatus(codes.Error, "job execution failed")
 			span.RecordError(err)
 			return fmt.Errorf("error running job: %s", err.Error())
 		}
+		// the results go to the job creator and mediators in the clear
+		// so they are never uploaded with one of the secrets in them
+		leaked, err := findSecretInResults(executorResult.ResultsDir, secrets)
+		if err != nil {
+			span.SetStatus(codes.Error, "scan results failed")
+			span.RecordError(err)
+			return fmt.Errorf("error checking results for secrets: %s", err.Error())
+		}
+		if leaked != "" {
+			os.RemoveAll(executorResult.ResultsDir)
+			span.SetStatus(codes.Error, "results contain a secret")
+			return fmt.Errorf("the job's results contain its secret %s so they were not uploaded", leaked)
+		}
 		result.InstructionCount = uint64(executorResult.InstructionCount)
 		result.DataID = executorResult.ResultsCID
+		result.Usage = executorResult.Usage
 		controller.log.Info("got result", result)
 		span.AddEvent("executor.job.complete")
 
@@ -534,6 +946,7 @@ func (controller *ResourceProviderController) runJob(ctx context.Context, deal d
 	)
 	if err != nil {
 		controller.log.Error("error calling add result tx for job", err)
+		controller.reportAbandoned(deal.ID, "add_result", err)
 		span.SetStatus(codes.Error, "add result to chain failed")
 		span.RecordError(err)
 		return
@@ -543,6 +956,7 @@ func (controller *ResourceProviderController) runJob(ctx context.Context, deal d
 	span.AddEvent("solver.transaction_hash.add")
 	_, err = controller.solverClient.UpdateTransactionsResourceProvider(deal.ID, data.DealTransactionsResourceProvider{
 		AddResult: txHash,
+		Stuck:     controller.web3SDK.StuckTransactions("add_result", txHash, nil),
 	})
 	if err != nil {
 		// TODO: we need a way of deciding based on certain classes of error what happens
@@ -555,7 +969,9 @@ func (controller *ResourceProviderController) runJob(ctx context.Context, deal d
 	}
 	span.AddEvent("solver.transaction_hash.added")
 
-	controller.ensureResourceOffers()
+	// the offer's new state may not have reached us yet so this does
+	// not wait for it
+	controller.replenishOffers()
 
 	span.AddEvent("done")
 }
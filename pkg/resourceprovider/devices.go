@@ -0,0 +1,93 @@
+package resourceprovider
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// hands out the GPU device indexes so jobs running at the same time each
+// get their own, a deal keeps its devices until they are released
+type deviceAllocator struct {
+	mutex    sync.Mutex
+	count    int
+	assigned map[string][]int
+	inUse    map[int]string
+}
+
+func newDeviceAllocator(count int) *deviceAllocator {
+	return &deviceAllocator{
+		count:    count,
+		assigned: map[string][]int{},
+		inUse:    map[int]string{},
+	}
+}
+
+// the lowest free indexes for the deal, false when not enough are free,
+// asking again for a deal that has devices gives it the same ones
+func (allocator *deviceAllocator) acquire(dealID string, count int) ([]int, bool) {
+	allocator.mutex.Lock()
+	defer allocator.mutex.Unlock()
+	if devices, ok := allocator.assigned[dealID]; ok {
+		return devices, true
+	}
+	if count > allocator.count-len(allocator.inUse) {
+		return nil, false
+	}
+	devices := make([]int, 0, count)
+	for index := 0; index < allocator.count && len(devices) < count; index++ {
+		if _, used := allocator.inUse[index]; !used {
+			devices = append(devices, index)
+		}
+	}
+	for _, index := range devices {
+		allocator.inUse[index] = dealID
+	}
+	allocator.assigned[dealID] = devices
+	return devices, true
+}
+
+// frees the deal's devices, releasing a deal twice does nothing
+func (allocator *deviceAllocator) release(dealID string) {
+	allocator.mutex.Lock()
+	defer allocator.mutex.Unlock()
+	for _, index := range allocator.assigned[dealID] {
+		delete(allocator.inUse, index)
+	}
+	delete(allocator.assigned, dealID)
+}
+
+func (allocator *deviceAllocator) free() int {
+	allocator.mutex.Lock()
+	defer allocator.mutex.Unlock()
+	return allocator.count - len(allocator.inUse)
+}
+
+// whole devices, a job asking for part of a GPU still gets one to itself
+func devicesNeeded(spec data.MachineSpec) int {
+	needed := (spec.GPU + 999) / 1000 //nolint:gomnd
+	return max(needed, len(spec.GPUs))
+}
+
+// the nvidia container runtime only exposes the devices listed in
+// NVIDIA_VISIBLE_DEVICES, inside the container they are numbered from 0
+func assignDevices(module *data.Module, devices []int) {
+	sorted := append([]int{}, devices...)
+	sort.Ints(sorted)
+	indexes := make([]string, len(sorted))
+	for i, index := range sorted {
+		indexes[i] = strconv.Itoa(index)
+	}
+	docker := &module.Job.Spec.Docker
+	env := []string{}
+	for _, variable := range docker.EnvironmentVariables {
+		if !strings.HasPrefix(variable, "NVIDIA_VISIBLE_DEVICES=") {
+			env = append(env, variable)
+		}
+	}
+	docker.EnvironmentVariables = append(env, "NVIDIA_VISIBLE_DEVICES="+strings.Join(indexes, ","))
+	module.Job.Spec.Resources.GPU = strconv.Itoa(len(devices))
+}
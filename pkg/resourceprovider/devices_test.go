@@ -0,0 +1,51 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceAllocator(t *testing.T) {
+	allocator := newDeviceAllocator(4)
+
+	devices, ok := allocator.acquire("a", 1)
+	assert.True(t, ok)
+	assert.Equal(t, []int{0}, devices)
+	devices, ok = allocator.acquire("b", 2)
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 2}, devices)
+
+	// the same deal keeps the devices it has
+	devices, _ = allocator.acquire("a", 1)
+	assert.Equal(t, []int{0}, devices)
+
+	_, ok = allocator.acquire("c", 2)
+	assert.False(t, ok)
+	assert.Equal(t, 1, allocator.free())
+
+	allocator.release("a")
+	allocator.release("a")
+	devices, ok = allocator.acquire("c", 2)
+	assert.True(t, ok)
+	assert.Equal(t, []int{0, 3}, devices)
+	assert.Equal(t, 0, allocator.free())
+}
+
+func TestDevicesNeeded(t *testing.T) {
+	assert.Equal(t, 0, devicesNeeded(data.MachineSpec{CPU: 1000}))
+	assert.Equal(t, 1, devicesNeeded(data.MachineSpec{GPU: 500}))
+	assert.Equal(t, 2, devicesNeeded(data.MachineSpec{GPU: 2000}))
+	assert.Equal(t, 2, devicesNeeded(data.MachineSpec{GPU: 1000, GPUs: []data.GPUSpec{{VRAM: 24000}, {VRAM: 24000}}}))
+}
+
+func TestAssignDevices(t *testing.T) {
+	module := data.Module{}
+	module.Job.Spec.Docker.EnvironmentVariables = []string{"NVIDIA_VISIBLE_DEVICES=all", "PROMPT=hello"}
+	assignDevices(&module, []int{3, 1})
+	assert.Equal(t, []string{"PROMPT=hello", "NVIDIA_VISIBLE_DEVICES=1,3"}, module.Job.Spec.Docker.EnvironmentVariables)
+	assert.Equal(t, "2", module.Job.Spec.Resources.GPU)
+}
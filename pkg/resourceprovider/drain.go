@@ -0,0 +1,142 @@
+package resourceprovider
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+// whether we take new work, a paused or draining resource provider posts
+// no offers and agrees to no new deals but finishes the ones it has
+type workState int
+
+const (
+	serving workState = iota
+	paused
+	draining
+)
+
+type drainControl struct {
+	mutex sync.Mutex
+	state workState
+	// closed once a drain has finished every deal we agreed to
+	drained     chan struct{}
+	drainedOnce sync.Once
+}
+
+func newDrainControl() *drainControl {
+	return &drainControl{drained: make(chan struct{})}
+}
+
+func (control *drainControl) get() workState {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	return control.state
+}
+
+// a drain is only undone by Resume
+func (control *drainControl) pause() error {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	if control.state == draining {
+		return fmt.Errorf("the resource provider is draining, resume it first")
+	}
+	control.state = paused
+	return nil
+}
+
+func (control *drainControl) set(state workState) workState {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	previous := control.state
+	control.state = state
+	return previous
+}
+
+// stops posting offers and agreeing to deals until Resume, the jobs of
+// deals we have already agreed to still run
+func (controller *ResourceProviderController) Pause() error {
+	if err := controller.drain.pause(); err != nil {
+		return err
+	}
+	controller.log.Info("paused, no new offers or deals until resumed", "")
+	return controller.withdrawOffers()
+}
+
+// takes new work again after Pause or Drain, a drain that has finished
+// has already stopped the resource provider
+func (controller *ResourceProviderController) Resume() {
+	if previous := controller.drain.set(serving); previous == serving {
+		return
+	}
+	controller.log.Info("resumed, posting offers again", "")
+	// the offers go back up on the next loop rather than in ten minutes
+	controller.replenishOffers()
+}
+
+// stops taking new work like Pause and closes Drained once the deals we
+// agreed to have their results in, so the resource provider can stop
+// without abandoning any of them
+func (controller *ResourceProviderController) Drain() error {
+	controller.drain.set(draining)
+	controller.log.Info("draining, the resource provider stops once its deals are done", "")
+	if err := controller.withdrawOffers(); err != nil {
+		return err
+	}
+	controller.loop.Trigger()
+	return nil
+}
+
+func (controller *ResourceProviderController) Drained() <-chan struct{} {
+	return controller.drain.drained
+}
+
+func (controller *ResourceProviderController) acceptingWork() bool {
+	return controller.drain.get() == serving
+}
+
+// our offers with no deal come off the solver so nothing new is matched
+// with us while we are not taking work
+func (controller *ResourceProviderController) withdrawOffers() error {
+	removed, err := controller.solverClient.RemoveResourceOffers()
+	if err != nil {
+		return fmt.Errorf("error removing resource offers: %s", err.Error())
+	}
+	controller.log.Info(fmt.Sprintf("removed %d resource offers", len(removed)), "")
+	return nil
+}
+
+// closes Drained when a drain has nothing left to do, the deals we have
+// agreed to but the job creator has not yet are still ours to run
+func (controller *ResourceProviderController) checkDrained() error {
+	if controller.drain.get() != draining {
+		return nil
+	}
+	if running, waiting := controller.jobs.length(); running > 0 || waiting > 0 {
+		controller.log.Debug(fmt.Sprintf("draining, %d jobs running and %d waiting", running, waiting), "")
+		return nil
+	}
+	deals, err := controller.solverClient.GetDealsWithFilter(
+		store.GetDealsQuery{
+			ResourceProvider: controller.web3SDK.GetAddress().String(),
+			State:            "DealNegotiating",
+		},
+		func(dealContainer data.DealContainer) bool {
+			return dealContainer.Transactions.ResourceProvider.Agree != ""
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(deals) > 0 {
+		controller.log.Debug(fmt.Sprintf("draining, %d deals waiting for the job creator to agree", len(deals)), "")
+		return nil
+	}
+	controller.drain.drainedOnce.Do(func() {
+		controller.log.Info("drained, every deal is done", "")
+		close(controller.drain.drained)
+	})
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainControl(t *testing.T) {
+	control := newDrainControl()
+	assert.Equal(t, serving, control.get())
+
+	assert.NoError(t, control.pause())
+	assert.Equal(t, paused, control.get())
+
+	// a pause does not undo a drain, only a resume does
+	assert.Equal(t, paused, control.set(draining))
+	assert.Error(t, control.pause())
+	assert.Equal(t, draining, control.get())
+
+	assert.Equal(t, draining, control.set(serving))
+	assert.Equal(t, serving, control.get())
+}
@@ -0,0 +1,118 @@
+package resourceprovider
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// nvidia-smi can take a few seconds while the driver wakes the cards up
+const gpuDetectTimeout = 15 * time.Second
+
+const nvidiaVendor = "NVIDIA"
+
+var cudaVersionPattern = regexp.MustCompile(`CUDA Version:\s*([0-9.]+)`)
+
+// the NVIDIA GPUs nvidia-smi lists, a machine without nvidia-smi has none
+// rather than that being an error so the same options work on every machine
+func DetectGPUs(ctx context.Context) ([]data.GPUSpec, error) {
+	nvidiaSmi, err := exec.LookPath("nvidia-smi")
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, gpuDetectTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, nvidiaSmi, "--query-gpu=name,memory.total,driver_version", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing gpus with nvidia-smi: %w", err)
+	}
+	gpus, err := parseGPUList(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	// the CUDA version is only in the summary nvidia-smi prints, it is the
+	// same for every GPU as it comes from the driver
+	summary, err := exec.CommandContext(ctx, nvidiaSmi).Output()
+	if err == nil {
+		cuda := parseCUDAVersion(string(summary))
+		for i := range gpus {
+			gpus[i].CUDA = cuda
+		}
+	}
+	return gpus, nil
+}
+
+// one "name, memory in MiB, driver" line per GPU
+func parseGPUList(output string) ([]data.GPUSpec, error) {
+	reader := csv.NewReader(strings.NewReader(output))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading nvidia-smi gpu list: %w", err)
+	}
+	gpus := []data.GPUSpec{}
+	for _, record := range records {
+		if len(record) != 3 { //nolint:gomnd
+			return nil, fmt.Errorf("nvidia-smi gpu list has %d fields rather than 3: %q", len(record), strings.Join(record, ","))
+		}
+		vram, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("nvidia-smi gave %q as the memory of %s", record[1], record[0])
+		}
+		gpus = append(gpus, data.GPUSpec{
+			Name:   strings.TrimSpace(record[0]),
+			Vendor: nvidiaVendor,
+			VRAM:   vram,
+			Driver: strings.TrimSpace(record[2]),
+		})
+	}
+	return gpus, nil
+}
+
+func parseCUDAVersion(summary string) string {
+	match := cudaVersionPattern.FindStringSubmatch(summary)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// the spec with the detected GPUs in it, the GPUs the executor reports are
+// kept and only given the driver and CUDA versions it does not know, the
+// detected ones are used when it reports none, when the operator set the
+// milli-gpus we keep them and a spec they gave no GPUs is left alone
+func withDetectedGPUs(spec data.MachineSpec, detected []data.GPUSpec, gpuSet bool) data.MachineSpec {
+	if len(detected) == 0 || (gpuSet && spec.GPU == 0) {
+		return spec
+	}
+	if len(spec.GPUs) == 0 {
+		spec.GPUs = append([]data.GPUSpec{}, detected...)
+	} else {
+		gpus := append([]data.GPUSpec{}, spec.GPUs...)
+		for i := range gpus {
+			if i < len(detected) && gpus[i].Name == detected[i].Name {
+				gpus[i].Driver = detected[i].Driver
+				gpus[i].CUDA = detected[i].CUDA
+			}
+		}
+		spec.GPUs = gpus
+	}
+	if !gpuSet && spec.GPU == 0 {
+		// milli-gpus
+		spec.GPU = len(spec.GPUs) * 1000 //nolint:gomnd
+	}
+	return spec
+}
@@ -0,0 +1,59 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGPUList(t *testing.T) {
+	gpus, err := parseGPUList("NVIDIA GeForce RTX 4090, 24564, 550.54.14\nNVIDIA A100-SXM4-80GB, 81920, 550.54.14\n")
+	require.NoError(t, err)
+	require.Len(t, gpus, 2)
+	assert.Equal(t, data.GPUSpec{Name: "NVIDIA GeForce RTX 4090", Vendor: "NVIDIA", VRAM: 24564, Driver: "550.54.14"}, gpus[0])
+	assert.Equal(t, 81920, gpus[1].VRAM)
+
+	gpus, err = parseGPUList("")
+	require.NoError(t, err)
+	assert.Empty(t, gpus)
+
+	_, err = parseGPUList("NVIDIA GeForce RTX 4090, [N/A], 550.54.14\n")
+	assert.Error(t, err)
+}
+
+func TestParseCUDAVersion(t *testing.T) {
+	summary := "| NVIDIA-SMI 550.54.14              Driver Version: 550.54.14      CUDA Version: 12.4     |"
+	assert.Equal(t, "12.4", parseCUDAVersion(summary))
+	assert.Equal(t, "", parseCUDAVersion("No devices were found"))
+}
+
+func TestWithDetectedGPUs(t *testing.T) {
+	detected := []data.GPUSpec{{Name: "NVIDIA L4", Vendor: "NVIDIA", VRAM: 23034, Driver: "550.54.14", CUDA: "12.4"}}
+
+	spec := withDetectedGPUs(data.MachineSpec{CPU: 8000}, detected, false)
+	assert.Equal(t, 1000, spec.GPU)
+	assert.Equal(t, detected, spec.GPUs)
+
+	// what the executor reports is kept and only given the versions
+	reported := data.MachineSpec{GPU: 1, GPUs: []data.GPUSpec{{Name: "NVIDIA L4", Vendor: "NVIDIA", VRAM: 22000}}}
+	spec = withDetectedGPUs(reported, detected, false)
+	assert.Equal(t, 1, spec.GPU)
+	assert.Equal(t, 22000, spec.GPUs[0].VRAM)
+	assert.Equal(t, "12.4", spec.GPUs[0].CUDA)
+	assert.Empty(t, reported.GPUs[0].CUDA)
+
+	assert.Equal(t, reported, withDetectedGPUs(reported, nil, false))
+
+	// an explicit OFFER_GPU=0 offers no GPUs whatever was detected
+	none := data.MachineSpec{CPU: 8000}
+	assert.Equal(t, none, withDetectedGPUs(none, detected, true))
+
+	// and an explicit count is kept with the detected GPUs listed
+	spec = withDetectedGPUs(data.MachineSpec{GPU: 500}, detected, true)
+	assert.Equal(t, 500, spec.GPU)
+	assert.Equal(t, detected, spec.GPUs)
+}
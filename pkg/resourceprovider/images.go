@@ -0,0 +1,76 @@
+package resourceprovider
+
+import (
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/module"
+	"github.com/lilypad-tech/lilypad/pkg/module/shortcuts"
+)
+
+// the modules an OFFER_MODULES allowlist names a single version of, a repo
+// on its own, a module ID or a pattern does not say which version to pull
+func offeredModules(allowlist []string) []data.ModuleConfig {
+	modules := []data.ModuleConfig{}
+	for _, allowed := range allowlist {
+		if strings.ContainsAny(allowed, "*?[") {
+			continue
+		}
+		if repo, hash, ok := strings.Cut(allowed, "@"); ok && repo != "" && hash != "" {
+			modules = append(modules, data.ModuleConfig{
+				Repo: repo,
+				Hash: hash,
+				Path: shortcuts.LILYPAD_MODULE_CONFIG_PATH,
+			})
+			continue
+		}
+		if !strings.Contains(allowed, "://") && strings.Count(allowed, ":") == 1 {
+			modules = append(modules, data.ModuleConfig{Name: allowed})
+		}
+	}
+	return modules
+}
+
+// pulls the images of the modules we offer so their first job does not
+// wait on a cold pull, it clones every module so it is run in the
+// background and a module that fails only logs
+func (controller *ResourceProviderController) warmImages(warmer executor.ImageWarmer) {
+	images := []string{}
+	for _, config := range offeredModules(controller.allowedModules()) {
+		config, err := module.ProcessModule(config)
+		if err != nil {
+			controller.log.Error("error resolving offered module", err)
+			continue
+		}
+		moduleImages, err := module.GetModuleImages(config)
+		if err != nil {
+			controller.log.Error("error reading the images of "+config.Repo, err)
+			continue
+		}
+		images = append(images, moduleImages...)
+	}
+	controller.log.Info("warming images", images)
+	if err := warmer.WarmImages(images); err != nil {
+		controller.log.Error("error warming images", err)
+	}
+}
+
+// OFFER_MODULES and the modules of every offer template, each once
+func (controller *ResourceProviderController) allowedModules() []string {
+	allowed := []string{}
+	seen := map[string]bool{}
+	lists := [][]string{controller.options.Offers.Modules}
+	for _, template := range controller.options.Offers.Templates {
+		lists = append(lists, template.Modules)
+	}
+	for _, list := range lists {
+		for _, module := range list {
+			if !seen[module] {
+				seen[module] = true
+				allowed = append(allowed, module)
+			}
+		}
+	}
+	return allowed
+}
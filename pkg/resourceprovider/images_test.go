@@ -0,0 +1,25 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/module/shortcuts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfferedModules(t *testing.T) {
+	modules := offeredModules([]string{
+		"cowsay:v0.0.4",
+		"https://github.com/acme/lilypad-module-sdxl@v1.2.0",
+		"https://github.com/acme/lilypad-module-any-version",
+		"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+		"https://github.com/acme/lilypad-module-llm@v1.*",
+	})
+	assert.Equal(t, []data.ModuleConfig{
+		{Name: "cowsay:v0.0.4"},
+		{Repo: "https://github.com/acme/lilypad-module-sdxl", Hash: "v1.2.0", Path: shortcuts.LILYPAD_MODULE_CONFIG_PATH},
+	}, modules)
+}
@@ -0,0 +1,67 @@
+package resourceprovider
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// the agreed deals waiting to run, at most slots of them run at once and
+// the rest wait in the order they were agreed, no slots means no limit
+type jobQueue struct {
+	mutex   sync.Mutex
+	slots   int
+	running int
+	waiting []queuedJob
+}
+
+type queuedJob struct {
+	deal data.DealContainer
+	// the time in the queue comes out of the deal's time to submit results
+	queued time.Time
+}
+
+func newJobQueue(slots int) *jobQueue {
+	return &jobQueue{slots: slots}
+}
+
+func (queue *jobQueue) push(deal data.DealContainer) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	queue.waiting = append(queue.waiting, queuedJob{deal: deal, queued: time.Now()})
+}
+
+// the deals to start now, each takes a slot until done is called for it,
+// a deal canStart refuses keeps its place so a job waiting on gpus does
+// not hold up the ones behind it that can run
+func (queue *jobQueue) next(canStart func(data.DealContainer) bool) []queuedJob {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	started := []queuedJob{}
+	waiting := []queuedJob{}
+	for _, job := range queue.waiting {
+		if (queue.slots > 0 && queue.running >= queue.slots) || !canStart(job.deal) {
+			waiting = append(waiting, job)
+			continue
+		}
+		queue.running++
+		started = append(started, job)
+	}
+	queue.waiting = waiting
+	return started
+}
+
+func (queue *jobQueue) done() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	if queue.running > 0 {
+		queue.running--
+	}
+}
+
+func (queue *jobQueue) length() (running int, waiting int) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	return queue.running, len(queue.waiting)
+}
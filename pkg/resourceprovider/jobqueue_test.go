@@ -0,0 +1,60 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func dealIDs(jobs []queuedJob) []string {
+	ids := []string{}
+	for _, job := range jobs {
+		ids = append(ids, job.deal.ID)
+	}
+	return ids
+}
+
+func TestJobQueueSlots(t *testing.T) {
+	queue := newJobQueue(2)
+	for _, id := range []string{"a", "b", "c"} {
+		queue.push(data.DealContainer{ID: id})
+	}
+	always := func(data.DealContainer) bool { return true }
+
+	assert.Equal(t, []string{"a", "b"}, dealIDs(queue.next(always)))
+	assert.Empty(t, queue.next(always))
+	running, waiting := queue.length()
+	assert.Equal(t, 2, running)
+	assert.Equal(t, 1, waiting)
+
+	queue.done()
+	assert.Equal(t, []string{"c"}, dealIDs(queue.next(always)))
+	running, waiting = queue.length()
+	assert.Equal(t, 2, running)
+	assert.Equal(t, 0, waiting)
+}
+
+func TestJobQueueUnlimited(t *testing.T) {
+	queue := newJobQueue(0)
+	for _, id := range []string{"a", "b", "c"} {
+		queue.push(data.DealContainer{ID: id})
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, dealIDs(queue.next(func(data.DealContainer) bool { return true })))
+}
+
+func TestJobQueueSkipsDealsThatCannotStart(t *testing.T) {
+	queue := newJobQueue(2)
+	for _, id := range []string{"gpu", "a", "b"} {
+		queue.push(data.DealContainer{ID: id})
+	}
+	gpusFree := false
+	canStart := func(deal data.DealContainer) bool { return deal.ID != "gpu" || gpusFree }
+
+	assert.Equal(t, []string{"a", "b"}, dealIDs(queue.next(canStart)))
+	queue.done()
+	gpusFree = true
+	assert.Equal(t, []string{"gpu"}, dealIDs(queue.next(canStart)))
+}
@@ -0,0 +1,21 @@
+package resourceprovider
+
+// an offer of ours that a deal has finished with leaves a machine with
+// nothing on offer, the next loop posts a replacement rather than waiting
+// out RESOURCE_OFFER_INTERVAL
+func (controller *ResourceProviderController) replenishOffers() {
+	controller.offersDue.Store(true)
+	if controller.loop != nil {
+		controller.loop.Trigger()
+	}
+}
+
+// how many of the wanted offers can go up, with MaxConcurrentJobs set we
+// never have more offers out than job slots and the offers that have a
+// deal hold a slot each
+func offersAllowed(slots int, active int, wanted int) int {
+	if slots <= 0 {
+		return wanted
+	}
+	return max(0, min(wanted, slots-active))
+}
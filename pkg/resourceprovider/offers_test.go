@@ -0,0 +1,19 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffersAllowed(t *testing.T) {
+	// no MAX_CONCURRENT_JOBS means an offer for every machine
+	assert.Equal(t, 3, offersAllowed(0, 5, 3))
+
+	assert.Equal(t, 2, offersAllowed(4, 2, 3))
+	assert.Equal(t, 1, offersAllowed(4, 0, 1))
+	assert.Equal(t, 0, offersAllowed(2, 2, 1))
+	assert.Equal(t, 0, offersAllowed(2, 3, 1))
+}
@@ -0,0 +1,229 @@
+package resourceprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+const (
+	// the offers go out with the configured prices
+	PricingStatic = "static"
+	// the price goes up as the job slots fill
+	PricingUtilization = "utilization"
+	// the price changes with the time of day e.g. cheaper overnight
+	PricingTimeOfDay = "time-of-day"
+	// the price follows the token so an instruction costs the same in
+	// the currency the token is quoted in
+	PricingTokenPeg = "token-peg"
+)
+
+var PricingStrategies = []string{PricingStatic, PricingUtilization, PricingTimeOfDay, PricingTokenPeg}
+
+// a time of day window and the percent of the configured price to charge
+// in it
+type PricedWindow struct {
+	Window  data.AvailabilityWindow
+	Percent int
+}
+
+// parse "sat,sun 00:00-24:00 Europe/London=80" style windows, the window
+// part is the same as the offer's availability windows
+func ParsePricedWindow(value string) (PricedWindow, error) {
+	index := strings.LastIndex(value, "=")
+	if index < 0 {
+		return PricedWindow{}, fmt.Errorf("invalid priced window %q, expected \"days start-end [timezone]=percent\"", value)
+	}
+	percent, err := strconv.Atoi(strings.TrimSpace(value[index+1:]))
+	if err != nil || percent <= 0 {
+		return PricedWindow{}, fmt.Errorf("invalid percent in priced window %q", value)
+	}
+	window, err := data.ParseAvailabilityWindow(value[:index])
+	if err != nil {
+		return PricedWindow{}, err
+	}
+	return PricedWindow{Window: window, Percent: percent}, nil
+}
+
+// what a strategy can go on when it prices an offer
+type PricingState struct {
+	Now time.Time
+	// the share of the job slots that are busy from 0 to 1
+	Utilization float64
+}
+
+// works out the prices an offer is posted with, it is asked again before
+// every offer goes out so the prices keep up without a restart
+type PricingStrategy interface {
+	Price(ctx context.Context, offer data.ResourceOffer, state PricingState) (data.ResourceOffer, error)
+}
+
+func NewPricingStrategy(options ResourceProviderPricingStrategyOptions) (PricingStrategy, error) {
+	switch options.Strategy {
+	case "", PricingStatic:
+		return staticPricing{}, nil
+	case PricingUtilization:
+		return utilizationPricing{markup: options.UtilizationMarkup}, nil
+	case PricingTimeOfDay:
+		return timeOfDayPricing{windows: options.TimeOfDay}, nil
+	case PricingTokenPeg:
+		if options.PegPrice <= 0 || options.TokenPriceURL == "" || options.TokenPriceField == "" {
+			return nil, fmt.Errorf("the %s pricing strategy needs a peg price, a token price url and the field the price is in", PricingTokenPeg)
+		}
+		return &tokenPegPricing{
+			pegPrice: options.PegPrice,
+			url:      options.TokenPriceURL,
+			field:    options.TokenPriceField,
+			client:   &http.Client{Timeout: tokenPriceTimeout},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown pricing strategy %s, expected one of %s", options.Strategy, strings.Join(PricingStrategies, ", "))
+	}
+}
+
+type staticPricing struct{}
+
+func (staticPricing) Price(_ context.Context, offer data.ResourceOffer, _ PricingState) (data.ResourceOffer, error) {
+	return offer, nil
+}
+
+// the instruction prices times percent over 100, never below 1 as a zero
+// price gives the work away, the resource prices are scaled with them and
+// the ones we do not charge for stay at zero
+func scaleInstructionPrices(offer data.ResourceOffer, percent float64) data.ResourceOffer {
+	scale := func(pricing data.DealPricing) data.DealPricing {
+		pricing.InstructionPrice = uint64(math.Max(1, math.Round(float64(pricing.InstructionPrice)*percent/100))) //nolint:gomnd
+		return pricing
+	}
+	offer.DefaultPricing = scale(offer.DefaultPricing)
+	modulePricing := map[string]data.DealPricing{}
+	for module, pricing := range offer.ModulePricing {
+		modulePricing[module] = scale(pricing)
+	}
+	offer.ModulePricing = modulePricing
+	tokenPricing := map[string]data.DealPricing{}
+	for token, pricing := range offer.TokenPricing {
+		tokenPricing[token] = scale(pricing)
+	}
+	offer.TokenPricing = tokenPricing
+	if offer.ResourcePricing != nil {
+		scaleResource := func(price uint64) uint64 {
+			return uint64(math.Round(float64(price) * percent / 100)) //nolint:gomnd
+		}
+		offer.ResourcePricing = &data.ResourcePricing{
+			GPUHour:   scaleResource(offer.ResourcePricing.GPUHour),
+			CPUHour:   scaleResource(offer.ResourcePricing.CPUHour),
+			RAMGBHour: scaleResource(offer.ResourcePricing.RAMGBHour),
+			EgressGB:  scaleResource(offer.ResourcePricing.EgressGB),
+		}
+	}
+	return offer
+}
+
+type utilizationPricing struct {
+	markup int
+}
+
+func (strategy utilizationPricing) Price(_ context.Context, offer data.ResourceOffer, state PricingState) (data.ResourceOffer, error) {
+	utilization := math.Min(1, math.Max(0, state.Utilization))
+	return scaleInstructionPrices(offer, 100+float64(strategy.markup)*utilization), nil //nolint:gomnd
+}
+
+type timeOfDayPricing struct {
+	windows []PricedWindow
+}
+
+// the first window we are in sets the price, outside them all it is the
+// configured price
+func (strategy timeOfDayPricing) Price(_ context.Context, offer data.ResourceOffer, state PricingState) (data.ResourceOffer, error) {
+	for _, priced := range strategy.windows {
+		if data.IsAvailable([]data.AvailabilityWindow{priced.Window}, state.Now) {
+			return scaleInstructionPrices(offer, float64(priced.Percent)), nil
+		}
+	}
+	return offer, nil
+}
+
+const tokenPriceTimeout = 10 * time.Second
+
+type tokenPegPricing struct {
+	pegPrice float64
+	url      string
+	field    string
+	client   *http.Client
+}
+
+// only the network token's price is pegged, the TokenPricing prices are
+// for tokens we have no price for
+func (strategy *tokenPegPricing) Price(ctx context.Context, offer data.ResourceOffer, _ PricingState) (data.ResourceOffer, error) {
+	tokenPrice, err := strategy.tokenPrice(ctx)
+	if err != nil {
+		return offer, err
+	}
+	offer.DefaultPricing.InstructionPrice = peggedInstructionPrice(strategy.pegPrice, tokenPrice)
+	return offer, nil
+}
+
+func peggedInstructionPrice(pegPrice float64, tokenPrice float64) uint64 {
+	return uint64(math.Max(1, math.Round(pegPrice/tokenPrice)))
+}
+
+func (strategy *tokenPegPricing) tokenPrice(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strategy.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strategy.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching the token price: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error fetching the token price: %s returned %s", strategy.url, res.Status)
+	}
+	var body interface{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("error decoding the token price: %w", err)
+	}
+	return priceField(body, strategy.field)
+}
+
+// the number at the dot separated path, quoted numbers are allowed as
+// some price apis send them as strings to keep their precision
+func priceField(body interface{}, field string) (float64, error) {
+	value := body
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("the token price response has no %s", field)
+		}
+		value, ok = object[key]
+		if !ok {
+			return 0, fmt.Errorf("the token price response has no %s", field)
+		}
+	}
+	var price float64
+	switch value := value.(type) {
+	case float64:
+		price = value
+	case string:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("the token price %s is %q rather than a number", field, value)
+		}
+		price = parsed
+	default:
+		return 0, fmt.Errorf("the token price %s is not a number", field)
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("the token price %s is %v", field, price)
+	}
+	return price, nil
+}
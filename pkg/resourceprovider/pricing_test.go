@@ -0,0 +1,115 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func pricedOffer(price uint64) data.ResourceOffer {
+	return data.ResourceOffer{
+		DefaultPricing: data.DealPricing{InstructionPrice: price, PaymentCollateral: 20},
+		TokenPricing:   map[string]data.DealPricing{"0xtoken": {InstructionPrice: price * 2}},
+	}
+}
+
+func TestUtilizationPricing(t *testing.T) {
+	strategy, err := NewPricingStrategy(ResourceProviderPricingStrategyOptions{Strategy: PricingUtilization, UtilizationMarkup: 100})
+	assert.NoError(t, err)
+
+	offer, err := strategy.Price(context.Background(), pricedOffer(10), PricingState{Utilization: 0})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), offer.DefaultPricing.InstructionPrice)
+
+	offer, _ = strategy.Price(context.Background(), pricedOffer(10), PricingState{Utilization: 0.5})
+	assert.Equal(t, uint64(15), offer.DefaultPricing.InstructionPrice)
+	assert.Equal(t, uint64(30), offer.TokenPricing["0xtoken"].InstructionPrice)
+	// only the prices move, not the collateral
+	assert.Equal(t, uint64(20), offer.DefaultPricing.PaymentCollateral)
+
+	resourceOffer := pricedOffer(10)
+	resourceOffer.ResourcePricing = &data.ResourcePricing{GPUHour: 100, EgressGB: 2}
+	offer, _ = strategy.Price(context.Background(), resourceOffer, PricingState{Utilization: 0.5})
+	assert.Equal(t, data.ResourcePricing{GPUHour: 150, EgressGB: 3}, *offer.ResourcePricing)
+	// the offer we were given is left alone
+	assert.Equal(t, uint64(100), resourceOffer.ResourcePricing.GPUHour)
+
+	// a queue longer than the slots is still full
+	offer, _ = strategy.Price(context.Background(), pricedOffer(10), PricingState{Utilization: 3})
+	assert.Equal(t, uint64(20), offer.DefaultPricing.InstructionPrice)
+}
+
+func TestTimeOfDayPricing(t *testing.T) {
+	night, err := ParsePricedWindow("mon,tue 00:00-06:00=50")
+	assert.NoError(t, err)
+	strategy, err := NewPricingStrategy(ResourceProviderPricingStrategyOptions{Strategy: PricingTimeOfDay, TimeOfDay: []PricedWindow{night}})
+	assert.NoError(t, err)
+
+	// 2024-01-01 was a monday
+	offer, _ := strategy.Price(context.Background(), pricedOffer(10), PricingState{Now: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)})
+	assert.Equal(t, uint64(5), offer.DefaultPricing.InstructionPrice)
+	offer, _ = strategy.Price(context.Background(), pricedOffer(10), PricingState{Now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)})
+	assert.Equal(t, uint64(10), offer.DefaultPricing.InstructionPrice)
+	// never down to nothing
+	offer, _ = strategy.Price(context.Background(), pricedOffer(1), PricingState{Now: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)})
+	assert.Equal(t, uint64(1), offer.DefaultPricing.InstructionPrice)
+}
+
+func TestParsePricedWindow(t *testing.T) {
+	window, err := ParsePricedWindow("sat,sun 00:00-24:00 Europe/London=80")
+	assert.NoError(t, err)
+	assert.Equal(t, 80, window.Percent)
+	assert.Equal(t, "Europe/London", window.Window.Timezone)
+
+	for _, value := range []string{"sat 00:00-24:00", "sat 00:00-24:00=0", "sat 00:00-24:00=cheap", "someday 00:00-24:00=80"} {
+		_, err := ParsePricedWindow(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTokenPegPricing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"lilypad":{"usd":"0.25"}}`))
+	}))
+	defer server.Close()
+
+	strategy, err := NewPricingStrategy(ResourceProviderPricingStrategyOptions{
+		Strategy:        PricingTokenPeg,
+		PegPrice:        2,
+		TokenPriceURL:   server.URL,
+		TokenPriceField: "lilypad.usd",
+	})
+	assert.NoError(t, err)
+	offer, err := strategy.Price(context.Background(), pricedOffer(1), PricingState{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), offer.DefaultPricing.InstructionPrice)
+	// the other tokens have prices of their own
+	assert.Equal(t, uint64(2), offer.TokenPricing["0xtoken"].InstructionPrice)
+
+	strategy, _ = NewPricingStrategy(ResourceProviderPricingStrategyOptions{
+		Strategy:        PricingTokenPeg,
+		PegPrice:        2,
+		TokenPriceURL:   server.URL,
+		TokenPriceField: "lilypad.eur",
+	})
+	_, err = strategy.Price(context.Background(), pricedOffer(1), PricingState{})
+	assert.ErrorContains(t, err, "lilypad.eur")
+}
+
+func TestNewPricingStrategy(t *testing.T) {
+	_, err := NewPricingStrategy(ResourceProviderPricingStrategyOptions{Strategy: "auction"})
+	assert.Error(t, err)
+	_, err = NewPricingStrategy(ResourceProviderPricingStrategyOptions{Strategy: PricingTokenPeg})
+	assert.Error(t, err)
+	strategy, err := NewPricingStrategy(ResourceProviderPricingStrategyOptions{})
+	assert.NoError(t, err)
+	offer, _ := strategy.Price(context.Background(), pricedOffer(7), PricingState{Utilization: 1})
+	assert.Equal(t, uint64(7), offer.DefaultPricing.InstructionPrice)
+}
@@ -9,12 +9,18 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/uuid"
 	"github.com/holiman/uint256"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/executor"
 	"github.com/lilypad-tech/lilypad/pkg/executor/bacalhau"
+	"github.com/lilypad-tech/lilypad/pkg/executor/container"
+	"github.com/lilypad-tech/lilypad/pkg/executor/firecracker"
+	"github.com/lilypad-tech/lilypad/pkg/executor/kubernetes"
+	"github.com/lilypad-tech/lilypad/pkg/http"
 	"github.com/lilypad-tech/lilypad/pkg/ipfs"
 	"github.com/lilypad-tech/lilypad/pkg/powLogs"
 	"github.com/lilypad-tech/lilypad/pkg/system"
@@ -33,13 +39,18 @@ type ResourceProviderOfferOptions struct {
 	OfferCount int
 	// this represents how many machines we will keep
 	// offering to the network
-	// we can configure this with a config file
-	// to start with we will just add --cpu --gpu and --ram flags
-	// to the resource provider CLI which constrains them to a single machine
+	// the --cpu --gpu and --ram flags give OfferCount of a single machine
+	// or the templates give one spec for each offer they make
 	Specs []data.MachineSpec
+	// the OFFER_TEMPLATES file, several shapes of offer at their own prices
+	// all kept up at once instead of the single spec above
+	TemplatesPath string
+	Templates     []OfferTemplate
 	// the list of modules we are willing to run
 	// an empty list means anything
 	Modules []string
+	// the modules we will not run even when Modules allows them
+	DeniedModules []string
 
 	// this will normally be FixedPrice for RP's
 	Mode data.PricingMode
@@ -53,8 +64,73 @@ type ResourceProviderOfferOptions struct {
 	ModulePricing  map[string]data.DealPricing
 	ModuleTimeouts map[string]data.DealTimeouts
 
+	// the instruction price in other ERC-20 tokens by token address
+	// e.g. 0x...=5, this is what we charge when a job is paid in that token
+	TokenPrices map[string]string
+	// the default pricing with the instruction price from TokenPrices
+	TokenPricing map[string]data.DealPricing
+	// what we charge for the spec and duration of a job on top of the
+	// instruction price, all zero charges per instruction only
+	ResourcePricing data.ResourcePricing
+	// offer the cpu, gpu and ram the last benchmark found with its
+	// attributes and its resource prices scaled from ResourcePricing
+	UseBenchmark bool
+
 	// which mediators and directories this RP will trust
 	Services data.ServiceConfig
+
+	// the availability windows as they were given on the command line
+	// e.g. "sat,sun 00:00-24:00 Europe/London"
+	AvailabilitySpec []string
+	// let the solver pack several concurrent deals onto each offer
+	Packing bool
+	// capabilities that are not part of the machine spec e.g. infiniband=true
+	Attributes map[string]string
+	// where the machine is e.g. eu-west
+	Region string
+
+	// the parsed windows we advertise on each resource offer
+	// an empty list means we are always available
+	Availability []data.AvailabilityWindow
+
+	// look the GPUs up with nvidia-smi at startup and put them in the offers
+	DetectGPUs bool
+	// the operator gave OFFER_GPU or --offer-gpu so the detected GPUs do not change how
+	// many milli-gpus are offered, 0 then means no GPUs at all
+	GPUSet bool
+
+	// how the prices above are adjusted each time an offer is posted
+	PricingStrategy ResourceProviderPricingStrategyOptions
+
+	// take jobs with secrets, their values are encrypted to the key in
+	// SecretsKeyPath which is made the first time it is needed
+	AcceptSecrets  bool
+	SecretsKeyPath string
+
+	// we run in a SEV-SNP or TDX confidential VM and put its quote on our
+	// offers, the certificates are for SEV-SNP hosts that do not give them
+	Attestation             bool
+	AttestationCertificates string
+}
+
+type ResourceProviderPricingStrategyOptions struct {
+	// one of the Pricing* strategies, static posts the prices as they are
+	Strategy string
+	// utilization: the percent the instruction price goes up by when
+	// every job slot is busy, it goes up in proportion below that
+	UtilizationMarkup int
+	// time-of-day: "days start-end [timezone]=percent" windows as they
+	// were given on the command line and parsed
+	TimeOfDaySpec []string
+	TimeOfDay     []PricedWindow
+	// token-peg: what an instruction should cost in the currency the
+	// token price is quoted in, the instruction price is that over the
+	// price of the token
+	PegPrice float64
+	// a url that returns the token price as json and the dot separated
+	// path to the number in it e.g. lilypad.usd
+	TokenPriceURL   string
+	TokenPriceField string
 }
 
 // this configures the pow we will keep track of
@@ -67,13 +143,33 @@ type ResourceProviderPowOptions struct {
 	CudaHashsPerThread int
 }
 
+type ResourceProviderJobOptions struct {
+	// how many jobs run at once, the rest wait in a queue for a slot,
+	// 0 runs every job the solver matches us with straight away
+	MaxConcurrentJobs int
+	// the most seconds a job can run for, the deal's submit results
+	// timeout less the time to post the result is used when it is 0 or
+	// longer than that
+	ExecutionTimeout int
+}
+
 type ResourceProviderOptions struct {
-	Bacalhau  bacalhau.BacalhauExecutorOptions
-	Offers    ResourceProviderOfferOptions
-	Web3      web3.Web3Options
-	Pow       ResourceProviderPowOptions
-	IPFS      ipfs.IPFSOptions
-	Telemetry system.TelemetryOptions
+	// bacalhau, podman, containerd, kubernetes or firecracker
+	Executor         string
+	Bacalhau         bacalhau.BacalhauExecutorOptions
+	Container        container.ContainerExecutorOptions
+	Kubernetes       kubernetes.KubernetesExecutorOptions
+	Firecracker      firecracker.FirecrackerExecutorOptions
+	Offers           ResourceProviderOfferOptions
+	Jobs             ResourceProviderJobOptions
+	Web3             web3.Web3Options
+	Pow              ResourceProviderPowOptions
+	Benchmark        ResourceProviderBenchmarkOptions
+	IPFS             ipfs.IPFSOptions
+	Telemetry        system.TelemetryOptions
+	ClientTLS        http.ClientTLSOptions
+	ClientRetry      http.ClientRetryOptions
+	ClientConnection http.ClientConnectionOptions
 }
 
 type ResourceProvider struct {
@@ -110,6 +206,23 @@ func (resourceProvider *ResourceProvider) Start(ctx context.Context, cm *system.
 	return resourceProvider.controller.Start(ctx, cm)
 }
 
+func (resourceProvider *ResourceProvider) Pause() error {
+	return resourceProvider.controller.Pause()
+}
+
+func (resourceProvider *ResourceProvider) Resume() {
+	resourceProvider.controller.Resume()
+}
+
+func (resourceProvider *ResourceProvider) Drain() error {
+	return resourceProvider.controller.Drain()
+}
+
+// closed once a drain has finished every deal
+func (resourceProvider *ResourceProvider) Drained() <-chan struct{} {
+	return resourceProvider.controller.Drained()
+}
+
 func (resourceProvider *ResourceProvider) StartMineLoop(ctx context.Context) chan error {
 	errorChan := make(chan error, 1)
 	walletAddress := resourceProvider.web3SDK.GetAddress()
@@ -177,7 +290,9 @@ func (resourceProvider *ResourceProvider) StartMineLoop(ctx context.Context) cha
 }
 
 func TriggerNewPowRound(ctx context.Context, web3SDK *web3.Web3SDK) (common.Hash, error) {
-	tx, err := web3SDK.Contracts.Pow.TriggerNewPowRound(web3SDK.TransactOpts)
+	tx, err := web3SDK.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return web3SDK.Contracts().Pow.TriggerNewPowRound(opts)
+	})
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -0,0 +1,122 @@
+package resourceprovider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+// a secret shorter than this could turn up in any results by chance so it
+// is not looked for in them
+const minScannedSecretLength = 8
+
+// the deal's secrets decrypted, they are only ever held in memory and
+// given to the executor apart from the job
+func (controller *ResourceProviderController) jobSecrets(deal data.DealContainer) (map[string]string, error) {
+	names := deal.Deal.JobOffer.Secrets
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if controller.secretsKey == nil {
+		return nil, fmt.Errorf("the job needs secrets and we do not accept them")
+	}
+	if deal.Secrets == "" {
+		// the job creator attaches them before agreeing so a deal from
+		// before that is fetched again
+		latest, err := controller.solverClient.GetDeal(deal.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the secrets for deal %s: %s", deal.ID, err.Error())
+		}
+		deal = latest
+	}
+	if deal.Secrets == "" {
+		return nil, fmt.Errorf("the job creator did not send the job's secrets")
+	}
+	secrets, err := web3.DecryptSecrets(controller.secretsKey, deal.ID, deal.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if _, ok := secrets[name]; !ok {
+			return nil, fmt.Errorf("the job creator did not send secret %s", name)
+		}
+	}
+	return secrets, nil
+}
+
+func canRunWithSecrets(jobExecutor executor.Executor) bool {
+	_, ok := jobExecutor.(executor.SecretsRunner)
+	return ok
+}
+
+// the secrets never go in the job spec, the tools that run jobs keep it
+// and show it, each executor hands them to the job its own way
+func (controller *ResourceProviderController) executeJob(deal data.DealContainer, module data.Module, secrets map[string]string) (*executor.ExecutorResults, error) {
+	if len(secrets) == 0 {
+		return controller.executor.RunJob(deal, module)
+	}
+	runner, ok := controller.executor.(executor.SecretsRunner)
+	if !ok {
+		return nil, fmt.Errorf("the executor cannot give a job its secrets")
+	}
+	return runner.RunJobWithSecrets(deal, module, secrets)
+}
+
+// the first secret found in a results file, the files are read in chunks
+// that overlap by the longest secret so one split across two chunks is
+// still found
+func findSecretInResults(dir string, secrets map[string]string) (string, error) {
+	values := map[string][]byte{}
+	longest := 0
+	for name, value := range secrets {
+		if len(value) >= minScannedSecretLength {
+			values[name] = []byte(value)
+			longest = max(longest, len(value))
+		}
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	found := ""
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || found != "" {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader := bufio.NewReader(file)
+		chunk := make([]byte, 0, 64*1024+longest) //nolint:gomnd
+		for {
+			buffer := chunk[len(chunk):cap(chunk)]
+			n, readErr := io.ReadFull(reader, buffer)
+			chunk = chunk[:len(chunk)+n]
+			for name, value := range values {
+				if bytes.Contains(chunk, value) {
+					found = name
+					return nil
+				}
+			}
+			if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+				return nil
+			}
+			if readErr != nil {
+				return readErr
+			}
+			// keep the tail so a secret across the boundary is still seen
+			keep := min(len(chunk), longest-1)
+			chunk = append(chunk[:0], chunk[len(chunk)-keep:]...)
+		}
+	})
+	return found, err
+}
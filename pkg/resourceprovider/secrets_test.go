@@ -0,0 +1,96 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/executor"
+	"github.com/lilypad-tech/lilypad/pkg/executor/noop"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobSecrets(t *testing.T) {
+	key, err := web3.LoadSecretsKey(filepath.Join(t.TempDir(), "secrets.key"))
+	assert.NoError(t, err)
+	controller := &ResourceProviderController{secretsKey: key}
+
+	deal := data.DealContainer{ID: "deal-1"}
+	deal.Deal.JobOffer.Secrets = []string{"HF_TOKEN"}
+	deal.Secrets, err = web3.EncryptSecrets(web3.SecretsPublicKey(key), "deal-1", map[string]string{"HF_TOKEN": "hf_abcdefgh"})
+	assert.NoError(t, err)
+
+	secrets, err := controller.jobSecrets(deal)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"HF_TOKEN": "hf_abcdefgh"}, secrets)
+
+	deal.Deal.JobOffer.Secrets = []string{"HF_TOKEN", "WANDB_API_KEY"}
+	_, err = controller.jobSecrets(deal)
+	assert.ErrorContains(t, err, "WANDB_API_KEY")
+
+	_, err = (&ResourceProviderController{}).jobSecrets(deal)
+	assert.ErrorContains(t, err, "do not accept")
+
+	secrets, err = controller.jobSecrets(data.DealContainer{ID: "deal-2"})
+	assert.NoError(t, err)
+	assert.Empty(t, secrets)
+}
+
+// takes the secrets apart from the job like the real executors do
+type secretsExecutor struct {
+	*noop.NoopExecutor
+	module  data.Module
+	secrets map[string]string
+}
+
+func (e *secretsExecutor) RunJobWithSecrets(deal data.DealContainer, module data.Module, secrets map[string]string) (*executor.ExecutorResults, error) {
+	e.module = module
+	e.secrets = secrets
+	return &executor.ExecutorResults{}, nil
+}
+
+func TestExecuteJobSecrets(t *testing.T) {
+	noopExecutor, err := noop.NewNoopExecutor(noop.NewNoopExecutorOptions())
+	assert.NoError(t, err)
+	module := data.Module{}
+	module.Job.Spec.Docker.EnvironmentVariables = []string{"MODEL=sdxl"}
+	secrets := map[string]string{"API_KEY": "sk=123"}
+
+	controller := &ResourceProviderController{executor: noopExecutor}
+	assert.False(t, canRunWithSecrets(noopExecutor))
+	_, err = controller.executeJob(data.DealContainer{ID: "deal-1"}, module, secrets)
+	assert.ErrorContains(t, err, "cannot give a job its secrets")
+
+	runner := &secretsExecutor{NoopExecutor: noopExecutor}
+	controller.executor = runner
+	assert.True(t, canRunWithSecrets(runner))
+	_, err = controller.executeJob(data.DealContainer{ID: "deal-1"}, module, secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, secrets, runner.secrets)
+	// the values stay out of the spec the executor is handed
+	assert.Equal(t, []string{"MODEL=sdxl"}, runner.module.Job.Spec.Docker.EnvironmentVariables)
+}
+
+func TestFindSecretInResults(t *testing.T) {
+	dir := t.TempDir()
+	secrets := map[string]string{"HF_TOKEN": "hf_abcdefgh", "SHORT": "abc"}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "stdout"), []byte("abc is too short to look for"), 0644))
+
+	found, err := findSecretInResults(dir, secrets)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	// across the boundary of the first chunk
+	leaked := strings.Repeat("x", 64*1024+5) + "hf_abcdefgh" + strings.Repeat("y", 100)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "outputs"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "outputs", "log.txt"), []byte(leaked), 0644))
+
+	found, err = findSecretInResults(dir, secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, "HF_TOKEN", found)
+}
@@ -0,0 +1,208 @@
+package resourceprovider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// one shape of offer from the OFFER_TEMPLATES file e.g. a GPU tier and a
+// cheaper CPU tier, each is posted Count times and kept up alongside the
+// others, anything a template leaves out comes from the offer flags
+type OfferTemplate struct {
+	Name  string `toml:"name"`
+	Count int    `toml:"count"`
+	// milli-cpus, milli-gpus and megabytes like the OFFER_* flags
+	CPU  int `toml:"cpu"`
+	GPU  int `toml:"gpu"`
+	RAM  int `toml:"ram"`
+	Disk int `toml:"disk"`
+
+	Pricing OfferTemplatePricing `toml:"pricing"`
+
+	// nil uses OFFER_MODULES and OFFER_DENIED_MODULES, modules = [] in the
+	// file means any module
+	Modules       []string `toml:"modules"`
+	DeniedModules []string `toml:"denied_modules"`
+	// added to OFFER_ATTRIBUTES, a template's value wins
+	Attributes map[string]string `toml:"attributes"`
+	Region     string            `toml:"region"`
+}
+
+// the prices a template charges, the ones left out are the defaults
+type OfferTemplatePricing struct {
+	InstructionPrice          *uint64 `toml:"instruction_price"`
+	PaymentCollateral         *uint64 `toml:"payment_collateral"`
+	ResultsCollateralMultiple *uint64 `toml:"results_collateral_multiple"`
+	MediationFee              *uint64 `toml:"mediation_fee"`
+	// resource prices on top of the instruction price, see ResourcePricing
+	GPUHour   *uint64 `toml:"gpu_hour"`
+	CPUHour   *uint64 `toml:"cpu_hour"`
+	RAMGBHour *uint64 `toml:"ram_gb_hour"`
+	EgressGB  *uint64 `toml:"egress_gb"`
+}
+
+type offerTemplatesFile struct {
+	Offers []OfferTemplate `toml:"offer"`
+}
+
+func (pricing OfferTemplatePricing) apply(defaults data.DealPricing) data.DealPricing {
+	if pricing.InstructionPrice != nil {
+		defaults.InstructionPrice = *pricing.InstructionPrice
+	}
+	if pricing.PaymentCollateral != nil {
+		defaults.PaymentCollateral = *pricing.PaymentCollateral
+	}
+	if pricing.ResultsCollateralMultiple != nil {
+		defaults.ResultsCollateralMultiple = *pricing.ResultsCollateralMultiple
+	}
+	if pricing.MediationFee != nil {
+		defaults.MediationFee = *pricing.MediationFee
+	}
+	return defaults
+}
+
+func (pricing OfferTemplatePricing) applyResources(defaults data.ResourcePricing) data.ResourcePricing {
+	if pricing.GPUHour != nil {
+		defaults.GPUHour = *pricing.GPUHour
+	}
+	if pricing.CPUHour != nil {
+		defaults.CPUHour = *pricing.CPUHour
+	}
+	if pricing.RAMGBHour != nil {
+		defaults.RAMGBHour = *pricing.RAMGBHour
+	}
+	if pricing.EgressGB != nil {
+		defaults.EgressGB = *pricing.EgressGB
+	}
+	return defaults
+}
+
+// the machine each of the template's offers is for
+func (template OfferTemplate) Spec() data.MachineSpec {
+	return data.MachineSpec{
+		CPU:  template.CPU,
+		GPU:  template.GPU,
+		RAM:  template.RAM,
+		Disk: template.Disk,
+	}
+}
+
+func LoadOfferTemplates(path string) ([]OfferTemplate, error) {
+	templatesToml, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OFFER_TEMPLATES %s: %w", path, err)
+	}
+	templates, err := ParseOfferTemplates(templatesToml)
+	if err != nil {
+		return nil, fmt.Errorf("OFFER_TEMPLATES %s: %w", path, err)
+	}
+	return templates, nil
+}
+
+// a key we do not know is an error rather than an offer that quietly
+// lacks what was meant e.g. instruction_prise
+func ParseOfferTemplates(templatesToml []byte) ([]OfferTemplate, error) {
+	var file offerTemplatesFile
+	metadata, err := toml.Decode(string(templatesToml), &file)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		keys := []string{}
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, fmt.Errorf("unknown keys %s", strings.Join(keys, ", "))
+	}
+	if len(file.Offers) == 0 {
+		return nil, fmt.Errorf("there are no [[offer]] templates")
+	}
+	names := map[string]bool{}
+	for i := range file.Offers {
+		template := &file.Offers[i]
+		if template.Name == "" {
+			template.Name = fmt.Sprintf("offer-%d", i+1)
+		}
+		if names[template.Name] {
+			return nil, fmt.Errorf("there are two offer templates named %s", template.Name)
+		}
+		names[template.Name] = true
+		if template.Count == 0 {
+			template.Count = 1
+		}
+		if template.Count < 0 {
+			return nil, fmt.Errorf("offer template %s has a negative count", template.Name)
+		}
+		if template.CPU <= 0 || template.RAM <= 0 {
+			return nil, fmt.Errorf("offer template %s needs cpu and ram", template.Name)
+		}
+		if template.GPU < 0 || template.Disk < 0 {
+			return nil, fmt.Errorf("offer template %s cannot have negative gpu or disk", template.Name)
+		}
+		if err := data.CheckModulePatterns(template.Modules); err != nil {
+			return nil, fmt.Errorf("offer template %s modules: %s", template.Name, err.Error())
+		}
+		if err := data.CheckModulePatterns(template.DeniedModules); err != nil {
+			return nil, fmt.Errorf("offer template %s denied_modules: %s", template.Name, err.Error())
+		}
+	}
+	return file.Offers, nil
+}
+
+// one template per offer we keep up, the position in the list is the
+// offer's index so a template keeps its index across restarts as long as
+// the ones above it in the file are unchanged
+func ExpandOfferTemplates(templates []OfferTemplate) []OfferTemplate {
+	offers := []OfferTemplate{}
+	for _, template := range templates {
+		for i := 0; i < template.Count; i++ {
+			offers = append(offers, template)
+		}
+	}
+	return offers
+}
+
+// the template the offer at index was made from
+func (controller *ResourceProviderController) offerTemplate(index int) (OfferTemplate, bool) {
+	offers := controller.templateOffers
+	if index < 0 || index >= len(offers) {
+		return OfferTemplate{}, false
+	}
+	return offers[index], true
+}
+
+// the offer flags with what the template sets over them
+func (controller *ResourceProviderController) getTemplateResourceOffer(index int, template OfferTemplate) data.ResourceOffer {
+	spec := template.Spec()
+	// the gpus are only listed on the templates that offer them
+	if spec.GPU > 0 {
+		spec = withDetectedGPUs(spec, controller.gpus, true)
+	}
+	resourceOffer := controller.getResourceOffer(index, spec)
+	resourceOffer.DefaultPricing = template.Pricing.apply(resourceOffer.DefaultPricing)
+	resourceOffer.ResourcePricing = resourcePricing(template.Pricing.applyResources(controller.options.Offers.ResourcePricing))
+	if template.Modules != nil {
+		resourceOffer.Modules = template.Modules
+	}
+	if template.DeniedModules != nil {
+		resourceOffer.DeniedModules = template.DeniedModules
+	}
+	if len(template.Attributes) > 0 {
+		attributes := map[string]string{}
+		for name, value := range resourceOffer.Attributes {
+			attributes[name] = value
+		}
+		for name, value := range template.Attributes {
+			attributes[name] = value
+		}
+		resourceOffer.Attributes = attributes
+	}
+	if template.Region != "" {
+		resourceOffer.Region = template.Region
+	}
+	return resourceOffer
+}
@@ -0,0 +1,85 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOfferTemplates = `
+[[offer]]
+name = "a100"
+cpu = 16000
+gpu = 1000
+ram = 65536
+modules = ["https://github.com/acme/*"]
+[offer.pricing]
+instruction_price = 20
+[offer.attributes]
+infiniband = "true"
+
+[[offer]]
+count = 3
+cpu = 2000
+ram = 4096
+modules = []
+`
+
+func TestParseOfferTemplates(t *testing.T) {
+	templates, err := ParseOfferTemplates([]byte(testOfferTemplates))
+	assert.NoError(t, err)
+	assert.Len(t, templates, 2)
+
+	assert.Equal(t, "a100", templates[0].Name)
+	assert.Equal(t, 1, templates[0].Count)
+	assert.Equal(t, data.MachineSpec{CPU: 16000, GPU: 1000, RAM: 65536}, templates[0].Spec())
+	assert.Equal(t, map[string]string{"infiniband": "true"}, templates[0].Attributes)
+	assert.Nil(t, templates[0].DeniedModules)
+
+	// an empty list is any module rather than OFFER_MODULES
+	assert.Equal(t, "offer-2", templates[1].Name)
+	assert.Equal(t, 3, templates[1].Count)
+	assert.NotNil(t, templates[1].Modules)
+	assert.Empty(t, templates[1].Modules)
+
+	offers := ExpandOfferTemplates(templates)
+	assert.Len(t, offers, 4)
+	assert.Equal(t, "a100", offers[0].Name)
+	assert.Equal(t, "offer-2", offers[3].Name)
+}
+
+func TestParseOfferTemplatesErrors(t *testing.T) {
+	for name, templates := range map[string]string{
+		"no templates":   ``,
+		"unknown key":    "[[offer]]\ncpu = 1000\nram = 1024\ninstruction_prise = 5\n",
+		"no ram":         "[[offer]]\ncpu = 1000\n",
+		"negative count": "[[offer]]\ncount = -1\ncpu = 1000\nram = 1024\n",
+		"same name":      "[[offer]]\nname = \"a\"\ncpu = 1000\nram = 1024\n[[offer]]\nname = \"a\"\ncpu = 1000\nram = 1024\n",
+		"bad pattern":    "[[offer]]\ncpu = 1000\nram = 1024\nmodules = [\"[\"]\n",
+	} {
+		_, err := ParseOfferTemplates([]byte(templates))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestOfferTemplatePricing(t *testing.T) {
+	defaults := data.DealPricing{
+		InstructionPrice:          1,
+		PaymentCollateral:         5,
+		ResultsCollateralMultiple: 4,
+		MediationFee:              2,
+	}
+	assert.Equal(t, defaults, OfferTemplatePricing{}.apply(defaults))
+
+	price := uint64(20)
+	collateral := uint64(50)
+	assert.Equal(t, data.DealPricing{
+		InstructionPrice:          20,
+		PaymentCollateral:         50,
+		ResultsCollateralMultiple: 4,
+		MediationFee:              2,
+	}, OfferTemplatePricing{InstructionPrice: &price, PaymentCollateral: &collateral}.apply(defaults))
+}
@@ -0,0 +1,58 @@
+package resourceprovider
+
+import (
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// what is kept back from the deal's submit results timeout to upload the
+// results and post them on chain once the job stops
+const resultPostingTime = 5 * time.Minute
+
+// how long the deal's job can run for, the job creator can take back the
+// payment once the submit results timeout passes so the job is stopped
+// with time left to post a result saying it timed out, limit caps it and
+// is all there is when the deal has no timeout, 0 is no timeout at all,
+// expired is a deal that used up its time waiting in the queue
+func executionTimeout(deal data.DealContainer, waited time.Duration, limit time.Duration) (timeout time.Duration, expired bool) {
+	window := time.Duration(deal.Deal.Timeouts.SubmitResults.Timeout) * time.Second
+	if window == 0 {
+		return limit, false
+	}
+	timeout = window - min(resultPostingTime, window/4) - waited //nolint:gomnd
+	if timeout <= 0 {
+		return 0, true
+	}
+	if limit > 0 && limit < timeout {
+		return limit, false
+	}
+	return timeout, false
+}
+
+// the executor kills the job once the timeout passes, a module that asks
+// for less time than that keeps its own
+func limitExecution(module *data.Module, timeout time.Duration) {
+	seconds := int64((timeout + time.Second - 1) / time.Second)
+	if seconds <= 0 {
+		return
+	}
+	spec := &module.Job.Spec
+	if spec.Timeout == 0 || spec.Timeout > seconds {
+		spec.Timeout = seconds
+	}
+}
+
+// with resource pricing the job is only paid for the duration its job
+// offer gave so it is stopped once that runs out
+func pricedExecutionLimit(deal data.DealContainer, limit time.Duration) time.Duration {
+	jobOffer := deal.Deal.JobOffer
+	if deal.Deal.ResourceOffer.ResourcePricing == nil || jobOffer.PaymentToken != "" || jobOffer.Duration <= 0 {
+		return limit
+	}
+	priced := time.Duration(jobOffer.Duration) * time.Second
+	if limit == 0 || priced < limit {
+		return priced
+	}
+	return limit
+}
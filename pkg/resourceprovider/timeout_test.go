@@ -0,0 +1,76 @@
+//go:build unit
+
+package resourceprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func submitResultsDeal(seconds uint64) data.DealContainer {
+	deal := data.DealContainer{}
+	deal.Deal.Timeouts.SubmitResults.Timeout = seconds
+	return deal
+}
+
+func TestExecutionTimeout(t *testing.T) {
+	// an hour to submit results keeps five minutes back to post them
+	timeout, expired := executionTimeout(submitResultsDeal(3600), 0, 0)
+	assert.False(t, expired)
+	assert.Equal(t, 55*time.Minute, timeout)
+
+	// a short window keeps back a quarter of it
+	timeout, _ = executionTimeout(submitResultsDeal(120), 0, 0)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	timeout, _ = executionTimeout(submitResultsDeal(3600), 15*time.Minute, 0)
+	assert.Equal(t, 40*time.Minute, timeout)
+
+	timeout, _ = executionTimeout(submitResultsDeal(3600), 0, 10*time.Minute)
+	assert.Equal(t, 10*time.Minute, timeout)
+
+	// a limit longer than the deal allows does not help the job
+	timeout, _ = executionTimeout(submitResultsDeal(3600), 0, 2*time.Hour)
+	assert.Equal(t, 55*time.Minute, timeout)
+
+	timeout, expired = executionTimeout(submitResultsDeal(0), time.Hour, 0)
+	assert.False(t, expired)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	_, expired = executionTimeout(submitResultsDeal(3600), time.Hour, 0)
+	assert.True(t, expired)
+}
+
+func TestPricedExecutionLimit(t *testing.T) {
+	deal := submitResultsDeal(3600)
+	deal.Deal.JobOffer.Duration = 600
+	// per instruction pricing does not care how long the job takes
+	assert.Equal(t, time.Duration(0), pricedExecutionLimit(deal, 0))
+
+	deal.Deal.ResourceOffer.ResourcePricing = &data.ResourcePricing{CPUHour: 10}
+	assert.Equal(t, 10*time.Minute, pricedExecutionLimit(deal, 0))
+	assert.Equal(t, 5*time.Minute, pricedExecutionLimit(deal, 5*time.Minute))
+	assert.Equal(t, 10*time.Minute, pricedExecutionLimit(deal, time.Hour))
+
+	// other tokens only pay the instruction price
+	deal.Deal.JobOffer.PaymentToken = "0x1234"
+	assert.Equal(t, time.Duration(0), pricedExecutionLimit(deal, 0))
+}
+
+func TestLimitExecution(t *testing.T) {
+	module := data.Module{}
+	limitExecution(&module, 90*time.Second)
+	assert.Equal(t, int64(90), module.Job.Spec.Timeout)
+
+	// the module's own shorter timeout is kept
+	module.Job.Spec.Timeout = 30
+	limitExecution(&module, 90*time.Second)
+	assert.Equal(t, int64(30), module.Job.Spec.Timeout)
+
+	module.Job.Spec.Timeout = 600
+	limitExecution(&module, 1500*time.Millisecond)
+	assert.Equal(t, int64(2), module.Job.Spec.Timeout)
+}
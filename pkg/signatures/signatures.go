@@ -0,0 +1,163 @@
+package signatures
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// what isValidSignature returns when the wallet accepts the signature
+var eip1271MagicValue = []byte{0x16, 0x26, 0xba, 0x7e}
+
+const eip1271ABIJSON = `[{"inputs":[{"internalType":"bytes32","name":"hash","type":"bytes32"},{"internalType":"bytes","name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"internalType":"bytes4","name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+var eip1271ABI = sync.OnceValues(func() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(eip1271ABIJSON))
+})
+
+// how many smart contract wallets we remember and for how long
+const (
+	contractCacheSize = 10000
+	contractCacheTTL  = time.Hour
+)
+
+// the chain reads EIP-1271 needs, the web3 FailoverClient has them
+type Backend interface {
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// checks that an address signed something, an EOA by recovering the key
+// from the signature and a smart contract wallet by asking it through
+// EIP-1271, with no backend only EOA signatures can be checked
+type Verifier struct {
+	backend Backend
+	// the addresses we found code at, the same wallets sign every
+	// request they send, an address without code is not kept as it can
+	// be deployed to later and anyone can send us any number of them
+	contracts *expirable.LRU[common.Address, struct{}]
+}
+
+func NewVerifier(backend Backend) *Verifier {
+	return &Verifier{
+		backend:   backend,
+		contracts: expirable.NewLRU[common.Address, struct{}](contractCacheSize, nil, contractCacheTTL),
+	}
+}
+
+var defaultVerifier atomic.Pointer[Verifier]
+
+func init() {
+	defaultVerifier.Store(NewVerifier(nil))
+}
+
+// the verifier the request signature checks use, it only knows EOA
+// signatures until a service with an rpc node sets one with SetDefault
+func Default() *Verifier {
+	return defaultVerifier.Load()
+}
+
+func SetDefault(verifier *Verifier) {
+	defaultVerifier.Store(verifier)
+}
+
+// the address that made the 65 byte signature over the hash, V can be
+// 0/1 or 27/28 so signatures from a wallet are taken as well as our own
+func Recover(hash []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes", crypto.SignatureLength)
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// nil when the address signed the hash, ErrInvalidSignature when it did
+// not and any other error when the wallet could not be asked
+func (verifier *Verifier) Verify(ctx context.Context, address common.Address, hash []byte, signature []byte) error {
+	if recovered, err := Recover(hash, signature); err == nil && recovered == address {
+		return nil
+	}
+	if verifier == nil || verifier.backend == nil {
+		return ErrInvalidSignature
+	}
+	isContract, err := verifier.isContract(ctx, address)
+	if err != nil {
+		return err
+	}
+	if !isContract {
+		return ErrInvalidSignature
+	}
+	return verifier.verifyContract(ctx, address, hash, signature)
+}
+
+// our signers sign the keccak256 of the message without the EIP-191 prefix,
+// this is how the request headers are signed
+func (verifier *Verifier) VerifyMessage(ctx context.Context, address common.Address, message []byte, signature []byte) error {
+	return verifier.Verify(ctx, address, crypto.Keccak256(message), signature)
+}
+
+func (verifier *Verifier) VerifyTypedData(ctx context.Context, address common.Address, typedData apitypes.TypedData, signature []byte) error {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return fmt.Errorf("error encoding typed data: %w", err)
+	}
+	return verifier.Verify(ctx, address, hash, signature)
+}
+
+func (verifier *Verifier) isContract(ctx context.Context, address common.Address) (bool, error) {
+	if verifier.contracts.Contains(address) {
+		return true, nil
+	}
+	code, err := verifier.backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, fmt.Errorf("error looking up code for %s: %w", address.Hex(), err)
+	}
+	if len(code) == 0 {
+		return false, nil
+	}
+	verifier.contracts.Add(address, struct{}{})
+	return true, nil
+}
+
+func (verifier *Verifier) verifyContract(ctx context.Context, address common.Address, hash []byte, signature []byte) error {
+	contractABI, err := eip1271ABI()
+	if err != nil {
+		return err
+	}
+	input, err := contractABI.Pack("isValidSignature", common.BytesToHash(hash), signature)
+	if err != nil {
+		return err
+	}
+	output, err := verifier.backend.CallContract(ctx, ethereum.CallMsg{To: &address, Data: input}, nil)
+	if err != nil {
+		// wallets revert for signatures they do not accept as well as
+		// returning something other than the magic value
+		return ErrInvalidSignature
+	}
+	if len(output) < len(eip1271MagicValue) || !bytes.Equal(output[:len(eip1271MagicValue)], eip1271MagicValue) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package signatures
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a smart wallet that accepts signatures made by its owner
+type fakeWalletBackend struct {
+	t       *testing.T
+	wallet  common.Address
+	owner   common.Address
+	codeAts int
+}
+
+func (backend *fakeWalletBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	backend.codeAts++
+	if contract == backend.wallet {
+		return []byte{0x60, 0x80}, nil
+	}
+	return nil, nil
+}
+
+func (backend *fakeWalletBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	require.Equal(backend.t, backend.wallet, *call.To)
+	contractABI, err := eip1271ABI()
+	require.NoError(backend.t, err)
+	args, err := contractABI.Methods["isValidSignature"].Inputs.Unpack(call.Data[4:])
+	require.NoError(backend.t, err)
+	hash := args[0].([32]byte)
+	signer, err := Recover(hash[:], args[1].([]byte))
+	if err != nil || signer != backend.owner {
+		return nil, errors.New("execution reverted")
+	}
+	return contractABI.Methods["isValidSignature"].Outputs.Pack([4]byte(eip1271MagicValue))
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	ownerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(ownerKey.PublicKey)
+	wallet := common.HexToAddress("0x1271")
+	message := []byte("hello")
+	signature, err := crypto.Sign(crypto.Keccak256(message), ownerKey)
+	require.NoError(t, err)
+
+	// an EOA needs no backend, and wallets send V as 27/28
+	eoaOnly := NewVerifier(nil)
+	assert.NoError(t, eoaOnly.VerifyMessage(ctx, owner, message, signature))
+	walletSignature := common.CopyBytes(signature)
+	walletSignature[crypto.RecoveryIDOffset] += 27
+	assert.NoError(t, eoaOnly.VerifyMessage(ctx, owner, message, walletSignature))
+	assert.ErrorIs(t, eoaOnly.VerifyMessage(ctx, wallet, message, signature), ErrInvalidSignature)
+
+	backend := &fakeWalletBackend{t: t, wallet: wallet, owner: owner}
+	verifier := NewVerifier(backend)
+	assert.NoError(t, verifier.VerifyMessage(ctx, owner, message, signature))
+	assert.Equal(t, 0, backend.codeAts)
+	assert.NoError(t, verifier.VerifyMessage(ctx, wallet, message, signature))
+
+	// the wallet turns away anyone but its owner
+	otherSignature, err := crypto.Sign(crypto.Keccak256(message), otherKey)
+	require.NoError(t, err)
+	assert.ErrorIs(t, verifier.VerifyMessage(ctx, wallet, message, otherSignature), ErrInvalidSignature)
+	assert.ErrorIs(t, verifier.VerifyMessage(ctx, wallet, []byte("other"), signature), ErrInvalidSignature)
+	// an address without code is never asked
+	assert.ErrorIs(t, verifier.VerifyMessage(ctx, crypto.PubkeyToAddress(otherKey.PublicKey), message, signature), ErrInvalidSignature)
+	// the wallet's code is looked up once
+	assert.Equal(t, 2, backend.codeAts)
+	// but an address without code is looked up every time
+	assert.ErrorIs(t, verifier.VerifyMessage(ctx, crypto.PubkeyToAddress(otherKey.PublicKey), message, signature), ErrInvalidSignature)
+	assert.Equal(t, 3, backend.codeAts)
+}
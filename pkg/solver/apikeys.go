@@ -0,0 +1,131 @@
+package solver
+
+import (
+	"fmt"
+	corehttp "net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/rs/zerolog/log"
+)
+
+type apiKeyRequest struct {
+	Name string `json:"name"`
+}
+
+func (solverServer *solverServer) isAdmin(address string) bool {
+	admins := solverServer.options.AccessControl.Admins
+	return address != "" && slices.ContainsFunc(admins, func(admin string) bool { return strings.EqualFold(admin, address) })
+}
+
+// only the addresses in the admin list can manage api keys
+func (solverServer *solverServer) checkAdmin(req *corehttp.Request) (string, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error checking signature")
+		return "", err
+	}
+	if !solverServer.isAdmin(signerAddress) {
+		return "", http.HTTPError{
+			Message:    fmt.Sprintf("%s is not an admin", signerAddress),
+			StatusCode: corehttp.StatusForbidden,
+		}
+	}
+	return signerAddress, nil
+}
+
+func (solverServer *solverServer) getAPIKeys(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.APIKey, error) {
+	if _, err := solverServer.checkAdmin(req); err != nil {
+		return nil, err
+	}
+	return solverServer.store.GetAPIKeys()
+}
+
+// the key is only ever in this response, the store just keeps its hash
+func (solverServer *solverServer) addAPIKey(payload apiKeyRequest, res corehttp.ResponseWriter, req *corehttp.Request) (*data.CreatedAPIKey, error) {
+	adminAddress, err := solverServer.checkAdmin(req)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Name == "" {
+		return nil, http.HTTPError{
+			Message:    "an api key needs a name",
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	key, err := http.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := solverServer.store.AddAPIKey(data.APIKey{
+		ID:        uuid.New().String(),
+		Name:      payload.Name,
+		Hash:      http.HashAPIKey(key),
+		CreatedBy: adminAddress,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("id", apiKey.ID).Str("name", apiKey.Name).Str("admin", adminAddress).Msgf("added api key")
+	return &data.CreatedAPIKey{
+		APIKey: *apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (solverServer *solverServer) removeAPIKey(res corehttp.ResponseWriter, req *corehttp.Request) (*data.APIKey, error) {
+	adminAddress, err := solverServer.checkAdmin(req)
+	if err != nil {
+		return nil, err
+	}
+	id := mux.Vars(req)["id"]
+	keys, err := solverServer.store.GetAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(keys, func(key data.APIKey) bool { return key.ID == id })
+	if index < 0 {
+		return nil, http.HTTPError{
+			Message:    "api key not found",
+			StatusCode: corehttp.StatusNotFound,
+		}
+	}
+	if err := solverServer.store.RemoveAPIKey(id); err != nil {
+		return nil, err
+	}
+	log.Info().Str("id", id).Str("admin", adminAddress).Msgf("removed api key")
+	return &keys[index], nil
+}
+
+// with read auth turned on every GET needs an api key or a wallet signature,
+// the writes already check signatures in their handlers and an api key
+// never lets anyone write so keys are read only
+func (solverServer *solverServer) readAuthMiddleware(next corehttp.Handler) corehttp.Handler {
+	lookup := func(hash string) (bool, error) {
+		key, err := solverServer.store.GetAPIKeyByHash(hash)
+		return key != nil, err
+	}
+	return corehttp.HandlerFunc(func(res corehttp.ResponseWriter, req *corehttp.Request) {
+		// websocket clients cannot sign the upgrade request, they only
+		// get the events for the addresses they connect with and none
+		// at all when they do not give one
+		if !solverServer.options.AccessControl.RequireReadAuth ||
+			!isReadRequest(req) ||
+			strings.HasSuffix(req.URL.Path, http.WEBSOCKET_SUB_PATH) {
+			next.ServeHTTP(res, req)
+			return
+		}
+		err := http.CheckAPIKeyOrSignature(req, lookup)
+		if err != nil {
+			http.WriteError(res, req, err)
+			return
+		}
+		next.ServeHTTP(res, req)
+	})
+}
@@ -0,0 +1,227 @@
+package solver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	corehttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+type SolverAuditOptions struct {
+	// how many days audit entries are kept for, 0 keeps them forever
+	Retention int
+}
+
+// records every signed request that is not a read, including the ones
+// the handler turned away, so operators can see who tried to change what
+func (solverServer *solverServer) auditMiddleware(next corehttp.Handler) corehttp.Handler {
+	return corehttp.HandlerFunc(func(res corehttp.ResponseWriter, req *corehttp.Request) {
+		if isReadRequest(req) {
+			next.ServeHTTP(res, req)
+			return
+		}
+		signerAddress, err := http.CheckSignature(req)
+		if err != nil {
+			// unsigned writes are turned away by their handlers
+			next.ServeHTTP(res, req)
+			return
+		}
+		body := &hashingBody{ReadCloser: req.Body, hash: sha256.New()}
+		req.Body = body
+		recorder := &auditRecorder{ResponseWriter: res, status: corehttp.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		route := req.URL.Path
+		if current := mux.CurrentRoute(req); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		solverServer.addAuditEntry(data.AuditEntry{
+			Address:     signerAddress,
+			Method:      req.Method,
+			Route:       route,
+			Path:        req.URL.Path,
+			PayloadHash: hex.EncodeToString(body.hash.Sum(nil)),
+			StatusCode:  recorder.status,
+			RequestID:   http.RequestIDFromContext(req.Context()),
+			IP:          solverServer.clientIP.ClientIP(req),
+		})
+	})
+}
+
+// the gRPC writes do not go through the router so they record themselves
+func (solverServer *solverServer) auditGRPC(ctx context.Context, method string, signerAddress string, req proto.Message, err error) {
+	payload, marshalErr := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msgf("error marshalling audited grpc request")
+	}
+	payloadHash := sha256.Sum256(payload)
+	entry := data.AuditEntry{
+		Address:     signerAddress,
+		Method:      "GRPC",
+		Route:       method,
+		Path:        method,
+		PayloadHash: hex.EncodeToString(payloadHash[:]),
+		StatusCode:  auditStatus(err),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(strings.ToLower(http.X_REQUEST_ID_HEADER)); len(ids) > 0 {
+			entry.RequestID = ids[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		host, _, splitErr := net.SplitHostPort(p.Addr.String())
+		if splitErr == nil {
+			entry.IP = host
+		}
+	}
+	solverServer.addAuditEntry(entry)
+}
+
+// the status the REST api would have sent for err
+func auditStatus(err error) int {
+	if err == nil {
+		return corehttp.StatusOK
+	}
+	var httpError http.HTTPError
+	if errors.As(err, &httpError) && httpError.StatusCode != 0 {
+		return httpError.StatusCode
+	}
+	return corehttp.StatusInternalServerError
+}
+
+// a failed write to the audit store does not fail the request,
+// the call has already happened by the time we get here
+func (solverServer *solverServer) addAuditEntry(entry data.AuditEntry) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Error().Err(err).Msgf("error making audit entry id")
+		return
+	}
+	entry.ID = id.String()
+	entry.Address = strings.ToLower(entry.Address)
+	entry.CreatedAt = time.Now().UnixMilli()
+	_, err = solverServer.store.AddAuditEntry(entry)
+	if err != nil {
+		log.Error().Err(err).
+			Str("address", entry.Address).
+			Str("route", entry.Route).
+			Str("request_id", entry.RequestID).
+			Msgf("error saving audit entry")
+	}
+}
+
+// hashes the body as the handler reads it so uploads are not buffered,
+// a handler that stops early leaves a hash of the part it read
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+func (body *hashingBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	body.hash.Write(p[:n])
+	return n, err
+}
+
+type auditRecorder struct {
+	corehttp.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (recorder *auditRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *auditRecorder) Write(data []byte) (int, error) {
+	recorder.wroteHeader = true
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *auditRecorder) Unwrap() corehttp.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+func (solverServer *solverServer) getAuditEntries(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.AuditEntry, error) {
+	if _, err := solverServer.checkAdmin(req); err != nil {
+		return nil, err
+	}
+	query := store.GetAuditEntriesQuery{
+		Address: strings.ToLower(req.URL.Query().Get("address")),
+		Method:  strings.ToUpper(req.URL.Query().Get("method")),
+		Route:   req.URL.Query().Get("route"),
+	}
+	for name, value := range map[string]*int64{"since": &query.Since, "until": &query.Until} {
+		param := req.URL.Query().Get(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(param, 10, 64)
+		if err != nil || parsed < 0 {
+			return nil, http.HTTPError{
+				Message:    fmt.Sprintf("invalid %s %q, expected a millisecond timestamp", name, param),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+		*value = parsed
+	}
+	page, err := getPageQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	query.Page = page
+	entries, err := solverServer.store.GetAuditEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	total, err := solverServer.store.CountAuditEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	setPageHeaders(res, page, total, entries, func(entry data.AuditEntry) string { return entry.ID })
+	return entries, nil
+}
+
+func (solverServer *solverServer) expireAuditEntries(ctx context.Context) {
+	retention := time.Duration(solverServer.controller.options.Audit.Retention) * 24 * time.Hour
+	if retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := solverServer.store.RemoveAuditEntriesBefore(time.Now().Add(-retention).UnixMilli())
+			if err != nil {
+				log.Error().Err(err).Msgf("error removing expired audit entries")
+			}
+		}
+	}
+}
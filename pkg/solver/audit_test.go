@@ -0,0 +1,92 @@
+//go:build unit
+
+package solver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-retryablehttp"
+	lilypadhttp "github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := web3.NewKeySigner(key)
+
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	clientIP, err := lilypadhttp.NewClientIPResolver(lilypadhttp.ProxyOptions{})
+	require.NoError(t, err)
+	server := &solverServer{store: memoryStore, clientIP: clientIP}
+
+	router := mux.NewRouter()
+	router.Use(server.auditMiddleware)
+	handler := func(res http.ResponseWriter, req *http.Request) {
+		_, _ = io.ReadAll(req.Body)
+		res.WriteHeader(http.StatusForbidden)
+		_, _ = res.Write([]byte(`{"secret":"response-secret"}`))
+	}
+	router.HandleFunc("/api/v1/deals/{id}/result", handler).Methods("POST", "GET")
+
+	body := `{"secret":"request-secret"}`
+	signature := ""
+	call := func(method string, signed bool) {
+		req := httptest.NewRequest(method, "/api/v1/deals/deal/result", strings.NewReader(body))
+		req.RemoteAddr = "203.0.113.7:4321"
+		if signed {
+			signedReq, err := retryablehttp.NewRequest(method, "/api/v1/deals/deal/result", nil)
+			require.NoError(t, err)
+			require.NoError(t, lilypadhttp.AddHeaders(signedReq, signer, signer.Address().String()))
+			req.Header = signedReq.Header
+			signature = req.Header.Get(lilypadhttp.X_LILYPAD_SIGNATURE_HEADER)
+		}
+		req.Header.Set(lilypadhttp.X_LILYPAD_API_KEY_HEADER, "api-key-secret")
+		req.Header.Set(lilypadhttp.X_REQUEST_ID_HEADER, "request-id")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// reads and unsigned writes are not recorded
+	call(http.MethodGet, true)
+	call(http.MethodPost, false)
+	entries, err := memoryStore.GetAuditEntries(store.GetAuditEntriesQuery{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	call(http.MethodPost, true)
+	entries, err = memoryStore.GetAuditEntries(store.GetAuditEntriesQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	bodyHash := sha256.Sum256([]byte(body))
+	require.Equal(t, strings.ToLower(signer.Address().String()), entry.Address)
+	require.Equal(t, http.MethodPost, entry.Method)
+	require.Equal(t, "/api/v1/deals/{id}/result", entry.Route)
+	require.Equal(t, "/api/v1/deals/deal/result", entry.Path)
+	require.Equal(t, http.StatusForbidden, entry.StatusCode)
+	require.Equal(t, hex.EncodeToString(bodyHash[:]), entry.PayloadHash)
+	require.Equal(t, "203.0.113.7", entry.IP)
+	require.NotEmpty(t, entry.ID)
+
+	// only the hash of the body is kept, no bodies, signatures or keys
+	recorded, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.NotEmpty(t, signature)
+	for _, secret := range []string{"request-secret", "response-secret", "api-key-secret", signature} {
+		require.NotContains(t, string(recorded), secret)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/http"
 	"github.com/lilypad-tech/lilypad/pkg/solver/store"
 	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,6 +24,11 @@ type SolverClient struct {
 func NewSolverClient(
 	options http.ClientOptions,
 ) (*SolverClient, error) {
+	// load the client certificate now so bad files fail at startup
+	_, err := http.ClientTLSConfig(options.TLS)
+	if err != nil {
+		return nil, err
+	}
 	client := &SolverClient{
 		options:         options,
 		solverEventSubs: []func(SolverEvent){},
@@ -27,11 +36,26 @@ func NewSolverClient(
 	return client, nil
 }
 
+// a copy of the client that sends this id with every call so the calls
+// can be found in the solver logs, the websocket subscriptions are not copied
+func (client *SolverClient) WithRequestID(id string) *SolverClient {
+	options := client.options
+	options.RequestID = id
+	return &SolverClient{
+		options:         options,
+		solverEventSubs: []func(SolverEvent){},
+	}
+}
+
 // connect the websocket to the solver server
 func (client *SolverClient) Start(ctx context.Context, cm *system.CleanupManager) error {
 
 	websocketURL := fmt.Sprintf("%s%s%s%s%s", http.WEBSOCKET_SUB_PATH, "?&Type=", client.options.Type, "&ID=", client.options.PublicAddress)
-	websocketEventChannel := http.ConnectWebSocket(http.WebsocketURL(client.options, websocketURL), ctx)
+	tlsConfig, err := http.ClientTLSConfig(client.options.TLS)
+	if err != nil {
+		return err
+	}
+	websocketEventChannel := http.ConnectWebSocket(http.WebsocketURL(client.options, websocketURL), tlsConfig, client.options.Connection, ctx)
 	go func() {
 		for {
 			select {
@@ -67,6 +91,13 @@ func (client *SolverClient) GetJobOffers(query store.GetJobOffersQuery) ([]data.
 	if query.NotMatched {
 		queryParams["not_matched"] = "true"
 	}
+	if query.IncludeCancelled {
+		queryParams["include_cancelled"] = "true"
+	}
+	if query.Series != "" {
+		queryParams["series"] = query.Series
+	}
+	addPageParams(queryParams, query.Page)
 	return http.GetRequest[[]data.JobOfferContainer](client.options, "/job_offers", queryParams)
 }
 
@@ -81,6 +112,15 @@ func (client *SolverClient) GetResourceOffers(query store.GetResourceOffersQuery
 	if query.NotMatched {
 		queryParams["not_matched"] = "true"
 	}
+	if len(query.Attributes) > 0 {
+		pairs := []string{}
+		for name, value := range query.Attributes {
+			pairs = append(pairs, name+"="+value)
+		}
+		sort.Strings(pairs)
+		queryParams["attributes"] = strings.Join(pairs, ",")
+	}
+	addPageParams(queryParams, query.Page)
 	return http.GetRequest[[]data.ResourceOfferContainer](client.options, "/resource_offers", queryParams)
 }
 
@@ -95,9 +135,55 @@ func (client *SolverClient) GetDeals(query store.GetDealsQuery) ([]data.DealCont
 	if query.State != "" {
 		queryParams["state"] = query.State
 	}
+	addPageParams(queryParams, query.Page)
 	return http.GetRequest[[]data.DealContainer](client.options, "/deals", queryParams)
 }
 
+func (client *SolverClient) GetChainEvents(query store.GetChainEventsQuery) ([]data.ChainEvent, error) {
+	queryParams := map[string]string{}
+	if query.DealID != "" {
+		queryParams["deal_id"] = query.DealID
+	}
+	if query.Address != "" {
+		queryParams["address"] = query.Address
+	}
+	if query.Contract != "" {
+		queryParams["contract"] = query.Contract
+	}
+	if query.Event != "" {
+		queryParams["event"] = query.Event
+	}
+	if query.FromBlock > 0 {
+		queryParams["from_block"] = strconv.FormatUint(query.FromBlock, 10)
+	}
+	if query.ToBlock > 0 {
+		queryParams["to_block"] = strconv.FormatUint(query.ToBlock, 10)
+	}
+	addPageParams(queryParams, query.Page)
+	return http.GetRequest[[]data.ChainEvent](client.options, "/chain_events", queryParams)
+}
+
+func (client *SolverClient) GetResults(page store.PageQuery) ([]data.Result, error) {
+	queryParams := map[string]string{}
+	addPageParams(queryParams, page)
+	return http.GetRequest[[]data.Result](client.options, "/results", queryParams)
+}
+
+func addPageParams(queryParams map[string]string, page store.PageQuery) {
+	if page.Limit > 0 {
+		queryParams["limit"] = strconv.Itoa(page.Limit)
+	}
+	if page.Offset > 0 {
+		queryParams["offset"] = strconv.Itoa(page.Offset)
+	}
+	if page.Cursor != "" {
+		queryParams["cursor"] = page.Cursor
+	}
+	if page.Order != "" {
+		queryParams["order"] = page.Order
+	}
+}
+
 func (client *SolverClient) GetDeal(id string) (data.DealContainer, error) {
 	return http.GetRequest[data.DealContainer](client.options, fmt.Sprintf("/deals/%s", id), map[string]string{})
 }
@@ -106,6 +192,14 @@ func (client *SolverClient) GetResult(id string) (data.Result, error) {
 	return http.GetRequest[data.Result](client.options, fmt.Sprintf("/deals/%s/result", id), map[string]string{})
 }
 
+func (client *SolverClient) GetMatchDecisions(jobOfferID string) ([]data.MatchDecision, error) {
+	return http.GetRequest[[]data.MatchDecision](client.options, fmt.Sprintf("/job_offers/%s/decisions", jobOfferID), map[string]string{})
+}
+
+func (client *SolverClient) GetAuctionBids(jobOfferID string) ([]data.AuctionBid, error) {
+	return http.GetRequest[[]data.AuctionBid](client.options, fmt.Sprintf("/job_offers/%s/bids", jobOfferID), map[string]string{})
+}
+
 func (client *SolverClient) GetDealsWithFilter(query store.GetDealsQuery, filter func(data.DealContainer) bool) ([]data.DealContainer, error) {
 	deals, err := client.GetDeals(query)
 	if err != nil {
@@ -121,15 +215,58 @@ func (client *SolverClient) GetDealsWithFilter(query store.GetDealsQuery, filter
 }
 
 func (client *SolverClient) AddJobOffer(jobOffer data.JobOffer) (data.JobOfferContainer, error) {
-	return http.PostRequest[data.JobOffer, data.JobOfferContainer](client.options, "/job_offers", jobOffer)
+	if client.options.SigningDomain == nil {
+		return http.PostRequest[data.JobOffer, data.JobOfferContainer](client.options, "/job_offers", jobOffer)
+	}
+	typedData, err := web3.JobOfferTypedData(*client.options.SigningDomain, jobOffer)
+	if err != nil {
+		return data.JobOfferContainer{}, err
+	}
+	return http.PostTypedRequest[data.JobOffer, data.JobOfferContainer](client.options, "/job_offers", jobOffer, typedData)
+}
+
+func (client *SolverClient) SimulateJobOffer(jobOffer data.JobOffer) (data.MatchSimulation, error) {
+	return http.PostRequest[data.JobOffer, data.MatchSimulation](client.options, "/job_offers/simulate", jobOffer)
+}
+
+// 0 for instructionCount lets the solver assume its default
+func (client *SolverClient) EstimateJobOffer(jobOffer data.JobOffer, instructionCount uint64) (data.CostEstimate, error) {
+	path := "/job_offers/estimate"
+	if instructionCount > 0 {
+		path = fmt.Sprintf("%s?instruction_count=%d", path, instructionCount)
+	}
+	return http.PostRequest[data.JobOffer, data.CostEstimate](client.options, path, jobOffer)
+}
+
+// hand a job offer we cannot match to a peer solver
+func (client *SolverClient) ForwardJobOffer(jobOffer data.JobOffer) (data.JobOfferContainer, error) {
+	return http.PostRequest[data.JobOffer, data.JobOfferContainer](client.options, "/federation/job_offers", jobOffer)
 }
 
 func (client *SolverClient) AddResourceOffer(resourceOffer data.ResourceOffer) (data.ResourceOfferContainer, error) {
-	return http.PostRequest[data.ResourceOffer, data.ResourceOfferContainer](client.options, "/resource_offers", resourceOffer)
+	if client.options.SigningDomain == nil {
+		return http.PostRequest[data.ResourceOffer, data.ResourceOfferContainer](client.options, "/resource_offers", resourceOffer)
+	}
+	typedData, err := web3.ResourceOfferTypedData(*client.options.SigningDomain, resourceOffer)
+	if err != nil {
+		return data.ResourceOfferContainer{}, err
+	}
+	return http.PostTypedRequest[data.ResourceOffer, data.ResourceOfferContainer](client.options, "/resource_offers", resourceOffer, typedData)
+}
+
+// takes our resource offers that have no deal off the solver so nothing
+// new is matched with us
+func (client *SolverClient) RemoveResourceOffers() ([]data.ResourceOfferContainer, error) {
+	return http.DeleteRequest[[]data.ResourceOfferContainer](client.options, "/resource_offers")
 }
 
 func (client *SolverClient) AddResult(result data.Result) (data.Result, error) {
-	return http.PostRequest[data.Result, data.Result](client.options, fmt.Sprintf("/deals/%s/result", result.DealID), result)
+	path := fmt.Sprintf("/deals/%s/result", result.DealID)
+	if client.options.SigningDomain == nil {
+		return http.PostRequest[data.Result, data.Result](client.options, path, result)
+	}
+	typedData := web3.ResultTypedData(*client.options.SigningDomain, result)
+	return http.PostTypedRequest[data.Result, data.Result](client.options, path, result, typedData)
 }
 
 func (client *SolverClient) UpdateTransactionsResourceProvider(id string, payload data.DealTransactionsResourceProvider) (data.DealContainer, error) {
@@ -144,6 +281,10 @@ func (client *SolverClient) UpdateTransactionsMediator(id string, payload data.D
 	return http.PostRequest[data.DealTransactionsMediator, data.DealContainer](client.options, fmt.Sprintf("/deals/%s/txs/mediator", id), payload)
 }
 
+func (client *SolverClient) UpdateDealSecrets(id string, payload data.DealSecrets) (data.DealContainer, error) {
+	return http.PostRequest[data.DealSecrets, data.DealContainer](client.options, fmt.Sprintf("/deals/%s/secrets", id), payload)
+}
+
 func (client *SolverClient) UploadResultFiles(id string, localPath string) (data.Result, error) {
 	buf, err := system.GetTarBuffer(localPath)
 	if err != nil {
@@ -160,6 +301,15 @@ func (client *SolverClient) DownloadResultFiles(id string, localPath string) err
 	return system.ExpandTarBuffer(buf, localPath)
 }
 
+// returns the results archive without expanding it
+func (client *SolverClient) DownloadResultArchive(id string) ([]byte, error) {
+	buf, err := http.GetRequestBuffer(client.options, fmt.Sprintf("/deals/%s/files", id), map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // Validation service
 
 func (client *SolverClient) GetValidationToken() (http.ValidationToken, error) {
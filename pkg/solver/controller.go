@@ -2,9 +2,13 @@ package solver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/metricsDashboard"
 	"github.com/lilypad-tech/lilypad/pkg/solver/matcher"
@@ -35,6 +39,10 @@ const (
 	ResourceProviderTransactionsUpdated SolverEventType = "ResourceProviderTransactionsUpdated"
 	JobCreatorTransactionsUpdated       SolverEventType = "JobCreatorTransactionsUpdated"
 	MediatorTransactionsUpdated         SolverEventType = "MediatorTransactionsUpdated"
+	DealSecretsUpdated                  SolverEventType = "DealSecretsUpdated"
+	// the deal sat in one state for too long and should be timed out on chain
+	DealTimedOut SolverEventType = "DealTimedOut"
+	ResultAdded  SolverEventType = "ResultAdded"
 )
 
 type SolverEvent struct {
@@ -42,13 +50,41 @@ type SolverEvent struct {
 	JobOffer      *data.JobOfferContainer      `json:"job_offer"`
 	ResourceOffer *data.ResourceOfferContainer `json:"resource_offer"`
 	Deal          *data.DealContainer          `json:"deal"`
+	Result        *data.Result                 `json:"result,omitempty"`
+}
+
+// the parties to the event, websocket clients only hear about
+// the events they are a party to unless they asked for everything
+func (ev SolverEvent) addresses() []string {
+	addresses := []string{}
+	if ev.JobOffer != nil {
+		addresses = append(addresses, ev.JobOffer.JobCreator)
+	}
+	if ev.ResourceOffer != nil {
+		addresses = append(addresses, ev.ResourceOffer.ResourceProvider)
+	}
+	if ev.Deal != nil {
+		addresses = append(addresses, ev.Deal.JobCreator, ev.Deal.ResourceProvider)
+		addresses = append(addresses, ev.Deal.Deal.Members.Mediators...)
+		if ev.Deal.Mediator != "" {
+			addresses = append(addresses, ev.Deal.Mediator)
+		}
+	}
+	return addresses
 }
 
 type SolverController struct {
-	web3SDK         *web3.Web3SDK
-	web3Events      *web3.EventChannels
-	store           store.SolverStore
-	loop            *system.ControlLoop
+	web3SDK    *web3.Web3SDK
+	web3Events *web3.EventChannels
+	store      store.SolverStore
+	mediators  matcher.MediatorSelector
+	attributes data.AttributeSchema
+	// set once the controller starts, store changes can arrive before then
+	loop            atomic.Pointer[system.ControlLoop]
+	federation      *federation
+	reaper          *dealReaper
+	indexer         *chainIndexer
+	modules         *knownModules
 	solverEventSubs []func(SolverEvent)
 	options         SolverOptions
 	log             *system.ServiceLogger
@@ -56,29 +92,49 @@ type SolverController struct {
 	meter           metric.Meter
 }
 
-// the background "even if we have not heard of an event" loop
-// i.e. things will not wait 10 seconds - the control loop
-// reacts to events in the system - this 10 second background
-// loop is just for in case we miss any events
-const CONTROL_LOOP_INTERVAL = 10 * time.Second
 const REQUIRED_BALANCE_IN_WEI = 0.0006
 
 func NewSolverController(
 	web3SDK *web3.Web3SDK,
-	store store.SolverStore,
+	solverStore store.SolverStore,
 	options SolverOptions,
 	tracer trace.Tracer,
 	meter metric.Meter,
 ) (*SolverController, error) {
+	options.Matcher.PriceBounds = options.Pricing
+	mediators, err := matcher.NewMediatorSelector(options.Matcher, solverStore)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := data.NewAttributeSchema(options.Matcher.AttributeSchema)
+	if err != nil {
+		return nil, err
+	}
+	// new offers trigger a matching pass rather than waiting for the next tick
+	notifyingStore := store.NewNotifyingStore(solverStore)
 	controller := &SolverController{
 		web3SDK:    web3SDK,
 		web3Events: web3.NewEventChannels(),
-		store:      store,
+		store:      notifyingStore,
+		mediators:  mediators,
+		attributes: attributes,
+		reaper:     newDealReaper(options.Reaper),
+		indexer:    newChainIndexer(options.Indexer, web3SDK, solverStore),
+		modules:    newKnownModules(),
 		options:    options,
 		log:        system.NewServiceLogger(system.SolverService),
 		tracer:     tracer,
 		meter:      meter,
 	}
+	notifyingStore.Subscribe(controller.reactToStoreChange)
+	// the modules of the job offers from before a restart
+	jobOffers, err := solverStore.GetJobOffers(store.GetJobOffersQuery{IncludeCancelled: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, jobOffer := range jobOffers {
+		controller.modules.add(jobOffer.JobOffer.Module)
+	}
 	return controller, nil
 }
 
@@ -102,16 +158,27 @@ func (controller *SolverController) Start(ctx context.Context, cm *system.Cleanu
 	// make sure we are registered as a solver
 	// so that users can lookup our URL
 	log.Debug().Msgf("controller.registerAsSolver")
-	err = controller.registerAsSolver()
+	err = controller.registerAsSolver(ctx)
+	if err != nil {
+		errorChan <- err
+		return errorChan
+	}
+
+	controller.federation, err = newFederation(
+		controller.options.Federation,
+		controller.store,
+		controller.web3SDK.Signer,
+	)
 	if err != nil {
 		errorChan <- err
 		return errorChan
 	}
 
-	controller.loop = system.NewControlLoop(
+	// the interval is the fallback in case we miss any events
+	loop := system.NewControlLoop(
 		system.SolverService,
 		ctx,
-		CONTROL_LOOP_INTERVAL,
+		time.Duration(controller.options.Loop.Interval)*time.Second,
 		func() error {
 			err := controller.solve(ctx)
 			if err != nil {
@@ -120,13 +187,46 @@ func (controller *SolverController) Start(ctx context.Context, cm *system.Cleanu
 			return err
 		},
 	)
+	controller.loop.Store(loop)
 	log.Debug().Msgf("controller.loop.Start")
-	err = controller.loop.Start(true)
+	err = loop.Start(true)
 	if err != nil {
 		errorChan <- err
 		return errorChan
 	}
 
+	if controller.options.Reaper.Interval > 0 {
+		reaperLoop := system.NewControlLoop(
+			system.SolverService,
+			ctx,
+			time.Duration(controller.options.Reaper.Interval)*time.Second,
+			controller.reapStaleDeals,
+		)
+		log.Debug().Msgf("controller.reaperLoop.Start")
+		err = reaperLoop.Start(false)
+		if err != nil {
+			errorChan <- err
+			return errorChan
+		}
+	}
+
+	if controller.options.Indexer.Interval > 0 {
+		indexerLoop := system.NewControlLoop(
+			system.SolverService,
+			ctx,
+			time.Duration(controller.options.Indexer.Interval)*time.Second,
+			func() error {
+				return controller.indexer.index(ctx)
+			},
+		)
+		log.Debug().Msgf("controller.indexerLoop.Start")
+		err = indexerLoop.Start(false)
+		if err != nil {
+			errorChan <- err
+			return errorChan
+		}
+	}
+
 	return errorChan
 }
 
@@ -149,6 +249,10 @@ func (controller *SolverController) subscribeToWeb3() error {
 
 	// change the deal state
 	controller.web3Events.Storage.SubscribeDealStateChange(func(ev storage.StorageDealStateChange) {
+		if ev.Raw.Removed {
+			controller.rollbackDeal(ev.DealId, ev.Raw)
+			return
+		}
 		_, err := controller.updateDealState(ev.DealId, ev.State)
 		if err != nil {
 			controller.log.Error("error updating deal state", err)
@@ -157,11 +261,15 @@ func (controller *SolverController) subscribeToWeb3() error {
 		controller.log.Info("StorageDealStateChange", data.GetAgreementStateString(ev.State))
 		system.DumpObjectDebug(ev)
 		// update the store with the state change
-		controller.loop.Trigger()
+		controller.loop.Load().Trigger()
 	})
 
 	// update the mediator
 	controller.web3Events.Mediation.SubscribeMediationRequested(func(ev mediation.MediationMediationRequested) {
+		if ev.Raw.Removed {
+			controller.rollbackDeal(ev.DealId, ev.Raw)
+			return
+		}
 		controller.log.Info("MediationMediationRequested", "")
 		system.DumpObjectDebug(ev)
 		_, err := controller.updateDealMediator(ev.DealId, ev.Mediator.String())
@@ -171,28 +279,83 @@ func (controller *SolverController) subscribeToWeb3() error {
 		}
 
 		// update the store with the state change
-		controller.loop.Trigger()
+		controller.loop.Load().Trigger()
 	})
 
 	return nil
 }
 
+// a reorg dropped an event for the deal, its state and mediator go back to
+// what the chain has now and the deal's transaction in the dropped block is
+// marked as reorged, replaying the new chain moves the deal on from there
+func (controller *SolverController) rollbackDeal(id string, raw types.Log) {
+	deal, err := controller.store.GetDeal(id)
+	if err != nil {
+		controller.log.Error("error getting reorged deal", err)
+		return
+	}
+	if deal == nil {
+		return
+	}
+	controller.log.Info("reorg dropped deal event", fmt.Sprintf("%s block %d tx %s", id, raw.BlockNumber, raw.TxHash.Hex()))
+
+	state, mediator, err := controller.web3SDK.GetAgreementStateAndMediator(context.Background(), id)
+	if err != nil {
+		controller.log.Error("error reading deal after reorg", err)
+		return
+	}
+	if state != deal.State {
+		if _, err := controller.updateDealState(id, state); err != nil {
+			controller.log.Error("error rolling back deal state", err)
+		}
+	}
+	mediatorAddress := ""
+	if mediator != (common.Address{}) {
+		mediatorAddress = mediator.String()
+	}
+	if mediatorAddress != deal.Mediator {
+		if _, err := controller.updateDealMediator(id, mediatorAddress); err != nil {
+			controller.log.Error("error rolling back deal mediator", err)
+		}
+	}
+
+	if update, found := deal.Transactions.Reorged(raw.TxHash.Hex()); found {
+		if len(update.JobCreator.Stuck) > 0 {
+			_, err = controller.updateDealTransactionsJobCreator(id, update.JobCreator)
+		}
+		if len(update.ResourceProvider.Stuck) > 0 {
+			_, err = controller.updateDealTransactionsResourceProvider(id, update.ResourceProvider)
+		}
+		if len(update.Mediator.Stuck) > 0 {
+			_, err = controller.updateDealTransactionsMediator(id, update.Mediator)
+		}
+		if err != nil {
+			controller.log.Error("error marking reorged deal transaction", err)
+		}
+	}
+	controller.loop.Load().Trigger()
+}
+
 // return a new event channel that will hear about events
 // coming out of this controller
 func (controller *SolverController) subscribeEvents(handler func(SolverEvent)) {
 	controller.solverEventSubs = append(controller.solverEventSubs, handler)
 }
 
-func (controller *SolverController) reactToEvent(ev SolverEvent) {
-	// both of these should trigger a solve
-	if ev.EventType == ResourceOfferAdded || ev.EventType == JobOfferAdded {
-		controller.loop.Trigger()
+// new offers should trigger a solve
+func (controller *SolverController) reactToStoreChange(change store.StoreChange) {
+	// we are not started yet so the first pass will pick the offer up
+	loop := controller.loop.Load()
+	if loop == nil {
+		return
+	}
+	if change.Type == store.JobOfferAddedChange || change.Type == store.ResourceOfferAddedChange {
+		loop.TriggerDebounced(time.Duration(controller.options.Loop.Debounce) * time.Millisecond)
 	}
 }
 
 // write the given event to all generated event channels
 func (controller *SolverController) writeEvent(ev SolverEvent) {
-	controller.reactToEvent(ev)
 	for _, handler := range controller.solverEventSubs {
 		handler(ev)
 	}
@@ -210,7 +373,7 @@ func (controller *SolverController) writeEvent(ev SolverEvent) {
  *
 */
 
-func (controller *SolverController) registerAsSolver() error {
+func (controller *SolverController) registerAsSolver(ctx context.Context) error {
 	selfAddress := controller.web3SDK.GetAddress()
 	solverType, err := data.GetServiceType("Solver")
 	if err != nil {
@@ -229,6 +392,7 @@ func (controller *SolverController) registerAsSolver() error {
 	if selfUser.Url != controller.options.Server.URL {
 		controller.log.Info("url change", fmt.Sprintf("solver will be updated because URL has changed: %s %s != %s", selfAddress.String(), selfUser.Url, controller.options.Server.URL))
 		err = controller.web3SDK.UpdateUser(
+			ctx,
 			"",
 			controller.options.Server.URL,
 			[]uint8{solverType},
@@ -256,6 +420,7 @@ func (controller *SolverController) registerAsSolver() error {
 		controller.log.Info("solver registering", "")
 		// add the solver to the storage contract
 		err = controller.web3SDK.AddUserToList(
+			ctx,
 			solverType,
 		)
 		if err != nil {
@@ -283,7 +448,7 @@ func (controller *SolverController) solve(ctx context.Context) error {
 	defer span.End()
 
 	// find out which deals we can make from matching the offers
-	deals, err := matcher.GetMatchingDeals(ctx, controller.store, controller.updateJobOfferState, controller.tracer, controller.meter)
+	deals, err := matcher.GetMatchingDeals(ctx, controller.store, controller.options.Matcher, controller.mediators, controller.updateJobOfferState, controller.tracer, controller.meter)
 	if err != nil {
 		span.SetStatus(codes.Error, "get matching deals failed")
 		span.RecordError(err)
@@ -304,6 +469,15 @@ func (controller *SolverController) solve(ctx context.Context) error {
 	}
 	span.AddEvent("add_deals.done")
 
+	span.AddEvent("federate.start")
+	err = controller.federate()
+	if err != nil {
+		span.SetStatus(codes.Error, "federate failed")
+		span.RecordError(err)
+		return err
+	}
+	span.AddEvent("federate.done")
+
 	span.AddEvent("report_deal_metrics.start")
 	storedDeals, err := controller.store.GetDealsAll()
 	if err != nil {
@@ -321,6 +495,52 @@ func (controller *SolverController) solve(ctx context.Context) error {
 	return nil
 }
 
+// tell the parties about deals that have been stuck too long so one of
+// them can call the timeout on chain, the deal keeps its state until the
+// chain event for the timeout moves it
+func (controller *SolverController) reapStaleDeals() error {
+	deals, err := controller.store.GetDealsAll()
+	if err != nil {
+		return err
+	}
+	staleDeals := controller.reaper.findStaleDeals(deals, time.Now())
+	if len(staleDeals) == 0 {
+		return nil
+	}
+
+	// a deal can look stuck because we missed its event, so check the chain
+	// for all of them in one batch before timing any out
+	dealIDs := make([]string, len(staleDeals))
+	for i, stale := range staleDeals {
+		dealIDs[i] = stale.deal.ID
+	}
+	chainStates, err := controller.web3SDK.GetAgreementStates(context.Background(), dealIDs)
+	if err != nil {
+		controller.log.Error("error reading stale deal states", err)
+		chainStates = map[string]uint8{}
+	}
+
+	for _, stale := range staleDeals {
+		if state, ok := chainStates[stale.deal.ID]; ok && state != 0 && state != stale.deal.State {
+			controller.log.Info("sync stale deal", fmt.Sprintf("%s %s -> %s", stale.deal.ID, data.GetAgreementStateString(stale.deal.State), data.GetAgreementStateString(state)))
+			if _, err := controller.updateDealState(stale.deal.ID, state); err != nil {
+				controller.log.Error("error syncing stale deal", err)
+			}
+			continue
+		}
+		controller.log.Info("reap stale deal", fmt.Sprintf("%s %s -> %s", stale.deal.ID, data.GetAgreementStateString(stale.deal.State), data.GetAgreementStateString(stale.state)))
+		// only the event has the timeout state, it is the timeout the
+		// parties are to call, the store is left as it is
+		deal := stale.deal
+		deal.State = stale.state
+		controller.writeEvent(SolverEvent{
+			EventType: DealTimedOut,
+			Deal:      &deal,
+		})
+	}
+	return nil
+}
+
 /*
 *
 *
@@ -339,9 +559,17 @@ func (controller *SolverController) addJobOffer(jobOffer data.JobOffer) (*data.J
 	}
 	jobOffer.ID = id
 
+	err = controller.attributes.ValidateRequirements(jobOffer.Requirements)
+	if err != nil {
+		return nil, err
+	}
+
 	controller.log.Info("add job offer", jobOffer)
+	controller.modules.add(jobOffer.Module)
 
-	ret, err := controller.store.AddJobOffer(data.GetJobOfferContainer(jobOffer))
+	container := data.GetJobOfferContainer(jobOffer)
+	container.ReceivedAt = time.Now().UnixMilli()
+	ret, err := controller.store.AddJobOffer(container)
 	if err != nil {
 		return nil, err
 	}
@@ -349,9 +577,66 @@ func (controller *SolverController) addJobOffer(jobOffer data.JobOffer) (*data.J
 		EventType: JobOfferAdded,
 		JobOffer:  ret,
 	})
+
+	// make sure we solve again as soon as the bid window closes
+	if controller.options.Matcher.Mode == matcher.AuctionMatch {
+		time.AfterFunc(time.Duration(controller.options.Matcher.AuctionWindow)*time.Second, controller.loop.Load().Trigger)
+	}
 	return ret, nil
 }
 
+// a job offer from a peer is brokered by us so it names us as the solver
+// the ID is left alone so the peer and job creator can still find it
+func (controller *SolverController) addForwardedJobOffer(jobOffer data.JobOffer, origin string) (*data.JobOfferContainer, error) {
+	id, err := data.GetJobOfferID(jobOffer)
+	if err != nil {
+		return nil, err
+	}
+	jobOffer.ID = id
+	jobOffer.Services.Solver = controller.web3SDK.GetAddress().String()
+
+	err = controller.attributes.ValidateRequirements(jobOffer.Requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := controller.store.GetJobOffer(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	controller.log.Info("add forwarded job offer", jobOffer)
+	controller.modules.add(jobOffer.Module)
+
+	container := data.GetJobOfferContainer(jobOffer)
+	container.Origin = origin
+	container.ReceivedAt = time.Now().UnixMilli()
+	ret, err := controller.store.AddJobOffer(container)
+	if err != nil {
+		return nil, err
+	}
+	controller.writeEvent(SolverEvent{
+		EventType: JobOfferAdded,
+		JobOffer:  ret,
+	})
+	return ret, nil
+}
+
+func (controller *SolverController) simulateJobOffer(jobOffer data.JobOffer) (*data.MatchSimulation, error) {
+	err := controller.attributes.ValidateRequirements(jobOffer.Requirements)
+	if err != nil {
+		return nil, err
+	}
+	simulation, err := matcher.SimulateMatch(controller.store, controller.options.Matcher, jobOffer, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &simulation, nil
+}
+
 func (controller *SolverController) addResourceOffer(resourceOffer data.ResourceOffer) (*data.ResourceOfferContainer, error) {
 	id, err := data.GetResourceOfferID(resourceOffer)
 	if err != nil {
@@ -359,6 +644,11 @@ func (controller *SolverController) addResourceOffer(resourceOffer data.Resource
 	}
 	resourceOffer.ID = id
 
+	err = controller.attributes.ValidateAttributes(resourceOffer.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check the resource provider's ETH balance
 	balance, err := controller.web3SDK.GetBalance(resourceOffer.ResourceProvider)
 	if err != nil {
@@ -405,22 +695,26 @@ func (controller *SolverController) addResourceOffer(resourceOffer data.Resource
 }
 
 // Remove resource offers in an unmatched DealNegotiating[0] state
-func (controller *SolverController) removeUnmatchedResourceOffers(resourceProviderID string) error {
+// and return the ones that were removed
+func (controller *SolverController) removeUnmatchedResourceOffers(resourceProviderID string) ([]data.ResourceOfferContainer, error) {
 	controller.log.Info("remove resource offer", resourceProviderID)
 	resourceOffers, err := controller.store.GetResourceOffers(store.GetResourceOffersQuery{
 		ResourceProvider: resourceProviderID,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	removed := []data.ResourceOfferContainer{}
 	for _, offer := range resourceOffers {
 		if offer.State == 0 {
 			err = controller.store.RemoveResourceOffer(offer.ID)
 			if err != nil {
 				controller.log.Error("remove resource offer failed",
 					fmt.Errorf("resource provider: %s, offer ID: %s, error: %s", resourceProviderID, offer.ID, err))
+				continue
 			}
+			removed = append(removed, offer)
 		}
 	}
 
@@ -428,7 +722,73 @@ func (controller *SolverController) removeUnmatchedResourceOffers(resourceProvid
 		EventType:     ResourceOfferRemoved,
 		ResourceOffer: nil,
 	})
-	return nil
+	return removed, nil
+}
+
+// forward job offers we cannot match and mirror the deals our peers make for them
+func (controller *SolverController) federate() error {
+	if controller.federation == nil || len(controller.federation.peers) == 0 {
+		return nil
+	}
+	forwarded, err := controller.federation.forwardJobOffers(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, jobOffer := range forwarded {
+		controller.log.Info("forwarded job offer", fmt.Sprintf("%s -> %s", jobOffer.ID, jobOffer.ForwardedTo))
+	}
+
+	deals, err := controller.federation.getPeerDeals()
+	if err != nil {
+		return err
+	}
+	for _, deal := range deals {
+		_, err := controller.addPeerDeal(deal)
+		if errors.Is(err, errPeerDealMismatch) {
+			controller.log.Error("rejected peer deal", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return controller.federation.syncResults()
+}
+
+// the resource offer lives with the peer so only the job offer is updated
+// after this the deal state follows the chain events like any other deal
+func (controller *SolverController) addPeerDeal(deal data.DealContainer) (*data.DealContainer, error) {
+	controller.log.Info("add peer deal", deal)
+
+	jobOffer, err := controller.store.GetJobOffer(deal.JobOffer)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPeerDeal(jobOffer, deal); err != nil {
+		return nil, err
+	}
+
+	ret, err := controller.store.GetDeal(deal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil {
+		ret, err = controller.store.AddDeal(deal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	controller.writeEvent(SolverEvent{
+		EventType: DealAdded,
+		Deal:      ret,
+	})
+
+	_, err = controller.updateJobOfferState(ret.JobOffer, ret.ID, ret.State)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 func (controller *SolverController) addDeal(ctx context.Context, deal data.Deal) (*data.DealContainer, error) {
@@ -485,6 +845,21 @@ func (controller *SolverController) addDeal(ctx context.Context, deal data.Deal)
 	return ret, nil
 }
 
+func (controller *SolverController) addResult(result data.Result, deal *data.DealContainer) (*data.Result, error) {
+	controller.log.Info("add result", result)
+
+	ret, err := controller.store.AddResult(result)
+	if err != nil {
+		return nil, err
+	}
+	controller.writeEvent(SolverEvent{
+		EventType: ResultAdded,
+		Deal:      deal,
+		Result:    ret,
+	})
+	return ret, nil
+}
+
 /*
 *
 *
@@ -541,6 +916,16 @@ func (controller *SolverController) updateDealState(id string, state uint8) (*da
 	if err != nil {
 		return nil, err
 	}
+	// a packing resource offer stays active while any of its deals are still running
+	if dealContainer.Deal.ResourceOffer.Packing && !data.IsActiveAgreementState(dealContainer.State) {
+		active, err := controller.hasActiveDeals(dealContainer.ResourceProvider, dealContainer.ResourceOffer)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			return dealContainer, nil
+		}
+	}
 	_, err = controller.updateResourceOfferState(dealContainer.ResourceOffer, dealContainer.ID, dealContainer.State)
 	if err != nil {
 		return nil, err
@@ -548,6 +933,21 @@ func (controller *SolverController) updateDealState(id string, state uint8) (*da
 	return dealContainer, nil
 }
 
+func (controller *SolverController) hasActiveDeals(resourceProvider string, resourceOffer string) (bool, error) {
+	deals, err := controller.store.GetDeals(store.GetDealsQuery{
+		ResourceProvider: resourceProvider,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, deal := range deals {
+		if deal.ResourceOffer == resourceOffer && data.IsActiveAgreementState(deal.State) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // this will also update the job and resource offer states
 func (controller *SolverController) updateDealMediator(id string, mediator string) (*data.DealContainer, error) {
 	controller.log.Info("update mediator", fmt.Sprintf("%s %s", id, mediator))
@@ -562,6 +962,20 @@ func (controller *SolverController) updateDealMediator(id string, mediator strin
 	return dealContainer, nil
 }
 
+// only the deal id is logged, the ciphertext is no use to anyone reading
+func (controller *SolverController) updateDealSecrets(id string, secrets string) (*data.DealContainer, error) {
+	controller.log.Info("update deal secrets", id)
+	dealContainer, err := controller.store.UpdateDealSecrets(id, secrets)
+	if err != nil {
+		return nil, err
+	}
+	controller.writeEvent(SolverEvent{
+		EventType: DealSecretsUpdated,
+		Deal:      dealContainer,
+	})
+	return dealContainer, nil
+}
+
 /*
 *
 *
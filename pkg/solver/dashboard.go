@@ -0,0 +1,55 @@
+package solver
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	corehttp "net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+)
+
+// seconds between the dashboard asking the api for fresh data
+const dashboardRefreshInterval = 5
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// what the dashboard needs to know before it can call the api
+type dashboardConfig struct {
+	API      string   `json:"api"`
+	States   []string `json:"states"`
+	Refresh  int      `json:"refresh"`
+	ReadAuth bool     `json:"read_auth"`
+}
+
+// serve the operator dashboard at /dashboard, the page only reads the
+// public api so it sees exactly what any other client would
+func (solverServer *solverServer) serveDashboard(router *mux.Router) error {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		return err
+	}
+	config, err := json.Marshal(dashboardConfig{
+		API:      http.API_SUB_PATH,
+		States:   data.AgreementState,
+		Refresh:  dashboardRefreshInterval,
+		ReadAuth: solverServer.options.AccessControl.RequireReadAuth,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the assets are linked relative to the page so it needs the slash
+	router.Handle("/dashboard", corehttp.RedirectHandler("/dashboard/", corehttp.StatusMovedPermanently)).Methods("GET")
+	router.HandleFunc("/dashboard/config.json", func(res corehttp.ResponseWriter, req *corehttp.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write(config)
+	}).Methods("GET")
+	router.PathPrefix("/dashboard/").Handler(
+		corehttp.StripPrefix("/dashboard/", corehttp.FileServer(corehttp.FS(files))),
+	).Methods("GET")
+	return nil
+}
@@ -0,0 +1,203 @@
+// everything here comes from the public solver api, the dashboard has
+// no endpoints of its own apart from config.json
+(() => {
+  const API_KEY_STORAGE = "lilypad-dashboard-api-key";
+  // enough to group providers from without asking for every offer
+  const OFFER_LIMIT = 500;
+  const ROW_LIMIT = 100;
+
+  let config = { api: "/api/v1", states: [], refresh: 5 };
+  let selectedJobOffer = "";
+
+  const $ = (id) => document.getElementById(id);
+
+  const short = (value) => (value && value.length > 14 ? `${value.slice(0, 8)}…${value.slice(-4)}` : value || "");
+  const stateName = (state) => config.states[state] || String(state);
+
+  async function get(path) {
+    const headers = {};
+    const apiKey = localStorage.getItem(API_KEY_STORAGE);
+    if (apiKey) {
+      headers["X-Lilypad-Api-Key"] = apiKey;
+    }
+    const res = await fetch(config.api + path, { headers });
+    if (!res.ok) {
+      let message = `${res.status} ${res.statusText}`;
+      try {
+        const envelope = await res.json();
+        message = envelope.message || message;
+      } catch (e) {
+        // not the error envelope, the status will do
+      }
+      throw new Error(`${path}: ${message}`);
+    }
+    return {
+      rows: (await res.json()) || [],
+      total: Number(res.headers.get("X-Total-Count") || 0),
+    };
+  }
+
+  // cells are set with textContent so nothing from the api is parsed as html
+  function cell(value, className) {
+    const td = document.createElement("td");
+    td.textContent = value === undefined || value === null ? "" : String(value);
+    if (className) {
+      td.className = className;
+    }
+    if (typeof value === "string" && value.length > 14) {
+      td.title = value;
+      td.textContent = short(value);
+    }
+    return td;
+  }
+
+  function render(tableId, rows, toCells, onClick) {
+    const body = $(tableId).querySelector("tbody");
+    body.replaceChildren(
+      ...rows.map((row) => {
+        const tr = document.createElement("tr");
+        tr.append(...toCells(row));
+        if (onClick) {
+          tr.addEventListener("click", () => onClick(row, tr));
+        }
+        return tr;
+      }),
+    );
+  }
+
+  function providers(resourceOffers) {
+    const byAddress = new Map();
+    for (const offer of resourceOffers) {
+      const address = offer.resource_provider;
+      const provider = byAddress.get(address) || { address, offers: 0, free: 0, gpu: 0, cpu: 0, ram: 0 };
+      const spec = offer.resource_offer.spec || {};
+      provider.offers++;
+      if (!offer.deal_id) {
+        provider.free++;
+      }
+      provider.gpu += spec.gpu || 0;
+      provider.cpu += spec.cpu || 0;
+      provider.ram += spec.ram || 0;
+      byAddress.set(address, provider);
+    }
+    return [...byAddress.values()].sort((a, b) => b.offers - a.offers);
+  }
+
+  async function showDecisions(jobOfferId) {
+    selectedJobOffer = jobOfferId;
+    const { rows } = await get(`/job_offers/${encodeURIComponent(jobOfferId)}/decisions`);
+    $("decisions-section").hidden = false;
+    $("decisions-job-offer").textContent = short(jobOfferId);
+    $("decisions-job-offer").title = jobOfferId;
+    render("decisions", rows, (decision) => [
+      cell(decision.resource_offer, "id"),
+      cell(decision.result ? "yes" : "no", decision.result ? "yes" : "no"),
+      cell(decision.deal, "id"),
+      cell(decision.reason),
+    ]);
+  }
+
+  async function refresh() {
+    const [resourceOffers, jobOffers, deals] = await Promise.all([
+      get(`/resource_offers?order=desc&limit=${OFFER_LIMIT}`),
+      get(`/job_offers?order=desc&limit=${ROW_LIMIT}&include_cancelled=true`),
+      get(`/deals?order=desc&limit=${ROW_LIMIT}`),
+    ]);
+
+    const providerRows = providers(resourceOffers.rows);
+    $("total-resource-offers").textContent = resourceOffers.total;
+    $("total-job-offers").textContent = jobOffers.total;
+    $("total-deals").textContent = deals.total;
+    // a lower bound when there are more offers than we fetched
+    $("total-providers").textContent =
+      resourceOffers.total > resourceOffers.rows.length ? `${providerRows.length}+` : providerRows.length;
+
+    render("providers", providerRows, (provider) => [
+      cell(provider.address, "id"),
+      cell(provider.offers),
+      cell(provider.free),
+      cell(provider.gpu / 1000),
+      cell(provider.cpu / 1000),
+      cell(`${provider.ram} MB`),
+    ]);
+    render("resource-offers", resourceOffers.rows.slice(0, ROW_LIMIT), (offer) => {
+      const spec = offer.resource_offer.spec || {};
+      const pricing = offer.resource_offer.default_pricing || {};
+      return [
+        cell(offer.id, "id"),
+        cell(offer.resource_provider, "id"),
+        cell(stateName(offer.state)),
+        cell(offer.deal_id, "id"),
+        cell((spec.gpu || 0) / 1000),
+        cell((spec.cpu || 0) / 1000),
+        cell(`${spec.ram || 0} MB`),
+        cell(pricing.instruction_price),
+      ];
+    });
+    render(
+      "job-offers",
+      jobOffers.rows,
+      (offer) => [
+        cell(offer.id, "id"),
+        cell(offer.job_creator, "id"),
+        cell((offer.job_offer.module || {}).name),
+        cell(stateName(offer.state)),
+        cell(offer.deal_id, "id"),
+        cell(offer.job_offer.mode),
+      ],
+      (offer, tr) => {
+        $("job-offers").querySelectorAll("tr.selected").forEach((row) => row.classList.remove("selected"));
+        tr.classList.add("selected");
+        showDecisions(offer.id).catch(showError);
+      },
+    );
+    render("deals", deals.rows, (deal) => [
+      cell(deal.id, "id"),
+      cell(deal.job_creator, "id"),
+      cell(deal.resource_provider, "id"),
+      cell(stateName(deal.state)),
+      cell(deal.mediator, "id"),
+    ]);
+
+    if (selectedJobOffer) {
+      await showDecisions(selectedJobOffer);
+    }
+    $("status").className = "";
+    $("status").textContent = `Updated ${new Date().toLocaleTimeString()}`;
+  }
+
+  function showError(err) {
+    $("status").className = "error";
+    $("status").textContent = err.message;
+  }
+
+  async function tick() {
+    try {
+      await refresh();
+    } catch (err) {
+      showError(err);
+    }
+    setTimeout(tick, config.refresh * 1000);
+  }
+
+  $("api-key").value = localStorage.getItem(API_KEY_STORAGE) || "";
+  $("api-key-form").addEventListener("submit", (event) => {
+    event.preventDefault();
+    const apiKey = $("api-key").value.trim();
+    if (apiKey) {
+      localStorage.setItem(API_KEY_STORAGE, apiKey);
+    } else {
+      localStorage.removeItem(API_KEY_STORAGE);
+    }
+    refresh().catch(showError);
+  });
+
+  fetch("config.json")
+    .then((res) => res.json())
+    .then((loaded) => {
+      config = loaded;
+      $("api-key-form").hidden = !config.read_auth;
+      tick();
+    })
+    .catch(showError);
+})();
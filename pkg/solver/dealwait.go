@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	corehttp "net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+)
+
+const (
+	// how long GET /deals/{id}?wait_state= waits when no timeout is given
+	defaultDealWaitTimeout = 30 * time.Second
+	// a longer wait is cut down to this so connections are not held forever
+	maxDealWaitTimeout = 120 * time.Second
+)
+
+// reads wait_state, a comma separated list of agreement state names,
+// and timeout in seconds
+func parseDealWait(req *corehttp.Request) ([]uint8, time.Duration, error) {
+	query := req.URL.Query()
+	states := []uint8{}
+	for _, name := range strings.Split(query.Get("wait_state"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		state, err := data.GetAgreementState(name)
+		if err != nil {
+			return nil, 0, http.HTTPError{
+				Message:    fmt.Sprintf("unknown wait_state %s, the states are %s", name, strings.Join(data.AgreementState, ", ")),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+		states = append(states, state)
+	}
+	timeout := defaultDealWaitTimeout
+	if value := query.Get("timeout"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return nil, 0, http.HTTPError{
+				Message:    fmt.Sprintf("invalid timeout %q, it is a number of seconds", value),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+		// clamped in seconds so a huge value cannot overflow the duration
+		timeout = time.Duration(min(seconds, int(maxDealWaitTimeout/time.Second))) * time.Second
+	}
+	return states, timeout, nil
+}
+
+// blocks until the deal is in one of the states, it reaches a terminal
+// state it can never leave or the timeout is up, the deal is returned
+// as it is then so the caller checks the state to see which happened
+func (solverServer *solverServer) waitForDealState(req *corehttp.Request, id string, states []uint8, timeout time.Duration) (*data.DealContainer, error) {
+	// subscribe before reading the deal so a change in between is not missed,
+	// only the events from now on matter
+	_, events, cancel := solverServer.dealEvents.subscribe(id, math.MaxUint64)
+	defer cancel()
+
+	done := func(deal *data.DealContainer) bool {
+		return deal == nil || slices.Contains(states, deal.State) || data.IsTerminalAgreementState(deal.State)
+	}
+
+	deal, err := solverServer.store.GetDeal(id)
+	if err != nil || done(deal) {
+		return deal, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return deal, nil
+		case <-timer.C:
+			return deal, nil
+		case <-events:
+			deal, err = solverServer.store.GetDeal(id)
+			if err != nil || done(deal) {
+				return deal, err
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package solver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	lilypadhttp "github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/stretchr/testify/require"
+)
+
+func parseDealWaitQuery(query string) ([]uint8, time.Duration, error) {
+	return parseDealWait(httptest.NewRequest(http.MethodGet, "/deals/deal?"+query, nil))
+}
+
+func TestParseDealWait(t *testing.T) {
+	states, timeout, err := parseDealWaitQuery("wait_state=DealAgreed,%20ResultsSubmitted,")
+	require.NoError(t, err)
+	require.Equal(t, []uint8{data.GetAgreementStateIndex("DealAgreed"), data.GetAgreementStateIndex("ResultsSubmitted")}, states)
+	require.Equal(t, defaultDealWaitTimeout, timeout)
+
+	_, timeout, err = parseDealWaitQuery("wait_state=DealAgreed&timeout=5")
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, timeout)
+
+	_, timeout, err = parseDealWaitQuery("wait_state=DealAgreed&timeout=0")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), timeout)
+}
+
+func TestParseDealWaitClamped(t *testing.T) {
+	for _, value := range []string{"121", "3600", "9223372036854775807"} {
+		_, timeout, err := parseDealWaitQuery("wait_state=DealAgreed&timeout=" + value)
+		require.NoError(t, err, value)
+		require.Equal(t, maxDealWaitTimeout, timeout, value)
+	}
+}
+
+func TestParseDealWaitInvalid(t *testing.T) {
+	for _, query := range []string{
+		"wait_state=DealAgreed&timeout=-1",
+		"wait_state=DealAgreed&timeout=soon",
+		"wait_state=DealAgreed&timeout=1.5",
+		"wait_state=DealAgreed&timeout=99999999999999999999",
+		"wait_state=NotAState",
+	} {
+		_, _, err := parseDealWaitQuery(query)
+		httpErr, ok := err.(lilypadhttp.HTTPError)
+		require.True(t, ok, "%s: expected an http error, got %v", query, err)
+		require.Equal(t, http.StatusBadRequest, httpErr.StatusCode, query)
+	}
+}
@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"fmt"
+	corehttp "net/http"
+
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+)
+
+// the errors the REST, gRPC and GraphQL apis share so each one
+// reports the same code for the same problem
+
+func offerNotFound(kind string, id string) http.HTTPError {
+	return http.HTTPError{
+		Message:    fmt.Sprintf("%s not found: %s", kind, id),
+		StatusCode: corehttp.StatusNotFound,
+		Code:       apierrors.OfferNotFound,
+		Details:    map[string]any{"id": id},
+	}
+}
+
+func dealNotFound(id string) http.HTTPError {
+	return http.HTTPError{
+		Message:    "deal not found",
+		StatusCode: corehttp.StatusNotFound,
+		Code:       apierrors.DealNotFound,
+		Details:    map[string]any{"deal_id": id},
+	}
+}
+
+func resultNotFound(id string) http.HTTPError {
+	return http.HTTPError{
+		Message:    "result not found",
+		StatusCode: corehttp.StatusNotFound,
+		Code:       apierrors.ResultNotFound,
+		Details:    map[string]any{"deal_id": id},
+	}
+}
+
+// the signer is not the party that is allowed to make this call
+func unauthorizedParty(party string, signerAddress string) http.HTTPError {
+	return http.HTTPError{
+		Message:    fmt.Sprintf("%s address does not match signer address", party),
+		StatusCode: corehttp.StatusForbidden,
+		Code:       apierrors.UnauthorizedParty,
+		Details:    map[string]any{"party": party, "signer": signerAddress},
+	}
+}
+
+func invalidTypedSignature(message string, signerAddress string) http.HTTPError {
+	return http.HTTPError{
+		Message:    message,
+		StatusCode: corehttp.StatusUnauthorized,
+		Code:       apierrors.InvalidTypedSignature,
+		Details:    map[string]any{"signer": signerAddress},
+	}
+}
+
+func unsupportedPaymentToken(token string) http.HTTPError {
+	return http.HTTPError{
+		Message:    fmt.Sprintf("deals cannot be paid in %s on this network", token),
+		StatusCode: corehttp.StatusBadRequest,
+		Code:       apierrors.UnsupportedPaymentToken,
+		Details:    map[string]any{"payment_token": token},
+	}
+}
+
+func priceRejected(err error) http.HTTPError {
+	return http.HTTPError{
+		Message:    err.Error(),
+		StatusCode: corehttp.StatusBadRequest,
+		Code:       apierrors.PriceRejected,
+	}
+}
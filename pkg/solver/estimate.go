@@ -0,0 +1,87 @@
+package solver
+
+import (
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/matcher"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+// the executors report one instruction per job for now
+const defaultEstimateInstructionCount = 1
+
+// rough gas used by each transaction a job creator sends for a deal,
+// the approve is only sent when the allowance is too low
+var jobCreatorGasLimits = map[string]uint64{
+	"token.approve":           50000,  //nolint:gomnd
+	"controller.agree":        300000, //nolint:gomnd
+	"controller.acceptResult": 250000, //nolint:gomnd
+}
+
+// what a deal costs the job creator, fees can be nil when there is no
+// estimate for them yet
+func EstimateJobCost(deal data.Deal, instructionCount uint64, fees *web3.FeeEstimate) data.CostEstimate {
+	pricing := deal.Pricing
+	jobCost := pricing.InstructionPrice * instructionCount
+	if jobCost > pricing.PaymentCollateral {
+		jobCost = pricing.PaymentCollateral
+	}
+	estimate := data.CostEstimate{
+		JobOffer:         deal.JobOffer,
+		ResourceOffer:    deal.ResourceOffer.ID,
+		PaymentToken:     deal.PaymentToken,
+		Pricing:          pricing,
+		InstructionCount: instructionCount,
+		JobCost:          jobCost,
+		Collateral:       pricing.PaymentCollateral + deal.Timeouts.JudgeResults.Collateral,
+		MediationFee:     pricing.MediationFee,
+		MaxCost:          jobCost + pricing.MediationFee,
+	}
+	for _, limit := range jobCreatorGasLimits {
+		estimate.GasLimit += limit
+	}
+	if fees != nil {
+		estimate.MaxFee = fees.MaxFee
+		estimate.GasCost = estimate.GasLimit * fees.MaxFee
+	}
+	return estimate
+}
+
+// prices the job offer with the resource offer it would be matched with
+// or with its own pricing when nothing matches right now
+func (controller *SolverController) estimateJobOffer(jobOffer data.JobOffer, instructionCount uint64) (*data.CostEstimate, error) {
+	if instructionCount == 0 {
+		instructionCount = defaultEstimateInstructionCount
+	}
+	err := controller.attributes.ValidateRequirements(jobOffer.Requirements)
+	if err != nil {
+		return nil, err
+	}
+	simulation, err := matcher.SimulateMatch(controller.store, controller.options.Matcher, jobOffer, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	deal := data.Deal{
+		Pricing:      jobOffer.Pricing,
+		Timeouts:     jobOffer.Timeouts,
+		JobOffer:     jobOffer,
+		PaymentToken: jobOffer.PaymentToken,
+	}
+	if simulation.Selected != "" {
+		resourceOffer, err := controller.store.GetResourceOffer(simulation.Selected)
+		if err != nil {
+			return nil, err
+		}
+		if resourceOffer != nil {
+			deal, err = data.GetDeal(jobOffer, resourceOffer.ResourceOffer)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	estimate := EstimateJobCost(deal, instructionCount, controller.web3SDK.GasOracle.Estimate())
+	return &estimate, nil
+}
@@ -0,0 +1,37 @@
+//go:build unit
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateJobCost(t *testing.T) {
+	deal := data.Deal{
+		Pricing: data.DealPricing{
+			InstructionPrice:  2,
+			PaymentCollateral: 5,
+			MediationFee:      1,
+		},
+		Timeouts: data.DealTimeouts{
+			JudgeResults: data.DealTimeout{Collateral: 3},
+		},
+	}
+
+	estimate := EstimateJobCost(deal, 1, nil)
+	assert.Equal(t, uint64(2), estimate.JobCost)
+	assert.Equal(t, uint64(8), estimate.Collateral)
+	assert.Equal(t, uint64(3), estimate.MaxCost)
+	assert.Equal(t, uint64(600000), estimate.GasLimit)
+	assert.Zero(t, estimate.GasCost)
+
+	// the payments contract never takes more than the payment collateral
+	estimate = EstimateJobCost(deal, 10, &web3.FeeEstimate{MaxFee: 100})
+	assert.Equal(t, uint64(5), estimate.JobCost)
+	assert.Equal(t, uint64(6), estimate.MaxCost)
+	assert.Equal(t, uint64(60000000), estimate.GasCost)
+}
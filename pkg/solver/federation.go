@@ -0,0 +1,228 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+type SolverFederationOptions struct {
+	// the urls of the solvers we forward job offers to when we cannot match them
+	Peers []string
+	// the addresses of the solvers we accept forwarded job offers from
+	TrustedPeers []string
+	// how many seconds a job offer can go unmatched before we forward it
+	ForwardAfter int
+}
+
+// forwards job offers we cannot match to peer solvers and mirrors the deals
+// they make back into our store so our job creators can see them
+//
+// the peer brokers the deal so it goes on chain with the peer as the solver,
+// we keep a copy of that exact deal so both sides agree to the same deal ID
+type federation struct {
+	options SolverFederationOptions
+	store   store.SolverStore
+	peers   map[string]*SolverClient
+}
+
+func newFederation(options SolverFederationOptions, solverStore store.SolverStore, signer web3.Signer) (*federation, error) {
+	peers := map[string]*SolverClient{}
+	for _, url := range options.Peers {
+		client, err := NewSolverClient(http.ClientOptions{
+			URL:           url,
+			Signer:        signer,
+			Type:          "Solver",
+			PublicAddress: signer.Address().String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		peers[url] = client
+	}
+	return &federation{
+		options: options,
+		store:   solverStore,
+		peers:   peers,
+	}, nil
+}
+
+func (federation *federation) trusts(address string) bool {
+	return slices.Contains(federation.options.TrustedPeers, address)
+}
+
+// job offers that have waited long enough are sent to the first peer that takes them
+// offers forwarded to us are never forwarded again so they cannot loop between solvers
+func (federation *federation) forwardJobOffers(now time.Time) ([]data.JobOfferContainer, error) {
+	forwarded := []data.JobOfferContainer{}
+	if len(federation.peers) == 0 {
+		return forwarded, nil
+	}
+	jobOffers, err := federation.store.GetJobOffers(store.GetJobOffersQuery{
+		NotMatched: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(-time.Duration(federation.options.ForwardAfter) * time.Second)
+	for _, jobOffer := range jobOffers {
+		if jobOffer.ForwardedTo != "" || jobOffer.Origin != "" {
+			continue
+		}
+		// targeted job offers can only be matched with the provider they name
+		if jobOffer.JobOffer.Target.Address != "" {
+			continue
+		}
+		// the job creator sets created at and could have it forwarded straight away
+		if jobOffer.Received().After(cutoff) {
+			continue
+		}
+		for _, url := range federation.options.Peers {
+			_, err := federation.peers[url].ForwardJobOffer(jobOffer.JobOffer)
+			if err != nil {
+				continue
+			}
+			ret, err := federation.store.UpdateJobOfferForwardedTo(jobOffer.ID, url)
+			if err != nil {
+				return nil, err
+			}
+			forwarded = append(forwarded, *ret)
+			break
+		}
+	}
+	return forwarded, nil
+}
+
+var errPeerDealMismatch = errors.New("peer deal does not match the job offer we forwarded")
+
+// a peer only brokers the job offers we forwarded to it so the deal it
+// sends back has to be for that exact job offer and job creator
+func checkPeerDeal(jobOffer *data.JobOfferContainer, deal data.DealContainer) error {
+	if jobOffer == nil || jobOffer.ForwardedTo == "" {
+		return fmt.Errorf("%w: we did not forward job offer %s", errPeerDealMismatch, deal.JobOffer)
+	}
+	dealJobOfferID, err := data.GetJobOfferID(deal.Deal.JobOffer)
+	if err != nil {
+		return err
+	}
+	if dealJobOfferID != jobOffer.ID {
+		return fmt.Errorf("%w: deal %s is for job offer %s", errPeerDealMismatch, deal.ID, dealJobOfferID)
+	}
+	if !strings.EqualFold(deal.JobCreator, jobOffer.JobCreator) || !strings.EqualFold(deal.Deal.Members.JobCreator, jobOffer.JobCreator) {
+		return fmt.Errorf("%w: deal %s has job creator %s", errPeerDealMismatch, deal.ID, deal.JobCreator)
+	}
+	if deal.Deal.JobOffer.Module != jobOffer.JobOffer.Module {
+		return fmt.Errorf("%w: deal %s is for module %s", errPeerDealMismatch, deal.ID, deal.Deal.JobOffer.Module.Name)
+	}
+	dealID, err := data.GetDealID(deal.Deal)
+	if err != nil {
+		return err
+	}
+	if dealID != deal.ID {
+		return fmt.Errorf("%w: deal %s has the id of another deal", errPeerDealMismatch, deal.ID)
+	}
+	return nil
+}
+
+// returns the deals peers have made for the job offers we forwarded to them
+func (federation *federation) getPeerDeals() ([]data.DealContainer, error) {
+	deals := []data.DealContainer{}
+	jobOffers, err := federation.store.GetJobOffers(store.GetJobOffersQuery{
+		NotMatched: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, jobOffer := range jobOffers {
+		peer, ok := federation.peers[jobOffer.ForwardedTo]
+		if !ok {
+			continue
+		}
+		peerJobOffers, err := peer.GetJobOffers(store.GetJobOffersQuery{
+			JobCreator: jobOffer.JobCreator,
+		})
+		if err != nil {
+			continue
+		}
+		for _, peerJobOffer := range peerJobOffers {
+			if peerJobOffer.ID != jobOffer.ID || peerJobOffer.DealID == "" {
+				continue
+			}
+			deal, err := peer.GetDeal(peerJobOffer.DealID)
+			if err != nil {
+				continue
+			}
+			deals = append(deals, deal)
+		}
+	}
+	return deals, nil
+}
+
+// copy the results of forwarded deals from the peer once they have been submitted
+// so our job creators can download them from us
+func (federation *federation) syncResults() error {
+	jobOffers, err := federation.store.GetJobOffers(store.GetJobOffersQuery{})
+	if err != nil {
+		return err
+	}
+	for _, jobOffer := range jobOffers {
+		peer, ok := federation.peers[jobOffer.ForwardedTo]
+		if !ok || jobOffer.DealID == "" {
+			continue
+		}
+		deal, err := federation.store.GetDeal(jobOffer.DealID)
+		if err != nil {
+			return err
+		}
+		if deal == nil || data.IsActiveAgreementState(deal.State) || deal.State == data.GetAgreementStateIndex("JobOfferCancelled") {
+			continue
+		}
+		existing, err := federation.store.GetResult(deal.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		result, err := peer.GetResult(deal.ID)
+		if err != nil {
+			continue
+		}
+		err = downloadPeerResultFiles(peer, deal.ID)
+		if err != nil {
+			continue
+		}
+		_, err = federation.store.AddResult(result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the files are kept as the archive the resource provider uploaded
+// so we serve them exactly as the peer would
+func downloadPeerResultFiles(peer *SolverClient, id string) error {
+	buf, err := peer.DownloadResultArchive(id)
+	if err != nil {
+		return err
+	}
+	dirPath, err := EnsureDealsFilePath(id)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dirPath, "results.tar"), buf, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing results for deal %s: %s", id, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+//go:build unit
+
+package solver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardJobOffers(t *testing.T) {
+	var forwards atomic.Int32
+	peer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		require.True(t, strings.HasSuffix(req.URL.Path, "/federation/job_offers"), req.URL.Path)
+		forwards.Add(1)
+		jobOffer := data.JobOffer{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&jobOffer))
+		require.NoError(t, json.NewEncoder(res).Encode(data.GetJobOfferContainer(jobOffer)))
+	}))
+	defer peer.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	federation, err := newFederation(SolverFederationOptions{
+		Peers:        []string{peer.URL},
+		ForwardAfter: 60,
+	}, memoryStore, web3.NewKeySigner(key))
+	require.NoError(t, err)
+
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	addJobOffer := func(id string, createdAt time.Time, receivedAt time.Time) {
+		_, err := memoryStore.AddJobOffer(data.JobOfferContainer{
+			ID:         id,
+			JobCreator: "0xjobcreator",
+			JobOffer:   data.JobOffer{ID: id, CreatedAt: int(createdAt.UnixMilli())},
+			ReceivedAt: receivedAt.UnixMilli(),
+		})
+		require.NoError(t, err)
+	}
+	// backdated by the job creator but only just received
+	addJobOffer("backdated", old, now)
+	addJobOffer("waiting", now, old)
+	_, err = memoryStore.AddJobOffer(data.JobOfferContainer{
+		ID:         "forwarded to us",
+		JobOffer:   data.JobOffer{ID: "forwarded to us", CreatedAt: int(old.UnixMilli())},
+		Origin:     "0xpeer",
+		ReceivedAt: old.UnixMilli(),
+	})
+	require.NoError(t, err)
+
+	forwarded, err := federation.forwardJobOffers(now)
+	require.NoError(t, err)
+	require.Len(t, forwarded, 1)
+	require.Equal(t, "waiting", forwarded[0].ID)
+	require.Equal(t, peer.URL, forwarded[0].ForwardedTo)
+	require.Equal(t, int32(1), forwards.Load())
+
+	// a forwarded job offer is not sent again
+	forwarded, err = federation.forwardJobOffers(now)
+	require.NoError(t, err)
+	require.Empty(t, forwarded)
+	require.Equal(t, int32(1), forwards.Load())
+}
+
+func TestCheckPeerDeal(t *testing.T) {
+	module := data.ModuleConfig{Name: "cowsay", Repo: "https://github.com/lilypad-tech/lilypad-module-cowsay", Hash: "v0.0.4"}
+	jobOffer := data.JobOffer{JobCreator: "0xJobCreator", Module: module, CreatedAt: 1}
+	id, err := data.GetJobOfferID(jobOffer)
+	require.NoError(t, err)
+	jobOffer.ID = id
+	forwarded := &data.JobOfferContainer{
+		ID:          id,
+		JobCreator:  jobOffer.JobCreator,
+		JobOffer:    jobOffer,
+		ForwardedTo: "https://peer",
+	}
+
+	peerDeal := func(jobOffer data.JobOffer, jobCreator string) data.DealContainer {
+		deal := data.Deal{
+			Members:  data.DealMembers{JobCreator: jobCreator, ResourceProvider: "0xprovider"},
+			JobOffer: jobOffer,
+		}
+		dealID, err := data.GetDealID(deal)
+		require.NoError(t, err)
+		deal.ID = dealID
+		container := data.GetDealContainer(deal)
+		container.JobOffer = id
+		return container
+	}
+
+	require.NoError(t, checkPeerDeal(forwarded, peerDeal(jobOffer, "0xjobcreator")))
+
+	t.Run("not forwarded", func(t *testing.T) {
+		notForwarded := *forwarded
+		notForwarded.ForwardedTo = ""
+		require.ErrorIs(t, checkPeerDeal(&notForwarded, peerDeal(jobOffer, jobOffer.JobCreator)), errPeerDealMismatch)
+		require.ErrorIs(t, checkPeerDeal(nil, peerDeal(jobOffer, jobOffer.JobCreator)), errPeerDealMismatch)
+	})
+
+	t.Run("another module", func(t *testing.T) {
+		other := jobOffer
+		other.Module.Hash = "v0.0.5"
+		require.ErrorIs(t, checkPeerDeal(forwarded, peerDeal(other, jobOffer.JobCreator)), errPeerDealMismatch)
+	})
+
+	t.Run("another job creator", func(t *testing.T) {
+		require.ErrorIs(t, checkPeerDeal(forwarded, peerDeal(jobOffer, "0xsomeoneelse")), errPeerDealMismatch)
+		deal := peerDeal(jobOffer, jobOffer.JobCreator)
+		deal.JobCreator = "0xsomeoneelse"
+		require.ErrorIs(t, checkPeerDeal(forwarded, deal), errPeerDealMismatch)
+	})
+
+	t.Run("the id of another deal", func(t *testing.T) {
+		deal := peerDeal(jobOffer, jobOffer.JobCreator)
+		deal.Deal.Pricing.InstructionPrice = 1000
+		require.ErrorIs(t, checkPeerDeal(forwarded, deal), errPeerDealMismatch)
+	})
+}
@@ -0,0 +1,634 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	corehttp "net/http"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// a list field without a limit returns this many rows
+	graphqlDefaultLimit = 100
+	graphqlMaxLimit     = 1000
+	// deals -> jobOffer -> matchDecisions is four deep, this leaves room
+	// for dashboards without letting a query walk the graph forever
+	graphqlMaxDepth = 8
+)
+
+// read only, the REST routes are still the way to change anything
+const graphqlSchema = `
+# any json value, used for the parts of offers and deals that are not broken out into fields
+scalar JSON
+
+schema {
+	query: Query
+}
+
+type Query {
+	jobOffers(jobCreator: String, notMatched: Boolean, includeCancelled: Boolean, series: String, limit: Int, offset: Int): [JobOffer!]!
+	jobOffer(id: ID!): JobOffer
+	resourceOffers(resourceProvider: String, active: Boolean, notMatched: Boolean, limit: Int, offset: Int): [ResourceOffer!]!
+	resourceOffer(id: ID!): ResourceOffer
+	# state is an agreement state name e.g. ResultsAccepted
+	deals(jobCreator: String, resourceProvider: String, mediator: String, state: String, limit: Int, offset: Int): [Deal!]!
+	deal(id: ID!): Deal
+	# only for admins
+	results(limit: Int, offset: Int): [Result!]!
+	# only for admins and the parties to the deal
+	result(dealId: ID!): Result
+}
+
+type JobOffer {
+	id: ID!
+	jobCreator: String!
+	state: String!
+	module: String!
+	# the address of the solver that forwarded this job offer to us
+	origin: String
+	# the peer solver we forwarded this job offer to
+	forwardedTo: String
+	# the job offer as it was posted
+	offer: JSON!
+	deal: Deal
+	# why each resource offer did or did not match
+	matchDecisions: [MatchDecision!]!
+}
+
+type ResourceOffer {
+	id: ID!
+	resourceProvider: String!
+	state: String!
+	# the resource offer as it was posted
+	offer: JSON!
+	deal: Deal
+}
+
+type Deal {
+	id: ID!
+	jobCreator: String!
+	resourceProvider: String!
+	mediator: String
+	state: String!
+	# accepted or rejected once a mediator has checked the result
+	mediationOutcome: String
+	jobOffer: JobOffer
+	resourceOffer: ResourceOffer
+	# only for admins and the parties to the deal
+	result: Result
+	# the agreed members, pricing and timeouts
+	agreement: JSON!
+	transactions: JSON!
+}
+
+type Result {
+	id: ID!
+	dealId: ID!
+	dataId: String!
+	error: String
+	instructionCount: Float!
+	# cpu time, peak memory, gpu utilization and duration as the
+	# resource provider measured them
+	usage: JSON
+	deal: Deal
+}
+
+type MatchDecision {
+	jobOfferId: ID!
+	resourceOfferId: ID!
+	dealId: ID
+	matched: Boolean!
+	reason: String
+}
+`
+
+// the JSON scalar
+type graphqlJSON struct {
+	value any
+}
+
+func (graphqlJSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (value *graphqlJSON) UnmarshalGraphQL(input any) error {
+	value.value = input
+	return nil
+}
+
+func (value graphqlJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(value.value)
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func stringArg(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func boolArg(value *bool) bool {
+	return value != nil && *value
+}
+
+func graphqlPage(limit *int32, offset *int32) (store.PageQuery, error) {
+	page := store.PageQuery{Limit: graphqlDefaultLimit}
+	if limit != nil {
+		if *limit < 0 || *limit > graphqlMaxLimit {
+			return page, fmt.Errorf("limit has to be between 0 and %d", graphqlMaxLimit)
+		}
+		page.Limit = int(*limit)
+	}
+	if offset != nil {
+		if *offset < 0 {
+			return page, fmt.Errorf("offset cannot be negative")
+		}
+		page.Offset = int(*offset)
+	}
+	return page, nil
+}
+
+type graphqlSignerKey struct{}
+
+// who signed the query, queries do not have to be signed but only an admin
+// can list results and only the parties to a deal can see its result
+type graphqlSigner struct {
+	address string
+	admin   bool
+}
+
+func graphqlSignerFrom(ctx context.Context) graphqlSigner {
+	signer, _ := ctx.Value(graphqlSignerKey{}).(graphqlSigner)
+	return signer
+}
+
+func (signer graphqlSigner) canSeeResult(deal *data.DealContainer) bool {
+	if signer.admin {
+		return true
+	}
+	if signer.address == "" {
+		return false
+	}
+	for _, party := range []string{deal.JobCreator, deal.ResourceProvider, deal.Mediator} {
+		if strings.EqualFold(party, signer.address) {
+			return true
+		}
+	}
+	return false
+}
+
+type graphqlQuery struct {
+	store store.SolverStore
+}
+
+func (query *graphqlQuery) JobOffers(args struct {
+	JobCreator       *string
+	NotMatched       *bool
+	IncludeCancelled *bool
+	Series           *string
+	Limit            *int32
+	Offset           *int32
+}) ([]*jobOfferResolver, error) {
+	page, err := graphqlPage(args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	jobOffers, err := query.store.GetJobOffers(store.GetJobOffersQuery{
+		JobCreator:       stringArg(args.JobCreator),
+		NotMatched:       boolArg(args.NotMatched),
+		IncludeCancelled: boolArg(args.IncludeCancelled),
+		Series:           stringArg(args.Series),
+		Page:             page,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := []*jobOfferResolver{}
+	for _, jobOffer := range jobOffers {
+		ret = append(ret, &jobOfferResolver{store: query.store, container: jobOffer})
+	}
+	return ret, nil
+}
+
+func (query *graphqlQuery) JobOffer(args struct{ ID graphql.ID }) (*jobOfferResolver, error) {
+	return resolveJobOffer(query.store, string(args.ID))
+}
+
+func (query *graphqlQuery) ResourceOffers(args struct {
+	ResourceProvider *string
+	Active           *bool
+	NotMatched       *bool
+	Limit            *int32
+	Offset           *int32
+}) ([]*resourceOfferResolver, error) {
+	page, err := graphqlPage(args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	resourceOffers, err := query.store.GetResourceOffers(store.GetResourceOffersQuery{
+		ResourceProvider: stringArg(args.ResourceProvider),
+		Active:           boolArg(args.Active),
+		NotMatched:       boolArg(args.NotMatched),
+		Page:             page,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := []*resourceOfferResolver{}
+	for _, resourceOffer := range resourceOffers {
+		ret = append(ret, &resourceOfferResolver{store: query.store, container: resourceOffer})
+	}
+	return ret, nil
+}
+
+func (query *graphqlQuery) ResourceOffer(args struct{ ID graphql.ID }) (*resourceOfferResolver, error) {
+	return resolveResourceOffer(query.store, string(args.ID))
+}
+
+func (query *graphqlQuery) Deals(args struct {
+	JobCreator       *string
+	ResourceProvider *string
+	Mediator         *string
+	State            *string
+	Limit            *int32
+	Offset           *int32
+}) ([]*dealResolver, error) {
+	page, err := graphqlPage(args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	deals, err := query.store.GetDeals(store.GetDealsQuery{
+		JobCreator:       stringArg(args.JobCreator),
+		ResourceProvider: stringArg(args.ResourceProvider),
+		Mediator:         stringArg(args.Mediator),
+		State:            stringArg(args.State),
+		Page:             page,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := []*dealResolver{}
+	for _, deal := range deals {
+		ret = append(ret, &dealResolver{store: query.store, container: deal})
+	}
+	return ret, nil
+}
+
+func (query *graphqlQuery) Deal(args struct{ ID graphql.ID }) (*dealResolver, error) {
+	return resolveDeal(query.store, string(args.ID))
+}
+
+func (query *graphqlQuery) Results(ctx context.Context, args struct {
+	Limit  *int32
+	Offset *int32
+}) ([]*resultResolver, error) {
+	if !graphqlSignerFrom(ctx).admin {
+		return nil, fmt.Errorf("only an admin can list results")
+	}
+	page, err := graphqlPage(args.Limit, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	results, err := query.store.GetResultsPage(page)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*resultResolver{}
+	for _, result := range results {
+		ret = append(ret, &resultResolver{store: query.store, result: result})
+	}
+	return ret, nil
+}
+
+func (query *graphqlQuery) Result(ctx context.Context, args struct{ DealID graphql.ID }) (*resultResolver, error) {
+	return resolveResult(ctx, query.store, string(args.DealID))
+}
+
+// the store returns nil for a missing row and so do these, which graphql shows as null
+func resolveJobOffer(solverStore store.SolverStore, id string) (*jobOfferResolver, error) {
+	jobOffer, err := solverStore.GetJobOffer(id)
+	if err != nil || jobOffer == nil {
+		return nil, err
+	}
+	return &jobOfferResolver{store: solverStore, container: *jobOffer}, nil
+}
+
+func resolveResourceOffer(solverStore store.SolverStore, id string) (*resourceOfferResolver, error) {
+	resourceOffer, err := solverStore.GetResourceOffer(id)
+	if err != nil || resourceOffer == nil {
+		return nil, err
+	}
+	return &resourceOfferResolver{store: solverStore, container: *resourceOffer}, nil
+}
+
+func resolveDeal(solverStore store.SolverStore, id string) (*dealResolver, error) {
+	if id == "" {
+		return nil, nil
+	}
+	deal, err := solverStore.GetDeal(id)
+	if err != nil || deal == nil {
+		return nil, err
+	}
+	return &dealResolver{store: solverStore, container: *deal}, nil
+}
+
+func resolveResult(ctx context.Context, solverStore store.SolverStore, dealID string) (*resultResolver, error) {
+	deal, err := solverStore.GetDeal(dealID)
+	if err != nil || deal == nil {
+		return nil, err
+	}
+	if !graphqlSignerFrom(ctx).canSeeResult(deal) {
+		return nil, fmt.Errorf("only an admin or a party to deal %s can see its result", dealID)
+	}
+	result, err := solverStore.GetResult(dealID)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return &resultResolver{store: solverStore, result: *result}, nil
+}
+
+type jobOfferResolver struct {
+	store     store.SolverStore
+	container data.JobOfferContainer
+}
+
+func (r *jobOfferResolver) ID() graphql.ID {
+	return graphql.ID(r.container.ID)
+}
+
+func (r *jobOfferResolver) JobCreator() string {
+	return r.container.JobCreator
+}
+
+func (r *jobOfferResolver) State() string {
+	return data.GetAgreementStateString(r.container.State)
+}
+
+func (r *jobOfferResolver) Module() string {
+	return r.container.JobOffer.Module.Name
+}
+
+func (r *jobOfferResolver) Origin() *string {
+	return optionalString(r.container.Origin)
+}
+
+func (r *jobOfferResolver) ForwardedTo() *string {
+	return optionalString(r.container.ForwardedTo)
+}
+
+func (r *jobOfferResolver) Offer() graphqlJSON {
+	return graphqlJSON{value: r.container.JobOffer}
+}
+
+func (r *jobOfferResolver) Deal() (*dealResolver, error) {
+	return resolveDeal(r.store, r.container.DealID)
+}
+
+func (r *jobOfferResolver) MatchDecisions() ([]*matchDecisionResolver, error) {
+	decisions, err := r.store.GetJobOfferMatchDecisions(r.container.ID)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*matchDecisionResolver{}
+	for _, decision := range decisions {
+		ret = append(ret, &matchDecisionResolver{decision: decision})
+	}
+	return ret, nil
+}
+
+type resourceOfferResolver struct {
+	store     store.SolverStore
+	container data.ResourceOfferContainer
+}
+
+func (r *resourceOfferResolver) ID() graphql.ID {
+	return graphql.ID(r.container.ID)
+}
+
+func (r *resourceOfferResolver) ResourceProvider() string {
+	return r.container.ResourceProvider
+}
+
+func (r *resourceOfferResolver) State() string {
+	return data.GetAgreementStateString(r.container.State)
+}
+
+func (r *resourceOfferResolver) Offer() graphqlJSON {
+	return graphqlJSON{value: r.container.ResourceOffer}
+}
+
+func (r *resourceOfferResolver) Deal() (*dealResolver, error) {
+	return resolveDeal(r.store, r.container.DealID)
+}
+
+type dealResolver struct {
+	store     store.SolverStore
+	container data.DealContainer
+}
+
+func (r *dealResolver) ID() graphql.ID {
+	return graphql.ID(r.container.ID)
+}
+
+func (r *dealResolver) JobCreator() string {
+	return r.container.JobCreator
+}
+
+func (r *dealResolver) ResourceProvider() string {
+	return r.container.ResourceProvider
+}
+
+func (r *dealResolver) Mediator() *string {
+	return optionalString(r.container.Mediator)
+}
+
+func (r *dealResolver) State() string {
+	return data.GetAgreementStateString(r.container.State)
+}
+
+func (r *dealResolver) Agreement() graphqlJSON {
+	return graphqlJSON{value: r.container.Deal}
+}
+
+func (r *dealResolver) Transactions() graphqlJSON {
+	return graphqlJSON{value: r.container.Transactions}
+}
+
+func (r *dealResolver) MediationOutcome() *string {
+	switch r.container.State {
+	case data.GetAgreementStateIndex("MediationAccepted"):
+		return optionalString("accepted")
+	case data.GetAgreementStateIndex("MediationRejected"):
+		return optionalString("rejected")
+	}
+	return nil
+}
+
+func (r *dealResolver) JobOffer() (*jobOfferResolver, error) {
+	return resolveJobOffer(r.store, r.container.JobOffer)
+}
+
+func (r *dealResolver) ResourceOffer() (*resourceOfferResolver, error) {
+	return resolveResourceOffer(r.store, r.container.ResourceOffer)
+}
+
+func (r *dealResolver) Result(ctx context.Context) (*resultResolver, error) {
+	return resolveResult(ctx, r.store, r.container.ID)
+}
+
+type resultResolver struct {
+	store  store.SolverStore
+	result data.Result
+}
+
+func (r *resultResolver) ID() graphql.ID {
+	return graphql.ID(r.result.ID)
+}
+
+func (r *resultResolver) DealID() graphql.ID {
+	return graphql.ID(r.result.DealID)
+}
+
+func (r *resultResolver) DataID() string {
+	return r.result.DataID
+}
+
+func (r *resultResolver) Error() *string {
+	return optionalString(r.result.Error)
+}
+
+// graphql ints are 32 bit so a count this size goes out as a float
+func (r *resultResolver) InstructionCount() float64 {
+	return float64(r.result.InstructionCount)
+}
+
+func (r *resultResolver) Usage() *graphqlJSON {
+	if r.result.Usage == nil {
+		return nil
+	}
+	return &graphqlJSON{value: r.result.Usage}
+}
+
+func (r *resultResolver) Deal() (*dealResolver, error) {
+	return resolveDeal(r.store, r.result.DealID)
+}
+
+type matchDecisionResolver struct {
+	decision data.MatchDecision
+}
+
+func (r *matchDecisionResolver) JobOfferID() graphql.ID {
+	return graphql.ID(r.decision.JobOffer)
+}
+
+func (r *matchDecisionResolver) ResourceOfferID() graphql.ID {
+	return graphql.ID(r.decision.ResourceOffer)
+}
+
+func (r *matchDecisionResolver) DealID() *graphql.ID {
+	if r.decision.Deal == "" {
+		return nil
+	}
+	id := graphql.ID(r.decision.Deal)
+	return &id
+}
+
+func (r *matchDecisionResolver) Matched() bool {
+	return r.decision.Result
+}
+
+func (r *matchDecisionResolver) Reason() *string {
+	return optionalString(r.decision.Reason)
+}
+
+/*
+ *
+ *
+ *
+
+ Handler
+
+ *
+ *
+ *
+*/
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// only used to document the route, the body comes from the graphql library
+type graphqlResponse struct {
+	Data   map[string]any   `json:"data"`
+	Errors []map[string]any `json:"errors,omitempty"`
+}
+
+func newGraphQLSchema(solverStore store.SolverStore) (*graphql.Schema, error) {
+	return graphql.ParseSchema(
+		graphqlSchema,
+		&graphqlQuery{store: solverStore},
+		graphql.MaxDepth(graphqlMaxDepth),
+	)
+}
+
+// queries come as POST json bodies or in the query string of a GET,
+// errors in the query itself are in the errors of a 200 response
+// as graphql clients expect
+func graphqlHandler(schema *graphql.Schema, isAdmin func(string) bool) corehttp.HandlerFunc {
+	return func(res corehttp.ResponseWriter, req *corehttp.Request) {
+		var request graphqlRequest
+		if req.Method == corehttp.MethodGet {
+			request.Query = req.URL.Query().Get("query")
+			request.OperationName = req.URL.Query().Get("operationName")
+			if variables := req.URL.Query().Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+					http.WriteErrorMessage(res, req, fmt.Sprintf("invalid variables: %s", err.Error()), corehttp.StatusBadRequest)
+					return
+				}
+			}
+		} else {
+			var err error
+			request, err = http.ReadBody[graphqlRequest](req)
+			if err != nil {
+				http.WriteErrorMessage(res, req, fmt.Sprintf("invalid graphql request: %s", err.Error()), corehttp.StatusBadRequest)
+				return
+			}
+		}
+		if request.Query == "" {
+			http.WriteErrorMessage(res, req, "missing graphql query", corehttp.StatusBadRequest)
+			return
+		}
+
+		// an unsigned query only sees what is public
+		signer := graphqlSigner{}
+		if signerAddress, err := http.CheckSignature(req); err == nil {
+			signer = graphqlSigner{address: signerAddress, admin: isAdmin(signerAddress)}
+		}
+		ctx := context.WithValue(req.Context(), graphqlSignerKey{}, signer)
+
+		response := schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+		if len(response.Errors) > 0 {
+			http.RequestLogger(req).Debug().Interface("errors", response.Errors).Msgf("graphql query errors")
+		}
+		res.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(res).Encode(response); err != nil {
+			log.Error().Err(err).Msgf("error encoding graphql response")
+		}
+	}
+}
@@ -0,0 +1,106 @@
+//go:build unit
+
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	lilypadhttp "github.com/lilypad-tech/lilypad/pkg/http"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+type graphqlTestResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	adminKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	jobCreatorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	strangerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	admin := web3.NewKeySigner(adminKey)
+	jobCreator := web3.NewKeySigner(jobCreatorKey)
+	stranger := web3.NewKeySigner(strangerKey)
+
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	_, err = memoryStore.AddDeal(data.DealContainer{
+		ID:               "deal",
+		JobCreator:       jobCreator.Address().String(),
+		ResourceProvider: "0x0000000000000000000000000000000000000001",
+	})
+	require.NoError(t, err)
+	_, err = memoryStore.AddResult(data.Result{ID: "result", DealID: "deal", DataID: "data"})
+	require.NoError(t, err)
+
+	schema, err := newGraphQLSchema(memoryStore)
+	require.NoError(t, err)
+	handler := graphqlHandler(schema, func(address string) bool {
+		return strings.EqualFold(address, admin.Address().String())
+	})
+
+	query := func(signer web3.Signer, query string) graphqlTestResponse {
+		body, err := json.Marshal(graphqlRequest{Query: query})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/graphql", bytes.NewReader(body))
+		if signer != nil {
+			signed, err := retryablehttp.NewRequest(http.MethodPost, "/api/v1/graphql", nil)
+			require.NoError(t, err)
+			require.NoError(t, lilypadhttp.AddHeaders(signed, signer, signer.Address().String()))
+			req.Header = signed.Header
+		}
+		res := httptest.NewRecorder()
+		handler(res, req)
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		response := graphqlTestResponse{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("depth limit", func(t *testing.T) {
+		response := query(nil, `{ deals { jobOffer { deal { jobOffer { deal { jobOffer { deal { jobOffer { id } } } } } } } } }`)
+		require.NotEmpty(t, response.Errors)
+		require.Empty(t, query(nil, `{ deals { jobOffer { deal { id } } } }`).Errors)
+	})
+
+	t.Run("limit cap", func(t *testing.T) {
+		response := query(nil, `{ deals(limit: 1001) { id } }`)
+		require.NotEmpty(t, response.Errors)
+		require.Contains(t, response.Errors[0].Message, "limit has to be between 0 and 1000")
+		require.Empty(t, query(nil, `{ deals(limit: 1000) { id } }`).Errors)
+	})
+
+	t.Run("results are for admins", func(t *testing.T) {
+		require.NotEmpty(t, query(nil, `{ results { id } }`).Errors)
+		require.NotEmpty(t, query(jobCreator, `{ results { id } }`).Errors)
+		response := query(admin, `{ results { id } }`)
+		require.Empty(t, response.Errors)
+		require.JSONEq(t, `[{"id":"result"}]`, string(response.Data["results"]))
+	})
+
+	t.Run("a result is for admins and parties", func(t *testing.T) {
+		for _, q := range []string{`{ result(dealId: "deal") { id } }`, `{ deal(id: "deal") { result { id } } }`} {
+			require.NotEmpty(t, query(nil, q).Errors, q)
+			require.NotEmpty(t, query(stranger, q).Errors, q)
+			require.Empty(t, query(jobCreator, q).Errors, q)
+			require.Empty(t, query(admin, q).Errors, q)
+		}
+		response := query(jobCreator, `{ result(dealId: "deal") { dataId } }`)
+		require.JSONEq(t, `{"dataId":"data"}`, string(response.Data["result"]))
+	})
+}
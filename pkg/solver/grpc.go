@@ -0,0 +1,192 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/solverpb"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+)
+
+// the solver api over gRPC, it shares the checks and the controller
+// with the REST routes so both behave the same way
+type solverGRPCServer struct {
+	solverpb.UnimplementedSolverServer
+	server *solverServer
+}
+
+func (solverServer *solverServer) registerGRPC(server *grpc.Server) {
+	solverpb.RegisterSolverServer(server, &solverGRPCServer{server: solverServer})
+}
+
+func (s *solverGRPCServer) GetJobOffers(ctx context.Context, req *solverpb.GetJobOffersRequest) (*solverpb.GetJobOffersResponse, error) {
+	jobOffers, err := s.server.store.GetJobOffers(store.GetJobOffersQuery{
+		JobCreator:       req.GetJobCreator(),
+		NotMatched:       req.GetNotMatched(),
+		IncludeCancelled: req.GetIncludeCancelled(),
+		Series:           req.GetSeries(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := &solverpb.GetJobOffersResponse{}
+	for _, jobOffer := range jobOffers {
+		ret.JobOffers = append(ret.JobOffers, solverpb.FromJobOfferContainer(jobOffer))
+	}
+	return ret, nil
+}
+
+func (s *solverGRPCServer) AddJobOffer(ctx context.Context, req *solverpb.AddJobOfferRequest) (*solverpb.JobOfferContainer, error) {
+	signerAddress, err := http.CheckGRPCSignature(ctx)
+	if err != nil {
+		log.Error().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	typedSignature, err := http.GRPCTypedSignature(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobOffer, err := s.server.addSignedJobOffer(solverpb.ToJobOffer(req.GetJobOffer()), signerAddress, typedSignature)
+	if err != nil {
+		return nil, err
+	}
+	return solverpb.FromJobOfferContainer(*jobOffer), nil
+}
+
+func (s *solverGRPCServer) GetResourceOffers(ctx context.Context, req *solverpb.GetResourceOffersRequest) (*solverpb.GetResourceOffersResponse, error) {
+	resourceOffers, err := s.server.store.GetResourceOffers(store.GetResourceOffersQuery{
+		ResourceProvider: req.GetResourceProvider(),
+		Active:           req.GetActive(),
+		NotMatched:       req.GetNotMatched(),
+		Attributes:       req.GetAttributes(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := &solverpb.GetResourceOffersResponse{}
+	for _, resourceOffer := range resourceOffers {
+		ret.ResourceOffers = append(ret.ResourceOffers, solverpb.FromResourceOfferContainer(resourceOffer))
+	}
+	return ret, nil
+}
+
+func (s *solverGRPCServer) AddResourceOffer(ctx context.Context, req *solverpb.AddResourceOfferRequest) (*solverpb.ResourceOfferContainer, error) {
+	signerAddress, err := http.CheckGRPCSignature(ctx)
+	if err != nil {
+		log.Error().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	typedSignature, err := http.GRPCTypedSignature(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resourceOffer, err := s.server.addSignedResourceOffer(solverpb.ToResourceOffer(req.GetResourceOffer()), signerAddress, typedSignature)
+	if err != nil {
+		return nil, err
+	}
+	return solverpb.FromResourceOfferContainer(*resourceOffer), nil
+}
+
+func (s *solverGRPCServer) GetDeals(ctx context.Context, req *solverpb.GetDealsRequest) (*solverpb.GetDealsResponse, error) {
+	deals, err := s.server.store.GetDeals(store.GetDealsQuery{
+		JobCreator:       req.GetJobCreator(),
+		ResourceProvider: req.GetResourceProvider(),
+		Mediator:         req.GetMediator(),
+		State:            req.GetState(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := &solverpb.GetDealsResponse{}
+	for _, deal := range deals {
+		ret.Deals = append(ret.Deals, solverpb.FromDealContainer(deal))
+	}
+	return ret, nil
+}
+
+func (s *solverGRPCServer) getDeal(id string) (*data.DealContainer, error) {
+	deal, err := s.server.store.GetDeal(id)
+	if err != nil {
+		return nil, err
+	}
+	if deal == nil {
+		return nil, dealNotFound(id)
+	}
+	return deal, nil
+}
+
+func (s *solverGRPCServer) GetDeal(ctx context.Context, req *solverpb.GetDealRequest) (*solverpb.DealContainer, error) {
+	deal, err := s.getDeal(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return solverpb.FromDealContainer(*deal), nil
+}
+
+func (s *solverGRPCServer) GetResult(ctx context.Context, req *solverpb.GetResultRequest) (*solverpb.Result, error) {
+	result, err := s.server.store.GetResult(req.GetDealId())
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, resultNotFound(req.GetDealId())
+	}
+	return solverpb.FromResult(*result), nil
+}
+
+// the streaming version of the deal's server-sent events, with the same
+// check that the caller is a party to the deal
+func (s *solverGRPCServer) WatchDeal(req *solverpb.WatchDealRequest, stream grpc.ServerStreamingServer[solverpb.DealEvent]) error {
+	deal, err := s.getDeal(req.GetId())
+	if err != nil {
+		return err
+	}
+	signerAddress, err := http.CheckGRPCSignature(stream.Context())
+	if err != nil {
+		return err
+	}
+	visible, err := s.server.dealEventsFilter(deal, signerAddress)
+	if err != nil {
+		return err
+	}
+
+	backlog, events, cancel := s.server.dealEvents.subscribe(req.GetId(), req.GetLastEventId())
+	defer cancel()
+
+	for _, entry := range backlog {
+		if !visible(entry) {
+			continue
+		}
+		if err := stream.Send(toDealEventMessage(entry)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case entry := <-events:
+			if !visible(entry) {
+				continue
+			}
+			if err := stream.Send(toDealEventMessage(entry)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toDealEventMessage(entry dealEvent) *solverpb.DealEvent {
+	ret := &solverpb.DealEvent{
+		Id:        entry.id,
+		EventType: string(entry.event.EventType),
+		Deal:      solverpb.FromDealContainer(*entry.event.Deal),
+	}
+	if entry.event.Result != nil {
+		ret.Result = solverpb.FromResult(*entry.event.Result)
+	}
+	return ret
+}
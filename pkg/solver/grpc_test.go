@@ -0,0 +1,124 @@
+//go:build unit
+
+package solver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	lilypadhttp "github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/solverpb"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type dealEventStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*solverpb.DealEvent
+}
+
+func (stream *dealEventStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *dealEventStream) Send(event *solverpb.DealEvent) error {
+	stream.events = append(stream.events, event)
+	return nil
+}
+
+// the incoming metadata a client signing as signer would send
+func signedContext(t *testing.T, signer web3.Signer) context.Context {
+	req, err := retryablehttp.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	require.NoError(t, lilypadhttp.AddHeaders(req, signer, signer.Address().String()))
+	md := metadata.MD{}
+	for key, values := range req.Header {
+		md.Append(strings.ToLower(key), values...)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestWatchDealParties(t *testing.T) {
+	jobCreatorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	strangerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	jobCreator := web3.NewKeySigner(jobCreatorKey)
+	stranger := web3.NewKeySigner(strangerKey)
+
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	deal := data.DealContainer{
+		ID:               "deal",
+		JobCreator:       jobCreator.Address().String(),
+		ResourceProvider: "0x0000000000000000000000000000000000000001",
+	}
+	_, err = memoryStore.AddDeal(deal)
+	require.NoError(t, err)
+
+	server := &solverServer{store: memoryStore, dealEvents: newDealEventLog()}
+	server.dealEvents.add(SolverEvent{EventType: DealStateUpdated, Deal: &deal})
+	grpcServer := &solverGRPCServer{server: server}
+
+	watch := func(ctx context.Context) (*dealEventStream, error) {
+		// the stream runs until the client goes away
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		stream := &dealEventStream{ctx: ctx}
+		err := grpcServer.WatchDeal(&solverpb.WatchDealRequest{Id: "deal"}, stream)
+		return stream, lilypadhttp.GRPCError(err)
+	}
+
+	_, err = watch(context.Background())
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = watch(signedContext(t, stranger))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	stream, err := watch(signedContext(t, jobCreator))
+	require.NoError(t, err)
+	require.Len(t, stream.events, 1)
+}
+
+func TestGRPCRequest(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "secret"))
+
+	req, err := grpcRequest(ctx, solverpb.Solver_AddJobOffer_FullMethodName)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, lilypadhttp.API_SUB_PATH+"/job_offers", req.URL.Path)
+	require.Equal(t, "secret", req.Header.Get("X-Api-Key"))
+	require.Equal(t, rateGroupOffers, rateGroup(req))
+
+	req, err = grpcRequest(ctx, solverpb.Solver_GetDeal_FullMethodName)
+	require.NoError(t, err)
+	require.Equal(t, rateGroupReads, rateGroup(req))
+
+	// a method without a route is a write
+	req, err = grpcRequest(ctx, "/solver.Solver/Unknown")
+	require.NoError(t, err)
+	require.Equal(t, rateGroupWrites, rateGroup(req))
+}
+
+func TestGRPCCheckResponse(t *testing.T) {
+	res := &grpcCheckResponse{header: http.Header{}}
+	require.NoError(t, res.err())
+
+	res = &grpcCheckResponse{header: http.Header{}}
+	lilypadhttp.WriteError(res, &http.Request{}, lilypadhttp.HTTPError{
+		Message:    "slow down",
+		StatusCode: http.StatusTooManyRequests,
+	})
+	require.Equal(t, codes.ResourceExhausted, status.Code(lilypadhttp.GRPCError(res.err())))
+}
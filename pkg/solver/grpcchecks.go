@@ -0,0 +1,132 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	corehttp "net/http"
+
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/solverpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+type grpcRoute struct {
+	method string
+	path   string
+}
+
+// the REST route each gRPC method stands in for, the calls are put past the
+// route's rate limit and read auth so gRPC is not a way around either
+var grpcRoutes = map[string]grpcRoute{
+	solverpb.Solver_GetJobOffers_FullMethodName:      {corehttp.MethodGet, "/job_offers"},
+	solverpb.Solver_AddJobOffer_FullMethodName:       {corehttp.MethodPost, "/job_offers"},
+	solverpb.Solver_GetResourceOffers_FullMethodName: {corehttp.MethodGet, "/resource_offers"},
+	solverpb.Solver_AddResourceOffer_FullMethodName:  {corehttp.MethodPost, "/resource_offers"},
+	solverpb.Solver_GetDeals_FullMethodName:          {corehttp.MethodGet, "/deals"},
+	solverpb.Solver_GetDeal_FullMethodName:           {corehttp.MethodGet, "/deals/{id}"},
+	solverpb.Solver_GetResult_FullMethodName:         {corehttp.MethodGet, "/deals/{id}/result"},
+	solverpb.Solver_WatchDeal_FullMethodName:         {corehttp.MethodGet, "/deals/{id}/events"},
+}
+
+// the REST request the call would have been, a method we have no route
+// for is counted as a write
+func grpcRequest(ctx context.Context, fullMethod string) (*corehttp.Request, error) {
+	route, ok := grpcRoutes[fullMethod]
+	if !ok {
+		route = grpcRoute{corehttp.MethodPost, fullMethod}
+	}
+	req, err := corehttp.NewRequestWithContext(ctx, route.method, http.API_SUB_PATH+route.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+	return req, nil
+}
+
+// keeps what the REST middleware wrote when it turned the request away
+type grpcCheckResponse struct {
+	header corehttp.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (res *grpcCheckResponse) Header() corehttp.Header {
+	return res.header
+}
+
+func (res *grpcCheckResponse) Write(p []byte) (int, error) {
+	return res.body.Write(p)
+}
+
+func (res *grpcCheckResponse) WriteHeader(status int) {
+	res.status = status
+}
+
+// the error the middleware sent back, nil when it let the request through
+func (res *grpcCheckResponse) err() error {
+	if res.status == 0 || res.status < corehttp.StatusBadRequest {
+		return nil
+	}
+	envelope := apierrors.ErrorEnvelope{}
+	_ = json.Unmarshal(res.body.Bytes(), &envelope)
+	if envelope.Message == "" {
+		envelope.Message = corehttp.StatusText(res.status)
+	}
+	return http.HTTPError{
+		Message:    envelope.Message,
+		StatusCode: res.status,
+		Code:       envelope.Code,
+	}
+}
+
+// the gRPC calls go through the same rate limiter and read auth as the
+// REST routes and their signed writes are audited the same way
+func (solverServer *solverServer) grpcInterceptors(rateLimit func(corehttp.Handler) corehttp.Handler) []grpc.ServerOption {
+	checks := rateLimit(solverServer.readAuthMiddleware(corehttp.HandlerFunc(func(corehttp.ResponseWriter, *corehttp.Request) {})))
+	check := func(ctx context.Context, fullMethod string) (*corehttp.Request, error) {
+		req, err := grpcRequest(ctx, fullMethod)
+		if err != nil {
+			return nil, err
+		}
+		res := &grpcCheckResponse{header: corehttp.Header{}}
+		checks.ServeHTTP(res, req)
+		return req, res.err()
+	}
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		restReq, err := check(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		res, err := handler(ctx, req)
+		if message, ok := req.(proto.Message); ok && !isReadRequest(restReq) {
+			// unsigned writes are turned away by their handlers
+			if signerAddress, signatureErr := http.CheckGRPCSignature(ctx); signatureErr == nil {
+				solverServer.auditGRPC(ctx, info.FullMethod, signerAddress, message, err)
+			}
+		}
+		return res, err
+	}
+	stream := func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, err := check(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary),
+		grpc.ChainStreamInterceptor(stream),
+	}
+}
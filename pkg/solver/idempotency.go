@@ -0,0 +1,179 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	corehttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/rs/zerolog/log"
+)
+
+// how long a response is kept for retries to replay
+const idempotentResponseTTL = 24 * time.Hour
+
+// the keys with a request running, a second request with one of
+// them has to wait for the first to finish before it can replay it
+type idempotencyKeys struct {
+	mutex   sync.Mutex
+	running map[string]bool
+}
+
+func newIdempotencyKeys() *idempotencyKeys {
+	return &idempotencyKeys{
+		running: map[string]bool{},
+	}
+}
+
+func (keys *idempotencyKeys) start(key string) bool {
+	keys.mutex.Lock()
+	defer keys.mutex.Unlock()
+	if keys.running[key] {
+		return false
+	}
+	keys.running[key] = true
+	return true
+}
+
+func (keys *idempotencyKeys) finish(key string) {
+	keys.mutex.Lock()
+	defer keys.mutex.Unlock()
+	delete(keys.running, key)
+}
+
+// the key is scoped to the signer and the path so one client
+// cannot get the response to a request another client made
+func idempotentResponseKey(signer string, path string, key string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(signer) + "\n" + path + "\n" + key))
+	return hex.EncodeToString(hash[:])
+}
+
+// wraps a POST handler so a request with an Idempotency-Key that has been
+// seen before gets the first response back, only successful responses are
+// kept so a retry after an error runs the handler again
+func (solverServer *solverServer) idempotency(next corehttp.HandlerFunc) corehttp.HandlerFunc {
+	return func(res corehttp.ResponseWriter, req *corehttp.Request) {
+		key := req.Header.Get(http.IDEMPOTENCY_KEY_HEADER)
+		if key == "" {
+			next(res, req)
+			return
+		}
+		if len(key) > http.MAX_IDEMPOTENCY_KEY_LENGTH {
+			http.WriteErrorMessage(res, req, fmt.Sprintf("%s is longer than %d characters", http.IDEMPOTENCY_KEY_HEADER, http.MAX_IDEMPOTENCY_KEY_LENGTH), corehttp.StatusBadRequest)
+			return
+		}
+		// the handler turns away unsigned requests itself
+		signerAddress, err := http.CheckSignature(req)
+		if err != nil {
+			next(res, req)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			var maxBytesError *corehttp.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				http.WriteErrorMessage(res, req, fmt.Sprintf("request body is larger than %d bytes", maxBytesError.Limit), corehttp.StatusRequestEntityTooLarge)
+				return
+			}
+			http.WriteErrorMessage(res, req, "Error reading request body", corehttp.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(bodyHash[:])
+
+		responseKey := idempotentResponseKey(signerAddress, req.URL.Path, key)
+		if !solverServer.idempotent.start(responseKey) {
+			http.WriteErrorMessage(res, req, fmt.Sprintf("a request with this %s is still running", http.IDEMPOTENCY_KEY_HEADER), corehttp.StatusConflict)
+			return
+		}
+		defer solverServer.idempotent.finish(responseKey)
+
+		existing, err := solverServer.store.GetIdempotentResponse(responseKey)
+		if err != nil {
+			http.RequestLogger(req).Error().Err(err).Msgf("error loading idempotent response")
+			http.WriteErrorMessage(res, req, err.Error(), corehttp.StatusInternalServerError)
+			return
+		}
+		if existing != nil && time.Since(time.UnixMilli(existing.CreatedAt)) < idempotentResponseTTL {
+			if existing.RequestHash != requestHash {
+				http.WriteErrorMessage(res, req, fmt.Sprintf("%s was used with a different request body", http.IDEMPOTENCY_KEY_HEADER), corehttp.StatusUnprocessableEntity)
+				return
+			}
+			http.RequestLogger(req).Info().Str("idempotency_key", key).Msgf("replaying response")
+			res.Header().Set("Content-Type", "application/json")
+			res.Header().Set(http.IDEMPOTENT_REPLAYED_HEADER, "true")
+			res.WriteHeader(existing.StatusCode)
+			_, _ = res.Write([]byte(existing.Body))
+			return
+		}
+		if existing != nil {
+			// expired and not cleaned up yet, the key can be used again
+			if err := solverServer.store.RemoveIdempotentResponsesBefore(time.Now().Add(-idempotentResponseTTL).UnixMilli()); err != nil {
+				http.RequestLogger(req).Error().Err(err).Msgf("error removing expired idempotent responses")
+				http.WriteErrorMessage(res, req, err.Error(), corehttp.StatusInternalServerError)
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: res, status: corehttp.StatusOK}
+		next(recorder, req)
+		if recorder.status < 200 || recorder.status >= 300 {
+			return
+		}
+		_, err = solverServer.store.AddIdempotentResponse(data.IdempotentResponse{
+			Key:         responseKey,
+			RequestHash: requestHash,
+			StatusCode:  recorder.status,
+			Body:        recorder.body.String(),
+			CreatedAt:   time.Now().UnixMilli(),
+		})
+		if err != nil {
+			// the request went through, the worst case is a retry runs it again
+			http.RequestLogger(req).Error().Err(err).Msgf("error saving idempotent response")
+		}
+	}
+}
+
+// keeps a copy of what the handler writes so it can be replayed
+type responseRecorder struct {
+	corehttp.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (solverServer *solverServer) expireIdempotentResponses(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := solverServer.store.RemoveIdempotentResponsesBefore(time.Now().Add(-idempotentResponseTTL).UnixMilli())
+			if err != nil {
+				log.Error().Err(err).Msgf("error removing expired idempotent responses")
+			}
+		}
+	}
+}
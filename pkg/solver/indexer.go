@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	corehttp "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/rs/zerolog/log"
+)
+
+type SolverIndexerOptions struct {
+	// how many seconds between reads of the new blocks
+	// zero turns the indexer off
+	Interval int
+	// where to start when nothing has been indexed yet
+	// zero starts from the newest confirmed block
+	StartBlock uint64
+}
+
+// the blocks read and stored in one go so a long backfill is kept as it goes
+const indexerBlockRange = 2000
+
+// copies every event the Lilypad contracts emit into the store so analytics
+// and dashboards can query them without an rpc node of their own
+//
+// only blocks WEB3_REORG_DEPTH behind the head are read so an event is
+// never stored from a block a reorg then drops
+type chainIndexer struct {
+	options SolverIndexerOptions
+	web3SDK *web3.Web3SDK
+	store   store.SolverStore
+	// the next block to read, worked out from the store on the first pass
+	next    uint64
+	resumed bool
+}
+
+func newChainIndexer(options SolverIndexerOptions, web3SDK *web3.Web3SDK, solverStore store.SolverStore) *chainIndexer {
+	return &chainIndexer{
+		options: options,
+		web3SDK: web3SDK,
+		store:   solverStore,
+	}
+}
+
+// carries on from the block of the newest stored event, that block is read
+// again as the store keeps the events it already has
+func (indexer *chainIndexer) resume(confirmed uint64) error {
+	latest, err := indexer.store.GetChainEvents(store.GetChainEventsQuery{
+		Page: store.PageQuery{Limit: 1, Order: store.OrderDescending},
+	})
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(latest) > 0:
+		indexer.next = latest[0].Block
+	case indexer.options.StartBlock > 0:
+		indexer.next = indexer.options.StartBlock
+	default:
+		indexer.next = confirmed
+	}
+	indexer.resumed = true
+	log.Info().Msgf("indexing chain events from block %d", indexer.next)
+	return nil
+}
+
+func (indexer *chainIndexer) index(ctx context.Context) error {
+	confirmed, err := indexer.web3SDK.ConfirmedBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if !indexer.resumed {
+		if err := indexer.resume(confirmed); err != nil {
+			return err
+		}
+	}
+	for indexer.next <= confirmed && ctx.Err() == nil {
+		end := min(indexer.next+indexerBlockRange-1, confirmed)
+		events, err := indexer.web3SDK.ChainEvents(ctx, indexer.next, end)
+		if err != nil {
+			return err
+		}
+		if err := indexer.store.AddChainEvents(events); err != nil {
+			return fmt.Errorf("error storing chain events from block %d: %w", indexer.next, err)
+		}
+		if len(events) > 0 {
+			log.Debug().Msgf("indexed %d chain events from blocks %d to %d", len(events), indexer.next, end)
+		}
+		indexer.next = end + 1
+	}
+	return nil
+}
+
+func (solverServer *solverServer) getChainEvents(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.ChainEvent, error) {
+	query := store.GetChainEventsQuery{
+		DealID:   req.URL.Query().Get("deal_id"),
+		Address:  strings.ToLower(req.URL.Query().Get("address")),
+		Contract: req.URL.Query().Get("contract"),
+		Event:    req.URL.Query().Get("event"),
+	}
+	for name, value := range map[string]*uint64{"from_block": &query.FromBlock, "to_block": &query.ToBlock} {
+		param := req.URL.Query().Get(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return nil, http.HTTPError{
+				Message:    fmt.Sprintf("invalid %s %q, expected a block number", name, param),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+		*value = parsed
+	}
+	page, err := getPageQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	query.Page = page
+	events, err := solverServer.store.GetChainEvents(query)
+	if err != nil {
+		return nil, err
+	}
+	total, err := solverServer.store.CountChainEvents(query)
+	if err != nil {
+		return nil, err
+	}
+	setPageHeaders(res, page, total, events, func(event data.ChainEvent) string { return event.ID })
+	return events, nil
+}
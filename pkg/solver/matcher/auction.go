@@ -0,0 +1,34 @@
+package matcher
+
+import (
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+// the window runs from when we received the job offer, the job creator
+// sets its created at and could keep the auction open or shut it early
+func auctionOpen(jobOffer data.JobOfferContainer, window int, now time.Time) bool {
+	closesAt := jobOffer.Received().Add(time.Duration(window) * time.Second)
+	return now.Before(closesAt)
+}
+
+// record every bid we collected for the job offer
+// the bids must already be sorted so the first one is the winner
+func recordAuctionBids(db store.SolverStore, jobOffer data.JobOfferContainer, rankedOffers []data.ResourceOffer) error {
+	for rank, resourceOffer := range rankedOffers {
+		_, err := db.AddAuctionBid(data.AuctionBid{
+			JobOffer:         jobOffer.ID,
+			ResourceOffer:    resourceOffer.ID,
+			ResourceProvider: resourceOffer.ResourceProvider,
+			InstructionPrice: instructionPrice(resourceOffer, jobOffer.JobOffer),
+			Rank:             rank,
+			Winner:           rank == 0,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package matcher
+
+import (
+	"sort"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+type FairnessPolicy string
+
+const (
+	// job offers are matched in the order we received them
+	FirstComeFirstServed FairnessPolicy = "fifo"
+	// job creators take turns so one creator flooding offers can't starve everyone else
+	RoundRobin FairnessPolicy = "round_robin"
+)
+
+// order the job offers so the matcher visits them according to the fairness policy
+// when there are more job offers than resource offers this decides who gets matched first
+func orderJobOffers(jobOffers []data.JobOfferContainer, policy FairnessPolicy) []data.JobOfferContainer {
+	ordered := append([]data.JobOfferContainer{}, jobOffers...)
+	// created at is up to the job creator so backdating it would jump the queue
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Received().Before(ordered[j].Received())
+	})
+
+	if policy != RoundRobin {
+		return ordered
+	}
+
+	// group by job creator keeping each creator's offers oldest first
+	// creators are visited in the order of their oldest offer
+	creators := []string{}
+	queues := map[string][]data.JobOfferContainer{}
+	for _, jobOffer := range ordered {
+		if _, ok := queues[jobOffer.JobCreator]; !ok {
+			creators = append(creators, jobOffer.JobCreator)
+		}
+		queues[jobOffer.JobCreator] = append(queues[jobOffer.JobCreator], jobOffer)
+	}
+
+	result := make([]data.JobOfferContainer, 0, len(ordered))
+	for len(result) < len(ordered) {
+		for _, creator := range creators {
+			queue := queues[creator]
+			if len(queue) == 0 {
+				continue
+			}
+			result = append(result, queue[0])
+			queues[creator] = queue[1:]
+		}
+	}
+	return result
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/attribute"
@@ -125,6 +126,41 @@ func (result diskSpaceMismatch) attributes() []attribute.KeyValue {
 	}
 }
 
+type attributeMismatch struct {
+	resourceOffer data.ResourceOffer
+	jobOffer      data.JobOffer
+	requirement   data.AttributeRequirement
+}
+
+func (_ attributeMismatch) matched() bool   { return false }
+func (_ attributeMismatch) message() string { return "did not match attribute requirement" }
+func (result attributeMismatch) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("match_result", fmt.Sprintf("%T", result)),
+		attribute.Bool("match_result.matched", result.matched()),
+		attribute.String("match_result.message", result.message()),
+		attribute.String("match_result.requirement", result.requirement.String()),
+		attribute.String("match_result.resource_offer.attribute", result.resourceOffer.Attributes[result.requirement.Key]),
+	}
+}
+
+type regionMismatch struct {
+	resourceOffer data.ResourceOffer
+	jobOffer      data.JobOffer
+}
+
+func (_ regionMismatch) matched() bool   { return false }
+func (_ regionMismatch) message() string { return "resource offer is not in a required region" }
+func (result regionMismatch) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("match_result", fmt.Sprintf("%T", result)),
+		attribute.Bool("match_result.matched", result.matched()),
+		attribute.String("match_result.message", result.message()),
+		attribute.StringSlice("match_result.job_offer.locality.regions", result.jobOffer.Locality.Regions),
+		attribute.String("match_result.resource_offer.region", result.resourceOffer.Region),
+	}
+}
+
 type moduleIDError struct {
 	resourceOffer data.ResourceOffer
 	jobOffer      data.JobOffer
@@ -148,6 +184,8 @@ type moduleMismatch struct {
 	resourceOffer data.ResourceOffer
 	jobOffer      data.JobOffer
 	moduleID      string
+	// the module is allowed but the resource provider has denied it
+	denied bool
 }
 
 func (_ moduleMismatch) matched() bool   { return false }
@@ -159,6 +197,8 @@ func (result moduleMismatch) attributes() []attribute.KeyValue {
 		attribute.String("match_result.message", result.message()),
 		attribute.String("match_result.module_id", result.moduleID),
 		attribute.StringSlice("match_result.resource_offer.modules", result.resourceOffer.Modules),
+		attribute.StringSlice("match_result.resource_offer.denied_modules", result.resourceOffer.DeniedModules),
+		attribute.Bool("match_result.module_denied", result.denied),
 		attribute.String("match_result.job_offer.module.repo", result.jobOffer.Module.Repo),
 		attribute.String("match_result.job_offer.module.hash", result.jobOffer.Module.Hash),
 	}
@@ -202,6 +242,7 @@ func (result priceMismatch) attributes() []attribute.KeyValue {
 		attribute.Int("match_result.job_offer.pricing.instruction_price", int(result.jobOffer.Pricing.InstructionPrice)),
 		attribute.Int("match_result.resource_offer.module_pricing.instruction_price", int(moduleInstructionPrice)),
 		attribute.Int("match_result.resource_offer.default_pricing.instruction_price", int(result.resourceOffer.DefaultPricing.InstructionPrice)),
+		attribute.Int("match_result.resource_offer.instruction_price", int(instructionPrice(result.resourceOffer, result.jobOffer))),
 		attribute.String("match_result.job_offer.mode", string(result.jobOffer.Mode)),
 		attribute.String("match_result.resource_offer.mode", string(result.resourceOffer.Mode)),
 		attribute.String("match_result.module_id", result.moduleID),
@@ -211,6 +252,82 @@ func (result priceMismatch) attributes() []attribute.KeyValue {
 	}
 }
 
+type priceOutOfBounds struct {
+	resourceOffer data.ResourceOffer
+	jobOffer      data.JobOffer
+	err           error
+}
+
+func (_ priceOutOfBounds) matched() bool { return false }
+func (_ priceOutOfBounds) message() string {
+	return "deal price is outside the solver's bounds"
+}
+func (result priceOutOfBounds) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("match_result", fmt.Sprintf("%T", result)),
+		attribute.Bool("match_result.matched", result.matched()),
+		attribute.String("match_result.message", result.message()),
+		attribute.String("match_result.error", result.err.Error()),
+		attribute.Int("match_result.resource_offer.instruction_price", int(instructionPrice(result.resourceOffer, result.jobOffer))),
+	}
+}
+
+type paymentTokenMismatch struct {
+	resourceOffer data.ResourceOffer
+	jobOffer      data.JobOffer
+}
+
+func (_ paymentTokenMismatch) matched() bool { return false }
+func (_ paymentTokenMismatch) message() string {
+	return "resource offer is not priced in the job offer's payment token"
+}
+func (result paymentTokenMismatch) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("match_result", fmt.Sprintf("%T", result)),
+		attribute.Bool("match_result.matched", result.matched()),
+		attribute.String("match_result.message", result.message()),
+		attribute.String("match_result.job_offer.payment_token", result.jobOffer.PaymentToken),
+	}
+}
+
+type secretsMismatch struct {
+	resourceOffer data.ResourceOffer
+	jobOffer      data.JobOffer
+}
+
+func (_ secretsMismatch) matched() bool { return false }
+func (_ secretsMismatch) message() string {
+	return "resource offer has no secrets key to encrypt the job's secrets to"
+}
+func (result secretsMismatch) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("match_result", fmt.Sprintf("%T", result)),
+		attribute.Bool("match_result.matched", result.matched()),
+		attribute.String("match_result.message", result.message()),
+		attribute.StringSlice("match_result.job_offer.secrets", result.jobOffer.Secrets),
+	}
+}
+
+type attestationMismatch struct {
+	resourceOffer data.ResourceOffer
+	jobOffer      data.JobOffer
+	err           error
+}
+
+func (_ attestationMismatch) matched() bool { return false }
+func (_ attestationMismatch) message() string {
+	return "resource offer does not have the attestation the job offer requires"
+}
+func (result attestationMismatch) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("match_result", fmt.Sprintf("%T", result)),
+		attribute.Bool("match_result.matched", result.matched()),
+		attribute.String("match_result.message", result.message()),
+		attribute.StringSlice("match_result.job_offer.attestation.types", result.jobOffer.Attestation.Types),
+		attribute.String("match_result.error", result.err.Error()),
+	}
+}
+
 type mediatorMismatch struct {
 	resourceOffer data.ResourceOffer
 	jobOffer      data.JobOffer
@@ -251,6 +368,7 @@ func (result solverMismatch) attributes() []attribute.KeyValue {
 func matchOffers(
 	resourceOffer data.ResourceOffer,
 	jobOffer data.JobOffer,
+	bounds PriceBounds,
 ) matchResult {
 	if resourceOffer.Spec.CPU < jobOffer.Spec.CPU {
 		return &cpuMismatch{
@@ -299,6 +417,21 @@ func matchOffers(
 		}
 	}
 
+	if requirement, unmet := data.UnmetRequirement(resourceOffer.Attributes, jobOffer.Requirements); unmet {
+		return &attributeMismatch{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
+			requirement:   requirement,
+		}
+	}
+
+	if jobOffer.Locality != nil && jobOffer.Locality.Strict && !inPreferredRegion(resourceOffer, jobOffer) {
+		return &regionMismatch{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
+		}
+	}
+
 	moduleID, err := data.GetModuleID(jobOffer.Module)
 	if err != nil {
 		return &moduleIDError{
@@ -309,21 +442,37 @@ func matchOffers(
 	}
 
 	// if the resource provider has specified modules then check them
-	if len(resourceOffer.Modules) > 0 {
-		// if the resourceOffer.Modules array does not contain the moduleID then we don't match
-		hasModule := false
-		for _, module := range resourceOffer.Modules {
-			if module == moduleID {
-				hasModule = true
-				break
-			}
+	if !data.IsModuleAllowed(resourceOffer.Modules, jobOffer.Module, moduleID) {
+		return &moduleMismatch{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
+			moduleID:      moduleID,
+		}
+	}
+	if data.IsModuleDenied(resourceOffer.DeniedModules, jobOffer.Module, moduleID) {
+		return &moduleMismatch{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
+			moduleID:      moduleID,
+			denied:        true,
+		}
+	}
+
+	// the secrets can only be sent to a resource provider with a key for them
+	if len(jobOffer.Secrets) > 0 && resourceOffer.SecretsKey == "" {
+		return &secretsMismatch{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
 		}
+	}
 
-		if !hasModule {
-			return &moduleMismatch{
+	// the quote's signatures are checked by the job creator before it agrees
+	if jobOffer.Attestation != nil {
+		if _, err := attestation.Check(resourceOffer, jobOffer.Attestation); err != nil {
+			return &attestationMismatch{
 				jobOffer:      jobOffer,
 				resourceOffer: resourceOffer,
-				moduleID:      moduleID,
+				err:           err,
 			}
 		}
 	}
@@ -335,9 +484,17 @@ func matchOffers(
 		}
 	}
 
+	pricing, ok := resourceOffer.JobPricing(jobOffer)
+	if !ok {
+		return &paymentTokenMismatch{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
+		}
+	}
+
 	// if both are fixed price then we filter out "cannot afford"
 	if resourceOffer.Mode == data.FixedPrice && jobOffer.Mode == data.FixedPrice {
-		if resourceOffer.DefaultPricing.InstructionPrice > jobOffer.Pricing.InstructionPrice {
+		if pricing.InstructionPrice > jobOffer.Pricing.InstructionPrice {
 			return &priceMismatch{
 				jobOffer:      jobOffer,
 				resourceOffer: resourceOffer,
@@ -346,6 +503,14 @@ func matchOffers(
 		}
 	}
 
+	if err := checkPriceBounds(bounds, resourceOffer, jobOffer, moduleID); err != nil {
+		return &priceOutOfBounds{
+			jobOffer:      jobOffer,
+			resourceOffer: resourceOffer,
+			err:           err,
+		}
+	}
+
 	mutualMediators := data.GetMutualServices(resourceOffer.Services.Mediator, jobOffer.Services.Mediator)
 	if len(mutualMediators) == 0 {
 		return &mediatorMismatch{
@@ -367,6 +532,21 @@ func matchOffers(
 	}
 }
 
+// what the resource offer charges per instruction in the job offer's token,
+// with resource pricing this is the price of the job's spec and duration
+func instructionPrice(resourceOffer data.ResourceOffer, jobOffer data.JobOffer) uint64 {
+	pricing, _ := resourceOffer.JobPricing(jobOffer)
+	return pricing.InstructionPrice
+}
+
+// the bounds are in the network's token so deals paid in another are not checked
+func checkPriceBounds(bounds PriceBounds, resourceOffer data.ResourceOffer, jobOffer data.JobOffer, moduleID string) error {
+	if bounds == nil || jobOffer.PaymentToken != "" {
+		return nil
+	}
+	return bounds.CheckDealPrice(jobOffer.Module, moduleID, instructionPrice(resourceOffer, jobOffer))
+}
+
 func getLargestVRAM(gpus []data.GPUSpec) int {
 	largestVRAM := 0
 	for _, gpu := range gpus {
@@ -405,6 +585,18 @@ func logMatch(result matchResult) {
 			Int("resource RAM", r.resourceOffer.Spec.RAM).
 			Int("job RAM", r.jobOffer.Spec.RAM).
 			Msg(r.message())
+	case attributeMismatch:
+		log.Trace().
+			Str("resource offer", r.resourceOffer.ID).
+			Str("job offer", r.jobOffer.ID).
+			Str("requirement", r.requirement.String()).
+			Msg(r.message())
+	case regionMismatch:
+		log.Trace().
+			Str("resource offer", r.resourceOffer.ID).
+			Str("job offer", r.jobOffer.ID).
+			Str("region", r.resourceOffer.Region).
+			Msg(r.message())
 	case moduleIDError:
 		log.Error().
 			Str("resource offer", r.resourceOffer.ID).
@@ -3,8 +3,9 @@ package matcher
 import (
 	"context"
 	"errors"
-	"sort"
+	"time"
 
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/solver/store"
 	"github.com/lilypad-tech/lilypad/pkg/system"
@@ -15,17 +16,48 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-type ListOfResourceOffers []data.ResourceOffer
+type MatchMode string
 
-func (a ListOfResourceOffers) Len() int { return len(a) }
-func (a ListOfResourceOffers) Less(i, j int) bool {
-	return a[i].DefaultPricing.InstructionPrice < a[j].DefaultPricing.InstructionPrice
+const (
+	// match a job offer with the cheapest resource offer as soon as we see it
+	ImmediateMatch MatchMode = "immediate"
+	// collect matching resource offers for a window and then pick a winner
+	AuctionMatch MatchMode = "auction"
+)
+
+type MatcherOptions struct {
+	Mode MatchMode
+	// how long in seconds an auction collects bids for a job offer
+	AuctionWindow int
+	// the order job creators are served in when offers exceed capacity
+	FairnessPolicy FairnessPolicy
+	// how the mediator for a deal is chosen from the mutually trusted mediators
+	MediatorPolicy MediatorPolicy
+	// the fee each mediator charges, used by the cheapest mediator policy
+	MediatorFees map[string]int64
+	// the attributes resource offers can describe themselves with
+	// mapped onto the type of value each one holds
+	AttributeSchema map[string]string
+	// the largest percentage of concurrent deals one resource provider can hold, zero for no cap
+	MaxProviderShare int
+	// the percentage an out of region resource offer's price is marked up by when ranking
+	RegionPenalty int
+	// set by the solver from its pricing options, nil for no bounds
+	PriceBounds PriceBounds
+}
+
+// the instruction prices the solver will broker deals at, checked against
+// the price a deal would actually be made at so the resource pricing and
+// the module configs we only learn from the job offer are covered
+type PriceBounds interface {
+	CheckDealPrice(module data.ModuleConfig, moduleID string, price uint64) error
 }
-func (a ListOfResourceOffers) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
 func GetMatchingDeals(
 	ctx context.Context,
 	db store.SolverStore,
+	options MatcherOptions,
+	mediators MediatorSelector,
 	updateJobOfferState func(string, string, uint8) (*data.JobOfferContainer, error),
 	tracer trace.Tracer,
 	meter metric.Meter,
@@ -52,6 +84,17 @@ func GetMatchingDeals(
 	})
 	span.AddEvent("db.get_resource_offers.done")
 
+	// packing offers that already have deals stay in the pool while they have capacity
+	span.AddEvent("db.get_packing_capacity.start")
+	partialResourceOffers, remainingCapacity, err := loadPackingCapacity(db, resourceOffers)
+	if err != nil {
+		span.SetStatus(codes.Error, "get packing capacity failed")
+		span.RecordError(err)
+		return nil, err
+	}
+	resourceOffers = append(resourceOffers, partialResourceOffers...)
+	span.AddEvent("db.get_packing_capacity.done")
+
 	// Get job offers
 	span.AddEvent("db.get_job_offers.start")
 	jobOffers, err := db.GetJobOffers(store.GetJobOffersQuery{
@@ -68,12 +111,51 @@ func GetMatchingDeals(
 	})
 	span.AddEvent("db.get_resource_offers.done")
 
+	now := time.Now()
+
+	// turn scheduled matches whose window has opened into deals
+	span.AddEvent("reconcile_scheduled_matches.start")
+	scheduledDeals, reserved, err := reconcileScheduledMatches(db, mediators, now)
+	if err != nil {
+		span.SetStatus(codes.Error, "reconcile scheduled matches failed")
+		span.RecordError(err)
+		return nil, err
+	}
+	deals = append(deals, scheduledDeals...)
+	span.AddEvent("reconcile_scheduled_matches.done")
+
+	// count the concurrent deals each resource provider already has
+	span.AddEvent("db.get_provider_spread.start")
+	spread, err := loadProviderSpread(db, options.MaxProviderShare, resourceOffers)
+	if err != nil {
+		span.SetStatus(codes.Error, "get provider spread failed")
+		span.RecordError(err)
+		return nil, err
+	}
+	for _, deal := range scheduledDeals {
+		spread.add(deal.ResourceOffer.ResourceProvider)
+	}
+	span.AddEvent("db.get_provider_spread.done")
+
+	// resource offers that have already been given a deal this round
+	// or are being held for a scheduled job offer
+	usedResourceOffers := reserved.resourceOffers
+
 	// loop over job offers
-	for _, jobOffer := range jobOffers {
+	for _, jobOffer := range orderJobOffers(jobOffers, options.FairnessPolicy) {
+		// this job offer is waiting for a resource offer's window to open
+		if reserved.jobOffers[jobOffer.ID] {
+			continue
+		}
+
+		// a peer solver is matching this job offer for us
+		if jobOffer.ForwardedTo != "" {
+			continue
+		}
 
 		// Check for targeted jobs
 		if jobOffer.JobOffer.Target.Address != "" {
-			deal, err := getTargetedDeal(ctx, db, jobOffer, updateJobOfferState, tracer)
+			deal, err := getTargetedDeal(ctx, db, jobOffer, options.PriceBounds, mediators, updateJobOfferState, tracer)
 			if err != nil {
 				return nil, err
 			}
@@ -86,7 +168,14 @@ func GetMatchingDeals(
 
 		// loop over resource offers
 		matchingResourceOffers := []data.ResourceOffer{}
+		// matching resource offers that are outside their availability windows
+		deferredResourceOffers := []data.ResourceOffer{}
 		for _, resourceOffer := range resourceOffers {
+			// we leave the pair undecided so it can be tried again next round
+			if excludedResourceOffer(resourceOffer, usedResourceOffers, spread) != "" {
+				continue
+			}
+
 			_, matchSpan := tracer.Start(ctx, "match",
 				trace.WithAttributes(attribute.String("job_offer.id", jobOffer.ID),
 					attribute.String("resource_offer.id", resourceOffer.ID)),
@@ -110,11 +199,30 @@ func GetMatchingDeals(
 			}
 
 			matchSpan.AddEvent("match_offers.start")
-			result := matchOffers(resourceOffer.ResourceOffer, jobOffer.JobOffer)
+			candidate := resourceOffer.ResourceOffer
+			spec, packing := remainingCapacity[resourceOffer.ID]
+			if packing {
+				candidate.Spec = spec
+			}
+			result := matchOffers(candidate, jobOffer.JobOffer, options.PriceBounds)
 			logMatch(result)
 			matchSpan.AddEvent("match_offers.done", trace.WithAttributes(result.attributes()...))
 
-			if result.matched() {
+			// the job would fit once other deals on this offer finish
+			// so we leave the pair undecided rather than recording a mismatch
+			if packingExhausted(resourceOffer, result, jobOffer.JobOffer, remainingCapacity, options.PriceBounds) {
+				matchSpan.AddEvent("packing_capacity_exhausted")
+				matchSpan.End()
+				continue
+			}
+
+			if result.matched() && !data.IsAvailable(resourceOffer.ResourceOffer.Availability, now) {
+				// the offer could run the job later so we leave the pair undecided
+				if jobOffer.JobOffer.MaxDeferral > 0 {
+					deferredResourceOffers = append(deferredResourceOffers, resourceOffer.ResourceOffer)
+				}
+				matchSpan.AddEvent("resource_offer_unavailable")
+			} else if result.matched() {
 				matchingResourceOffers = append(matchingResourceOffers, resourceOffer.ResourceOffer)
 				matchSpan.AddEvent("append_match",
 					trace.WithAttributes(attribute.KeyValue{
@@ -123,7 +231,7 @@ func GetMatchingDeals(
 					}))
 			} else {
 				matchSpan.AddEvent("add_match_decision.start")
-				_, err := db.AddMatchDecision(resourceOffer.ID, jobOffer.ID, "", false)
+				_, err := db.AddMatchDecision(resourceOffer.ID, jobOffer.ID, "", false, describeMatch(result))
 				if err != nil {
 					matchSpan.SetStatus(codes.Error, "unable to record mismatch decision")
 					matchSpan.RecordError(err)
@@ -135,19 +243,57 @@ func GetMatchingDeals(
 			matchSpan.End()
 		}
 
+		// nothing can run the job now so see if it can wait for a future window
+		if len(matchingResourceOffers) == 0 && len(deferredResourceOffers) > 0 {
+			span.AddEvent("schedule_deferred_match.start")
+			scheduled, err := scheduleDeferredMatch(db, jobOffer, deferredResourceOffers, now)
+			if err != nil {
+				span.SetStatus(codes.Error, "unable to schedule deferred match")
+				span.RecordError(err)
+				return nil, err
+			}
+			if scheduled != nil {
+				usedResourceOffers[scheduled.ResourceOffer] = true
+				span.AddEvent("schedule_deferred_match.done", trace.WithAttributes(
+					attribute.String("resource_offer.id", scheduled.ResourceOffer),
+					attribute.Int64("scheduled_match.starts_at", scheduled.StartsAt),
+				))
+			}
+			continue
+		}
+
+		// in auction mode we leave the matching offers undecided
+		// so they are collected again until the bid window closes
+		if options.Mode == AuctionMatch && auctionOpen(jobOffer, options.AuctionWindow, now) {
+			span.AddEvent("auction_open", trace.WithAttributes(
+				attribute.String("job_offer.id", jobOffer.ID),
+				attribute.Int("auction.bids", len(matchingResourceOffers)),
+			))
+			continue
+		}
+
 		// yay - we've got some matching resource offers
 		// let's choose the cheapest one
 		if len(matchingResourceOffers) > 0 {
 			// now let's order the matching resource offers by price
-			sort.Sort(ListOfResourceOffers(matchingResourceOffers))
+			// taking the job offer's region preference into account
+			rankResourceOffers(matchingResourceOffers, jobOffer.JobOffer, options.RegionPenalty)
 			cheapestResourceOffer := matchingResourceOffers[0]
+			if spec, packing := remainingCapacity[cheapestResourceOffer.ID]; packing {
+				spec = subtractSpec(spec, jobOffer.JobOffer.Spec)
+				remainingCapacity[cheapestResourceOffer.ID] = spec
+				usedResourceOffers[cheapestResourceOffer.ID] = !hasCapacity(spec)
+			} else {
+				usedResourceOffers[cheapestResourceOffer.ID] = true
+			}
+			spread.add(cheapestResourceOffer.ResourceProvider)
 
 			span.AddEvent("get_deal.start", trace.WithAttributes(attribute.String("cheapest_resource_offer", cheapestResourceOffer.ID),
 				attribute.KeyValue{
 					Key:   "matching_resource_offers",
 					Value: attribute.StringSliceValue(data.GetResourceOfferIDs(matchingResourceOffers)),
 				}))
-			deal, err := data.GetDeal(jobOffer.JobOffer, cheapestResourceOffer)
+			deal, err := getDeal(jobOffer.JobOffer, cheapestResourceOffer, mediators)
 			if err != nil {
 				span.SetStatus(codes.Error, "unable to get deal")
 				span.RecordError(err)
@@ -159,12 +305,14 @@ func GetMatchingDeals(
 			for _, matchingResourceOffer := range matchingResourceOffers {
 
 				addDealID := ""
+				reason := "matched but a better ranked resource offer was selected"
 				if cheapestResourceOffer.ID == matchingResourceOffer.ID {
 					addDealID = deal.ID
+					reason = "matched and selected as the best ranked resource offer"
 				}
 
 				span.AddEvent("add_match_decision.start")
-				_, err := db.AddMatchDecision(matchingResourceOffer.ID, jobOffer.ID, addDealID, true)
+				_, err := db.AddMatchDecision(matchingResourceOffer.ID, jobOffer.ID, addDealID, true, reason)
 				if err != nil {
 					span.SetStatus(codes.Error, "unable to add match decision")
 					span.RecordError(err)
@@ -173,6 +321,17 @@ func GetMatchingDeals(
 				span.AddEvent("add_match_decision.done")
 			}
 
+			if options.Mode == AuctionMatch {
+				span.AddEvent("add_auction_bids.start")
+				err := recordAuctionBids(db, jobOffer, matchingResourceOffers)
+				if err != nil {
+					span.SetStatus(codes.Error, "unable to add auction bids")
+					span.RecordError(err)
+					return nil, err
+				}
+				span.AddEvent("add_auction_bids.done")
+			}
+
 			deals = append(deals, deal)
 			span.AddEvent("append_deal",
 				trace.WithAttributes(attribute.KeyValue{
@@ -196,12 +355,30 @@ func GetMatchingDeals(
 	return deals, nil
 }
 
+// why a resource offer sits out a job offer's round before it is matched, empty if it does not
+func excludedResourceOffer(resourceOffer data.ResourceOfferContainer, used map[string]bool, spread *providerSpread) string {
+	if used[resourceOffer.ID] {
+		return "resource offer is already taken this round or held for a scheduled job offer"
+	}
+	if !spread.allows(resourceOffer.ResourceProvider) {
+		return "resource provider already has its share of the concurrent deals"
+	}
+	return ""
+}
+
+func packingExhausted(resourceOffer data.ResourceOfferContainer, result matchResult, jobOffer data.JobOffer, remainingCapacity map[string]data.MachineSpec, bounds PriceBounds) bool {
+	_, packing := remainingCapacity[resourceOffer.ID]
+	return packing && !result.matched() && matchOffers(resourceOffer.ResourceOffer, jobOffer, bounds).matched()
+}
+
 // See if our jobOffer targets a specific address. If so, we will create a deal automatically
 // with the matcing resourceOffer.
 func getTargetedDeal(
 	ctx context.Context,
 	db store.SolverStore,
 	jobOffer data.JobOfferContainer,
+	bounds PriceBounds,
+	mediators MediatorSelector,
 	updateJobOfferState func(string, string, uint8) (*data.JobOfferContainer, error),
 	tracer trace.Tracer,
 ) (*data.Deal, error) {
@@ -229,8 +406,68 @@ func getTargetedDeal(
 	}
 	span.AddEvent("db.get_resource_offer_by_address.found", trace.WithAttributes(attribute.String("resource_offer.id", resourceOffer.ID)))
 
+	// the targeted resource provider won't run this module, cannot be
+	// sent the job's secrets or is not attested so the deal would be doomed
+	moduleID, err := data.GetModuleID(jobOffer.JobOffer.Module)
+	if err != nil {
+		span.SetStatus(codes.Error, "get module id failed")
+		span.RecordError(err)
+		return nil, err
+	}
+	var result matchResult
+	if !data.IsModuleAllowed(resourceOffer.ResourceOffer.Modules, jobOffer.JobOffer.Module, moduleID) || data.IsModuleDenied(resourceOffer.ResourceOffer.DeniedModules, jobOffer.JobOffer.Module, moduleID) {
+		result = &moduleMismatch{
+			jobOffer:      jobOffer.JobOffer,
+			resourceOffer: resourceOffer.ResourceOffer,
+			moduleID:      moduleID,
+		}
+		span.AddEvent("module_not_allowed", trace.WithAttributes(result.attributes()...))
+	} else if len(jobOffer.JobOffer.Secrets) > 0 && resourceOffer.ResourceOffer.SecretsKey == "" {
+		result = &secretsMismatch{
+			jobOffer:      jobOffer.JobOffer,
+			resourceOffer: resourceOffer.ResourceOffer,
+		}
+		span.AddEvent("secrets_not_supported", trace.WithAttributes(result.attributes()...))
+	} else if jobOffer.JobOffer.Attestation != nil {
+		if _, err := attestation.Check(resourceOffer.ResourceOffer, jobOffer.JobOffer.Attestation); err != nil {
+			result = &attestationMismatch{
+				jobOffer:      jobOffer.JobOffer,
+				resourceOffer: resourceOffer.ResourceOffer,
+				err:           err,
+			}
+			span.AddEvent("attestation_not_met", trace.WithAttributes(result.attributes()...))
+		}
+	}
+	if result == nil {
+		if err := checkPriceBounds(bounds, resourceOffer.ResourceOffer, jobOffer.JobOffer, moduleID); err != nil {
+			result = &priceOutOfBounds{
+				jobOffer:      jobOffer.JobOffer,
+				resourceOffer: resourceOffer.ResourceOffer,
+				err:           err,
+			}
+			span.AddEvent("price_out_of_bounds", trace.WithAttributes(result.attributes()...))
+		}
+	}
+	if result != nil {
+		decision, err := db.GetMatchDecision(resourceOffer.ID, jobOffer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if decision == nil {
+			_, err = db.AddMatchDecision(resourceOffer.ID, jobOffer.ID, "", false, describeMatch(result))
+			if err != nil {
+				span.SetStatus(codes.Error, "unable to record mismatch decision")
+				span.RecordError(err)
+				return nil, err
+			}
+		}
+
+		updateJobOfferState(jobOffer.ID, "", data.GetAgreementStateIndex("JobOfferCancelled"))
+		return nil, nil
+	}
+
 	span.AddEvent("get_deal.start")
-	deal, err := data.GetDeal(jobOffer.JobOffer, resourceOffer.ResourceOffer)
+	deal, err := getDeal(jobOffer.JobOffer, resourceOffer.ResourceOffer, mediators)
 	if err != nil {
 		span.SetStatus(codes.Error, "get deal failed")
 		span.RecordError(err)
@@ -3,9 +3,15 @@
 package matcher
 
 import (
+	"encoding/base64"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/lilypad-tech/lilypad/pkg/attestation"
 	"github.com/lilypad-tech/lilypad/pkg/data"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
 )
 
 func TestMatchOffers(t *testing.T) {
@@ -233,6 +239,79 @@ func TestMatchOffers(t *testing.T) {
 			},
 			shouldMatch: false,
 		},
+		{
+			name: "Resource provider allows every version of a module repo",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Modules = []string{cowsayModuleConfig.Repo}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Module = cowsayModuleConfig
+				return offer
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "Resource provider allows a different version of the module",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Modules = []string{cowsayModuleConfig.Repo + "@v0.0.1"}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Module = cowsayModuleConfig
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Resource provider allows the module's repo by pattern",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Modules = []string{"https://github.com/Lilypad-Tech/*"}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Module = cowsayModuleConfig
+				return offer
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "Resource provider denies the module",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.DeniedModules = []string{cowsayModuleConfig.Repo + "@*"}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Module = cowsayModuleConfig
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Resource provider denies a module it allows",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Modules = []string{cowsayModuleConfig.Repo}
+				offer.DeniedModules = []string{cowsayModuleConfig.Repo}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Module = cowsayModuleConfig
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Resource provider denies a different module",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.DeniedModules = []string{lilysayModuleConfig.Repo}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Module = cowsayModuleConfig
+				return offer
+			},
+			shouldMatch: true,
+		},
 		{
 			name: "Empty mediators",
 			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
@@ -269,6 +348,34 @@ func TestMatchOffers(t *testing.T) {
 			},
 			shouldMatch: true,
 		},
+		{
+			name: "Fixed price - cannot afford the job's resources",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.ResourcePricing = &data.ResourcePricing{GPUHour: 20}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Mode = data.FixedPrice
+				offer.Pricing.InstructionPrice = 15
+				offer.Duration = 1800
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Fixed price - can afford the job's resources",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.ResourcePricing = &data.ResourcePricing{GPUHour: 20}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Mode = data.FixedPrice
+				offer.Pricing.InstructionPrice = 25
+				offer.Duration = 1800
+				return offer
+			},
+			shouldMatch: true,
+		},
 		{
 			name: "Resource provider using unimplemented market pricing",
 			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
@@ -302,6 +409,130 @@ func TestMatchOffers(t *testing.T) {
 			},
 			shouldMatch: true,
 		},
+		{
+			name: "Resource offer has required attributes",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Attributes = map[string]string{"infiniband": "true", "gpu.count": "4"}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Requirements = []data.AttributeRequirement{
+					{Key: "infiniband", Operator: data.RequireExists},
+					{Key: "gpu.count", Operator: data.RequireGreaterEq, Value: "2"},
+				}
+				return offer
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "Resource offer missing required attribute",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Attributes = map[string]string{"gpu.count": "4"}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Requirements = []data.AttributeRequirement{
+					{Key: "tpu", Operator: data.RequireEqual, Value: "v5e"},
+				}
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Resource offer priced in the job offer's payment token",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.TokenPricing = map[string]data.DealPricing{
+					"0x5FbDB2315678afecb367f032d93F642f64180aa3": {InstructionPrice: 5},
+				}
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.PaymentToken = "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+				return offer
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "Resource offer not priced in the job offer's payment token",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.PaymentToken = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Job offer with secrets and a resource offer with a secrets key",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.SecretsKey = "0x02a1633cafcc01ebfb6d78e39f687a1f0995c62fc95f51ead10a02ee0be551b5dc"
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Secrets = []string{"HF_TOKEN"}
+				return offer
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "Job offer with secrets and a resource offer without a secrets key",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Secrets = []string{"HF_TOKEN"}
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Job offer requiring attestation and an attested resource offer",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Attestation = testSEVSNPAttestation(offer.ResourceProvider, offer.SecretsKey)
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Attestation = &data.AttestationRequirement{Types: []string{attestation.TypeSEVSNP}}
+				return offer
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "Job offer requiring attestation and a resource offer without one",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Attestation = &data.AttestationRequirement{}
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Job offer requiring tdx and a sev-snp resource offer",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Attestation = testSEVSNPAttestation(offer.ResourceProvider, offer.SecretsKey)
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Attestation = &data.AttestationRequirement{Types: []string{attestation.TypeTDX}}
+				return offer
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "Resource offer with another resource provider's attestation",
+			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
+				offer.Attestation = testSEVSNPAttestation("0x0000000000000000000000000000000000000001", offer.SecretsKey)
+				return offer
+			},
+			jobOffer: func(offer data.JobOffer) data.JobOffer {
+				offer.Attestation = &data.AttestationRequirement{}
+				return offer
+			},
+			shouldMatch: false,
+		},
 		{
 			name: "Different solver",
 			resourceOffer: func(offer data.ResourceOffer) data.ResourceOffer {
@@ -317,10 +548,405 @@ func TestMatchOffers(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := matchOffers(tc.resourceOffer(basicResourceOffer), tc.jobOffer(basicJobOffer))
+			result := matchOffers(tc.resourceOffer(basicResourceOffer), tc.jobOffer(basicJobOffer), nil)
 			if result.matched() != tc.shouldMatch {
 				t.Errorf("Expected match to be %v, but got %+v", tc.shouldMatch, result)
 			}
 		})
 	}
 }
+
+// an unsigned sev-snp report, the matcher does not check signatures
+func testSEVSNPAttestation(resourceProvider string, secretsKey string) *data.Attestation {
+	report := make([]byte, 0x4A0)
+	reportData := attestation.ReportData(resourceProvider, secretsKey)
+	copy(report[0x50:], reportData[:])
+	return &data.Attestation{
+		Type:  attestation.TypeSEVSNP,
+		Quote: base64.StdEncoding.EncodeToString(report),
+	}
+}
+
+func TestOrderJobOffers(t *testing.T) {
+	offer := func(id string, creator string, receivedAt int) data.JobOfferContainer {
+		return data.JobOfferContainer{
+			ID:         id,
+			JobCreator: creator,
+			JobOffer:   data.JobOffer{ID: id, JobCreator: creator, CreatedAt: receivedAt},
+			ReceivedAt: int64(receivedAt),
+		}
+	}
+	// dave says his offer is the oldest but it came in last
+	backdated := offer("d1", "dave", 7)
+	backdated.JobOffer.CreatedAt = 0
+
+	// alice floods the solver before bob and carol show up
+	jobOffers := []data.JobOfferContainer{
+		offer("a3", "alice", 3),
+		backdated,
+		offer("b1", "bob", 5),
+		offer("a1", "alice", 1),
+		offer("c1", "carol", 6),
+		offer("a2", "alice", 2),
+		offer("a4", "alice", 4),
+	}
+
+	tests := []struct {
+		name     string
+		policy   FairnessPolicy
+		expected []string
+	}{
+		{
+			name:     "First come first served",
+			policy:   FirstComeFirstServed,
+			expected: []string{"a1", "a2", "a3", "a4", "b1", "c1", "d1"},
+		},
+		{
+			name:     "Round robin",
+			policy:   RoundRobin,
+			expected: []string{"a1", "b1", "c1", "d1", "a2", "a3", "a4"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ordered := orderJobOffers(jobOffers, tc.policy)
+			if len(ordered) != len(tc.expected) {
+				t.Fatalf("expected %d job offers, got %d", len(tc.expected), len(ordered))
+			}
+			for i, id := range tc.expected {
+				if ordered[i].ID != id {
+					t.Errorf("position %d: expected %s, got %s", i, id, ordered[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateMatch(t *testing.T) {
+	db, err := memorystore.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatalf("unexpected error creating store: %v", err)
+	}
+
+	services := data.ServiceConfig{
+		Solver:   "oranges",
+		Mediator: []string{"apples"},
+	}
+	resourceOffer := func(id string, cpu int, price uint64) data.ResourceOfferContainer {
+		return data.ResourceOfferContainer{
+			ID:               id,
+			ResourceProvider: "0x" + id,
+			State:            data.GetDefaultAgreementState(),
+			ResourceOffer: data.ResourceOffer{
+				ID:             id,
+				Spec:           data.MachineSpec{CPU: cpu},
+				Mode:           data.FixedPrice,
+				DefaultPricing: data.DealPricing{InstructionPrice: price},
+				Services:       services,
+			},
+		}
+	}
+	for _, offer := range []data.ResourceOfferContainer{
+		resourceOffer("small", 500, 1),
+		resourceOffer("expensive", 2000, 10),
+		resourceOffer("cheap", 2000, 5),
+	} {
+		if _, err := db.AddResourceOffer(offer); err != nil {
+			t.Fatalf("unexpected error adding resource offer: %v", err)
+		}
+	}
+
+	jobOffer := data.JobOffer{
+		Spec:     data.MachineSpec{CPU: 1000},
+		Mode:     data.MarketPrice,
+		Services: services,
+	}
+	simulation, err := SimulateMatch(db, MatcherOptions{}, jobOffer, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error simulating: %v", err)
+	}
+
+	if simulation.Selected != "cheap" {
+		t.Errorf("expected the cheapest matching offer to be selected, got %q", simulation.Selected)
+	}
+	if len(simulation.Matches) != 2 || simulation.Matches[1].ResourceOffer != "expensive" {
+		t.Errorf("expected two matches ordered by price, got %+v", simulation.Matches)
+	}
+	if len(simulation.Rejections) != 1 || !strings.HasPrefix(simulation.Rejections[0].Reason, "did not match CPU") {
+		t.Errorf("expected the small offer to be rejected on CPU, got %+v", simulation.Rejections)
+	}
+
+	decision, err := db.GetMatchDecision("cheap", "")
+	if err != nil || decision != nil {
+		t.Errorf("expected no match decisions to be recorded, got %+v %v", decision, err)
+	}
+}
+
+func TestDescribeModuleMismatch(t *testing.T) {
+	module := data.ModuleConfig{
+		Name: "cowsay",
+		Repo: "https://github.com/Lilypad-Tech/lilypad-module-cowsay",
+		Hash: "v0.0.4",
+		Path: "/lilypad_module.json.tmpl",
+	}
+	jobOffer := data.JobOffer{Module: module}
+
+	notAllowed := describeMatch(matchOffers(data.ResourceOffer{Modules: []string{"https://github.com/acme/other"}}, jobOffer, nil))
+	if !strings.HasSuffix(notAllowed, "is not in the allowed modules") {
+		t.Errorf("expected the module to be reported as not allowed, got %q", notAllowed)
+	}
+	denied := describeMatch(matchOffers(data.ResourceOffer{Modules: []string{module.Repo}, DeniedModules: []string{module.Repo}}, jobOffer, nil))
+	if !strings.HasSuffix(denied, "is in the denied modules") {
+		t.Errorf("expected the module to be reported as denied, got %q", denied)
+	}
+}
+
+func TestSimulateMatchFollowsTheRound(t *testing.T) {
+	db, err := memorystore.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatalf("unexpected error creating store: %v", err)
+	}
+
+	services := data.ServiceConfig{
+		Solver:   "oranges",
+		Mediator: []string{"apples"},
+	}
+	for _, id := range []string{"held", "busy", "free"} {
+		_, err := db.AddResourceOffer(data.ResourceOfferContainer{
+			ID:               id,
+			ResourceProvider: "0x" + id,
+			State:            data.GetDefaultAgreementState(),
+			ResourceOffer: data.ResourceOffer{
+				ID:               id,
+				ResourceProvider: "0x" + id,
+				Spec:             data.MachineSpec{CPU: 2000},
+				Mode:             data.FixedPrice,
+				DefaultPricing:   data.DealPricing{InstructionPrice: 5},
+				Services:         services,
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error adding resource offer: %v", err)
+		}
+	}
+
+	// held is waiting on a scheduled job offer
+	_, err = db.AddJobOffer(data.JobOfferContainer{ID: "waiting", State: data.GetDefaultAgreementState()})
+	if err != nil {
+		t.Fatalf("unexpected error adding job offer: %v", err)
+	}
+	_, err = db.AddScheduledMatch(data.ScheduledMatch{
+		JobOffer:      "waiting",
+		ResourceOffer: "held",
+		StartsAt:      time.Now().Add(time.Hour).UnixMilli(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error adding scheduled match: %v", err)
+	}
+
+	// busy already runs the only concurrent deal
+	_, err = db.AddDeal(data.DealContainer{
+		ID:               "deal",
+		ResourceProvider: "0xbusy",
+		State:            data.GetAgreementStateIndex("DealAgreed"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error adding deal: %v", err)
+	}
+
+	jobOffer := data.JobOffer{
+		Spec:     data.MachineSpec{CPU: 1000},
+		Mode:     data.MarketPrice,
+		Services: services,
+	}
+	options := MatcherOptions{Mode: AuctionMatch, AuctionWindow: 30, MaxProviderShare: 50}
+	simulation, err := SimulateMatch(db, options, jobOffer, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error simulating: %v", err)
+	}
+
+	if simulation.Selected != "free" || len(simulation.Matches) != 1 {
+		t.Fatalf("expected only the free offer to match, got %+v", simulation)
+	}
+	if !strings.Contains(simulation.Matches[0].Reason, "auction collects bids for 30 seconds") {
+		t.Errorf("expected the auction to be open, got %q", simulation.Matches[0].Reason)
+	}
+	reasons := map[string]string{}
+	for _, rejection := range simulation.Rejections {
+		reasons[rejection.ResourceOffer] = rejection.Reason
+	}
+	if !strings.Contains(reasons["held"], "held for a scheduled job offer") {
+		t.Errorf("expected the held offer to be reserved, got %q", reasons["held"])
+	}
+	if !strings.Contains(reasons["busy"], "share of the concurrent deals") {
+		t.Errorf("expected the busy provider to be capped, got %q", reasons["busy"])
+	}
+
+	// the simulation leaves the scheduled match alone
+	scheduled, err := db.GetScheduledMatches()
+	if err != nil || len(scheduled) != 1 {
+		t.Errorf("expected the scheduled match to be kept, got %+v %v", scheduled, err)
+	}
+}
+
+func TestProviderSpread(t *testing.T) {
+	providers := map[string]bool{"0xa": true, "0xb": true, "0xc": true}
+	spread := &providerSpread{maxShare: 50, deals: map[string]int{}, providers: providers}
+
+	// everyone gets their first deal even though it is all of the deals
+	if !spread.allows("0xa") {
+		t.Fatal("expected the first deal to be allowed")
+	}
+	spread.add("0xa")
+	if spread.allows("0xa") {
+		t.Error("expected a second deal to take the provider over half")
+	}
+	if !spread.allows("0xb") {
+		t.Error("expected another provider to be allowed")
+	}
+	spread.add("0xb")
+	spread.add("0xc")
+	if !spread.allows("0xa") {
+		t.Error("expected the provider to be allowed once the network has grown")
+	}
+
+	uncapped := &providerSpread{deals: map[string]int{"0xa": 10}, total: 10, providers: providers}
+	if !uncapped.allows("0xa") {
+		t.Error("expected no cap when the max share is zero")
+	}
+}
+
+func TestProviderSpreadSmallNetwork(t *testing.T) {
+	// one provider can take every deal when it is the only one there is
+	alone := &providerSpread{maxShare: 50, deals: map[string]int{}, providers: map[string]bool{"0xa": true}}
+	for i := 0; i < 5; i++ {
+		if !alone.allows("0xa") {
+			t.Fatalf("expected the only provider to be allowed deal %d", i+1)
+		}
+		alone.add("0xa")
+	}
+
+	// three providers under a 30% cap cannot cover the whole network
+	few := &providerSpread{maxShare: 30, deals: map[string]int{"0xa": 4}, total: 4, providers: map[string]bool{"0xa": true, "0xb": true, "0xc": true}}
+	if !few.allows("0xa") {
+		t.Error("expected no cap with too few providers to spread over")
+	}
+
+	// with four the share rounds up, 30% of 3 deals is one each
+	few.providers["0xd"] = true
+	few.deals = map[string]int{"0xa": 1, "0xb": 1}
+	few.total = 2
+	if few.allows("0xa") {
+		t.Error("expected the cap once there are enough providers")
+	}
+	if !few.allows("0xc") {
+		t.Error("expected a provider without a deal to be allowed")
+	}
+}
+
+func TestAuctionOpen(t *testing.T) {
+	now := time.Now()
+	// a created at far in the future does not hold the auction open
+	jobOffer := data.JobOfferContainer{
+		JobOffer:   data.JobOffer{CreatedAt: int(now.Add(time.Hour).UnixMilli())},
+		ReceivedAt: now.Add(-10 * time.Second).UnixMilli(),
+	}
+	if auctionOpen(jobOffer, 5, now) {
+		t.Error("expected the window to run from when the offer was received")
+	}
+	jobOffer.ReceivedAt = now.Add(-2 * time.Second).UnixMilli()
+	if !auctionOpen(jobOffer, 5, now) {
+		t.Error("expected the auction to be open within the window")
+	}
+}
+
+func TestRankResourceOffers(t *testing.T) {
+	offer := func(id string, region string, price uint64) data.ResourceOffer {
+		return data.ResourceOffer{
+			ID:             id,
+			Region:         region,
+			DefaultPricing: data.DealPricing{InstructionPrice: price},
+		}
+	}
+	jobOffer := data.JobOffer{
+		Locality: &data.LocalityPreference{Regions: []string{"eu-west"}},
+	}
+
+	testCases := []struct {
+		name     string
+		offers   []data.ResourceOffer
+		penalty  int
+		expected string
+	}{
+		{
+			name:     "In region offer beats a slightly cheaper one elsewhere",
+			offers:   []data.ResourceOffer{offer("far", "us-east", 90), offer("near", "eu-west", 100)},
+			penalty:  20,
+			expected: "near",
+		},
+		{
+			name:     "Out of region offer wins when it is cheaper even with the penalty",
+			offers:   []data.ResourceOffer{offer("far", "us-east", 50), offer("near", "eu-west", 100)},
+			penalty:  20,
+			expected: "far",
+		},
+		{
+			name:     "No penalty still prefers in region on a tie",
+			offers:   []data.ResourceOffer{offer("far", "us-east", 100), offer("near", "EU-WEST", 100)},
+			penalty:  0,
+			expected: "near",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rankResourceOffers(tc.offers, jobOffer, tc.penalty)
+			if tc.offers[0].ID != tc.expected {
+				t.Errorf("Expected %s to be ranked first, got %s", tc.expected, tc.offers[0].ID)
+			}
+		})
+	}
+}
+
+type maxPriceBounds uint64
+
+func (max maxPriceBounds) CheckDealPrice(module data.ModuleConfig, moduleID string, price uint64) error {
+	if price > uint64(max) {
+		return fmt.Errorf("instruction price %d is above the solver maximum of %d", price, max)
+	}
+	return nil
+}
+
+func TestMatchOffersPriceBounds(t *testing.T) {
+	services := data.ServiceConfig{Solver: "oranges", Mediator: []string{"apples"}}
+	resourceOffer := data.ResourceOffer{
+		Spec:            data.MachineSpec{CPU: 1000, GPU: 1000, RAM: 1024},
+		DefaultPricing:  data.DealPricing{InstructionPrice: 10},
+		ResourcePricing: &data.ResourcePricing{GPUHour: 20},
+		Mode:            data.FixedPrice,
+		Services:        services,
+	}
+	jobOffer := data.JobOffer{
+		Spec:     data.MachineSpec{CPU: 1000, GPU: 1000, RAM: 1024},
+		Mode:     data.MarketPrice,
+		Duration: 1800,
+		Services: services,
+	}
+
+	// the resource priced deal costs more than the default price the
+	// resource offer was checked at when it was posted
+	price := instructionPrice(resourceOffer, jobOffer)
+	if price <= resourceOffer.DefaultPricing.InstructionPrice {
+		t.Fatalf("expected resource pricing above the default price, got %d", price)
+	}
+
+	result := matchOffers(resourceOffer, jobOffer, maxPriceBounds(price-1))
+	if _, ok := result.(*priceOutOfBounds); !ok {
+		t.Errorf("expected the price to be out of bounds, got %+v", result)
+	}
+	if result := matchOffers(resourceOffer, jobOffer, maxPriceBounds(price)); !result.matched() {
+		t.Errorf("expected a match within bounds, got %+v", result)
+	}
+
+}
@@ -0,0 +1,171 @@
+package matcher
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+type MediatorPolicy string
+
+const (
+	// put every mutually trusted mediator on the deal and let the contract choose
+	AllMediators       MediatorPolicy = "all"
+	RandomMediator     MediatorPolicy = "random"
+	RoundRobinMediator MediatorPolicy = "round_robin"
+	// weight the choice by how often each mediator has finished a mediation
+	ReputationMediator MediatorPolicy = "reputation"
+	// choose the mediator with the lowest configured fee
+	CheapestMediator MediatorPolicy = "cheapest"
+)
+
+// chooses which of the mutually trusted mediators a deal will use
+type MediatorSelector interface {
+	SelectMediator(deal data.Deal, candidates []string) (string, error)
+}
+
+type MediatorSelectorFactory func(options MatcherOptions, db store.SolverStore) MediatorSelector
+
+var mediatorSelectors = map[MediatorPolicy]MediatorSelectorFactory{}
+
+// add a mediator policy that can be chosen with the matcher options
+func RegisterMediatorSelector(policy MediatorPolicy, factory MediatorSelectorFactory) {
+	mediatorSelectors[policy] = factory
+}
+
+func IsMediatorPolicy(policy MediatorPolicy) bool {
+	_, ok := mediatorSelectors[policy]
+	return policy == AllMediators || ok
+}
+
+// returns nil for the all policy which leaves the deal mediators as they are
+func NewMediatorSelector(options MatcherOptions, db store.SolverStore) (MediatorSelector, error) {
+	if options.MediatorPolicy == AllMediators || options.MediatorPolicy == "" {
+		return nil, nil
+	}
+	factory, ok := mediatorSelectors[options.MediatorPolicy]
+	if !ok {
+		return nil, fmt.Errorf("unknown mediator policy: %s", options.MediatorPolicy)
+	}
+	return factory(options, db), nil
+}
+
+func init() {
+	RegisterMediatorSelector(RandomMediator, func(_ MatcherOptions, _ store.SolverStore) MediatorSelector {
+		return randomMediatorSelector{}
+	})
+	RegisterMediatorSelector(RoundRobinMediator, func(_ MatcherOptions, _ store.SolverStore) MediatorSelector {
+		return &roundRobinMediatorSelector{}
+	})
+	RegisterMediatorSelector(ReputationMediator, func(_ MatcherOptions, db store.SolverStore) MediatorSelector {
+		return reputationMediatorSelector{db: db}
+	})
+	RegisterMediatorSelector(CheapestMediator, func(options MatcherOptions, _ store.SolverStore) MediatorSelector {
+		return cheapestMediatorSelector{fees: options.MediatorFees}
+	})
+}
+
+// make a deal for the offers using the selector to narrow down the mediators
+func getDeal(jobOffer data.JobOffer, resourceOffer data.ResourceOffer, selector MediatorSelector) (data.Deal, error) {
+	deal, err := data.GetDeal(jobOffer, resourceOffer)
+	if err != nil || selector == nil {
+		return deal, err
+	}
+
+	mediator, err := selector.SelectMediator(deal, deal.Members.Mediators)
+	if err != nil {
+		return data.Deal{}, err
+	}
+	deal.Members.Mediators = []string{mediator}
+
+	// the mediators are part of the deal so we need a new ID
+	deal.ID, err = data.GetDealID(deal)
+	if err != nil {
+		return data.Deal{}, err
+	}
+	return deal, nil
+}
+
+type randomMediatorSelector struct{}
+
+func (randomMediatorSelector) SelectMediator(_ data.Deal, candidates []string) (string, error) {
+	return candidates[rand.Intn(len(candidates))], nil
+}
+
+type roundRobinMediatorSelector struct {
+	mtx  sync.Mutex
+	next int
+}
+
+func (selector *roundRobinMediatorSelector) SelectMediator(_ data.Deal, candidates []string) (string, error) {
+	selector.mtx.Lock()
+	defer selector.mtx.Unlock()
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+	mediator := sorted[selector.next%len(sorted)]
+	selector.next++
+	return mediator, nil
+}
+
+type reputationMediatorSelector struct {
+	db store.SolverStore
+}
+
+// mediators that have finished mediations are more likely to be chosen
+// and ones that have let mediations time out are less likely
+func (selector reputationMediatorSelector) SelectMediator(_ data.Deal, candidates []string) (string, error) {
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, mediator := range candidates {
+		deals, err := selector.db.GetDeals(store.GetDealsQuery{Mediator: mediator})
+		if err != nil {
+			return "", err
+		}
+		completed, timedOut := 0.0, 0.0
+		for _, deal := range deals {
+			switch deal.State {
+			case data.GetAgreementStateIndex("MediationAccepted"), data.GetAgreementStateIndex("MediationRejected"):
+				completed++
+			case data.GetAgreementStateIndex("TimeoutMediateResults"):
+				timedOut++
+			}
+		}
+		// start everyone at one half so new mediators still get picked
+		weights[i] = (completed + 1) / (completed + timedOut + 2)
+		total += weights[i]
+	}
+
+	pick := rand.Float64() * total
+	for i, weight := range weights {
+		pick -= weight
+		if pick < 0 {
+			return candidates[i], nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+type cheapestMediatorSelector struct {
+	fees map[string]int64
+}
+
+// mediators without a configured fee are treated as the most expensive
+func (selector cheapestMediatorSelector) SelectMediator(_ data.Deal, candidates []string) (string, error) {
+	sorted := append([]string{}, candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		feeI, okI := selector.fees[sorted[i]]
+		feeJ, okJ := selector.fees[sorted[j]]
+		if okI != okJ {
+			return okI
+		}
+		if feeI == feeJ {
+			return sorted[i] < sorted[j]
+		}
+		return feeI < feeJ
+	})
+	return sorted[0], nil
+}
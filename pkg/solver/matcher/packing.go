@@ -0,0 +1,72 @@
+package matcher
+
+import (
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+// load the packing resource offers that already have deals on them
+// along with how much capacity every packing offer has left
+func loadPackingCapacity(
+	db store.SolverStore,
+	unmatched []data.ResourceOfferContainer,
+) ([]data.ResourceOfferContainer, map[string]data.MachineSpec, error) {
+	remaining := map[string]data.MachineSpec{}
+	for _, resourceOffer := range unmatched {
+		if resourceOffer.ResourceOffer.Packing {
+			remaining[resourceOffer.ID] = resourceOffer.ResourceOffer.Spec
+		}
+	}
+
+	active, err := db.GetResourceOffers(store.GetResourceOffersQuery{
+		Active: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	partial := []data.ResourceOfferContainer{}
+	dealsByProvider := map[string][]data.DealContainer{}
+	for _, resourceOffer := range active {
+		if !resourceOffer.ResourceOffer.Packing || resourceOffer.DealID == "" {
+			continue
+		}
+
+		deals, ok := dealsByProvider[resourceOffer.ResourceProvider]
+		if !ok {
+			deals, err = db.GetDeals(store.GetDealsQuery{
+				ResourceProvider: resourceOffer.ResourceProvider,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			dealsByProvider[resourceOffer.ResourceProvider] = deals
+		}
+
+		spec := resourceOffer.ResourceOffer.Spec
+		for _, deal := range deals {
+			if deal.ResourceOffer == resourceOffer.ID && data.IsActiveAgreementState(deal.State) {
+				spec = subtractSpec(spec, deal.Deal.JobOffer.Spec)
+			}
+		}
+		if !hasCapacity(spec) {
+			continue
+		}
+
+		remaining[resourceOffer.ID] = spec
+		partial = append(partial, resourceOffer)
+	}
+
+	return partial, remaining, nil
+}
+
+func subtractSpec(spec data.MachineSpec, used data.MachineSpec) data.MachineSpec {
+	spec.CPU -= used.CPU
+	spec.GPU -= used.GPU
+	spec.RAM -= used.RAM
+	return spec
+}
+
+func hasCapacity(spec data.MachineSpec) bool {
+	return spec.CPU > 0 && spec.RAM > 0
+}
@@ -0,0 +1,57 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// is the resource offer in one of the regions the job offer asked for
+// a job offer without a region preference is happy anywhere
+func inPreferredRegion(resourceOffer data.ResourceOffer, jobOffer data.JobOffer) bool {
+	if jobOffer.Locality == nil || len(jobOffer.Locality.Regions) == 0 {
+		return true
+	}
+	for _, region := range jobOffer.Locality.Regions {
+		if strings.EqualFold(region, resourceOffer.Region) {
+			return true
+		}
+	}
+	return false
+}
+
+// the price we rank a resource offer at for this job offer
+// offers outside the preferred regions are marked up by the penalty percentage
+func rankingPrice(resourceOffer data.ResourceOffer, jobOffer data.JobOffer, penalty int) uint64 {
+	price := instructionPrice(resourceOffer, jobOffer)
+	if inPreferredRegion(resourceOffer, jobOffer) {
+		return price
+	}
+	return price * uint64(100+penalty) / 100
+}
+
+// order the matching resource offers so the first one is the one we pick
+// in region offers win unless an out of region offer is cheaper even with the penalty
+func rankResourceOffers(resourceOffers []data.ResourceOffer, jobOffer data.JobOffer, penalty int) {
+	sort.SliceStable(resourceOffers, func(i, j int) bool {
+		priceI := instructionPrice(resourceOffers[i], jobOffer)
+		priceJ := instructionPrice(resourceOffers[j], jobOffer)
+		if priceI == priceJ {
+			// on a tie the offer that was made first wins
+			return resourceOffers[i].CreatedAt < resourceOffers[j].CreatedAt
+		}
+		return priceI < priceJ
+	})
+	if jobOffer.Locality == nil || len(jobOffer.Locality.Regions) == 0 {
+		return
+	}
+	sort.SliceStable(resourceOffers, func(i, j int) bool {
+		priceI := rankingPrice(resourceOffers[i], jobOffer, penalty)
+		priceJ := rankingPrice(resourceOffers[j], jobOffer, penalty)
+		if priceI == priceJ {
+			return inPreferredRegion(resourceOffers[i], jobOffer) && !inPreferredRegion(resourceOffers[j], jobOffer)
+		}
+		return priceI < priceJ
+	})
+}
@@ -0,0 +1,160 @@
+package matcher
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+type reservations struct {
+	jobOffers      map[string]bool
+	resourceOffers map[string]bool
+}
+
+type scheduledPair struct {
+	match         data.ScheduledMatch
+	jobOffer      *data.JobOfferContainer
+	resourceOffer *data.ResourceOfferContainer
+}
+
+// a scheduled match is stale once either side has gone away or been given another deal
+func (pair scheduledPair) stale() bool {
+	return pair.jobOffer == nil || pair.resourceOffer == nil ||
+		pair.jobOffer.DealID != "" || pair.resourceOffer.DealID != "" ||
+		pair.jobOffer.State == data.GetAgreementStateIndex("JobOfferCancelled")
+}
+
+func loadScheduledPairs(db store.SolverStore) ([]scheduledPair, error) {
+	scheduled, err := db.GetScheduledMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []scheduledPair{}
+	for _, match := range scheduled {
+		jobOffer, err := db.GetJobOffer(match.JobOffer)
+		if err != nil {
+			return nil, err
+		}
+		resourceOffer, err := db.GetResourceOffer(match.ResourceOffer)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, scheduledPair{match, jobOffer, resourceOffer})
+	}
+	return pairs, nil
+}
+
+// the offers held by scheduled matches without touching the store, the
+// resource offers of matches that are due would be dealt this round
+func peekScheduledMatches(db store.SolverStore, now time.Time) (reservations, []string, error) {
+	reserved := reservations{
+		jobOffers:      map[string]bool{},
+		resourceOffers: map[string]bool{},
+	}
+	dueProviders := []string{}
+
+	pairs, err := loadScheduledPairs(db)
+	if err != nil {
+		return reserved, nil, err
+	}
+	for _, pair := range pairs {
+		if pair.stale() {
+			continue
+		}
+		reserved.jobOffers[pair.jobOffer.ID] = true
+		reserved.resourceOffers[pair.resourceOffer.ID] = true
+		if now.UnixMilli() >= pair.match.StartsAt {
+			dueProviders = append(dueProviders, pair.resourceOffer.ResourceProvider)
+		}
+	}
+	return reserved, dueProviders, nil
+}
+
+// turn any scheduled matches whose window has opened into deals
+// matches that are still waiting for their window keep both offers out of this round
+// and matches where either side has gone away are dropped so the job offer can be matched again
+func reconcileScheduledMatches(db store.SolverStore, mediators MediatorSelector, now time.Time) ([]data.Deal, reservations, error) {
+	deals := []data.Deal{}
+	reserved := reservations{
+		jobOffers:      map[string]bool{},
+		resourceOffers: map[string]bool{},
+	}
+
+	pairs, err := loadScheduledPairs(db)
+	if err != nil {
+		return nil, reserved, err
+	}
+
+	for _, pair := range pairs {
+		match, jobOffer, resourceOffer := pair.match, pair.jobOffer, pair.resourceOffer
+		if pair.stale() {
+			err = db.RemoveScheduledMatch(match.JobOffer)
+			if err != nil {
+				return nil, reserved, err
+			}
+			continue
+		}
+
+		reserved.jobOffers[jobOffer.ID] = true
+		reserved.resourceOffers[resourceOffer.ID] = true
+
+		if now.UnixMilli() < match.StartsAt {
+			continue
+		}
+
+		deal, err := getDeal(jobOffer.JobOffer, resourceOffer.ResourceOffer, mediators)
+		if err != nil {
+			return nil, reserved, err
+		}
+		_, err = db.AddMatchDecision(resourceOffer.ID, jobOffer.ID, deal.ID, true, "matched once the resource offer's availability window opened")
+		if err != nil {
+			return nil, reserved, err
+		}
+		err = db.RemoveScheduledMatch(match.JobOffer)
+		if err != nil {
+			return nil, reserved, err
+		}
+		deals = append(deals, deal)
+	}
+
+	return deals, reserved, nil
+}
+
+// reserve the resource offer whose next window opens soonest
+// as long as it opens before the job creator stops waiting
+func scheduleDeferredMatch(db store.SolverStore, jobOffer data.JobOfferContainer, resourceOffers []data.ResourceOffer, now time.Time) (*data.ScheduledMatch, error) {
+	type candidate struct {
+		resourceOffer data.ResourceOffer
+		opens         time.Time
+	}
+
+	latest := now.Add(time.Duration(jobOffer.JobOffer.MaxDeferral) * time.Second)
+	candidates := []candidate{}
+	for _, resourceOffer := range resourceOffers {
+		opens, ok := data.NextAvailability(resourceOffer.Availability, now)
+		if !ok || opens.After(latest) {
+			continue
+		}
+		candidates = append(candidates, candidate{resourceOffer, opens})
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// soonest window first and then cheapest
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].opens.Equal(candidates[j].opens) {
+			return instructionPrice(candidates[i].resourceOffer, jobOffer.JobOffer) < instructionPrice(candidates[j].resourceOffer, jobOffer.JobOffer)
+		}
+		return candidates[i].opens.Before(candidates[j].opens)
+	})
+
+	return db.AddScheduledMatch(data.ScheduledMatch{
+		JobOffer:      jobOffer.ID,
+		ResourceOffer: candidates[0].resourceOffer.ID,
+		StartsAt:      candidates[0].opens.UnixMilli(),
+	})
+}
@@ -0,0 +1,161 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+// work out which of the current resource offers would match the job offer
+// this runs the same checks as GetMatchingDeals, including the offers held
+// by scheduled matches, the provider share cap and the auction window, but
+// does not record decisions or create deals so it is safe to call at any time
+func SimulateMatch(db store.SolverStore, options MatcherOptions, jobOffer data.JobOffer, now time.Time) (data.MatchSimulation, error) {
+	simulation := data.MatchSimulation{
+		JobOffer:   jobOffer,
+		Matches:    []data.SimulatedMatch{},
+		Rejections: []data.SimulatedMatch{},
+	}
+
+	resourceOffers, err := db.GetResourceOffers(store.GetResourceOffersQuery{
+		NotMatched: true,
+	})
+	if err != nil {
+		return simulation, err
+	}
+	partialResourceOffers, remainingCapacity, err := loadPackingCapacity(db, resourceOffers)
+	if err != nil {
+		return simulation, err
+	}
+	resourceOffers = append(resourceOffers, partialResourceOffers...)
+
+	reserved, dueProviders, err := peekScheduledMatches(db, now)
+	if err != nil {
+		return simulation, err
+	}
+	spread, err := loadProviderSpread(db, options.MaxProviderShare, resourceOffers)
+	if err != nil {
+		return simulation, err
+	}
+	for _, resourceProvider := range dueProviders {
+		spread.add(resourceProvider)
+	}
+
+	matchingResourceOffers := []data.ResourceOffer{}
+	for _, resourceOffer := range resourceOffers {
+		simulated := data.SimulatedMatch{
+			ResourceOffer:    resourceOffer.ID,
+			ResourceProvider: resourceOffer.ResourceProvider,
+			InstructionPrice: instructionPrice(resourceOffer.ResourceOffer, jobOffer),
+		}
+
+		if jobOffer.Target.Address != "" && jobOffer.Target.Address != resourceOffer.ResourceProvider {
+			simulated.Reason = "job offer targets a different resource provider"
+			simulation.Rejections = append(simulation.Rejections, simulated)
+			continue
+		}
+
+		if reason := excludedResourceOffer(resourceOffer, reserved.resourceOffers, spread); reason != "" {
+			simulated.Reason = reason
+			simulation.Rejections = append(simulation.Rejections, simulated)
+			continue
+		}
+
+		candidate := resourceOffer.ResourceOffer
+		if spec, packing := remainingCapacity[resourceOffer.ID]; packing {
+			candidate.Spec = spec
+		}
+		result := matchOffers(candidate, jobOffer, options.PriceBounds)
+		if packingExhausted(resourceOffer, result, jobOffer, remainingCapacity, options.PriceBounds) {
+			simulated.Reason = "resource offer is packed full until some of its deals finish"
+			simulation.Rejections = append(simulation.Rejections, simulated)
+			continue
+		}
+		if !result.matched() {
+			simulated.Reason = describeMatch(result)
+			simulation.Rejections = append(simulation.Rejections, simulated)
+			continue
+		}
+
+		if !data.IsAvailable(resourceOffer.ResourceOffer.Availability, now) {
+			next, ok := data.NextAvailability(resourceOffer.ResourceOffer.Availability, now)
+			switch {
+			case !ok:
+				simulated.Reason = "resource offer has no upcoming availability window"
+			case jobOffer.MaxDeferral <= 0:
+				simulated.Reason = fmt.Sprintf("resource offer is not available until %s and the job offer cannot wait", next.UTC().Format(time.RFC3339))
+			default:
+				simulated.Reason = fmt.Sprintf("resource offer is not available until %s", next.UTC().Format(time.RFC3339))
+			}
+			simulation.Rejections = append(simulation.Rejections, simulated)
+			continue
+		}
+
+		matchingResourceOffers = append(matchingResourceOffers, resourceOffer.ResourceOffer)
+	}
+
+	// a job offer posted now would open its auction now
+	auction := options.Mode == AuctionMatch && auctionOpen(data.JobOfferContainer{ReceivedAt: now.UnixMilli()}, options.AuctionWindow, now)
+
+	rankResourceOffers(matchingResourceOffers, jobOffer, options.RegionPenalty)
+	for i, resourceOffer := range matchingResourceOffers {
+		reason := "offers matched"
+		if i == 0 {
+			reason = "offers matched and this is the best ranked resource offer"
+			if auction {
+				reason = fmt.Sprintf("offers matched and this is the best ranked bid so far, the auction collects bids for %d seconds", options.AuctionWindow)
+			}
+			simulation.Selected = resourceOffer.ID
+		}
+		simulation.Matches = append(simulation.Matches, data.SimulatedMatch{
+			ResourceOffer:    resourceOffer.ID,
+			ResourceProvider: resourceOffer.ResourceProvider,
+			InstructionPrice: instructionPrice(resourceOffer, jobOffer),
+			Matched:          true,
+			Reason:           reason,
+		})
+	}
+
+	return simulation, nil
+}
+
+// a human readable reason for the match result including the values that were compared
+func describeMatch(result matchResult) string {
+	switch r := result.(type) {
+	case *cpuMismatch:
+		return fmt.Sprintf("%s: job offer needs %d, resource offer has %d", r.message(), r.jobOffer.Spec.CPU, r.resourceOffer.Spec.CPU)
+	case *gpuMismatch:
+		return fmt.Sprintf("%s: job offer needs %d, resource offer has %d", r.message(), r.jobOffer.Spec.GPU, r.resourceOffer.Spec.GPU)
+	case *ramMismatch:
+		return fmt.Sprintf("%s: job offer needs %d, resource offer has %d", r.message(), r.jobOffer.Spec.RAM, r.resourceOffer.Spec.RAM)
+	case *vramMismatch:
+		return fmt.Sprintf("%s: job offer needs %d, resource offer has %d", r.message(), getLargestVRAM(r.jobOffer.Spec.GPUs), getLargestVRAM(r.resourceOffer.Spec.GPUs))
+	case *diskSpaceMismatch:
+		return fmt.Sprintf("%s: job offer needs %d, resource offer has %d", r.message(), r.jobOffer.Spec.Disk, r.resourceOffer.Spec.Disk)
+	case *attributeMismatch:
+		return fmt.Sprintf("%s: %s", r.message(), r.requirement)
+	case *regionMismatch:
+		return fmt.Sprintf("%s: job offer wants %s, resource offer is in %q", r.message(), strings.Join(r.jobOffer.Locality.Regions, ", "), r.resourceOffer.Region)
+	case *moduleMismatch:
+		if r.denied {
+			return fmt.Sprintf("%s: %s@%s (%s) is in the denied modules", r.message(), r.jobOffer.Module.Repo, r.jobOffer.Module.Hash, r.moduleID)
+		}
+		return fmt.Sprintf("%s: %s@%s (%s) is not in the allowed modules", r.message(), r.jobOffer.Module.Repo, r.jobOffer.Module.Hash, r.moduleID)
+	case *moduleIDError:
+		return fmt.Sprintf("%s: %s", r.message(), r.err.Error())
+	case *priceMismatch:
+		return fmt.Sprintf("%s: job offer pays %d, resource offer charges %d", r.message(), r.jobOffer.Pricing.InstructionPrice, instructionPrice(r.resourceOffer, r.jobOffer))
+	case *priceOutOfBounds:
+		return fmt.Sprintf("%s: %s", r.message(), r.err.Error())
+	case *paymentTokenMismatch:
+		return fmt.Sprintf("%s: %s", r.message(), r.jobOffer.PaymentToken)
+	case *secretsMismatch:
+		return fmt.Sprintf("%s: %s", r.message(), strings.Join(r.jobOffer.Secrets, ", "))
+	case *attestationMismatch:
+		return fmt.Sprintf("%s: %s", r.message(), r.err.Error())
+	}
+	return result.message()
+}
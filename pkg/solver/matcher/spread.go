@@ -0,0 +1,63 @@
+package matcher
+
+import (
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+)
+
+// keeps track of how many concurrent deals each resource provider has
+// so no single provider ends up running most of the network's work
+type providerSpread struct {
+	// the most any one provider can hold as a percentage of all concurrent deals
+	// zero turns the cap off
+	maxShare int
+	deals    map[string]int
+	total    int
+	// the providers with deals or offers, the work can only be spread
+	// over the ones there are
+	providers map[string]bool
+}
+
+func loadProviderSpread(db store.SolverStore, maxShare int, resourceOffers []data.ResourceOfferContainer) (*providerSpread, error) {
+	spread := &providerSpread{
+		maxShare:  maxShare,
+		deals:     map[string]int{},
+		providers: map[string]bool{},
+	}
+	if maxShare <= 0 {
+		return spread, nil
+	}
+
+	deals, err := db.GetDeals(store.GetDealsQuery{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deal := range deals {
+		if data.IsActiveAgreementState(deal.State) {
+			spread.add(deal.ResourceProvider)
+		}
+	}
+	for _, resourceOffer := range resourceOffers {
+		spread.providers[resourceOffer.ResourceProvider] = true
+	}
+	return spread, nil
+}
+
+// would giving the provider another deal take it over the cap
+//
+// a network with fewer providers than it takes to keep each under the cap
+// is not capped at all, it would only leave jobs waiting, and the share
+// rounds up so every provider can always have a first deal
+func (spread *providerSpread) allows(resourceProvider string) bool {
+	if spread.maxShare <= 0 || len(spread.providers)*spread.maxShare < 100 { //nolint:gomnd
+		return true
+	}
+	allowed := (spread.maxShare*(spread.total+1) + 99) / 100 //nolint:gomnd
+	return spread.deals[resourceProvider] < allowed
+}
+
+func (spread *providerSpread) add(resourceProvider string) {
+	spread.deals[resourceProvider]++
+	spread.total++
+	spread.providers[resourceProvider] = true
+}
@@ -17,6 +17,7 @@ type metrics struct {
 	resultsChecked        metric.Int64Gauge
 	mediationAccepted     metric.Int64Gauge
 	mediationRejected     metric.Int64Gauge
+	timeoutAgree          metric.Int64Gauge
 	timeoutSubmitResults  metric.Int64Gauge
 	timeoutJudgeResults   metric.Int64Gauge
 	timeoutMediateResults metric.Int64Gauge
@@ -36,6 +37,7 @@ type stateCounts struct {
 	resultsChecked        int64
 	mediationAccepted     int64
 	mediationRejected     int64
+	timeoutAgree          int64
 	timeoutSubmitResults  int64
 	timeoutJudgeResults   int64
 	timeoutMediateResults int64
@@ -100,6 +102,13 @@ func newMetrics(meter metric.Meter) (*metrics, error) {
 	if err != nil {
 		return nil, err
 	}
+	timeoutAgree, err := meter.Int64Gauge(
+		"solver.deal.state.timeout_agree",
+		metric.WithDescription("Number of deals in a TimeoutAgree state."),
+	)
+	if err != nil {
+		return nil, err
+	}
 	timeoutSubmitResults, err := meter.Int64Gauge(
 		"solver.deal.state.timeout_submit_results",
 		metric.WithDescription("Number of deals in a TimeoutSubmitResults state."),
@@ -138,6 +147,7 @@ func newMetrics(meter metric.Meter) (*metrics, error) {
 		resultsChecked,
 		mediationAccepted,
 		mediationRejected,
+		timeoutAgree,
 		timeoutSubmitResults,
 		timeoutJudgeResults,
 		timeoutMediateResults,
@@ -167,6 +177,8 @@ func reportDealMetrics(ctx context.Context, meter metric.Meter, deals []data.Dea
 			dealStats.stateCounts.mediationAccepted += 1
 		case "MediationRejected":
 			dealStats.stateCounts.mediationRejected += 1
+		case "TimeoutAgree":
+			dealStats.stateCounts.timeoutAgree += 1
 		case "TimeoutSubmitResults":
 			dealStats.stateCounts.timeoutSubmitResults += 1
 		case "TimeoutJudgeResults":
@@ -194,6 +206,7 @@ func reportDealMetrics(ctx context.Context, meter metric.Meter, deals []data.Dea
 	metrics.resultsChecked.Record(ctx, dealStats.stateCounts.resultsChecked)
 	metrics.mediationAccepted.Record(ctx, dealStats.stateCounts.mediationAccepted)
 	metrics.mediationRejected.Record(ctx, dealStats.stateCounts.mediationRejected)
+	metrics.timeoutAgree.Record(ctx, dealStats.stateCounts.timeoutAgree)
 	metrics.timeoutSubmitResults.Record(ctx, dealStats.stateCounts.timeoutSubmitResults)
 	metrics.timeoutJudgeResults.Record(ctx, dealStats.stateCounts.timeoutJudgeResults)
 	metrics.timeoutMediateResults.Record(ctx, dealStats.stateCounts.timeoutMediateResults)
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// gauges that count what is in the store each time prometheus scrapes
+func (solverServer *solverServer) storeMetrics() []prometheus.Collector {
+	gauge := func(name string, help string, count func() (int64, error)) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "lilypad",
+			Subsystem: "solver_store",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			total, err := count()
+			if err != nil {
+				log.Error().Err(err).Str("metric", name).Msgf("error counting store metric")
+				return 0
+			}
+			return float64(total)
+		})
+	}
+	return []prometheus.Collector{
+		gauge("job_offers", "Job offers in the solver store that are not cancelled.", func() (int64, error) {
+			return solverServer.store.CountJobOffers(store.GetJobOffersQuery{})
+		}),
+		gauge("resource_offers", "Resource offers in the solver store.", func() (int64, error) {
+			return solverServer.store.CountResourceOffers(store.GetResourceOffersQuery{})
+		}),
+		gauge("deals", "Deals in the solver store.", func() (int64, error) {
+			return solverServer.store.CountDeals(store.GetDealsQuery{})
+		}),
+		gauge("results", "Results in the solver store.", func() (int64, error) {
+			return solverServer.store.CountResults()
+		}),
+	}
+}
@@ -0,0 +1,258 @@
+package solver
+
+import (
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+func apiRoute(method string, path string) string {
+	return method + " " + http.API_SUB_PATH + path
+}
+
+// every list endpoint takes these, X-Total-Count and X-Next-Cursor are
+// only set when one of them is given
+var pageParams = []http.APIParam{
+	{Name: "limit", Description: "the most rows to return, all of them when not set"},
+	{Name: "offset", Description: "how many rows to skip"},
+	{Name: "cursor", Description: "the X-Next-Cursor header from the previous page"},
+	{Name: "order", Description: "asc or desc by id"},
+}
+
+func withPageParams(params []http.APIParam) []http.APIParam {
+	return append(params, pageParams...)
+}
+
+// the types each solver route takes and returns for the OpenAPI document
+// a route that is missing here is still listed but without its body types
+var solverAPIOperations = map[string]http.APIOperation{
+	"GET /api/versions": {
+		Summary:  "List the api versions this solver serves",
+		Response: []http.APIVersion{},
+	},
+	apiRoute("GET", "/job_offers"): {
+		Summary:  "List job offers",
+		Response: []data.JobOfferContainer{},
+		Query: withPageParams([]http.APIParam{
+			{Name: "job_creator", Description: "only the job offers from this address"},
+			{Name: "not_matched", Description: "true for job offers that have no deal yet"},
+			{Name: "include_cancelled", Description: "true to include cancelled job offers"},
+			{Name: "series", Description: "only the runs of this recurring job"},
+		}),
+	},
+	apiRoute("POST", "/job_offers"): {
+		Summary:    "Add a job offer",
+		Request:    data.JobOffer{},
+		Response:   data.JobOfferContainer{},
+		Signed:     true,
+		Idempotent: true,
+	},
+	apiRoute("POST", "/job_offers/simulate"): {
+		Summary:  "Show which resource offers a job offer would match without adding it",
+		Request:  data.JobOffer{},
+		Response: data.MatchSimulation{},
+	},
+	apiRoute("POST", "/job_offers/estimate"): {
+		Summary:  "Estimate what a job offer would cost its job creator without adding it",
+		Request:  data.JobOffer{},
+		Response: data.CostEstimate{},
+		Query: []http.APIParam{
+			{Name: "instruction_count", Description: "the instructions the job is expected to report, 1 by default"},
+		},
+	},
+	apiRoute("GET", "/job_offers/{id}/decisions"): {
+		Summary:  "List the match decisions made for a job offer",
+		Response: []data.MatchDecision{},
+	},
+	apiRoute("GET", "/job_offers/{id}/bids"): {
+		Summary:  "List the auction bids for a job offer",
+		Response: []data.AuctionBid{},
+	},
+	apiRoute("POST", "/federation/job_offers"): {
+		Summary:  "Accept a job offer forwarded by a trusted peer solver",
+		Request:  data.JobOffer{},
+		Response: data.JobOfferContainer{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/resource_offers"): {
+		Summary:  "List resource offers",
+		Response: []data.ResourceOfferContainer{},
+		Query: withPageParams([]http.APIParam{
+			{Name: "resource_provider", Description: "only the resource offers from this address"},
+			{Name: "active", Description: "true for resource offers that are free or running a job"},
+			{Name: "not_matched", Description: "true for resource offers that have no deal yet"},
+			{Name: "attributes", Description: "comma separated name=value pairs the offer must have"},
+		}),
+	},
+	apiRoute("POST", "/resource_offers"): {
+		Summary:    "Add a resource offer",
+		Request:    data.ResourceOffer{},
+		Response:   data.ResourceOfferContainer{},
+		Signed:     true,
+		Idempotent: true,
+	},
+	apiRoute("DELETE", "/resource_offers"): {
+		Summary:  "Remove the signer's resource offers that have no deal, for a resource provider that is draining or paused",
+		Response: []data.ResourceOfferContainer{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/deals"): {
+		Summary:  "List deals",
+		Response: []data.DealContainer{},
+		Query: withPageParams([]http.APIParam{
+			{Name: "job_creator", Description: "only the deals with this job creator"},
+			{Name: "resource_provider", Description: "only the deals with this resource provider"},
+			{Name: "state", Description: "only the deals in this agreement state e.g. DealAgreed"},
+		}),
+	},
+	apiRoute("GET", "/deals/{id}"): {
+		Summary:  "Get a deal, with wait_state it waits for the deal to reach one of the states",
+		Response: data.DealContainer{},
+		Query: []http.APIParam{
+			{Name: "wait_state", Description: "comma separated agreement states e.g. ResultsSubmitted, it also stops waiting once the deal reaches a terminal state"},
+			{Name: "timeout", Description: "seconds to wait for, 30 by default and at most 120, the deal is returned as it is when it runs out"},
+		},
+	},
+	apiRoute("GET", "/deals/{id}/events"): {
+		Summary:             "Stream the events for a deal as server-sent events, send Last-Event-ID to resume, only the parties to the deal can do this",
+		ResponseContentType: "text/event-stream",
+		Query: []http.APIParam{
+			{Name: "last_event_id", Description: "for clients that cannot set the Last-Event-ID header"},
+		},
+	},
+	apiRoute("GET", "/deals/{id}/files"): {
+		Summary:             "Download the result files for a deal, only the job creator can do this",
+		ResponseContentType: "application/x-tar",
+		Signed:              true,
+	},
+	apiRoute("POST", "/deals/{id}/files"): {
+		Summary:            "Upload the result files for a deal as a tar, only the resource provider can do this",
+		RequestContentType: "application/x-tar",
+		Response:           data.Result{},
+		Signed:             true,
+	},
+	apiRoute("GET", "/chain_events"): {
+		Summary:  "List the contract events the indexer has stored, oldest first",
+		Response: []data.ChainEvent{},
+		Query: withPageParams([]http.APIParam{
+			{Name: "deal_id", Description: "only the events for this deal"},
+			{Name: "address", Description: "only the events with this address in their arguments"},
+			{Name: "contract", Description: "only the events from this contract e.g. storage"},
+			{Name: "event", Description: "only the events with this name e.g. DealStateChange"},
+			{Name: "from_block", Description: "the first block to list events from"},
+			{Name: "to_block", Description: "the last block to list events from"},
+		}),
+	},
+	apiRoute("GET", "/results"): {
+		Summary:  "List results, only admins can do this",
+		Response: []data.Result{},
+		Query:    pageParams,
+	},
+	apiRoute("GET", "/deals/{id}/result"): {
+		Summary:  "Get the result for a deal",
+		Response: data.Result{},
+	},
+	apiRoute("POST", "/deals/{id}/result"): {
+		Summary:    "Add the result for a deal",
+		Request:    data.Result{},
+		Response:   data.Result{},
+		Signed:     true,
+		Idempotent: true,
+	},
+	apiRoute("POST", "/deals/{id}/txs/resource_provider"): {
+		Summary:  "Record the transactions the resource provider has sent",
+		Request:  data.DealTransactionsResourceProvider{},
+		Response: data.DealContainer{},
+		Signed:   true,
+	},
+	apiRoute("POST", "/deals/{id}/txs/job_creator"): {
+		Summary:  "Record the transactions the job creator has sent",
+		Request:  data.DealTransactionsJobCreator{},
+		Response: data.DealContainer{},
+		Signed:   true,
+	},
+	apiRoute("POST", "/deals/{id}/txs/mediator"): {
+		Summary:  "Record the transactions the mediator has sent",
+		Request:  data.DealTransactionsMediator{},
+		Response: data.DealContainer{},
+		Signed:   true,
+	},
+	apiRoute("POST", "/deals/{id}/secrets"): {
+		Summary:  "Attach the job's secrets encrypted to the resource provider's secrets key",
+		Request:  data.DealSecrets{},
+		Response: data.DealContainer{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/validation_token"): {
+		Summary:  "Get a token resource providers use with the validation service",
+		Response: http.ValidationToken{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/gas"): {
+		Summary:  "Get the current gas fee estimate, 503 until the first one is in",
+		Response: web3.FeeEstimate{},
+	},
+	apiRoute("GET", "/admin/api_keys"): {
+		Summary:  "List the api keys, only admins can do this",
+		Response: []data.APIKey{},
+		Signed:   true,
+	},
+	apiRoute("POST", "/admin/api_keys"): {
+		Summary:  "Make a read only api key, the key is only returned this once",
+		Request:  apiKeyRequest{},
+		Response: data.CreatedAPIKey{},
+		Signed:   true,
+	},
+	apiRoute("DELETE", "/admin/api_keys/{id}"): {
+		Summary:  "Remove an api key",
+		Response: data.APIKey{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/admin/audit"): {
+		Summary:  "List the signed writes made to the solver, oldest first, only admins can do this",
+		Response: []data.AuditEntry{},
+		Query: withPageParams([]http.APIParam{
+			{Name: "address", Description: "only the calls this address signed"},
+			{Name: "method", Description: "only calls with this method e.g. POST, DELETE or GRPC"},
+			{Name: "route", Description: "only calls to this route template e.g. /api/v1/job_offers"},
+			{Name: "since", Description: "only calls made at or after this millisecond timestamp"},
+			{Name: "until", Description: "only calls made before this millisecond timestamp"},
+		}),
+		Signed: true,
+	},
+	apiRoute("GET", "/webhooks"): {
+		Summary:  "List the webhooks the signer has registered",
+		Response: []data.Webhook{},
+		Signed:   true,
+	},
+	apiRoute("POST", "/webhooks"): {
+		Summary:  "Register a webhook for the deals the signer is a party to, the url has to resolve to a public address and the signing secret is only returned here",
+		Request:  webhookRequest{},
+		Response: data.CreatedWebhook{},
+		Signed:   true,
+	},
+	apiRoute("DELETE", "/webhooks/{id}"): {
+		Summary:  "Remove a webhook and its delivery log",
+		Response: data.Webhook{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/webhooks/{id}/deliveries"): {
+		Summary:  "List every attempt at sending an event to a webhook",
+		Response: []data.WebhookDelivery{},
+		Signed:   true,
+	},
+	apiRoute("GET", "/graphql"): {
+		Summary:  "Run a read only graphql query over offers, deals, results and match decisions",
+		Response: graphqlResponse{},
+		Query: []http.APIParam{
+			{Name: "query", Description: "the graphql query"},
+			{Name: "operationName", Description: "which operation to run when the query has more than one"},
+			{Name: "variables", Description: "json encoded variables for the query"},
+		},
+	},
+	apiRoute("POST", "/graphql"): {
+		Summary:  "Run a read only graphql query, the same as the GET but with the query in the body",
+		Request:  graphqlRequest{},
+		Response: graphqlResponse{},
+	},
+}
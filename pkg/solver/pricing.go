@@ -0,0 +1,147 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// the instruction prices the solver is willing to broker deals at
+// zero for any bound means there is no limit
+type SolverPricingOptions struct {
+	MinInstructionPrice uint64
+	MaxInstructionPrice uint64
+	// bounds for specific modules keyed by module ID, repo or repo@hash
+	// these take precedence over the bounds above
+	ModuleMinInstructionPrice map[string]int64
+	ModuleMaxInstructionPrice map[string]int64
+}
+
+// returns the bounds that apply to a module
+func (options SolverPricingOptions) bounds(module data.ModuleConfig, moduleID string) (uint64, uint64) {
+	min, max := options.MinInstructionPrice, options.MaxInstructionPrice
+	if price, ok := moduleBound(options.ModuleMinInstructionPrice, module, moduleID); ok {
+		min = uint64(price)
+	}
+	if price, ok := moduleBound(options.ModuleMaxInstructionPrice, module, moduleID); ok {
+		max = uint64(price)
+	}
+	return min, max
+}
+
+// the most specific of the bounds that covers the module, its ID then
+// repo@hash then the repo, failing those the longest pattern that
+// matches, ranging over the map would pick one at random
+func moduleBound(bounds map[string]int64, module data.ModuleConfig, moduleID string) (int64, bool) {
+	exact := []string{moduleID}
+	if module.Repo != "" {
+		exact = append(exact, module.Repo+"@"+module.Hash, module.Repo)
+	}
+	for _, key := range exact {
+		if price, ok := bounds[key]; ok && key != "" {
+			return price, true
+		}
+	}
+	keys := make([]string, 0, len(bounds))
+	for key := range bounds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	best := ""
+	for _, key := range keys {
+		if len(key) > len(best) && data.IsModuleAllowed([]string{key}, module, moduleID) {
+			best = key
+		}
+	}
+	if best == "" {
+		return 0, false
+	}
+	return bounds[best], true
+}
+
+func checkPriceBounds(price uint64, min uint64, max uint64) error {
+	if min > 0 && price < min {
+		return fmt.Errorf("instruction price %d is below the solver minimum of %d", price, min)
+	}
+	if max > 0 && price > max {
+		return fmt.Errorf("instruction price %d is above the solver maximum of %d", price, max)
+	}
+	return nil
+}
+
+// a fixed price job offer outside the bounds can never be matched
+// market priced job offers pay whatever the resource offer asks so are not checked
+// and neither are ones paid in another token as the bounds are in the network's
+func (options SolverPricingOptions) CheckJobOffer(jobOffer data.JobOffer) error {
+	if jobOffer.Mode != data.FixedPrice || jobOffer.PaymentToken != "" {
+		return nil
+	}
+	moduleID, err := data.GetModuleID(jobOffer.Module)
+	if err != nil {
+		return err
+	}
+	min, max := options.bounds(jobOffer.Module, moduleID)
+	return checkPriceBounds(jobOffer.Pricing.InstructionPrice, min, max)
+}
+
+// the price a deal would be made at, which with resource pricing is only
+// known once the resource offer is matched with a job offer's spec
+func (options SolverPricingOptions) CheckDealPrice(module data.ModuleConfig, moduleID string, price uint64) error {
+	min, max := options.bounds(module, moduleID)
+	return checkPriceBounds(price, min, max)
+}
+
+// module pricing is keyed by module ID, modules holds the configs of the
+// ones we have had job offers for so their repo bounds apply as well, this
+// only turns away offers early, the matcher checks every deal's real price
+func (options SolverPricingOptions) CheckResourceOffer(resourceOffer data.ResourceOffer, modules map[string]data.ModuleConfig) error {
+	err := checkPriceBounds(resourceOffer.DefaultPricing.InstructionPrice, options.MinInstructionPrice, options.MaxInstructionPrice)
+	if err != nil {
+		return fmt.Errorf("default pricing: %s", err.Error())
+	}
+	for moduleID, pricing := range resourceOffer.ModulePricing {
+		min, max := options.bounds(modules[moduleID], moduleID)
+		err := checkPriceBounds(pricing.InstructionPrice, min, max)
+		if err != nil {
+			return fmt.Errorf("module %s pricing: %s", moduleID, err.Error())
+		}
+	}
+	return nil
+}
+
+// the configs of the modules job offers have been posted for by their ID,
+// a module ID cannot be turned back into its repo so this is how a resource
+// offer's module pricing gets checked against the repo bounds
+type knownModules struct {
+	mutex   sync.RWMutex
+	configs map[string]data.ModuleConfig
+}
+
+func newKnownModules() *knownModules {
+	return &knownModules{configs: map[string]data.ModuleConfig{}}
+}
+
+func (modules *knownModules) add(module data.ModuleConfig) {
+	moduleID, err := data.GetModuleID(module)
+	if err != nil {
+		return
+	}
+	modules.mutex.Lock()
+	defer modules.mutex.Unlock()
+	modules.configs[moduleID] = module
+}
+
+// the configs we know of for the modules the resource offer prices
+func (modules *knownModules) priced(resourceOffer data.ResourceOffer) map[string]data.ModuleConfig {
+	modules.mutex.RLock()
+	defer modules.mutex.RUnlock()
+	configs := map[string]data.ModuleConfig{}
+	for moduleID := range resourceOffer.ModulePricing {
+		if config, ok := modules.configs[moduleID]; ok {
+			configs[moduleID] = config
+		}
+	}
+	return configs
+}
@@ -0,0 +1,68 @@
+//go:build unit
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleBounds(t *testing.T) {
+	options := SolverPricingOptions{
+		MaxInstructionPrice: 100,
+		ModuleMaxInstructionPrice: map[string]int64{
+			"https://github.com/acme/*":         50,
+			"https://github.com/acme/sdxl":      40,
+			"https://github.com/acme/sdxl@v1.0": 30,
+		},
+	}
+	sdxl := data.ModuleConfig{Repo: "https://github.com/acme/sdxl", Hash: "v1.0"}
+	// the same answer every time whatever order the map ranges in
+	for i := 0; i < 20; i++ {
+		_, max := options.bounds(sdxl, "sdxl-v1")
+		assert.Equal(t, uint64(30), max)
+	}
+	_, max := options.bounds(data.ModuleConfig{Repo: sdxl.Repo, Hash: "v2.0"}, "sdxl-v2")
+	assert.Equal(t, uint64(40), max)
+	_, max = options.bounds(data.ModuleConfig{Repo: "https://github.com/acme/llm", Hash: "v1.0"}, "llm-v1")
+	assert.Equal(t, uint64(50), max)
+	_, max = options.bounds(data.ModuleConfig{Repo: "https://github.com/other/llm"}, "other")
+	assert.Equal(t, uint64(100), max)
+}
+
+func TestCheckResourceOfferModuleBounds(t *testing.T) {
+	options := SolverPricingOptions{
+		ModuleMaxInstructionPrice: map[string]int64{"https://github.com/acme/sdxl": 40},
+	}
+	sdxl := data.ModuleConfig{Repo: "https://github.com/acme/sdxl", Hash: "v1.0", Path: "/lilypad_module.json.tmpl"}
+	sdxlID, err := data.GetModuleID(sdxl)
+	assert.NoError(t, err)
+	resourceOffer := data.ResourceOffer{
+		DefaultPricing: data.DealPricing{InstructionPrice: 10},
+		ModulePricing:  map[string]data.DealPricing{sdxlID: {InstructionPrice: 45}},
+	}
+
+	// nothing is known of the module until a job offer names it
+	modules := newKnownModules()
+	assert.NoError(t, options.CheckResourceOffer(resourceOffer, modules.priced(resourceOffer)))
+
+	modules.add(sdxl)
+	err = options.CheckResourceOffer(resourceOffer, modules.priced(resourceOffer))
+	assert.ErrorContains(t, err, "above the solver maximum of 40")
+
+	resourceOffer.ModulePricing[sdxlID] = data.DealPricing{InstructionPrice: 40}
+	assert.NoError(t, options.CheckResourceOffer(resourceOffer, modules.priced(resourceOffer)))
+}
+
+func TestCheckDealPriceModuleBounds(t *testing.T) {
+	options := SolverPricingOptions{
+		MaxInstructionPrice:       100,
+		ModuleMaxInstructionPrice: map[string]int64{"https://github.com/acme/sdxl": 40},
+	}
+	sdxl := data.ModuleConfig{Repo: "https://github.com/acme/sdxl", Hash: "v1.0"}
+	assert.NoError(t, options.CheckDealPrice(sdxl, "sdxl-v1", 40))
+	assert.ErrorContains(t, options.CheckDealPrice(sdxl, "sdxl-v1", 41), "above the solver maximum of 40")
+	assert.NoError(t, options.CheckDealPrice(data.ModuleConfig{Repo: "https://github.com/other/llm"}, "llm", 41))
+}
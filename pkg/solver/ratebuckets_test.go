@@ -0,0 +1,78 @@
+//go:build unit
+
+package solver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hashicorp/go-retryablehttp"
+	lilypadhttp "github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitBuckets(t *testing.T) {
+	firstKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	secondKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	first := web3.NewKeySigner(firstKey)
+	second := web3.NewKeySigner(secondKey)
+
+	limited := func(options lilypadhttp.RateLimiterOptions) func(web3.Signer) int {
+		clientIP, err := lilypadhttp.NewClientIPResolver(lilypadhttp.ProxyOptions{})
+		require.NoError(t, err)
+		server := &solverServer{
+			options:  lilypadhttp.ServerOptions{RateLimiter: options},
+			clientIP: clientIP,
+		}
+		handler := server.rateLimitMiddleware()(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+		return func(signer web3.Signer) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/deals", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if signer != nil {
+				signed, err := retryablehttp.NewRequest(http.MethodGet, "/api/v1/deals", nil)
+				require.NoError(t, err)
+				require.NoError(t, lilypadhttp.AddHeaders(signed, signer, signer.Address().String()))
+				req.Header = signed.Header
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+			return res.Code
+		}
+	}
+
+	t.Run("a new signer does not get around the ip", func(t *testing.T) {
+		call := limited(lilypadhttp.RateLimiterOptions{RequestLimit: 2, WindowLength: 60, PerAddress: true})
+		require.Equal(t, http.StatusOK, call(first))
+		require.Equal(t, http.StatusOK, call(second))
+		require.Equal(t, http.StatusTooManyRequests, call(nil))
+		require.Equal(t, http.StatusTooManyRequests, call(first))
+	})
+
+	t.Run("the signer has its own limit", func(t *testing.T) {
+		call := limited(lilypadhttp.RateLimiterOptions{
+			RequestLimit:  5,
+			WindowLength:  60,
+			PerAddress:    true,
+			AddressLimits: map[string]int64{first.Address().String(): 1},
+		})
+		require.Equal(t, http.StatusOK, call(first))
+		require.Equal(t, http.StatusTooManyRequests, call(first))
+		require.Equal(t, http.StatusOK, call(second))
+		require.Equal(t, http.StatusOK, call(nil))
+	})
+
+	t.Run("the group limit", func(t *testing.T) {
+		call := limited(lilypadhttp.RateLimiterOptions{
+			RequestLimit: 5,
+			WindowLength: 60,
+			GroupLimits:  map[string]int64{rateGroupReads: 1},
+		})
+		require.Equal(t, http.StatusOK, call(first))
+		require.Equal(t, http.StatusTooManyRequests, call(second))
+	})
+}
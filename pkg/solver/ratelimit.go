@@ -0,0 +1,104 @@
+package solver
+
+import (
+	corehttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/httprate"
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+)
+
+// the route groups SERVER_RATE_GROUP_LIMITS can set limits for
+const (
+	// adding job offers and resource offers
+	rateGroupOffers = "offers"
+	// every other POST or DELETE
+	rateGroupWrites = "writes"
+	rateGroupReads  = "reads"
+)
+
+// a count of requests for one caller, 0 is the limiter's request limit
+type rateBucket struct {
+	identity string
+	limit    int
+}
+
+// requests are counted per endpoint and route group for each caller, every
+// request counts against its ip and a signed one against its signer as well
+// when the limiter is per address, so a new key for each request cannot get
+// around the ip's limit, the limit comes from the address or the route group
+// when those have one
+func (solverServer *solverServer) rateLimitMiddleware() func(corehttp.Handler) corehttp.Handler {
+	options := solverServer.options.RateLimiter
+	limiter := httprate.NewRateLimiter(
+		options.RequestLimit,
+		time.Duration(options.WindowLength)*time.Second,
+		httprate.WithLimitHandler(func(res corehttp.ResponseWriter, req *corehttp.Request) {
+			http.RecordRateLimited(req)
+			http.WriteErrorMessage(res, req, corehttp.StatusText(corehttp.StatusTooManyRequests), corehttp.StatusTooManyRequests)
+		}),
+	)
+	return func(next corehttp.Handler) corehttp.Handler {
+		return corehttp.HandlerFunc(func(res corehttp.ResponseWriter, req *corehttp.Request) {
+			for _, bucket := range solverServer.rateBuckets(req) {
+				limited := req
+				if bucket.limit > 0 {
+					limited = req.WithContext(httprate.WithRequestLimit(req.Context(), bucket.limit))
+				}
+				// reads and writes to the same path are counted apart
+				key := strings.Join([]string{bucket.identity, rateGroup(req), req.URL.Path}, ":")
+				if limiter.RespondOnLimit(res, limited, key) {
+					return
+				}
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+func (solverServer *solverServer) rateBuckets(req *corehttp.Request) []rateBucket {
+	options := solverServer.options.RateLimiter
+	groupLimit := int(options.GroupLimits[rateGroup(req)])
+	// behind a load balancer this is the client rather than the balancer
+	// as long as SERVER_TRUSTED_PROXIES covers it
+	buckets := []rateBucket{{identity: "ip:" + solverServer.clientIP.ClientIP(req), limit: groupLimit}}
+	if !options.PerAddress {
+		return buckets
+	}
+	address, err := http.CheckSignature(req)
+	if err != nil {
+		return buckets
+	}
+	address = strings.ToLower(address)
+	limit := groupLimit
+	for limitAddress, addressLimit := range options.AddressLimits {
+		if strings.ToLower(limitAddress) == address {
+			limit = int(addressLimit)
+		}
+	}
+	return append(buckets, rateBucket{identity: "address:" + address, limit: limit})
+}
+
+// graphql queries are POSTed but cannot change anything
+func isReadRequest(req *corehttp.Request) bool {
+	return req.Method == corehttp.MethodGet || strings.HasSuffix(req.URL.Path, "/graphql")
+}
+
+func rateGroup(req *corehttp.Request) string {
+	if isReadRequest(req) {
+		return rateGroupReads
+	}
+	// the gRPC calls have no route but are given the path of the one they stand in for
+	template := req.URL.Path
+	if route := mux.CurrentRoute(req); route != nil {
+		if routeTemplate, err := route.GetPathTemplate(); err == nil {
+			template = routeTemplate
+		}
+	}
+	if strings.HasSuffix(template, "/job_offers") || strings.HasSuffix(template, "/resource_offers") {
+		return rateGroupOffers
+	}
+	return rateGroupWrites
+}
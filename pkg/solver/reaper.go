@@ -0,0 +1,104 @@
+package solver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+type SolverReaperOptions struct {
+	// how many seconds between checks for stale deals
+	// zero turns the reaper off
+	Interval int
+	// how many seconds past the deal's own timeout we wait before reaping it
+	// so the parties get the first chance to notice for themselves
+	Grace int
+}
+
+// the timeout that applies to a deal in each state and the state it times out into
+type stageTimeout struct {
+	timeout func(data.DealTimeouts) uint64
+	state   string
+}
+
+var stageTimeouts = map[string]stageTimeout{
+	"DealNegotiating": {
+		timeout: func(timeouts data.DealTimeouts) uint64 { return timeouts.Agree.Timeout },
+		state:   "TimeoutAgree",
+	},
+	"DealAgreed": {
+		timeout: func(timeouts data.DealTimeouts) uint64 { return timeouts.SubmitResults.Timeout },
+		state:   "TimeoutSubmitResults",
+	},
+	"ResultsSubmitted": {
+		timeout: func(timeouts data.DealTimeouts) uint64 { return timeouts.JudgeResults.Timeout },
+		state:   "TimeoutJudgeResults",
+	},
+	"ResultsChecked": {
+		timeout: func(timeouts data.DealTimeouts) uint64 { return timeouts.MediateResults.Timeout },
+		state:   "TimeoutMediateResults",
+	},
+}
+
+type staleDeal struct {
+	deal  data.DealContainer
+	state uint8
+}
+
+// finds deals that have sat in an intermediate state for longer than they should,
+// each is found once per timeout and grace for as long as it stays there
+//
+// the store does not record when a deal changed state so we time each
+// state from when we first saw the deal in it, after a restart the clock
+// starts again which only ever makes us reap later not sooner
+type dealReaper struct {
+	options SolverReaperOptions
+	mutex   sync.Mutex
+	seen    map[string]dealSighting
+}
+
+type dealSighting struct {
+	state uint8
+	since time.Time
+}
+
+func newDealReaper(options SolverReaperOptions) *dealReaper {
+	return &dealReaper{
+		options: options,
+		seen:    map[string]dealSighting{},
+	}
+}
+
+func (reaper *dealReaper) findStaleDeals(deals []data.DealContainer, now time.Time) []staleDeal {
+	reaper.mutex.Lock()
+	defer reaper.mutex.Unlock()
+
+	stale := []staleDeal{}
+	current := map[string]dealSighting{}
+	for _, deal := range deals {
+		stage, ok := stageTimeouts[data.GetAgreementStateString(deal.State)]
+		if !ok {
+			continue
+		}
+		sighting, ok := reaper.seen[deal.ID]
+		if !ok || sighting.state != deal.State {
+			sighting = dealSighting{state: deal.State, since: now}
+		}
+
+		wait := time.Duration(stage.timeout(deal.Deal.Timeouts)+uint64(reaper.options.Grace)) * time.Second
+		if now.Sub(sighting.since) > wait {
+			stale = append(stale, staleDeal{
+				deal:  deal,
+				state: data.GetAgreementStateIndex(stage.state),
+			})
+			// the deal stays in its state until the timeout is on chain, so
+			// the clock restarts and the parties are told again if it never is
+			sighting.since = now
+		}
+		current[deal.ID] = sighting
+	}
+	// forget deals that have moved on to a state we do not time
+	reaper.seen = current
+	return stale
+}
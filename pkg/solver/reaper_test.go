@@ -0,0 +1,56 @@
+//go:build unit
+
+package solver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+func TestFindStaleDeals(t *testing.T) {
+	reaper := newDealReaper(SolverReaperOptions{Grace: 10})
+	deal := data.DealContainer{
+		ID:    "deal",
+		State: data.GetAgreementStateIndex("DealAgreed"),
+		Deal: data.Deal{
+			Timeouts: data.DealTimeouts{
+				SubmitResults: data.DealTimeout{Timeout: 60},
+			},
+		},
+	}
+	settled := data.DealContainer{
+		ID:    "settled",
+		State: data.GetAgreementStateIndex("ResultsAccepted"),
+	}
+	start := time.Now()
+
+	if stale := reaper.findStaleDeals([]data.DealContainer{deal, settled}, start); len(stale) != 0 {
+		t.Fatalf("expected no stale deals when first seen, got %d", len(stale))
+	}
+	if stale := reaper.findStaleDeals([]data.DealContainer{deal}, start.Add(65*time.Second)); len(stale) != 0 {
+		t.Fatalf("expected the grace to hold off the reaper, got %d", len(stale))
+	}
+	stale := reaper.findStaleDeals([]data.DealContainer{deal}, start.Add(75*time.Second))
+	if len(stale) != 1 {
+		t.Fatalf("expected one stale deal, got %d", len(stale))
+	}
+	if stale[0].state != data.GetAgreementStateIndex("TimeoutSubmitResults") {
+		t.Errorf("expected TimeoutSubmitResults, got %s", data.GetAgreementStateString(stale[0].state))
+	}
+
+	// the deal is only found again after another timeout and grace
+	if stale := reaper.findStaleDeals([]data.DealContainer{deal}, start.Add(140*time.Second)); len(stale) != 0 {
+		t.Errorf("expected the reaper to wait before finding the deal again, got %d", len(stale))
+	}
+	if stale := reaper.findStaleDeals([]data.DealContainer{deal}, start.Add(150*time.Second)); len(stale) != 1 {
+		t.Errorf("expected the deal to be found again while it is stuck, got %d", len(stale))
+	}
+
+	// moving on to a new state restarts the clock
+	deal.State = data.GetAgreementStateIndex("ResultsSubmitted")
+	if stale := reaper.findStaleDeals([]data.DealContainer{deal}, start.Add(155*time.Second)); len(stale) != 0 {
+		t.Errorf("expected the clock to restart on a state change, got %d", len(stale))
+	}
+}
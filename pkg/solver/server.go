@@ -9,18 +9,20 @@ import (
 	corehttp "net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/go-chi/httprate"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/http"
 	"github.com/lilypad-tech/lilypad/pkg/metricsDashboard"
 	"github.com/lilypad-tech/lilypad/pkg/solver/store"
 	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 	"go.opentelemetry.io/otel/sdk/trace"
@@ -31,6 +33,10 @@ type solverServer struct {
 	controller *SolverController
 	store      store.SolverStore
 	services   data.ServiceConfig
+	dealEvents *dealEventLog
+	idempotent *idempotencyKeys
+	replay     *http.ReplayGuard
+	clientIP   *http.ClientIPResolver
 }
 
 func NewSolverServer(
@@ -39,12 +45,23 @@ func NewSolverServer(
 	store store.SolverStore,
 	services data.ServiceConfig,
 ) (*solverServer, error) {
+	clientIP, err := http.NewClientIPResolver(options.Proxy)
+	if err != nil {
+		return nil, err
+	}
 	server := &solverServer{
 		options:    options,
 		controller: controller,
 		store:      store,
+		dealEvents: newDealEventLog(),
+		idempotent: newIdempotencyKeys(),
+		replay:     http.NewReplayGuard(options.Replay),
+		clientIP:   clientIP,
 	}
 
+	// keep a history of each deal's events for the server-sent event stream
+	controller.subscribeEvents(server.dealEvents.add)
+
 	metricsDashboard.Init(services.APIHost)
 
 	return server, nil
@@ -65,41 +82,81 @@ func NewSolverServer(
 func (solverServer *solverServer) ListenAndServe(ctx context.Context, cm *system.CleanupManager, tracerProvider *trace.TracerProvider) error {
 	router := mux.NewRouter()
 
-	subrouter := router.PathPrefix(http.API_SUB_PATH).Subrouter()
+	api := http.NewAPIRouter(router)
+	subrouter := api.Version(http.API_VERSION_1)
 
-	subrouter.Use(http.CorsMiddleware)
+	subrouter.Use(http.MetricsMiddleware)
 	subrouter.Use(otelmux.Middleware("solver", otelmux.WithTracerProvider(tracerProvider)))
-	subrouter.Use(httprate.Limit(
-		solverServer.options.RateLimiter.RequestLimit,
-		time.Duration(solverServer.options.RateLimiter.WindowLength)*time.Second,
-		httprate.WithKeyFuncs(httprate.KeyByRealIP, httprate.KeyByEndpoint),
-	))
+	subrouter.Use(http.SignatureMiddleware)
+	// the gRPC calls share the limiter so they count against the same buckets
+	rateLimit := solverServer.rateLimitMiddleware()
+	subrouter.Use(rateLimit)
+	subrouter.Use(solverServer.replay.Middleware)
+	subrouter.Use(solverServer.readAuthMiddleware)
+	subrouter.Use(solverServer.bodyLimitMiddleware)
+	subrouter.Use(solverServer.auditMiddleware)
 
 	subrouter.HandleFunc("/job_offers", http.GetHandler(solverServer.getJobOffers)).Methods("GET")
-	subrouter.HandleFunc("/job_offers", http.PostHandler(solverServer.addJobOffer)).Methods("POST")
+	subrouter.HandleFunc("/job_offers", solverServer.idempotency(http.PostHandler(solverServer.addJobOffer))).Methods("POST")
+	subrouter.HandleFunc("/job_offers/simulate", http.PostHandler(solverServer.simulateJobOffer)).Methods("POST")
+	subrouter.HandleFunc("/job_offers/estimate", http.PostHandler(solverServer.estimateJobOffer)).Methods("POST")
+	subrouter.HandleFunc("/job_offers/{id}/decisions", http.GetHandler(solverServer.getMatchDecisions)).Methods("GET")
+	subrouter.HandleFunc("/job_offers/{id}/bids", http.GetHandler(solverServer.getAuctionBids)).Methods("GET")
+
+	subrouter.HandleFunc("/federation/job_offers", http.PostHandler(solverServer.addForwardedJobOffer)).Methods("POST")
 
 	subrouter.HandleFunc("/resource_offers", http.GetHandler(solverServer.getResourceOffers)).Methods("GET")
-	subrouter.HandleFunc("/resource_offers", http.PostHandler(solverServer.addResourceOffer)).Methods("POST")
+	subrouter.HandleFunc("/resource_offers", solverServer.idempotency(http.PostHandler(solverServer.addResourceOffer))).Methods("POST")
+	subrouter.HandleFunc("/resource_offers", http.GetHandler(solverServer.removeResourceOffers)).Methods("DELETE")
 
 	subrouter.HandleFunc("/deals", http.GetHandler(solverServer.getDeals)).Methods("GET")
 	subrouter.HandleFunc("/deals/{id}", http.GetHandler(solverServer.getDeal)).Methods("GET")
+	subrouter.HandleFunc("/deals/{id}/events", solverServer.streamDealEvents).Methods("GET")
 
 	subrouter.HandleFunc("/deals/{id}/files", solverServer.downloadFiles).Methods("GET")
 	subrouter.HandleFunc("/deals/{id}/files", solverServer.uploadFiles).Methods("POST")
 
+	subrouter.HandleFunc("/results", http.GetHandler(solverServer.getResults)).Methods("GET")
+
 	subrouter.HandleFunc("/deals/{id}/result", http.GetHandler(solverServer.getResult)).Methods("GET")
-	subrouter.HandleFunc("/deals/{id}/result", http.PostHandler(solverServer.addResult)).Methods("POST")
+	subrouter.HandleFunc("/deals/{id}/result", solverServer.idempotency(http.PostHandler(solverServer.addResult))).Methods("POST")
 
 	subrouter.HandleFunc("/deals/{id}/txs/resource_provider", http.PostHandler(solverServer.updateTransactionsResourceProvider)).Methods("POST")
 	subrouter.HandleFunc("/deals/{id}/txs/job_creator", http.PostHandler(solverServer.updateTransactionsJobCreator)).Methods("POST")
 	subrouter.HandleFunc("/deals/{id}/txs/mediator", http.PostHandler(solverServer.updateTransactionsMediator)).Methods("POST")
+	subrouter.HandleFunc("/deals/{id}/secrets", http.PostHandler(solverServer.updateDealSecrets)).Methods("POST")
+
+	subrouter.HandleFunc("/chain_events", http.GetHandler(solverServer.getChainEvents)).Methods("GET")
 
 	subrouter.HandleFunc("/validation_token", http.GetHandler(solverServer.getValidationToken)).Methods("GET")
 
-	// this will fan out to all connected web socket connections
+	subrouter.HandleFunc("/gas", http.GetHandler(solverServer.getGasEstimate)).Methods("GET")
+
+	subrouter.HandleFunc("/admin/api_keys", http.GetHandler(solverServer.getAPIKeys)).Methods("GET")
+	subrouter.HandleFunc("/admin/api_keys", http.PostHandler(solverServer.addAPIKey)).Methods("POST")
+	// a delete has no body so the get wrapper fits it
+	subrouter.HandleFunc("/admin/api_keys/{id}", http.GetHandler(solverServer.removeAPIKey)).Methods("DELETE")
+	subrouter.HandleFunc("/admin/audit", http.GetHandler(solverServer.getAuditEntries)).Methods("GET")
+
+	subrouter.HandleFunc("/webhooks", http.GetHandler(solverServer.getWebhooks)).Methods("GET")
+	subrouter.HandleFunc("/webhooks", http.PostHandler(solverServer.addWebhook)).Methods("POST")
+	subrouter.HandleFunc("/webhooks/{id}", http.GetHandler(solverServer.removeWebhook)).Methods("DELETE")
+	subrouter.HandleFunc("/webhooks/{id}/deliveries", http.GetHandler(solverServer.getWebhookDeliveries)).Methods("GET")
+
+	graphqlSchema, err := newGraphQLSchema(solverServer.store)
+	if err != nil {
+		return err
+	}
+	subrouter.HandleFunc("/graphql", graphqlHandler(graphqlSchema, solverServer.isAdmin)).Methods("GET", "POST")
+
+	// signed deal lifecycle events for the parties that registered a webhook
+	webhooks := newWebhookDispatcher(ctx, solverServer.store, solverServer.controller.options.Webhooks)
+	solverServer.controller.subscribeEvents(webhooks.dispatch)
+
+	// this will fan out to the connected web socket connections
 	// we read all events coming from inside the solver controller
-	// and write them to anyone who is connected to us
-	websocketEventChannel := make(chan []byte)
+	// and write them to the clients subscribed to the parties involved
+	websocketEventChannel := make(chan http.WSMessage)
 
 	log.Debug().Msgf("begin solverServer.controller.subscribeEvents")
 	solverServer.controller.subscribeEvents(func(ev SolverEvent) {
@@ -107,7 +164,10 @@ func (solverServer *solverServer) ListenAndServe(ctx context.Context, cm *system
 		if err != nil {
 			log.Error().Msgf("Error marshalling event: %s", err.Error())
 		}
-		websocketEventChannel <- evBytes
+		websocketEventChannel <- http.WSMessage{
+			Payload:   evBytes,
+			Addresses: ev.addresses(),
+		}
 	})
 
 	http.StartWebSocketServer(
@@ -119,21 +179,92 @@ func (solverServer *solverServer) ListenAndServe(ctx context.Context, cm *system
 		solverServer.disconnectCB,
 	)
 
+	api.ServeVersions()
+
+	// the document is built from the routes above so it has to come last
+	openAPI, err := http.GenerateOpenAPI(router, http.OpenAPIInfo{
+		Title:   "Lilypad Solver",
+		Version: system.Version,
+	}, solverAPIOperations)
+	if err != nil {
+		return err
+	}
+	err = http.ServeOpenAPI(router, openAPI, solverServer.options.Docs)
+	if err != nil {
+		return err
+	}
+	err = solverServer.serveDashboard(router)
+	if err != nil {
+		return err
+	}
+
+	http.ServeHealth(router, []http.ReadinessCheck{
+		{Name: "store", Check: solverServer.store.Ping},
+		{Name: "web3", Check: solverServer.controller.web3SDK.Ping},
+		{Name: "events", Check: func(ctx context.Context) error {
+			return solverServer.controller.web3Events.Healthy()
+		}},
+	})
+
+	if solverServer.options.Prometheus.Enabled {
+		err = http.RegisterMetrics(solverServer.storeMetrics()...)
+		if err != nil {
+			return err
+		}
+		err = http.RegisterMetrics(solverServer.controller.web3SDK.GasOracle.Collectors()...)
+		if err != nil {
+			return err
+		}
+		http.ServeMetrics(router, solverServer.options.Prometheus)
+	}
+
+	go solverServer.expireIdempotentResponses(ctx)
+	go solverServer.expireAuditEntries(ctx)
+
+	tlsConfig, err := http.ServerTLSConfig(solverServer.options.TLS)
+	if err != nil {
+		return err
+	}
+
+	// compression sits inside so the request id and cors headers are set either way
+	handler := http.CompressHandler(solverServer.options.Compression, router)
+	handler = http.CorsHandler(solverServer.options.Cors, http.RequestIDMiddleware(handler))
+
 	srv := &corehttp.Server{
 		Addr:              fmt.Sprintf("%s:%d", solverServer.options.Host, solverServer.options.Port),
-		WriteTimeout:      time.Minute * 15,
-		ReadTimeout:       time.Minute * 15,
-		ReadHeaderTimeout: time.Minute * 15,
-		IdleTimeout:       time.Minute * 60,
-		Handler:           router,
+		WriteTimeout:      time.Duration(solverServer.options.Limits.WriteTimeout) * time.Second,
+		ReadTimeout:       time.Duration(solverServer.options.Limits.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(solverServer.options.Limits.ReadHeaderTimeout) * time.Second,
+		IdleTimeout:       time.Duration(solverServer.options.Limits.IdleTimeout) * time.Second,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+	}
+	err = http.ConfigureServer(srv, solverServer.options.Connection)
+	if err != nil {
+		return err
+	}
+	listener, err := http.Listen(srv, solverServer.options.Connection)
+	if err != nil {
+		return err
 	}
 
 	// Create a channel to receive errors from ListenAndServe
-	serverErrors := make(chan error, 1)
+	serverErrors := make(chan error, 2)
 
-	// Run ListenAndServe in a goroutine because it blocks
+	if solverServer.options.GRPCPort != 0 {
+		go func() {
+			serverErrors <- http.ListenAndServeGRPC(ctx, solverServer.options, tlsConfig, solverServer.replay, solverServer.registerGRPC, solverServer.grpcInterceptors(rateLimit)...)
+		}()
+	}
+
+	// Run Serve in a goroutine because it blocks
 	go func() {
-		serverErrors <- srv.ListenAndServe()
+		if tlsConfig != nil {
+			// the certificates are already in the tls config
+			serverErrors <- srv.ServeTLS(listener, "", "")
+			return
+		}
+		serverErrors <- srv.Serve(listener)
 	}()
 
 	select {
@@ -153,6 +284,21 @@ func (solverServer *solverServer) ListenAndServe(ctx context.Context, cm *system
 	return nil
 }
 
+// the result files are streamed to disk and are far bigger than
+// any json body so they have a limit of their own
+func (solverServer *solverServer) bodyLimitMiddleware(next corehttp.Handler) corehttp.Handler {
+	return corehttp.HandlerFunc(func(res corehttp.ResponseWriter, req *corehttp.Request) {
+		limit := solverServer.options.Limits.MaxBodySize
+		if route := mux.CurrentRoute(req); route != nil && req.Method == corehttp.MethodPost {
+			if template, err := route.GetPathTemplate(); err == nil && strings.HasSuffix(template, "/files") {
+				limit = solverServer.options.Limits.MaxUploadSize
+			}
+		}
+		http.LimitBody(res, req, limit)
+		next.ServeHTTP(res, req)
+	})
+}
+
 // WS connect events
 func (solverServer *solverServer) connectCB(connParams http.WSConnectionParams) {
 	if connParams.Type == "ResourceProvider" {
@@ -173,7 +319,7 @@ func (solverServer *solverServer) disconnectCB(connParams http.WSConnectionParam
 			CountryCode: connParams.CountryCode,
 			IP:          connParams.IP,
 		})
-		solverServer.controller.removeUnmatchedResourceOffers(connParams.ID)
+		_, _ = solverServer.controller.removeUnmatchedResourceOffers(connParams.ID)
 	}
 }
 
@@ -200,7 +346,27 @@ func (solverServer *solverServer) getJobOffers(res corehttp.ResponseWriter, req
 	if includeCancelled := req.URL.Query().Get("include_cancelled"); includeCancelled == "true" {
 		query.IncludeCancelled = true
 	}
-	return solverServer.store.GetJobOffers(query)
+	if series := req.URL.Query().Get("series"); series != "" {
+		query.Series = series
+	}
+	page, err := getPageQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	query.Page = page
+	jobOffers, err := solverServer.store.GetJobOffers(query)
+	if err != nil {
+		return nil, err
+	}
+	if !page.Paged() {
+		return jobOffers, nil
+	}
+	total, err := solverServer.store.CountJobOffers(query)
+	if err != nil {
+		return nil, err
+	}
+	setPageHeaders(res, page, total, jobOffers, func(jobOffer data.JobOfferContainer) string { return jobOffer.ID })
+	return jobOffers, nil
 }
 
 func (solverServer *solverServer) getResourceOffers(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.ResourceOfferContainer, error) {
@@ -215,7 +381,38 @@ func (solverServer *solverServer) getResourceOffers(res corehttp.ResponseWriter,
 	if notMatched := req.URL.Query().Get("not_matched"); notMatched == "true" {
 		query.NotMatched = true
 	}
-	return solverServer.store.GetResourceOffers(query)
+	// attributes are passed as name=value pairs separated by commas
+	if attributes := req.URL.Query().Get("attributes"); attributes != "" {
+		query.Attributes = map[string]string{}
+		for _, pair := range strings.Split(attributes, ",") {
+			name, value, found := strings.Cut(pair, "=")
+			if !found {
+				return nil, http.HTTPError{
+					Message:    fmt.Sprintf("invalid attribute filter %q, expected name=value", pair),
+					StatusCode: corehttp.StatusBadRequest,
+				}
+			}
+			query.Attributes[name] = value
+		}
+	}
+	page, err := getPageQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	query.Page = page
+	resourceOffers, err := solverServer.store.GetResourceOffers(query)
+	if err != nil {
+		return nil, err
+	}
+	if !page.Paged() {
+		return resourceOffers, nil
+	}
+	total, err := solverServer.store.CountResourceOffers(query)
+	if err != nil {
+		return nil, err
+	}
+	setPageHeaders(res, page, total, resourceOffers, func(resourceOffer data.ResourceOfferContainer) string { return resourceOffer.ID })
+	return resourceOffers, nil
 }
 
 func (solverServer *solverServer) getDeals(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.DealContainer, error) {
@@ -230,7 +427,88 @@ func (solverServer *solverServer) getDeals(res corehttp.ResponseWriter, req *cor
 	if state := req.URL.Query().Get("state"); state != "" {
 		query.State = state
 	}
-	return solverServer.store.GetDeals(query)
+	page, err := getPageQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	query.Page = page
+	deals, err := solverServer.store.GetDeals(query)
+	if err != nil {
+		return nil, err
+	}
+	if !page.Paged() {
+		return deals, nil
+	}
+	total, err := solverServer.store.CountDeals(query)
+	if err != nil {
+		return nil, err
+	}
+	setPageHeaders(res, page, total, deals, func(deal data.DealContainer) string { return deal.ID })
+	return deals, nil
+}
+
+// every deal's results at once so like the other admin lists only
+// admins can see it, each party can still get their own deal's result
+func (solverServer *solverServer) getResults(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.Result, error) {
+	if _, err := solverServer.checkAdmin(req); err != nil {
+		return nil, err
+	}
+	page, err := getPageQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	results, err := solverServer.store.GetResultsPage(page)
+	if err != nil {
+		return nil, err
+	}
+	if !page.Paged() {
+		return results, nil
+	}
+	total, err := solverServer.store.CountResults()
+	if err != nil {
+		return nil, err
+	}
+	// results are keyed by their deal so that is what the cursor uses
+	setPageHeaders(res, page, total, results, func(result data.Result) string { return result.DealID })
+	return results, nil
+}
+
+// the limit, offset, cursor and order query params every list endpoint takes
+func getPageQuery(req *corehttp.Request) (store.PageQuery, error) {
+	page := store.PageQuery{
+		Cursor: req.URL.Query().Get("cursor"),
+		Order:  req.URL.Query().Get("order"),
+	}
+	for name, value := range map[string]*int{"limit": &page.Limit, "offset": &page.Offset} {
+		param := req.URL.Query().Get(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 0 {
+			return page, http.HTTPError{
+				Message:    fmt.Sprintf("invalid %s %q, expected a positive number", name, param),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+		*value = parsed
+	}
+	if page.Order != "" && page.Order != store.OrderAscending && page.Order != store.OrderDescending {
+		return page, http.HTTPError{
+			Message:    fmt.Sprintf("invalid order %q, expected %s or %s", page.Order, store.OrderAscending, store.OrderDescending),
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	return page, nil
+}
+
+// the body stays a plain list so older clients keep working and the page
+// details go in headers, the next cursor is only set when there may be more
+func setPageHeaders[T any](res corehttp.ResponseWriter, page store.PageQuery, total int64, rows []T, id func(T) string) {
+	res.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	if page.Limit > 0 && len(rows) == page.Limit {
+		res.Header().Set("X-Next-Cursor", id(rows[len(rows)-1]))
+	}
 }
 
 /*
@@ -247,16 +525,44 @@ func (solverServer *solverServer) getDeals(res corehttp.ResponseWriter, req *cor
 func (solverServer *solverServer) getDeal(res corehttp.ResponseWriter, req *corehttp.Request) (data.DealContainer, error) {
 	vars := mux.Vars(req)
 	id := vars["id"]
-	deal, err := solverServer.store.GetDeal(id)
+	states, timeout, err := parseDealWait(req)
+	if err != nil {
+		return data.DealContainer{}, err
+	}
+	var deal *data.DealContainer
+	if len(states) > 0 {
+		deal, err = solverServer.waitForDealState(req, id, states, timeout)
+	} else {
+		deal, err = solverServer.store.GetDeal(id)
+	}
 	if err != nil {
 		return data.DealContainer{}, err
 	}
 	if deal == nil {
-		return data.DealContainer{}, fmt.Errorf("deal not found")
+		return data.DealContainer{}, dealNotFound(id)
 	}
 	return *deal, nil
 }
 
+func (solverServer *solverServer) getAuctionBids(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.AuctionBid, error) {
+	vars := mux.Vars(req)
+	id := vars["id"]
+	return solverServer.store.GetAuctionBids(id)
+}
+
+func (solverServer *solverServer) getMatchDecisions(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.MatchDecision, error) {
+	vars := mux.Vars(req)
+	id := vars["id"]
+	jobOffer, err := solverServer.store.GetJobOffer(id)
+	if err != nil {
+		return nil, err
+	}
+	if jobOffer == nil {
+		return nil, offerNotFound("job offer", id)
+	}
+	return solverServer.store.GetJobOfferMatchDecisions(id)
+}
+
 func (solverServer *solverServer) getResult(res corehttp.ResponseWriter, req *corehttp.Request) (data.Result, error) {
 	vars := mux.Vars(req)
 	id := vars["id"]
@@ -265,7 +571,7 @@ func (solverServer *solverServer) getResult(res corehttp.ResponseWriter, req *co
 		return data.Result{}, err
 	}
 	if result == nil {
-		return data.Result{}, fmt.Errorf("result not found")
+		return data.Result{}, resultNotFound(id)
 	}
 	return *result, nil
 }
@@ -284,21 +590,116 @@ func (solverServer *solverServer) getResult(res corehttp.ResponseWriter, req *co
 func (solverServer *solverServer) addJobOffer(jobOffer data.JobOffer, res corehttp.ResponseWriter, req *corehttp.Request) (*data.JobOfferContainer, error) {
 	signerAddress, err := http.CheckSignature(req)
 	if err != nil {
-		log.Error().Err(err).Msgf("error checking signature")
+		http.RequestLogger(req).Error().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	typedSignature, err := http.TypedSignature(req)
+	if err != nil {
 		return nil, err
 	}
+	jobOfferContainer, err := solverServer.addSignedJobOffer(jobOffer, signerAddress, typedSignature)
+	if err != nil {
+		return nil, err
+	}
+	// ties the request id the job creator logged to the job offer id
+	// that shows up in the deal and on the resource provider
+	http.RequestLogger(req).Info().Str("job_offer", jobOfferContainer.ID).Msgf("added job offer")
+	return jobOfferContainer, nil
+}
+
+// the checks for a new job offer shared by the REST and gRPC apis
+func (solverServer *solverServer) addSignedJobOffer(jobOffer data.JobOffer, signerAddress string, typedSignature []byte) (*data.JobOfferContainer, error) {
 	// Only the job creator can post their job offer
 	if signerAddress != jobOffer.JobCreator {
-		return nil, fmt.Errorf("job creator address does not match signer address")
+		return nil, unauthorizedParty("job creator", signerAddress)
 	}
-	err = data.CheckJobOffer(jobOffer)
+	err := data.CheckJobOffer(jobOffer)
 	if err != nil {
 		log.Error().Err(err).Msgf("Error checking job offer")
 		return nil, err
 	}
+	err = solverServer.checkJobOfferSignature(jobOffer, typedSignature, signerAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !solverServer.controller.web3SDK.SettlesIn(jobOffer.PaymentToken) {
+		return nil, unsupportedPaymentToken(jobOffer.PaymentToken)
+	}
+	err = solverServer.controller.options.Pricing.CheckJobOffer(jobOffer)
+	if err != nil {
+		log.Error().Err(err).Msgf("Job offer pricing outside solver bounds")
+		return nil, priceRejected(err)
+	}
 	return solverServer.controller.addJobOffer(jobOffer)
 }
 
+// a peer solver forwarding a job offer it could not match
+// the signer must be a trusted peer and the solver the job creator chose
+func (solverServer *solverServer) addForwardedJobOffer(jobOffer data.JobOffer, res corehttp.ResponseWriter, req *corehttp.Request) (*data.JobOfferContainer, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Error().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	if !solverServer.controller.federation.trusts(signerAddress) {
+		return nil, http.HTTPError{
+			Message:    "solver is not a trusted peer",
+			StatusCode: corehttp.StatusForbidden,
+		}
+	}
+	if signerAddress != jobOffer.Services.Solver {
+		return nil, unauthorizedParty("job offer solver", signerAddress)
+	}
+	err = data.CheckJobOffer(jobOffer)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error checking job offer")
+		return nil, err
+	}
+	if !solverServer.controller.web3SDK.SettlesIn(jobOffer.PaymentToken) {
+		return nil, unsupportedPaymentToken(jobOffer.PaymentToken)
+	}
+	err = solverServer.controller.options.Pricing.CheckJobOffer(jobOffer)
+	if err != nil {
+		log.Error().Err(err).Msgf("Job offer pricing outside solver bounds")
+		return nil, priceRejected(err)
+	}
+	return solverServer.controller.addForwardedJobOffer(jobOffer, signerAddress)
+}
+
+// show what would happen to a job offer without adding it
+// this does not need a signature because nothing is stored
+func (solverServer *solverServer) simulateJobOffer(jobOffer data.JobOffer, res corehttp.ResponseWriter, req *corehttp.Request) (*data.MatchSimulation, error) {
+	err := data.CheckJobOffer(jobOffer)
+	if err != nil {
+		return nil, http.HTTPError{
+			Message:    err.Error(),
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	return solverServer.controller.simulateJobOffer(jobOffer)
+}
+
+func (solverServer *solverServer) estimateJobOffer(jobOffer data.JobOffer, res corehttp.ResponseWriter, req *corehttp.Request) (*data.CostEstimate, error) {
+	err := data.CheckJobOffer(jobOffer)
+	if err != nil {
+		return nil, http.HTTPError{
+			Message:    err.Error(),
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	instructionCount := uint64(0)
+	if param := req.URL.Query().Get("instruction_count"); param != "" {
+		instructionCount, err = strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return nil, http.HTTPError{
+				Message:    fmt.Sprintf("invalid instruction_count %q, expected a positive number", param),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+	}
+	return solverServer.controller.estimateJobOffer(jobOffer, instructionCount)
+}
+
 func (solverServer *solverServer) addResourceOffer(resourceOffer data.ResourceOffer, res corehttp.ResponseWriter, req *corehttp.Request) (*data.ResourceOfferContainer, error) {
 	versionHeader, _ := http.GetVersionFromHeaders(req)
 	log.Debug().Msgf("resource provider adding offer with version header %s", versionHeader)
@@ -308,15 +709,44 @@ func (solverServer *solverServer) addResourceOffer(resourceOffer data.ResourceOf
 		log.Error().Err(err).Msgf("error checking signature")
 		return nil, err
 	}
+	typedSignature, err := http.TypedSignature(req)
+	if err != nil {
+		return nil, err
+	}
+	return solverServer.addSignedResourceOffer(resourceOffer, signerAddress, typedSignature)
+}
+
+// a resource provider that is draining or paused takes its offers off the
+// solver, only the ones without a deal go so running deals are untouched
+func (solverServer *solverServer) removeResourceOffers(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.ResourceOfferContainer, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Error().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	return solverServer.controller.removeUnmatchedResourceOffers(signerAddress)
+}
+
+// the checks for a new resource offer shared by the REST and gRPC apis
+func (solverServer *solverServer) addSignedResourceOffer(resourceOffer data.ResourceOffer, signerAddress string, typedSignature []byte) (*data.ResourceOfferContainer, error) {
 	// Only the resource provider can post their resource offer
 	if signerAddress != resourceOffer.ResourceProvider {
-		return nil, fmt.Errorf("resource provider address does not match signer address")
+		return nil, unauthorizedParty("resource provider", signerAddress)
 	}
-	err = data.CheckResourceOffer(resourceOffer)
+	err := data.CheckResourceOffer(resourceOffer)
 	if err != nil {
 		log.Error().Err(err).Msgf("Error checking resource offer")
 		return nil, err
 	}
+	err = solverServer.checkResourceOfferSignature(resourceOffer, typedSignature, signerAddress)
+	if err != nil {
+		return nil, err
+	}
+	err = solverServer.controller.options.Pricing.CheckResourceOffer(resourceOffer, solverServer.controller.modules.priced(resourceOffer))
+	if err != nil {
+		log.Error().Err(err).Msgf("Resource offer pricing outside solver bounds")
+		return nil, priceRejected(err)
+	}
 	return solverServer.controller.addResourceOffer(resourceOffer)
 }
 
@@ -329,7 +759,7 @@ func (solverServer *solverServer) addResult(results data.Result, res corehttp.Re
 		return nil, err
 	}
 	if deal == nil {
-		return nil, fmt.Errorf("deal not found")
+		return nil, dealNotFound(id)
 	}
 	signerAddress, err := http.CheckSignature(req)
 	if err != nil {
@@ -338,7 +768,7 @@ func (solverServer *solverServer) addResult(results data.Result, res corehttp.Re
 	}
 	// Only the resource provider in a deal can add a result
 	if signerAddress != deal.ResourceProvider {
-		return nil, fmt.Errorf("resource provider address does not match signer address")
+		return nil, unauthorizedParty("resource provider", signerAddress)
 	}
 	err = data.CheckResult(results)
 	if err != nil {
@@ -346,7 +776,15 @@ func (solverServer *solverServer) addResult(results data.Result, res corehttp.Re
 		return nil, err
 	}
 	results.DealID = id
-	return solverServer.store.AddResult(results)
+	typedSignature, err := http.TypedSignature(req)
+	if err != nil {
+		return nil, err
+	}
+	err = solverServer.checkResultSignature(results, typedSignature, signerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return solverServer.controller.addResult(results, deal)
 }
 
 /*
@@ -370,7 +808,7 @@ func (solverServer *solverServer) updateTransactionsResourceProvider(payload dat
 	}
 	if deal == nil {
 		log.Error().Err(err).Msgf("deal not found")
-		return nil, fmt.Errorf("deal not found")
+		return nil, dealNotFound(id)
 	}
 	signerAddress, err := http.CheckSignature(req)
 	if err != nil {
@@ -379,7 +817,7 @@ func (solverServer *solverServer) updateTransactionsResourceProvider(payload dat
 	}
 	// Only the resource provider in a deal can update its transactions
 	if signerAddress != deal.ResourceProvider {
-		return nil, fmt.Errorf("resource provider address does not match signer address")
+		return nil, unauthorizedParty("resource provider", signerAddress)
 	}
 	return solverServer.controller.updateDealTransactionsResourceProvider(id, payload)
 }
@@ -394,7 +832,7 @@ func (solverServer *solverServer) updateTransactionsJobCreator(payload data.Deal
 	}
 	if deal == nil {
 		log.Error().Err(err).Msgf("deal not found")
-		return nil, fmt.Errorf("deal not found")
+		return nil, dealNotFound(id)
 	}
 	signerAddress, err := http.CheckSignature(req)
 	if err != nil {
@@ -403,11 +841,38 @@ func (solverServer *solverServer) updateTransactionsJobCreator(payload data.Deal
 	}
 	// Only the job creator in a deal can update its transactions
 	if signerAddress != deal.JobCreator {
-		return nil, fmt.Errorf("job creator address does not match signer address")
+		return nil, unauthorizedParty("job creator", signerAddress)
 	}
 	return solverServer.controller.updateDealTransactionsJobCreator(id, payload)
 }
 
+func (solverServer *solverServer) updateDealSecrets(payload data.DealSecrets, res corehttp.ResponseWriter, req *corehttp.Request) (*data.DealContainer, error) {
+	vars := mux.Vars(req)
+	id := vars["id"]
+	deal, err := solverServer.store.GetDeal(id)
+	if err != nil {
+		log.Error().Err(err).Msgf("error loading deal")
+		return nil, err
+	}
+	if deal == nil {
+		log.Error().Err(err).Msgf("deal not found")
+		return nil, dealNotFound(id)
+	}
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Error().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	// Only the job creator in a deal has the secrets for its job
+	if signerAddress != deal.JobCreator {
+		return nil, unauthorizedParty("job creator", signerAddress)
+	}
+	if payload.Secrets == "" {
+		return nil, fmt.Errorf("no secrets posted for deal %s", id)
+	}
+	return solverServer.controller.updateDealSecrets(id, payload.Secrets)
+}
+
 func (solverServer *solverServer) updateTransactionsMediator(payload data.DealTransactionsMediator, res corehttp.ResponseWriter, req *corehttp.Request) (*data.DealContainer, error) {
 	vars := mux.Vars(req)
 	id := vars["id"]
@@ -418,7 +883,7 @@ func (solverServer *solverServer) updateTransactionsMediator(payload data.DealTr
 	}
 	if deal == nil {
 		log.Error().Err(err).Msgf("deal not found")
-		return nil, fmt.Errorf("deal not found")
+		return nil, dealNotFound(id)
 	}
 	signerAddress, err := http.CheckSignature(req)
 	if err != nil {
@@ -427,7 +892,7 @@ func (solverServer *solverServer) updateTransactionsMediator(payload data.DealTr
 	}
 	// Only the mediator in a deal can update its transactions
 	if signerAddress != deal.Mediator {
-		return nil, fmt.Errorf("job creator address does not match mediator address")
+		return nil, unauthorizedParty("mediator", signerAddress)
 	}
 	return solverServer.controller.updateDealTransactionsMediator(id, payload)
 }
@@ -458,10 +923,8 @@ func (solverServer *solverServer) downloadFiles(res corehttp.ResponseWriter, req
 			}
 		}
 		if deal == nil {
-			return &http.HTTPError{
-				Message:    err.Error(),
-				StatusCode: corehttp.StatusNotFound,
-			}
+			notFound := dealNotFound(id)
+			return &notFound
 		}
 
 		signerAddress, err := http.CheckSignature(req)
@@ -473,11 +936,13 @@ func (solverServer *solverServer) downloadFiles(res corehttp.ResponseWriter, req
 			}
 		}
 		// Only the job creator in a deal can download job outputs
-		if signerAddress != deal.JobCreator {
+		// or the solver that forwarded us the job offer on their behalf
+		if signerAddress != deal.JobCreator && !solverServer.isJobOfferOrigin(deal.JobOffer, signerAddress) {
 			log.Error().Err(err).Msgf("job creator address does not match signer address")
 			return &http.HTTPError{
 				Message:    errors.New("not authorized").Error(),
 				StatusCode: corehttp.StatusUnauthorized,
+				Code:       apierrors.UnauthorizedParty,
 			}
 		}
 
@@ -545,11 +1010,19 @@ func (solverServer *solverServer) downloadFiles(res corehttp.ResponseWriter, req
 
 	if err != nil {
 		log.Ctx(req.Context()).Error().Msgf("error for route: %s", err.Error())
-		corehttp.Error(res, err.Error(), err.StatusCode)
+		http.WriteError(res, req, *err)
 		return
 	}
 }
 
+func (solverServer *solverServer) isJobOfferOrigin(id string, address string) bool {
+	jobOffer, err := solverServer.store.GetJobOffer(id)
+	if err != nil || jobOffer == nil {
+		return false
+	}
+	return jobOffer.Origin != "" && jobOffer.Origin == address
+}
+
 func (solverServer *solverServer) uploadFiles(res corehttp.ResponseWriter, req *corehttp.Request) {
 	vars := mux.Vars(req)
 	id := vars["id"]
@@ -562,7 +1035,7 @@ func (solverServer *solverServer) uploadFiles(res corehttp.ResponseWriter, req *
 		}
 		if deal == nil {
 			log.Error().Msgf("deal not found")
-			return err
+			return dealNotFound(id)
 		}
 		signerAddress, err := http.CheckSignature(req)
 		if err != nil {
@@ -571,7 +1044,7 @@ func (solverServer *solverServer) uploadFiles(res corehttp.ResponseWriter, req *
 		}
 		// Only the resource provider in a deal can upload job outputs
 		if signerAddress != deal.ResourceProvider {
-			return fmt.Errorf("resource provider address does not match signer address")
+			return unauthorizedParty("resource provider", signerAddress)
 		}
 
 		// Get the directory path
@@ -603,6 +1076,8 @@ func (solverServer *solverServer) uploadFiles(res corehttp.ResponseWriter, req *
 		// Copy the data
 		_, err = io.Copy(f, req.Body)
 		if err != nil {
+			// do not leave half an upload behind
+			os.Remove(filePath)
 			return err
 		}
 
@@ -611,7 +1086,12 @@ func (solverServer *solverServer) uploadFiles(res corehttp.ResponseWriter, req *
 
 	if err != nil {
 		log.Ctx(req.Context()).Error().Msgf("error for route: %s", err.Error())
-		corehttp.Error(res, err.Error(), corehttp.StatusInternalServerError)
+		var maxBytesError *corehttp.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.WriteErrorMessage(res, req, fmt.Sprintf("upload is larger than %d bytes", maxBytesError.Limit), corehttp.StatusRequestEntityTooLarge)
+			return
+		}
+		http.WriteError(res, req, err)
 		return
 	}
 
@@ -620,7 +1100,7 @@ func (solverServer *solverServer) uploadFiles(res corehttp.ResponseWriter, req *
 	})
 	if err != nil {
 		log.Ctx(req.Context()).Error().Msgf("error for json encoding: %s", err.Error())
-		corehttp.Error(res, err.Error(), corehttp.StatusInternalServerError)
+		http.WriteErrorMessage(res, req, err.Error(), corehttp.StatusInternalServerError)
 		return
 	}
 }
@@ -660,3 +1140,14 @@ func (solverServer *solverServer) getValidationToken(res corehttp.ResponseWriter
 	// Respond with the JWT
 	return &http.ValidationToken{JWT: tokenString}, nil
 }
+
+func (solverServer *solverServer) getGasEstimate(res corehttp.ResponseWriter, req *corehttp.Request) (*web3.FeeEstimate, error) {
+	estimate := solverServer.controller.web3SDK.GasOracle.Estimate()
+	if estimate == nil {
+		return nil, http.HTTPError{
+			Message:    "no gas fee estimate yet",
+			StatusCode: corehttp.StatusServiceUnavailable,
+		}
+	}
+	return estimate, nil
+}
@@ -0,0 +1,60 @@
+package solver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/signatures"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+)
+
+type SolverSignatureOptions struct {
+	// turn away offers and results sent without an EIP-712 signature,
+	// clients from before it was added only sign the request
+	RequireTyped bool
+}
+
+// the typed signature must be the signer's over the typed data for this
+// network, one that is missing is let through unless RequireTyped is set
+//
+// a smart contract wallet's signature is checked by the wallet itself
+func (solverServer *solverServer) checkTypedSignature(typedData apitypes.TypedData, signature []byte, signerAddress string) error {
+	if signature == nil {
+		if solverServer.controller.options.Signatures.RequireTyped {
+			return invalidTypedSignature("missing typed signature", signerAddress)
+		}
+		return nil
+	}
+	err := signatures.Default().VerifyTypedData(context.Background(), common.HexToAddress(signerAddress), typedData, signature)
+	if errors.Is(err, signatures.ErrInvalidSignature) {
+		return invalidTypedSignature("typed signature was not made by the signer", signerAddress)
+	}
+	if err != nil {
+		return invalidTypedSignature(err.Error(), signerAddress)
+	}
+	return nil
+}
+
+func (solverServer *solverServer) checkJobOfferSignature(jobOffer data.JobOffer, signature []byte, signerAddress string) error {
+	typedData, err := web3.JobOfferTypedData(solverServer.controller.web3SDK.TypedDataDomain(), jobOffer)
+	if err != nil {
+		return err
+	}
+	return solverServer.checkTypedSignature(typedData, signature, signerAddress)
+}
+
+func (solverServer *solverServer) checkResourceOfferSignature(resourceOffer data.ResourceOffer, signature []byte, signerAddress string) error {
+	typedData, err := web3.ResourceOfferTypedData(solverServer.controller.web3SDK.TypedDataDomain(), resourceOffer)
+	if err != nil {
+		return err
+	}
+	return solverServer.checkTypedSignature(typedData, signature, signerAddress)
+}
+
+func (solverServer *solverServer) checkResultSignature(result data.Result, signature []byte, signerAddress string) error {
+	typedData := web3.ResultTypedData(solverServer.controller.web3SDK.TypedDataDomain(), result)
+	return solverServer.checkTypedSignature(typedData, signature, signerAddress)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/matcher"
 	"github.com/lilypad-tech/lilypad/pkg/solver/store"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3"
@@ -14,13 +15,30 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+type SolverLoopOptions struct {
+	// how many seconds between matching passes when nothing has happened
+	Interval int
+	// how many milliseconds to wait after an offer arrives before matching
+	// so a burst of offers is matched in a single pass
+	Debounce int
+}
+
 type SolverOptions struct {
-	Server    http.ServerOptions
-	Store     store.StoreOptions
-	Web3      web3.Web3Options
-	Services  data.ServiceConfig
-	Telemetry system.TelemetryOptions
-	Metrics   system.MetricsOptions
+	Loop       SolverLoopOptions
+	Pricing    SolverPricingOptions
+	Federation SolverFederationOptions
+	Reaper     SolverReaperOptions
+	Indexer    SolverIndexerOptions
+	Webhooks   SolverWebhookOptions
+	Audit      SolverAuditOptions
+	Signatures SolverSignatureOptions
+	Server     http.ServerOptions
+	Store      store.StoreOptions
+	Matcher    matcher.MatcherOptions
+	Web3       web3.Web3Options
+	Services   data.ServiceConfig
+	Telemetry  system.TelemetryOptions
+	Metrics    system.MetricsOptions
 }
 
 type Solver struct {
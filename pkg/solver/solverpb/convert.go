@@ -0,0 +1,376 @@
+package solverpb
+
+import (
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// conversions between the protobuf messages and the data types the rest of
+// lilypad uses, optional fields that are nil on one side stay nil on the other
+
+func FromMachineSpec(spec data.MachineSpec) *MachineSpec {
+	gpus := make([]*GPUSpec, 0, len(spec.GPUs))
+	for _, gpu := range spec.GPUs {
+		gpus = append(gpus, &GPUSpec{
+			Name:   gpu.Name,
+			Vendor: gpu.Vendor,
+			Vram:   int64(gpu.VRAM),
+			Driver: gpu.Driver,
+			Cuda:   gpu.CUDA,
+		})
+	}
+	return &MachineSpec{
+		Gpu:  int64(spec.GPU),
+		Gpus: gpus,
+		Cpu:  int64(spec.CPU),
+		Ram:  int64(spec.RAM),
+		Disk: int64(spec.Disk),
+	}
+}
+
+func ToMachineSpec(spec *MachineSpec) data.MachineSpec {
+	gpus := []data.GPUSpec{}
+	for _, gpu := range spec.GetGpus() {
+		gpus = append(gpus, data.GPUSpec{
+			Name:   gpu.GetName(),
+			Vendor: gpu.GetVendor(),
+			VRAM:   int(gpu.GetVram()),
+			Driver: gpu.GetDriver(),
+			CUDA:   gpu.GetCuda(),
+		})
+	}
+	return data.MachineSpec{
+		GPU:  int(spec.GetGpu()),
+		GPUs: gpus,
+		CPU:  int(spec.GetCpu()),
+		RAM:  int(spec.GetRam()),
+		Disk: int(spec.GetDisk()),
+	}
+}
+
+func FromDealPricing(pricing data.DealPricing) *DealPricing {
+	return &DealPricing{
+		InstructionPrice:          pricing.InstructionPrice,
+		PaymentCollateral:         pricing.PaymentCollateral,
+		ResultsCollateralMultiple: pricing.ResultsCollateralMultiple,
+		MediationFee:              pricing.MediationFee,
+	}
+}
+
+func ToDealPricing(pricing *DealPricing) data.DealPricing {
+	return data.DealPricing{
+		InstructionPrice:          pricing.GetInstructionPrice(),
+		PaymentCollateral:         pricing.GetPaymentCollateral(),
+		ResultsCollateralMultiple: pricing.GetResultsCollateralMultiple(),
+		MediationFee:              pricing.GetMediationFee(),
+	}
+}
+
+func fromDealTimeout(timeout data.DealTimeout) *DealTimeout {
+	return &DealTimeout{
+		Timeout:    timeout.Timeout,
+		Collateral: timeout.Collateral,
+	}
+}
+
+func toDealTimeout(timeout *DealTimeout) data.DealTimeout {
+	return data.DealTimeout{
+		Timeout:    timeout.GetTimeout(),
+		Collateral: timeout.GetCollateral(),
+	}
+}
+
+func FromDealTimeouts(timeouts data.DealTimeouts) *DealTimeouts {
+	return &DealTimeouts{
+		Agree:          fromDealTimeout(timeouts.Agree),
+		SubmitResults:  fromDealTimeout(timeouts.SubmitResults),
+		JudgeResults:   fromDealTimeout(timeouts.JudgeResults),
+		MediateResults: fromDealTimeout(timeouts.MediateResults),
+	}
+}
+
+func ToDealTimeouts(timeouts *DealTimeouts) data.DealTimeouts {
+	return data.DealTimeouts{
+		Agree:          toDealTimeout(timeouts.GetAgree()),
+		SubmitResults:  toDealTimeout(timeouts.GetSubmitResults()),
+		JudgeResults:   toDealTimeout(timeouts.GetJudgeResults()),
+		MediateResults: toDealTimeout(timeouts.GetMediateResults()),
+	}
+}
+
+func fromServiceConfig(services data.ServiceConfig) *ServiceConfig {
+	return &ServiceConfig{
+		Solver:   services.Solver,
+		Mediator: services.Mediator,
+		ApiHost:  services.APIHost,
+	}
+}
+
+func toServiceConfig(services *ServiceConfig) data.ServiceConfig {
+	return data.ServiceConfig{
+		Solver:   services.GetSolver(),
+		Mediator: services.GetMediator(),
+		APIHost:  services.GetApiHost(),
+	}
+}
+
+func FromJobOffer(jobOffer data.JobOffer) *JobOffer {
+	ret := &JobOffer{
+		Id:         jobOffer.ID,
+		CreatedAt:  int64(jobOffer.CreatedAt),
+		JobCreator: jobOffer.JobCreator,
+		Module: &ModuleConfig{
+			Name: jobOffer.Module.Name,
+			Repo: jobOffer.Module.Repo,
+			Hash: jobOffer.Module.Hash,
+			Path: jobOffer.Module.Path,
+		},
+		Spec:           FromMachineSpec(jobOffer.Spec),
+		Inputs:         jobOffer.Inputs,
+		Mode:           string(jobOffer.Mode),
+		Pricing:        FromDealPricing(jobOffer.Pricing),
+		Timeouts:       FromDealTimeouts(jobOffer.Timeouts),
+		TrustedParties: fromServiceConfig(jobOffer.Services),
+		Target:         &TargetConfig{Address: jobOffer.Target.Address},
+		MaxDeferral:    int64(jobOffer.MaxDeferral),
+		Series:         jobOffer.Series,
+	}
+	if jobOffer.Locality != nil {
+		ret.Locality = &LocalityPreference{
+			Regions: jobOffer.Locality.Regions,
+			Strict:  jobOffer.Locality.Strict,
+		}
+	}
+	for _, requirement := range jobOffer.Requirements {
+		ret.Requirements = append(ret.Requirements, &AttributeRequirement{
+			Key:      requirement.Key,
+			Operator: string(requirement.Operator),
+			Value:    requirement.Value,
+		})
+	}
+	if jobOffer.Mediation != nil {
+		ret.Mediation = &MediationPolicy{
+			AlwaysCheckFirst:       int64(jobOffer.Mediation.AlwaysCheckFirst),
+			CheckResultsPercentage: int64(jobOffer.Mediation.CheckResultsPercentage),
+		}
+	}
+	return ret
+}
+
+func ToJobOffer(jobOffer *JobOffer) data.JobOffer {
+	ret := data.JobOffer{
+		ID:         jobOffer.GetId(),
+		CreatedAt:  int(jobOffer.GetCreatedAt()),
+		JobCreator: jobOffer.GetJobCreator(),
+		Module: data.ModuleConfig{
+			Name: jobOffer.GetModule().GetName(),
+			Repo: jobOffer.GetModule().GetRepo(),
+			Hash: jobOffer.GetModule().GetHash(),
+			Path: jobOffer.GetModule().GetPath(),
+		},
+		Spec:        ToMachineSpec(jobOffer.GetSpec()),
+		Inputs:      jobOffer.GetInputs(),
+		Mode:        data.PricingMode(jobOffer.GetMode()),
+		Pricing:     ToDealPricing(jobOffer.GetPricing()),
+		Timeouts:    ToDealTimeouts(jobOffer.GetTimeouts()),
+		Services:    toServiceConfig(jobOffer.GetTrustedParties()),
+		Target:      data.TargetConfig{Address: jobOffer.GetTarget().GetAddress()},
+		MaxDeferral: int(jobOffer.GetMaxDeferral()),
+		Series:      jobOffer.GetSeries(),
+	}
+	if ret.Inputs == nil {
+		ret.Inputs = map[string]string{}
+	}
+	if jobOffer.GetLocality() != nil {
+		ret.Locality = &data.LocalityPreference{
+			Regions: jobOffer.GetLocality().GetRegions(),
+			Strict:  jobOffer.GetLocality().GetStrict(),
+		}
+	}
+	for _, requirement := range jobOffer.GetRequirements() {
+		ret.Requirements = append(ret.Requirements, data.AttributeRequirement{
+			Key:      requirement.GetKey(),
+			Operator: data.RequirementOperator(requirement.GetOperator()),
+			Value:    requirement.GetValue(),
+		})
+	}
+	if jobOffer.GetMediation() != nil {
+		ret.Mediation = &data.MediationPolicy{
+			AlwaysCheckFirst:       int(jobOffer.GetMediation().GetAlwaysCheckFirst()),
+			CheckResultsPercentage: int(jobOffer.GetMediation().GetCheckResultsPercentage()),
+		}
+	}
+	return ret
+}
+
+func FromJobOfferContainer(jobOffer data.JobOfferContainer) *JobOfferContainer {
+	return &JobOfferContainer{
+		Id:          jobOffer.ID,
+		DealId:      jobOffer.DealID,
+		JobCreator:  jobOffer.JobCreator,
+		State:       uint32(jobOffer.State),
+		JobOffer:    FromJobOffer(jobOffer.JobOffer),
+		Origin:      jobOffer.Origin,
+		ForwardedTo: jobOffer.ForwardedTo,
+	}
+}
+
+func FromResourceOffer(resourceOffer data.ResourceOffer) *ResourceOffer {
+	ret := &ResourceOffer{
+		Id:               resourceOffer.ID,
+		CreatedAt:        int64(resourceOffer.CreatedAt),
+		ResourceProvider: resourceOffer.ResourceProvider,
+		Index:            int64(resourceOffer.Index),
+		Spec:             FromMachineSpec(resourceOffer.Spec),
+		Modules:          resourceOffer.Modules,
+		Mode:             string(resourceOffer.Mode),
+		DefaultPricing:   FromDealPricing(resourceOffer.DefaultPricing),
+		DefaultTimeouts:  FromDealTimeouts(resourceOffer.DefaultTimeouts),
+		ModulePricing:    map[string]*DealPricing{},
+		ModuleTimeouts:   map[string]*DealTimeouts{},
+		TrustedParties:   fromServiceConfig(resourceOffer.Services),
+		Region:           resourceOffer.Region,
+		Attributes:       resourceOffer.Attributes,
+		Packing:          resourceOffer.Packing,
+	}
+	for module, pricing := range resourceOffer.ModulePricing {
+		ret.ModulePricing[module] = FromDealPricing(pricing)
+	}
+	for module, timeouts := range resourceOffer.ModuleTimeouts {
+		ret.ModuleTimeouts[module] = FromDealTimeouts(timeouts)
+	}
+	for _, window := range resourceOffer.Availability {
+		days := make([]int32, 0, len(window.Days))
+		for _, day := range window.Days {
+			days = append(days, int32(day))
+		}
+		ret.Availability = append(ret.Availability, &AvailabilityWindow{
+			Days:     days,
+			Start:    window.Start,
+			End:      window.End,
+			Timezone: window.Timezone,
+		})
+	}
+	return ret
+}
+
+func ToResourceOffer(resourceOffer *ResourceOffer) data.ResourceOffer {
+	ret := data.ResourceOffer{
+		ID:               resourceOffer.GetId(),
+		CreatedAt:        int(resourceOffer.GetCreatedAt()),
+		ResourceProvider: resourceOffer.GetResourceProvider(),
+		Index:            int(resourceOffer.GetIndex()),
+		Spec:             ToMachineSpec(resourceOffer.GetSpec()),
+		Modules:          resourceOffer.GetModules(),
+		Mode:             data.PricingMode(resourceOffer.GetMode()),
+		DefaultPricing:   ToDealPricing(resourceOffer.GetDefaultPricing()),
+		DefaultTimeouts:  ToDealTimeouts(resourceOffer.GetDefaultTimeouts()),
+		ModulePricing:    map[string]data.DealPricing{},
+		ModuleTimeouts:   map[string]data.DealTimeouts{},
+		Services:         toServiceConfig(resourceOffer.GetTrustedParties()),
+		Region:           resourceOffer.GetRegion(),
+		Attributes:       resourceOffer.GetAttributes(),
+		Packing:          resourceOffer.GetPacking(),
+	}
+	if ret.Modules == nil {
+		ret.Modules = []string{}
+	}
+	for module, pricing := range resourceOffer.GetModulePricing() {
+		ret.ModulePricing[module] = ToDealPricing(pricing)
+	}
+	for module, timeouts := range resourceOffer.GetModuleTimeouts() {
+		ret.ModuleTimeouts[module] = ToDealTimeouts(timeouts)
+	}
+	for _, window := range resourceOffer.GetAvailability() {
+		days := make([]time.Weekday, 0, len(window.GetDays()))
+		for _, day := range window.GetDays() {
+			days = append(days, time.Weekday(day))
+		}
+		ret.Availability = append(ret.Availability, data.AvailabilityWindow{
+			Days:     days,
+			Start:    window.GetStart(),
+			End:      window.GetEnd(),
+			Timezone: window.GetTimezone(),
+		})
+	}
+	return ret
+}
+
+func FromResourceOfferContainer(resourceOffer data.ResourceOfferContainer) *ResourceOfferContainer {
+	return &ResourceOfferContainer{
+		Id:               resourceOffer.ID,
+		DealId:           resourceOffer.DealID,
+		ResourceProvider: resourceOffer.ResourceProvider,
+		State:            uint32(resourceOffer.State),
+		ResourceOffer:    FromResourceOffer(resourceOffer.ResourceOffer),
+	}
+}
+
+func FromDeal(deal data.Deal) *Deal {
+	return &Deal{
+		Id: deal.ID,
+		Members: &DealMembers{
+			Solver:           deal.Members.Solver,
+			JobCreator:       deal.Members.JobCreator,
+			ResourceProvider: deal.Members.ResourceProvider,
+			Mediators:        deal.Members.Mediators,
+		},
+		Pricing:       FromDealPricing(deal.Pricing),
+		Timeouts:      FromDealTimeouts(deal.Timeouts),
+		JobOffer:      FromJobOffer(deal.JobOffer),
+		ResourceOffer: FromResourceOffer(deal.ResourceOffer),
+	}
+}
+
+func FromDealContainer(deal data.DealContainer) *DealContainer {
+	transactions := deal.Transactions
+	return &DealContainer{
+		Id:               deal.ID,
+		JobCreator:       deal.JobCreator,
+		ResourceProvider: deal.ResourceProvider,
+		JobOffer:         deal.JobOffer,
+		ResourceOffer:    deal.ResourceOffer,
+		State:            uint32(deal.State),
+		Deal:             FromDeal(deal.Deal),
+		Transactions: &DealTransactions{
+			ResourceProvider: &DealTransactionsResourceProvider{
+				Agree:                transactions.ResourceProvider.Agree,
+				AddResult:            transactions.ResourceProvider.AddResult,
+				TimeoutAgree:         transactions.ResourceProvider.TimeoutAgree,
+				TimeoutJudgeResult:   transactions.ResourceProvider.TimeoutJudgeResult,
+				TimeoutMediateResult: transactions.ResourceProvider.TimeoutMediateResult,
+			},
+			JobCreator: &DealTransactionsJobCreator{
+				Agree:                transactions.JobCreator.Agree,
+				AcceptResult:         transactions.JobCreator.AcceptResult,
+				CheckResult:          transactions.JobCreator.CheckResult,
+				TimeoutAgree:         transactions.JobCreator.TimeoutAgree,
+				TimeoutSubmitResult:  transactions.JobCreator.TimeoutSubmitResult,
+				TimeoutMediateResult: transactions.JobCreator.TimeoutMediateResult,
+			},
+			Mediator: &DealTransactionsMediator{
+				MediationAcceptResult: transactions.Mediator.MediationAcceptResult,
+				MediationRejectResult: transactions.Mediator.MediationRejectResult,
+			},
+		},
+		Mediator: deal.Mediator,
+	}
+}
+
+func FromResult(result data.Result) *Result {
+	ret := &Result{
+		Id:               result.ID,
+		DealId:           result.DealID,
+		DataId:           result.DataID,
+		Error:            result.Error,
+		InstructionCount: result.InstructionCount,
+	}
+	if result.Usage != nil {
+		ret.CpuTime = result.Usage.CPUTime
+		ret.PeakMemory = int64(result.Usage.PeakMemory)
+		ret.GpuUtilization = result.Usage.GPUUtilization
+		ret.Duration = result.Usage.Duration
+	}
+	return ret
+}
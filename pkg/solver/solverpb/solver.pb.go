@@ -0,0 +1,3241 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        (unknown)
+// source: solver.proto
+
+package solverpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GPUSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Vendor string `protobuf:"bytes,2,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Vram   int64  `protobuf:"varint,3,opt,name=vram,proto3" json:"vram,omitempty"`
+	// Set for the GPUs a resource provider detected
+	Driver string `protobuf:"bytes,4,opt,name=driver,proto3" json:"driver,omitempty"`
+	Cuda   string `protobuf:"bytes,5,opt,name=cuda,proto3" json:"cuda,omitempty"`
+}
+
+func (x *GPUSpec) Reset() {
+	*x = GPUSpec{}
+	mi := &file_solver_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPUSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUSpec) ProtoMessage() {}
+
+func (x *GPUSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUSpec.ProtoReflect.Descriptor instead.
+func (*GPUSpec) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GPUSpec) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GPUSpec) GetVendor() string {
+	if x != nil {
+		return x.Vendor
+	}
+	return ""
+}
+
+func (x *GPUSpec) GetVram() int64 {
+	if x != nil {
+		return x.Vram
+	}
+	return 0
+}
+
+func (x *GPUSpec) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *GPUSpec) GetCuda() string {
+	if x != nil {
+		return x.Cuda
+	}
+	return ""
+}
+
+type MachineSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Milli-GPU
+	Gpu  int64      `protobuf:"varint,1,opt,name=gpu,proto3" json:"gpu,omitempty"`
+	Gpus []*GPUSpec `protobuf:"bytes,2,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	// Milli-CPU
+	Cpu int64 `protobuf:"varint,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	// Megabytes
+	Ram  int64 `protobuf:"varint,4,opt,name=ram,proto3" json:"ram,omitempty"`
+	Disk int64 `protobuf:"varint,5,opt,name=disk,proto3" json:"disk,omitempty"`
+}
+
+func (x *MachineSpec) Reset() {
+	*x = MachineSpec{}
+	mi := &file_solver_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineSpec) ProtoMessage() {}
+
+func (x *MachineSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineSpec.ProtoReflect.Descriptor instead.
+func (*MachineSpec) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MachineSpec) GetGpu() int64 {
+	if x != nil {
+		return x.Gpu
+	}
+	return 0
+}
+
+func (x *MachineSpec) GetGpus() []*GPUSpec {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *MachineSpec) GetCpu() int64 {
+	if x != nil {
+		return x.Cpu
+	}
+	return 0
+}
+
+func (x *MachineSpec) GetRam() int64 {
+	if x != nil {
+		return x.Ram
+	}
+	return 0
+}
+
+func (x *MachineSpec) GetDisk() int64 {
+	if x != nil {
+		return x.Disk
+	}
+	return 0
+}
+
+type ModuleConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Repo string `protobuf:"bytes,2,opt,name=repo,proto3" json:"repo,omitempty"`
+	Hash string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	Path string `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *ModuleConfig) Reset() {
+	*x = ModuleConfig{}
+	mi := &file_solver_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleConfig) ProtoMessage() {}
+
+func (x *ModuleConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleConfig.ProtoReflect.Descriptor instead.
+func (*ModuleConfig) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ModuleConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModuleConfig) GetRepo() string {
+	if x != nil {
+		return x.Repo
+	}
+	return ""
+}
+
+func (x *ModuleConfig) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ModuleConfig) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type DealPricing struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InstructionPrice          uint64 `protobuf:"varint,1,opt,name=instruction_price,json=instructionPrice,proto3" json:"instruction_price,omitempty"`
+	PaymentCollateral         uint64 `protobuf:"varint,2,opt,name=payment_collateral,json=paymentCollateral,proto3" json:"payment_collateral,omitempty"`
+	ResultsCollateralMultiple uint64 `protobuf:"varint,3,opt,name=results_collateral_multiple,json=resultsCollateralMultiple,proto3" json:"results_collateral_multiple,omitempty"`
+	MediationFee              uint64 `protobuf:"varint,4,opt,name=mediation_fee,json=mediationFee,proto3" json:"mediation_fee,omitempty"`
+}
+
+func (x *DealPricing) Reset() {
+	*x = DealPricing{}
+	mi := &file_solver_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealPricing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealPricing) ProtoMessage() {}
+
+func (x *DealPricing) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealPricing.ProtoReflect.Descriptor instead.
+func (*DealPricing) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DealPricing) GetInstructionPrice() uint64 {
+	if x != nil {
+		return x.InstructionPrice
+	}
+	return 0
+}
+
+func (x *DealPricing) GetPaymentCollateral() uint64 {
+	if x != nil {
+		return x.PaymentCollateral
+	}
+	return 0
+}
+
+func (x *DealPricing) GetResultsCollateralMultiple() uint64 {
+	if x != nil {
+		return x.ResultsCollateralMultiple
+	}
+	return 0
+}
+
+func (x *DealPricing) GetMediationFee() uint64 {
+	if x != nil {
+		return x.MediationFee
+	}
+	return 0
+}
+
+type DealTimeout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timeout    uint64 `protobuf:"varint,1,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Collateral uint64 `protobuf:"varint,2,opt,name=collateral,proto3" json:"collateral,omitempty"`
+}
+
+func (x *DealTimeout) Reset() {
+	*x = DealTimeout{}
+	mi := &file_solver_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealTimeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealTimeout) ProtoMessage() {}
+
+func (x *DealTimeout) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealTimeout.ProtoReflect.Descriptor instead.
+func (*DealTimeout) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DealTimeout) GetTimeout() uint64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *DealTimeout) GetCollateral() uint64 {
+	if x != nil {
+		return x.Collateral
+	}
+	return 0
+}
+
+type DealTimeouts struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Agree          *DealTimeout `protobuf:"bytes,1,opt,name=agree,proto3" json:"agree,omitempty"`
+	SubmitResults  *DealTimeout `protobuf:"bytes,2,opt,name=submit_results,json=submitResults,proto3" json:"submit_results,omitempty"`
+	JudgeResults   *DealTimeout `protobuf:"bytes,3,opt,name=judge_results,json=judgeResults,proto3" json:"judge_results,omitempty"`
+	MediateResults *DealTimeout `protobuf:"bytes,4,opt,name=mediate_results,json=mediateResults,proto3" json:"mediate_results,omitempty"`
+}
+
+func (x *DealTimeouts) Reset() {
+	*x = DealTimeouts{}
+	mi := &file_solver_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealTimeouts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealTimeouts) ProtoMessage() {}
+
+func (x *DealTimeouts) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealTimeouts.ProtoReflect.Descriptor instead.
+func (*DealTimeouts) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DealTimeouts) GetAgree() *DealTimeout {
+	if x != nil {
+		return x.Agree
+	}
+	return nil
+}
+
+func (x *DealTimeouts) GetSubmitResults() *DealTimeout {
+	if x != nil {
+		return x.SubmitResults
+	}
+	return nil
+}
+
+func (x *DealTimeouts) GetJudgeResults() *DealTimeout {
+	if x != nil {
+		return x.JudgeResults
+	}
+	return nil
+}
+
+func (x *DealTimeouts) GetMediateResults() *DealTimeout {
+	if x != nil {
+		return x.MediateResults
+	}
+	return nil
+}
+
+type ServiceConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Solver   string   `protobuf:"bytes,1,opt,name=solver,proto3" json:"solver,omitempty"`
+	Mediator []string `protobuf:"bytes,2,rep,name=mediator,proto3" json:"mediator,omitempty"`
+	ApiHost  string   `protobuf:"bytes,3,opt,name=api_host,json=apiHost,proto3" json:"api_host,omitempty"`
+}
+
+func (x *ServiceConfig) Reset() {
+	*x = ServiceConfig{}
+	mi := &file_solver_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceConfig) ProtoMessage() {}
+
+func (x *ServiceConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceConfig.ProtoReflect.Descriptor instead.
+func (*ServiceConfig) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ServiceConfig) GetSolver() string {
+	if x != nil {
+		return x.Solver
+	}
+	return ""
+}
+
+func (x *ServiceConfig) GetMediator() []string {
+	if x != nil {
+		return x.Mediator
+	}
+	return nil
+}
+
+func (x *ServiceConfig) GetApiHost() string {
+	if x != nil {
+		return x.ApiHost
+	}
+	return ""
+}
+
+type TargetConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *TargetConfig) Reset() {
+	*x = TargetConfig{}
+	mi := &file_solver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetConfig) ProtoMessage() {}
+
+func (x *TargetConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetConfig.ProtoReflect.Descriptor instead.
+func (*TargetConfig) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TargetConfig) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type LocalityPreference struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Regions []string `protobuf:"bytes,1,rep,name=regions,proto3" json:"regions,omitempty"`
+	Strict  bool     `protobuf:"varint,2,opt,name=strict,proto3" json:"strict,omitempty"`
+}
+
+func (x *LocalityPreference) Reset() {
+	*x = LocalityPreference{}
+	mi := &file_solver_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocalityPreference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalityPreference) ProtoMessage() {}
+
+func (x *LocalityPreference) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalityPreference.ProtoReflect.Descriptor instead.
+func (*LocalityPreference) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LocalityPreference) GetRegions() []string {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+func (x *LocalityPreference) GetStrict() bool {
+	if x != nil {
+		return x.Strict
+	}
+	return false
+}
+
+type AttributeRequirement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Operator string `protobuf:"bytes,2,opt,name=operator,proto3" json:"operator,omitempty"`
+	Value    string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *AttributeRequirement) Reset() {
+	*x = AttributeRequirement{}
+	mi := &file_solver_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeRequirement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeRequirement) ProtoMessage() {}
+
+func (x *AttributeRequirement) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeRequirement.ProtoReflect.Descriptor instead.
+func (*AttributeRequirement) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AttributeRequirement) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AttributeRequirement) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *AttributeRequirement) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type MediationPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AlwaysCheckFirst       int64 `protobuf:"varint,1,opt,name=always_check_first,json=alwaysCheckFirst,proto3" json:"always_check_first,omitempty"`
+	CheckResultsPercentage int64 `protobuf:"varint,2,opt,name=check_results_percentage,json=checkResultsPercentage,proto3" json:"check_results_percentage,omitempty"`
+}
+
+func (x *MediationPolicy) Reset() {
+	*x = MediationPolicy{}
+	mi := &file_solver_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MediationPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediationPolicy) ProtoMessage() {}
+
+func (x *MediationPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediationPolicy.ProtoReflect.Descriptor instead.
+func (*MediationPolicy) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MediationPolicy) GetAlwaysCheckFirst() int64 {
+	if x != nil {
+		return x.AlwaysCheckFirst
+	}
+	return 0
+}
+
+func (x *MediationPolicy) GetCheckResultsPercentage() int64 {
+	if x != nil {
+		return x.CheckResultsPercentage
+	}
+	return 0
+}
+
+type JobOffer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Milliseconds since the epoch.
+	CreatedAt  int64             `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	JobCreator string            `protobuf:"bytes,3,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	Module     *ModuleConfig     `protobuf:"bytes,4,opt,name=module,proto3" json:"module,omitempty"`
+	Spec       *MachineSpec      `protobuf:"bytes,5,opt,name=spec,proto3" json:"spec,omitempty"`
+	Inputs     map[string]string `protobuf:"bytes,6,rep,name=inputs,proto3" json:"inputs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// MarketPrice or FixedPrice
+	Mode           string                  `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	Pricing        *DealPricing            `protobuf:"bytes,8,opt,name=pricing,proto3" json:"pricing,omitempty"`
+	Timeouts       *DealTimeouts           `protobuf:"bytes,9,opt,name=timeouts,proto3" json:"timeouts,omitempty"`
+	TrustedParties *ServiceConfig          `protobuf:"bytes,10,opt,name=trusted_parties,json=trustedParties,proto3" json:"trusted_parties,omitempty"`
+	Target         *TargetConfig           `protobuf:"bytes,11,opt,name=target,proto3" json:"target,omitempty"`
+	Locality       *LocalityPreference     `protobuf:"bytes,12,opt,name=locality,proto3" json:"locality,omitempty"`
+	Requirements   []*AttributeRequirement `protobuf:"bytes,13,rep,name=requirements,proto3" json:"requirements,omitempty"`
+	Mediation      *MediationPolicy        `protobuf:"bytes,14,opt,name=mediation,proto3" json:"mediation,omitempty"`
+	MaxDeferral    int64                   `protobuf:"varint,15,opt,name=max_deferral,json=maxDeferral,proto3" json:"max_deferral,omitempty"`
+	Series         string                  `protobuf:"bytes,16,opt,name=series,proto3" json:"series,omitempty"`
+}
+
+func (x *JobOffer) Reset() {
+	*x = JobOffer{}
+	mi := &file_solver_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOffer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOffer) ProtoMessage() {}
+
+func (x *JobOffer) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOffer.ProtoReflect.Descriptor instead.
+func (*JobOffer) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *JobOffer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JobOffer) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *JobOffer) GetJobCreator() string {
+	if x != nil {
+		return x.JobCreator
+	}
+	return ""
+}
+
+func (x *JobOffer) GetModule() *ModuleConfig {
+	if x != nil {
+		return x.Module
+	}
+	return nil
+}
+
+func (x *JobOffer) GetSpec() *MachineSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *JobOffer) GetInputs() map[string]string {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *JobOffer) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *JobOffer) GetPricing() *DealPricing {
+	if x != nil {
+		return x.Pricing
+	}
+	return nil
+}
+
+func (x *JobOffer) GetTimeouts() *DealTimeouts {
+	if x != nil {
+		return x.Timeouts
+	}
+	return nil
+}
+
+func (x *JobOffer) GetTrustedParties() *ServiceConfig {
+	if x != nil {
+		return x.TrustedParties
+	}
+	return nil
+}
+
+func (x *JobOffer) GetTarget() *TargetConfig {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *JobOffer) GetLocality() *LocalityPreference {
+	if x != nil {
+		return x.Locality
+	}
+	return nil
+}
+
+func (x *JobOffer) GetRequirements() []*AttributeRequirement {
+	if x != nil {
+		return x.Requirements
+	}
+	return nil
+}
+
+func (x *JobOffer) GetMediation() *MediationPolicy {
+	if x != nil {
+		return x.Mediation
+	}
+	return nil
+}
+
+func (x *JobOffer) GetMaxDeferral() int64 {
+	if x != nil {
+		return x.MaxDeferral
+	}
+	return 0
+}
+
+func (x *JobOffer) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+type JobOfferContainer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DealId      string    `protobuf:"bytes,2,opt,name=deal_id,json=dealId,proto3" json:"deal_id,omitempty"`
+	JobCreator  string    `protobuf:"bytes,3,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	State       uint32    `protobuf:"varint,4,opt,name=state,proto3" json:"state,omitempty"`
+	JobOffer    *JobOffer `protobuf:"bytes,5,opt,name=job_offer,json=jobOffer,proto3" json:"job_offer,omitempty"`
+	Origin      string    `protobuf:"bytes,6,opt,name=origin,proto3" json:"origin,omitempty"`
+	ForwardedTo string    `protobuf:"bytes,7,opt,name=forwarded_to,json=forwardedTo,proto3" json:"forwarded_to,omitempty"`
+}
+
+func (x *JobOfferContainer) Reset() {
+	*x = JobOfferContainer{}
+	mi := &file_solver_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOfferContainer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOfferContainer) ProtoMessage() {}
+
+func (x *JobOfferContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOfferContainer.ProtoReflect.Descriptor instead.
+func (*JobOfferContainer) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *JobOfferContainer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JobOfferContainer) GetDealId() string {
+	if x != nil {
+		return x.DealId
+	}
+	return ""
+}
+
+func (x *JobOfferContainer) GetJobCreator() string {
+	if x != nil {
+		return x.JobCreator
+	}
+	return ""
+}
+
+func (x *JobOfferContainer) GetState() uint32 {
+	if x != nil {
+		return x.State
+	}
+	return 0
+}
+
+func (x *JobOfferContainer) GetJobOffer() *JobOffer {
+	if x != nil {
+		return x.JobOffer
+	}
+	return nil
+}
+
+func (x *JobOfferContainer) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *JobOfferContainer) GetForwardedTo() string {
+	if x != nil {
+		return x.ForwardedTo
+	}
+	return ""
+}
+
+type AvailabilityWindow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Sunday is 0.
+	Days     []int32 `protobuf:"varint,1,rep,packed,name=days,proto3" json:"days,omitempty"`
+	Start    string  `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End      string  `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Timezone string  `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+}
+
+func (x *AvailabilityWindow) Reset() {
+	*x = AvailabilityWindow{}
+	mi := &file_solver_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvailabilityWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvailabilityWindow) ProtoMessage() {}
+
+func (x *AvailabilityWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvailabilityWindow.ProtoReflect.Descriptor instead.
+func (*AvailabilityWindow) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AvailabilityWindow) GetDays() []int32 {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *AvailabilityWindow) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *AvailabilityWindow) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *AvailabilityWindow) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+type ResourceOffer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Milliseconds since the epoch.
+	CreatedAt        int64        `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ResourceProvider string       `protobuf:"bytes,3,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	Index            int64        `protobuf:"varint,4,opt,name=index,proto3" json:"index,omitempty"`
+	Spec             *MachineSpec `protobuf:"bytes,5,opt,name=spec,proto3" json:"spec,omitempty"`
+	Modules          []string     `protobuf:"bytes,6,rep,name=modules,proto3" json:"modules,omitempty"`
+	// MarketPrice or FixedPrice
+	Mode            string                   `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	DefaultPricing  *DealPricing             `protobuf:"bytes,8,opt,name=default_pricing,json=defaultPricing,proto3" json:"default_pricing,omitempty"`
+	DefaultTimeouts *DealTimeouts            `protobuf:"bytes,9,opt,name=default_timeouts,json=defaultTimeouts,proto3" json:"default_timeouts,omitempty"`
+	ModulePricing   map[string]*DealPricing  `protobuf:"bytes,10,rep,name=module_pricing,json=modulePricing,proto3" json:"module_pricing,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ModuleTimeouts  map[string]*DealTimeouts `protobuf:"bytes,11,rep,name=module_timeouts,json=moduleTimeouts,proto3" json:"module_timeouts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	TrustedParties  *ServiceConfig           `protobuf:"bytes,12,opt,name=trusted_parties,json=trustedParties,proto3" json:"trusted_parties,omitempty"`
+	Region          string                   `protobuf:"bytes,13,opt,name=region,proto3" json:"region,omitempty"`
+	Attributes      map[string]string        `protobuf:"bytes,14,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Availability    []*AvailabilityWindow    `protobuf:"bytes,15,rep,name=availability,proto3" json:"availability,omitempty"`
+	Packing         bool                     `protobuf:"varint,16,opt,name=packing,proto3" json:"packing,omitempty"`
+}
+
+func (x *ResourceOffer) Reset() {
+	*x = ResourceOffer{}
+	mi := &file_solver_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceOffer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceOffer) ProtoMessage() {}
+
+func (x *ResourceOffer) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceOffer.ProtoReflect.Descriptor instead.
+func (*ResourceOffer) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ResourceOffer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ResourceOffer) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ResourceOffer) GetResourceProvider() string {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return ""
+}
+
+func (x *ResourceOffer) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ResourceOffer) GetSpec() *MachineSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetModules() []string {
+	if x != nil {
+		return x.Modules
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ResourceOffer) GetDefaultPricing() *DealPricing {
+	if x != nil {
+		return x.DefaultPricing
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetDefaultTimeouts() *DealTimeouts {
+	if x != nil {
+		return x.DefaultTimeouts
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetModulePricing() map[string]*DealPricing {
+	if x != nil {
+		return x.ModulePricing
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetModuleTimeouts() map[string]*DealTimeouts {
+	if x != nil {
+		return x.ModuleTimeouts
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetTrustedParties() *ServiceConfig {
+	if x != nil {
+		return x.TrustedParties
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ResourceOffer) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetAvailability() []*AvailabilityWindow {
+	if x != nil {
+		return x.Availability
+	}
+	return nil
+}
+
+func (x *ResourceOffer) GetPacking() bool {
+	if x != nil {
+		return x.Packing
+	}
+	return false
+}
+
+type ResourceOfferContainer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id               string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DealId           string         `protobuf:"bytes,2,opt,name=deal_id,json=dealId,proto3" json:"deal_id,omitempty"`
+	ResourceProvider string         `protobuf:"bytes,3,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	State            uint32         `protobuf:"varint,4,opt,name=state,proto3" json:"state,omitempty"`
+	ResourceOffer    *ResourceOffer `protobuf:"bytes,5,opt,name=resource_offer,json=resourceOffer,proto3" json:"resource_offer,omitempty"`
+}
+
+func (x *ResourceOfferContainer) Reset() {
+	*x = ResourceOfferContainer{}
+	mi := &file_solver_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceOfferContainer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceOfferContainer) ProtoMessage() {}
+
+func (x *ResourceOfferContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceOfferContainer.ProtoReflect.Descriptor instead.
+func (*ResourceOfferContainer) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResourceOfferContainer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ResourceOfferContainer) GetDealId() string {
+	if x != nil {
+		return x.DealId
+	}
+	return ""
+}
+
+func (x *ResourceOfferContainer) GetResourceProvider() string {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return ""
+}
+
+func (x *ResourceOfferContainer) GetState() uint32 {
+	if x != nil {
+		return x.State
+	}
+	return 0
+}
+
+func (x *ResourceOfferContainer) GetResourceOffer() *ResourceOffer {
+	if x != nil {
+		return x.ResourceOffer
+	}
+	return nil
+}
+
+type DealMembers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Solver           string   `protobuf:"bytes,1,opt,name=solver,proto3" json:"solver,omitempty"`
+	JobCreator       string   `protobuf:"bytes,2,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	ResourceProvider string   `protobuf:"bytes,3,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	Mediators        []string `protobuf:"bytes,4,rep,name=mediators,proto3" json:"mediators,omitempty"`
+}
+
+func (x *DealMembers) Reset() {
+	*x = DealMembers{}
+	mi := &file_solver_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealMembers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealMembers) ProtoMessage() {}
+
+func (x *DealMembers) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealMembers.ProtoReflect.Descriptor instead.
+func (*DealMembers) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DealMembers) GetSolver() string {
+	if x != nil {
+		return x.Solver
+	}
+	return ""
+}
+
+func (x *DealMembers) GetJobCreator() string {
+	if x != nil {
+		return x.JobCreator
+	}
+	return ""
+}
+
+func (x *DealMembers) GetResourceProvider() string {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return ""
+}
+
+func (x *DealMembers) GetMediators() []string {
+	if x != nil {
+		return x.Mediators
+	}
+	return nil
+}
+
+type Deal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id            string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Members       *DealMembers   `protobuf:"bytes,2,opt,name=members,proto3" json:"members,omitempty"`
+	Pricing       *DealPricing   `protobuf:"bytes,3,opt,name=pricing,proto3" json:"pricing,omitempty"`
+	Timeouts      *DealTimeouts  `protobuf:"bytes,4,opt,name=timeouts,proto3" json:"timeouts,omitempty"`
+	JobOffer      *JobOffer      `protobuf:"bytes,5,opt,name=job_offer,json=jobOffer,proto3" json:"job_offer,omitempty"`
+	ResourceOffer *ResourceOffer `protobuf:"bytes,6,opt,name=resource_offer,json=resourceOffer,proto3" json:"resource_offer,omitempty"`
+}
+
+func (x *Deal) Reset() {
+	*x = Deal{}
+	mi := &file_solver_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Deal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deal) ProtoMessage() {}
+
+func (x *Deal) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deal.ProtoReflect.Descriptor instead.
+func (*Deal) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Deal) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Deal) GetMembers() *DealMembers {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *Deal) GetPricing() *DealPricing {
+	if x != nil {
+		return x.Pricing
+	}
+	return nil
+}
+
+func (x *Deal) GetTimeouts() *DealTimeouts {
+	if x != nil {
+		return x.Timeouts
+	}
+	return nil
+}
+
+func (x *Deal) GetJobOffer() *JobOffer {
+	if x != nil {
+		return x.JobOffer
+	}
+	return nil
+}
+
+func (x *Deal) GetResourceOffer() *ResourceOffer {
+	if x != nil {
+		return x.ResourceOffer
+	}
+	return nil
+}
+
+type DealTransactionsJobCreator struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Agree                string `protobuf:"bytes,1,opt,name=agree,proto3" json:"agree,omitempty"`
+	AcceptResult         string `protobuf:"bytes,2,opt,name=accept_result,json=acceptResult,proto3" json:"accept_result,omitempty"`
+	CheckResult          string `protobuf:"bytes,3,opt,name=check_result,json=checkResult,proto3" json:"check_result,omitempty"`
+	TimeoutAgree         string `protobuf:"bytes,4,opt,name=timeout_agree,json=timeoutAgree,proto3" json:"timeout_agree,omitempty"`
+	TimeoutSubmitResult  string `protobuf:"bytes,5,opt,name=timeout_submit_result,json=timeoutSubmitResult,proto3" json:"timeout_submit_result,omitempty"`
+	TimeoutMediateResult string `protobuf:"bytes,6,opt,name=timeout_mediate_result,json=timeoutMediateResult,proto3" json:"timeout_mediate_result,omitempty"`
+}
+
+func (x *DealTransactionsJobCreator) Reset() {
+	*x = DealTransactionsJobCreator{}
+	mi := &file_solver_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealTransactionsJobCreator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealTransactionsJobCreator) ProtoMessage() {}
+
+func (x *DealTransactionsJobCreator) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealTransactionsJobCreator.ProtoReflect.Descriptor instead.
+func (*DealTransactionsJobCreator) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DealTransactionsJobCreator) GetAgree() string {
+	if x != nil {
+		return x.Agree
+	}
+	return ""
+}
+
+func (x *DealTransactionsJobCreator) GetAcceptResult() string {
+	if x != nil {
+		return x.AcceptResult
+	}
+	return ""
+}
+
+func (x *DealTransactionsJobCreator) GetCheckResult() string {
+	if x != nil {
+		return x.CheckResult
+	}
+	return ""
+}
+
+func (x *DealTransactionsJobCreator) GetTimeoutAgree() string {
+	if x != nil {
+		return x.TimeoutAgree
+	}
+	return ""
+}
+
+func (x *DealTransactionsJobCreator) GetTimeoutSubmitResult() string {
+	if x != nil {
+		return x.TimeoutSubmitResult
+	}
+	return ""
+}
+
+func (x *DealTransactionsJobCreator) GetTimeoutMediateResult() string {
+	if x != nil {
+		return x.TimeoutMediateResult
+	}
+	return ""
+}
+
+type DealTransactionsResourceProvider struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Agree                string `protobuf:"bytes,1,opt,name=agree,proto3" json:"agree,omitempty"`
+	AddResult            string `protobuf:"bytes,2,opt,name=add_result,json=addResult,proto3" json:"add_result,omitempty"`
+	TimeoutAgree         string `protobuf:"bytes,3,opt,name=timeout_agree,json=timeoutAgree,proto3" json:"timeout_agree,omitempty"`
+	TimeoutJudgeResult   string `protobuf:"bytes,4,opt,name=timeout_judge_result,json=timeoutJudgeResult,proto3" json:"timeout_judge_result,omitempty"`
+	TimeoutMediateResult string `protobuf:"bytes,5,opt,name=timeout_mediate_result,json=timeoutMediateResult,proto3" json:"timeout_mediate_result,omitempty"`
+}
+
+func (x *DealTransactionsResourceProvider) Reset() {
+	*x = DealTransactionsResourceProvider{}
+	mi := &file_solver_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealTransactionsResourceProvider) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealTransactionsResourceProvider) ProtoMessage() {}
+
+func (x *DealTransactionsResourceProvider) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealTransactionsResourceProvider.ProtoReflect.Descriptor instead.
+func (*DealTransactionsResourceProvider) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DealTransactionsResourceProvider) GetAgree() string {
+	if x != nil {
+		return x.Agree
+	}
+	return ""
+}
+
+func (x *DealTransactionsResourceProvider) GetAddResult() string {
+	if x != nil {
+		return x.AddResult
+	}
+	return ""
+}
+
+func (x *DealTransactionsResourceProvider) GetTimeoutAgree() string {
+	if x != nil {
+		return x.TimeoutAgree
+	}
+	return ""
+}
+
+func (x *DealTransactionsResourceProvider) GetTimeoutJudgeResult() string {
+	if x != nil {
+		return x.TimeoutJudgeResult
+	}
+	return ""
+}
+
+func (x *DealTransactionsResourceProvider) GetTimeoutMediateResult() string {
+	if x != nil {
+		return x.TimeoutMediateResult
+	}
+	return ""
+}
+
+type DealTransactionsMediator struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MediationAcceptResult string `protobuf:"bytes,1,opt,name=mediation_accept_result,json=mediationAcceptResult,proto3" json:"mediation_accept_result,omitempty"`
+	MediationRejectResult string `protobuf:"bytes,2,opt,name=mediation_reject_result,json=mediationRejectResult,proto3" json:"mediation_reject_result,omitempty"`
+}
+
+func (x *DealTransactionsMediator) Reset() {
+	*x = DealTransactionsMediator{}
+	mi := &file_solver_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealTransactionsMediator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealTransactionsMediator) ProtoMessage() {}
+
+func (x *DealTransactionsMediator) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealTransactionsMediator.ProtoReflect.Descriptor instead.
+func (*DealTransactionsMediator) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DealTransactionsMediator) GetMediationAcceptResult() string {
+	if x != nil {
+		return x.MediationAcceptResult
+	}
+	return ""
+}
+
+func (x *DealTransactionsMediator) GetMediationRejectResult() string {
+	if x != nil {
+		return x.MediationRejectResult
+	}
+	return ""
+}
+
+type DealTransactions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ResourceProvider *DealTransactionsResourceProvider `protobuf:"bytes,1,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	JobCreator       *DealTransactionsJobCreator       `protobuf:"bytes,2,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	Mediator         *DealTransactionsMediator         `protobuf:"bytes,3,opt,name=mediator,proto3" json:"mediator,omitempty"`
+}
+
+func (x *DealTransactions) Reset() {
+	*x = DealTransactions{}
+	mi := &file_solver_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealTransactions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealTransactions) ProtoMessage() {}
+
+func (x *DealTransactions) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealTransactions.ProtoReflect.Descriptor instead.
+func (*DealTransactions) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DealTransactions) GetResourceProvider() *DealTransactionsResourceProvider {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return nil
+}
+
+func (x *DealTransactions) GetJobCreator() *DealTransactionsJobCreator {
+	if x != nil {
+		return x.JobCreator
+	}
+	return nil
+}
+
+func (x *DealTransactions) GetMediator() *DealTransactionsMediator {
+	if x != nil {
+		return x.Mediator
+	}
+	return nil
+}
+
+type DealContainer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id               string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	JobCreator       string            `protobuf:"bytes,2,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	ResourceProvider string            `protobuf:"bytes,3,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	JobOffer         string            `protobuf:"bytes,4,opt,name=job_offer,json=jobOffer,proto3" json:"job_offer,omitempty"`
+	ResourceOffer    string            `protobuf:"bytes,5,opt,name=resource_offer,json=resourceOffer,proto3" json:"resource_offer,omitempty"`
+	State            uint32            `protobuf:"varint,6,opt,name=state,proto3" json:"state,omitempty"`
+	Deal             *Deal             `protobuf:"bytes,7,opt,name=deal,proto3" json:"deal,omitempty"`
+	Transactions     *DealTransactions `protobuf:"bytes,8,opt,name=transactions,proto3" json:"transactions,omitempty"`
+	Mediator         string            `protobuf:"bytes,9,opt,name=mediator,proto3" json:"mediator,omitempty"`
+}
+
+func (x *DealContainer) Reset() {
+	*x = DealContainer{}
+	mi := &file_solver_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealContainer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealContainer) ProtoMessage() {}
+
+func (x *DealContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealContainer.ProtoReflect.Descriptor instead.
+func (*DealContainer) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DealContainer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DealContainer) GetJobCreator() string {
+	if x != nil {
+		return x.JobCreator
+	}
+	return ""
+}
+
+func (x *DealContainer) GetResourceProvider() string {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return ""
+}
+
+func (x *DealContainer) GetJobOffer() string {
+	if x != nil {
+		return x.JobOffer
+	}
+	return ""
+}
+
+func (x *DealContainer) GetResourceOffer() string {
+	if x != nil {
+		return x.ResourceOffer
+	}
+	return ""
+}
+
+func (x *DealContainer) GetState() uint32 {
+	if x != nil {
+		return x.State
+	}
+	return 0
+}
+
+func (x *DealContainer) GetDeal() *Deal {
+	if x != nil {
+		return x.Deal
+	}
+	return nil
+}
+
+func (x *DealContainer) GetTransactions() *DealTransactions {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *DealContainer) GetMediator() string {
+	if x != nil {
+		return x.Mediator
+	}
+	return ""
+}
+
+type Result struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id               string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DealId           string `protobuf:"bytes,2,opt,name=deal_id,json=dealId,proto3" json:"deal_id,omitempty"`
+	DataId           string `protobuf:"bytes,3,opt,name=data_id,json=dataId,proto3" json:"data_id,omitempty"`
+	Error            string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	InstructionCount uint64 `protobuf:"varint,5,opt,name=instruction_count,json=instructionCount,proto3" json:"instruction_count,omitempty"`
+	// what the job used as the resource provider measured it
+	CpuTime        float64 `protobuf:"fixed64,6,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	PeakMemory     int64   `protobuf:"varint,7,opt,name=peak_memory,json=peakMemory,proto3" json:"peak_memory,omitempty"`
+	GpuUtilization float64 `protobuf:"fixed64,8,opt,name=gpu_utilization,json=gpuUtilization,proto3" json:"gpu_utilization,omitempty"`
+	Duration       float64 `protobuf:"fixed64,9,opt,name=duration,proto3" json:"duration,omitempty"`
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_solver_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Result) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Result) GetDealId() string {
+	if x != nil {
+		return x.DealId
+	}
+	return ""
+}
+
+func (x *Result) GetDataId() string {
+	if x != nil {
+		return x.DataId
+	}
+	return ""
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Result) GetInstructionCount() uint64 {
+	if x != nil {
+		return x.InstructionCount
+	}
+	return 0
+}
+
+func (x *Result) GetCpuTime() float64 {
+	if x != nil {
+		return x.CpuTime
+	}
+	return 0
+}
+
+func (x *Result) GetPeakMemory() int64 {
+	if x != nil {
+		return x.PeakMemory
+	}
+	return 0
+}
+
+func (x *Result) GetGpuUtilization() float64 {
+	if x != nil {
+		return x.GpuUtilization
+	}
+	return 0
+}
+
+func (x *Result) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type GetJobOffersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobCreator       string `protobuf:"bytes,1,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	NotMatched       bool   `protobuf:"varint,2,opt,name=not_matched,json=notMatched,proto3" json:"not_matched,omitempty"`
+	IncludeCancelled bool   `protobuf:"varint,3,opt,name=include_cancelled,json=includeCancelled,proto3" json:"include_cancelled,omitempty"`
+	Series           string `protobuf:"bytes,4,opt,name=series,proto3" json:"series,omitempty"`
+}
+
+func (x *GetJobOffersRequest) Reset() {
+	*x = GetJobOffersRequest{}
+	mi := &file_solver_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobOffersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobOffersRequest) ProtoMessage() {}
+
+func (x *GetJobOffersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobOffersRequest.ProtoReflect.Descriptor instead.
+func (*GetJobOffersRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetJobOffersRequest) GetJobCreator() string {
+	if x != nil {
+		return x.JobCreator
+	}
+	return ""
+}
+
+func (x *GetJobOffersRequest) GetNotMatched() bool {
+	if x != nil {
+		return x.NotMatched
+	}
+	return false
+}
+
+func (x *GetJobOffersRequest) GetIncludeCancelled() bool {
+	if x != nil {
+		return x.IncludeCancelled
+	}
+	return false
+}
+
+func (x *GetJobOffersRequest) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+type GetJobOffersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobOffers []*JobOfferContainer `protobuf:"bytes,1,rep,name=job_offers,json=jobOffers,proto3" json:"job_offers,omitempty"`
+}
+
+func (x *GetJobOffersResponse) Reset() {
+	*x = GetJobOffersResponse{}
+	mi := &file_solver_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobOffersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobOffersResponse) ProtoMessage() {}
+
+func (x *GetJobOffersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobOffersResponse.ProtoReflect.Descriptor instead.
+func (*GetJobOffersResponse) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetJobOffersResponse) GetJobOffers() []*JobOfferContainer {
+	if x != nil {
+		return x.JobOffers
+	}
+	return nil
+}
+
+type AddJobOfferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobOffer *JobOffer `protobuf:"bytes,1,opt,name=job_offer,json=jobOffer,proto3" json:"job_offer,omitempty"`
+}
+
+func (x *AddJobOfferRequest) Reset() {
+	*x = AddJobOfferRequest{}
+	mi := &file_solver_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddJobOfferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddJobOfferRequest) ProtoMessage() {}
+
+func (x *AddJobOfferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddJobOfferRequest.ProtoReflect.Descriptor instead.
+func (*AddJobOfferRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AddJobOfferRequest) GetJobOffer() *JobOffer {
+	if x != nil {
+		return x.JobOffer
+	}
+	return nil
+}
+
+type GetResourceOffersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ResourceProvider string            `protobuf:"bytes,1,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	Active           bool              `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	NotMatched       bool              `protobuf:"varint,3,opt,name=not_matched,json=notMatched,proto3" json:"not_matched,omitempty"`
+	Attributes       map[string]string `protobuf:"bytes,4,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *GetResourceOffersRequest) Reset() {
+	*x = GetResourceOffersRequest{}
+	mi := &file_solver_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResourceOffersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResourceOffersRequest) ProtoMessage() {}
+
+func (x *GetResourceOffersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResourceOffersRequest.ProtoReflect.Descriptor instead.
+func (*GetResourceOffersRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetResourceOffersRequest) GetResourceProvider() string {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return ""
+}
+
+func (x *GetResourceOffersRequest) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *GetResourceOffersRequest) GetNotMatched() bool {
+	if x != nil {
+		return x.NotMatched
+	}
+	return false
+}
+
+func (x *GetResourceOffersRequest) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type GetResourceOffersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ResourceOffers []*ResourceOfferContainer `protobuf:"bytes,1,rep,name=resource_offers,json=resourceOffers,proto3" json:"resource_offers,omitempty"`
+}
+
+func (x *GetResourceOffersResponse) Reset() {
+	*x = GetResourceOffersResponse{}
+	mi := &file_solver_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResourceOffersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResourceOffersResponse) ProtoMessage() {}
+
+func (x *GetResourceOffersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResourceOffersResponse.ProtoReflect.Descriptor instead.
+func (*GetResourceOffersResponse) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetResourceOffersResponse) GetResourceOffers() []*ResourceOfferContainer {
+	if x != nil {
+		return x.ResourceOffers
+	}
+	return nil
+}
+
+type AddResourceOfferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ResourceOffer *ResourceOffer `protobuf:"bytes,1,opt,name=resource_offer,json=resourceOffer,proto3" json:"resource_offer,omitempty"`
+}
+
+func (x *AddResourceOfferRequest) Reset() {
+	*x = AddResourceOfferRequest{}
+	mi := &file_solver_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddResourceOfferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddResourceOfferRequest) ProtoMessage() {}
+
+func (x *AddResourceOfferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddResourceOfferRequest.ProtoReflect.Descriptor instead.
+func (*AddResourceOfferRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AddResourceOfferRequest) GetResourceOffer() *ResourceOffer {
+	if x != nil {
+		return x.ResourceOffer
+	}
+	return nil
+}
+
+type GetDealsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobCreator       string `protobuf:"bytes,1,opt,name=job_creator,json=jobCreator,proto3" json:"job_creator,omitempty"`
+	ResourceProvider string `protobuf:"bytes,2,opt,name=resource_provider,json=resourceProvider,proto3" json:"resource_provider,omitempty"`
+	Mediator         string `protobuf:"bytes,3,opt,name=mediator,proto3" json:"mediator,omitempty"`
+	State            string `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+}
+
+func (x *GetDealsRequest) Reset() {
+	*x = GetDealsRequest{}
+	mi := &file_solver_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDealsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDealsRequest) ProtoMessage() {}
+
+func (x *GetDealsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDealsRequest.ProtoReflect.Descriptor instead.
+func (*GetDealsRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetDealsRequest) GetJobCreator() string {
+	if x != nil {
+		return x.JobCreator
+	}
+	return ""
+}
+
+func (x *GetDealsRequest) GetResourceProvider() string {
+	if x != nil {
+		return x.ResourceProvider
+	}
+	return ""
+}
+
+func (x *GetDealsRequest) GetMediator() string {
+	if x != nil {
+		return x.Mediator
+	}
+	return ""
+}
+
+func (x *GetDealsRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type GetDealsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Deals []*DealContainer `protobuf:"bytes,1,rep,name=deals,proto3" json:"deals,omitempty"`
+}
+
+func (x *GetDealsResponse) Reset() {
+	*x = GetDealsResponse{}
+	mi := &file_solver_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDealsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDealsResponse) ProtoMessage() {}
+
+func (x *GetDealsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDealsResponse.ProtoReflect.Descriptor instead.
+func (*GetDealsResponse) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetDealsResponse) GetDeals() []*DealContainer {
+	if x != nil {
+		return x.Deals
+	}
+	return nil
+}
+
+type GetDealRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetDealRequest) Reset() {
+	*x = GetDealRequest{}
+	mi := &file_solver_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDealRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDealRequest) ProtoMessage() {}
+
+func (x *GetDealRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDealRequest.ProtoReflect.Descriptor instead.
+func (*GetDealRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetDealRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetResultRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DealId string `protobuf:"bytes,1,opt,name=deal_id,json=dealId,proto3" json:"deal_id,omitempty"`
+}
+
+func (x *GetResultRequest) Reset() {
+	*x = GetResultRequest{}
+	mi := &file_solver_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResultRequest) ProtoMessage() {}
+
+func (x *GetResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResultRequest.ProtoReflect.Descriptor instead.
+func (*GetResultRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetResultRequest) GetDealId() string {
+	if x != nil {
+		return x.DealId
+	}
+	return ""
+}
+
+type WatchDealRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LastEventId uint64 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+}
+
+func (x *WatchDealRequest) Reset() {
+	*x = WatchDealRequest{}
+	mi := &file_solver_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchDealRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDealRequest) ProtoMessage() {}
+
+func (x *WatchDealRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDealRequest.ProtoReflect.Descriptor instead.
+func (*WatchDealRequest) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *WatchDealRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WatchDealRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+type DealEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        uint64         `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	EventType string         `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Deal      *DealContainer `protobuf:"bytes,3,opt,name=deal,proto3" json:"deal,omitempty"`
+	Result    *Result        `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *DealEvent) Reset() {
+	*x = DealEvent{}
+	mi := &file_solver_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealEvent) ProtoMessage() {}
+
+func (x *DealEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_solver_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealEvent.ProtoReflect.Descriptor instead.
+func (*DealEvent) Descriptor() ([]byte, []int) {
+	return file_solver_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DealEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DealEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *DealEvent) GetDeal() *DealContainer {
+	if x != nil {
+		return x.Deal
+	}
+	return nil
+}
+
+func (x *DealEvent) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+var File_solver_proto protoreflect.FileDescriptor
+
+var file_solver_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x11,
+	0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x22, 0x75, 0x0a, 0x07, 0x47, 0x50, 0x55, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x72, 0x61, 0x6d,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x76, 0x72, 0x61, 0x6d, 0x12, 0x16, 0x0a, 0x06,
+	0x64, 0x72, 0x69, 0x76, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x72,
+	0x69, 0x76, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x75, 0x64, 0x61, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x63, 0x75, 0x64, 0x61, 0x22, 0x87, 0x01, 0x0a, 0x0b, 0x4d, 0x61, 0x63,
+	0x68, 0x69, 0x6e, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x67, 0x70, 0x75, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x67, 0x70, 0x75, 0x12, 0x2e, 0x0a, 0x04, 0x67, 0x70,
+	0x75, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70,
+	0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x50, 0x55,
+	0x53, 0x70, 0x65, 0x63, 0x52, 0x04, 0x67, 0x70, 0x75, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x70,
+	0x75, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x63, 0x70, 0x75, 0x12, 0x10, 0x0a, 0x03,
+	0x72, 0x61, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x72, 0x61, 0x6d, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x69, 0x73, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x64, 0x69,
+	0x73, 0x6b, 0x22, 0x5e, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x65, 0x70, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x65, 0x70, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61,
+	0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x22, 0xce, 0x01, 0x0a, 0x0b, 0x44, 0x65, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x69,
+	0x6e, 0x67, 0x12, 0x2b, 0x0a, 0x11, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x69,
+	0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12,
+	0x2d, 0x0a, 0x12, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x63, 0x6f, 0x6c, 0x6c, 0x61,
+	0x74, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x70, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x3e,
+	0x0a, 0x1b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x5f, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74,
+	0x65, 0x72, 0x61, 0x6c, 0x5f, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x19, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x43, 0x6f, 0x6c, 0x6c,
+	0x61, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x12, 0x23,
+	0x0a, 0x0d, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x66, 0x65, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x46, 0x65, 0x65, 0x22, 0x47, 0x0a, 0x0b, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0a, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x22, 0x99, 0x02, 0x0a,
+	0x0c, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x12, 0x34, 0x0a,
+	0x05, 0x61, 0x67, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c,
+	0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x52, 0x05, 0x61, 0x67,
+	0x72, 0x65, 0x65, 0x12, 0x45, 0x0a, 0x0e, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x5f, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69,
+	0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x52, 0x0d, 0x73, 0x75, 0x62,
+	0x6d, 0x69, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x43, 0x0a, 0x0d, 0x6a, 0x75,
+	0x64, 0x67, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x52, 0x0c, 0x6a, 0x75, 0x64, 0x67, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12,
+	0x47, 0x0a, 0x0f, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70,
+	0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61,
+	0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x52, 0x0e, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x5e, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x6c, 0x76, 0x65,
+	0x72, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x19, 0x0a,
+	0x08, 0x61, 0x70, 0x69, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x61, 0x70, 0x69, 0x48, 0x6f, 0x73, 0x74, 0x22, 0x28, 0x0a, 0x0c, 0x54, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x22, 0x46, 0x0a, 0x12, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x50, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x67, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x67, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x22, 0x5a, 0x0a, 0x14, 0x41, 0x74,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x79, 0x0a, 0x0f, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x2c, 0x0a, 0x12, 0x61, 0x6c, 0x77,
+	0x61, 0x79, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x66, 0x69, 0x72, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x61, 0x6c, 0x77, 0x61, 0x79, 0x73, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x46, 0x69, 0x72, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x18, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x16, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67,
+	0x65, 0x22, 0xdf, 0x06, 0x0a, 0x08, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x6a, 0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x37,
+	0x0a, 0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x32, 0x0a, 0x04, 0x73, 0x70, 0x65, 0x63, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e,
+	0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e,
+	0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x04, 0x73, 0x70, 0x65, 0x63, 0x12, 0x3f, 0x0a, 0x06, 0x69,
+	0x6e, 0x70, 0x75, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6c, 0x69,
+	0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x12, 0x38, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e,
+	0x67, 0x52, 0x07, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x12, 0x3b, 0x0a, 0x08, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c,
+	0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x52, 0x08, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x12, 0x49, 0x0a, 0x0f, 0x74, 0x72, 0x75, 0x73, 0x74,
+	0x65, 0x64, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x52, 0x0e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x65, 0x73, 0x12, 0x37, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x41, 0x0a, 0x08, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e,
+	0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x50, 0x72, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x4b,
+	0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x0d,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73,
+	0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0c, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x40, 0x0a, 0x09, 0x6d,
+	0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a,
+	0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65, 0x66, 0x65, 0x72, 0x72, 0x61, 0x6c, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x44, 0x65, 0x66, 0x65, 0x72, 0x72, 0x61, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x49, 0x6e, 0x70, 0x75,
+	0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xe8, 0x01, 0x0a, 0x11, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x65, 0x61,
+	0x6c, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x61, 0x6c,
+	0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6a, 0x6f, 0x62, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x6a, 0x6f, 0x62,
+	0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6c,
+	0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x08, 0x6a, 0x6f, 0x62, 0x4f, 0x66,
+	0x66, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x66,
+	0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x65, 0x64, 0x5f, 0x74, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x65, 0x64, 0x54, 0x6f, 0x22, 0x6c,
+	0x0a, 0x12, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x57, 0x69,
+	0x6e, 0x64, 0x6f, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x05, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x6e, 0x64,
+	0x12, 0x1a, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x22, 0xd2, 0x08, 0x0a,
+	0x0d, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x2b, 0x0a,
+	0x11, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64,
+	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x32, 0x0a, 0x04, 0x73, 0x70, 0x65, 0x63, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x04,
+	0x73, 0x70, 0x65, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f,
+	0x64, 0x65, 0x12, 0x47, 0x0a, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x70, 0x72,
+	0x69, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69,
+	0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x52, 0x0e, 0x64, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x12, 0x4a, 0x0a, 0x10, 0x64,
+	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e,
+	0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x52, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x12, 0x5a, 0x0a, 0x0e, 0x6d, 0x6f, 0x64, 0x75, 0x6c,
+	0x65, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x33, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63,
+	0x69, 0x6e, 0x67, 0x12, 0x5d, 0x0a, 0x0f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x6c,
+	0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x2e, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0e, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x73, 0x12, 0x49, 0x0a, 0x0f, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6c, 0x69,
+	0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0e, 0x74,
+	0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x50, 0x61, 0x72, 0x74, 0x69, 0x65, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x50, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x6c, 0x69, 0x6c, 0x79,
+	0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x2e, 0x41, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x61, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x49, 0x0a, 0x0c, 0x61, 0x76, 0x61, 0x69, 0x6c,
+	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e,
+	0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x57, 0x69,
+	0x6e, 0x64, 0x6f, 0x77, 0x52, 0x0c, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x1a, 0x60, 0x0a, 0x12,
+	0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f,
+	0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63,
+	0x69, 0x6e, 0x67, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x62,
+	0x0a, 0x13, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x35, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64,
+	0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x1a, 0x3d, 0x0a, 0x0f, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0xcd, 0x01, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66,
+	0x66, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07,
+	0x64, 0x65, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x65, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64,
+	0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x47, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66,
+	0x65, 0x72, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x22, 0x91, 0x01, 0x0a, 0x0b, 0x44, 0x65, 0x61, 0x6c, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62,
+	0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x6a, 0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50,
+	0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x74, 0x6f, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x74, 0x6f, 0x72, 0x73, 0x22, 0xca, 0x02, 0x0a, 0x04, 0x44, 0x65, 0x61, 0x6c, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x38,
+	0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x52,
+	0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x38, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x63,
+	0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x69, 0x6c, 0x79,
+	0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x70, 0x72, 0x69, 0x63, 0x69,
+	0x6e, 0x67, 0x12, 0x3b, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73,
+	0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x73, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x12,
+	0x38, 0x0a, 0x09, 0x6a, 0x6f, 0x62, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52,
+	0x08, 0x6a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x47, 0x0a, 0x0e, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66,
+	0x66, 0x65, 0x72, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66,
+	0x65, 0x72, 0x22, 0x89, 0x02, 0x0a, 0x1a, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x4a, 0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x6f,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x67, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x61, 0x67, 0x72, 0x65, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x63, 0x63, 0x65, 0x70,
+	0x74, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x21, 0x0a, 0x0c,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x61, 0x67, 0x72, 0x65, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x41,
+	0x67, 0x72, 0x65, 0x65, 0x12, 0x32, 0x0a, 0x15, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f,
+	0x73, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x13, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x75, 0x62, 0x6d,
+	0x69, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x34, 0x0a, 0x16, 0x74, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0xe4,
+	0x01, 0x0a, 0x20, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69,
+	0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x67, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x67, 0x72, 0x65, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x64, 0x64,
+	0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61,
+	0x64, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x5f, 0x61, 0x67, 0x72, 0x65, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x41, 0x67, 0x72, 0x65, 0x65, 0x12, 0x30, 0x0a,
+	0x14, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6a, 0x75, 0x64, 0x67, 0x65, 0x5f, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x34, 0x0a, 0x16, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x74, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x14, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x8a, 0x01, 0x0a, 0x18, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74,
+	0x6f, 0x72, 0x12, 0x36, 0x0a, 0x17, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x15, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x36, 0x0a, 0x17, 0x6d, 0x65,
+	0x64, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x22, 0x8d, 0x02, 0x0a, 0x10, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x60, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x33, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50,
+	0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x52, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x4e, 0x0a, 0x0b, 0x6a, 0x6f, 0x62,
+	0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x4a, 0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x52, 0x0a, 0x6a,
+	0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x47, 0x0a, 0x08, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x6c, 0x69,
+	0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74,
+	0x6f, 0x72, 0x22, 0xd9, 0x02, 0x0a, 0x0d, 0x44, 0x65, 0x61, 0x6c, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6a, 0x6f, 0x62, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64,
+	0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x6a, 0x6f, 0x62, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12,
+	0x25, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x65,
+	0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x04,
+	0x64, 0x65, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6c, 0x69, 0x6c,
+	0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x61, 0x6c, 0x52, 0x04, 0x64, 0x65, 0x61, 0x6c, 0x12, 0x47, 0x0a, 0x0c, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x23, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x22, 0x8e,
+	0x02, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x65, 0x61,
+	0x6c, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x61, 0x6c,
+	0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x61, 0x74, 0x61, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x12, 0x2b, 0x0a, 0x11, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x69, 0x6e,
+	0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x63, 0x70, 0x75, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x07, 0x63, 0x70, 0x75, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x65, 0x61,
+	0x6b, 0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
+	0x70, 0x65, 0x61, 0x6b, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x27, 0x0a, 0x0f, 0x67, 0x70,
+	0x75, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0e, 0x67, 0x70, 0x75, 0x55, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0x9c, 0x01, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6a, 0x6f,
+	0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x74, 0x5f,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6e,
+	0x6f, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x5f, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x43, 0x61, 0x6e,
+	0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22, 0x5b,
+	0x0a, 0x14, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x0a, 0x6a, 0x6f, 0x62, 0x5f, 0x6f, 0x66,
+	0x66, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6c, 0x69, 0x6c,
+	0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4a,
+	0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x52, 0x09, 0x6a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x22, 0x4e, 0x0a, 0x12, 0x41,
+	0x64, 0x64, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x38, 0x0a, 0x09, 0x6a, 0x6f, 0x62, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73,
+	0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x52, 0x08, 0x6a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x22, 0x9c, 0x02, 0x0a, 0x18,
+	0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x72, 0x6f,
+	0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6e, 0x6f, 0x74, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0a, 0x6e, 0x6f, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x5b,
+	0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x3b, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x1a, 0x3d, 0x0a, 0x0f, 0x41,
+	0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x6f, 0x0a, 0x19, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52, 0x0a, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x29, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66,
+	0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x0e, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x22, 0x62, 0x0a, 0x17, 0x41,
+	0x64, 0x64, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x47, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x52, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x22,
+	0x91, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6a, 0x6f, 0x62, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x6f, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65,
+	0x72, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x22, 0x4a, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x05, 0x64, 0x65, 0x61, 0x6c, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64,
+	0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x43,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x05, 0x64, 0x65, 0x61, 0x6c, 0x73, 0x22,
+	0x20, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0x2b, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x65, 0x61, 0x6c, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x61, 0x6c, 0x49, 0x64, 0x22, 0x46,
+	0x0a, 0x10, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22, 0xa3, 0x01, 0x0a, 0x09, 0x44, 0x65, 0x61, 0x6c, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x34, 0x0a, 0x04, 0x64, 0x65, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x52, 0x04, 0x64, 0x65, 0x61, 0x6c, 0x12, 0x31, 0x0a, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6c, 0x69, 0x6c, 0x79,
+	0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x32, 0xe4, 0x05, 0x0a,
+	0x06, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x12, 0x5f, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x4a, 0x6f,
+	0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x12, 0x26, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61,
+	0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4a,
+	0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x27, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0b, 0x41, 0x64, 0x64, 0x4a,
+	0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x25, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61,
+	0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x64, 0x4a,
+	0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x12, 0x6e, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x12, 0x2b, 0x2e, 0x6c, 0x69, 0x6c, 0x79,
+	0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64,
+	0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x69, 0x0a, 0x10, 0x41, 0x64, 0x64, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x2a, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70,
+	0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x64,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73,
+	0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12,
+	0x53, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x22, 0x2e, 0x6c, 0x69,
+	0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x23, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x12,
+	0x21, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x20, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x12, 0x4b, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x23, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64,
+	0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x50, 0x0a, 0x09, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x61, 0x6c, 0x12, 0x23,
+	0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2e, 0x73, 0x6f,
+	0x6c, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x30, 0x01, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x6c, 0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2d, 0x74, 0x65, 0x63, 0x68, 0x2f, 0x6c,
+	0x69, 0x6c, 0x79, 0x70, 0x61, 0x64, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x6f, 0x6c, 0x76, 0x65,
+	0x72, 0x2f, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_solver_proto_rawDescOnce sync.Once
+	file_solver_proto_rawDescData = file_solver_proto_rawDesc
+)
+
+func file_solver_proto_rawDescGZIP() []byte {
+	file_solver_proto_rawDescOnce.Do(func() {
+		file_solver_proto_rawDescData = protoimpl.X.CompressGZIP(file_solver_proto_rawDescData)
+	})
+	return file_solver_proto_rawDescData
+}
+
+var file_solver_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
+var file_solver_proto_goTypes = []any{
+	(*GPUSpec)(nil),                          // 0: lilypad.solver.v1.GPUSpec
+	(*MachineSpec)(nil),                      // 1: lilypad.solver.v1.MachineSpec
+	(*ModuleConfig)(nil),                     // 2: lilypad.solver.v1.ModuleConfig
+	(*DealPricing)(nil),                      // 3: lilypad.solver.v1.DealPricing
+	(*DealTimeout)(nil),                      // 4: lilypad.solver.v1.DealTimeout
+	(*DealTimeouts)(nil),                     // 5: lilypad.solver.v1.DealTimeouts
+	(*ServiceConfig)(nil),                    // 6: lilypad.solver.v1.ServiceConfig
+	(*TargetConfig)(nil),                     // 7: lilypad.solver.v1.TargetConfig
+	(*LocalityPreference)(nil),               // 8: lilypad.solver.v1.LocalityPreference
+	(*AttributeRequirement)(nil),             // 9: lilypad.solver.v1.AttributeRequirement
+	(*MediationPolicy)(nil),                  // 10: lilypad.solver.v1.MediationPolicy
+	(*JobOffer)(nil),                         // 11: lilypad.solver.v1.JobOffer
+	(*JobOfferContainer)(nil),                // 12: lilypad.solver.v1.JobOfferContainer
+	(*AvailabilityWindow)(nil),               // 13: lilypad.solver.v1.AvailabilityWindow
+	(*ResourceOffer)(nil),                    // 14: lilypad.solver.v1.ResourceOffer
+	(*ResourceOfferContainer)(nil),           // 15: lilypad.solver.v1.ResourceOfferContainer
+	(*DealMembers)(nil),                      // 16: lilypad.solver.v1.DealMembers
+	(*Deal)(nil),                             // 17: lilypad.solver.v1.Deal
+	(*DealTransactionsJobCreator)(nil),       // 18: lilypad.solver.v1.DealTransactionsJobCreator
+	(*DealTransactionsResourceProvider)(nil), // 19: lilypad.solver.v1.DealTransactionsResourceProvider
+	(*DealTransactionsMediator)(nil),         // 20: lilypad.solver.v1.DealTransactionsMediator
+	(*DealTransactions)(nil),                 // 21: lilypad.solver.v1.DealTransactions
+	(*DealContainer)(nil),                    // 22: lilypad.solver.v1.DealContainer
+	(*Result)(nil),                           // 23: lilypad.solver.v1.Result
+	(*GetJobOffersRequest)(nil),              // 24: lilypad.solver.v1.GetJobOffersRequest
+	(*GetJobOffersResponse)(nil),             // 25: lilypad.solver.v1.GetJobOffersResponse
+	(*AddJobOfferRequest)(nil),               // 26: lilypad.solver.v1.AddJobOfferRequest
+	(*GetResourceOffersRequest)(nil),         // 27: lilypad.solver.v1.GetResourceOffersRequest
+	(*GetResourceOffersResponse)(nil),        // 28: lilypad.solver.v1.GetResourceOffersResponse
+	(*AddResourceOfferRequest)(nil),          // 29: lilypad.solver.v1.AddResourceOfferRequest
+	(*GetDealsRequest)(nil),                  // 30: lilypad.solver.v1.GetDealsRequest
+	(*GetDealsResponse)(nil),                 // 31: lilypad.solver.v1.GetDealsResponse
+	(*GetDealRequest)(nil),                   // 32: lilypad.solver.v1.GetDealRequest
+	(*GetResultRequest)(nil),                 // 33: lilypad.solver.v1.GetResultRequest
+	(*WatchDealRequest)(nil),                 // 34: lilypad.solver.v1.WatchDealRequest
+	(*DealEvent)(nil),                        // 35: lilypad.solver.v1.DealEvent
+	nil,                                      // 36: lilypad.solver.v1.JobOffer.InputsEntry
+	nil,                                      // 37: lilypad.solver.v1.ResourceOffer.ModulePricingEntry
+	nil,                                      // 38: lilypad.solver.v1.ResourceOffer.ModuleTimeoutsEntry
+	nil,                                      // 39: lilypad.solver.v1.ResourceOffer.AttributesEntry
+	nil,                                      // 40: lilypad.solver.v1.GetResourceOffersRequest.AttributesEntry
+}
+var file_solver_proto_depIdxs = []int32{
+	0,  // 0: lilypad.solver.v1.MachineSpec.gpus:type_name -> lilypad.solver.v1.GPUSpec
+	4,  // 1: lilypad.solver.v1.DealTimeouts.agree:type_name -> lilypad.solver.v1.DealTimeout
+	4,  // 2: lilypad.solver.v1.DealTimeouts.submit_results:type_name -> lilypad.solver.v1.DealTimeout
+	4,  // 3: lilypad.solver.v1.DealTimeouts.judge_results:type_name -> lilypad.solver.v1.DealTimeout
+	4,  // 4: lilypad.solver.v1.DealTimeouts.mediate_results:type_name -> lilypad.solver.v1.DealTimeout
+	2,  // 5: lilypad.solver.v1.JobOffer.module:type_name -> lilypad.solver.v1.ModuleConfig
+	1,  // 6: lilypad.solver.v1.JobOffer.spec:type_name -> lilypad.solver.v1.MachineSpec
+	36, // 7: lilypad.solver.v1.JobOffer.inputs:type_name -> lilypad.solver.v1.JobOffer.InputsEntry
+	3,  // 8: lilypad.solver.v1.JobOffer.pricing:type_name -> lilypad.solver.v1.DealPricing
+	5,  // 9: lilypad.solver.v1.JobOffer.timeouts:type_name -> lilypad.solver.v1.DealTimeouts
+	6,  // 10: lilypad.solver.v1.JobOffer.trusted_parties:type_name -> lilypad.solver.v1.ServiceConfig
+	7,  // 11: lilypad.solver.v1.JobOffer.target:type_name -> lilypad.solver.v1.TargetConfig
+	8,  // 12: lilypad.solver.v1.JobOffer.locality:type_name -> lilypad.solver.v1.LocalityPreference
+	9,  // 13: lilypad.solver.v1.JobOffer.requirements:type_name -> lilypad.solver.v1.AttributeRequirement
+	10, // 14: lilypad.solver.v1.JobOffer.mediation:type_name -> lilypad.solver.v1.MediationPolicy
+	11, // 15: lilypad.solver.v1.JobOfferContainer.job_offer:type_name -> lilypad.solver.v1.JobOffer
+	1,  // 16: lilypad.solver.v1.ResourceOffer.spec:type_name -> lilypad.solver.v1.MachineSpec
+	3,  // 17: lilypad.solver.v1.ResourceOffer.default_pricing:type_name -> lilypad.solver.v1.DealPricing
+	5,  // 18: lilypad.solver.v1.ResourceOffer.default_timeouts:type_name -> lilypad.solver.v1.DealTimeouts
+	37, // 19: lilypad.solver.v1.ResourceOffer.module_pricing:type_name -> lilypad.solver.v1.ResourceOffer.ModulePricingEntry
+	38, // 20: lilypad.solver.v1.ResourceOffer.module_timeouts:type_name -> lilypad.solver.v1.ResourceOffer.ModuleTimeoutsEntry
+	6,  // 21: lilypad.solver.v1.ResourceOffer.trusted_parties:type_name -> lilypad.solver.v1.ServiceConfig
+	39, // 22: lilypad.solver.v1.ResourceOffer.attributes:type_name -> lilypad.solver.v1.ResourceOffer.AttributesEntry
+	13, // 23: lilypad.solver.v1.ResourceOffer.availability:type_name -> lilypad.solver.v1.AvailabilityWindow
+	14, // 24: lilypad.solver.v1.ResourceOfferContainer.resource_offer:type_name -> lilypad.solver.v1.ResourceOffer
+	16, // 25: lilypad.solver.v1.Deal.members:type_name -> lilypad.solver.v1.DealMembers
+	3,  // 26: lilypad.solver.v1.Deal.pricing:type_name -> lilypad.solver.v1.DealPricing
+	5,  // 27: lilypad.solver.v1.Deal.timeouts:type_name -> lilypad.solver.v1.DealTimeouts
+	11, // 28: lilypad.solver.v1.Deal.job_offer:type_name -> lilypad.solver.v1.JobOffer
+	14, // 29: lilypad.solver.v1.Deal.resource_offer:type_name -> lilypad.solver.v1.ResourceOffer
+	19, // 30: lilypad.solver.v1.DealTransactions.resource_provider:type_name -> lilypad.solver.v1.DealTransactionsResourceProvider
+	18, // 31: lilypad.solver.v1.DealTransactions.job_creator:type_name -> lilypad.solver.v1.DealTransactionsJobCreator
+	20, // 32: lilypad.solver.v1.DealTransactions.mediator:type_name -> lilypad.solver.v1.DealTransactionsMediator
+	17, // 33: lilypad.solver.v1.DealContainer.deal:type_name -> lilypad.solver.v1.Deal
+	21, // 34: lilypad.solver.v1.DealContainer.transactions:type_name -> lilypad.solver.v1.DealTransactions
+	12, // 35: lilypad.solver.v1.GetJobOffersResponse.job_offers:type_name -> lilypad.solver.v1.JobOfferContainer
+	11, // 36: lilypad.solver.v1.AddJobOfferRequest.job_offer:type_name -> lilypad.solver.v1.JobOffer
+	40, // 37: lilypad.solver.v1.GetResourceOffersRequest.attributes:type_name -> lilypad.solver.v1.GetResourceOffersRequest.AttributesEntry
+	15, // 38: lilypad.solver.v1.GetResourceOffersResponse.resource_offers:type_name -> lilypad.solver.v1.ResourceOfferContainer
+	14, // 39: lilypad.solver.v1.AddResourceOfferRequest.resource_offer:type_name -> lilypad.solver.v1.ResourceOffer
+	22, // 40: lilypad.solver.v1.GetDealsResponse.deals:type_name -> lilypad.solver.v1.DealContainer
+	22, // 41: lilypad.solver.v1.DealEvent.deal:type_name -> lilypad.solver.v1.DealContainer
+	23, // 42: lilypad.solver.v1.DealEvent.result:type_name -> lilypad.solver.v1.Result
+	3,  // 43: lilypad.solver.v1.ResourceOffer.ModulePricingEntry.value:type_name -> lilypad.solver.v1.DealPricing
+	5,  // 44: lilypad.solver.v1.ResourceOffer.ModuleTimeoutsEntry.value:type_name -> lilypad.solver.v1.DealTimeouts
+	24, // 45: lilypad.solver.v1.Solver.GetJobOffers:input_type -> lilypad.solver.v1.GetJobOffersRequest
+	26, // 46: lilypad.solver.v1.Solver.AddJobOffer:input_type -> lilypad.solver.v1.AddJobOfferRequest
+	27, // 47: lilypad.solver.v1.Solver.GetResourceOffers:input_type -> lilypad.solver.v1.GetResourceOffersRequest
+	29, // 48: lilypad.solver.v1.Solver.AddResourceOffer:input_type -> lilypad.solver.v1.AddResourceOfferRequest
+	30, // 49: lilypad.solver.v1.Solver.GetDeals:input_type -> lilypad.solver.v1.GetDealsRequest
+	32, // 50: lilypad.solver.v1.Solver.GetDeal:input_type -> lilypad.solver.v1.GetDealRequest
+	33, // 51: lilypad.solver.v1.Solver.GetResult:input_type -> lilypad.solver.v1.GetResultRequest
+	34, // 52: lilypad.solver.v1.Solver.WatchDeal:input_type -> lilypad.solver.v1.WatchDealRequest
+	25, // 53: lilypad.solver.v1.Solver.GetJobOffers:output_type -> lilypad.solver.v1.GetJobOffersResponse
+	12, // 54: lilypad.solver.v1.Solver.AddJobOffer:output_type -> lilypad.solver.v1.JobOfferContainer
+	28, // 55: lilypad.solver.v1.Solver.GetResourceOffers:output_type -> lilypad.solver.v1.GetResourceOffersResponse
+	15, // 56: lilypad.solver.v1.Solver.AddResourceOffer:output_type -> lilypad.solver.v1.ResourceOfferContainer
+	31, // 57: lilypad.solver.v1.Solver.GetDeals:output_type -> lilypad.solver.v1.GetDealsResponse
+	22, // 58: lilypad.solver.v1.Solver.GetDeal:output_type -> lilypad.solver.v1.DealContainer
+	23, // 59: lilypad.solver.v1.Solver.GetResult:output_type -> lilypad.solver.v1.Result
+	35, // 60: lilypad.solver.v1.Solver.WatchDeal:output_type -> lilypad.solver.v1.DealEvent
+	53, // [53:61] is the sub-list for method output_type
+	45, // [45:53] is the sub-list for method input_type
+	45, // [45:45] is the sub-list for extension type_name
+	45, // [45:45] is the sub-list for extension extendee
+	0,  // [0:45] is the sub-list for field type_name
+}
+
+func init() { file_solver_proto_init() }
+func file_solver_proto_init() {
+	if File_solver_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_solver_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   41,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_solver_proto_goTypes,
+		DependencyIndexes: file_solver_proto_depIdxs,
+		MessageInfos:      file_solver_proto_msgTypes,
+	}.Build()
+	File_solver_proto = out.File
+	file_solver_proto_rawDesc = nil
+	file_solver_proto_goTypes = nil
+	file_solver_proto_depIdxs = nil
+}
@@ -0,0 +1,243 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	corehttp "net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/apierrors"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// how many events we keep per deal so a reconnecting client can catch up
+	dealEventHistory = 100
+	// how many deals we keep events for, the oldest deal is forgotten first
+	dealEventDeals = 1000
+	// a comment is sent this often so proxies do not close an idle stream
+	dealEventKeepalive = 30 * time.Second
+)
+
+type dealEvent struct {
+	id    uint64
+	event SolverEvent
+}
+
+// keeps a short history of the events for each deal so server-sent event
+// clients can resume from the Last-Event-ID they saw before disconnecting
+//
+// event ids count up across every deal so an id is never reused,
+// the history only lives in memory so ids start again after a restart
+type dealEventLog struct {
+	mutex       sync.Mutex
+	lastID      uint64
+	events      map[string][]dealEvent
+	order       []string
+	subscribers map[string]map[chan dealEvent]bool
+}
+
+func newDealEventLog() *dealEventLog {
+	return &dealEventLog{
+		events:      map[string][]dealEvent{},
+		order:       []string{},
+		subscribers: map[string]map[chan dealEvent]bool{},
+	}
+}
+
+func (eventLog *dealEventLog) add(ev SolverEvent) {
+	if ev.Deal == nil {
+		return
+	}
+	eventLog.mutex.Lock()
+	defer eventLog.mutex.Unlock()
+
+	dealID := ev.Deal.ID
+	eventLog.lastID++
+	entry := dealEvent{id: eventLog.lastID, event: ev}
+
+	history, ok := eventLog.events[dealID]
+	if !ok {
+		eventLog.order = append(eventLog.order, dealID)
+		if len(eventLog.order) > dealEventDeals {
+			delete(eventLog.events, eventLog.order[0])
+			eventLog.order = eventLog.order[1:]
+		}
+	}
+	history = append(history, entry)
+	if len(history) > dealEventHistory {
+		history = history[len(history)-dealEventHistory:]
+	}
+	eventLog.events[dealID] = history
+
+	for ch := range eventLog.subscribers[dealID] {
+		select {
+		case ch <- entry:
+		default:
+			// a client that cannot keep up will catch up from the
+			// history when it reconnects with its Last-Event-ID
+			log.Debug().Msgf("dropping event %d for slow deal %s subscriber", entry.id, dealID)
+		}
+	}
+}
+
+// returns the events after the given id and a channel for the ones that follow
+// the caller must call the returned function once it stops listening
+func (eventLog *dealEventLog) subscribe(dealID string, after uint64) ([]dealEvent, chan dealEvent, func()) {
+	eventLog.mutex.Lock()
+	defer eventLog.mutex.Unlock()
+
+	backlog := []dealEvent{}
+	for _, entry := range eventLog.events[dealID] {
+		if entry.id > after {
+			backlog = append(backlog, entry)
+		}
+	}
+
+	ch := make(chan dealEvent, dealEventHistory)
+	if eventLog.subscribers[dealID] == nil {
+		eventLog.subscribers[dealID] = map[chan dealEvent]bool{}
+	}
+	eventLog.subscribers[dealID][ch] = true
+
+	return backlog, ch, func() {
+		eventLog.mutex.Lock()
+		defer eventLog.mutex.Unlock()
+		delete(eventLog.subscribers[dealID], ch)
+		if len(eventLog.subscribers[dealID]) == 0 {
+			delete(eventLog.subscribers, dealID)
+		}
+	}
+}
+
+func writeDealEvent(res corehttp.ResponseWriter, entry dealEvent) error {
+	evBytes, err := json.Marshal(entry.event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", entry.id, entry.event.EventType, evBytes)
+	return err
+}
+
+func isEventParty(ev SolverEvent, address string) bool {
+	return slices.ContainsFunc(ev.addresses(), func(party string) bool { return strings.EqualFold(party, address) })
+}
+
+// the events say who was paid what and where the results are so only
+// the parties to the deal and the solver that forwarded it can follow it,
+// the filter is for the events of the deal the signer can see
+func (solverServer *solverServer) dealEventsFilter(deal *data.DealContainer, signerAddress string) (func(dealEvent) bool, error) {
+	origin := solverServer.isJobOfferOrigin(deal.JobOffer, signerAddress)
+	if !origin && !isEventParty(SolverEvent{Deal: deal}, signerAddress) {
+		return nil, http.HTTPError{
+			Message:    fmt.Sprintf("%s is not a party to deal %s", signerAddress, deal.ID),
+			StatusCode: corehttp.StatusForbidden,
+			Code:       apierrors.UnauthorizedParty,
+		}
+	}
+	return func(entry dealEvent) bool {
+		return origin || isEventParty(entry.event, signerAddress)
+	}, nil
+}
+
+// streams the events for one deal as server-sent events for clients that cannot use websockets
+func (solverServer *solverServer) streamDealEvents(res corehttp.ResponseWriter, req *corehttp.Request) {
+	vars := mux.Vars(req)
+	id := vars["id"]
+
+	flusher, ok := res.(corehttp.Flusher)
+	if !ok {
+		http.WriteErrorMessage(res, req, "streaming is not supported", corehttp.StatusInternalServerError)
+		return
+	}
+
+	deal, err := solverServer.store.GetDeal(id)
+	if err != nil {
+		log.Error().Err(err).Msgf("error loading deal")
+		http.WriteErrorMessage(res, req, err.Error(), corehttp.StatusInternalServerError)
+		return
+	}
+	if deal == nil {
+		http.WriteError(res, req, dealNotFound(id))
+		return
+	}
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		http.WriteError(res, req, err)
+		return
+	}
+	visible, err := solverServer.dealEventsFilter(deal, signerAddress)
+	if err != nil {
+		http.WriteError(res, req, err)
+		return
+	}
+
+	// browsers send the header when they reconnect, the query param
+	// is for clients that cannot set headers on the initial request
+	lastEventID := req.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = req.URL.Query().Get("last_event_id")
+	}
+	var after uint64
+	if lastEventID != "" {
+		after, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.WriteErrorMessage(res, req, fmt.Sprintf("invalid Last-Event-ID %q", lastEventID), corehttp.StatusBadRequest)
+			return
+		}
+	}
+
+	// the server write timeout is for plain requests, a stream is
+	// open for as long as the client wants its events
+	if err := corehttp.NewResponseController(res).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msgf("error clearing the write deadline for deal %s events", id)
+	}
+
+	backlog, events, cancel := solverServer.dealEvents.subscribe(id, after)
+	defer cancel()
+
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(corehttp.StatusOK)
+
+	for _, entry := range backlog {
+		if !visible(entry) {
+			continue
+		}
+		if err := writeDealEvent(res, entry); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(dealEventKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case entry := <-events:
+			if !visible(entry) {
+				continue
+			}
+			if err := writeDealEvent(res, entry); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(res, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
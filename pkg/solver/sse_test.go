@@ -0,0 +1,65 @@
+//go:build unit
+
+package solver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	lilypadhttp "github.com/lilypad-tech/lilypad/pkg/http"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
+	"github.com/lilypad-tech/lilypad/pkg/web3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamDealEventsParties(t *testing.T) {
+	jobCreatorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	strangerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	jobCreator := web3.NewKeySigner(jobCreatorKey)
+	stranger := web3.NewKeySigner(strangerKey)
+
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	deal := data.DealContainer{
+		ID:               "deal",
+		JobCreator:       jobCreator.Address().String(),
+		ResourceProvider: "0x0000000000000000000000000000000000000001",
+	}
+	_, err = memoryStore.AddDeal(deal)
+	require.NoError(t, err)
+
+	server := &solverServer{store: memoryStore, dealEvents: newDealEventLog()}
+	server.dealEvents.add(SolverEvent{EventType: DealStateUpdated, Deal: &deal})
+
+	stream := func(signer web3.Signer) *httptest.ResponseRecorder {
+		retryReq, err := retryablehttp.NewRequest(http.MethodGet, "/api/v1/deals/deal/events", nil)
+		require.NoError(t, err)
+		if signer != nil {
+			require.NoError(t, lilypadhttp.AddHeaders(retryReq, signer, signer.Address().String()))
+		}
+		// the stream runs until the client goes away
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		req := mux.SetURLVars(retryReq.Request.WithContext(ctx), map[string]string{"id": "deal"})
+		res := httptest.NewRecorder()
+		server.streamDealEvents(res, req)
+		return res
+	}
+
+	require.Equal(t, http.StatusUnauthorized, stream(nil).Code)
+	require.Equal(t, http.StatusForbidden, stream(stranger).Code)
+
+	res := stream(jobCreator)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.True(t, strings.Contains(res.Body.String(), "event: "+string(DealStateUpdated)), res.Body.String())
+}
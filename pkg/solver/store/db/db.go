@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -40,9 +41,24 @@ func NewSolverStoreDatabase(connStr string, gormLogLevel string) (*SolverStoreDa
 
 	db.AutoMigrate(&JobOffer{})
 	db.AutoMigrate(&ResourceOffer{})
+	db.AutoMigrate(&ResourceOfferAttribute{})
 	db.AutoMigrate(&Deal{})
 	db.AutoMigrate(&Result{})
 	db.AutoMigrate(&MatchDecision{})
+	db.AutoMigrate(&AuctionBid{})
+	db.AutoMigrate(&ScheduledMatch{})
+	db.AutoMigrate(&APIKey{})
+	db.AutoMigrate(&IdempotentResponse{})
+	db.AutoMigrate(&Webhook{})
+	db.AutoMigrate(&WebhookDelivery{})
+	db.AutoMigrate(&AuditEntry{})
+	db.AutoMigrate(&ChainEvent{})
+	db.AutoMigrate(&ChainEventAddress{})
+	db.AutoMigrate(&DataMigration{})
+
+	if err := runDataMigrations(db, dataMigrations); err != nil {
+		return nil, err
+	}
 
 	return &SolverStoreDatabase{db}, nil
 }
@@ -52,6 +68,7 @@ func (store *SolverStoreDatabase) AddJobOffer(jobOffer data.JobOfferContainer) (
 		CID:        jobOffer.ID,
 		JobCreator: jobOffer.JobCreator,
 		DealID:     jobOffer.DealID,
+		Series:     jobOffer.JobOffer.Series,
 		State:      jobOffer.State,
 		Attributes: datatypes.NewJSONType(jobOffer),
 	}
@@ -73,9 +90,24 @@ func (store *SolverStoreDatabase) AddResourceOffer(resourceOffer data.ResourceOf
 		Attributes:       datatypes.NewJSONType(resourceOffer),
 	}
 
-	result := store.db.Create(&record)
-	if result.Error != nil {
-		return nil, result.Error
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		for name, value := range resourceOffer.ResourceOffer.Attributes {
+			attribute := ResourceOfferAttribute{
+				ResourceOffer: resourceOffer.ID,
+				Name:          name,
+				Value:         value,
+			}
+			if err := tx.Create(&attribute).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &resourceOffer, nil
@@ -114,12 +146,13 @@ func (store *SolverStoreDatabase) AddResult(result data.Result) (*data.Result, e
 	return &result, nil
 }
 
-func (store *SolverStoreDatabase) AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error) {
+func (store *SolverStoreDatabase) AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool, reason string) (*data.MatchDecision, error) {
 	decision := &data.MatchDecision{
 		ResourceOffer: resourceOffer,
 		JobOffer:      jobOffer,
 		Deal:          deal,
 		Result:        result,
+		Reason:        reason,
 	}
 	record := MatchDecision{
 		ResourceOffer: resourceOffer,
@@ -135,8 +168,149 @@ func (store *SolverStoreDatabase) AddMatchDecision(resourceOffer string, jobOffe
 	return decision, nil
 }
 
-func (store *SolverStoreDatabase) GetJobOffers(query store.GetJobOffersQuery) ([]data.JobOfferContainer, error) {
-	q := store.db.Where([]JobOffer{})
+func (store *SolverStoreDatabase) AddAuctionBid(bid data.AuctionBid) (*data.AuctionBid, error) {
+	record := AuctionBid{
+		JobOffer:      bid.JobOffer,
+		ResourceOffer: bid.ResourceOffer,
+		Attributes:    datatypes.NewJSONType(bid),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &bid, nil
+}
+
+func (store *SolverStoreDatabase) AddScheduledMatch(match data.ScheduledMatch) (*data.ScheduledMatch, error) {
+	record := ScheduledMatch{
+		JobOffer:      match.JobOffer,
+		ResourceOffer: match.ResourceOffer,
+		StartsAt:      match.StartsAt,
+		Attributes:    datatypes.NewJSONType(match),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &match, nil
+}
+
+func (store *SolverStoreDatabase) AddAPIKey(key data.APIKey) (*data.APIKey, error) {
+	record := APIKey{
+		KeyID:      key.ID,
+		Hash:       key.Hash,
+		Attributes: datatypes.NewJSONType(key),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &key, nil
+}
+
+func (store *SolverStoreDatabase) AddIdempotentResponse(response data.IdempotentResponse) (*data.IdempotentResponse, error) {
+	record := IdempotentResponse{
+		ResponseKey: response.Key,
+		RecordedAt:  response.CreatedAt,
+		Attributes:  datatypes.NewJSONType(response),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &response, nil
+}
+
+func (store *SolverStoreDatabase) AddWebhook(webhook data.Webhook) (*data.Webhook, error) {
+	record := Webhook{
+		WebhookID:  webhook.ID,
+		Owner:      webhook.Owner,
+		Secret:     webhook.Secret,
+		Attributes: datatypes.NewJSONType(webhook),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &webhook, nil
+}
+
+func (store *SolverStoreDatabase) AddWebhookDelivery(delivery data.WebhookDelivery) (*data.WebhookDelivery, error) {
+	record := WebhookDelivery{
+		DeliveryID: delivery.ID,
+		WebhookID:  delivery.WebhookID,
+		Attributes: datatypes.NewJSONType(delivery),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &delivery, nil
+}
+
+func (store *SolverStoreDatabase) AddAuditEntry(entry data.AuditEntry) (*data.AuditEntry, error) {
+	record := AuditEntry{
+		EntryID:    entry.ID,
+		Address:    entry.Address,
+		Method:     entry.Method,
+		Route:      entry.Route,
+		RecordedAt: entry.CreatedAt,
+		Attributes: datatypes.NewJSONType(entry),
+	}
+
+	res := store.db.Create(&record)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &entry, nil
+}
+
+func (store *SolverStoreDatabase) AddChainEvents(events []data.ChainEvent) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			var count int64
+			if err := tx.Model(&ChainEvent{}).Where("event_id = ?", event.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			record := ChainEvent{
+				EventID:    event.ID,
+				DealID:     event.DealID,
+				Contract:   event.Contract,
+				Event:      event.Event,
+				Block:      event.Block,
+				Attributes: datatypes.NewJSONType(event),
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+			for _, address := range event.Addresses {
+				if err := tx.Create(&ChainEventAddress{EventID: event.ID, Address: address}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (store *SolverStoreDatabase) jobOffersQuery(query store.GetJobOffersQuery) *gorm.DB {
+	q := store.db.Model(&JobOffer{})
 
 	// Apply filters
 	if query.JobCreator != "" {
@@ -145,9 +319,17 @@ func (store *SolverStoreDatabase) GetJobOffers(query store.GetJobOffersQuery) ([
 	if query.NotMatched {
 		q = q.Where("deal_id = ''")
 	}
+	if query.Series != "" {
+		q = q.Where("series = ?", query.Series)
+	}
 	if !query.IncludeCancelled {
 		q = q.Where("state != ?", data.GetAgreementStateIndex("JobOfferCancelled"))
 	}
+	return q
+}
+
+func (store *SolverStoreDatabase) GetJobOffers(query store.GetJobOffersQuery) ([]data.JobOfferContainer, error) {
+	q := pageIfAsked(store.jobOffersQuery(query), "c_id", query.Page)
 
 	var records []JobOffer
 	if err := q.Find(&records).Error; err != nil {
@@ -162,8 +344,14 @@ func (store *SolverStoreDatabase) GetJobOffers(query store.GetJobOffersQuery) ([
 	return jobOffers, nil
 }
 
-func (store *SolverStoreDatabase) GetResourceOffers(query store.GetResourceOffersQuery) ([]data.ResourceOfferContainer, error) {
-	q := store.db.Where([]ResourceOffer{})
+func (store *SolverStoreDatabase) CountJobOffers(query store.GetJobOffersQuery) (int64, error) {
+	var count int64
+	err := store.jobOffersQuery(query).Count(&count).Error
+	return count, err
+}
+
+func (store *SolverStoreDatabase) resourceOffersQuery(query store.GetResourceOffersQuery) *gorm.DB {
+	q := store.db.Model(&ResourceOffer{})
 
 	// Apply filters
 	if query.ResourceProvider != "" {
@@ -178,6 +366,16 @@ func (store *SolverStoreDatabase) GetResourceOffers(query store.GetResourceOffer
 			data.GetAgreementStateIndex("DealAgreed"),
 		})
 	}
+	for name, value := range query.Attributes {
+		q = q.Where("c_id IN (?)", store.db.Model(&ResourceOfferAttribute{}).
+			Select("resource_offer").
+			Where("name = ? AND value = ?", name, value))
+	}
+	return q
+}
+
+func (store *SolverStoreDatabase) GetResourceOffers(query store.GetResourceOffersQuery) ([]data.ResourceOfferContainer, error) {
+	q := pageIfAsked(store.resourceOffersQuery(query), "c_id", query.Page)
 
 	var records []ResourceOffer
 	if err := q.Find(&records).Error; err != nil {
@@ -192,8 +390,14 @@ func (store *SolverStoreDatabase) GetResourceOffers(query store.GetResourceOffer
 	return resourceOffers, nil
 }
 
-func (store *SolverStoreDatabase) GetDeals(query store.GetDealsQuery) ([]data.DealContainer, error) {
-	q := store.db.Where([]Deal{})
+func (store *SolverStoreDatabase) CountResourceOffers(query store.GetResourceOffersQuery) (int64, error) {
+	var count int64
+	err := store.resourceOffersQuery(query).Count(&count).Error
+	return count, err
+}
+
+func (store *SolverStoreDatabase) dealsQuery(query store.GetDealsQuery) (*gorm.DB, error) {
+	q := store.db.Model(&Deal{})
 
 	// Apply filters
 	if query.JobCreator != "" {
@@ -212,6 +416,15 @@ func (store *SolverStoreDatabase) GetDeals(query store.GetDealsQuery) ([]data.De
 		}
 		q = q.Where("state = ?", parsedState)
 	}
+	return q, nil
+}
+
+func (store *SolverStoreDatabase) GetDeals(query store.GetDealsQuery) ([]data.DealContainer, error) {
+	q, err := store.dealsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	q = pageIfAsked(q, "c_id", query.Page)
 
 	var records []Deal
 	if err := q.Find(&records).Error; err != nil {
@@ -226,6 +439,16 @@ func (store *SolverStoreDatabase) GetDeals(query store.GetDealsQuery) ([]data.De
 	return deals, nil
 }
 
+func (store *SolverStoreDatabase) CountDeals(query store.GetDealsQuery) (int64, error) {
+	q, err := store.dealsQuery(query)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = q.Count(&count).Error
+	return count, err
+}
+
 func (store *SolverStoreDatabase) GetDealsAll() ([]data.DealContainer, error) {
 	var records []Deal
 	if err := store.db.Find(&records).Error; err != nil {
@@ -254,6 +477,26 @@ func (store *SolverStoreDatabase) GetResults() ([]data.Result, error) {
 	return results, nil
 }
 
+func (store *SolverStoreDatabase) GetResultsPage(page store.PageQuery) ([]data.Result, error) {
+	var records []Result
+	if err := pageIfAsked(store.db.Model(&Result{}), "deal_id", page).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]data.Result, len(records))
+	for i, record := range records {
+		results[i] = record.Attributes.Data()
+	}
+
+	return results, nil
+}
+
+func (store *SolverStoreDatabase) CountResults() (int64, error) {
+	var count int64
+	err := store.db.Model(&Result{}).Count(&count).Error
+	return count, err
+}
+
 func (store *SolverStoreDatabase) GetMatchDecisions() ([]data.MatchDecision, error) {
 	var records []MatchDecision
 	if err := store.db.Find(&records).Error; err != nil {
@@ -268,6 +511,20 @@ func (store *SolverStoreDatabase) GetMatchDecisions() ([]data.MatchDecision, err
 	return decisions, nil
 }
 
+func (store *SolverStoreDatabase) GetJobOfferMatchDecisions(jobOffer string) ([]data.MatchDecision, error) {
+	var records []MatchDecision
+	if err := store.db.Where("job_offer = ?", jobOffer).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	decisions := make([]data.MatchDecision, len(records))
+	for i, record := range records {
+		decisions[i] = record.Attributes.Data()
+	}
+
+	return decisions, nil
+}
+
 func (store *SolverStoreDatabase) GetJobOffer(id string) (*data.JobOfferContainer, error) {
 	// Offers are unique by CID, so we can query first
 	var record JobOffer
@@ -365,6 +622,226 @@ func (store *SolverStoreDatabase) GetMatchDecision(resourceOffer string, jobOffe
 	return &decision, nil
 }
 
+func (store *SolverStoreDatabase) GetAuctionBids(jobOffer string) ([]data.AuctionBid, error) {
+	var records []AuctionBid
+	if err := store.db.Where("job_offer = ?", jobOffer).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	bids := make([]data.AuctionBid, len(records))
+	for i, record := range records {
+		bids[i] = record.Attributes.Data()
+	}
+
+	return bids, nil
+}
+
+func (store *SolverStoreDatabase) GetScheduledMatches() ([]data.ScheduledMatch, error) {
+	var records []ScheduledMatch
+	if err := store.db.Order("starts_at").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	matches := make([]data.ScheduledMatch, len(records))
+	for i, record := range records {
+		matches[i] = record.Attributes.Data()
+	}
+
+	return matches, nil
+}
+
+func (store *SolverStoreDatabase) GetAPIKeys() ([]data.APIKey, error) {
+	var records []APIKey
+	if err := store.db.Order("created_at").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]data.APIKey, len(records))
+	for i, record := range records {
+		keys[i] = apiKeyFromRecord(record)
+	}
+
+	return keys, nil
+}
+
+func (store *SolverStoreDatabase) GetAPIKeyByHash(hash string) (*data.APIKey, error) {
+	var record APIKey
+	result := store.db.Where("hash = ?", hash).First(&record)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	key := apiKeyFromRecord(record)
+	return &key, nil
+}
+
+// the hash is left out of the json so it comes from its own column
+func (store *SolverStoreDatabase) GetIdempotentResponse(key string) (*data.IdempotentResponse, error) {
+	var record IdempotentResponse
+	result := store.db.Where("response_key = ?", key).First(&record)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	response := record.Attributes.Data()
+	return &response, nil
+}
+
+func (store *SolverStoreDatabase) GetWebhooks(owner string) ([]data.Webhook, error) {
+	q := store.db.Order("created_at")
+	if owner != "" {
+		q = q.Where("owner = ?", owner)
+	}
+	var records []Webhook
+	if err := q.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]data.Webhook, len(records))
+	for i, record := range records {
+		webhooks[i] = webhookFromRecord(record)
+	}
+
+	return webhooks, nil
+}
+
+func (store *SolverStoreDatabase) GetWebhook(id string) (*data.Webhook, error) {
+	var record Webhook
+	result := store.db.Where("webhook_id = ?", id).First(&record)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	webhook := webhookFromRecord(record)
+	return &webhook, nil
+}
+
+// the secret is left out of the json so it comes from its own column
+func webhookFromRecord(record Webhook) data.Webhook {
+	webhook := record.Attributes.Data()
+	webhook.Secret = record.Secret
+	return webhook
+}
+
+func (store *SolverStoreDatabase) GetWebhookDeliveries(webhookID string) ([]data.WebhookDelivery, error) {
+	var records []WebhookDelivery
+	if err := store.db.Where("webhook_id = ?", webhookID).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]data.WebhookDelivery, len(records))
+	for i, record := range records {
+		deliveries[i] = record.Attributes.Data()
+	}
+
+	return deliveries, nil
+}
+
+func (store *SolverStoreDatabase) auditEntriesQuery(query store.GetAuditEntriesQuery) *gorm.DB {
+	q := store.db.Model(&AuditEntry{})
+
+	// Apply filters
+	if query.Address != "" {
+		q = q.Where("address = ?", query.Address)
+	}
+	if query.Method != "" {
+		q = q.Where("method = ?", query.Method)
+	}
+	if query.Route != "" {
+		q = q.Where("route = ?", query.Route)
+	}
+	if query.Since > 0 {
+		q = q.Where("recorded_at >= ?", query.Since)
+	}
+	if query.Until > 0 {
+		q = q.Where("recorded_at < ?", query.Until)
+	}
+	return q
+}
+
+func (store *SolverStoreDatabase) GetAuditEntries(query store.GetAuditEntriesQuery) ([]data.AuditEntry, error) {
+	q := paginate(store.auditEntriesQuery(query), "entry_id", query.Page)
+
+	var records []AuditEntry
+	if err := q.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]data.AuditEntry, len(records))
+	for i, record := range records {
+		entries[i] = record.Attributes.Data()
+	}
+
+	return entries, nil
+}
+
+func (store *SolverStoreDatabase) CountAuditEntries(query store.GetAuditEntriesQuery) (int64, error) {
+	var count int64
+	err := store.auditEntriesQuery(query).Count(&count).Error
+	return count, err
+}
+
+func (store *SolverStoreDatabase) chainEventsQuery(query store.GetChainEventsQuery) *gorm.DB {
+	q := store.db.Model(&ChainEvent{})
+
+	// Apply filters
+	if query.DealID != "" {
+		q = q.Where("deal_id = ?", query.DealID)
+	}
+	if query.Address != "" {
+		q = q.Where("event_id IN (?)", store.db.Model(&ChainEventAddress{}).
+			Select("event_id").
+			Where("address = ?", query.Address))
+	}
+	if query.Contract != "" {
+		q = q.Where("contract = ?", query.Contract)
+	}
+	if query.Event != "" {
+		q = q.Where("event = ?", query.Event)
+	}
+	if query.FromBlock > 0 {
+		q = q.Where("block >= ?", query.FromBlock)
+	}
+	if query.ToBlock > 0 {
+		q = q.Where("block <= ?", query.ToBlock)
+	}
+	return q
+}
+
+func (store *SolverStoreDatabase) GetChainEvents(query store.GetChainEventsQuery) ([]data.ChainEvent, error) {
+	q := paginate(store.chainEventsQuery(query), "event_id", query.Page)
+
+	var records []ChainEvent
+	if err := q.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]data.ChainEvent, len(records))
+	for i, record := range records {
+		events[i] = record.Attributes.Data()
+	}
+
+	return events, nil
+}
+
+func (store *SolverStoreDatabase) CountChainEvents(query store.GetChainEventsQuery) (int64, error) {
+	var count int64
+	err := store.chainEventsQuery(query).Count(&count).Error
+	return count, err
+}
+
+func apiKeyFromRecord(record APIKey) data.APIKey {
+	key := record.Attributes.Data()
+	key.Hash = record.Hash
+	return key
+}
+
 func (store *SolverStoreDatabase) UpdateJobOfferState(id string, dealID string, state uint8) (*data.JobOfferContainer, error) {
 	var record JobOffer
 	result := store.db.Where("c_id = ?", id).First(&record)
@@ -394,6 +871,32 @@ func (store *SolverStoreDatabase) UpdateJobOfferState(id string, dealID string,
 	return &inner, nil
 }
 
+func (store *SolverStoreDatabase) UpdateJobOfferForwardedTo(id string, peer string) (*data.JobOfferContainer, error) {
+	var record JobOffer
+	result := store.db.Where("c_id = ?", id).First(&record)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("job offer not found: %s", id)
+		}
+		return nil, result.Error
+	}
+
+	// the forwarding is only kept in the jsonb data
+	inner := record.Attributes.Data()
+	inner.ForwardedTo = peer
+
+	if err := store.db.Model(&record).
+		Select("Attributes").
+		Updates(JobOffer{
+			Attributes: datatypes.NewJSONType(inner),
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return &inner, nil
+}
+
 func (store *SolverStoreDatabase) UpdateResourceOfferState(id string, dealID string, state uint8) (*data.ResourceOfferContainer, error) {
 	var record ResourceOffer
 	result := store.db.Where("c_id = ?", id).First(&record)
@@ -477,6 +980,31 @@ func (store *SolverStoreDatabase) UpdateDealMediator(id string, mediator string)
 	return &inner, nil
 }
 
+func (store *SolverStoreDatabase) UpdateDealSecrets(id string, secrets string) (*data.DealContainer, error) {
+	var record Deal
+	result := store.db.Where("c_id = ?", id).First(&record)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("deal not found: %s", id)
+		}
+		return nil, result.Error
+	}
+
+	inner := record.Attributes.Data()
+	inner.Secrets = secrets
+
+	if err := store.db.Model(&record).
+		Select("Attributes").
+		Updates(Deal{
+			Attributes: datatypes.NewJSONType(inner),
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return &inner, nil
+}
+
 func (store *SolverStoreDatabase) UpdateDealTransactionsJobCreator(id string, data data.DealTransactionsJobCreator) (*data.DealContainer, error) {
 	var record Deal
 	result := store.db.Where("c_id = ?", id).First(&record)
@@ -490,7 +1018,7 @@ func (store *SolverStoreDatabase) UpdateDealTransactionsJobCreator(id string, da
 
 	// Update the jsonb data
 	inner := record.Attributes.Data()
-	inner.Transactions.JobCreator = data
+	inner.Transactions.JobCreator = inner.Transactions.JobCreator.Merge(data)
 
 	if err := store.db.Model(&record).
 		Select("Attributes").
@@ -516,7 +1044,7 @@ func (store *SolverStoreDatabase) UpdateDealTransactionsResourceProvider(id stri
 
 	// Update the jsonb data
 	inner := record.Attributes.Data()
-	inner.Transactions.ResourceProvider = data
+	inner.Transactions.ResourceProvider = inner.Transactions.ResourceProvider.Merge(data)
 
 	if err := store.db.Model(&record).
 		Select("Attributes").
@@ -542,7 +1070,7 @@ func (store *SolverStoreDatabase) UpdateDealTransactionsMediator(id string, data
 
 	// Update the jsonb data
 	inner := record.Attributes.Data()
-	inner.Transactions.Mediator = data
+	inner.Transactions.Mediator = inner.Transactions.Mediator.Merge(data)
 
 	if err := store.db.Model(&record).
 		Select("Attributes").
@@ -570,6 +1098,10 @@ func (store *SolverStoreDatabase) RemoveResourceOffer(id string) error {
 	if result.Error != nil {
 		return result.Error
 	}
+	result = store.db.Where("resource_offer = ?", id).Delete(&ResourceOfferAttribute{})
+	if result.Error != nil {
+		return result.Error
+	}
 	return nil
 }
 
@@ -615,7 +1147,90 @@ func (store *SolverStoreDatabase) RemoveMatchDecision(resourceOffer string, jobO
 	return nil
 }
 
+func (store *SolverStoreDatabase) RemoveScheduledMatch(jobOffer string) error {
+	// Unscoped so the unique job offer index is free if the offer is scheduled again
+	result := store.db.Unscoped().Where("job_offer = ?", jobOffer).Delete(&ScheduledMatch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (store *SolverStoreDatabase) RemoveAPIKey(id string) error {
+	// Unscoped so a removed key cannot be found by its hash again
+	result := store.db.Unscoped().Where("key_id = ?", id).Delete(&APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (store *SolverStoreDatabase) RemoveIdempotentResponsesBefore(createdAt int64) error {
+	// Unscoped so the keys can be used again once the responses expire
+	result := store.db.Unscoped().Where("recorded_at < ?", createdAt).Delete(&IdempotentResponse{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (store *SolverStoreDatabase) RemoveWebhook(id string) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("webhook_id = ?", id).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("webhook_id = ?", id).Delete(&Webhook{}).Error
+	})
+}
+
+func (store *SolverStoreDatabase) RemoveAuditEntriesBefore(createdAt int64) error {
+	result := store.db.Unscoped().Where("recorded_at < ?", createdAt).Delete(&AuditEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (store *SolverStoreDatabase) Ping(ctx context.Context) error {
+	db, err := store.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
 // Strictly speaking, the compiler will check the interface
 // implementation without this check. But some code editors
 // report errors more effectively when we have it.
 var _ store.SolverStore = (*SolverStoreDatabase)(nil)
+
+// orders by the id column so pages line up with the memory store
+func paginate(q *gorm.DB, column string, page store.PageQuery) *gorm.DB {
+	if page.Order == store.OrderDescending {
+		q = q.Order(column + " DESC")
+		if page.Cursor != "" {
+			q = q.Where(column+" < ?", page.Cursor)
+		}
+	} else {
+		q = q.Order(column + " ASC")
+		if page.Cursor != "" {
+			q = q.Where(column+" > ?", page.Cursor)
+		}
+	}
+	if page.Offset > 0 {
+		q = q.Offset(page.Offset)
+	}
+	if page.Limit > 0 {
+		q = q.Limit(page.Limit)
+	}
+	return q
+}
+
+// leaves the query in whatever order the database returns it unless
+// the client asked for a page
+func pageIfAsked(q *gorm.DB, column string, page store.PageQuery) *gorm.DB {
+	if !page.Paged() {
+		return q
+	}
+	return paginate(q, column, page)
+}
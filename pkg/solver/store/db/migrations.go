@@ -0,0 +1,63 @@
+package store
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// a data migration that has run, auto migrate only changes the schema so
+// rows that have to be rewritten are done once by name
+type DataMigration struct {
+	gorm.Model
+	Name string `gorm:"uniqueIndex"`
+}
+
+type dataMigration struct {
+	name string
+	run  func(tx *gorm.DB) error
+}
+
+// in the order they run, a migration is never changed once it has shipped
+var dataMigrations = []dataMigration{
+	{name: "agreement-state-timeout-agree", run: migrateTimeoutAgree},
+}
+
+// TimeoutAgree went in at 7 to match the contract, every state from 7 on
+// moved up by one in the columns and in the jsonb the stores read back
+func migrateTimeoutAgree(tx *gorm.DB) error {
+	for _, table := range []string{"job_offers", "resource_offers", "deals"} {
+		err := tx.Exec(fmt.Sprintf(
+			`UPDATE %s SET state = state + 1, attributes = jsonb_set(attributes::jsonb, '{state}', to_jsonb(state + 1)) WHERE state >= 7`,
+			table,
+		)).Error
+		if err != nil {
+			return fmt.Errorf("error remapping the agreement states of %s: %s", table, err.Error())
+		}
+	}
+	return nil
+}
+
+// each migration runs in a transaction with its record, a solver starting
+// alongside another fails to add the record and rolls its run back
+func runDataMigrations(db *gorm.DB, migrations []dataMigration) error {
+	for _, migration := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var count int64
+			if err := tx.Model(&DataMigration{}).Where("name = ?", migration.name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+			if err := migration.run(tx); err != nil {
+				return err
+			}
+			return tx.Create(&DataMigration{Name: migration.name}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("error running data migration %s: %s", migration.name, err.Error())
+		}
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ type JobOffer struct {
 	CID        string `gorm:"index"`
 	JobCreator string `gorm:"index"`
 	DealID     string `gorm:"index"`
+	Series     string `gorm:"index"`
 	State      uint8
 	Attributes datatypes.JSONType[data.JobOfferContainer]
 }
@@ -24,6 +25,14 @@ type ResourceOffer struct {
 	Attributes       datatypes.JSONType[data.ResourceOfferContainer]
 }
 
+// one row per attribute so we can query resource offers by capability
+type ResourceOfferAttribute struct {
+	gorm.Model
+	ResourceOffer string `gorm:"index"`
+	Name          string `gorm:"index:idx_resource_offer_attribute"`
+	Value         string `gorm:"index:idx_resource_offer_attribute"`
+}
+
 type Deal struct {
 	gorm.Model
 	CID              string `gorm:"index"`
@@ -47,3 +56,76 @@ type MatchDecision struct {
 	JobOffer      string `gorm:"primaryKey"`
 	Attributes    datatypes.JSONType[data.MatchDecision]
 }
+
+type AuctionBid struct {
+	gorm.Model
+	JobOffer      string `gorm:"index"`
+	ResourceOffer string
+	Attributes    datatypes.JSONType[data.AuctionBid]
+}
+
+type APIKey struct {
+	gorm.Model
+	KeyID      string `gorm:"uniqueIndex"`
+	Hash       string `gorm:"uniqueIndex"`
+	Attributes datatypes.JSONType[data.APIKey]
+}
+
+type IdempotentResponse struct {
+	gorm.Model
+	ResponseKey string `gorm:"uniqueIndex"`
+	// millisecond timestamp, the expired responses are removed by it
+	RecordedAt int64 `gorm:"index"`
+	Attributes datatypes.JSONType[data.IdempotentResponse]
+}
+
+type Webhook struct {
+	gorm.Model
+	WebhookID  string `gorm:"uniqueIndex"`
+	Owner      string `gorm:"index"`
+	Secret     string
+	Attributes datatypes.JSONType[data.Webhook]
+}
+
+type WebhookDelivery struct {
+	gorm.Model
+	DeliveryID string `gorm:"uniqueIndex"`
+	WebhookID  string `gorm:"index"`
+	Attributes datatypes.JSONType[data.WebhookDelivery]
+}
+
+type AuditEntry struct {
+	gorm.Model
+	EntryID string `gorm:"uniqueIndex"`
+	Address string `gorm:"index"`
+	Method  string
+	Route   string `gorm:"index"`
+	// millisecond timestamp, the time filters and expiry use it
+	RecordedAt int64 `gorm:"index"`
+	Attributes datatypes.JSONType[data.AuditEntry]
+}
+
+type ChainEvent struct {
+	gorm.Model
+	EventID    string `gorm:"uniqueIndex"`
+	DealID     string `gorm:"index"`
+	Contract   string `gorm:"index:idx_chain_event_name"`
+	Event      string `gorm:"index:idx_chain_event_name"`
+	Block      uint64 `gorm:"index"`
+	Attributes datatypes.JSONType[data.ChainEvent]
+}
+
+// one row for each address in a chain event's arguments
+type ChainEventAddress struct {
+	gorm.Model
+	EventID string `gorm:"index"`
+	Address string `gorm:"index"`
+}
+
+type ScheduledMatch struct {
+	gorm.Model
+	JobOffer      string `gorm:"uniqueIndex"`
+	ResourceOffer string `gorm:"index"`
+	StartsAt      int64  `gorm:"index"`
+	Attributes    datatypes.JSONType[data.ScheduledMatch]
+}
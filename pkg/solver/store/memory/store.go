@@ -1,7 +1,10 @@
 package store
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 
@@ -15,6 +18,14 @@ type SolverStoreMemory struct {
 	dealMap          map[string]*data.DealContainer
 	resultMap        map[string]*data.Result
 	matchDecisionMap map[string]*data.MatchDecision
+	auctionBidMap    map[string][]data.AuctionBid
+	scheduledMap     map[string]*data.ScheduledMatch
+	apiKeyMap        map[string]*data.APIKey
+	idempotentMap    map[string]*data.IdempotentResponse
+	webhookMap       map[string]*data.Webhook
+	deliveryMap      map[string][]data.WebhookDelivery
+	auditMap         map[string]*data.AuditEntry
+	chainEventMap    map[string]*data.ChainEvent
 	mutex            sync.RWMutex
 }
 
@@ -25,6 +36,14 @@ func NewSolverStoreMemory() (*SolverStoreMemory, error) {
 		dealMap:          map[string]*data.DealContainer{},
 		resultMap:        map[string]*data.Result{},
 		matchDecisionMap: map[string]*data.MatchDecision{},
+		auctionBidMap:    map[string][]data.AuctionBid{},
+		scheduledMap:     map[string]*data.ScheduledMatch{},
+		apiKeyMap:        map[string]*data.APIKey{},
+		idempotentMap:    map[string]*data.IdempotentResponse{},
+		webhookMap:       map[string]*data.Webhook{},
+		deliveryMap:      map[string][]data.WebhookDelivery{},
+		auditMap:         map[string]*data.AuditEntry{},
+		chainEventMap:    map[string]*data.ChainEvent{},
 	}, nil
 }
 
@@ -60,7 +79,7 @@ func (s *SolverStoreMemory) AddResult(result data.Result) (*data.Result, error)
 	return &result, nil
 }
 
-func (s *SolverStoreMemory) AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error) {
+func (s *SolverStoreMemory) AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool, reason string) (*data.MatchDecision, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	id := store.GetMatchID(resourceOffer, jobOffer)
@@ -73,34 +92,161 @@ func (s *SolverStoreMemory) AddMatchDecision(resourceOffer string, jobOffer stri
 		JobOffer:      jobOffer,
 		Deal:          deal,
 		Result:        result,
+		Reason:        reason,
 	}
 	s.matchDecisionMap[id] = decision
 
 	return decision, nil
 }
 
+func (s *SolverStoreMemory) AddAuctionBid(bid data.AuctionBid) (*data.AuctionBid, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.auctionBidMap[bid.JobOffer] = append(s.auctionBidMap[bid.JobOffer], bid)
+
+	return &bid, nil
+}
+
+func (s *SolverStoreMemory) AddAPIKey(key data.APIKey) (*data.APIKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.apiKeyMap[key.ID]
+	if ok {
+		return nil, fmt.Errorf("api key already exists: %s", key.ID)
+	}
+	s.apiKeyMap[key.ID] = &key
+
+	return &key, nil
+}
+
+func (s *SolverStoreMemory) AddIdempotentResponse(response data.IdempotentResponse) (*data.IdempotentResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.idempotentMap[response.Key]
+	if ok {
+		return nil, fmt.Errorf("idempotent response already exists: %s", response.Key)
+	}
+	s.idempotentMap[response.Key] = &response
+
+	return &response, nil
+}
+
+func (s *SolverStoreMemory) AddWebhook(webhook data.Webhook) (*data.Webhook, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.webhookMap[webhook.ID]
+	if ok {
+		return nil, fmt.Errorf("webhook already exists: %s", webhook.ID)
+	}
+	s.webhookMap[webhook.ID] = &webhook
+
+	return &webhook, nil
+}
+
+func (s *SolverStoreMemory) AddWebhookDelivery(delivery data.WebhookDelivery) (*data.WebhookDelivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.deliveryMap[delivery.WebhookID] = append(s.deliveryMap[delivery.WebhookID], delivery)
+
+	return &delivery, nil
+}
+
+func (s *SolverStoreMemory) AddAuditEntry(entry data.AuditEntry) (*data.AuditEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.auditMap[entry.ID]
+	if ok {
+		return nil, fmt.Errorf("audit entry already exists: %s", entry.ID)
+	}
+	s.auditMap[entry.ID] = &entry
+
+	return &entry, nil
+}
+
+func (s *SolverStoreMemory) AddChainEvents(events []data.ChainEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := range events {
+		if _, ok := s.chainEventMap[events[i].ID]; !ok {
+			event := events[i]
+			s.chainEventMap[event.ID] = &event
+		}
+	}
+
+	return nil
+}
+
+func (s *SolverStoreMemory) AddScheduledMatch(match data.ScheduledMatch) (*data.ScheduledMatch, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.scheduledMap[match.JobOffer]
+	if ok {
+		return nil, fmt.Errorf("job offer is already scheduled: %s", match.JobOffer)
+	}
+	s.scheduledMap[match.JobOffer] = &match
+
+	return &match, nil
+}
+
+func jobOfferMatches(query store.GetJobOffersQuery, jobOffer *data.JobOfferContainer) bool {
+	if query.JobCreator != "" && jobOffer.JobCreator != query.JobCreator {
+		return false
+	}
+	if query.NotMatched && jobOffer.DealID != "" {
+		return false
+	}
+	if query.Series != "" && jobOffer.JobOffer.Series != query.Series {
+		return false
+	}
+	if !query.IncludeCancelled && jobOffer.State == data.GetAgreementStateIndex("JobOfferCancelled") {
+		return false
+	}
+	return true
+}
+
 func (s *SolverStoreMemory) GetJobOffers(query store.GetJobOffersQuery) ([]data.JobOfferContainer, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 	jobOffers := []data.JobOfferContainer{}
 	for _, jobOffer := range s.jobOfferMap {
-		matching := true
-		if query.JobCreator != "" && jobOffer.JobCreator != query.JobCreator {
-			matching = false
-		}
-		if query.NotMatched {
-			if jobOffer.DealID != "" {
-				matching = false
-			}
+		if jobOfferMatches(query, jobOffer) {
+			jobOffers = append(jobOffers, *jobOffer)
 		}
-		if !query.IncludeCancelled && jobOffer.State == data.GetAgreementStateIndex("JobOfferCancelled") {
-			matching = false
+	}
+	if !query.Page.Paged() {
+		return jobOffers, nil
+	}
+	return store.Paginate(jobOffers, func(jobOffer data.JobOfferContainer) string { return jobOffer.ID }, query.Page), nil
+}
+
+func (s *SolverStoreMemory) CountJobOffers(query store.GetJobOffersQuery) (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var count int64
+	for _, jobOffer := range s.jobOfferMap {
+		if jobOfferMatches(query, jobOffer) {
+			count++
 		}
-		if matching {
-			jobOffers = append(jobOffers, *jobOffer)
+	}
+	return count, nil
+}
+
+func resourceOfferMatches(query store.GetResourceOffersQuery, resourceOffer *data.ResourceOfferContainer) bool {
+	if query.ResourceProvider != "" && resourceOffer.ResourceProvider != query.ResourceProvider {
+		return false
+	}
+	if query.Active && !data.IsActiveAgreementState(resourceOffer.State) {
+		return false
+	}
+	if query.NotMatched && resourceOffer.DealID != "" {
+		return false
+	}
+	for name, value := range query.Attributes {
+		if resourceOffer.ResourceOffer.Attributes[name] != value {
+			return false
 		}
 	}
-	return jobOffers, nil
+	return true
 }
 
 func (s *SolverStoreMemory) GetResourceOffers(query store.GetResourceOffersQuery) ([]data.ResourceOfferContainer, error) {
@@ -108,28 +254,45 @@ func (s *SolverStoreMemory) GetResourceOffers(query store.GetResourceOffersQuery
 	defer s.mutex.RUnlock()
 	resourceOffers := []data.ResourceOfferContainer{}
 	for _, resourceOffer := range s.resourceOfferMap {
-		matching := true
-		if query.ResourceProvider != "" && resourceOffer.ResourceProvider != query.ResourceProvider {
-			matching = false
-		}
-		if query.Active && !data.IsActiveAgreementState(resourceOffer.State) {
-			matching = false
-		}
-		if query.NotMatched {
-			if resourceOffer.DealID != "" {
-				matching = false
-			}
-		}
-		if matching {
+		if resourceOfferMatches(query, resourceOffer) {
 			resourceOffers = append(resourceOffers, *resourceOffer)
 		}
 	}
-	return resourceOffers, nil
+	if !query.Page.Paged() {
+		return resourceOffers, nil
+	}
+	return store.Paginate(resourceOffers, func(resourceOffer data.ResourceOfferContainer) string { return resourceOffer.ID }, query.Page), nil
 }
 
-func (s *SolverStoreMemory) GetDeals(query store.GetDealsQuery) ([]data.DealContainer, error) {
+func (s *SolverStoreMemory) CountResourceOffers(query store.GetResourceOffersQuery) (int64, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
+	var count int64
+	for _, resourceOffer := range s.resourceOfferMap {
+		if resourceOfferMatches(query, resourceOffer) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func dealMatches(query store.GetDealsQuery, queryState uint8, deal *data.DealContainer) bool {
+	if query.JobCreator != "" && deal.JobCreator != query.JobCreator {
+		return false
+	}
+	if query.ResourceProvider != "" && deal.ResourceProvider != query.ResourceProvider {
+		return false
+	}
+	if query.Mediator != "" && deal.Mediator != query.Mediator {
+		return false
+	}
+	if query.State != "" && deal.State != queryState {
+		return false
+	}
+	return true
+}
+
+func (s *SolverStoreMemory) filterDeals(query store.GetDealsQuery) ([]data.DealContainer, error) {
 	deals := []data.DealContainer{}
 	queryState := uint8(0)
 	if query.State != "" {
@@ -140,26 +303,36 @@ func (s *SolverStoreMemory) GetDeals(query store.GetDealsQuery) ([]data.DealCont
 		queryState = parsedState
 	}
 	for _, deal := range s.dealMap {
-		matching := true
-		if query.JobCreator != "" && deal.JobCreator != query.JobCreator {
-			matching = false
-		}
-		if query.ResourceProvider != "" && deal.ResourceProvider != query.ResourceProvider {
-			matching = false
-		}
-		if query.Mediator != "" && deal.Mediator != query.Mediator {
-			matching = false
-		}
-		if query.State != "" && deal.State != queryState {
-			matching = false
-		}
-		if matching {
+		if dealMatches(query, queryState, deal) {
 			deals = append(deals, *deal)
 		}
 	}
 	return deals, nil
 }
 
+func (s *SolverStoreMemory) GetDeals(query store.GetDealsQuery) ([]data.DealContainer, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	deals, err := s.filterDeals(query)
+	if err != nil {
+		return nil, err
+	}
+	if !query.Page.Paged() {
+		return deals, nil
+	}
+	return store.Paginate(deals, func(deal data.DealContainer) string { return deal.ID }, query.Page), nil
+}
+
+func (s *SolverStoreMemory) CountDeals(query store.GetDealsQuery) (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	deals, err := s.filterDeals(query)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(deals)), nil
+}
+
 func (s *SolverStoreMemory) GetDealsAll() ([]data.DealContainer, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -180,6 +353,25 @@ func (s *SolverStoreMemory) GetResults() ([]data.Result, error) {
 	return results, nil
 }
 
+func (s *SolverStoreMemory) GetResultsPage(page store.PageQuery) ([]data.Result, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	results := []data.Result{}
+	for _, result := range s.resultMap {
+		results = append(results, *result)
+	}
+	if !page.Paged() {
+		return results, nil
+	}
+	return store.Paginate(results, func(result data.Result) string { return result.DealID }, page), nil
+}
+
+func (s *SolverStoreMemory) CountResults() (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return int64(len(s.resultMap)), nil
+}
+
 func (s *SolverStoreMemory) GetMatchDecisions() ([]data.MatchDecision, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -190,6 +382,18 @@ func (s *SolverStoreMemory) GetMatchDecisions() ([]data.MatchDecision, error) {
 	return results, nil
 }
 
+func (s *SolverStoreMemory) GetJobOfferMatchDecisions(jobOffer string) ([]data.MatchDecision, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	results := []data.MatchDecision{}
+	for _, decision := range s.matchDecisionMap {
+		if decision.JobOffer == jobOffer {
+			results = append(results, *decision)
+		}
+	}
+	return results, nil
+}
+
 func (s *SolverStoreMemory) GetJobOffer(id string) (*data.JobOfferContainer, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -252,6 +456,178 @@ func (s *SolverStoreMemory) GetMatchDecision(resourceOffer string, jobOffer stri
 	return decision, nil
 }
 
+func (s *SolverStoreMemory) GetAuctionBids(jobOffer string) ([]data.AuctionBid, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	bids := []data.AuctionBid{}
+	bids = append(bids, s.auctionBidMap[jobOffer]...)
+	return bids, nil
+}
+
+func (s *SolverStoreMemory) GetAPIKeys() ([]data.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := []data.APIKey{}
+	for _, key := range s.apiKeyMap {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt < keys[j].CreatedAt })
+	return keys, nil
+}
+
+func (s *SolverStoreMemory) GetAPIKeyByHash(hash string) (*data.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, key := range s.apiKeyMap {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *SolverStoreMemory) GetIdempotentResponse(key string) (*data.IdempotentResponse, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	response, ok := s.idempotentMap[key]
+	if !ok {
+		return nil, nil
+	}
+	return response, nil
+}
+
+func (s *SolverStoreMemory) GetWebhooks(owner string) ([]data.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	webhooks := []data.Webhook{}
+	for _, webhook := range s.webhookMap {
+		if owner == "" || webhook.Owner == owner {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].CreatedAt < webhooks[j].CreatedAt })
+	return webhooks, nil
+}
+
+func (s *SolverStoreMemory) GetWebhook(id string) (*data.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	webhook, ok := s.webhookMap[id]
+	if !ok {
+		return nil, nil
+	}
+	return webhook, nil
+}
+
+func (s *SolverStoreMemory) GetWebhookDeliveries(webhookID string) ([]data.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	deliveries := []data.WebhookDelivery{}
+	deliveries = append(deliveries, s.deliveryMap[webhookID]...)
+	return deliveries, nil
+}
+
+func auditEntryMatches(query store.GetAuditEntriesQuery, entry *data.AuditEntry) bool {
+	if query.Address != "" && entry.Address != query.Address {
+		return false
+	}
+	if query.Method != "" && entry.Method != query.Method {
+		return false
+	}
+	if query.Route != "" && entry.Route != query.Route {
+		return false
+	}
+	if query.Since > 0 && entry.CreatedAt < query.Since {
+		return false
+	}
+	if query.Until > 0 && entry.CreatedAt >= query.Until {
+		return false
+	}
+	return true
+}
+
+func (s *SolverStoreMemory) GetAuditEntries(query store.GetAuditEntriesQuery) ([]data.AuditEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	entries := []data.AuditEntry{}
+	for _, entry := range s.auditMap {
+		if auditEntryMatches(query, entry) {
+			entries = append(entries, *entry)
+		}
+	}
+	return store.Paginate(entries, func(entry data.AuditEntry) string { return entry.ID }, query.Page), nil
+}
+
+func (s *SolverStoreMemory) CountAuditEntries(query store.GetAuditEntriesQuery) (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var count int64
+	for _, entry := range s.auditMap {
+		if auditEntryMatches(query, entry) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func chainEventMatches(query store.GetChainEventsQuery, event *data.ChainEvent) bool {
+	if query.DealID != "" && event.DealID != query.DealID {
+		return false
+	}
+	if query.Address != "" && !slices.Contains(event.Addresses, query.Address) {
+		return false
+	}
+	if query.Contract != "" && event.Contract != query.Contract {
+		return false
+	}
+	if query.Event != "" && event.Event != query.Event {
+		return false
+	}
+	if query.FromBlock > 0 && event.Block < query.FromBlock {
+		return false
+	}
+	if query.ToBlock > 0 && event.Block > query.ToBlock {
+		return false
+	}
+	return true
+}
+
+func (s *SolverStoreMemory) GetChainEvents(query store.GetChainEventsQuery) ([]data.ChainEvent, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	events := []data.ChainEvent{}
+	for _, event := range s.chainEventMap {
+		if chainEventMatches(query, event) {
+			events = append(events, *event)
+		}
+	}
+	return store.Paginate(events, func(event data.ChainEvent) string { return event.ID }, query.Page), nil
+}
+
+func (s *SolverStoreMemory) CountChainEvents(query store.GetChainEventsQuery) (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var count int64
+	for _, event := range s.chainEventMap {
+		if chainEventMatches(query, event) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *SolverStoreMemory) GetScheduledMatches() ([]data.ScheduledMatch, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	matches := []data.ScheduledMatch{}
+	for _, match := range s.scheduledMap {
+		matches = append(matches, *match)
+	}
+	// soonest first like the database
+	sort.Slice(matches, func(i, j int) bool { return matches[i].StartsAt < matches[j].StartsAt })
+	return matches, nil
+}
+
 func (s *SolverStoreMemory) UpdateJobOfferState(id string, dealID string, state uint8) (*data.JobOfferContainer, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -265,6 +641,18 @@ func (s *SolverStoreMemory) UpdateJobOfferState(id string, dealID string, state
 	return jobOffer, nil
 }
 
+func (s *SolverStoreMemory) UpdateJobOfferForwardedTo(id string, peer string) (*data.JobOfferContainer, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	jobOffer, ok := s.jobOfferMap[id]
+	if !ok {
+		return nil, fmt.Errorf("job offer not found: %s", id)
+	}
+	jobOffer.ForwardedTo = peer
+	s.jobOfferMap[id] = jobOffer
+	return jobOffer, nil
+}
+
 func (s *SolverStoreMemory) UpdateResourceOfferState(id string, dealID string, state uint8) (*data.ResourceOfferContainer, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -302,29 +690,27 @@ func (s *SolverStoreMemory) UpdateDealMediator(id string, mediator string) (*dat
 	return deal, nil
 }
 
-func (s *SolverStoreMemory) UpdateDealTransactionsResourceProvider(id string, data data.DealTransactionsResourceProvider) (*data.DealContainer, error) {
+func (s *SolverStoreMemory) UpdateDealSecrets(id string, secrets string) (*data.DealContainer, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	deal, ok := s.dealMap[id]
 	if !ok {
 		return nil, fmt.Errorf("deal not found: %s", id)
 	}
-	txs := &deal.Transactions.ResourceProvider
-	if data.Agree != "" {
-		txs.Agree = data.Agree
-	}
-	if data.AddResult != "" {
-		txs.AddResult = data.AddResult
-	}
-	if data.TimeoutAgree != "" {
-		txs.TimeoutAgree = data.TimeoutAgree
-	}
-	if data.TimeoutJudgeResult != "" {
-		txs.TimeoutJudgeResult = data.TimeoutJudgeResult
-	}
-	if data.TimeoutMediateResult != "" {
-		txs.TimeoutMediateResult = data.TimeoutMediateResult
+	deal.Secrets = secrets
+	s.dealMap[id] = deal
+	return deal, nil
+}
+
+func (s *SolverStoreMemory) UpdateDealTransactionsResourceProvider(id string, data data.DealTransactionsResourceProvider) (*data.DealContainer, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	deal, ok := s.dealMap[id]
+	if !ok {
+		return nil, fmt.Errorf("deal not found: %s", id)
 	}
+	deal.Transactions.ResourceProvider = deal.Transactions.ResourceProvider.Merge(data)
+	s.dealMap[id] = deal
 	return deal, nil
 }
 func (s *SolverStoreMemory) UpdateDealTransactionsJobCreator(id string, data data.DealTransactionsJobCreator) (*data.DealContainer, error) {
@@ -334,25 +720,7 @@ func (s *SolverStoreMemory) UpdateDealTransactionsJobCreator(id string, data dat
 	if !ok {
 		return nil, fmt.Errorf("deal not found: %s", id)
 	}
-	txs := &deal.Transactions.JobCreator
-	if data.Agree != "" {
-		txs.Agree = data.Agree
-	}
-	if data.AcceptResult != "" {
-		txs.AcceptResult = data.AcceptResult
-	}
-	if data.CheckResult != "" {
-		txs.CheckResult = data.CheckResult
-	}
-	if data.TimeoutAgree != "" {
-		txs.TimeoutAgree = data.TimeoutAgree
-	}
-	if data.TimeoutSubmitResult != "" {
-		txs.TimeoutSubmitResult = data.TimeoutSubmitResult
-	}
-	if data.TimeoutMediateResult != "" {
-		txs.TimeoutMediateResult = data.TimeoutMediateResult
-	}
+	deal.Transactions.JobCreator = deal.Transactions.JobCreator.Merge(data)
 	s.dealMap[id] = deal
 	return deal, nil
 }
@@ -364,13 +732,7 @@ func (s *SolverStoreMemory) UpdateDealTransactionsMediator(id string, data data.
 	if !ok {
 		return nil, fmt.Errorf("deal not found: %s", id)
 	}
-	txs := &deal.Transactions.Mediator
-	if data.MediationAcceptResult != "" {
-		txs.MediationAcceptResult = data.MediationAcceptResult
-	}
-	if data.MediationRejectResult != "" {
-		txs.MediationRejectResult = data.MediationRejectResult
-	}
+	deal.Transactions.Mediator = deal.Transactions.Mediator.Merge(data)
 	s.dealMap[id] = deal
 	return deal, nil
 }
@@ -414,6 +776,55 @@ func (s *SolverStoreMemory) RemoveMatchDecision(resourceOffer string, jobOffer s
 	return nil
 }
 
+func (s *SolverStoreMemory) RemoveScheduledMatch(jobOffer string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.scheduledMap, jobOffer)
+	return nil
+}
+
+func (s *SolverStoreMemory) RemoveAPIKey(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.apiKeyMap, id)
+	return nil
+}
+
+func (s *SolverStoreMemory) RemoveIdempotentResponsesBefore(createdAt int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, response := range s.idempotentMap {
+		if response.CreatedAt < createdAt {
+			delete(s.idempotentMap, key)
+		}
+	}
+	return nil
+}
+
+func (s *SolverStoreMemory) RemoveWebhook(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.webhookMap, id)
+	delete(s.deliveryMap, id)
+	return nil
+}
+
+func (s *SolverStoreMemory) RemoveAuditEntriesBefore(createdAt int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, entry := range s.auditMap {
+		if entry.CreatedAt < createdAt {
+			delete(s.auditMap, id)
+		}
+	}
+	return nil
+}
+
+// the maps are always there
+func (s *SolverStoreMemory) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Strictly speaking, the compiler will check the interface
 // implementation without this check. But some code editors
 // report errors more effectively when we have it.
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+type StoreChangeType string
+
+const (
+	JobOfferAddedChange        StoreChangeType = "JobOfferAdded"
+	ResourceOfferAddedChange   StoreChangeType = "ResourceOfferAdded"
+	JobOfferRemovedChange      StoreChangeType = "JobOfferRemoved"
+	ResourceOfferRemovedChange StoreChangeType = "ResourceOfferRemoved"
+)
+
+type StoreChange struct {
+	Type StoreChangeType
+	ID   string
+}
+
+// wraps a solver store and tells subscribers when offers are added or removed
+// so the solver can match straight away instead of waiting for the next tick
+type NotifyingStore struct {
+	SolverStore
+	mutex       sync.RWMutex
+	subscribers []func(StoreChange)
+}
+
+func NewNotifyingStore(store SolverStore) *NotifyingStore {
+	return &NotifyingStore{
+		SolverStore: store,
+		subscribers: []func(StoreChange){},
+	}
+}
+
+func (s *NotifyingStore) Subscribe(handler func(StoreChange)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscribers = append(s.subscribers, handler)
+}
+
+func (s *NotifyingStore) notify(change StoreChange) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, handler := range s.subscribers {
+		handler(change)
+	}
+}
+
+func (s *NotifyingStore) AddJobOffer(jobOffer data.JobOfferContainer) (*data.JobOfferContainer, error) {
+	ret, err := s.SolverStore.AddJobOffer(jobOffer)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(StoreChange{Type: JobOfferAddedChange, ID: jobOffer.ID})
+	return ret, nil
+}
+
+func (s *NotifyingStore) AddResourceOffer(resourceOffer data.ResourceOfferContainer) (*data.ResourceOfferContainer, error) {
+	ret, err := s.SolverStore.AddResourceOffer(resourceOffer)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(StoreChange{Type: ResourceOfferAddedChange, ID: resourceOffer.ID})
+	return ret, nil
+}
+
+func (s *NotifyingStore) RemoveJobOffer(id string) error {
+	err := s.SolverStore.RemoveJobOffer(id)
+	if err != nil {
+		return err
+	}
+	s.notify(StoreChange{Type: JobOfferRemovedChange, ID: id})
+	return nil
+}
+
+func (s *NotifyingStore) RemoveResourceOffer(id string) error {
+	err := s.SolverStore.RemoveResourceOffer(id)
+	if err != nil {
+		return err
+	}
+	s.notify(StoreChange{Type: ResourceOfferRemovedChange, ID: id})
+	return nil
+}
+
+// Compile-time interface check:
+var _ SolverStore = (*NotifyingStore)(nil)
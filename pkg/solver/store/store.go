@@ -1,7 +1,9 @@
 package store
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"github.com/lilypad-tech/lilypad/pkg/data"
 )
@@ -12,6 +14,30 @@ type StoreOptions struct {
 	GormLogLevel string
 }
 
+const (
+	OrderAscending  = "asc"
+	OrderDescending = "desc"
+)
+
+// which slice of a list query to return, results are ordered by id
+// so a page is stable while new rows are being added
+// a zero limit returns everything after the offset or cursor
+type PageQuery struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// the last id of the previous page, this is applied before the offset
+	Cursor string `json:"cursor"`
+	// asc or desc, empty means asc
+	Order string `json:"order"`
+}
+
+// whether the client asked for a page at all, the plain list queries
+// skip the ordering and the count when it did not as most callers
+// just want every row and the sort is not free on a big table
+func (page PageQuery) Paged() bool {
+	return page.Limit > 0 || page.Cursor != "" || page.Offset > 0 || page.Order != ""
+}
+
 type GetJobOffersQuery struct {
 	JobCreator string `json:"job_creator"`
 	// this means job offers that have not been matched at all yet
@@ -22,6 +48,11 @@ type GetJobOffersQuery struct {
 
 	// this will include cancelled job offers in the results
 	IncludeCancelled bool `json:"include_cancelled"`
+
+	// only the runs of this recurring job
+	Series string `json:"series"`
+
+	Page PageQuery `json:"page"`
 }
 
 type GetResourceOffersQuery struct {
@@ -44,6 +75,11 @@ type GetResourceOffersQuery struct {
 
 	// we use the DealID property of the resourceOfferContainer to tell if it's been matched
 	NotMatched bool `json:"not_matched"`
+
+	// only resource offers that have all of these attribute values
+	Attributes map[string]string `json:"attributes"`
+
+	Page PageQuery `json:"page"`
 }
 
 type GetDealsQuery struct {
@@ -53,6 +89,32 @@ type GetDealsQuery struct {
 
 	// only deals that are in this state will be returned
 	State string `json:"state"`
+
+	Page PageQuery `json:"page"`
+}
+
+type GetAuditEntriesQuery struct {
+	Address string `json:"address"`
+	Method  string `json:"method"`
+	Route   string `json:"route"`
+	// millisecond timestamps, since is inclusive, until is not and zero means no bound
+	Since int64 `json:"since"`
+	Until int64 `json:"until"`
+
+	Page PageQuery `json:"page"`
+}
+
+type GetChainEventsQuery struct {
+	DealID string `json:"deal_id"`
+	// lower case, an event matches when the address is one of its arguments
+	Address  string `json:"address"`
+	Contract string `json:"contract"`
+	Event    string `json:"event"`
+	// both inclusive, zero means no bound
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+
+	Page PageQuery `json:"page"`
 }
 
 type SolverStore interface {
@@ -60,23 +122,55 @@ type SolverStore interface {
 	AddResourceOffer(resourceOffer data.ResourceOfferContainer) (*data.ResourceOfferContainer, error)
 	AddDeal(deal data.DealContainer) (*data.DealContainer, error)
 	AddResult(result data.Result) (*data.Result, error)
-	AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error)
+	AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool, reason string) (*data.MatchDecision, error)
+	AddAuctionBid(bid data.AuctionBid) (*data.AuctionBid, error)
+	AddScheduledMatch(match data.ScheduledMatch) (*data.ScheduledMatch, error)
+	AddAPIKey(key data.APIKey) (*data.APIKey, error)
+	AddIdempotentResponse(response data.IdempotentResponse) (*data.IdempotentResponse, error)
+	AddWebhook(webhook data.Webhook) (*data.Webhook, error)
+	AddWebhookDelivery(delivery data.WebhookDelivery) (*data.WebhookDelivery, error)
+	AddAuditEntry(entry data.AuditEntry) (*data.AuditEntry, error)
+	// events that are already stored are left alone so blocks can be indexed again
+	AddChainEvents(events []data.ChainEvent) error
 	GetJobOffers(query GetJobOffersQuery) ([]data.JobOfferContainer, error)
 	GetResourceOffers(query GetResourceOffersQuery) ([]data.ResourceOfferContainer, error)
 	GetDeals(query GetDealsQuery) ([]data.DealContainer, error)
 	GetDealsAll() ([]data.DealContainer, error)
 	GetResults() ([]data.Result, error)
+	GetResultsPage(page PageQuery) ([]data.Result, error)
+	// the counts ignore the page so clients can work out how many pages there are
+	CountJobOffers(query GetJobOffersQuery) (int64, error)
+	CountResourceOffers(query GetResourceOffersQuery) (int64, error)
+	CountDeals(query GetDealsQuery) (int64, error)
+	CountResults() (int64, error)
 	GetMatchDecisions() ([]data.MatchDecision, error)
+	GetJobOfferMatchDecisions(jobOffer string) ([]data.MatchDecision, error)
 	GetJobOffer(id string) (*data.JobOfferContainer, error)
 	GetResourceOffer(id string) (*data.ResourceOfferContainer, error)
 	GetResourceOfferByAddress(address string) (*data.ResourceOfferContainer, error)
 	GetDeal(id string) (*data.DealContainer, error)
 	GetResult(id string) (*data.Result, error)
 	GetMatchDecision(resourceOffer string, jobOffer string) (*data.MatchDecision, error)
+	GetAuctionBids(jobOffer string) ([]data.AuctionBid, error)
+	GetScheduledMatches() ([]data.ScheduledMatch, error)
+	GetAPIKeys() ([]data.APIKey, error)
+	GetAPIKeyByHash(hash string) (*data.APIKey, error)
+	GetIdempotentResponse(key string) (*data.IdempotentResponse, error)
+	// every webhook when the owner is empty
+	GetWebhooks(owner string) ([]data.Webhook, error)
+	GetWebhook(id string) (*data.Webhook, error)
+	// oldest first
+	GetWebhookDeliveries(webhookID string) ([]data.WebhookDelivery, error)
+	GetAuditEntries(query GetAuditEntriesQuery) ([]data.AuditEntry, error)
+	CountAuditEntries(query GetAuditEntriesQuery) (int64, error)
+	GetChainEvents(query GetChainEventsQuery) ([]data.ChainEvent, error)
+	CountChainEvents(query GetChainEventsQuery) (int64, error)
 	UpdateJobOfferState(id string, dealID string, state uint8) (*data.JobOfferContainer, error)
+	UpdateJobOfferForwardedTo(id string, peer string) (*data.JobOfferContainer, error)
 	UpdateResourceOfferState(id string, dealID string, state uint8) (*data.ResourceOfferContainer, error)
 	UpdateDealState(id string, state uint8) (*data.DealContainer, error)
 	UpdateDealMediator(id string, mediator string) (*data.DealContainer, error)
+	UpdateDealSecrets(id string, secrets string) (*data.DealContainer, error)
 	UpdateDealTransactionsJobCreator(id string, data data.DealTransactionsJobCreator) (*data.DealContainer, error)
 	UpdateDealTransactionsResourceProvider(id string, data data.DealTransactionsResourceProvider) (*data.DealContainer, error)
 	UpdateDealTransactionsMediator(id string, data data.DealTransactionsMediator) (*data.DealContainer, error)
@@ -85,8 +179,49 @@ type SolverStore interface {
 	RemoveDeal(id string) error
 	RemoveResult(id string) error
 	RemoveMatchDecision(resourceOffer string, jobOffer string) error
+	RemoveScheduledMatch(jobOffer string) error
+	RemoveAPIKey(id string) error
+	// drop the responses made before the millisecond timestamp
+	RemoveIdempotentResponsesBefore(createdAt int64) error
+	// removes the deliveries for the webhook too
+	RemoveWebhook(id string) error
+	// drop the entries made before the millisecond timestamp
+	RemoveAuditEntriesBefore(createdAt int64) error
+	// errors when the store cannot be reached, the readiness check uses it
+	Ping(ctx context.Context) error
 }
 
 func GetMatchID(resourceOffer string, jobOffer string) string {
 	return fmt.Sprintf("%s-%s", resourceOffer, jobOffer)
 }
+
+// applies a page to rows that are already filtered, stores that
+// cannot page in their query use this so they order rows the same way
+func Paginate[T any](rows []T, id func(T) string, page PageQuery) []T {
+	descending := page.Order == OrderDescending
+	sort.Slice(rows, func(i, j int) bool {
+		if descending {
+			return id(rows[i]) > id(rows[j])
+		}
+		return id(rows[i]) < id(rows[j])
+	})
+	if page.Cursor != "" {
+		start := sort.Search(len(rows), func(i int) bool {
+			if descending {
+				return id(rows[i]) < page.Cursor
+			}
+			return id(rows[i]) > page.Cursor
+		})
+		rows = rows[start:]
+	}
+	if page.Offset > 0 {
+		if page.Offset >= len(rows) {
+			return rows[:0]
+		}
+		rows = rows[page.Offset:]
+	}
+	if page.Limit > 0 && page.Limit < len(rows) {
+		rows = rows[:page.Limit]
+	}
+	return rows
+}
@@ -4,6 +4,8 @@ package store_test
 
 import (
 	"fmt"
+	"math"
+	"reflect"
 	"slices"
 	"sort"
 	"sync"
@@ -68,6 +70,17 @@ func TestJobOfferOps(t *testing.T) {
 						newDealID, newState, updated.DealID, updated.State)
 				}
 
+				// Forward job offer
+				peer := "http://peer-solver:8080"
+				forwarded, err := store.UpdateJobOfferForwardedTo(jobOffer.ID, peer)
+				if err != nil {
+					t.Fatalf("Failed to update job offer forwarding: %v", err)
+				}
+				if forwarded.ForwardedTo != peer || forwarded.DealID != newDealID {
+					t.Errorf("Forwarding failed: expected forwardedTo=%s dealID=%s, got forwardedTo=%s dealID=%s",
+						peer, newDealID, forwarded.ForwardedTo, forwarded.DealID)
+				}
+
 				// Remove job offer
 				err = store.RemoveJobOffer(jobOffer.ID)
 				if err != nil {
@@ -183,6 +196,28 @@ func TestJobOfferQuery(t *testing.T) {
 				"QmX9JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Ky",
 			},
 		},
+		{
+			name: "filter by series",
+			offers: []data.JobOfferContainer{
+				{
+					ID:         "QmY8JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Kx",
+					JobCreator: "0x1234567890123456789012345678901234567890",
+					DealID:     "",
+					State:      0,
+					JobOffer:   data.JobOffer{Series: "QmS1JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Ks"},
+				},
+				{
+					ID:         "QmX9JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Ky",
+					JobCreator: "0x1234567890123456789012345678901234567890",
+					DealID:     "",
+					State:      0,
+				},
+			},
+			query: store.GetJobOffersQuery{
+				Series: "QmS1JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Ks",
+			},
+			expected: []string{"QmY8JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Kx"},
+		},
 		{
 			name: "combined filters",
 			offers: []data.JobOfferContainer{
@@ -438,6 +473,33 @@ func TestResourceOfferQuery(t *testing.T) {
 				"QmX9JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Ky",
 			},
 		},
+		{
+			name: "filter by attributes",
+			offers: []data.ResourceOfferContainer{
+				{
+					ID:               "QmY8JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Kx",
+					ResourceProvider: "0x1234567890123456789012345678901234567890",
+					DealID:           "",
+					State:            data.GetDefaultAgreementState(),
+					ResourceOffer: data.ResourceOffer{
+						Attributes: map[string]string{"infiniband": "true", "tpu": "v5e"},
+					},
+				},
+				{
+					ID:               "QmX9JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Ky",
+					ResourceProvider: "0x1234567890123456789012345678901234567890",
+					DealID:           "",
+					State:            data.GetDefaultAgreementState(),
+					ResourceOffer: data.ResourceOffer{
+						Attributes: map[string]string{"infiniband": "true"},
+					},
+				},
+			},
+			query: store.GetResourceOffersQuery{
+				Attributes: map[string]string{"infiniband": "true", "tpu": "v5e"},
+			},
+			expected: []string{"QmY8JwJh3bYDUuAnwfpxwStjUY1nQwyhJJ4SPpdV3bZ9Kx"},
+		},
 		{
 			name: "combined filters",
 			offers: []data.ResourceOfferContainer{
@@ -623,6 +685,77 @@ func TestDealGetAll(t *testing.T) {
 	}
 }
 
+func TestDealPagination(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			deals := generateDeals(5, 10)
+			addedIDs := make([]string, len(deals))
+			for i, deal := range deals {
+				added, err := store.AddDeal(deal)
+				if err != nil {
+					t.Fatalf("Failed to add deal: %v", err)
+				}
+				addedIDs[i] = added.ID
+			}
+			sort.Strings(addedIDs)
+
+			total, err := store.CountDeals(solverstore.GetDealsQuery{})
+			if err != nil {
+				t.Fatalf("Failed to count deals: %v", err)
+			}
+			if total != int64(len(deals)) {
+				t.Errorf("Expected a total of %d deals, got %d", len(deals), total)
+			}
+
+			// Without a page every deal comes back in no particular order
+			unpaged, err := store.GetDeals(solverstore.GetDealsQuery{})
+			if err != nil {
+				t.Fatalf("Failed to get deals: %v", err)
+			}
+			if len(unpaged) != len(deals) {
+				t.Errorf("Expected %d deals without a page, got %d", len(deals), len(unpaged))
+			}
+
+			// Walk the pages with the cursor
+			retrievedIDs := []string{}
+			page := solverstore.PageQuery{Limit: 2}
+			for {
+				deals, err := store.GetDeals(solverstore.GetDealsQuery{Page: page})
+				if err != nil {
+					t.Fatalf("Failed to get deals page: %v", err)
+				}
+				for _, deal := range deals {
+					retrievedIDs = append(retrievedIDs, deal.ID)
+				}
+				if len(deals) < page.Limit {
+					break
+				}
+				page.Cursor = deals[len(deals)-1].ID
+			}
+			if !slices.Equal(retrievedIDs, addedIDs) {
+				t.Errorf("Paged deals don't match added deals.\nAdded: %v\nRetrieved: %v",
+					addedIDs, retrievedIDs)
+			}
+
+			// Offset and descending order
+			deals2, err := store.GetDeals(solverstore.GetDealsQuery{
+				Page: solverstore.PageQuery{Limit: 1, Offset: 1, Order: solverstore.OrderDescending},
+			})
+			if err != nil {
+				t.Fatalf("Failed to get deals page: %v", err)
+			}
+			if len(deals2) != 1 || deals2[0].ID != addedIDs[len(addedIDs)-2] {
+				t.Errorf("Expected deal %s, got %v", addedIDs[len(addedIDs)-2], deals2)
+			}
+		})
+	}
+}
+
 func TestDealUpdates(t *testing.T) {
 	storeConfigs := setupStores(t)
 	for _, config := range storeConfigs {
@@ -664,6 +797,15 @@ func TestDealUpdates(t *testing.T) {
 						newMediator, updated.Mediator)
 				}
 
+				// Update deal secrets
+				updated, err = store.UpdateDealSecrets(added.ID, "c2VjcmV0cw==")
+				if err != nil {
+					t.Fatalf("Failed to update deal secrets: %v", err)
+				}
+				if updated.Secrets != "c2VjcmV0cw==" {
+					t.Errorf("Update secrets failed: expected secrets=c2VjcmV0cw==, got secrets=%s", updated.Secrets)
+				}
+
 				// Update deal job creator transactions
 				jcTxs := data.DealTransactionsJobCreator{
 					Agree:                generateEthTxHash(),
@@ -677,7 +819,7 @@ func TestDealUpdates(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Failed to update job creator transactions: %v", err)
 				}
-				if updated.Transactions.JobCreator != jcTxs {
+				if !reflect.DeepEqual(updated.Transactions.JobCreator, jcTxs) {
 					t.Error("Job creator transactions not updated correctly")
 				}
 
@@ -693,7 +835,7 @@ func TestDealUpdates(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Failed to update resource provider transactions: %v", err)
 				}
-				if updated.Transactions.ResourceProvider != rpTxs {
+				if !reflect.DeepEqual(updated.Transactions.ResourceProvider, rpTxs) {
 					t.Error("Resource provider transactions not updated correctly")
 				}
 
@@ -706,9 +848,27 @@ func TestDealUpdates(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Failed to update mediator transactions: %v", err)
 				}
-				if updatedMediatorTxs.Transactions.Mediator != mediatorTxs {
+				if !reflect.DeepEqual(updatedMediatorTxs.Transactions.Mediator, mediatorTxs) {
 					t.Error("Mediator transactions not updated correctly")
 				}
+
+				// Stuck transactions are added without clearing the hashes
+				stuck := data.DealTransactionStatus{
+					Action:     "agree",
+					Hash:       generateEthTxHash(),
+					Status:     data.DealTransactionReplaced,
+					ReplacedBy: rpTxs.Agree,
+				}
+				updated, err = store.UpdateDealTransactionsResourceProvider(added.ID, data.DealTransactionsResourceProvider{
+					Stuck: []data.DealTransactionStatus{stuck},
+				})
+				if err != nil {
+					t.Fatalf("Failed to update resource provider transactions: %v", err)
+				}
+				rpTxs.Stuck = []data.DealTransactionStatus{stuck}
+				if !reflect.DeepEqual(updated.Transactions.ResourceProvider, rpTxs) {
+					t.Error("Resource provider stuck transactions not added correctly")
+				}
 			}
 		})
 	}
@@ -971,6 +1131,362 @@ func TestResultOps(t *testing.T) {
 
 // Match decisions
 
+func TestAPIKeyOps(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			key := data.APIKey{
+				ID:        generateCID(),
+				Name:      "dashboard",
+				Hash:      generateCID(),
+				CreatedBy: generateEthAddress(),
+				CreatedAt: 1,
+			}
+			_, err := store.AddAPIKey(key)
+			if err != nil {
+				t.Fatalf("Failed to add api key: %v", err)
+			}
+
+			// Get by hash
+			retrieved, err := store.GetAPIKeyByHash(key.Hash)
+			if err != nil {
+				t.Fatalf("Failed to get api key: %v", err)
+			}
+			if retrieved == nil || *retrieved != key {
+				t.Errorf("Expected api key %+v, got %+v", key, retrieved)
+			}
+
+			keys, err := store.GetAPIKeys()
+			if err != nil {
+				t.Fatalf("Failed to get api keys: %v", err)
+			}
+			if len(keys) != 1 {
+				t.Errorf("Expected 1 api key, got %d", len(keys))
+			}
+
+			// Remove and check it can no longer be found
+			err = store.RemoveAPIKey(key.ID)
+			if err != nil {
+				t.Fatalf("Failed to remove api key: %v", err)
+			}
+			retrieved, err = store.GetAPIKeyByHash(key.Hash)
+			if err != nil {
+				t.Fatalf("Failed to get api key: %v", err)
+			}
+			if retrieved != nil {
+				t.Errorf("Expected api key to be removed, got %+v", retrieved)
+			}
+		})
+	}
+}
+
+func TestWebhookOps(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			owner := generateEthAddress()
+			webhook := data.Webhook{
+				ID:        generateCID(),
+				Owner:     owner,
+				URL:       "https://example.com/hook",
+				Secret:    generateCID(),
+				Events:    []string{"deal.created"},
+				CreatedAt: 1,
+			}
+			other := data.Webhook{
+				ID:        generateCID(),
+				Owner:     generateEthAddress(),
+				URL:       "https://example.com/other",
+				Secret:    generateCID(),
+				Events:    []string{},
+				CreatedAt: 2,
+			}
+			for _, w := range []data.Webhook{webhook, other} {
+				_, err := store.AddWebhook(w)
+				if err != nil {
+					t.Fatalf("Failed to add webhook: %v", err)
+				}
+			}
+
+			// The secret is kept even though it is not in the json
+			retrieved, err := store.GetWebhook(webhook.ID)
+			if err != nil {
+				t.Fatalf("Failed to get webhook: %v", err)
+			}
+			if retrieved == nil || retrieved.Secret != webhook.Secret || !slices.Equal(retrieved.Events, webhook.Events) {
+				t.Errorf("Expected webhook %+v, got %+v", webhook, retrieved)
+			}
+
+			owned, err := store.GetWebhooks(owner)
+			if err != nil {
+				t.Fatalf("Failed to get webhooks: %v", err)
+			}
+			if len(owned) != 1 || owned[0].ID != webhook.ID {
+				t.Errorf("Expected only the owner's webhook, got %+v", owned)
+			}
+			all, err := store.GetWebhooks("")
+			if err != nil {
+				t.Fatalf("Failed to get webhooks: %v", err)
+			}
+			if len(all) != 2 {
+				t.Errorf("Expected 2 webhooks, got %d", len(all))
+			}
+
+			// Deliveries come back oldest first
+			for attempt := 1; attempt <= 2; attempt++ {
+				_, err := store.AddWebhookDelivery(data.WebhookDelivery{
+					ID:        generateCID(),
+					WebhookID: webhook.ID,
+					EventID:   "event",
+					Event:     "deal.created",
+					Attempt:   attempt,
+					Delivered: attempt == 2,
+					CreatedAt: int64(attempt),
+				})
+				if err != nil {
+					t.Fatalf("Failed to add webhook delivery: %v", err)
+				}
+			}
+			deliveries, err := store.GetWebhookDeliveries(webhook.ID)
+			if err != nil {
+				t.Fatalf("Failed to get webhook deliveries: %v", err)
+			}
+			if len(deliveries) != 2 || deliveries[0].Attempt != 1 || !deliveries[1].Delivered {
+				t.Errorf("Expected 2 deliveries in order, got %+v", deliveries)
+			}
+
+			// Removing the webhook removes its deliveries
+			err = store.RemoveWebhook(webhook.ID)
+			if err != nil {
+				t.Fatalf("Failed to remove webhook: %v", err)
+			}
+			retrieved, err = store.GetWebhook(webhook.ID)
+			if err != nil {
+				t.Fatalf("Failed to get webhook: %v", err)
+			}
+			if retrieved != nil {
+				t.Errorf("Expected webhook to be removed, got %+v", retrieved)
+			}
+			deliveries, err = store.GetWebhookDeliveries(webhook.ID)
+			if err != nil {
+				t.Fatalf("Failed to get webhook deliveries: %v", err)
+			}
+			if len(deliveries) != 0 {
+				t.Errorf("Expected deliveries to be removed, got %d", len(deliveries))
+			}
+		})
+	}
+}
+
+func TestIdempotentResponseOps(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			response := data.IdempotentResponse{
+				Key:         generateCID(),
+				RequestHash: generateCID(),
+				StatusCode:  200,
+				Body:        `{"id":"abc"}`,
+				CreatedAt:   100,
+			}
+			_, err := store.AddIdempotentResponse(response)
+			if err != nil {
+				t.Fatalf("Failed to add idempotent response: %v", err)
+			}
+
+			// A second response with the same key is rejected
+			_, err = store.AddIdempotentResponse(response)
+			if err == nil {
+				t.Errorf("Expected an error adding a response with the same key")
+			}
+
+			retrieved, err := store.GetIdempotentResponse(response.Key)
+			if err != nil {
+				t.Fatalf("Failed to get idempotent response: %v", err)
+			}
+			if retrieved == nil || *retrieved != response {
+				t.Errorf("Expected idempotent response %+v, got %+v", response, retrieved)
+			}
+
+			// Responses made at or after the timestamp are kept
+			err = store.RemoveIdempotentResponsesBefore(response.CreatedAt)
+			if err != nil {
+				t.Fatalf("Failed to remove idempotent responses: %v", err)
+			}
+			retrieved, err = store.GetIdempotentResponse(response.Key)
+			if err != nil {
+				t.Fatalf("Failed to get idempotent response: %v", err)
+			}
+			if retrieved == nil {
+				t.Errorf("Expected idempotent response to be kept")
+			}
+
+			err = store.RemoveIdempotentResponsesBefore(response.CreatedAt + 1)
+			if err != nil {
+				t.Fatalf("Failed to remove idempotent responses: %v", err)
+			}
+			retrieved, err = store.GetIdempotentResponse(response.Key)
+			if err != nil {
+				t.Fatalf("Failed to get idempotent response: %v", err)
+			}
+			if retrieved != nil {
+				t.Errorf("Expected idempotent response to be removed, got %+v", retrieved)
+			}
+		})
+	}
+}
+
+func TestAuditEntryOps(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			address := generateEthAddress()
+			entries := []data.AuditEntry{
+				{ID: "a", Address: address, Method: "POST", Route: "/api/v1/job_offers", StatusCode: 200, CreatedAt: 100},
+				{ID: "b", Address: address, Method: "DELETE", Route: "/api/v1/webhooks/{id}", StatusCode: 200, CreatedAt: 200},
+				{ID: "c", Address: generateEthAddress(), Method: "POST", Route: "/api/v1/job_offers", StatusCode: 403, CreatedAt: 300},
+			}
+			for _, entry := range entries {
+				_, err := store.AddAuditEntry(entry)
+				if err != nil {
+					t.Fatalf("Failed to add audit entry: %v", err)
+				}
+			}
+
+			// A second entry with the same id is rejected
+			_, err := store.AddAuditEntry(entries[0])
+			if err == nil {
+				t.Errorf("Expected an error adding an entry with the same id")
+			}
+
+			tests := []struct {
+				name     string
+				query    solverstore.GetAuditEntriesQuery
+				expected []string
+			}{
+				{name: "all", query: solverstore.GetAuditEntriesQuery{}, expected: []string{"a", "b", "c"}},
+				{name: "address", query: solverstore.GetAuditEntriesQuery{Address: address}, expected: []string{"a", "b"}},
+				{name: "method", query: solverstore.GetAuditEntriesQuery{Method: "DELETE"}, expected: []string{"b"}},
+				{name: "route", query: solverstore.GetAuditEntriesQuery{Route: "/api/v1/job_offers"}, expected: []string{"a", "c"}},
+				{name: "window", query: solverstore.GetAuditEntriesQuery{Since: 200, Until: 300}, expected: []string{"b"}},
+				{name: "newest first", query: solverstore.GetAuditEntriesQuery{Page: solverstore.PageQuery{Limit: 2, Order: solverstore.OrderDescending}}, expected: []string{"c", "b"}},
+			}
+			for _, tc := range tests {
+				got, err := store.GetAuditEntries(tc.query)
+				if err != nil {
+					t.Fatalf("Failed to get audit entries: %v", err)
+				}
+				ids := []string{}
+				for _, entry := range got {
+					ids = append(ids, entry.ID)
+				}
+				if !slices.Equal(ids, tc.expected) {
+					t.Errorf("%s: expected entries %v, got %v", tc.name, tc.expected, ids)
+				}
+			}
+
+			count, err := store.CountAuditEntries(solverstore.GetAuditEntriesQuery{Address: address})
+			if err != nil {
+				t.Fatalf("Failed to count audit entries: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Expected 2 audit entries, got %d", count)
+			}
+
+			// Entries made at or after the timestamp are kept
+			err = store.RemoveAuditEntriesBefore(200)
+			if err != nil {
+				t.Fatalf("Failed to remove audit entries: %v", err)
+			}
+			count, err = store.CountAuditEntries(solverstore.GetAuditEntriesQuery{})
+			if err != nil {
+				t.Fatalf("Failed to count audit entries: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Expected 2 audit entries to be kept, got %d", count)
+			}
+		})
+	}
+}
+
+func TestChainEventOps(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			address := generateEthAddress()
+			events := []data.ChainEvent{
+				{ID: "000000000010-000000", Contract: "storage", Event: "DealStateChange", Block: 10, DealID: "deal-1", Addresses: []string{}},
+				{ID: "000000000010-000001", Contract: "payments", Event: "Payment", Block: 10, DealID: "deal-1", Addresses: []string{address}},
+				{ID: "000000000020-000000", Contract: "token", Event: "Transfer", Block: 20, Addresses: []string{address, generateEthAddress()}},
+			}
+			err := store.AddChainEvents(events)
+			if err != nil {
+				t.Fatalf("Failed to add chain events: %v", err)
+			}
+
+			// Adding the same events again leaves the stored ones alone
+			err = store.AddChainEvents(events[:1])
+			if err != nil {
+				t.Fatalf("Failed to add chain events a second time: %v", err)
+			}
+
+			tests := []struct {
+				name     string
+				query    solverstore.GetChainEventsQuery
+				expected []string
+			}{
+				{name: "all", query: solverstore.GetChainEventsQuery{}, expected: []string{events[0].ID, events[1].ID, events[2].ID}},
+				{name: "deal", query: solverstore.GetChainEventsQuery{DealID: "deal-1"}, expected: []string{events[0].ID, events[1].ID}},
+				{name: "address", query: solverstore.GetChainEventsQuery{Address: address}, expected: []string{events[1].ID, events[2].ID}},
+				{name: "event", query: solverstore.GetChainEventsQuery{Contract: "token", Event: "Transfer"}, expected: []string{events[2].ID}},
+				{name: "blocks", query: solverstore.GetChainEventsQuery{FromBlock: 11, ToBlock: 20}, expected: []string{events[2].ID}},
+				{name: "newest first", query: solverstore.GetChainEventsQuery{Page: solverstore.PageQuery{Limit: 1, Order: solverstore.OrderDescending}}, expected: []string{events[2].ID}},
+			}
+			for _, tc := range tests {
+				got, err := store.GetChainEvents(tc.query)
+				if err != nil {
+					t.Fatalf("Failed to get chain events: %v", err)
+				}
+				ids := []string{}
+				for _, event := range got {
+					ids = append(ids, event.ID)
+				}
+				if !slices.Equal(ids, tc.expected) {
+					t.Errorf("%s: expected events %v, got %v", tc.name, tc.expected, ids)
+				}
+			}
+
+			count, err := store.CountChainEvents(solverstore.GetChainEventsQuery{})
+			if err != nil {
+				t.Fatalf("Failed to count chain events: %v", err)
+			}
+			if count != 3 {
+				t.Errorf("Expected 3 chain events, got %d", count)
+			}
+		})
+	}
+}
+
 func TestMatchDecisionOps(t *testing.T) {
 	storeConfigs := setupStores(t)
 	for _, config := range storeConfigs {
@@ -992,6 +1508,7 @@ func TestMatchDecisionOps(t *testing.T) {
 					decision.JobOffer,
 					decision.Deal,
 					decision.Result,
+					"",
 				)
 				if err != nil {
 					t.Fatalf("Failed to add match decision: %v", err)
@@ -1050,6 +1567,28 @@ func TestMatchDecisionOps(t *testing.T) {
 				}
 			}
 
+			// Get match decisions for each job offer
+			for _, decision := range decisions {
+				jobOfferDecisions, err := store.GetJobOfferMatchDecisions(decision.JobOffer)
+				if err != nil {
+					t.Fatalf("Failed to get job offer match decisions: %v", err)
+				}
+				found := false
+				for _, jobOfferDecision := range jobOfferDecisions {
+					if jobOfferDecision.JobOffer != decision.JobOffer {
+						t.Errorf("Expected JobOffer %s, got %s",
+							decision.JobOffer, jobOfferDecision.JobOffer)
+					}
+					if jobOfferDecision.ResourceOffer == decision.ResourceOffer {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Expected match decision for resource offer %s in job offer %s decisions",
+						decision.ResourceOffer, decision.JobOffer)
+				}
+			}
+
 			// Test individual match decision operations
 			for _, decision := range decisions {
 				// Get match decision
@@ -1155,6 +1694,61 @@ func TestMatchDecisionRemove(t *testing.T) {
 
 // Concurrency for all
 
+func TestScheduledMatchOps(t *testing.T) {
+	storeConfigs := setupStores(t)
+	for _, config := range storeConfigs {
+		t.Run(config.name, func(t *testing.T) {
+			getStore, clearStore := config.init()
+			store := getStore()
+			defer clearStore()
+
+			// Added out of order, they come back soonest first
+			startsAt := []int64{3000, 1000, 4000, 2000}
+			for _, starts := range startsAt {
+				_, err := store.AddScheduledMatch(data.ScheduledMatch{
+					JobOffer:      generateCID(),
+					ResourceOffer: generateCID(),
+					StartsAt:      starts,
+				})
+				if err != nil {
+					t.Fatalf("Failed to add scheduled match: %v", err)
+				}
+			}
+
+			matches, err := store.GetScheduledMatches()
+			if err != nil {
+				t.Fatalf("Failed to get scheduled matches: %v", err)
+			}
+			retrieved := make([]int64, len(matches))
+			for i, match := range matches {
+				retrieved[i] = match.StartsAt
+			}
+			expected := []int64{1000, 2000, 3000, 4000}
+			if !slices.Equal(retrieved, expected) {
+				t.Errorf("Expected scheduled matches starting at %v, got %v", expected, retrieved)
+			}
+
+			// A job offer can only be scheduled once
+			_, err = store.AddScheduledMatch(matches[0])
+			if err == nil {
+				t.Errorf("Expected an error scheduling job offer %s twice", matches[0].JobOffer)
+			}
+
+			err = store.RemoveScheduledMatch(matches[0].JobOffer)
+			if err != nil {
+				t.Fatalf("Failed to remove scheduled match: %v", err)
+			}
+			matches, err = store.GetScheduledMatches()
+			if err != nil {
+				t.Fatalf("Failed to get scheduled matches: %v", err)
+			}
+			if len(matches) != 3 || matches[0].StartsAt != 2000 {
+				t.Errorf("Expected the 3 later scheduled matches, got %+v", matches)
+			}
+		})
+	}
+}
+
 func TestConcurrentOps(t *testing.T) {
 	jobOffers := generateJobOffers(4, 10)
 	resourceOffers := generateResourceOffers(4, 10)
@@ -1227,7 +1821,7 @@ func TestConcurrentOps(t *testing.T) {
 				wg.Add(1)
 				go func(d data.MatchDecision) {
 					defer wg.Done()
-					_, err := store.AddMatchDecision(d.ResourceOffer, d.JobOffer, d.Deal, d.Result)
+					_, err := store.AddMatchDecision(d.ResourceOffer, d.JobOffer, d.Deal, d.Result, "")
 					if err != nil {
 						errCh <- fmt.Errorf("match decision error: %v", err)
 					}
@@ -1456,6 +2050,57 @@ func clearStoreDatabase(t *testing.T, s store.SolverStore) {
 			t.Fatalf("Failed to remove existing match decision: %v", err)
 		}
 	}
+
+	// Delete api keys
+	keys, err := s.GetAPIKeys()
+	if err != nil {
+		t.Fatalf("Failed to get existing api keys: %v", err)
+	}
+
+	for _, key := range keys {
+		err := s.RemoveAPIKey(key.ID)
+		if err != nil {
+			t.Fatalf("Failed to remove existing api key: %v", err)
+		}
+	}
+
+	// Delete webhooks and their deliveries
+	webhooks, err := s.GetWebhooks("")
+	if err != nil {
+		t.Fatalf("Failed to get existing webhooks: %v", err)
+	}
+
+	for _, webhook := range webhooks {
+		err := s.RemoveWebhook(webhook.ID)
+		if err != nil {
+			t.Fatalf("Failed to remove existing webhook: %v", err)
+		}
+	}
+
+	// Delete scheduled matches
+	matches, err := s.GetScheduledMatches()
+	if err != nil {
+		t.Fatalf("Failed to get existing scheduled matches: %v", err)
+	}
+
+	for _, match := range matches {
+		err := s.RemoveScheduledMatch(match.JobOffer)
+		if err != nil {
+			t.Fatalf("Failed to remove existing scheduled match: %v", err)
+		}
+	}
+
+	// Delete idempotent responses
+	err = s.RemoveIdempotentResponsesBefore(math.MaxInt64)
+	if err != nil {
+		t.Fatalf("Failed to remove existing idempotent responses: %v", err)
+	}
+
+	// Delete audit entries
+	err = s.RemoveAuditEntriesBefore(math.MaxInt64)
+	if err != nil {
+		t.Fatalf("Failed to remove existing audit entries: %v", err)
+	}
 }
 
 // Generators
@@ -45,6 +45,10 @@ func LogSolverEvent(badge string, ev SolverEvent) {
 		log.Debug().
 			Str(fmt.Sprintf("%s -> JobCreatorTransactionsUpdated", badge), fmt.Sprintf("%+v", ev)).
 			Msgf("")
+	case ResultAdded:
+		log.Debug().
+			Str(fmt.Sprintf("%s -> ResultAdded", badge), fmt.Sprintf("%+v", ev)).
+			Msgf("")
 	}
 }
 
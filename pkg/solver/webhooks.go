@@ -0,0 +1,397 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	corehttp "net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/http"
+	"github.com/lilypad-tech/lilypad/pkg/solver/store"
+	"github.com/rs/zerolog/log"
+)
+
+type SolverWebhookOptions struct {
+	// how many times we try to send each event, the wait
+	// between attempts doubles from a second
+	MaxAttempts int
+	// how many seconds each attempt has to get a response
+	Timeout int
+	// how many webhooks each address can register, 0 means no limit
+	MaxPerOwner int
+}
+
+// the events a webhook can ask for
+const (
+	WebhookDealCreated      = "deal.created"
+	WebhookDealStateUpdated = "deal.state_updated"
+	WebhookResultsPosted    = "results.posted"
+	WebhookResultsAccepted  = "results.accepted"
+)
+
+var webhookEvents = []string{
+	WebhookDealCreated,
+	WebhookDealStateUpdated,
+	WebhookResultsPosted,
+	WebhookResultsAccepted,
+}
+
+// the longest we wait between attempts
+const maxWebhookBackoff = 5 * time.Minute
+
+type webhookPayload struct {
+	// the same for every attempt so receivers can drop repeats
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt int64       `json:"created_at"`
+	Data      SolverEvent `json:"data"`
+}
+
+// the webhook events a solver event turns into, a deal moving to
+// results accepted is both a state update and results accepted
+func webhookEventsFor(ev SolverEvent) []string {
+	switch ev.EventType {
+	case DealAdded:
+		return []string{WebhookDealCreated}
+	case ResultAdded:
+		return []string{WebhookResultsPosted}
+	case DealStateUpdated:
+		if ev.Deal != nil && ev.Deal.State == data.GetAgreementStateIndex("ResultsAccepted") {
+			return []string{WebhookDealStateUpdated, WebhookResultsAccepted}
+		}
+		return []string{WebhookDealStateUpdated}
+	}
+	return nil
+}
+
+// webhooks are sent from inside the solver's network so they cannot point
+// at anything that is only meant to be reachable from there
+func isPublicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsUnspecified()
+}
+
+var errWebhookAddress = errors.New("webhooks cannot be sent to loopback, private, link-local or unspecified addresses")
+
+// every address the host resolves to has to be public
+func checkWebhookHost(ctx context.Context, host string) error {
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("error resolving %s: %w", host, err)
+	}
+	for _, address := range addresses {
+		if !isPublicAddress(address.IP) {
+			return fmt.Errorf("%s resolves to %s: %w", host, address.IP, errWebhookAddress)
+		}
+	}
+	return nil
+}
+
+// the host was checked when the webhook was added but it can resolve to
+// something else since, so the address we actually connect to is checked too
+func checkWebhookDial(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicAddress(ip) {
+		return fmt.Errorf("dialing %s: %w", address, errWebhookAddress)
+	}
+	return nil
+}
+
+func newWebhookClient(timeout time.Duration) *corehttp.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: checkWebhookDial,
+	}
+	return &corehttp.Client{
+		Timeout: timeout,
+		Transport: &corehttp.Transport{
+			// no proxy, it would be the one dialing and skip the check
+			Proxy:       nil,
+			DialContext: dialer.DialContext,
+		},
+		// a redirect could send the event somewhere it was never checked
+		// to go, the receiver gets a 3xx logged as a failed delivery
+		CheckRedirect: func(*corehttp.Request, []*corehttp.Request) error {
+			return corehttp.ErrUseLastResponse
+		},
+	}
+}
+
+// sends the solver events to the webhooks of the parties to each deal
+type webhookDispatcher struct {
+	ctx     context.Context
+	store   store.SolverStore
+	options SolverWebhookOptions
+	client  *corehttp.Client
+	// the wait before the second attempt
+	backoff time.Duration
+}
+
+func newWebhookDispatcher(ctx context.Context, store store.SolverStore, options SolverWebhookOptions) *webhookDispatcher {
+	return &webhookDispatcher{
+		ctx:     ctx,
+		store:   store,
+		options: options,
+		client:  newWebhookClient(time.Duration(options.Timeout) * time.Second),
+		backoff: time.Second,
+	}
+}
+
+// events are written from the solver loop so nothing here can block it
+func (dispatcher *webhookDispatcher) dispatch(ev SolverEvent) {
+	events := webhookEventsFor(ev)
+	if len(events) == 0 {
+		return
+	}
+	go func() {
+		webhooks, err := dispatcher.store.GetWebhooks("")
+		if err != nil {
+			log.Error().Err(err).Msgf("error loading webhooks")
+			return
+		}
+		parties := ev.addresses()
+		for _, webhook := range webhooks {
+			if !slices.ContainsFunc(parties, func(party string) bool { return strings.EqualFold(party, webhook.Owner) }) {
+				continue
+			}
+			for _, event := range events {
+				if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event) {
+					continue
+				}
+				go dispatcher.deliver(webhook, webhookPayload{
+					ID:        uuid.New().String(),
+					Event:     event,
+					CreatedAt: time.Now().UnixMilli(),
+					Data:      ev,
+				})
+			}
+		}
+	}()
+}
+
+// every attempt goes in the delivery log so the owner can see what happened
+func (dispatcher *webhookDispatcher) deliver(webhook data.Webhook, payload webhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Error().Err(err).Msgf("error encoding webhook payload")
+		return
+	}
+	backoff := dispatcher.backoff
+	for attempt := 1; attempt <= dispatcher.options.MaxAttempts; attempt++ {
+		statusCode, err := dispatcher.send(webhook, payload, body)
+		delivery := data.WebhookDelivery{
+			ID:         uuid.New().String(),
+			WebhookID:  webhook.ID,
+			EventID:    payload.ID,
+			Event:      payload.Event,
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Delivered:  err == nil,
+			CreatedAt:  time.Now().UnixMilli(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if _, storeErr := dispatcher.store.AddWebhookDelivery(delivery); storeErr != nil {
+			log.Error().Err(storeErr).Msgf("error saving webhook delivery")
+		}
+		if err == nil {
+			return
+		}
+		log.Warn().Err(err).
+			Str("webhook", webhook.ID).
+			Str("event", payload.Event).
+			Int("attempt", attempt).
+			Msgf("error delivering webhook")
+		if attempt == dispatcher.options.MaxAttempts {
+			return
+		}
+
+		select {
+		case <-dispatcher.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = nextWebhookBackoff(backoff)
+
+		// the owner may have removed the webhook while we waited
+		current, err := dispatcher.store.GetWebhook(webhook.ID)
+		if err != nil || current == nil {
+			return
+		}
+	}
+}
+
+func nextWebhookBackoff(backoff time.Duration) time.Duration {
+	return min(backoff*2, maxWebhookBackoff)
+}
+
+func (dispatcher *webhookDispatcher) send(webhook data.Webhook, payload webhookPayload, body []byte) (int, error) {
+	req, err := corehttp.NewRequestWithContext(dispatcher.ctx, "POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	http.AddWebhookHeaders(req, webhook.Secret, payload.Event, payload.ID, body)
+	res, err := dispatcher.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook responded with %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+/*
+ *
+ *
+ *
+
+ Handlers
+
+ *
+ *
+ *
+*/
+
+type webhookRequest struct {
+	URL string `json:"url"`
+	// every event when empty
+	Events []string `json:"events"`
+}
+
+// the secret is only ever in this response
+func (solverServer *solverServer) addWebhook(payload webhookRequest, res corehttp.ResponseWriter, req *corehttp.Request) (*data.CreatedWebhook, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	target, err := url.Parse(payload.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, http.HTTPError{
+			Message:    "a webhook needs an http or https url",
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	if err := checkWebhookHost(req.Context(), target.Hostname()); err != nil {
+		return nil, http.HTTPError{
+			Message:    err.Error(),
+			StatusCode: corehttp.StatusBadRequest,
+		}
+	}
+	for _, event := range payload.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, http.HTTPError{
+				Message:    fmt.Sprintf("unknown webhook event %s, the events are %s", event, strings.Join(webhookEvents, ", ")),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+	}
+	options := solverServer.controller.options.Webhooks
+	if options.MaxPerOwner > 0 {
+		existing, err := solverServer.store.GetWebhooks(signerAddress)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) >= options.MaxPerOwner {
+			return nil, http.HTTPError{
+				Message:    fmt.Sprintf("%s already has %d webhooks", signerAddress, len(existing)),
+				StatusCode: corehttp.StatusBadRequest,
+			}
+		}
+	}
+	secret, err := http.GenerateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	events := payload.Events
+	if events == nil {
+		events = []string{}
+	}
+	webhook, err := solverServer.store.AddWebhook(data.Webhook{
+		ID:        uuid.New().String(),
+		Owner:     signerAddress,
+		URL:       payload.URL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("id", webhook.ID).Str("owner", signerAddress).Msgf("added webhook")
+	return &data.CreatedWebhook{
+		Webhook: *webhook,
+		Secret:  secret,
+	}, nil
+}
+
+func (solverServer *solverServer) getWebhooks(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.Webhook, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	return solverServer.store.GetWebhooks(signerAddress)
+}
+
+// someone else's webhook is not found rather than forbidden so ids cannot be probed
+func (solverServer *solverServer) getOwnWebhook(req *corehttp.Request) (*data.Webhook, error) {
+	signerAddress, err := http.CheckSignature(req)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error checking signature")
+		return nil, err
+	}
+	webhook, err := solverServer.store.GetWebhook(mux.Vars(req)["id"])
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil || !strings.EqualFold(webhook.Owner, signerAddress) {
+		return nil, http.HTTPError{
+			Message:    "webhook not found",
+			StatusCode: corehttp.StatusNotFound,
+		}
+	}
+	return webhook, nil
+}
+
+func (solverServer *solverServer) removeWebhook(res corehttp.ResponseWriter, req *corehttp.Request) (*data.Webhook, error) {
+	webhook, err := solverServer.getOwnWebhook(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := solverServer.store.RemoveWebhook(webhook.ID); err != nil {
+		return nil, err
+	}
+	log.Info().Str("id", webhook.ID).Str("owner", webhook.Owner).Msgf("removed webhook")
+	return webhook, nil
+}
+
+func (solverServer *solverServer) getWebhookDeliveries(res corehttp.ResponseWriter, req *corehttp.Request) ([]data.WebhookDelivery, error) {
+	webhook, err := solverServer.getOwnWebhook(req)
+	if err != nil {
+		return nil, err
+	}
+	return solverServer.store.GetWebhookDeliveries(webhook.ID)
+}
@@ -0,0 +1,151 @@
+//go:build unit
+
+package solver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	memorystore "github.com/lilypad-tech/lilypad/pkg/solver/store/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookEventsFor(t *testing.T) {
+	accepted := data.DealContainer{State: data.GetAgreementStateIndex("ResultsAccepted")}
+	submitted := data.DealContainer{State: data.GetAgreementStateIndex("ResultsSubmitted")}
+
+	tests := []struct {
+		name     string
+		event    SolverEvent
+		expected []string
+	}{
+		{"deal added", SolverEvent{EventType: DealAdded}, []string{WebhookDealCreated}},
+		{"result added", SolverEvent{EventType: ResultAdded}, []string{WebhookResultsPosted}},
+		{"state updated", SolverEvent{EventType: DealStateUpdated, Deal: &submitted}, []string{WebhookDealStateUpdated}},
+		{"results accepted", SolverEvent{EventType: DealStateUpdated, Deal: &accepted}, []string{WebhookDealStateUpdated, WebhookResultsAccepted}},
+		{"offer added", SolverEvent{EventType: JobOfferAdded}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, webhookEventsFor(test.event))
+		})
+	}
+}
+
+func TestWebhookDeliveryRetries(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if calls.Add(1) < 3 {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	webhook, err := memoryStore.AddWebhook(data.Webhook{ID: "webhook", URL: receiver.URL, Secret: "secret"})
+	require.NoError(t, err)
+
+	dispatcher := &webhookDispatcher{
+		ctx:     context.Background(),
+		store:   memoryStore,
+		options: SolverWebhookOptions{MaxAttempts: 5},
+		// the receiver is on loopback which the real client refuses
+		client:  receiver.Client(),
+		backoff: time.Millisecond,
+	}
+	dispatcher.deliver(*webhook, webhookPayload{ID: "event", Event: WebhookDealCreated})
+
+	require.Equal(t, int32(3), calls.Load())
+	deliveries, err := memoryStore.GetWebhookDeliveries("webhook")
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	delivered := 0
+	for _, delivery := range deliveries {
+		require.Equal(t, "event", delivery.EventID)
+		if delivery.Delivered {
+			delivered++
+			require.Equal(t, http.StatusNoContent, delivery.StatusCode)
+		} else {
+			require.Equal(t, http.StatusInternalServerError, delivery.StatusCode)
+		}
+	}
+	require.Equal(t, 1, delivered)
+}
+
+func TestWebhookDeliveryGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		res.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	memoryStore, err := memorystore.NewSolverStoreMemory()
+	require.NoError(t, err)
+	webhook, err := memoryStore.AddWebhook(data.Webhook{ID: "webhook", URL: receiver.URL, Secret: "secret"})
+	require.NoError(t, err)
+
+	dispatcher := &webhookDispatcher{
+		ctx:     context.Background(),
+		store:   memoryStore,
+		options: SolverWebhookOptions{MaxAttempts: 3},
+		client:  receiver.Client(),
+		backoff: time.Millisecond,
+	}
+	dispatcher.deliver(*webhook, webhookPayload{ID: "event", Event: WebhookDealCreated})
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestNextWebhookBackoff(t *testing.T) {
+	require.Equal(t, 2*time.Second, nextWebhookBackoff(time.Second))
+	require.Equal(t, maxWebhookBackoff, nextWebhookBackoff(4*time.Minute))
+	require.Equal(t, maxWebhookBackoff, nextWebhookBackoff(maxWebhookBackoff))
+}
+
+func TestWebhookAddresses(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "::1", "10.0.0.1", "192.168.1.1", "172.16.0.1", "169.254.169.254", "fe80::1", "0.0.0.0", "::"} {
+		t.Run(host, func(t *testing.T) {
+			require.ErrorIs(t, checkWebhookHost(context.Background(), host), errWebhookAddress)
+			require.ErrorIs(t, checkWebhookDial("tcp", net.JoinHostPort(host, "443"), nil), errWebhookAddress)
+		})
+	}
+	require.NoError(t, checkWebhookHost(context.Background(), "1.1.1.1"))
+	require.NoError(t, checkWebhookDial("tcp", "1.1.1.1:443", nil))
+}
+
+func TestWebhookClient(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	_, err := newWebhookClient(time.Second).Post(receiver.URL, "application/json", nil)
+	require.True(t, errors.Is(err, errWebhookAddress), err)
+	require.Zero(t, calls.Load())
+}
+
+func TestWebhookClientRedirects(t *testing.T) {
+	client := newWebhookClient(time.Second)
+	// only the redirect policy, the receiver is on loopback
+	client.Transport = nil
+	receiver := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer receiver.Close()
+
+	res, err := client.Post(receiver.URL, "application/json", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+}
@@ -15,6 +15,7 @@ type ControlLoop struct {
 	handler      func() error
 	running      bool
 	counter      int
+	debounce     *time.Timer
 }
 
 func NewControlLoop(
@@ -57,6 +58,22 @@ func (loop *ControlLoop) Trigger() {
 	}
 }
 
+// run the handler once the window has passed
+// any other triggers that arrive in the window are folded into the same run
+func (loop *ControlLoop) TriggerDebounced(window time.Duration) {
+	loop.triggerMutex.Lock()
+	defer loop.triggerMutex.Unlock()
+	if loop.debounce != nil {
+		return
+	}
+	loop.debounce = time.AfterFunc(window, func() {
+		loop.triggerMutex.Lock()
+		loop.debounce = nil
+		loop.triggerMutex.Unlock()
+		loop.Trigger()
+	})
+}
+
 func (loop *ControlLoop) run() {
 	// this means that only 1 version this of function can be running at a time
 	loop.runMutex.Lock()
@@ -0,0 +1,275 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/payments"
+	"github.com/rs/zerolog/log"
+)
+
+// a contract the controller now links at another address
+type ContractChange struct {
+	// the field of ContractAddresses that changed e.g. Storage
+	Contract string
+	Old      common.Address
+	New      common.Address
+}
+
+type namedAddress struct {
+	name    string
+	address common.Address
+}
+
+func (addresses ContractAddresses) named() []namedAddress {
+	return []namedAddress{
+		{"Token", addresses.Token},
+		{"Payments", addresses.Payments},
+		{"Storage", addresses.Storage},
+		{"Users", addresses.Users},
+		{"JobCreator", addresses.JobCreator},
+		{"Mediation", addresses.Mediation},
+		{"Controller", addresses.Controller},
+		{"Pow", addresses.Pow},
+	}
+}
+
+func diffContractAddresses(current ContractAddresses, latest ContractAddresses) []ContractChange {
+	changes := []ContractChange{}
+	latestNamed := latest.named()
+	for i, before := range current.named() {
+		if after := latestNamed[i].address; after != before.address {
+			changes = append(changes, ContractChange{Contract: before.name, Old: before.address, New: after})
+		}
+	}
+	return changes
+}
+
+// an address set in the options is used over the one the controller links
+func withPinnedAddresses(options Web3Options, addresses ContractAddresses) ContractAddresses {
+	for _, pin := range []struct {
+		option  string
+		address *common.Address
+	}{
+		{options.TokenAddress, &addresses.Token},
+		{options.PaymentsAddress, &addresses.Payments},
+		{options.StorageAddress, &addresses.Storage},
+		{options.UsersAddress, &addresses.Users},
+		{options.JobCreatorAddress, &addresses.JobCreator},
+		{options.MediationAddress, &addresses.Mediation},
+		{options.PowAddress, &addresses.Pow},
+	} {
+		if pin.option != "" {
+			*pin.address = common.HexToAddress(pin.option)
+		}
+	}
+	addresses.Controller = common.HexToAddress(options.ControllerAddress)
+	return addresses
+}
+
+// the addresses last loaded from the controller, kept so a restart does
+// not have to read them all again before it can start
+type addressBook struct {
+	path string
+}
+
+// one file per chain and controller so switching networks does not pick
+// up the addresses of another deployment
+func newAddressBook(options Web3Options) *addressBook {
+	name := fmt.Sprintf("%d-%s.json", options.ChainID, strings.ToLower(common.HexToAddress(options.ControllerAddress).Hex()))
+	return &addressBook{
+		path: filepath.Join(system.GetDataDir("contracts"), name),
+	}
+}
+
+func (book *addressBook) load() (ContractAddresses, bool) {
+	data, err := os.ReadFile(book.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Str("path", book.path).Msgf("error reading contract addresses, loading them from the controller")
+		}
+		return ContractAddresses{}, false
+	}
+	var addresses ContractAddresses
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		log.Warn().Err(err).Str("path", book.path).Msgf("error parsing contract addresses, loading them from the controller")
+		return ContractAddresses{}, false
+	}
+	return addresses, true
+}
+
+func (book *addressBook) save(addresses ContractAddresses) error {
+	data, err := json.MarshalIndent(addresses, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(book.path), 0755); err != nil {
+		return err
+	}
+	tmp := book.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, book.path)
+}
+
+// the bindings for the kept addresses when there are some, the refresh
+// started with the sdk catches up with any the controller changed since,
+// with refreshing turned off they are read from the controller every time
+func loadContracts(options Web3Options, book *addressBook, client bind.ContractBackend, callOpts *bind.CallOpts) (*Contracts, error) {
+	if options.ContractRefreshInterval <= 0 {
+		return NewContracts(options, client, callOpts)
+	}
+	if addresses, ok := book.load(); ok {
+		log.Debug().Str("path", book.path).Msgf("using kept contract addresses")
+		return bindContracts(withPinnedAddresses(options, addresses), client)
+	}
+	contracts, err := NewContracts(options, client, callOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := book.save(contracts.Addresses); err != nil {
+		log.Warn().Err(err).Str("path", book.path).Msgf("error keeping contract addresses")
+	}
+	return contracts, nil
+}
+
+func (sdk *Web3SDK) Contracts() *Contracts {
+	sdk.contractsMutex.RLock()
+	defer sdk.contractsMutex.RUnlock()
+	return sdk.contracts
+}
+
+// closed when the bindings are swapped, the event listeners watch it to
+// subscribe to the new contracts
+func (sdk *Web3SDK) contractsChangedSignal() <-chan struct{} {
+	sdk.contractsMutex.RLock()
+	defer sdk.contractsMutex.RUnlock()
+	return sdk.contractsChanged
+}
+
+// the handler is called with each contract the controller moves
+func (sdk *Web3SDK) SubscribeContractChanges(handler func(ContractChange)) {
+	sdk.contractsMutex.Lock()
+	defer sdk.contractsMutex.Unlock()
+	sdk.contractChangeSubs = append(sdk.contractChangeSubs, handler)
+}
+
+// the linked addresses as the controller has them now, read in one batch
+func (sdk *Web3SDK) readContractAddresses(ctx context.Context) (ContractAddresses, error) {
+	controllerABI, err := controller.ControllerMetaData.GetAbi()
+	if err != nil {
+		return ContractAddresses{}, err
+	}
+	addresses := sdk.Contracts().Addresses
+	reads := []struct {
+		method  string
+		address *common.Address
+	}{
+		{"getPaymentsAddress", &addresses.Payments},
+		{"getStorageAddress", &addresses.Storage},
+		{"getUsersAddress", &addresses.Users},
+		{"getJobCreatorAddress", &addresses.JobCreator},
+		{"getMediationAddress", &addresses.Mediation},
+		{"getPowAddress", &addresses.Pow},
+	}
+	calls := make([]*BatchCall, len(reads))
+	for i, read := range reads {
+		calls[i] = &BatchCall{Contract: addresses.Controller, ABI: controllerABI, Method: read.method}
+	}
+	if err := sdk.BatchRead(ctx, calls); err != nil {
+		return ContractAddresses{}, err
+	}
+	for i, call := range calls {
+		if call.Err != nil {
+			return ContractAddresses{}, fmt.Errorf("error reading %s: %w", call.Method, call.Err)
+		}
+		*reads[i].address = *abi.ConvertType(call.Result[0], new(common.Address)).(*common.Address)
+	}
+
+	addresses = withPinnedAddresses(sdk.Options, addresses)
+
+	// the token is linked from the payments contract, which may have just moved
+	if sdk.Options.TokenAddress == "" {
+		payments, err := payments.NewPayments(addresses.Payments, sdk.Client)
+		if err != nil {
+			return ContractAddresses{}, err
+		}
+		addresses.Token, err = payments.GetTokenAddress(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return ContractAddresses{}, fmt.Errorf("error reading getTokenAddress: %w", err)
+		}
+	}
+	return addresses, nil
+}
+
+// reads the linked addresses from the controller and swaps in new bindings
+// for the contracts that moved, so an upgrade is picked up without a restart
+func (sdk *Web3SDK) RefreshContracts(ctx context.Context) ([]ContractChange, error) {
+	addresses, err := sdk.readContractAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffContractAddresses(sdk.Contracts().Addresses, addresses)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	contracts, err := bindContracts(addresses, sdk.backend)
+	if err != nil {
+		return nil, err
+	}
+
+	sdk.contractsMutex.Lock()
+	sdk.contracts = contracts
+	close(sdk.contractsChanged)
+	sdk.contractsChanged = make(chan struct{})
+	handlers := sdk.contractChangeSubs
+	sdk.contractsMutex.Unlock()
+
+	if err := sdk.addressBook.save(addresses); err != nil {
+		log.Warn().Err(err).Str("path", sdk.addressBook.path).Msgf("error keeping contract addresses")
+	}
+	for _, change := range changes {
+		log.Warn().
+			Str("contract", change.Contract).
+			Str("old", change.Old.Hex()).
+			Str("new", change.New.Hex()).
+			Msgf("controller links a new contract address")
+		for _, handler := range handlers {
+			go handler(change)
+		}
+	}
+	return changes, nil
+}
+
+// checks the controller every WEB3_CONTRACT_REFRESH_INTERVAL seconds, the
+// first check is straight away since the kept addresses may be out of date
+func (sdk *Web3SDK) runContractRefresh(ctx context.Context) {
+	interval := time.Duration(sdk.Options.ContractRefreshInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := sdk.RefreshContracts(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msgf("error refreshing contract addresses")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build unit
+
+package web3
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractAddressChanges(t *testing.T) {
+	current := ContractAddresses{
+		Token:   common.HexToAddress("0x01"),
+		Storage: common.HexToAddress("0x02"),
+		Pow:     common.HexToAddress("0x03"),
+	}
+	latest := current
+	latest.Storage = common.HexToAddress("0x12")
+	assert.Empty(t, diffContractAddresses(current, current))
+	assert.Equal(t, []ContractChange{
+		{Contract: "Storage", Old: current.Storage, New: latest.Storage},
+	}, diffContractAddresses(current, latest))
+
+	// an address from the options is kept whatever the controller says
+	pinned := withPinnedAddresses(Web3Options{StorageAddress: current.Storage.Hex()}, latest)
+	assert.Equal(t, current.Storage, pinned.Storage)
+	assert.Empty(t, diffContractAddresses(current, pinned))
+}
+
+func TestAddressBook(t *testing.T) {
+	book := &addressBook{path: filepath.Join(t.TempDir(), "contracts", "1337.json")}
+	_, ok := book.load()
+	assert.False(t, ok)
+
+	addresses := ContractAddresses{
+		Token:      common.HexToAddress("0x01"),
+		Controller: common.HexToAddress("0x02"),
+	}
+	require.NoError(t, book.save(addresses))
+	loaded, ok := book.load()
+	require.True(t, ok)
+	assert.Equal(t, addresses, loaded)
+}
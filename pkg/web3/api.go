@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/lilypad-tech/lilypad/pkg/data"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/pow"
@@ -18,7 +20,7 @@ func (sdk *Web3SDK) GetServiceAddresses(serviceType string) ([]common.Address, e
 	if err != nil {
 		return nil, err
 	}
-	return sdk.Contracts.Users.ShowUsersInList(
+	return sdk.Contracts().Users.ShowUsersInList(
 		sdk.CallOpts,
 		solverType,
 	)
@@ -31,23 +33,29 @@ func (sdk *Web3SDK) GetSolverAddresses() ([]common.Address, error) {
 func (sdk *Web3SDK) GetUser(
 	address common.Address,
 ) (users.SharedStructsUser, error) {
-	return sdk.Contracts.Users.GetUser(
+	return sdk.Contracts().Users.GetUser(
 		sdk.CallOpts,
 		address,
 	)
 }
 
 func (sdk *Web3SDK) UpdateUser(
+	ctx context.Context,
 	metadataCID string,
 	url string,
 	roles []uint8,
 ) error {
-	tx, err := sdk.Contracts.Users.UpdateUser(
-		sdk.TransactOpts,
-		metadataCID,
-		url,
-		roles,
-	)
+	if err := sdk.GasOracle.WaitForFees(ctx); err != nil {
+		return err
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Users.UpdateUser(
+			opts,
+			metadataCID,
+			url,
+			roles,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting Users.UpdateUser", err)
 		return err
@@ -55,7 +63,7 @@ func (sdk *Web3SDK) UpdateUser(
 		system.Info(sdk.Options.Service, "submitted users.UpdateUser", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	_, err = sdk.WaitTx(ctx, tx)
 	if err != nil {
 		return err
 	}
@@ -63,12 +71,18 @@ func (sdk *Web3SDK) UpdateUser(
 }
 
 func (sdk *Web3SDK) AddUserToList(
+	ctx context.Context,
 	serviceType uint8,
 ) error {
-	tx, err := sdk.Contracts.Users.AddUserToList(
-		sdk.TransactOpts,
-		serviceType,
-	)
+	if err := sdk.GasOracle.WaitForFees(ctx); err != nil {
+		return err
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Users.AddUserToList(
+			opts,
+			serviceType,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting Users.AddUserToList", err)
 		return err
@@ -76,7 +90,7 @@ func (sdk *Web3SDK) AddUserToList(
 		system.Info(sdk.Options.Service, "submitted users.AddUserToList", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	_, err = sdk.WaitTx(ctx, tx)
 	if err != nil {
 		return err
 	}
@@ -85,7 +99,7 @@ func (sdk *Web3SDK) AddUserToList(
 
 func (sdk *Web3SDK) GetSolverUrl(address string) (string, error) {
 	log.Debug().Msgf("begin GetSolverUrl from contract at address: %s", address)
-	solver, err := sdk.Contracts.Users.GetUser(
+	solver, err := sdk.Contracts().Users.GetUser(
 		sdk.CallOpts,
 		common.HexToAddress(address),
 	)
@@ -103,17 +117,39 @@ func (sdk *Web3SDK) GetSolverUrl(address string) (string, error) {
 func (sdk *Web3SDK) Agree(
 	deal data.Deal,
 ) (string, error) {
-	mediators := []common.Address{}
-	for _, mediator := range deal.Members.Mediators {
-		mediators = append(mediators, common.HexToAddress(mediator))
-	}
-	tx, err := sdk.Contracts.Controller.Agree(
-		sdk.TransactOpts,
-		deal.ID,
-		data.ConvertDealMembers(deal.Members),
-		data.ConvertDealTimeouts(deal.Timeouts),
-		data.ConvertDealPricing(deal.Pricing),
-	)
+	paymentToken, err := sdk.GetPaymentToken(context.Background(), deal.PaymentToken)
+	if err != nil {
+		return "", err
+	}
+	members := data.ConvertDealMembers(deal.Members)
+	timeouts := data.ConvertDealTimeouts(deal.Timeouts, paymentToken.Decimals)
+	pricing := data.ConvertDealPricing(deal.Pricing, paymentToken.Decimals)
+	if paymentToken.Address != sdk.Contracts().Addresses.Token {
+		collateral := agreeCollateral(deal, sdk.GetAddress(), paymentToken.Decimals)
+		multicall, bundle := sdk.permitMulticall(context.Background())
+		permit, err := sdk.ensureAllowance(context.Background(), paymentToken.Address, collateral, bundle)
+		if err != nil {
+			return "", err
+		}
+		// agree would revert without the allowance the permit gives so
+		// the two are simulated together
+		if permit != nil {
+			return sdk.sendWithPermit(context.Background(), multicall, *permit, "agree", deal.ID, members, timeouts, pricing)
+		}
+	}
+	if err := sdk.simulate(context.Background(), "controller", "agree", deal.ID, members, timeouts, pricing); err != nil {
+		system.Error(sdk.Options.Service, "error simulating controller.Agree() tx", err)
+		return "", err
+	}
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.Agree(
+			opts,
+			deal.ID,
+			members,
+			timeouts,
+			pricing,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting controller.Agree() tx", err)
 		return "", err
@@ -121,11 +157,11 @@ func (sdk *Web3SDK) Agree(
 		system.Debug(sdk.Options.Service, "submitted controller.Agree() tx", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	receipt, err := sdk.WaitTx(context.Background(), tx)
 	if err != nil {
 		return "", err
 	}
-	return tx.Hash().String(), nil
+	return receipt.TxHash.String(), nil
 }
 
 func (sdk *Web3SDK) AddResult(
@@ -134,13 +170,20 @@ func (sdk *Web3SDK) AddResult(
 	dataId string,
 	instructionCount uint64,
 ) (string, error) {
-	tx, err := sdk.Contracts.Controller.AddResult(
-		sdk.TransactOpts,
-		dealId,
-		resultsId,
-		dataId,
-		big.NewInt(int64(instructionCount)),
-	)
+	instructions := big.NewInt(int64(instructionCount))
+	if err := sdk.simulate(context.Background(), "controller", "addResult", dealId, resultsId, dataId, instructions); err != nil {
+		system.Error(sdk.Options.Service, "error simulating controller.AddResult", err)
+		return "", err
+	}
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.AddResult(
+			opts,
+			dealId,
+			resultsId,
+			dataId,
+			instructions,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting controller.AddResult", err)
 		return "", err
@@ -148,20 +191,26 @@ func (sdk *Web3SDK) AddResult(
 		system.Debug(sdk.Options.Service, "submitted controller.AddResult", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	receipt, err := sdk.WaitTx(context.Background(), tx)
 	if err != nil {
 		return "", err
 	}
-	return tx.Hash().String(), nil
+	return receipt.TxHash.String(), nil
 }
 
 func (sdk *Web3SDK) AcceptResult(
 	dealId string,
 ) (string, error) {
-	tx, err := sdk.Contracts.Controller.AcceptResult(
-		sdk.TransactOpts,
-		dealId,
-	)
+	if err := sdk.simulate(context.Background(), "controller", "acceptResult", dealId); err != nil {
+		system.Error(sdk.Options.Service, "error simulating controller.AcceptResult", err)
+		return "", err
+	}
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.AcceptResult(
+			opts,
+			dealId,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting controller.AcceptResult", err)
 		return "", err
@@ -169,20 +218,22 @@ func (sdk *Web3SDK) AcceptResult(
 		system.Debug(sdk.Options.Service, "submitted controller.AcceptResult", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	receipt, err := sdk.WaitTx(context.Background(), tx)
 	if err != nil {
 		return "", err
 	}
-	return tx.Hash().String(), nil
+	return receipt.TxHash.String(), nil
 }
 
 func (sdk *Web3SDK) CheckResult(
 	dealId string,
 ) (string, error) {
-	tx, err := sdk.Contracts.Controller.CheckResult(
-		sdk.TransactOpts,
-		dealId,
-	)
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.CheckResult(
+			opts,
+			dealId,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting controller.CheckResult", err)
 		return "", err
@@ -190,20 +241,22 @@ func (sdk *Web3SDK) CheckResult(
 		system.Debug(sdk.Options.Service, "submitted controller.CheckResult", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	receipt, err := sdk.WaitTx(context.Background(), tx)
 	if err != nil {
 		return "", err
 	}
-	return tx.Hash().String(), nil
+	return receipt.TxHash.String(), nil
 }
 
 func (sdk *Web3SDK) MediationAcceptResult(
 	dealId string,
 ) (string, error) {
-	tx, err := sdk.Contracts.Controller.MediationAcceptResult(
-		sdk.TransactOpts,
-		dealId,
-	)
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.MediationAcceptResult(
+			opts,
+			dealId,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting controller.MediationAcceptResult", err)
 		return "", err
@@ -211,20 +264,22 @@ func (sdk *Web3SDK) MediationAcceptResult(
 		system.Debug(sdk.Options.Service, "submitted controller.MediationAcceptResult", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	receipt, err := sdk.WaitTx(context.Background(), tx)
 	if err != nil {
 		return "", err
 	}
-	return tx.Hash().String(), nil
+	return receipt.TxHash.String(), nil
 }
 
 func (sdk *Web3SDK) MediationRejectResult(
 	dealId string,
 ) (string, error) {
-	tx, err := sdk.Contracts.Controller.MediationRejectResult(
-		sdk.TransactOpts,
-		dealId,
-	)
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.MediationRejectResult(
+			opts,
+			dealId,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting controller.MediationRejectResult", err)
 		return "", err
@@ -232,21 +287,131 @@ func (sdk *Web3SDK) MediationRejectResult(
 		system.Debug(sdk.Options.Service, "submitted controller.MediationRejectResult", tx.Hash().String())
 		system.DumpObjectDebug(tx)
 	}
-	_, err = sdk.WaitTx(context.Background(), tx)
+	receipt, err := sdk.WaitTx(context.Background(), tx)
+	if err != nil {
+		return "", err
+	}
+	return receipt.TxHash.String(), nil
+}
+
+func (sdk *Web3SDK) TimeoutAgree(
+	ctx context.Context,
+	dealId string,
+) (string, error) {
+	if err := sdk.GasOracle.WaitForFees(ctx); err != nil {
+		return "", err
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.TimeoutAgree(
+			opts,
+			dealId,
+		)
+	})
+	if err != nil {
+		system.Error(sdk.Options.Service, "error submitting controller.TimeoutAgree", err)
+		return "", err
+	} else {
+		system.Debug(sdk.Options.Service, "submitted controller.TimeoutAgree", tx.Hash().String())
+		system.DumpObjectDebug(tx)
+	}
+	receipt, err := sdk.WaitTx(ctx, tx)
 	if err != nil {
 		return "", err
 	}
-	return tx.Hash().String(), nil
+	return receipt.TxHash.String(), nil
+}
+
+func (sdk *Web3SDK) TimeoutSubmitResult(
+	ctx context.Context,
+	dealId string,
+) (string, error) {
+	if err := sdk.GasOracle.WaitForFees(ctx); err != nil {
+		return "", err
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.TimeoutSubmitResult(
+			opts,
+			dealId,
+		)
+	})
+	if err != nil {
+		system.Error(sdk.Options.Service, "error submitting controller.TimeoutSubmitResult", err)
+		return "", err
+	} else {
+		system.Debug(sdk.Options.Service, "submitted controller.TimeoutSubmitResult", tx.Hash().String())
+		system.DumpObjectDebug(tx)
+	}
+	receipt, err := sdk.WaitTx(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	return receipt.TxHash.String(), nil
+}
+
+func (sdk *Web3SDK) TimeoutJudgeResult(
+	ctx context.Context,
+	dealId string,
+) (string, error) {
+	if err := sdk.GasOracle.WaitForFees(ctx); err != nil {
+		return "", err
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.TimeoutJudgeResult(
+			opts,
+			dealId,
+		)
+	})
+	if err != nil {
+		system.Error(sdk.Options.Service, "error submitting controller.TimeoutJudgeResult", err)
+		return "", err
+	} else {
+		system.Debug(sdk.Options.Service, "submitted controller.TimeoutJudgeResult", tx.Hash().String())
+		system.DumpObjectDebug(tx)
+	}
+	receipt, err := sdk.WaitTx(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	return receipt.TxHash.String(), nil
+}
+
+func (sdk *Web3SDK) TimeoutMediateResult(
+	ctx context.Context,
+	dealId string,
+) (string, error) {
+	if err := sdk.GasOracle.WaitForFees(ctx); err != nil {
+		return "", err
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Controller.TimeoutMediateResult(
+			opts,
+			dealId,
+		)
+	})
+	if err != nil {
+		system.Error(sdk.Options.Service, "error submitting controller.TimeoutMediateResult", err)
+		return "", err
+	} else {
+		system.Debug(sdk.Options.Service, "submitted controller.TimeoutMediateResult", tx.Hash().String())
+		system.DumpObjectDebug(tx)
+	}
+	receipt, err := sdk.WaitTx(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	return receipt.TxHash.String(), nil
 }
 
 func (sdk *Web3SDK) GetGenerateChallenge(
 	ctx context.Context,
 	nodeId string,
 ) (string, *pow.PowGenerateChallenge, error) {
-	tx, err := sdk.Contracts.Pow.GenerateChallenge(
-		sdk.TransactOpts,
-		nodeId,
-	)
+	tx, err := sdk.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Pow.GenerateChallenge(
+			opts,
+			nodeId,
+		)
+	})
 	if err != nil {
 		system.Error(sdk.Options.Service, "error submitting pow.GenerateChallenge", err)
 		return "", nil, err
@@ -263,7 +428,7 @@ func (sdk *Web3SDK) GetGenerateChallenge(
 		return tx.Hash().String(), nil, fmt.Errorf("execute challenge fail")
 	}
 
-	challenge, err := sdk.Contracts.Pow.ParseGenerateChallenge(*receipt.Logs[0])
+	challenge, err := sdk.Contracts().Pow.ParseGenerateChallenge(*receipt.Logs[0])
 	if err != nil {
 		return "", nil, err
 	}
@@ -275,7 +440,9 @@ func (sdk *Web3SDK) SubmitWork(
 	nonce *big.Int,
 	nodeId string,
 ) (common.Hash, error) {
-	tx, err := sdk.Contracts.Pow.SubmitWork(sdk.TransactOpts, nonce, nodeId)
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Pow.SubmitWork(opts, nonce, nodeId)
+	})
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -303,7 +470,9 @@ type PowValidPOWSubmission struct {
 }
 
 func (sdk *Web3SDK) SendPowSignal(ctx context.Context) (*pow.PowNewPowRound, error) {
-	tx, err := sdk.Contracts.Pow.TriggerNewPowRound(sdk.TransactOpts)
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return sdk.Contracts().Pow.TriggerNewPowRound(opts)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -317,7 +486,7 @@ func (sdk *Web3SDK) SendPowSignal(ctx context.Context) (*pow.PowNewPowRound, err
 		return nil, fmt.Errorf("send new pow signal successfully but execute fail status(%d) tx(%s)", receipt.Status, tx.Hash())
 	}
 
-	newPowRoundEvent, err := sdk.Contracts.Pow.ParseNewPowRound(*receipt.Logs[0])
+	newPowRoundEvent, err := sdk.Contracts().Pow.ParseNewPowRound(*receipt.Logs[0])
 	if err != nil {
 		return nil, fmt.Errorf("parse new pow round event fail tx(%s) %w", tx.Hash(), err)
 	}
@@ -0,0 +1,201 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// the chain's own coin that pays for gas
+	BalanceAssetNative = "native"
+	// the network's token that collateral and payments are in
+	BalanceAssetToken = "token"
+)
+
+// sent to WEB3_BALANCE_ALERT_WEBHOOK when a balance goes under its
+// threshold and again when it is topped back up
+type BalanceAlert struct {
+	Service   string  `json:"service"`
+	Address   string  `json:"address"`
+	Asset     string  `json:"asset"`
+	Balance   float64 `json:"balance"`
+	Threshold float64 `json:"threshold"`
+	Low       bool    `json:"low"`
+	Time      int64   `json:"time"`
+}
+
+type balanceState struct {
+	balance float64
+	checked bool
+	low     bool
+}
+
+// checks our balances every WEB3_BALANCE_CHECK_INTERVAL seconds and warns
+// once each time one falls under the amount we need for gas or collateral
+type BalanceWatcher struct {
+	sdk    *Web3SDK
+	client *http.Client
+	states map[string]*balanceState
+	mutex  sync.RWMutex
+}
+
+func newBalanceWatcher(sdk *Web3SDK) *BalanceWatcher {
+	return &BalanceWatcher{
+		sdk:    sdk,
+		client: &http.Client{Timeout: 10 * time.Second},
+		states: map[string]*balanceState{
+			BalanceAssetNative: {},
+			BalanceAssetToken:  {},
+		},
+	}
+}
+
+// the last balance read of each asset in whole coins or tokens
+func (watcher *BalanceWatcher) Balances() map[string]float64 {
+	watcher.mutex.RLock()
+	defer watcher.mutex.RUnlock()
+	balances := map[string]float64{}
+	for asset, state := range watcher.states {
+		if state.checked {
+			balances[asset] = state.balance
+		}
+	}
+	return balances
+}
+
+// reports the balances as the web3.balance gauge with an asset attribute
+func (watcher *BalanceWatcher) RegisterMetrics(meter metric.Meter) error {
+	gauge, err := meter.Float64ObservableGauge(
+		"web3.balance",
+		metric.WithDescription("The balance of the service's address in whole coins or tokens."),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		for asset, balance := range watcher.Balances() {
+			observer.ObserveFloat64(gauge, balance, metric.WithAttributes(attribute.String("asset", asset)))
+		}
+		return nil
+	}, gauge)
+	return err
+}
+
+func (watcher *BalanceWatcher) Run(ctx context.Context) {
+	interval := time.Duration(watcher.sdk.Options.BalanceCheckInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := watcher.check(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msgf("error checking balances")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (watcher *BalanceWatcher) check(ctx context.Context) error {
+	address := watcher.sdk.GetAddress()
+	native, err := watcher.sdk.Client.BalanceAt(ctx, address, nil)
+	if err != nil {
+		return fmt.Errorf("error reading balance: %w", err)
+	}
+	watcher.update(ctx, BalanceAssetNative, weiToUnits(native, 18), watcher.sdk.Options.MinNativeBalance)
+
+	paymentToken, err := watcher.sdk.GetPaymentToken(ctx, "")
+	if err != nil {
+		return err
+	}
+	tokens, err := watcher.sdk.GetTokenBalance(paymentToken.Address, address.Hex())
+	if err != nil {
+		return fmt.Errorf("error reading %s balance: %w", paymentToken.Symbol, err)
+	}
+	watcher.update(ctx, BalanceAssetToken, weiToUnits(tokens, paymentToken.Decimals), watcher.sdk.Options.MinTokenBalance)
+	return nil
+}
+
+// a threshold of 0 is never alerted on
+func (watcher *BalanceWatcher) update(ctx context.Context, asset string, balance float64, threshold float64) {
+	watcher.mutex.Lock()
+	state := watcher.states[asset]
+	wasLow := state.low
+	state.balance = balance
+	state.checked = true
+	low := threshold > 0 && balance < threshold
+	state.low = low
+	watcher.mutex.Unlock()
+
+	if low == wasLow {
+		return
+	}
+	logger := log.Info()
+	message := "balance is back above its threshold"
+	if low {
+		logger = log.Warn()
+		message = "balance is below its threshold, top it up to keep paying for gas and collateral"
+	}
+	logger.
+		Str("asset", asset).
+		Float64("balance", balance).
+		Float64("threshold", threshold).
+		Msg(message)
+	watcher.notify(ctx, BalanceAlert{
+		Service:   string(watcher.sdk.Options.Service),
+		Address:   watcher.sdk.GetAddress().Hex(),
+		Asset:     asset,
+		Balance:   balance,
+		Threshold: threshold,
+		Low:       low,
+		Time:      time.Now().UnixMilli(),
+	})
+}
+
+func (watcher *BalanceWatcher) notify(ctx context.Context, alert BalanceAlert) {
+	url := watcher.sdk.Options.BalanceAlertWebhook
+	if url == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		log.Warn().Err(err).Msgf("error making balance alert request")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := watcher.client.Do(req)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error sending balance alert")
+		return
+	}
+	res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		log.Warn().Int("status", res.StatusCode).Msgf("balance alert webhook turned the alert away")
+	}
+}
+
+func weiToUnits(amount *big.Int, decimals uint8) float64 {
+	units, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(amount),
+		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)),
+	).Float64()
+	return units
+}
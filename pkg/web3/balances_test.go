@@ -0,0 +1,52 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceAlerts(t *testing.T) {
+	alerts := []BalanceAlert{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert BalanceAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts = append(alerts, alert)
+	}))
+	defer server.Close()
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sdk := &Web3SDK{
+		Options: Web3Options{Service: "resource-provider", BalanceAlertWebhook: server.URL},
+		Signer:  NewKeySigner(privateKey),
+	}
+	watcher := newBalanceWatcher(sdk)
+	ctx := context.Background()
+
+	// only going under the threshold and back over it is sent
+	watcher.update(ctx, BalanceAssetNative, 0.5, 0.1)
+	watcher.update(ctx, BalanceAssetNative, 0.05, 0.1)
+	watcher.update(ctx, BalanceAssetNative, 0.01, 0.1)
+	watcher.update(ctx, BalanceAssetNative, 1, 0.1)
+	// a threshold of 0 turns the alert off
+	watcher.update(ctx, BalanceAssetToken, 0, 0)
+
+	require.Len(t, alerts, 2)
+	assert.True(t, alerts[0].Low)
+	assert.Equal(t, 0.05, alerts[0].Balance)
+	assert.Equal(t, sdk.GetAddress().Hex(), alerts[0].Address)
+	assert.False(t, alerts[1].Low)
+	assert.Equal(t, map[string]float64{BalanceAssetNative: 1, BalanceAssetToken: 0}, watcher.Balances())
+
+	assert.Equal(t, 1.5, weiToUnits(big.NewInt(1500000), 6))
+}
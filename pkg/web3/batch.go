@@ -0,0 +1,261 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/rs/zerolog/log"
+)
+
+// how one deal did in a batch
+type DealTx struct {
+	DealID string
+	// every deal sent in the same batch has the same hash
+	TxHash string
+	// the deal was left out of the batch or the batch failed
+	Err error
+}
+
+// one controller call in a batch, index is the deal's place in the results
+// and token the payment token it needs an allowance for, if any
+type dealCall struct {
+	index  int
+	method string
+	args   []interface{}
+	token  common.Address
+}
+
+// a signed permit for the deals in the token
+type tokenPermit struct {
+	token common.Address
+	call  multicallCall
+}
+
+// agrees to the deals WEB3_TX_BATCH_SIZE at a time through the multicall
+// contract, the controller and payments contracts only look at tx.origin
+// so the calls count as ours even though the multicall contract makes them
+//
+// without a multicall contract or a batch size over 1 each deal is agreed
+// to on its own
+func (sdk *Web3SDK) BatchAgree(deals []data.Deal) []DealTx {
+	ctx := context.Background()
+	results := make([]DealTx, len(deals))
+	for i, deal := range deals {
+		results[i].DealID = deal.ID
+	}
+	multicall, ok := sdk.batchMulticall(ctx, len(deals))
+	if !ok {
+		for i, deal := range deals {
+			results[i].TxHash, results[i].Err = sdk.Agree(deal)
+		}
+		return results
+	}
+
+	// the allowance has to cover every deal in the token at once
+	allowances := map[common.Address]*big.Int{}
+	calls := []dealCall{}
+	for i, deal := range deals {
+		paymentToken, err := sdk.GetPaymentToken(ctx, deal.PaymentToken)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		call := dealCall{
+			index:  i,
+			method: "agree",
+			args: []interface{}{
+				deal.ID,
+				data.ConvertDealMembers(deal.Members),
+				data.ConvertDealTimeouts(deal.Timeouts, paymentToken.Decimals),
+				data.ConvertDealPricing(deal.Pricing, paymentToken.Decimals),
+			},
+		}
+		if paymentToken.Address != sdk.Contracts().Addresses.Token {
+			call.token = paymentToken.Address
+			if allowances[paymentToken.Address] == nil {
+				allowances[paymentToken.Address] = big.NewInt(0)
+			}
+			allowances[paymentToken.Address].Add(allowances[paymentToken.Address], agreeCollateral(deal, sdk.GetAddress(), paymentToken.Decimals))
+		}
+		calls = append(calls, call)
+	}
+	_, bundle := sdk.permitMulticall(ctx)
+	permits := []tokenPermit{}
+	for tokenAddress, amount := range allowances {
+		permit, err := sdk.ensureAllowance(ctx, tokenAddress, amount, bundle)
+		if err == nil {
+			if permit != nil {
+				permits = append(permits, tokenPermit{token: tokenAddress, call: *permit})
+			}
+			continue
+		}
+		allowed := []dealCall{}
+		for _, call := range calls {
+			if call.token == tokenAddress {
+				results[call.index].Err = err
+			} else {
+				allowed = append(allowed, call)
+			}
+		}
+		calls = allowed
+	}
+	sdk.sendBatch(ctx, multicall, permits, calls, results)
+	return results
+}
+
+// the multicall contract when the deals are worth batching
+func (sdk *Web3SDK) batchMulticall(ctx context.Context, deals int) (common.Address, bool) {
+	if sdk.Options.TxBatchSize <= 1 || deals <= 1 {
+		return common.Address{}, false
+	}
+	multicall, ok, err := sdk.multicall(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error looking up multicall contract, sending deals one at a time")
+		return common.Address{}, false
+	}
+	return multicall, ok
+}
+
+// sends the calls WEB3_TX_BATCH_SIZE at a time, each batch is run as an
+// eth_call first and the calls that would revert are left out so one bad
+// deal does not take the rest of the batch down with it
+//
+// the permits go ahead of the first batch that sends anything, the
+// allowance they give covers the deals in the later batches too
+func (sdk *Web3SDK) sendBatch(ctx context.Context, multicall common.Address, permits []tokenPermit, calls []dealCall, results []DealTx) {
+	controllerABI, err := controller.ControllerMetaData.GetAbi()
+	if err != nil {
+		for _, call := range calls {
+			results[call.index].Err = err
+		}
+		return
+	}
+	controllerAddress := sdk.Contracts().Addresses.Controller
+	for start := 0; start < len(calls); start += sdk.Options.TxBatchSize {
+		batch := calls[start:min(start+sdk.Options.TxBatchSize, len(calls))]
+		packed := make([]multicallCall, 0, len(permits)+len(batch))
+		for _, permit := range permits {
+			packed = append(packed, permit.call)
+		}
+		included := make([]dealCall, 0, len(batch))
+		for _, call := range batch {
+			input, err := controllerABI.Pack(call.method, call.args...)
+			if err != nil {
+				results[call.index].Err = fmt.Errorf("error packing controller.%s: %w", call.method, err)
+				continue
+			}
+			packed = append(packed, multicallCall{Target: controllerAddress, AllowFailure: true, CallData: input})
+			included = append(included, call)
+		}
+
+		simulated, err := simulateBatch(ctx, sdk.Client, sdk.GetAddress(), multicall, packed)
+		if err != nil {
+			for _, call := range included {
+				results[call.index].Err = err
+			}
+			continue
+		}
+		// a permit that would revert is dropped along with the deals that
+		// needed it, the later batches are simulated without the allowance
+		// so their deals come back with the revert from agree
+		failedPermits := map[common.Address]error{}
+		validPermits := []tokenPermit{}
+		sending := []multicallCall{}
+		for i, permit := range permits {
+			if !simulated[i].Success {
+				failedPermits[permit.token] = decodeRevert("token.permit", simulated[i].ReturnData)
+				continue
+			}
+			validPermits = append(validPermits, permit)
+			packed[i].AllowFailure = false
+			sending = append(sending, packed[i])
+		}
+		permits = validPermits
+		sent := []dealCall{}
+		for i, result := range simulated[len(simulated)-len(included):] {
+			call := included[i]
+			if err, ok := failedPermits[call.token]; ok {
+				results[call.index].Err = err
+				continue
+			}
+			if !result.Success {
+				results[call.index].Err = decodeRevert("controller."+call.method, result.ReturnData)
+				continue
+			}
+			// everything left should go through so a failure reverts the lot
+			// and none of the deals look done when they are not
+			callData := packed[len(packed)-len(included)+i]
+			callData.AllowFailure = false
+			sending = append(sending, callData)
+			sent = append(sent, call)
+		}
+		if len(sent) == 0 {
+			continue
+		}
+
+		txHash, err := sdk.sendMulticall(ctx, multicall, sending)
+		for _, call := range sent {
+			results[call.index].TxHash = txHash
+			results[call.index].Err = err
+		}
+		// the permits were not used up when the batch failed
+		if err == nil {
+			permits = nil
+		}
+	}
+}
+
+func (sdk *Web3SDK) sendMulticall(ctx context.Context, multicall common.Address, calls []multicallCall) (string, error) {
+	multicallABI, err := multicallABI()
+	if err != nil {
+		return "", err
+	}
+	contract := bind.NewBoundContract(multicall, multicallABI, sdk.backend, sdk.backend, sdk.backend)
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return contract.Transact(opts, "aggregate3", calls)
+	})
+	if err != nil {
+		system.Error(sdk.Options.Service, "error submitting multicall.aggregate3 tx", err)
+		return "", err
+	}
+	system.Debug(sdk.Options.Service, fmt.Sprintf("submitted multicall.aggregate3 tx with %d calls", len(calls)), tx.Hash().String())
+	receipt, err := sdk.WaitTx(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	return receipt.TxHash.String(), nil
+}
+
+// what each call would do if the batch was sent from our address now
+func simulateBatch(ctx context.Context, backend callBackend, from common.Address, multicall common.Address, calls []multicallCall) ([]multicallResult, error) {
+	multicallABI, err := multicallABI()
+	if err != nil {
+		return nil, err
+	}
+	input, err := multicallABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, err
+	}
+	output, err := backend.CallContract(ctx, ethereum.CallMsg{From: from, To: &multicall, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error simulating multicall: %w", err)
+	}
+	unpacked, err := multicallABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, fmt.Errorf("error reading multicall simulation: %w", err)
+	}
+	results := *abi.ConvertType(unpacked[0], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("multicall simulation gave %d results for %d calls", len(results), len(calls))
+	}
+	return results, nil
+}
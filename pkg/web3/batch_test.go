@@ -0,0 +1,70 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runs aggregate3 as the controller would, acceptResult only goes through
+// for the "ok" deal and reverts with a reason for any other
+type fakeBatchBackend struct {
+	t    *testing.T
+	from common.Address
+}
+
+func (backend *fakeBatchBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	require.Equal(backend.t, backend.from, call.From)
+	multicallABI, err := multicallABI()
+	require.NoError(backend.t, err)
+	controllerABI, err := controller.ControllerMetaData.GetAbi()
+	require.NoError(backend.t, err)
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(backend.t, err)
+
+	method := multicallABI.Methods["aggregate3"]
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	require.NoError(backend.t, err)
+	calls := *abi.ConvertType(args[0], new([]multicallCall)).(*[]multicallCall)
+	results := make([]multicallResult, len(calls))
+	for i, inner := range calls {
+		dealArgs, err := controllerABI.Methods["acceptResult"].Inputs.Unpack(inner.CallData[4:])
+		require.NoError(backend.t, err)
+		if dealArgs[0].(string) == "ok" {
+			results[i] = multicallResult{Success: true}
+			continue
+		}
+		reason, err := abi.Arguments{{Type: stringType}}.Pack("ResultsSubmitted")
+		require.NoError(backend.t, err)
+		results[i] = multicallResult{ReturnData: append([]byte{0x08, 0xc3, 0x79, 0xa0}, reason...)}
+	}
+	return method.Outputs.Pack(results)
+}
+
+func TestSimulateBatch(t *testing.T) {
+	controllerABI, err := controller.ControllerMetaData.GetAbi()
+	require.NoError(t, err)
+	from := common.HexToAddress("0x01")
+	calls := []multicallCall{}
+	for _, dealID := range []string{"ok", "settled"} {
+		input, err := controllerABI.Pack("acceptResult", dealID)
+		require.NoError(t, err)
+		calls = append(calls, multicallCall{Target: common.HexToAddress("0x02"), AllowFailure: true, CallData: input})
+	}
+
+	results, err := simulateBatch(context.Background(), &fakeBatchBackend{t: t, from: from}, from, testMulticall, calls)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "ResultsSubmitted", decodeRevert("controller.acceptResult", results[1].ReturnData).Reason)
+}
@@ -0,0 +1,157 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/signatures"
+)
+
+const (
+	typedDataName    = "Lilypad"
+	typedDataVersion = "1"
+)
+
+var typedDataDomainType = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+// the id is the cid of the whole offer so signing it covers the fields that
+// are not spelled out here, the rest are what a wallet shows the signer
+var typedDataTypes = apitypes.Types{
+	"EIP712Domain": typedDataDomainType,
+	"JobOffer": {
+		{Name: "id", Type: "string"},
+		{Name: "jobCreator", Type: "address"},
+		{Name: "module", Type: "string"},
+		{Name: "mode", Type: "string"},
+		{Name: "instructionPrice", Type: "uint256"},
+		{Name: "paymentToken", Type: "address"},
+		{Name: "createdAt", Type: "uint256"},
+	},
+	"ResourceOffer": {
+		{Name: "id", Type: "string"},
+		{Name: "resourceProvider", Type: "address"},
+		{Name: "mode", Type: "string"},
+		{Name: "instructionPrice", Type: "uint256"},
+		{Name: "createdAt", Type: "uint256"},
+	},
+	"Result": {
+		{Name: "dealId", Type: "string"},
+		{Name: "dataId", Type: "string"},
+		{Name: "error", Type: "string"},
+		{Name: "instructionCount", Type: "uint256"},
+	},
+}
+
+// offers and results are signed for one network, the chain id and the
+// controller contract keep a signature from being replayed on another
+// network or by another protocol using the same key
+func NewTypedDataDomain(chainID int, controllerAddress string) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              typedDataName,
+		Version:           typedDataVersion,
+		ChainId:           math.NewHexOrDecimal256(int64(chainID)),
+		VerifyingContract: common.HexToAddress(controllerAddress).Hex(),
+	}
+}
+
+func (sdk *Web3SDK) TypedDataDomain() apitypes.TypedDataDomain {
+	return NewTypedDataDomain(sdk.Options.ChainID, sdk.Contracts().Addresses.Controller.Hex())
+}
+
+func newTypedData(domain apitypes.TypedDataDomain, primaryType string, message apitypes.TypedDataMessage) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types:       typedDataTypes,
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     message,
+	}
+}
+
+func uint256String(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+// no payment token is the network's token, that is signed as the zero address
+func paymentTokenAddress(token string) string {
+	if token == "" {
+		return common.Address{}.Hex()
+	}
+	return common.HexToAddress(token).Hex()
+}
+
+func JobOfferTypedData(domain apitypes.TypedDataDomain, offer data.JobOffer) (apitypes.TypedData, error) {
+	id, err := data.GetJobOfferID(offer)
+	if err != nil {
+		return apitypes.TypedData{}, err
+	}
+	return newTypedData(domain, "JobOffer", apitypes.TypedDataMessage{
+		"id":               id,
+		"jobCreator":       common.HexToAddress(offer.JobCreator).Hex(),
+		"module":           fmt.Sprintf("%s@%s", offer.Module.Repo, offer.Module.Hash),
+		"mode":             string(offer.Mode),
+		"instructionPrice": uint256String(offer.Pricing.InstructionPrice),
+		"paymentToken":     paymentTokenAddress(offer.PaymentToken),
+		"createdAt":        strconv.Itoa(offer.CreatedAt),
+	}), nil
+}
+
+func ResourceOfferTypedData(domain apitypes.TypedDataDomain, offer data.ResourceOffer) (apitypes.TypedData, error) {
+	id, err := data.GetResourceOfferID(offer)
+	if err != nil {
+		return apitypes.TypedData{}, err
+	}
+	return newTypedData(domain, "ResourceOffer", apitypes.TypedDataMessage{
+		"id":               id,
+		"resourceProvider": common.HexToAddress(offer.ResourceProvider).Hex(),
+		"mode":             string(offer.Mode),
+		"instructionPrice": uint256String(offer.DefaultPricing.InstructionPrice),
+		"createdAt":        strconv.Itoa(offer.CreatedAt),
+	}), nil
+}
+
+func ResultTypedData(domain apitypes.TypedDataDomain, result data.Result) apitypes.TypedData {
+	return newTypedData(domain, "Result", apitypes.TypedDataMessage{
+		"dealId":           result.DealID,
+		"dataId":           result.DataID,
+		"error":            result.Error,
+		"instructionCount": uint256String(result.InstructionCount),
+	})
+}
+
+// a wallet signs the typed data itself, any other signer is handed the
+// preimage of its hash
+func SignTypedData(ctx context.Context, signer Signer, typedData apitypes.TypedData) ([]byte, error) {
+	if wallet, ok := signer.(Wallet); ok {
+		return wallet.SignTypedData(ctx, typedData)
+	}
+	return signTypedDataPayload(ctx, signer, typedData)
+}
+
+// the signer hashes what it is given with keccak256, so handing it the
+// 0x1901 preimage gives the same signature eth_signTypedData_v4 would
+func signTypedDataPayload(ctx context.Context, signer Signer, typedData apitypes.TypedData) ([]byte, error) {
+	_, rawData, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding typed data: %w", err)
+	}
+	return signer.Sign(ctx, []byte(rawData))
+}
+
+// the address that signed the typed data, see signatures.Recover
+func RecoverTypedData(typedData apitypes.TypedData, signature []byte) (common.Address, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error encoding typed data: %w", err)
+	}
+	return signatures.Recover(hash, signature)
+}
@@ -0,0 +1,74 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testController = "0x0000000000000000000000000000000000000099"
+
+func TestTypedDataSignature(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewKeySigner(privateKey)
+
+	offer := data.JobOffer{
+		CreatedAt:  1700000000,
+		JobCreator: signer.Address().Hex(),
+		Module:     data.ModuleConfig{Repo: "https://github.com/lilypad-tech/lilypad-module-cowsay", Hash: "v0.0.4"},
+		Mode:       data.MarketPrice,
+		Pricing:    data.DealPricing{InstructionPrice: 10},
+	}
+	domain := NewTypedDataDomain(1337, testController)
+	typedData, err := JobOfferTypedData(domain, offer)
+	require.NoError(t, err)
+	signature, err := SignTypedData(context.Background(), signer, typedData)
+	require.NoError(t, err)
+
+	address, err := RecoverTypedData(typedData, signature)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), address)
+
+	// wallets give V as 27 or 28
+	walletSignature := append([]byte{}, signature...)
+	walletSignature[crypto.RecoveryIDOffset] += 27
+	address, err = RecoverTypedData(typedData, walletSignature)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), address)
+
+	for name, other := range map[string]func() data.JobOffer{
+		"another price": func() data.JobOffer {
+			changed := offer
+			changed.Pricing.InstructionPrice = 1
+			return changed
+		},
+		"another input": func() data.JobOffer {
+			changed := offer
+			changed.Inputs = map[string]string{"Message": "moo"}
+			return changed
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			otherTypedData, err := JobOfferTypedData(domain, other())
+			require.NoError(t, err)
+			address, err := RecoverTypedData(otherTypedData, signature)
+			require.NoError(t, err)
+			assert.NotEqual(t, signer.Address(), address)
+		})
+	}
+
+	t.Run("another network", func(t *testing.T) {
+		otherTypedData, err := JobOfferTypedData(NewTypedDataDomain(412346, testController), offer)
+		require.NoError(t, err)
+		address, err := RecoverTypedData(otherTypedData, signature)
+		require.NoError(t, err)
+		assert.NotEqual(t, signer.Address(), address)
+	})
+}
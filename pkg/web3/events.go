@@ -2,8 +2,16 @@ package web3
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/rs/zerolog/log"
 )
@@ -16,6 +24,10 @@ type EventChannels struct {
 	Mediation   *MediationEventChannels
 	Pow         *PowEventChannels
 	collections []EventChannelCollection
+	// the last error from each collection, nil while it is subscribed
+	errors  []error
+	started bool
+	mutex   sync.RWMutex
 }
 
 func NewEventChannels() *EventChannels {
@@ -41,6 +53,7 @@ func NewEventChannels() *EventChannels {
 		Mediation:   mediationChannels,
 		Pow:         powChannels,
 		collections: collections,
+		errors:      make([]error, len(collections)),
 	}
 }
 
@@ -49,14 +62,23 @@ func (eventChannels *EventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	for _, collection := range eventChannels.collections {
+	eventChannels.mutex.Lock()
+	eventChannels.started = true
+	eventChannels.mutex.Unlock()
+	for i, collection := range eventChannels.collections {
 		c := collection
+		index := i
 		go func() {
 			for {
+				eventChannels.setError(index, nil)
 				err := c.Start(ctx, cm, sdk)
 				if err != nil {
+					eventChannels.setError(index, err)
 					log.Error().Msgf("error starting listeners: %s reconnect in 2 seconds", err.Error())
 				}
+				if ctx.Err() != nil {
+					return
+				}
 
 				time.Sleep(time.Second * 2)
 			}
@@ -64,3 +86,330 @@ func (eventChannels *EventChannels) Start(
 	}
 	return nil
 }
+
+func (eventChannels *EventChannels) setError(index int, err error) {
+	eventChannels.mutex.Lock()
+	defer eventChannels.mutex.Unlock()
+	eventChannels.errors[index] = err
+}
+
+// errors when the listeners have not started or one of them
+// lost its subscription and is waiting to reconnect
+func (eventChannels *EventChannels) Healthy() error {
+	eventChannels.mutex.RLock()
+	defer eventChannels.mutex.RUnlock()
+	if !eventChannels.started {
+		return errors.New("the event listeners have not started")
+	}
+	for _, err := range eventChannels.errors {
+		if err != nil {
+			return fmt.Errorf("an event subscription is reconnecting: %w", err)
+		}
+	}
+	return nil
+}
+
+// the most blocks asked for in one go when backfilling, rpc
+// providers turn away log queries over a wide range
+const backfillBlockRange = 2000
+
+// how often the events waiting for WEB3_CONFIRMATIONS are checked
+const confirmationCheckInterval = 2 * time.Second
+
+// one contract event a listener follows, T is the binding's event type
+type eventSource[T any] struct {
+	// the checkpoint name e.g. storage.DealStateChange
+	name string
+	// the bindings watch and filter use, the listener starts over with new
+	// ones when the controller links another contract
+	contracts *Contracts
+	watch     func(opts *bind.WatchOpts, sink chan<- *T) (event.Subscription, error)
+	// nil for events that are stale by the time we could backfill them
+	filter func(opts *bind.FilterOpts) ([]*T, error)
+	raw    func(event *T) types.Log
+	// reads a kept log back into its event so a reorg can undo it
+	parse func(raw types.Log) (*T, error)
+}
+
+type logIterator interface {
+	Next() bool
+	Error() error
+	Close() error
+}
+
+// reads every event out of a binding's filter iterator
+func collectEvents[T any](iter logIterator, event func() *T) ([]*T, error) {
+	defer iter.Close()
+	events := []*T{}
+	for iter.Next() {
+		events = append(events, event())
+	}
+	return events, iter.Error()
+}
+
+type logKey struct {
+	txHash common.Hash
+	index  uint
+}
+
+// subscribes to source and hands each event to handle until the
+// subscription drops, the events emitted since the checkpoint are read
+// with FilterLogs and replayed first so a reconnect or restart does not
+// lose any, the checkpoint has the log index so the ones handled before
+// a restart are not handled again
+//
+// when a reorg drops a block with events that were handled, those events
+// are handed to handle again with Raw.Removed set so they can be undone
+// and the listener starts over to replay the new chain
+func followEvents[T any](
+	ctx context.Context,
+	cm *system.CleanupManager,
+	sdk *Web3SDK,
+	source eventSource[T],
+	sink chan *T,
+	handle func(*T),
+) error {
+	contractsChanged := sdk.contractsChangedSignal()
+	if source.contracts != nil && source.contracts != sdk.Contracts() {
+		return nil
+	}
+	subscribedFrom, err := sdk.getBlockNumber()
+	if err != nil {
+		return err
+	}
+	log.Debug().
+		Str("connect", source.name).
+		Msgf("")
+	sub, err := source.watch(&bind.WatchOpts{Start: &subscribedFrom, Context: ctx}, sink)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	cm.RegisterCallback(unsubscribeSub(sub))
+
+	// read after subscribing so no block falls between the two
+	head, err := sdk.getBlockNumber()
+	if err != nil {
+		return err
+	}
+	// the subscription can send the blocks backfill already covered
+	backfilled := map[logKey]bool{}
+	reorgDepth := uint64(sdk.Options.ReorgDepth)
+	dispatch := func(event *T) {
+		handle(event)
+		if source.filter == nil {
+			return
+		}
+		if err := sdk.checkpoints.Handled(source.name, source.raw(event), reorgDepth); err != nil {
+			log.Error().Err(err).Str("event", source.name).Msgf("error saving event checkpoint")
+		}
+	}
+	// events that are not WEB3_CONFIRMATIONS deep yet, oldest first
+	confirmations := uint64(sdk.Options.Confirmations)
+	pending := []*T{}
+	deliver := func(event *T) {
+		if confirmations == 0 {
+			dispatch(event)
+			return
+		}
+		pending = append(pending, event)
+	}
+	// hands over the pending events that are deep enough now, the ones
+	// whose block has gone from the chain are dropped without being handled
+	flushPending := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		head, err := sdk.getBlockNumber()
+		if err != nil {
+			return err
+		}
+		canonical := map[uint64]common.Hash{}
+		for len(pending) > 0 {
+			raw := source.raw(pending[0])
+			if raw.BlockNumber+confirmations > head {
+				return nil
+			}
+			hash, ok := canonical[raw.BlockNumber]
+			if !ok {
+				header, err := sdk.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(raw.BlockNumber))
+				if err != nil {
+					return err
+				}
+				hash = header.Hash()
+				canonical[raw.BlockNumber] = hash
+			}
+			if hash == raw.BlockHash {
+				dispatch(pending[0])
+			} else {
+				log.Warn().Str("event", source.name).Str("tx", raw.TxHash.Hex()).Msgf("dropping an event from block %d that was reorged out before it was confirmed", raw.BlockNumber)
+			}
+			pending = pending[1:]
+		}
+		return nil
+	}
+	// true when the removed log had not been handed over yet
+	dropPending := func(removed types.Log) bool {
+		for i, event := range pending {
+			raw := source.raw(event)
+			if raw.TxHash == removed.TxHash && raw.Index == removed.Index {
+				pending = append(pending[:i], pending[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+
+	// rewinds the checkpoint past a reorg and undoes the events it dropped
+	rewindReorg := func() (uint64, bool, error) {
+		if source.filter == nil || reorgDepth == 0 {
+			return 0, false, nil
+		}
+		checkpoint, _, err := sdk.checkpoints.Get(source.name)
+		if err != nil {
+			return 0, false, err
+		}
+		block, found, err := findReorg(ctx, sdk.Client, checkpoint.Logs)
+		if err != nil || !found {
+			return 0, false, err
+		}
+		dropped, err := sdk.checkpoints.Rewind(source.name, block)
+		if err != nil {
+			return 0, false, err
+		}
+		log.Warn().Str("event", source.name).Msgf("reorg dropped blocks from %d, undoing %d events and replaying the chain from there", block, len(dropped))
+		for _, raw := range dropped {
+			raw.Removed = true
+			event, err := source.parse(raw)
+			if err != nil {
+				log.Error().Err(err).Str("event", source.name).Str("tx", raw.TxHash.Hex()).Msgf("error reading dropped event")
+				continue
+			}
+			handle(event)
+		}
+		return block, true, nil
+	}
+
+	// a reorg while we were down is undone before replaying
+	if _, _, err := rewindReorg(); err != nil {
+		return fmt.Errorf("error checking %s events for a reorg: %w", source.name, err)
+	}
+
+	// nothing before this is handled again, from the replay or the subscription
+	var replayed eventCheckpoint
+	if source.filter != nil {
+		checkpoint, ok, err := sdk.checkpoints.Get(source.name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			checkpoint = eventCheckpoint{Block: head + 1}
+		}
+		if limit := uint64(sdk.Options.EventBackfillLimit); limit > 0 && head > limit && checkpoint.Block < head-limit {
+			log.Warn().Str("event", source.name).Msgf("only backfilling the last %d blocks, events from block %d to %d are skipped", limit, checkpoint.Block, head-limit-1)
+			checkpoint = eventCheckpoint{Block: head - limit}
+		}
+		// with no checkpoint yet the subscription has everything from its start
+		if ok {
+			replayed = checkpoint
+		}
+		for start := checkpoint.Block; start <= head; start += backfillBlockRange {
+			end := min(start+backfillBlockRange-1, head)
+			events, err := source.filter(&bind.FilterOpts{Start: start, End: &end, Context: ctx})
+			if err != nil {
+				return fmt.Errorf("error backfilling %s events from block %d: %w", source.name, start, err)
+			}
+			if len(events) > 0 {
+				log.Info().Str("event", source.name).Msgf("backfilled %d events from blocks %d to %d", len(events), start, end)
+			}
+			for _, event := range events {
+				raw := source.raw(event)
+				if checkpoint.covers(raw) {
+					continue
+				}
+				if raw.BlockNumber >= subscribedFrom {
+					backfilled[logKey{raw.TxHash, raw.Index}] = true
+				}
+				deliver(event)
+			}
+		}
+		if err := flushPending(); err != nil {
+			return err
+		}
+		// the pending events are read again if we stop before they are handled
+		next := eventCheckpoint{Block: head + 1}
+		if len(pending) > 0 {
+			raw := source.raw(pending[0])
+			next = eventCheckpoint{Block: raw.BlockNumber, Index: raw.Index}
+		}
+		if err := sdk.checkpoints.Set(source.name, next); err != nil {
+			log.Error().Err(err).Str("event", source.name).Msgf("error saving event checkpoint")
+		}
+	}
+
+	var reorgCheck <-chan time.Time
+	if source.filter != nil && reorgDepth > 0 {
+		ticker := time.NewTicker(time.Duration(sdk.Options.ReorgCheckInterval) * time.Second)
+		defer ticker.Stop()
+		reorgCheck = ticker.C
+	}
+	var confirmationCheck <-chan time.Time
+	if confirmations > 0 {
+		ticker := time.NewTicker(confirmationCheckInterval)
+		defer ticker.Stop()
+		confirmationCheck = ticker.C
+	}
+	restartOnReorg := func() error {
+		block, found, err := rewindReorg()
+		if err != nil {
+			log.Error().Err(err).Str("event", source.name).Msgf("error checking for a reorg")
+			return nil
+		}
+		if found {
+			return &ReorgError{Event: source.name, Block: block}
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-confirmationCheck:
+			if err := flushPending(); err != nil {
+				return fmt.Errorf("error confirming %s events: %w", source.name, err)
+			}
+		case <-reorgCheck:
+			if err := restartOnReorg(); err != nil {
+				return err
+			}
+		case <-contractsChanged:
+			log.Info().Str("event", source.name).Msgf("contract addresses changed, subscribing again")
+			return nil
+		case event := <-sink:
+			raw := source.raw(event)
+			// the node saw the reorg first, the kept logs say what to undo
+			if raw.Removed {
+				if dropPending(raw) {
+					continue
+				}
+				if err := restartOnReorg(); err != nil {
+					return err
+				}
+				continue
+			}
+			if replayed.covers(raw) || (raw.BlockNumber <= head && backfilled[logKey{raw.TxHash, raw.Index}]) {
+				continue
+			}
+			log.Debug().
+				Str("event", source.name).
+				Msgf("%+v", event)
+			deliver(event)
+		case err := <-sub.Err():
+			if err != nil {
+				return fmt.Errorf("cancel by %s event subscribe error %w", source.name, err)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package web3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+)
+
+// where a listener is up to, every log before Index in Block and every
+// log in the blocks before it has been handled
+type eventCheckpoint struct {
+	Block uint64 `json:"block"`
+	Index uint   `json:"index"`
+	// the logs handled in the last WEB3_REORG_DEPTH blocks, a reorg is
+	// spotted by their block hashes no longer being on the chain
+	Logs []types.Log `json:"logs,omitempty"`
+}
+
+// the checkpoint for having handled the log
+func checkpointAfter(raw types.Log) eventCheckpoint {
+	return eventCheckpoint{Block: raw.BlockNumber, Index: raw.Index + 1}
+}
+
+// true when the log was handled before the checkpoint was saved
+func (checkpoint eventCheckpoint) covers(raw types.Log) bool {
+	return raw.BlockNumber < checkpoint.Block ||
+		(raw.BlockNumber == checkpoint.Block && raw.Index < checkpoint.Index)
+}
+
+func (checkpoint eventCheckpoint) before(other eventCheckpoint) bool {
+	return checkpoint.Block < other.Block ||
+		(checkpoint.Block == other.Block && checkpoint.Index < other.Index)
+}
+
+// the files used to only have the block to read from next, which is the
+// same as a checkpoint with none of that block's logs handled
+func (checkpoint *eventCheckpoint) UnmarshalJSON(data []byte) error {
+	var block uint64
+	if err := json.Unmarshal(data, &block); err == nil {
+		*checkpoint = eventCheckpoint{Block: block}
+		return nil
+	}
+	type plain eventCheckpoint
+	return json.Unmarshal(data, (*plain)(checkpoint))
+}
+
+// the checkpoint of each event listener, kept on disk so a restart replays
+// the events that were emitted while we were down
+type eventCheckpoints struct {
+	path        string
+	checkpoints map[string]eventCheckpoint
+	loaded      bool
+	mutex       sync.Mutex
+}
+
+// one file per service, chain and address so nodes sharing a data dir
+// do not move each other's checkpoints
+func newEventCheckpoints(options Web3Options, address string) *eventCheckpoints {
+	name := fmt.Sprintf("%d-%s.json", options.ChainID, strings.ToLower(address))
+	return &eventCheckpoints{
+		path:        filepath.Join(system.GetDataDir(filepath.Join("events", string(options.Service))), name),
+		checkpoints: map[string]eventCheckpoint{},
+	}
+}
+
+// called with the lock held
+func (checkpoints *eventCheckpoints) load() error {
+	if checkpoints.loaded {
+		return nil
+	}
+	data, err := os.ReadFile(checkpoints.path)
+	if errors.Is(err, os.ErrNotExist) {
+		checkpoints.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &checkpoints.checkpoints); err != nil {
+		return fmt.Errorf("error reading event checkpoints %s: %w", checkpoints.path, err)
+	}
+	checkpoints.loaded = true
+	return nil
+}
+
+// a nil checkpoints has nothing saved, the sdks made in tests have none
+func (checkpoints *eventCheckpoints) Get(name string) (eventCheckpoint, bool, error) {
+	if checkpoints == nil {
+		return eventCheckpoint{}, false, nil
+	}
+	checkpoints.mutex.Lock()
+	defer checkpoints.mutex.Unlock()
+	if err := checkpoints.load(); err != nil {
+		return eventCheckpoint{}, false, err
+	}
+	checkpoint, ok := checkpoints.checkpoints[name]
+	checkpoint.Logs = slices.Clone(checkpoint.Logs)
+	return checkpoint, ok, nil
+}
+
+// only ever moves forward so a late backfill cannot undo a live event
+func (checkpoints *eventCheckpoints) Set(name string, checkpoint eventCheckpoint) error {
+	if checkpoints == nil {
+		return nil
+	}
+	checkpoints.mutex.Lock()
+	defer checkpoints.mutex.Unlock()
+	if err := checkpoints.load(); err != nil {
+		return err
+	}
+	current := checkpoints.checkpoints[name]
+	if !current.before(checkpoint) {
+		return nil
+	}
+	current.Block = checkpoint.Block
+	current.Index = checkpoint.Index
+	checkpoints.checkpoints[name] = current
+	return checkpoints.save()
+}
+
+// moves the checkpoint past the log and keeps the log so a reorg that
+// drops its block can be spotted, the ones more than depth blocks older
+// are let go
+func (checkpoints *eventCheckpoints) Handled(name string, raw types.Log, depth uint64) error {
+	if checkpoints == nil {
+		return nil
+	}
+	checkpoints.mutex.Lock()
+	defer checkpoints.mutex.Unlock()
+	if err := checkpoints.load(); err != nil {
+		return err
+	}
+	current := checkpoints.checkpoints[name]
+	after := checkpointAfter(raw)
+	if !current.before(after) {
+		return nil
+	}
+	current.Block = after.Block
+	current.Index = after.Index
+	if depth > 0 {
+		current.Logs = slices.DeleteFunc(append(current.Logs, raw), func(kept types.Log) bool {
+			return kept.BlockNumber+depth < raw.BlockNumber
+		})
+	}
+	checkpoints.checkpoints[name] = current
+	return checkpoints.save()
+}
+
+// moves the checkpoint back to the start of the block so it is read again,
+// the logs kept from that block on are returned so they can be undone
+func (checkpoints *eventCheckpoints) Rewind(name string, block uint64) ([]types.Log, error) {
+	if checkpoints == nil {
+		return nil, nil
+	}
+	checkpoints.mutex.Lock()
+	defer checkpoints.mutex.Unlock()
+	if err := checkpoints.load(); err != nil {
+		return nil, err
+	}
+	current := checkpoints.checkpoints[name]
+	dropped := []types.Log{}
+	kept := []types.Log{}
+	for _, raw := range current.Logs {
+		if raw.BlockNumber >= block {
+			dropped = append(dropped, raw)
+		} else {
+			kept = append(kept, raw)
+		}
+	}
+	current.Logs = kept
+	if block < current.Block {
+		current.Block = block
+		current.Index = 0
+	}
+	checkpoints.checkpoints[name] = current
+	return dropped, checkpoints.save()
+}
+
+// called with the lock held
+func (checkpoints *eventCheckpoints) save() error {
+	data, err := json.Marshal(checkpoints.checkpoints)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(checkpoints.path), 0755); err != nil {
+		return err
+	}
+	// written to the side and renamed so a crash never leaves half a file
+	tmp := checkpoints.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, checkpoints.path)
+}
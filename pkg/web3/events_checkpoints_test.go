@@ -0,0 +1,95 @@
+//go:build unit
+
+package web3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventCheckpoints(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	options := Web3Options{ChainID: 1337, Service: system.SolverService}
+	address := "0xABC"
+
+	checkpoints := newEventCheckpoints(options, address)
+	_, ok, err := checkpoints.Get("storage.DealStateChange")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, checkpoints.Set("storage.DealStateChange", eventCheckpoint{Block: 100, Index: 3}))
+	// a checkpoint never goes back
+	require.NoError(t, checkpoints.Set("storage.DealStateChange", eventCheckpoint{Block: 100, Index: 1}))
+	require.NoError(t, checkpoints.Set("storage.DealStateChange", eventCheckpoint{Block: 90, Index: 7}))
+
+	// read back from disk the way a restarted node would
+	reopened := newEventCheckpoints(options, address)
+	checkpoint, ok, err := reopened.Get("storage.DealStateChange")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, eventCheckpoint{Block: 100, Index: 3}, checkpoint)
+
+	// another node on the same data dir has its own file
+	other := newEventCheckpoints(options, "0xDEF")
+	_, ok, err = other.Get("storage.DealStateChange")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEventCheckpointCovers(t *testing.T) {
+	checkpoint := checkpointAfter(types.Log{BlockNumber: 100, Index: 2})
+	assert.True(t, checkpoint.covers(types.Log{BlockNumber: 99, Index: 10}))
+	assert.True(t, checkpoint.covers(types.Log{BlockNumber: 100, Index: 2}))
+	assert.False(t, checkpoint.covers(types.Log{BlockNumber: 100, Index: 3}))
+	assert.False(t, checkpoint.covers(types.Log{BlockNumber: 101, Index: 0}))
+}
+
+// the files written before the log index was kept only have the block
+func TestEventCheckpointsBlockOnly(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	options := Web3Options{ChainID: 1337, Service: system.ResourceProviderService}
+	checkpoints := newEventCheckpoints(options, "0xABC")
+	require.NoError(t, os.MkdirAll(filepath.Dir(checkpoints.path), 0755))
+	require.NoError(t, os.WriteFile(checkpoints.path, []byte(`{"storage.DealStateChange":100}`), 0644))
+
+	checkpoint, ok, err := checkpoints.Get("storage.DealStateChange")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, eventCheckpoint{Block: 100}, checkpoint)
+}
+
+func TestNilEventCheckpoints(t *testing.T) {
+	var checkpoints *eventCheckpoints
+	require.NoError(t, checkpoints.Set("token.Transfer", eventCheckpoint{Block: 1}))
+	_, ok, err := checkpoints.Get("token.Transfer")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEventCheckpointsKeepLogsForReorgs(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	checkpoints := newEventCheckpoints(Web3Options{ChainID: 1337, Service: system.SolverService}, "0xABC")
+	for _, block := range []uint64{10, 50, 70, 71} {
+		require.NoError(t, checkpoints.Handled("storage.DealStateChange", types.Log{BlockNumber: block, Index: 1}, 25))
+	}
+	checkpoint, _, err := checkpoints.Get("storage.DealStateChange")
+	require.NoError(t, err)
+	assert.Equal(t, eventCheckpoint{Block: 71, Index: 2}, eventCheckpoint{Block: checkpoint.Block, Index: checkpoint.Index})
+	// only the logs within the reorg depth of the newest are kept
+	assert.Len(t, checkpoint.Logs, 3)
+
+	dropped, err := checkpoints.Rewind("storage.DealStateChange", 70)
+	require.NoError(t, err)
+	assert.Len(t, dropped, 2)
+	checkpoint, _, err = checkpoints.Get("storage.DealStateChange")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(70), checkpoint.Block)
+	assert.Equal(t, uint(0), checkpoint.Index)
+	assert.Len(t, checkpoint.Logs, 1)
+}
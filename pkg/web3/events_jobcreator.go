@@ -2,13 +2,12 @@ package web3
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/jobcreator"
-	"github.com/rs/zerolog/log"
 )
 
 type JobCreatorEventChannels struct {
@@ -28,47 +27,28 @@ func (s *JobCreatorEventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	blockNumber, err := sdk.getBlockNumber()
-	if err != nil {
-		return err
+	contracts := sdk.Contracts()
+	source := eventSource[jobcreator.JobcreatorJobAdded]{
+		name:      "jobcreator.JobAdded",
+		contracts: contracts,
+		watch: func(opts *bind.WatchOpts, sink chan<- *jobcreator.JobcreatorJobAdded) (event.Subscription, error) {
+			return contracts.JobCreator.WatchJobAdded(opts, sink)
+		},
+		raw:   func(event *jobcreator.JobcreatorJobAdded) types.Log { return event.Raw },
+		parse: contracts.JobCreator.ParseJobAdded,
 	}
-
-	var jobAddedSub event.Subscription
-
-	connectJobAddedSub := func() (event.Subscription, error) {
-		log.Debug().
-			Str("jobcreator->connect", "JobAdded").
-			Msgf("")
-		return sdk.Contracts.JobCreator.WatchJobAdded(
-			&bind.WatchOpts{Start: &blockNumber, Context: ctx},
-			s.jobAddedChan,
-		)
-	}
-
-	jobAddedSub, err = connectJobAddedSub()
-	if err != nil {
-		return err
-	}
-	cm.RegisterCallback(unsubscribeSub(jobAddedSub))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case event := <-s.jobAddedChan:
-			log.Debug().
-				Str("storage->event", "JobAdded").
-				Msgf("%+v", event)
-			for _, handler := range s.jobAddedSubs {
-				go handler(*event)
-			}
-		case err := <-jobAddedSub.Err():
-			if err != nil {
-				return fmt.Errorf("cancel by job JobAdded event subscribe error %w", err)
-			}
-			return nil
+	source.filter = func(opts *bind.FilterOpts) ([]*jobcreator.JobcreatorJobAdded, error) {
+		iter, err := contracts.JobCreator.FilterJobAdded(opts)
+		if err != nil {
+			return nil, err
 		}
+		return collectEvents(iter, func() *jobcreator.JobcreatorJobAdded { return iter.Event })
 	}
+	return followEvents(ctx, cm, sdk, source, s.jobAddedChan, func(event *jobcreator.JobcreatorJobAdded) {
+		for _, handler := range s.jobAddedSubs {
+			go handler(*event)
+		}
+	})
 }
 
 func (t *JobCreatorEventChannels) SubscribeJobAdded(handler func(jobcreator.JobcreatorJobAdded)) {
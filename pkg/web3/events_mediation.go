@@ -2,13 +2,12 @@ package web3
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/mediation"
-	"github.com/rs/zerolog/log"
 )
 
 type MediationEventChannels struct {
@@ -28,47 +27,28 @@ func (m *MediationEventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	blockNumber, err := sdk.getBlockNumber()
-	if err != nil {
-		return err
+	contracts := sdk.Contracts()
+	source := eventSource[mediation.MediationMediationRequested]{
+		name:      "mediation.MediationRequested",
+		contracts: contracts,
+		watch: func(opts *bind.WatchOpts, sink chan<- *mediation.MediationMediationRequested) (event.Subscription, error) {
+			return contracts.Mediation.WatchMediationRequested(opts, sink)
+		},
+		raw:   func(event *mediation.MediationMediationRequested) types.Log { return event.Raw },
+		parse: contracts.Mediation.ParseMediationRequested,
 	}
-
-	var mediationRequestedSub event.Subscription
-
-	connectMediationRequestedSub := func() (event.Subscription, error) {
-		log.Debug().
-			Str("mediation->connect", "MediationRequested").
-			Msgf("")
-		return sdk.Contracts.Mediation.WatchMediationRequested(
-			&bind.WatchOpts{Start: &blockNumber, Context: ctx},
-			m.mediationRequestedChan,
-		)
-	}
-
-	mediationRequestedSub, err = connectMediationRequestedSub()
-	if err != nil {
-		return err
-	}
-	cm.RegisterCallback(unsubscribeSub(mediationRequestedSub))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case event := <-m.mediationRequestedChan:
-			log.Debug().
-				Str("mediation->event", "MediationRequested").
-				Msgf("%+v", event)
-			for _, handler := range m.mediationRequestedSubs {
-				go handler(*event)
-			}
-		case err := <-mediationRequestedSub.Err():
-			if err != nil {
-				return fmt.Errorf("cancel by mediation MediationRequested event subscribe error %w", err)
-			}
-			return nil
+	source.filter = func(opts *bind.FilterOpts) ([]*mediation.MediationMediationRequested, error) {
+		iter, err := contracts.Mediation.FilterMediationRequested(opts)
+		if err != nil {
+			return nil, err
 		}
+		return collectEvents(iter, func() *mediation.MediationMediationRequested { return iter.Event })
 	}
+	return followEvents(ctx, cm, sdk, source, m.mediationRequestedChan, func(event *mediation.MediationMediationRequested) {
+		for _, handler := range m.mediationRequestedSubs {
+			go handler(*event)
+		}
+	})
 }
 
 func (m *MediationEventChannels) SubscribeMediationRequested(handler func(mediation.MediationMediationRequested)) {
@@ -2,13 +2,12 @@ package web3
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/payments"
-	"github.com/rs/zerolog/log"
 )
 
 type PaymentEventChannels struct {
@@ -28,47 +27,28 @@ func (p *PaymentEventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	blockNumber, err := sdk.getBlockNumber()
-	if err != nil {
-		return err
+	contracts := sdk.Contracts()
+	source := eventSource[payments.PaymentsPayment]{
+		name:      "payments.Payment",
+		contracts: contracts,
+		watch: func(opts *bind.WatchOpts, sink chan<- *payments.PaymentsPayment) (event.Subscription, error) {
+			return contracts.Payments.WatchPayment(opts, sink)
+		},
+		raw:   func(event *payments.PaymentsPayment) types.Log { return event.Raw },
+		parse: contracts.Payments.ParsePayment,
 	}
-
-	var paymentSub event.Subscription
-
-	connectPaymentSub := func() (event.Subscription, error) {
-		log.Debug().
-			Str("payments->connect", "Payment").
-			Msgf("")
-		return sdk.Contracts.Payments.WatchPayment(
-			&bind.WatchOpts{Start: &blockNumber, Context: ctx},
-			p.paymentChan,
-		)
-	}
-
-	paymentSub, err = connectPaymentSub()
-	if err != nil {
-		return err
-	}
-	cm.RegisterCallback(unsubscribeSub(paymentSub))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case event := <-p.paymentChan:
-			log.Debug().
-				Str("payments->event", "Payment").
-				Msgf("%+v", event)
-			for _, handler := range p.paymentSubs {
-				go handler(*event)
-			}
-		case err := <-paymentSub.Err():
-			if err != nil {
-				return fmt.Errorf("cancel by mediation MediationRequested event subscribe error %w", err)
-			}
-			return nil
+	source.filter = func(opts *bind.FilterOpts) ([]*payments.PaymentsPayment, error) {
+		iter, err := contracts.Payments.FilterPayment(opts)
+		if err != nil {
+			return nil, err
 		}
+		return collectEvents(iter, func() *payments.PaymentsPayment { return iter.Event })
 	}
+	return followEvents(ctx, cm, sdk, source, p.paymentChan, func(event *payments.PaymentsPayment) {
+		for _, handler := range p.paymentSubs {
+			go handler(*event)
+		}
+	})
 }
 
 func (p *PaymentEventChannels) SubscribePayment(handler func(payments.PaymentsPayment)) {
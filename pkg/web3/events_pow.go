@@ -2,13 +2,12 @@ package web3
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/pow"
-	"github.com/rs/zerolog/log"
 )
 
 type PowEventChannels struct {
@@ -28,49 +27,21 @@ func (s *PowEventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	blockNumber, err := sdk.getBlockNumber()
-	if err != nil {
-		return err
+	contracts := sdk.Contracts()
+	source := eventSource[pow.PowNewPowRound]{
+		name:      "pow.NewPowRound",
+		contracts: contracts,
+		watch: func(opts *bind.WatchOpts, sink chan<- *pow.PowNewPowRound) (event.Subscription, error) {
+			return contracts.Pow.WatchNewPowRound(opts, sink)
+		},
+		raw: func(event *pow.PowNewPowRound) types.Log { return event.Raw },
 	}
-
-	var newPowRoundSub event.Subscription
-
-	connectnewPowRoundSub := func() (event.Subscription, error) {
-		log.Debug().
-			Str("pow->connect", "newPowRound").
-			Msgf("start to watch new pow round")
-
-		return sdk.Contracts.Pow.WatchNewPowRound(
-			&bind.WatchOpts{Start: &blockNumber, Context: ctx},
-			s.newPowRoundChan,
-		)
-	}
-
-	newPowRoundSub, err = connectnewPowRoundSub()
-	if err != nil {
-		return err
-	}
-	cm.RegisterCallback(unsubscribeSub(newPowRoundSub))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case event := <-s.newPowRoundChan:
-			log.Debug().
-				Str("pow->event", "PowNewPowRound").
-				Msgf("%+v", event)
-
-			for _, handler := range s.newPowRoundSubs {
-				go handler(*event)
-			}
-		case err := <-newPowRoundSub.Err():
-			if err != nil {
-				return fmt.Errorf("cancel by pow newPowRound event subscribe error %w", err)
-			}
-			return nil
+	// a round that ended while we were away is no use to us, only the live ones are followed
+	return followEvents(ctx, cm, sdk, source, s.newPowRoundChan, func(event *pow.PowNewPowRound) {
+		for _, handler := range s.newPowRoundSubs {
+			go handler(*event)
 		}
-	}
+	})
 }
 
 func (t *PowEventChannels) SubscribenewPowRound(handler func(pow.PowNewPowRound)) {
@@ -0,0 +1,65 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// a reorg dropped blocks with events that had been handled, the listener
+// is restarted to replay the chain from where it was rewound to
+type ReorgError struct {
+	Event string
+	Block uint64
+}
+
+func (err *ReorgError) Error() string {
+	return fmt.Sprintf("a reorg dropped %s events from block %d", err.Event, err.Block)
+}
+
+type headerBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// the block to replay from when the chain no longer has the blocks some of
+// the logs were in, that is the block after the last one that is still
+// there so events only on the new chain between the two are not missed
+//
+// blocks before the oldest kept log are not checked, a reorg reaching back
+// past those is deeper than WEB3_REORG_DEPTH
+func findReorg(ctx context.Context, backend headerBackend, logs []types.Log) (uint64, bool, error) {
+	hashes := map[uint64]common.Hash{}
+	for _, raw := range logs {
+		hashes[raw.BlockNumber] = raw.BlockHash
+	}
+	blocks := make([]uint64, 0, len(hashes))
+	for block := range hashes {
+		blocks = append(blocks, block)
+	}
+	slices.Sort(blocks)
+
+	for i, block := range blocks {
+		header, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+		// the new chain is not as long yet
+		if errors.Is(err, ethereum.NotFound) {
+			header, err = nil, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if header != nil && header.Hash() == hashes[block] {
+			continue
+		}
+		if i == 0 {
+			return block, true, nil
+		}
+		return blocks[i-1] + 1, true, nil
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,54 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHeaders map[uint64]*types.Header
+
+func (headers fakeHeaders) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := headers[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+func TestFindReorg(t *testing.T) {
+	headers := fakeHeaders{}
+	for block := uint64(1); block <= 5; block++ {
+		headers[block] = &types.Header{Number: new(big.Int).SetUint64(block)}
+	}
+	logs := []types.Log{}
+	for _, block := range []uint64{2, 3, 5} {
+		logs = append(logs, types.Log{BlockNumber: block, BlockHash: headers[block].Hash()})
+	}
+
+	_, found, err := findReorg(context.Background(), headers, logs)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// block 5 was replaced so everything after 3, the last block still there, is replayed
+	headers[5] = &types.Header{Number: big.NewInt(5), Extra: []byte("other")}
+	block, found, err := findReorg(context.Background(), headers, logs)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(4), block)
+
+	// the new chain is shorter and the first kept block is gone too
+	delete(headers, 5)
+	headers[2] = &types.Header{Number: big.NewInt(2), Extra: []byte("other")}
+	block, found, err = findReorg(context.Background(), headers, logs)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(2), block)
+}
@@ -2,13 +2,12 @@ package web3
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/storage"
-	"github.com/rs/zerolog/log"
 )
 
 type StorageEventChannels struct {
@@ -28,47 +27,28 @@ func (s *StorageEventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	blockNumber, err := sdk.getBlockNumber()
-	if err != nil {
-		return err
+	contracts := sdk.Contracts()
+	source := eventSource[storage.StorageDealStateChange]{
+		name:      "storage.DealStateChange",
+		contracts: contracts,
+		watch: func(opts *bind.WatchOpts, sink chan<- *storage.StorageDealStateChange) (event.Subscription, error) {
+			return contracts.Storage.WatchDealStateChange(opts, sink)
+		},
+		raw:   func(event *storage.StorageDealStateChange) types.Log { return event.Raw },
+		parse: contracts.Storage.ParseDealStateChange,
 	}
-
-	var dealStateChangeSub event.Subscription
-
-	connectDealStateChangeSub := func() (event.Subscription, error) {
-		log.Debug().
-			Str("storage->connect", "DealStateChange").
-			Msgf("")
-		return sdk.Contracts.Storage.WatchDealStateChange(
-			&bind.WatchOpts{Start: &blockNumber, Context: ctx},
-			s.dealStateChangeChan,
-		)
-	}
-
-	dealStateChangeSub, err = connectDealStateChangeSub()
-	if err != nil {
-		return err
-	}
-	cm.RegisterCallback(unsubscribeSub(dealStateChangeSub))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case event := <-s.dealStateChangeChan:
-			log.Debug().
-				Str("storage->event", "DealStateChange").
-				Msgf("%+v", event)
-			for _, handler := range s.dealStateChangeSubs {
-				go handler(*event)
-			}
-		case err := <-dealStateChangeSub.Err():
-			if err != nil {
-				return fmt.Errorf("cancel by storage DealStateChange event subscribe error %w", err)
-			}
-			return nil
+	source.filter = func(opts *bind.FilterOpts) ([]*storage.StorageDealStateChange, error) {
+		iter, err := contracts.Storage.FilterDealStateChange(opts)
+		if err != nil {
+			return nil, err
 		}
+		return collectEvents(iter, func() *storage.StorageDealStateChange { return iter.Event })
 	}
+	return followEvents(ctx, cm, sdk, source, s.dealStateChangeChan, func(event *storage.StorageDealStateChange) {
+		for _, handler := range s.dealStateChangeSubs {
+			go handler(*event)
+		}
+	})
 }
 
 func (t *StorageEventChannels) SubscribeDealStateChange(handler func(storage.StorageDealStateChange)) {
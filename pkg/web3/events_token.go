@@ -2,14 +2,13 @@ package web3
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/lilypad-tech/lilypad/pkg/system"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/token"
-	"github.com/rs/zerolog/log"
 )
 
 type TokenEventChannels struct {
@@ -29,49 +28,28 @@ func (t *TokenEventChannels) Start(
 	cm *system.CleanupManager,
 	sdk *Web3SDK,
 ) error {
-	blockNumber, err := sdk.getBlockNumber()
-	if err != nil {
-		return err
+	contracts := sdk.Contracts()
+	source := eventSource[token.TokenTransfer]{
+		name:      "token.Transfer",
+		contracts: contracts,
+		watch: func(opts *bind.WatchOpts, sink chan<- *token.TokenTransfer) (event.Subscription, error) {
+			return contracts.Token.WatchTransfer(opts, sink, []common.Address{}, []common.Address{})
+		},
+		raw:   func(event *token.TokenTransfer) types.Log { return event.Raw },
+		parse: contracts.Token.ParseTransfer,
 	}
-
-	var transferSub event.Subscription
-
-	connectTransferSub := func() (event.Subscription, error) {
-		log.Debug().
-			Str("token->connect", "Transfer").
-			Msgf("")
-		return sdk.Contracts.Token.WatchTransfer(
-			&bind.WatchOpts{Start: &blockNumber, Context: ctx},
-			t.transferChan,
-			[]common.Address{},
-			[]common.Address{},
-		)
-	}
-
-	transferSub, err = connectTransferSub()
-	if err != nil {
-		return err
-	}
-	cm.RegisterCallback(unsubscribeSub(transferSub))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case event := <-t.transferChan:
-			log.Debug().
-				Str("token->event", "Transfer").
-				Msgf("%+v", event)
-			for _, handler := range t.transferSubs {
-				go handler(*event)
-			}
-		case err := <-transferSub.Err():
-			if err != nil {
-				return fmt.Errorf("cancel by token Transfer event subscribe error %w", err)
-			}
-			return nil
+	source.filter = func(opts *bind.FilterOpts) ([]*token.TokenTransfer, error) {
+		iter, err := contracts.Token.FilterTransfer(opts, []common.Address{}, []common.Address{})
+		if err != nil {
+			return nil, err
 		}
+		return collectEvents(iter, func() *token.TokenTransfer { return iter.Event })
 	}
+	return followEvents(ctx, cm, sdk, source, t.transferChan, func(event *token.TokenTransfer) {
+		for _, handler := range t.transferSubs {
+			go handler(*event)
+		}
+	})
 }
 
 func (t *TokenEventChannels) SubscribeTransfer(handler func(token.TokenTransfer)) {
@@ -0,0 +1,440 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// the json-rpc code providers send when we go over their rate limit
+const rpcLimitExceededCode = -32005
+
+var _ bind.ContractBackend = (*FailoverClient)(nil)
+var _ bind.DeployBackend = (*FailoverClient)(nil)
+
+type rpcEndpoint struct {
+	url string
+	// only the host is logged, the path often has an api key in it
+	host   string
+	client *ethclient.Client
+	// false from the first failed call until a health check gets an answer
+	healthy   bool
+	failures  int
+	lastError error
+}
+
+// talks to the first healthy RPC endpoint in the order they were
+// configured, a call that fails because of the endpoint is tried on the
+// next one and the earlier endpoints are taken back once they answer again
+type FailoverClient struct {
+	endpoints []*rpcEndpoint
+	// the index of the endpoint calls go to first
+	current int
+	// how long one attempt at a call can take
+	timeout time.Duration
+	mutex   sync.RWMutex
+}
+
+func newFailoverClient(ctx context.Context, urls []string, options Web3Options, span trace.Span) (*FailoverClient, error) {
+	client := &FailoverClient{
+		timeout: time.Duration(options.RpcTimeout) * time.Second,
+	}
+	for _, u := range urls {
+		parsedURL, err := url.Parse(u)
+		if err != nil {
+			log.Warn().Msgf("Unable to parse web3 RPC URL: %v", err)
+			span.RecordError(errors.New("Unable to parse web3 RPC URL"))
+			continue
+		}
+		endpoint := &rpcEndpoint{url: u, host: parsedURL.Host}
+		client.endpoints = append(client.endpoints, endpoint)
+
+		span.AddEvent("ethclient.dial", trace.WithAttributes(attribute.String("web3.rpc_url", endpoint.host)))
+		err = client.dial(ctx, endpoint)
+		if err != nil {
+			log.Warn().Msgf("Failed to connect to %s: %v", endpoint.host, err)
+			span.RecordError(fmt.Errorf("Failed to connect to %s", endpoint.host))
+			endpoint.lastError = err
+			continue
+		}
+		log.Info().Msgf("Connected to %s", endpoint.host)
+		span.AddEvent("ethclient.connected")
+		endpoint.healthy = true
+	}
+	client.current = client.firstHealthy()
+	if client.current < 0 {
+		return nil, errors.New("Failed to connect to a web3 RPC provider")
+	}
+	return client, nil
+}
+
+func (client *FailoverClient) dial(ctx context.Context, endpoint *rpcEndpoint) error {
+	dialCtx, cancel := client.withTimeout(ctx)
+	defer cancel()
+	ethClient, err := ethclient.DialContext(dialCtx, endpoint.url)
+	if err != nil {
+		return err
+	}
+	client.mutex.Lock()
+	endpoint.client = ethClient
+	client.mutex.Unlock()
+	return nil
+}
+
+func (client *FailoverClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, client.timeout)
+}
+
+// called with the lock held
+func (client *FailoverClient) firstHealthy() int {
+	for index, endpoint := range client.endpoints {
+		if endpoint.healthy {
+			return index
+		}
+	}
+	return -1
+}
+
+// the endpoints to try a call on, the current one then the other healthy
+// ones and the unhealthy ones last in case they have come back
+func (client *FailoverClient) order() []int {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	order := []int{}
+	if client.current >= 0 {
+		order = append(order, client.current)
+	}
+	for _, healthy := range []bool{true, false} {
+		for index, endpoint := range client.endpoints {
+			if index != client.current && endpoint.healthy == healthy && endpoint.client != nil {
+				order = append(order, index)
+			}
+		}
+	}
+	return order
+}
+
+func (client *FailoverClient) markHealthy(index int) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	endpoint := client.endpoints[index]
+	if endpoint.healthy {
+		endpoint.failures = 0
+		return
+	}
+	log.Info().Msgf("web3 RPC endpoint %s is answering again after %d failures", endpoint.host, endpoint.failures)
+	endpoint.healthy = true
+	endpoint.failures = 0
+	endpoint.lastError = nil
+	client.updateCurrent()
+}
+
+func (client *FailoverClient) markFailed(index int, method string, err error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	endpoint := client.endpoints[index]
+	endpoint.failures++
+	endpoint.lastError = err
+	if endpoint.healthy {
+		log.Warn().Err(err).Str("method", method).Msgf("web3 RPC endpoint %s failed", endpoint.host)
+		endpoint.healthy = false
+		client.updateCurrent()
+	}
+}
+
+// called with the lock held
+func (client *FailoverClient) updateCurrent() {
+	next := client.firstHealthy()
+	if next == client.current {
+		return
+	}
+	switch {
+	case next < 0:
+		log.Error().Msgf("no web3 RPC endpoint is healthy, still trying all of them")
+		return
+	case client.current >= 0 && next < client.current:
+		log.Info().Msgf("web3 RPC failing back to %s", client.endpoints[next].host)
+	default:
+		log.Warn().Msgf("web3 RPC failing over to %s", client.endpoints[next].host)
+	}
+	client.current = next
+}
+
+// whether err means the endpoint is down or overloaded rather than the
+// call itself being wrong, a revert would fail on every endpoint
+func isEndpointError(err error) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var httpError rpc.HTTPError
+	if errors.As(err, &httpError) {
+		return httpError.StatusCode == 429 || httpError.StatusCode >= 500
+	}
+	var rpcError rpc.Error
+	if errors.As(err, &rpcError) {
+		return rpcError.ErrorCode() == rpcLimitExceededCode
+	}
+	return true
+}
+
+// runs call on each endpoint in turn until one of them answers
+func failover[T any](client *FailoverClient, ctx context.Context, method string, call func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var zero T
+	var lastErr error
+	for _, index := range client.order() {
+		client.mutex.RLock()
+		ethClient := client.endpoints[index].client
+		client.mutex.RUnlock()
+
+		callCtx, cancel := client.withTimeout(ctx)
+		result, err := call(callCtx, ethClient)
+		cancel()
+		if err == nil {
+			client.markHealthy(index)
+			return result, nil
+		}
+		// the caller gave up, that says nothing about the endpoint
+		if ctx.Err() != nil || !isEndpointError(err) {
+			return zero, err
+		}
+		client.markFailed(index, method, err)
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no web3 RPC endpoint is connected")
+	}
+	return zero, lastErr
+}
+
+// asks the unhealthy endpoints for a block number every interval and takes
+// them back once they answer, dialling the ones we never connected to
+func (client *FailoverClient) checkHealth(ctx context.Context, interval time.Duration) {
+	if len(client.endpoints) < 2 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for index, endpoint := range client.endpoints {
+			client.mutex.RLock()
+			healthy, ethClient := endpoint.healthy, endpoint.client
+			client.mutex.RUnlock()
+			if healthy {
+				continue
+			}
+			if ethClient == nil {
+				if err := client.dial(ctx, endpoint); err != nil {
+					client.markFailed(index, "dial", err)
+					continue
+				}
+				client.mutex.RLock()
+				ethClient = endpoint.client
+				client.mutex.RUnlock()
+			}
+			checkCtx, cancel := client.withTimeout(ctx)
+			_, err := ethClient.BlockNumber(checkCtx)
+			cancel()
+			if err != nil {
+				client.markFailed(index, "eth_blockNumber", err)
+				continue
+			}
+			client.markHealthy(index)
+		}
+	}
+}
+
+// the rpc client of the endpoint in use, calls made on it do not fail over
+func (client *FailoverClient) Client() *rpc.Client {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	if client.current < 0 {
+		return nil
+	}
+	return client.endpoints[client.current].client.Client()
+}
+
+func (client *FailoverClient) Close() {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	for _, endpoint := range client.endpoints {
+		if endpoint.client != nil {
+			endpoint.client.Close()
+		}
+	}
+}
+
+func (client *FailoverClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return failover(client, ctx, "eth_blockNumber", func(ctx context.Context, ethClient *ethclient.Client) (uint64, error) {
+		return ethClient.BlockNumber(ctx)
+	})
+}
+
+func (client *FailoverClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return failover(client, ctx, "eth_getBalance", func(ctx context.Context, ethClient *ethclient.Client) (*big.Int, error) {
+		return ethClient.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (client *FailoverClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return failover(client, ctx, "eth_getCode", func(ctx context.Context, ethClient *ethclient.Client) ([]byte, error) {
+		return ethClient.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (client *FailoverClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return failover(client, ctx, "eth_call", func(ctx context.Context, ethClient *ethclient.Client) ([]byte, error) {
+		return ethClient.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (client *FailoverClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return failover(client, ctx, "eth_getBlockByNumber", func(ctx context.Context, ethClient *ethclient.Client) (*types.Header, error) {
+		return ethClient.HeaderByNumber(ctx, number)
+	})
+}
+
+func (client *FailoverClient) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return failover(client, ctx, "eth_getCode", func(ctx context.Context, ethClient *ethclient.Client) ([]byte, error) {
+		return ethClient.PendingCodeAt(ctx, account)
+	})
+}
+
+func (client *FailoverClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return failover(client, ctx, "eth_getTransactionCount", func(ctx context.Context, ethClient *ethclient.Client) (uint64, error) {
+		return ethClient.PendingNonceAt(ctx, account)
+	})
+}
+
+func (client *FailoverClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return failover(client, ctx, "eth_gasPrice", func(ctx context.Context, ethClient *ethclient.Client) (*big.Int, error) {
+		return ethClient.SuggestGasPrice(ctx)
+	})
+}
+
+func (client *FailoverClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return failover(client, ctx, "eth_maxPriorityFeePerGas", func(ctx context.Context, ethClient *ethclient.Client) (*big.Int, error) {
+		return ethClient.SuggestGasTipCap(ctx)
+	})
+}
+
+func (client *FailoverClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return failover(client, ctx, "eth_feeHistory", func(ctx context.Context, ethClient *ethclient.Client) (*ethereum.FeeHistory, error) {
+		return ethClient.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+func (client *FailoverClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return failover(client, ctx, "eth_estimateGas", func(ctx context.Context, ethClient *ethclient.Client) (uint64, error) {
+		return ethClient.EstimateGas(ctx, call)
+	})
+}
+
+// an endpoint that timed out may still have passed the transaction on,
+// the next one then already knows it and that counts as sent
+func (client *FailoverClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	attempts := 0
+	_, err := failover(client, ctx, "eth_sendRawTransaction", func(ctx context.Context, ethClient *ethclient.Client) (struct{}, error) {
+		attempts++
+		err := ethClient.SendTransaction(ctx, tx)
+		if err != nil && attempts > 1 && strings.Contains(err.Error(), "already known") {
+			return struct{}{}, nil
+		}
+		return struct{}{}, err
+	})
+	return err
+}
+
+func (client *FailoverClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return failover(client, ctx, "eth_getTransactionReceipt", func(ctx context.Context, ethClient *ethclient.Client) (*types.Receipt, error) {
+		return ethClient.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (client *FailoverClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return failover(client, ctx, "eth_getLogs", func(ctx context.Context, ethClient *ethclient.Client) ([]types.Log, error) {
+		return ethClient.FilterLogs(ctx, query)
+	})
+}
+
+// the event listeners resubscribe when a subscription drops,
+// marking the endpoint failed sends them to the next one
+func (client *FailoverClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	index := -1
+	sub, err := failover(client, ctx, "eth_subscribe", func(ctx context.Context, ethClient *ethclient.Client) (ethereum.Subscription, error) {
+		index = client.indexOf(ethClient)
+		return ethClient.SubscribeFilterLogs(ctx, query, ch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newFailoverSubscription(client, index, sub), nil
+}
+
+func (client *FailoverClient) indexOf(ethClient *ethclient.Client) int {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	for index, endpoint := range client.endpoints {
+		if endpoint.client == ethClient {
+			return index
+		}
+	}
+	return -1
+}
+
+type failoverSubscription struct {
+	ethereum.Subscription
+	err chan error
+}
+
+func newFailoverSubscription(client *FailoverClient, index int, sub ethereum.Subscription) *failoverSubscription {
+	wrapped := &failoverSubscription{
+		Subscription: sub,
+		err:          make(chan error, 1),
+	}
+	go func() {
+		defer close(wrapped.err)
+		// the channel is closed on unsubscribe and sends once on an error
+		err, ok := <-sub.Err()
+		if !ok {
+			return
+		}
+		if err != nil && index >= 0 {
+			client.markFailed(index, "eth_subscribe", err)
+		}
+		wrapped.err <- err
+	}()
+	return wrapped
+}
+
+func (sub *failoverSubscription) Err() <-chan error {
+	return sub.err
+}
@@ -0,0 +1,111 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testEthService struct {
+	blockNumber uint64
+}
+
+func (service *testEthService) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(service.blockNumber)
+}
+
+// an RPC endpoint that answers with its block number until it is taken down
+func newTestEndpoint(t *testing.T, blockNumber uint64) (*httptest.Server, *atomic.Bool) {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &testEthService{blockNumber: blockNumber}))
+	down := &atomic.Bool{}
+	endpoint := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if down.Load() {
+			http.Error(res, "down", http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(res, req)
+	}))
+	t.Cleanup(endpoint.Close)
+	t.Cleanup(server.Stop)
+	return endpoint, down
+}
+
+func TestFailoverClient(t *testing.T) {
+	primary, primaryDown := newTestEndpoint(t, 1)
+	backup, _ := newTestEndpoint(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := newFailoverClient(ctx, []string{primary.URL, backup.URL}, Web3Options{RpcTimeout: 5}, trace.SpanFromContext(ctx))
+	require.NoError(t, err)
+	defer client.Close()
+
+	blockNumber, err := client.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), blockNumber)
+
+	// fails over without the caller seeing the error
+	primaryDown.Store(true)
+	blockNumber, err = client.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), blockNumber)
+	assert.False(t, client.endpoints[0].healthy)
+	assert.Equal(t, 1, client.current)
+
+	// and goes back once the health check gets an answer
+	primaryDown.Store(false)
+	go client.checkHealth(ctx, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		blockNumber, err := client.BlockNumber(ctx)
+		return err == nil && blockNumber == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFailoverClientAllDown(t *testing.T) {
+	primary, primaryDown := newTestEndpoint(t, 1)
+	backup, backupDown := newTestEndpoint(t, 2)
+
+	ctx := context.Background()
+	client, err := newFailoverClient(ctx, []string{primary.URL, backup.URL}, Web3Options{RpcTimeout: 5}, trace.SpanFromContext(ctx))
+	require.NoError(t, err)
+	defer client.Close()
+
+	primaryDown.Store(true)
+	backupDown.Store(true)
+	_, err = client.BlockNumber(ctx)
+	assert.Error(t, err)
+
+	// still tried when nothing is healthy so one coming back is noticed
+	backupDown.Store(false)
+	blockNumber, err := client.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), blockNumber)
+	assert.Equal(t, 1, client.current)
+}
+
+func TestIsEndpointError(t *testing.T) {
+	assert.True(t, isEndpointError(context.DeadlineExceeded))
+	assert.True(t, isEndpointError(rpc.HTTPError{StatusCode: http.StatusBadGateway}))
+	assert.True(t, isEndpointError(rpc.HTTPError{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, isEndpointError(rpc.HTTPError{StatusCode: http.StatusBadRequest}))
+}
+
+func TestRpcURLList(t *testing.T) {
+	options := Web3Options{
+		RpcURL:  "wss://one, wss://two",
+		RpcURLs: []string{"wss://two", "wss://three", ""},
+	}
+	assert.Equal(t, []string{"wss://one", "wss://two", "wss://three"}, options.RpcURLList())
+}
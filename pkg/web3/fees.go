@@ -0,0 +1,94 @@
+package web3
+
+import (
+	"context"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// the priority fee comes from what recent blocks paid
+	FeeModeAuto = "auto"
+	// the priority fee is always WEB3_MAX_PRIORITY_FEE_PER_GAS
+	FeeModeFixed = "fixed"
+)
+
+type feeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// the backend the contract bindings send transactions through, the
+// bindings build a dynamic fee transaction whenever the chain has a base
+// fee and ask this for the priority fee when the options do not fix one
+type feeBackend struct {
+	bind.ContractBackend
+	history feeHistoryReader
+	options Web3Options
+}
+
+func newFeeBackend(client *FailoverClient, options Web3Options) bind.ContractBackend {
+	return &feeBackend{
+		ContractBackend: client,
+		history:         client,
+		options:         options,
+	}
+}
+
+// the median over the last blocks of the priority fee paid at the
+// configured percentile, capped so it never goes over the max fees
+func (backend *feeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	tip, err := backend.historyTip(ctx)
+	if err != nil {
+		// not every rpc node serves eth_feeHistory
+		log.Debug().Err(err).Msgf("error reading fee history, asking the node for a priority fee")
+		tip, err = backend.ContractBackend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if limit := backend.options.MaxPriorityFeePerGas; limit > 0 && tip.Cmp(new(big.Int).SetUint64(limit)) > 0 {
+		tip = new(big.Int).SetUint64(limit)
+	}
+	if limit := backend.options.feeCap(); limit > 0 && tip.Cmp(new(big.Int).SetUint64(limit)) > 0 {
+		tip = new(big.Int).SetUint64(limit)
+	}
+	return tip, nil
+}
+
+func (backend *feeBackend) historyTip(ctx context.Context) (*big.Int, error) {
+	history, err := backend.history.FeeHistory(
+		ctx,
+		uint64(backend.options.FeeHistoryBlocks),
+		nil,
+		[]float64{float64(backend.options.FeeHistoryPercentile)},
+	)
+	if err != nil {
+		return nil, err
+	}
+	tips := []*big.Int{}
+	for _, rewards := range history.Reward {
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	if len(tips) == 0 {
+		return big.NewInt(0), nil
+	}
+	slices.SortFunc(tips, func(a, b *big.Int) int { return a.Cmp(b) })
+	return new(big.Int).Set(tips[len(tips)/2]), nil
+}
+
+// the fees that are the same for every transaction go on the transact opts,
+// the fee cap is the lower of the max fee and the role's gas cap and a nil one lets the bindings use twice the base fee plus the tip
+func applyFeeOptions(opts *bind.TransactOpts, options Web3Options) {
+	if feeCap := options.feeCap(); feeCap > 0 {
+		opts.GasFeeCap = new(big.Int).SetUint64(feeCap)
+	}
+	if options.FeeMode == FeeModeFixed {
+		opts.GasTipCap = new(big.Int).SetUint64(options.MaxPriorityFeePerGas)
+	}
+}
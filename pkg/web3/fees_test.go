@@ -0,0 +1,103 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFeeHistory struct {
+	history *ethereum.FeeHistory
+	err     error
+}
+
+func (history testFeeHistory) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return history.history, history.err
+}
+
+// only the node's own suggestion is ever asked for
+type testTipBackend struct {
+	bind.ContractBackend
+	tip int64
+}
+
+func (backend testTipBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(backend.tip), nil
+}
+
+func rewards(tips ...int64) [][]*big.Int {
+	reward := [][]*big.Int{}
+	for _, tip := range tips {
+		reward = append(reward, []*big.Int{big.NewInt(tip)})
+	}
+	return reward
+}
+
+func TestFeeBackendSuggestGasTipCap(t *testing.T) {
+	tests := []struct {
+		name    string
+		history testFeeHistory
+		options Web3Options
+		want    int64
+	}{
+		{
+			name:    "median of recent blocks",
+			history: testFeeHistory{history: &ethereum.FeeHistory{Reward: rewards(5, 1, 100, 3, 2)}},
+			want:    3,
+		},
+		{
+			name:    "capped by the max priority fee",
+			history: testFeeHistory{history: &ethereum.FeeHistory{Reward: rewards(50, 60, 70)}},
+			options: Web3Options{MaxPriorityFeePerGas: 10},
+			want:    10,
+		},
+		{
+			name:    "capped by the max fee",
+			history: testFeeHistory{history: &ethereum.FeeHistory{Reward: rewards(50, 60, 70)}},
+			options: Web3Options{MaxFeePerGas: 20},
+			want:    20,
+		},
+		{
+			name:    "empty blocks pay no tip",
+			history: testFeeHistory{history: &ethereum.FeeHistory{}},
+			want:    0,
+		},
+		{
+			name:    "falls back to the node without fee history",
+			history: testFeeHistory{err: errors.New("method not found")},
+			want:    7,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &feeBackend{
+				ContractBackend: testTipBackend{tip: 7},
+				history:         test.history,
+				options:         test.options,
+			}
+			tip, err := backend.SuggestGasTipCap(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, big.NewInt(test.want), tip)
+		})
+	}
+}
+
+func TestApplyFeeOptions(t *testing.T) {
+	opts := &bind.TransactOpts{}
+	applyFeeOptions(opts, Web3Options{FeeMode: FeeModeAuto})
+	assert.Nil(t, opts.GasFeeCap)
+	assert.Nil(t, opts.GasTipCap)
+
+	opts = &bind.TransactOpts{}
+	applyFeeOptions(opts, Web3Options{FeeMode: FeeModeFixed, MaxFeePerGas: 100, MaxPriorityFeePerGas: 2})
+	assert.Equal(t, big.NewInt(100), opts.GasFeeCap)
+	assert.Equal(t, big.NewInt(2), opts.GasTipCap)
+}
@@ -0,0 +1,237 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// how many base fee samples the spike check compares against
+const gasOracleSamples = 20
+
+// the fees the oracle last saw, all in wei per gas
+type FeeEstimate struct {
+	BlockNumber uint64 `json:"block_number"`
+	// the gas price on chains without a base fee
+	BaseFee     uint64 `json:"base_fee"`
+	PriorityFee uint64 `json:"priority_fee"`
+	// the most a transaction sent now would offer to pay, after the caps
+	MaxFee uint64 `json:"max_fee"`
+	// the cap for this node's role, 0 when there is none
+	Cap uint64 `json:"cap"`
+	// the base fee is far enough over its recent median that non urgent
+	// transactions are held back
+	Spike bool `json:"spike"`
+	// the base fee and priority fee are over the cap so a transaction
+	// would sit in the mempool until they come down
+	OverCap   bool  `json:"over_cap"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+type gasOracleBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// keeps an eye on the chain's fees so transactions that can wait are sent
+// when fees are normal and none of them offer more than the role's cap
+type GasOracle struct {
+	backend  gasOracleBackend
+	options  Web3Options
+	estimate *FeeEstimate
+	// the recent base fees, oldest first
+	baseFees []*big.Int
+	mutex    sync.RWMutex
+
+	baseFeeGauge     prometheus.Gauge
+	priorityFeeGauge prometheus.Gauge
+	maxFeeGauge      prometheus.Gauge
+	spikeGauge       prometheus.Gauge
+}
+
+func NewGasOracle(backend gasOracleBackend, options Web3Options) *GasOracle {
+	gauge := func(name string, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "lilypad",
+			Subsystem: "web3",
+			Name:      name,
+			Help:      help,
+		})
+	}
+	return &GasOracle{
+		backend:          backend,
+		options:          options,
+		baseFeeGauge:     gauge("base_fee_wei", "The base fee per gas of the latest block."),
+		priorityFeeGauge: gauge("priority_fee_wei", "The priority fee per gas transactions are sent with."),
+		maxFeeGauge:      gauge("max_fee_wei", "The most per gas a transaction sent now would pay."),
+		spikeGauge:       gauge("gas_spike", "1 while non urgent transactions are held back for high fees."),
+	}
+}
+
+// the gauges for the servers that have a /metrics endpoint
+func (oracle *GasOracle) Collectors() []prometheus.Collector {
+	if oracle == nil {
+		return nil
+	}
+	return []prometheus.Collector{
+		oracle.baseFeeGauge,
+		oracle.priorityFeeGauge,
+		oracle.maxFeeGauge,
+		oracle.spikeGauge,
+	}
+}
+
+// nil until the first update has gone through
+func (oracle *GasOracle) Estimate() *FeeEstimate {
+	if oracle == nil {
+		return nil
+	}
+	oracle.mutex.RLock()
+	defer oracle.mutex.RUnlock()
+	if oracle.estimate == nil {
+		return nil
+	}
+	estimate := *oracle.estimate
+	return &estimate
+}
+
+func (oracle *GasOracle) Run(ctx context.Context) {
+	interval := time.Duration(oracle.options.GasOracleInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := oracle.update(ctx); err != nil {
+			log.Warn().Err(err).Msgf("error updating gas fee estimate")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (oracle *GasOracle) update(ctx context.Context) error {
+	header, err := oracle.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	baseFee := header.BaseFee
+	priorityFee := big.NewInt(0)
+	if baseFee == nil {
+		// a chain without a base fee only has the one price
+		baseFee, err = oracle.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		priorityFee, err = oracle.backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the same sum the bindings use when there is no fee cap
+	maxFee := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), priorityFee)
+	feeCap := oracle.options.feeCap()
+	if feeCap > 0 && maxFee.Cmp(new(big.Int).SetUint64(feeCap)) > 0 {
+		maxFee.SetUint64(feeCap)
+	}
+	overCap := feeCap > 0 && new(big.Int).Add(baseFee, priorityFee).Cmp(new(big.Int).SetUint64(feeCap)) > 0
+
+	oracle.mutex.Lock()
+	defer oracle.mutex.Unlock()
+	spike := oracle.isSpike(baseFee)
+	oracle.baseFees = append(oracle.baseFees, baseFee)
+	if len(oracle.baseFees) > gasOracleSamples {
+		oracle.baseFees = oracle.baseFees[1:]
+	}
+
+	previous := oracle.estimate
+	oracle.estimate = &FeeEstimate{
+		BlockNumber: header.Number.Uint64(),
+		BaseFee:     baseFee.Uint64(),
+		PriorityFee: priorityFee.Uint64(),
+		MaxFee:      maxFee.Uint64(),
+		Cap:         feeCap,
+		Spike:       spike,
+		OverCap:     overCap,
+		UpdatedAt:   time.Now().UnixMilli(),
+	}
+	if spike && (previous == nil || !previous.Spike) {
+		log.Warn().Uint64("base_fee", oracle.estimate.BaseFee).Msgf("gas fees are spiking, holding back transactions that can wait")
+	}
+	if !spike && previous != nil && previous.Spike {
+		log.Info().Uint64("base_fee", oracle.estimate.BaseFee).Msgf("gas fees are back to normal")
+	}
+	if overCap && (previous == nil || !previous.OverCap) {
+		log.Warn().Uint64("base_fee", oracle.estimate.BaseFee).Uint64("cap", feeCap).Msgf("gas fees are over the %s cap, transactions will wait in the mempool", oracle.options.Service)
+	}
+
+	oracle.baseFeeGauge.Set(float64(oracle.estimate.BaseFee))
+	oracle.priorityFeeGauge.Set(float64(oracle.estimate.PriorityFee))
+	oracle.maxFeeGauge.Set(float64(oracle.estimate.MaxFee))
+	if spike {
+		oracle.spikeGauge.Set(1)
+	} else {
+		oracle.spikeGauge.Set(0)
+	}
+	return nil
+}
+
+// called with the lock held, a few samples are needed before
+// there is anything to call normal
+func (oracle *GasOracle) isSpike(baseFee *big.Int) bool {
+	threshold := oracle.options.GasSpikeThreshold
+	if threshold <= 0 || len(oracle.baseFees) < 3 {
+		return false
+	}
+	sorted := slices.Clone(oracle.baseFees)
+	slices.SortFunc(sorted, func(a, b *big.Int) int { return a.Cmp(b) })
+	median := sorted[len(sorted)/2]
+	// base fee / median > threshold / 100
+	return new(big.Int).Mul(baseFee, big.NewInt(100)).Cmp(new(big.Int).Mul(median, big.NewInt(int64(threshold)))) > 0
+}
+
+// holds back a transaction that can wait while fees are spiking or over
+// the cap, it goes anyway once it has waited WEB3_GAS_MAX_DEFER seconds
+func (oracle *GasOracle) WaitForFees(ctx context.Context) error {
+	if oracle == nil || oracle.options.GasOracleInterval <= 0 {
+		return nil
+	}
+	high := func() bool {
+		estimate := oracle.Estimate()
+		return estimate != nil && (estimate.Spike || estimate.OverCap)
+	}
+	if !high() {
+		return nil
+	}
+	log.Info().Msgf("deferring transaction until gas fees come down")
+	deadline := time.After(time.Duration(oracle.options.GasMaxDefer) * time.Second)
+	ticker := time.NewTicker(time.Duration(oracle.options.GasOracleInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Join(errors.New("gave up waiting for gas fees to come down"), ctx.Err())
+		case <-deadline:
+			log.Warn().Msgf("gas fees are still high, sending the deferred transaction anyway")
+			return nil
+		case <-ticker.C:
+			if !high() {
+				return nil
+			}
+		}
+	}
+}
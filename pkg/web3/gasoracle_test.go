@@ -0,0 +1,92 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGasBackend struct {
+	baseFee int64
+	tip     int64
+}
+
+func (backend *testGasBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(backend.baseFee)}, nil
+}
+
+func (backend *testGasBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(backend.baseFee), nil
+}
+
+func (backend *testGasBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(backend.tip), nil
+}
+
+func TestGasOracleSpike(t *testing.T) {
+	backend := &testGasBackend{baseFee: 100, tip: 2}
+	oracle := NewGasOracle(backend, Web3Options{GasSpikeThreshold: 200})
+	for i := 0; i < 3; i++ {
+		require.NoError(t, oracle.update(context.Background()))
+		assert.False(t, oracle.Estimate().Spike)
+	}
+
+	// twice the median is not over the threshold
+	backend.baseFee = 200
+	require.NoError(t, oracle.update(context.Background()))
+	assert.False(t, oracle.Estimate().Spike)
+
+	backend.baseFee = 201
+	require.NoError(t, oracle.update(context.Background()))
+	estimate := oracle.Estimate()
+	assert.True(t, estimate.Spike)
+	assert.Equal(t, uint64(201*2+2), estimate.MaxFee)
+}
+
+func TestGasOracleCaps(t *testing.T) {
+	backend := &testGasBackend{baseFee: 100, tip: 2}
+	oracle := NewGasOracle(backend, Web3Options{
+		MaxFeePerGas: 500,
+		GasCaps:      map[string]int64{string(system.SolverService): 150},
+		Service:      system.SolverService,
+	})
+	require.NoError(t, oracle.update(context.Background()))
+	estimate := oracle.Estimate()
+	assert.Equal(t, uint64(150), estimate.Cap)
+	assert.Equal(t, uint64(150), estimate.MaxFee)
+	assert.False(t, estimate.OverCap)
+
+	backend.baseFee = 149
+	require.NoError(t, oracle.update(context.Background()))
+	assert.True(t, oracle.Estimate().OverCap)
+
+	// the other roles only have the max fee
+	oracle.options.Service = system.JobCreatorService
+	require.NoError(t, oracle.update(context.Background()))
+	assert.Equal(t, uint64(500), oracle.Estimate().Cap)
+	assert.False(t, oracle.Estimate().OverCap)
+}
+
+func TestGasOracleWaitForFees(t *testing.T) {
+	var oracle *GasOracle
+	require.NoError(t, oracle.WaitForFees(context.Background()))
+
+	oracle = NewGasOracle(&testGasBackend{}, Web3Options{GasOracleInterval: 1})
+	oracle.estimate = &FeeEstimate{Spike: true}
+	start := time.Now()
+	require.NoError(t, oracle.WaitForFees(context.Background()))
+	assert.Less(t, time.Since(start), time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	oracle.options.GasMaxDefer = 60
+	assert.Error(t, oracle.WaitForFees(ctx))
+}
@@ -0,0 +1,119 @@
+package web3
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// the gas one method was sent with by one service, the contract is kept
+// apart so a method that costs more after an upgrade shows up on its own
+type GasUsage struct {
+	Service  string
+	Method   string
+	Contract string
+	// only the mined transactions are counted
+	Transactions int
+	Reverted     int
+	GasUsed      uint64
+	MaxGasUsed   uint64
+	GasCost      *big.Int
+}
+
+func (usage GasUsage) AverageGasUsed() uint64 {
+	if usage.Transactions == 0 {
+		return 0
+	}
+	return usage.GasUsed / uint64(usage.Transactions)
+}
+
+// per service, method and contract with the most gas first
+func SummarizeGasUsage(entries []JournalEntry) []GasUsage {
+	type key struct{ service, method, contract string }
+	byMethod := map[key]*GasUsage{}
+	for _, entry := range entries {
+		if entry.MinedAt == 0 {
+			continue
+		}
+		k := key{entry.Service, entry.Method, entry.To}
+		usage, ok := byMethod[k]
+		if !ok {
+			usage = &GasUsage{Service: entry.Service, Method: entry.Method, Contract: entry.To, GasCost: big.NewInt(0)}
+			byMethod[k] = usage
+		}
+		usage.Transactions++
+		if entry.Status == TxStatusReverted {
+			usage.Reverted++
+		}
+		usage.GasUsed += entry.GasUsed
+		usage.MaxGasUsed = max(usage.MaxGasUsed, entry.GasUsed)
+		usage.GasCost.Add(usage.GasCost, entry.GasCost())
+	}
+	usages := make([]GasUsage, 0, len(byMethod))
+	for _, usage := range byMethod {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].GasUsed != usages[j].GasUsed {
+			return usages[i].GasUsed > usages[j].GasUsed
+		}
+		return usages[i].Method < usages[j].Method
+	})
+	return usages
+}
+
+type gasMetrics struct {
+	gasUsed metric.Int64Counter
+	gasCost metric.Float64Counter
+}
+
+// counts the gas each mined transaction used by method, as well as the
+// balance metrics
+func (sdk *Web3SDK) RegisterMetrics(meter metric.Meter) error {
+	if err := sdk.Balances.RegisterMetrics(meter); err != nil {
+		return err
+	}
+	gasUsed, err := meter.Int64Counter(
+		"web3.gas_used",
+		metric.WithDescription("The gas used by the transactions the service sent, by contract method."),
+	)
+	if err != nil {
+		return err
+	}
+	gasCost, err := meter.Float64Counter(
+		"web3.gas_cost",
+		metric.WithDescription("What the gas for the transactions the service sent cost in whole coins, by contract method."),
+	)
+	if err != nil {
+		return err
+	}
+	sdk.gasCounters.Store(&gasMetrics{gasUsed: gasUsed, gasCost: gasCost})
+	return nil
+}
+
+func (sdk *Web3SDK) recordGasUsed(tx *types.Transaction, receipt *types.Receipt) {
+	metrics := sdk.gasCounters.Load()
+	if metrics == nil || receipt == nil {
+		return
+	}
+	method, _, _ := describeTransaction(tx, sdk.Contracts().Addresses)
+	status := TxStatusSuccess
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status = TxStatusReverted
+	}
+	attributes := metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("status", status),
+	)
+	ctx := context.Background()
+	metrics.gasUsed.Add(ctx, int64(receipt.GasUsed), attributes)
+	if receipt.EffectiveGasPrice != nil {
+		cost := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		costInCoins, _ := WeiToEther(cost).Float64()
+		metrics.gasCost.Add(ctx, costInCoins, attributes)
+	}
+}
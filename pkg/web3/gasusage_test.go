@@ -0,0 +1,37 @@
+//go:build unit
+
+package web3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeGasUsage(t *testing.T) {
+	usages := SummarizeGasUsage([]JournalEntry{
+		{Service: "resource-provider", Method: "controller.agree", To: "0xc1", Status: TxStatusSuccess, GasUsed: 200, GasPrice: "2", MinedAt: 1},
+		{Service: "resource-provider", Method: "controller.agree", To: "0xc1", Status: TxStatusReverted, GasUsed: 100, GasPrice: "2", MinedAt: 2},
+		// the same method on the upgraded controller is counted on its own
+		{Service: "resource-provider", Method: "controller.agree", To: "0xc2", Status: TxStatusSuccess, GasUsed: 400, GasPrice: "1", MinedAt: 3},
+		{Service: "resource-provider", Method: "controller.addResult", To: "0xc1", Status: TxStatusSuccess, GasUsed: 50, GasPrice: "1", MinedAt: 4},
+		// nothing was mined so there is no gas to count
+		{Service: "resource-provider", Method: "controller.addResult", To: "0xc1", Status: TxStatusPending},
+	})
+
+	require.Len(t, usages, 3)
+	assert.Equal(t, "0xc2", usages[0].Contract)
+	assert.Equal(t, uint64(400), usages[0].GasUsed)
+
+	agree := usages[1]
+	assert.Equal(t, "controller.agree", agree.Method)
+	assert.Equal(t, 2, agree.Transactions)
+	assert.Equal(t, 1, agree.Reverted)
+	assert.Equal(t, uint64(300), agree.GasUsed)
+	assert.Equal(t, uint64(150), agree.AverageGasUsed())
+	assert.Equal(t, uint64(200), agree.MaxGasUsed)
+	assert.Equal(t, "600", agree.GasCost.String())
+
+	assert.Equal(t, 1, usages[2].Transactions)
+}
@@ -0,0 +1,135 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+)
+
+// the newest block a reorg is not expected to drop, WEB3_REORG_DEPTH
+// blocks behind the head
+func (sdk *Web3SDK) ConfirmedBlock(ctx context.Context) (uint64, error) {
+	head, err := sdk.Client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	depth := uint64(sdk.Options.ReorgDepth)
+	if head < depth {
+		return 0, nil
+	}
+	return head - depth, nil
+}
+
+// every event the Lilypad contracts emitted between the blocks, both
+// included, in chain order
+func (sdk *Web3SDK) ChainEvents(ctx context.Context, from uint64, to uint64) ([]data.ChainEvent, error) {
+	abis := contractABIs(sdk.Contracts().Addresses)
+	addresses := []common.Address{}
+	for address := range abis {
+		if address != (common.Address{}) {
+			addresses = append(addresses, address)
+		}
+	}
+	events := []data.ChainEvent{}
+	for start := from; start <= to; start += backfillBlockRange {
+		end := min(start+backfillBlockRange-1, to)
+		logs, err := sdk.Client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: addresses,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading events from block %d: %w", start, err)
+		}
+		for _, raw := range logs {
+			if raw.Removed {
+				continue
+			}
+			if event, ok := decodeChainEvent(abis, raw); ok {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+func ChainEventID(block uint64, index uint) string {
+	return fmt.Sprintf("%012d-%06d", block, index)
+}
+
+// false for logs from a contract or with an event we do not know
+func decodeChainEvent(abis map[common.Address]namedABI, raw types.Log) (data.ChainEvent, bool) {
+	contract, ok := abis[raw.Address]
+	if !ok || len(raw.Topics) == 0 {
+		return data.ChainEvent{}, false
+	}
+	event, err := contract.abi.EventByID(raw.Topics[0])
+	if err != nil {
+		return data.ChainEvent{}, false
+	}
+	values := map[string]interface{}{}
+	if err := event.Inputs.NonIndexed().UnpackIntoMap(values, raw.Data); err != nil {
+		return data.ChainEvent{}, false
+	}
+	indexed := abi.Arguments{}
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, raw.Topics[1:]); err != nil {
+		return data.ChainEvent{}, false
+	}
+
+	chainEvent := data.ChainEvent{
+		ID:        ChainEventID(raw.BlockNumber, raw.Index),
+		Contract:  contract.name,
+		Address:   strings.ToLower(raw.Address.Hex()),
+		Event:     event.Name,
+		Block:     raw.BlockNumber,
+		TxHash:    raw.TxHash.Hex(),
+		LogIndex:  raw.Index,
+		Addresses: []string{},
+		Args:      map[string]string{},
+	}
+	for name, value := range values {
+		chainEvent.Args[name] = formatEventArg(value)
+		if address, ok := value.(common.Address); ok {
+			chainEvent.Addresses = append(chainEvent.Addresses, strings.ToLower(address.Hex()))
+		}
+	}
+	sort.Strings(chainEvent.Addresses)
+	if dealID, ok := values["dealId"].(string); ok {
+		chainEvent.DealID = dealID
+	}
+	return chainEvent, true
+}
+
+func formatEventArg(value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return strings.ToLower(v.Hex())
+	case common.Hash:
+		return v.Hex()
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Encode(v)
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	default:
+		return fmt.Sprint(v)
+	}
+}
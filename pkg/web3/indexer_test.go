@@ -0,0 +1,66 @@
+//go:build unit
+
+package web3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/payments"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeChainEvent(t *testing.T) {
+	addresses := ContractAddresses{
+		Payments: common.HexToAddress("0x10"),
+		Token:    common.HexToAddress("0x20"),
+	}
+	abis := contractABIs(addresses)
+	payee := common.HexToAddress("0xabcdef")
+
+	paymentsABI, err := payments.PaymentsMetaData.GetAbi()
+	require.NoError(t, err)
+	paymentEvent := paymentsABI.Events["Payment"]
+	eventData, err := paymentEvent.Inputs.NonIndexed().Pack("deal-1", payee, big.NewInt(5), uint8(1), uint8(0))
+	require.NoError(t, err)
+	event, ok := decodeChainEvent(abis, types.Log{
+		Address:     addresses.Payments,
+		Topics:      []common.Hash{paymentEvent.ID},
+		Data:        eventData,
+		BlockNumber: 12,
+		Index:       3,
+	})
+	require.True(t, ok)
+	assert.Equal(t, "000000000012-000003", event.ID)
+	assert.Equal(t, "payments", event.Contract)
+	assert.Equal(t, "Payment", event.Event)
+	assert.Equal(t, "deal-1", event.DealID)
+	assert.Equal(t, []string{"0x0000000000000000000000000000000000abcdef"}, event.Addresses)
+	assert.Equal(t, "5", event.Args["amount"])
+
+	// the indexed arguments come from the topics
+	tokenABI, err := token.TokenMetaData.GetAbi()
+	require.NoError(t, err)
+	transferEvent := tokenABI.Events["Transfer"]
+	eventData, err = transferEvent.Inputs.NonIndexed().Pack(big.NewInt(7))
+	require.NoError(t, err)
+	from := common.HexToAddress("0x01")
+	event, ok = decodeChainEvent(abis, types.Log{
+		Address: addresses.Token,
+		Topics:  []common.Hash{transferEvent.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(payee.Bytes())},
+		Data:    eventData,
+	})
+	require.True(t, ok)
+	assert.Equal(t, "Transfer", event.Event)
+	assert.Empty(t, event.DealID)
+	assert.Len(t, event.Addresses, 2)
+	assert.Equal(t, "7", event.Args["value"])
+
+	// logs from anywhere else are not ours
+	_, ok = decodeChainEvent(abis, types.Log{Address: common.HexToAddress("0x30"), Topics: []common.Hash{transferEvent.ID}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,325 @@
+package web3
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/jobcreator"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/mediation"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/payments"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/pow"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/storage"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/token"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/users"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TxStatusPending  = "pending"
+	TxStatusSuccess  = "success"
+	TxStatusReverted = "reverted"
+	// nothing was mined or we stopped waiting for it, Error says why
+	TxStatusFailed = "failed"
+)
+
+// one transaction we sent, the journal has a line for when it was sent
+// and another once it was mined or given up on
+type JournalEntry struct {
+	Hash string `json:"hash"`
+	// the replacement that was mined when the fees had to be raised
+	MinedHash string `json:"mined_hash,omitempty"`
+	Service   string `json:"service"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	// e.g. controller.agree, or the selector for a method we do not know
+	Method string `json:"method"`
+	// keccak256 of the encoded arguments, two sends of the same call match
+	ArgsHash string `json:"args_hash"`
+	DealID   string `json:"deal_id,omitempty"`
+	Nonce    uint64 `json:"nonce"`
+	Status   string `json:"status"`
+	GasUsed  uint64 `json:"gas_used,omitempty"`
+	// the effective price paid per gas in wei
+	GasPrice string `json:"gas_price,omitempty"`
+	Block    uint64 `json:"block,omitempty"`
+	Error    string `json:"error,omitempty"`
+	SentAt   int64  `json:"sent_at"`
+	MinedAt  int64  `json:"mined_at,omitempty"`
+}
+
+// the wei spent on gas, 0 until it is mined
+func (entry JournalEntry) GasCost() *big.Int {
+	price, ok := new(big.Int).SetString(entry.GasPrice, 10)
+	if !ok {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Mul(price, new(big.Int).SetUint64(entry.GasUsed))
+}
+
+type JournalQuery struct {
+	// entries sent before this are left out, the zero time keeps them all
+	Since  time.Time
+	DealID string
+	Method string
+	Status string
+}
+
+func (query JournalQuery) matches(entry JournalEntry) bool {
+	return (query.Since.IsZero() || entry.SentAt >= query.Since.UnixMilli()) &&
+		(query.DealID == "" || entry.DealID == query.DealID) &&
+		(query.Method == "" || entry.Method == query.Method) &&
+		(query.Status == "" || entry.Status == query.Status)
+}
+
+// every transaction one node sent, as json lines that are only appended to
+// so a crash can at worst lose the line being written
+type TransactionJournal struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// where the journals of every service are kept
+func JournalDir() string {
+	return system.GetDataDir("transactions")
+}
+
+// one file per service, chain and address like the event checkpoints
+func NewTransactionJournal(options Web3Options, address string) *TransactionJournal {
+	name := fmt.Sprintf("%s-%d-%s.jsonl", options.Service, options.ChainID, strings.ToLower(address))
+	return OpenTransactionJournal(filepath.Join(JournalDir(), name))
+}
+
+func OpenTransactionJournal(path string) *TransactionJournal {
+	return &TransactionJournal{path: path}
+}
+
+func (journal *TransactionJournal) append(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(journal.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(journal.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// the latest line for each transaction that matches, oldest first
+func (journal *TransactionJournal) Entries(query JournalQuery) ([]JournalEntry, error) {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+	file, err := os.Open(journal.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []JournalEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	latest := map[string]JournalEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a line cut short by a crash
+			log.Debug().Err(err).Str("path", journal.path).Msgf("skipping unreadable journal line")
+			continue
+		}
+		latest[entry.Hash] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := []JournalEntry{}
+	for _, entry := range latest {
+		if query.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].SentAt < entries[j].SentAt
+	})
+	return entries, nil
+}
+
+// the ABI of each contract so the journal can name the method a
+// transaction called and the indexer the event a log is
+func contractABIs(addresses ContractAddresses) map[common.Address]namedABI {
+	abis := map[common.Address]namedABI{}
+	for _, contract := range []struct {
+		name     string
+		address  common.Address
+		metadata interface{ GetAbi() (*abi.ABI, error) }
+	}{
+		{"controller", addresses.Controller, controller.ControllerMetaData},
+		{"payments", addresses.Payments, payments.PaymentsMetaData},
+		{"storage", addresses.Storage, storage.StorageMetaData},
+		{"users", addresses.Users, users.UsersMetaData},
+		{"jobcreator", addresses.JobCreator, jobcreator.JobcreatorMetaData},
+		{"mediation", addresses.Mediation, mediation.MediationMetaData},
+		{"pow", addresses.Pow, pow.PowMetaData},
+		{"token", addresses.Token, token.TokenMetaData},
+	} {
+		contractABI, err := contract.metadata.GetAbi()
+		if err != nil {
+			continue
+		}
+		abis[contract.address] = namedABI{name: contract.name, abi: contractABI}
+	}
+	return abis
+}
+
+type namedABI struct {
+	name string
+	abi  *abi.ABI
+}
+
+// what the transaction called and the deal it was for when one of its
+// arguments is a deal id
+func describeTransaction(tx *types.Transaction, addresses ContractAddresses) (method string, argsHash string, dealID string) {
+	input := tx.Data()
+	if len(input) < 4 {
+		return "transfer", "", ""
+	}
+	argsHash = crypto.Keccak256Hash(input[4:]).Hex()
+	method = "0x" + hex.EncodeToString(input[:4])
+	if tx.To() == nil {
+		return method, argsHash, ""
+	}
+	contract, ok := contractABIs(addresses)[*tx.To()]
+	if !ok {
+		// the deals batched through the multicall contract
+		if multicallABI, err := multicallABI(); err == nil {
+			if called, err := multicallABI.MethodById(input[:4]); err == nil {
+				method = "multicall." + called.Name
+			}
+		}
+		return method, argsHash, ""
+	}
+	called, err := contract.abi.MethodById(input[:4])
+	if err != nil {
+		return method, argsHash, ""
+	}
+	method = contract.name + "." + called.Name
+	args, err := called.Inputs.Unpack(input[4:])
+	if err != nil {
+		return method, argsHash, ""
+	}
+	for i, input := range called.Inputs {
+		if input.Name == "dealId" {
+			if id, ok := args[i].(string); ok {
+				dealID = id
+			}
+		}
+	}
+	return method, argsHash, dealID
+}
+
+func (sdk *Web3SDK) journalSent(tx *types.Transaction) {
+	if sdk.journal == nil {
+		return
+	}
+	method, argsHash, dealID := describeTransaction(tx, sdk.Contracts().Addresses)
+	entry := JournalEntry{
+		Hash:     tx.Hash().Hex(),
+		Service:  string(sdk.Options.Service),
+		From:     sdk.GetAddress().Hex(),
+		Method:   method,
+		ArgsHash: argsHash,
+		DealID:   dealID,
+		Nonce:    tx.Nonce(),
+		Status:   TxStatusPending,
+		SentAt:   time.Now().UnixMilli(),
+	}
+	if tx.To() != nil {
+		entry.To = tx.To().Hex()
+	}
+	sdk.journalEntries.Store(entry.Hash, entry)
+	if err := sdk.journal.append(entry); err != nil {
+		log.Warn().Err(err).Str("tx", entry.Hash).Msgf("error writing transaction journal")
+	}
+}
+
+func (sdk *Web3SDK) journalDone(tx *types.Transaction, receipt *types.Receipt, waitErr error) {
+	if sdk.journal == nil {
+		return
+	}
+	stored, ok := sdk.journalEntries.LoadAndDelete(tx.Hash().Hex())
+	if !ok {
+		return
+	}
+	entry := stored.(JournalEntry)
+	switch {
+	case waitErr != nil:
+		entry.Status = TxStatusFailed
+		entry.Error = waitErr.Error()
+	case receipt.Status == types.ReceiptStatusSuccessful:
+		entry.Status = TxStatusSuccess
+	default:
+		entry.Status = TxStatusReverted
+	}
+	if receipt != nil {
+		if receipt.TxHash != tx.Hash() {
+			entry.MinedHash = receipt.TxHash.Hex()
+		}
+		entry.GasUsed = receipt.GasUsed
+		if receipt.EffectiveGasPrice != nil {
+			entry.GasPrice = receipt.EffectiveGasPrice.String()
+		}
+		if receipt.BlockNumber != nil {
+			entry.Block = receipt.BlockNumber.Uint64()
+		}
+		entry.MinedAt = time.Now().UnixMilli()
+	}
+	if err := sdk.journal.append(entry); err != nil {
+		log.Warn().Err(err).Str("tx", entry.Hash).Msgf("error writing transaction journal")
+	}
+}
+
+// what the transactions cost between them
+type JournalSummary struct {
+	Transactions int
+	Failed       int
+	GasUsed      uint64
+	GasCost      *big.Int
+}
+
+func SummarizeJournal(entries []JournalEntry) JournalSummary {
+	summary := JournalSummary{GasCost: big.NewInt(0)}
+	for _, entry := range entries {
+		summary.Transactions++
+		if entry.Status == TxStatusFailed || entry.Status == TxStatusReverted {
+			summary.Failed++
+		}
+		summary.GasUsed += entry.GasUsed
+		summary.GasCost.Add(summary.GasCost, entry.GasCost())
+	}
+	return summary
+}
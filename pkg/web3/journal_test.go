@@ -0,0 +1,68 @@
+//go:build unit
+
+package web3
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeTransaction(t *testing.T) {
+	addresses := ContractAddresses{Controller: common.HexToAddress("0x99")}
+	controllerABI, err := controller.ControllerMetaData.GetAbi()
+	require.NoError(t, err)
+	input, err := controllerABI.Pack("acceptResult", "deal-1")
+	require.NoError(t, err)
+
+	tx := types.NewTx(&types.LegacyTx{To: &addresses.Controller, Data: input})
+	method, argsHash, dealID := describeTransaction(tx, addresses)
+	assert.Equal(t, "controller.acceptResult", method)
+	assert.NotEmpty(t, argsHash)
+	assert.Equal(t, "deal-1", dealID)
+
+	// the same call to a contract we do not know only gets its selector
+	other := common.HexToAddress("0x42")
+	tx = types.NewTx(&types.LegacyTx{To: &other, Data: input})
+	method, _, dealID = describeTransaction(tx, addresses)
+	assert.Equal(t, "0x"+common.Bytes2Hex(input[:4]), method)
+	assert.Empty(t, dealID)
+}
+
+func TestTransactionJournal(t *testing.T) {
+	journal := OpenTransactionJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	entries, err := journal.Entries(JournalQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Now()
+	old := JournalEntry{Hash: "0x01", Method: "controller.agree", DealID: "deal-1", Status: TxStatusPending, SentAt: now.Add(-48 * time.Hour).UnixMilli()}
+	sent := JournalEntry{Hash: "0x02", Method: "controller.addResult", DealID: "deal-1", Status: TxStatusPending, SentAt: now.UnixMilli()}
+	mined := sent
+	mined.Status = TxStatusSuccess
+	mined.GasUsed = 21000
+	mined.GasPrice = "1000000000"
+	for _, entry := range []JournalEntry{old, sent, mined} {
+		require.NoError(t, journal.append(entry))
+	}
+
+	entries, err = journal.Entries(JournalQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []JournalEntry{old, mined}, entries)
+
+	entries, err = journal.Entries(JournalQuery{Since: now.Add(-time.Hour), DealID: "deal-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []JournalEntry{mined}, entries)
+
+	summary := SummarizeJournal([]JournalEntry{old, mined})
+	assert.Equal(t, 2, summary.Transactions)
+	assert.Equal(t, uint64(21000), summary.GasUsed)
+	assert.Equal(t, big.NewInt(21000*1000000000), summary.GasCost)
+}
@@ -0,0 +1,99 @@
+package web3
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// the first account of the first wallet, what most wallets derive by default
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// indexes from here up are hardened, written with a ' in the path
+const hardenedKeyStart = 0x80000000
+
+// reads the key out of an encrypted UTC JSON keystore file like the ones
+// geth, clef and most wallets export
+func LoadKeystore(path string, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keystore %s: %w", path, err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error unlocking keystore %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+// the key at the BIP-32 derivation path of the BIP-39 mnemonic's seed
+func DeriveMnemonicKey(mnemonic string, path string) (*ecdsa.PrivateKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("the mnemonic is not a valid BIP-39 mnemonic")
+	}
+	if path == "" {
+		path = DefaultDerivationPath
+	}
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing derivation path %s: %w", path, err)
+	}
+
+	seed := bip39.NewSeed(mnemonic, "")
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+	for _, index := range derivationPath {
+		key, chainCode, err = deriveChildKey(key, chainCode, index)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving %s: %w", path, err)
+		}
+	}
+	return crypto.ToECDSA(key)
+}
+
+func hmacSHA512(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+// the private child key derivation from BIP-32
+func deriveChildKey(key []byte, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := []byte{}
+	if index >= hardenedKeyStart {
+		data = append(data, 0)
+		data = append(data, key...)
+	} else {
+		parent, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&parent.PublicKey)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	tweak, childChainCode := hmacSHA512(chainCode, data)
+	order := crypto.S256().Params().N
+	tweakInt := new(big.Int).SetBytes(tweak)
+	if tweakInt.Cmp(order) >= 0 {
+		return nil, nil, fmt.Errorf("index %d gives an invalid key, use the next one", index)
+	}
+	child := tweakInt.Add(tweakInt, new(big.Int).SetBytes(key))
+	child.Mod(child, order)
+	if child.Sign() == 0 {
+		return nil, nil, fmt.Errorf("index %d gives an invalid key, use the next one", index)
+	}
+	return child.FillBytes(make([]byte, 32)), childChainCode, nil
+}
@@ -0,0 +1,53 @@
+//go:build unit
+
+package web3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the development mnemonic hardhat and anvil fund their accounts from
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveMnemonicKey(t *testing.T) {
+	key, err := DeriveMnemonicKey(testMnemonic, "")
+	require.NoError(t, err)
+	assert.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", GetAddress(key).Hex())
+
+	key, err = DeriveMnemonicKey(testMnemonic, "m/44'/60'/0'/0/1")
+	require.NoError(t, err)
+	assert.Equal(t, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", GetAddress(key).Hex())
+
+	_, err = DeriveMnemonicKey("test test test", "")
+	assert.Error(t, err)
+	_, err = DeriveMnemonicKey(testMnemonic, "m/not/a/path")
+	assert.Error(t, err)
+}
+
+func TestLoadKeystore(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    GetAddress(privateKey),
+		PrivateKey: privateKey,
+	}, "hunter2", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, keyJSON, 0600))
+
+	loaded, err := LoadKeystore(path, "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, GetAddress(privateKey), GetAddress(loaded))
+
+	_, err = LoadKeystore(path, "wrong")
+	assert.Error(t, err)
+}
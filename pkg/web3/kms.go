@@ -0,0 +1,321 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// the instance's service account token on GCP, used when
+// GOOGLE_OAUTH_ACCESS_TOKEN is not set
+const gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// a token is fetched again this long before it expires
+const gcpTokenMargin = time.Minute
+
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// signs with an ECC_SECG_P256K1 key in AWS KMS, the kms signs the digest
+// as it is so it is given the keccak256 hash
+type awsKMSSigner struct {
+	keyID       string
+	region      string
+	endpoint    string
+	credentials awsCredentials
+	address     common.Address
+}
+
+// the credentials come from the usual AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables
+func newAWSKMSSigner(ctx context.Context, options Web3Options) (*awsKMSSigner, error) {
+	region := options.SignerRegion
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		return nil, errors.New("WEB3_SIGNER_REGION or AWS_REGION is required for AWS KMS")
+	}
+	credentials := awsCredentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if credentials.accessKeyID == "" || credentials.secretAccessKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for AWS KMS")
+	}
+	endpoint := strings.TrimSuffix(options.SignerURL, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", region)
+	}
+	signer := &awsKMSSigner{
+		keyID:       options.SignerKeyID,
+		region:      region,
+		endpoint:    endpoint,
+		credentials: credentials,
+	}
+
+	var key struct {
+		KeySpec   string
+		PublicKey []byte
+	}
+	if err := signer.call(ctx, "GetPublicKey", map[string]string{"KeyId": signer.keyID}, &key); err != nil {
+		return nil, fmt.Errorf("error reading AWS KMS key %s: %w", signer.keyID, err)
+	}
+	if key.KeySpec != "ECC_SECG_P256K1" {
+		return nil, fmt.Errorf("AWS KMS key %s is %s, it has to be ECC_SECG_P256K1", signer.keyID, key.KeySpec)
+	}
+	publicKey, err := parseSubjectPublicKey(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signer.address = crypto.PubkeyToAddress(*publicKey)
+	return signer, nil
+}
+
+func (signer *awsKMSSigner) Address() common.Address {
+	return signer.address
+}
+
+func (signer *awsKMSSigner) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	hash := crypto.Keccak256(data)
+	var result struct {
+		Signature []byte
+	}
+	err := signer.call(ctx, "Sign", map[string]interface{}{
+		"KeyId":            signer.keyID,
+		"Message":          hash,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return recoverableSignature(hash, result.Signature, signer.address)
+}
+
+// the kms json api, the byte fields go both ways as base64
+func (signer *awsKMSSigner) call(ctx context.Context, action string, input interface{}, output interface{}) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, signer.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	signAWSRequest(req, payload, signer.credentials, signer.region, "kms", time.Now())
+	body, err := doSignerRequest(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, output)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AWS signature version 4 over every header already on the request
+func signAWSRequest(req *http.Request, payload []byte, credentials awsCredentials, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// signs with an EC_SIGN_SECP256K1_SHA256 key version in GCP Cloud KMS,
+// WEB3_SIGNER_KEY_ID is its projects/.../cryptoKeyVersions/... name
+type gcpKMSSigner struct {
+	keyVersion string
+	endpoint   string
+	address    common.Address
+
+	token        string
+	tokenExpires time.Time
+	tokenMutex   sync.Mutex
+}
+
+func newGCPKMSSigner(ctx context.Context, options Web3Options) (*gcpKMSSigner, error) {
+	endpoint := strings.TrimSuffix(options.SignerURL, "/")
+	if endpoint == "" {
+		endpoint = "https://cloudkms.googleapis.com"
+	}
+	signer := &gcpKMSSigner{
+		keyVersion: strings.TrimPrefix(options.SignerKeyID, "/"),
+		endpoint:   endpoint,
+	}
+
+	var key struct {
+		Pem       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := signer.call(ctx, http.MethodGet, "/publicKey", nil, &key); err != nil {
+		return nil, fmt.Errorf("error reading GCP KMS key %s: %w", signer.keyVersion, err)
+	}
+	if key.Algorithm != "EC_SIGN_SECP256K1_SHA256" {
+		return nil, fmt.Errorf("GCP KMS key %s is %s, it has to be EC_SIGN_SECP256K1_SHA256", signer.keyVersion, key.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(key.Pem))
+	if block == nil {
+		return nil, fmt.Errorf("GCP KMS key %s has no PEM public key", signer.keyVersion)
+	}
+	publicKey, err := parseSubjectPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer.address = crypto.PubkeyToAddress(*publicKey)
+	return signer, nil
+}
+
+func (signer *gcpKMSSigner) Address() common.Address {
+	return signer.address
+}
+
+func (signer *gcpKMSSigner) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	hash := crypto.Keccak256(data)
+	var result struct {
+		Signature []byte `json:"signature"`
+	}
+	// the digest field has to say sha256 to match the key's algorithm
+	// but the kms signs whatever 32 bytes it is given
+	input := map[string]interface{}{
+		"digest": map[string][]byte{"sha256": hash},
+	}
+	if err := signer.call(ctx, http.MethodPost, ":asymmetricSign", input, &result); err != nil {
+		return nil, err
+	}
+	return recoverableSignature(hash, result.Signature, signer.address)
+}
+
+func (signer *gcpKMSSigner) call(ctx context.Context, method string, suffix string, input interface{}, output interface{}) error {
+	token, err := signer.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	if input != nil {
+		payload, err = json.Marshal(input)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, signer.endpoint+"/v1/"+signer.keyVersion+suffix, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	body, err := doSignerRequest(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, output)
+}
+
+// GOOGLE_OAUTH_ACCESS_TOKEN when it is set, otherwise the instance's
+// service account token from the metadata server
+func (signer *gcpKMSSigner) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	signer.tokenMutex.Lock()
+	defer signer.tokenMutex.Unlock()
+	if signer.token != "" && time.Now().Add(gcpTokenMargin).Before(signer.tokenExpires) {
+		return signer.token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	body, err := doSignerRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("error getting a GCP access token, set GOOGLE_OAUTH_ACCESS_TOKEN when not on GCP: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", err
+	}
+	signer.token = token.AccessToken
+	signer.tokenExpires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return signer.token, nil
+}
@@ -0,0 +1,206 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/mediation"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Multicall3 is deployed at the same address on nearly every chain
+const DefaultMulticallAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// just aggregate3 from the Multicall3 ABI
+const multicallABIJSON = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var multicallABI = sync.OnceValues(func() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(multicallABIJSON))
+})
+
+// one contract read for BatchRead, the ABI is the binding's e.g. from
+// storage.StorageMetaData.GetAbi()
+type BatchCall struct {
+	Contract common.Address
+	ABI      *abi.ABI
+	Method   string
+	Args     []interface{}
+	// set by BatchRead to the unpacked return values, or to the error
+	// when this one call failed
+	Result []interface{}
+	Err    error
+}
+
+func (call *BatchCall) unpack(output []byte, err error) {
+	if err != nil {
+		call.Err = err
+		return
+	}
+	call.Result, call.Err = call.ABI.Unpack(call.Method, output)
+}
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type callBackend interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// the Multicall3 contract to batch through, false when there is none set
+// or it is not deployed on this chain so the calls are made one at a time
+func (sdk *Web3SDK) multicall(ctx context.Context) (common.Address, bool, error) {
+	if sdk.Options.MulticallAddress == "" {
+		return common.Address{}, false, nil
+	}
+	address := common.HexToAddress(sdk.Options.MulticallAddress)
+	sdk.multicallMutex.Lock()
+	defer sdk.multicallMutex.Unlock()
+	if !sdk.multicallChecked {
+		code, err := sdk.Client.CodeAt(ctx, address, nil)
+		if err != nil {
+			return common.Address{}, false, fmt.Errorf("error looking up multicall contract: %w", err)
+		}
+		sdk.multicallDeployed = len(code) > 0
+		sdk.multicallChecked = true
+		if !sdk.multicallDeployed {
+			log.Warn().Str("address", address.Hex()).Msgf("no multicall contract on this chain, contract reads are not batched")
+		}
+	}
+	return address, sdk.multicallDeployed, nil
+}
+
+// makes the reads with as few eth_calls as WEB3_MULTICALL_BATCH_SIZE
+// allows, a call that reverts only sets its own Err and the error returned
+// is for when the batch itself could not be read
+func (sdk *Web3SDK) BatchRead(ctx context.Context, calls []*BatchCall) error {
+	multicall, ok, err := sdk.multicall(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return batchRead(ctx, sdk.Client, nil, 0, calls)
+	}
+	return batchRead(ctx, sdk.Client, &multicall, sdk.Options.MulticallBatchSize, calls)
+}
+
+// with no multicall contract each call is its own eth_call
+func batchRead(ctx context.Context, backend callBackend, multicall *common.Address, batchSize int, calls []*BatchCall) error {
+	packed := make([][]byte, len(calls))
+	for i, call := range calls {
+		data, err := call.ABI.Pack(call.Method, call.Args...)
+		if err != nil {
+			return fmt.Errorf("error packing %s call: %w", call.Method, err)
+		}
+		packed[i] = data
+	}
+	if multicall == nil {
+		for i, call := range calls {
+			call.unpack(backend.CallContract(ctx, ethereum.CallMsg{To: &call.Contract, Data: packed[i]}, nil))
+		}
+		return nil
+	}
+
+	multicallABI, err := multicallABI()
+	if err != nil {
+		return err
+	}
+	batchSize = max(batchSize, 1)
+	for start := 0; start < len(calls); start += batchSize {
+		end := min(start+batchSize, len(calls))
+		batch := make([]multicallCall, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, multicallCall{Target: calls[i].Contract, AllowFailure: true, CallData: packed[i]})
+		}
+		data, err := multicallABI.Pack("aggregate3", batch)
+		if err != nil {
+			return err
+		}
+		output, err := backend.CallContract(ctx, ethereum.CallMsg{To: multicall, Data: data}, nil)
+		if err != nil {
+			return fmt.Errorf("error calling multicall: %w", err)
+		}
+		unpacked, err := multicallABI.Unpack("aggregate3", output)
+		if err != nil {
+			return fmt.Errorf("error reading multicall results: %w", err)
+		}
+		results := *abi.ConvertType(unpacked[0], new([]multicallResult)).(*[]multicallResult)
+		if len(results) != len(batch) {
+			return fmt.Errorf("multicall gave %d results for %d calls", len(results), len(batch))
+		}
+		for i, result := range results {
+			call := calls[start+i]
+			if !result.Success {
+				call.Err = fmt.Errorf("%s reverted", call.Method)
+				continue
+			}
+			call.unpack(result.ReturnData, nil)
+		}
+	}
+	return nil
+}
+
+// the on chain state of each deal in one batch, a deal that could not be
+// read is left out
+func (sdk *Web3SDK) GetAgreementStates(ctx context.Context, dealIDs []string) (map[string]uint8, error) {
+	storageABI, err := storage.StorageMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	storageAddress := sdk.Contracts().Addresses.Storage
+	calls := make([]*BatchCall, len(dealIDs))
+	for i, dealID := range dealIDs {
+		calls[i] = &BatchCall{Contract: storageAddress, ABI: storageABI, Method: "getAgreement", Args: []interface{}{dealID}}
+	}
+	if err := sdk.BatchRead(ctx, calls); err != nil {
+		return nil, err
+	}
+	states := map[string]uint8{}
+	for i, call := range calls {
+		if call.Err != nil {
+			log.Debug().Err(call.Err).Str("deal", dealIDs[i]).Msgf("error reading deal state")
+			continue
+		}
+		agreement := *abi.ConvertType(call.Result[0], new(storage.SharedStructsAgreement)).(*storage.SharedStructsAgreement)
+		states[dealIDs[i]] = agreement.State
+	}
+	return states, nil
+}
+
+// the deal's on chain state and mediator read together
+func (sdk *Web3SDK) GetAgreementStateAndMediator(ctx context.Context, dealID string) (uint8, common.Address, error) {
+	storageABI, err := storage.StorageMetaData.GetAbi()
+	if err != nil {
+		return 0, common.Address{}, err
+	}
+	mediationABI, err := mediation.MediationMetaData.GetAbi()
+	if err != nil {
+		return 0, common.Address{}, err
+	}
+	agreementCall := &BatchCall{Contract: sdk.Contracts().Addresses.Storage, ABI: storageABI, Method: "getAgreement", Args: []interface{}{dealID}}
+	mediatorCall := &BatchCall{Contract: sdk.Contracts().Addresses.Mediation, ABI: mediationABI, Method: "getMediator", Args: []interface{}{dealID}}
+	if err := sdk.BatchRead(ctx, []*BatchCall{agreementCall, mediatorCall}); err != nil {
+		return 0, common.Address{}, err
+	}
+	for _, call := range []*BatchCall{agreementCall, mediatorCall} {
+		if call.Err != nil {
+			return 0, common.Address{}, fmt.Errorf("error reading deal %s: %w", dealID, call.Err)
+		}
+	}
+	agreement := *abi.ConvertType(agreementCall.Result[0], new(storage.SharedStructsAgreement)).(*storage.SharedStructsAgreement)
+	return agreement.State, *abi.ConvertType(mediatorCall.Result[0], new(common.Address)).(*common.Address), nil
+}
@@ -0,0 +1,104 @@
+//go:build unit
+
+package web3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/mediation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testMulticall = common.HexToAddress(DefaultMulticallAddress)
+	testMediation = common.HexToAddress("0x0000000000000000000000000000000000000042")
+	testMediator  = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+)
+
+// answers getMediator for "ok" and reverts for anything else, either
+// directly or through aggregate3
+type fakeCallBackend struct {
+	t     *testing.T
+	abi   *abi.ABI
+	calls int
+}
+
+func (backend *fakeCallBackend) read(data []byte) ([]byte, error) {
+	args, err := backend.abi.Methods["getMediator"].Inputs.Unpack(data[4:])
+	require.NoError(backend.t, err)
+	if args[0].(string) != "ok" {
+		return nil, errors.New("execution reverted")
+	}
+	return backend.abi.Methods["getMediator"].Outputs.Pack(testMediator)
+}
+
+func (backend *fakeCallBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	backend.calls++
+	if *call.To == testMediation {
+		return backend.read(call.Data)
+	}
+	require.Equal(backend.t, testMulticall, *call.To)
+	multicallABI, err := multicallABI()
+	require.NoError(backend.t, err)
+	method := multicallABI.Methods["aggregate3"]
+	require.True(backend.t, bytes.Equal(method.ID, call.Data[:4]))
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	require.NoError(backend.t, err)
+	calls := *abi.ConvertType(args[0], new([]multicallCall)).(*[]multicallCall)
+	results := make([]multicallResult, len(calls))
+	for i, inner := range calls {
+		require.True(backend.t, inner.AllowFailure)
+		output, err := backend.read(inner.CallData)
+		results[i] = multicallResult{Success: err == nil, ReturnData: output}
+	}
+	return method.Outputs.Pack(results)
+}
+
+func mediatorCalls(t *testing.T, dealIDs ...string) []*BatchCall {
+	mediationABI, err := mediation.MediationMetaData.GetAbi()
+	require.NoError(t, err)
+	calls := make([]*BatchCall, len(dealIDs))
+	for i, dealID := range dealIDs {
+		calls[i] = &BatchCall{Contract: testMediation, ABI: mediationABI, Method: "getMediator", Args: []interface{}{dealID}}
+	}
+	return calls
+}
+
+func TestBatchRead(t *testing.T) {
+	mediationABI, err := mediation.MediationMetaData.GetAbi()
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name      string
+		multicall *common.Address
+		calls     int
+	}{
+		// five reads in batches of two
+		{name: "multicall", multicall: &testMulticall, calls: 3},
+		{name: "one at a time", calls: 5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &fakeCallBackend{t: t, abi: mediationABI}
+			calls := mediatorCalls(t, "ok", "bad", "ok", "ok", "bad")
+			require.NoError(t, batchRead(context.Background(), backend, test.multicall, 2, calls))
+			assert.Equal(t, test.calls, backend.calls)
+
+			for i, call := range calls {
+				if i == 1 || i == 4 {
+					assert.Error(t, call.Err)
+					continue
+				}
+				require.NoError(t, call.Err)
+				assert.Equal(t, testMediator, call.Result[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package web3
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// how many times a transaction is resent with a fresh nonce after the node
+// says its nonce was wrong
+const nonceRetries = 3
+
+// how many finished nonces are remembered so the deal records can still be
+// told what happened to them
+const nonceHistory = 256
+
+type nonceBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// every version of the transaction sent for one nonce, the replacements
+// for a stuck one go out with the same nonce and a higher fee
+type trackedTx struct {
+	txs  []*types.Transaction
+	opts bind.TransactOpts
+	// when the latest version was sent
+	sentAt    time.Time
+	abandoned bool
+}
+
+func (tracked *trackedTx) latest() *types.Transaction {
+	return tracked.txs[len(tracked.txs)-1]
+}
+
+func (tracked *trackedTx) has(hash common.Hash) bool {
+	return slices.ContainsFunc(tracked.txs, func(tx *types.Transaction) bool { return tx.Hash() == hash })
+}
+
+// the bindings ask the node for the pending nonce on every transaction so
+// two sent at once from the same key get the same one and the second fails,
+// this hands them out one at a time and keeps count itself
+type NonceManager struct {
+	backend nonceBackend
+	address common.Address
+	options Web3Options
+	// the nonce the next transaction gets, only read from the node when
+	// there is none yet or after the node said ours was wrong
+	next   uint64
+	synced bool
+	// the transactions sent that have not been mined yet, by nonce
+	inFlight map[uint64]*trackedTx
+	// the ones that were mined or given up on
+	finished map[uint64]*trackedTx
+	mutex    sync.Mutex
+}
+
+func NewNonceManager(backend nonceBackend, address common.Address, options Web3Options) *NonceManager {
+	return &NonceManager{
+		backend:  backend,
+		address:  address,
+		options:  options,
+		inFlight: map[uint64]*trackedTx{},
+		finished: map[uint64]*trackedTx{},
+	}
+}
+
+// true for the errors a node gives for a nonce that is used or skips ahead
+func isNonceError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "nonce too low") ||
+		strings.Contains(message, "nonce too high") ||
+		strings.Contains(message, "replacement transaction underpriced")
+}
+
+// called with the lock held
+func (manager *NonceManager) sync(ctx context.Context) error {
+	nonce, err := manager.backend.PendingNonceAt(ctx, manager.address)
+	if err != nil {
+		return err
+	}
+	if manager.synced && nonce != manager.next {
+		log.Warn().Uint64("was", manager.next).Uint64("now", nonce).Msgf("resynced nonce from the node")
+	}
+	// whatever is at or past the node's count never made it into the pool
+	for inFlight := range manager.inFlight {
+		if inFlight >= nonce {
+			delete(manager.inFlight, inFlight)
+		}
+	}
+	manager.next = nonce
+	manager.synced = true
+	return nil
+}
+
+// send builds and sends the transaction with the opts it is given, the
+// nonce on them is the only one in use until send returns
+func (manager *NonceManager) Send(
+	ctx context.Context,
+	opts *bind.TransactOpts,
+	send func(opts *bind.TransactOpts) (*types.Transaction, error),
+) (*types.Transaction, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	for attempt := 0; ; attempt++ {
+		if !manager.synced {
+			if err := manager.sync(ctx); err != nil {
+				return nil, fmt.Errorf("error reading nonce: %w", err)
+			}
+		}
+		nonceOpts := *opts
+		nonceOpts.Nonce = new(big.Int).SetUint64(manager.next)
+		if nonceOpts.Context == nil {
+			nonceOpts.Context = ctx
+		}
+		tx, err := send(&nonceOpts)
+		if err == nil {
+			// replacements are signed outside of any call so they get no context
+			nonceOpts.Context = nil
+			manager.inFlight[tx.Nonce()] = &trackedTx{
+				txs:    []*types.Transaction{tx},
+				opts:   nonceOpts,
+				sentAt: time.Now(),
+			}
+			manager.next = tx.Nonce() + 1
+			return tx, nil
+		}
+		if !isNonceError(err) || attempt >= nonceRetries {
+			return nil, err
+		}
+		log.Debug().Err(err).Uint64("nonce", manager.next).Msgf("nonce rejected, resyncing and resending")
+		manager.synced = false
+	}
+}
+
+// called with the lock held
+func (manager *NonceManager) finish(nonce uint64) {
+	tracked, ok := manager.inFlight[nonce]
+	if !ok {
+		return
+	}
+	delete(manager.inFlight, nonce)
+	manager.finished[nonce] = tracked
+	for finished := range manager.finished {
+		if finished+nonceHistory < nonce {
+			delete(manager.finished, finished)
+		}
+	}
+}
+
+// called with the lock held
+func (manager *NonceManager) lookup(tx *types.Transaction) *trackedTx {
+	for _, tracked := range []*trackedTx{manager.inFlight[tx.Nonce()], manager.finished[tx.Nonce()]} {
+		if tracked != nil && tracked.has(tx.Hash()) {
+			return tracked
+		}
+	}
+	return nil
+}
+
+// a mined transaction is not in flight any more
+func (manager *NonceManager) Confirm(tx *types.Transaction) {
+	if manager == nil {
+		return
+	}
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	if inFlight, ok := manager.inFlight[tx.Nonce()]; ok && inFlight.has(tx.Hash()) {
+		manager.finish(tx.Nonce())
+	}
+}
+
+// every version sent of the transaction, any one of them can be mined
+func (manager *NonceManager) versions(tx *types.Transaction) ([]*types.Transaction, bool) {
+	if manager == nil {
+		return []*types.Transaction{tx}, false
+	}
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	tracked := manager.lookup(tx)
+	if tracked == nil {
+		return []*types.Transaction{tx}, false
+	}
+	return slices.Clone(tracked.txs), tracked.abandoned
+}
+
+// the transactions waiting to be mined, lowest nonce first
+func (manager *NonceManager) InFlight() []*types.Transaction {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	txs := []*types.Transaction{}
+	for _, tracked := range manager.inFlight {
+		txs = append(txs, tracked.latest())
+	}
+	slices.SortFunc(txs, func(a, b *types.Transaction) int {
+		return cmp.Compare(a.Nonce(), b.Nonce())
+	})
+	return txs
+}
@@ -0,0 +1,109 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNonceBackend struct {
+	pending uint64
+	calls   int
+}
+
+func (backend *testNonceBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	backend.calls++
+	return backend.pending, nil
+}
+
+func (backend *testNonceBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return nil
+}
+
+func (backend *testNonceBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func sendNonce(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64()}), nil
+}
+
+func TestNonceManagerConcurrentSends(t *testing.T) {
+	backend := &testNonceBackend{pending: 5}
+	manager := NewNonceManager(backend, common.Address{}, Web3Options{})
+
+	var wg sync.WaitGroup
+	nonces := make(chan uint64, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := manager.Send(context.Background(), &bind.TransactOpts{}, sendNonce)
+			if assert.NoError(t, err) {
+				nonces <- tx.Nonce()
+			}
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := map[uint64]bool{}
+	for nonce := range nonces {
+		assert.False(t, seen[nonce], "nonce %d was handed out twice", nonce)
+		seen[nonce] = true
+	}
+	assert.Len(t, seen, 20)
+	assert.Equal(t, 1, backend.calls)
+	assert.Len(t, manager.InFlight(), 20)
+
+	manager.Confirm(manager.InFlight()[0])
+	assert.Len(t, manager.InFlight(), 19)
+}
+
+func TestNonceManagerRecovers(t *testing.T) {
+	backend := &testNonceBackend{pending: 1}
+	manager := NewNonceManager(backend, common.Address{}, Web3Options{})
+	_, err := manager.Send(context.Background(), &bind.TransactOpts{}, sendNonce)
+	require.NoError(t, err)
+
+	// something else sent from the same key
+	backend.pending = 4
+	tx, err := manager.Send(context.Background(), &bind.TransactOpts{}, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if opts.Nonce.Uint64() < backend.pending {
+			return nil, errors.New("nonce too low: next nonce 4, tx nonce 2")
+		}
+		return sendNonce(opts)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), tx.Nonce())
+
+	// a dropped transaction leaves a gap behind it
+	backend.pending = 3
+	tx, err = manager.Send(context.Background(), &bind.TransactOpts{}, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if opts.Nonce.Uint64() > backend.pending {
+			return nil, errors.New("nonce too high")
+		}
+		return sendNonce(opts)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), tx.Nonce())
+
+	// other errors do not use up the nonce
+	_, err = manager.Send(context.Background(), &bind.TransactOpts{}, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return nil, errors.New("execution reverted")
+	})
+	require.Error(t, err)
+	tx, err = manager.Send(context.Background(), &bind.TransactOpts{}, sendNonce)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), tx.Nonce())
+}
@@ -0,0 +1,238 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
+	"github.com/rs/zerolog/log"
+)
+
+// nonces, DOMAIN_SEPARATOR and permit from EIP-2612 and eip712Domain from
+// EIP-5267, which tokens with a non standard domain use to say what it is
+const permitABIJSON = `[
+{"inputs":[{"internalType":"address","name":"owner","type":"address"}],"name":"nonces","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"eip712Domain","outputs":[{"internalType":"bytes1","name":"fields","type":"bytes1"},{"internalType":"string","name":"name","type":"string"},{"internalType":"string","name":"version","type":"string"},{"internalType":"uint256","name":"chainId","type":"uint256"},{"internalType":"address","name":"verifyingContract","type":"address"},{"internalType":"bytes32","name":"salt","type":"bytes32"},{"internalType":"uint256[]","name":"extensions","type":"uint256[]"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"uint256","name":"deadline","type":"uint256"},{"internalType":"uint8","name":"v","type":"uint8"},{"internalType":"bytes32","name":"r","type":"bytes32"},{"internalType":"bytes32","name":"s","type":"bytes32"}],"name":"permit","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+var permitABI = sync.OnceValues(func() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(permitABIJSON))
+})
+
+// the permit is sent straight away, the deadline only has to outlast a
+// transaction that is stuck and resent a few times
+const permitDeadline = 30 * time.Minute
+
+// eip712Domain says which domain fields a token uses, name, version,
+// chainId and verifyingContract is the only set we sign for
+const permitDomainFields = 0x0f
+
+var permitTypes = apitypes.Types{
+	"EIP712Domain": typedDataDomainType,
+	"Permit": {
+		{Name: "owner", Type: "address"},
+		{Name: "spender", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+}
+
+func permitTypedData(domain apitypes.TypedDataDomain, owner common.Address, spender common.Address, value *big.Int, nonce *big.Int, deadline *big.Int) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types:       permitTypes,
+		PrimaryType: "Permit",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  spender.Hex(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": deadline.String(),
+		},
+	}
+}
+
+// what eip712Domain returned, nil when the token does not have it
+type eip712DomainResult struct {
+	Fields            [1]byte
+	Name              string
+	Version           string
+	ChainId           *big.Int
+	VerifyingContract common.Address
+}
+
+// the domain the token's permits are signed for, the one it gives from
+// eip712Domain or else the usual version 1 domain with its name, nil when
+// neither hashes to its DOMAIN_SEPARATOR as we would sign permits it
+// rejects
+func matchPermitDomain(paymentToken PaymentToken, chainID int, reported *eip712DomainResult, separator []byte) *apitypes.TypedDataDomain {
+	candidates := []apitypes.TypedDataDomain{}
+	if reported != nil && reported.Fields[0] == permitDomainFields {
+		candidates = append(candidates, apitypes.TypedDataDomain{
+			Name:              reported.Name,
+			Version:           reported.Version,
+			ChainId:           (*math.HexOrDecimal256)(reported.ChainId),
+			VerifyingContract: reported.VerifyingContract.Hex(),
+		})
+	}
+	candidates = append(candidates, apitypes.TypedDataDomain{
+		Name:              paymentToken.Name,
+		Version:           "1",
+		ChainId:           math.NewHexOrDecimal256(int64(chainID)),
+		VerifyingContract: paymentToken.Address.Hex(),
+	})
+	for _, domain := range candidates {
+		typedData := apitypes.TypedData{Types: permitTypes, Domain: domain}
+		hash, err := typedData.HashStruct("EIP712Domain", domain.Map())
+		if err == nil && bytes.Equal(hash, separator) {
+			return &domain
+		}
+	}
+	return nil
+}
+
+// the token's permit domain, nil when it does not support permits, this
+// does not change so it is only worked out once for each token
+func (sdk *Web3SDK) permitDomain(ctx context.Context, paymentToken PaymentToken) (*apitypes.TypedDataDomain, error) {
+	sdk.permitDomainsMutex.Lock()
+	defer sdk.permitDomainsMutex.Unlock()
+	if domain, ok := sdk.permitDomains[paymentToken.Address]; ok {
+		return domain, nil
+	}
+
+	permitABI, err := permitABI()
+	if err != nil {
+		return nil, err
+	}
+	calls := []*BatchCall{
+		{Contract: paymentToken.Address, ABI: &permitABI, Method: "DOMAIN_SEPARATOR"},
+		{Contract: paymentToken.Address, ABI: &permitABI, Method: "eip712Domain"},
+	}
+	if err := sdk.BatchRead(ctx, calls); err != nil {
+		return nil, err
+	}
+	var domain *apitypes.TypedDataDomain
+	// a token without DOMAIN_SEPARATOR has no permit
+	if calls[0].Err == nil {
+		separator := *abi.ConvertType(calls[0].Result[0], new([32]byte)).(*[32]byte)
+		var reported *eip712DomainResult
+		if calls[1].Err == nil {
+			reported = &eip712DomainResult{
+				Fields:            *abi.ConvertType(calls[1].Result[0], new([1]byte)).(*[1]byte),
+				Name:              *abi.ConvertType(calls[1].Result[1], new(string)).(*string),
+				Version:           *abi.ConvertType(calls[1].Result[2], new(string)).(*string),
+				ChainId:           *abi.ConvertType(calls[1].Result[3], new(*big.Int)).(**big.Int),
+				VerifyingContract: *abi.ConvertType(calls[1].Result[4], new(common.Address)).(*common.Address),
+			}
+		}
+		domain = matchPermitDomain(paymentToken, sdk.Options.ChainID, reported, separator[:])
+		if domain == nil {
+			log.Warn().Str("token", paymentToken.Address.Hex()).Msgf("%s has a DOMAIN_SEPARATOR we cannot sign permits for, approving it instead", paymentToken.Symbol)
+		}
+	}
+	if sdk.permitDomains == nil {
+		sdk.permitDomains = map[common.Address]*apitypes.TypedDataDomain{}
+	}
+	sdk.permitDomains[paymentToken.Address] = domain
+	return domain, nil
+}
+
+// the token's permit call allowing the spender amount, signed by our
+// wallet, nil when the token does not support permits
+func (sdk *Web3SDK) signPermit(ctx context.Context, tokenAddress common.Address, spender common.Address, amount *big.Int) (*multicallCall, error) {
+	paymentToken, err := sdk.GetPaymentToken(ctx, tokenAddress.Hex())
+	if err != nil {
+		return nil, err
+	}
+	domain, err := sdk.permitDomain(ctx, paymentToken)
+	if err != nil || domain == nil {
+		return nil, err
+	}
+
+	permitABI, err := permitABI()
+	if err != nil {
+		return nil, err
+	}
+	owner := sdk.GetAddress()
+	nonceCall := &BatchCall{Contract: tokenAddress, ABI: &permitABI, Method: "nonces", Args: []interface{}{owner}}
+	if err := sdk.BatchRead(ctx, []*BatchCall{nonceCall}); err != nil {
+		return nil, err
+	}
+	if nonceCall.Err != nil {
+		return nil, fmt.Errorf("error reading permit nonce for %s: %w", tokenAddress.Hex(), nonceCall.Err)
+	}
+	nonce := *abi.ConvertType(nonceCall.Result[0], new(*big.Int)).(**big.Int)
+	deadline := big.NewInt(time.Now().Add(permitDeadline).Unix())
+
+	signature, err := SignTypedData(ctx, sdk.Signer, permitTypedData(*domain, owner, spender, amount, nonce, deadline))
+	if err != nil {
+		return nil, fmt.Errorf("error signing permit for %s: %w", tokenAddress.Hex(), err)
+	}
+	input, err := packPermit(owner, spender, amount, deadline, signature)
+	if err != nil {
+		return nil, err
+	}
+	return &multicallCall{Target: tokenAddress, AllowFailure: true, CallData: input}, nil
+}
+
+// permit takes the signature as v, r and s with v as 27 or 28
+func packPermit(owner common.Address, spender common.Address, amount *big.Int, deadline *big.Int, signature []byte) ([]byte, error) {
+	if len(signature) != 65 { //nolint:gomnd
+		return nil, fmt.Errorf("permit signature is %d bytes rather than 65", len(signature))
+	}
+	var r, s [32]byte
+	copy(r[:], signature[:32])
+	copy(s[:], signature[32:64])
+	v := signature[64]
+	if v < 27 { //nolint:gomnd
+		v += 27 //nolint:gomnd
+	}
+	permitABI, err := permitABI()
+	if err != nil {
+		return nil, err
+	}
+	return permitABI.Pack("permit", owner, spender, amount, deadline, v, r, s)
+}
+
+// sends the permit and the controller call in one multicall transaction so
+// the allowance never needs a transaction of its own, the pair is run as an
+// eth_call first so a revert comes back as the RevertError of whichever
+// call would have failed
+func (sdk *Web3SDK) sendWithPermit(ctx context.Context, multicall common.Address, permit multicallCall, method string, args ...interface{}) (string, error) {
+	controllerABI, err := controller.ControllerMetaData.GetAbi()
+	if err != nil {
+		return "", err
+	}
+	input, err := controllerABI.Pack(method, args...)
+	if err != nil {
+		return "", fmt.Errorf("error packing controller.%s: %w", method, err)
+	}
+	calls := []multicallCall{
+		permit,
+		{Target: sdk.Contracts().Addresses.Controller, AllowFailure: true, CallData: input},
+	}
+	names := []string{"token.permit", "controller." + method}
+	simulated, err := simulateBatch(ctx, sdk.Client, sdk.GetAddress(), multicall, calls)
+	if err != nil {
+		return "", err
+	}
+	for i, result := range simulated {
+		if !result.Success {
+			return "", decodeRevert(names[i], result.ReturnData)
+		}
+		calls[i].AllowFailure = false
+	}
+	return sdk.sendMulticall(ctx, multicall, calls)
+}
@@ -0,0 +1,77 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func domainSeparator(t *testing.T, domain apitypes.TypedDataDomain) []byte {
+	typedData := apitypes.TypedData{Types: permitTypes, Domain: domain}
+	hash, err := typedData.HashStruct("EIP712Domain", domain.Map())
+	require.NoError(t, err)
+	return hash
+}
+
+func TestMatchPermitDomain(t *testing.T) {
+	paymentToken := PaymentToken{Address: common.HexToAddress("0xa513E6E4b8f2a923D98304ec87F64353C4D5C853"), Name: "USD Coin"}
+	versionOne := apitypes.TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "1",
+		ChainId:           math.NewHexOrDecimal256(1337),
+		VerifyingContract: paymentToken.Address.Hex(),
+	}
+	domain := matchPermitDomain(paymentToken, 1337, nil, domainSeparator(t, versionOne))
+	require.NotNil(t, domain)
+	assert.Equal(t, "1", domain.Version)
+
+	// the version eip712Domain reports is used over the usual one
+	versionTwo := versionOne
+	versionTwo.Version = "2"
+	reported := &eip712DomainResult{Fields: [1]byte{permitDomainFields}, Name: "USD Coin", Version: "2", ChainId: big.NewInt(1337), VerifyingContract: paymentToken.Address}
+	domain = matchPermitDomain(paymentToken, 1337, reported, domainSeparator(t, versionTwo))
+	require.NotNil(t, domain)
+	assert.Equal(t, "2", domain.Version)
+
+	// without eip712Domain there is no telling what version 2 is
+	assert.Nil(t, matchPermitDomain(paymentToken, 1337, nil, domainSeparator(t, versionTwo)))
+}
+
+func TestPackPermit(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(privateKey.PublicKey)
+	spender := common.HexToAddress("0x2279B7A0a67DB372996a5FaB50D91eAA73d2eBe6")
+	domain := apitypes.TypedDataDomain{Name: "USD Coin", Version: "1", ChainId: math.NewHexOrDecimal256(1337), VerifyingContract: "0xa513E6E4b8f2a923D98304ec87F64353C4D5C853"}
+	typedData := permitTypedData(domain, owner, spender, big.NewInt(500), big.NewInt(0), big.NewInt(1700000000))
+	signature, err := SignTypedData(context.Background(), NewKeySigner(privateKey), typedData)
+	require.NoError(t, err)
+
+	input, err := packPermit(owner, spender, big.NewInt(500), big.NewInt(1700000000), signature)
+	require.NoError(t, err)
+	permitABI, err := permitABI()
+	require.NoError(t, err)
+	args, err := permitABI.Methods["permit"].Inputs.Unpack(input[4:])
+	require.NoError(t, err)
+	v := args[4].(uint8)
+	r := args[5].([32]byte)
+	s := args[6].([32]byte)
+	assert.Contains(t, []uint8{27, 28}, v)
+
+	// the token recovers the owner from v, r and s
+	address, err := RecoverTypedData(typedData, append(append(r[:], s[:]...), v))
+	require.NoError(t, err)
+	assert.Equal(t, owner, address)
+
+	_, err = packPermit(owner, spender, big.NewInt(500), big.NewInt(1700000000), signature[:64])
+	assert.Error(t, err)
+}
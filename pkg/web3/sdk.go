@@ -3,19 +3,15 @@ package web3
 import (
 	"context"
 	"crypto/ecdsa"
-	"errors"
-	"fmt"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"math/big"
-	"net/url"
-	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/controller"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/jobcreator"
 	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/mediation"
@@ -30,9 +26,6 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-// LP token ABI
-const erc20ABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256","internalType": "uint256"}],"type":"function"}]`
-
 // these are the go-binding wrappers for the various deployed contracts
 type Contracts struct {
 	Token      *token.Token
@@ -43,50 +36,111 @@ type Contracts struct {
 	Mediation  *mediation.Mediation
 	Controller *controller.Controller
 	Pow        *pow.Pow
+	// where each contract is, including the ones loaded from the controller
+	Addresses ContractAddresses
+}
+
+type ContractAddresses struct {
+	Token      common.Address
+	Payments   common.Address
+	Storage    common.Address
+	Users      common.Address
+	JobCreator common.Address
+	Mediation  common.Address
+	Controller common.Address
+	Pow        common.Address
 }
 
 type Web3SDK struct {
-	Options      Web3Options
-	PrivateKey   *ecdsa.PrivateKey
-	Client       *ethclient.Client
+	Options Web3Options
+	// nil when the wallet does not hold the key in memory here
+	PrivateKey *ecdsa.PrivateKey
+	// signs the transactions and the typed data
+	Wallet Wallet
+	// the wallet again, for the clients that only sign their requests
+	Signer       Signer
+	Client       *FailoverClient
 	CallOpts     *bind.CallOpts
 	TransactOpts *bind.TransactOpts
-	Contracts    *Contracts
+	// the bindings, swapped for new ones when the controller links a
+	// contract at another address
+	contracts        *Contracts
+	contractsChanged chan struct{}
+	contractsMutex   sync.RWMutex
+	// the backend the bindings are made with
+	backend bind.ContractBackend
+	// where the addresses loaded from the controller are kept between runs
+	addressBook        *addressBook
+	contractChangeSubs []func(ContractChange)
+	// every transaction sent, nil when WEB3_TX_JOURNAL_ENABLED is off
+	journal *TransactionJournal
+	// the sent entries WaitTx fills in, by tx hash
+	journalEntries sync.Map
+	// hands out nonces so transactions sent at once do not collide
+	Nonces *NonceManager
+	// tracks fees so transactions that can wait are held back during spikes
+	GasOracle *GasOracle
+	// warns when we are running out of gas or collateral
+	Balances *BalanceWatcher
+	// the gas used counters, nil until RegisterMetrics is called
+	gasCounters atomic.Pointer[gasMetrics]
+	// where the event listeners remember how far they have read
+	checkpoints *eventCheckpoints
+	// whether WEB3_MULTICALL_ADDRESS has a contract, looked up on first use
+	multicallChecked  bool
+	multicallDeployed bool
+	multicallMutex    sync.Mutex
+	// the metadata of the tokens deals are paid in
+	paymentTokens      map[common.Address]PaymentToken
+	paymentTokensMutex sync.Mutex
+	// the EIP-712 domain of each payment token's permits, nil for the
+	// tokens without permits
+	permitDomains      map[common.Address]*apitypes.TypedDataDomain
+	permitDomainsMutex sync.Mutex
 }
 
 func NewContracts(
 	options Web3Options,
-	client *ethclient.Client,
+	client bind.ContractBackend,
 	callOpts *bind.CallOpts,
 ) (*Contracts, error) {
-	controller, err := controller.NewController(common.HexToAddress(options.ControllerAddress), client)
+	addresses, err := resolveContractAddresses(options, client, callOpts)
 	if err != nil {
 		return nil, err
 	}
+	return bindContracts(addresses, client)
+}
+
+// the addresses set in the options, the rest are loaded from the controller
+func resolveContractAddresses(
+	options Web3Options,
+	client bind.ContractBackend,
+	callOpts *bind.CallOpts,
+) (ContractAddresses, error) {
+	controller, err := controller.NewController(common.HexToAddress(options.ControllerAddress), client)
+	if err != nil {
+		return ContractAddresses{}, err
+	}
 
 	paymentsAddress := options.PaymentsAddress
 	log.Debug().Msgf("paymentsAddress: %s", paymentsAddress)
 	if paymentsAddress == "" {
 		loadedPaymentsAddress, err := controller.GetPaymentsAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		paymentsAddress = loadedPaymentsAddress.String()
 		log.Debug().
 			Str("load payments address", paymentsAddress).
 			Msgf("")
 	}
-	payments, err := payments.NewPayments(common.HexToAddress(paymentsAddress), client)
-	if err != nil {
-		return nil, err
-	}
 
 	powAddress := options.PowAddress
 	log.Debug().Msgf("PowAddress: %s", powAddress)
 	if powAddress == "" {
 		loadedPowAddress, err := controller.GetPowAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		powAddress = loadedPowAddress.String()
 		log.Debug().
@@ -94,17 +148,16 @@ func NewContracts(
 			Msgf("")
 	}
 
-	pow, err := pow.NewPow(common.HexToAddress(powAddress), client)
-	if err != nil {
-		return nil, err
-	}
-
 	tokenAddress := options.TokenAddress
 	log.Debug().Msgf("TokenAddress: %s", tokenAddress)
 	if tokenAddress == "" {
+		payments, err := payments.NewPayments(common.HexToAddress(paymentsAddress), client)
+		if err != nil {
+			return ContractAddresses{}, err
+		}
 		loadedTokenAddress, err := payments.GetTokenAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		tokenAddress = loadedTokenAddress.String()
 		log.Debug().
@@ -112,17 +165,12 @@ func NewContracts(
 			Msgf("")
 	}
 
-	token, err := token.NewToken(common.HexToAddress(tokenAddress), client)
-	if err != nil {
-		return nil, err
-	}
-
 	storageAddress := options.StorageAddress
 	log.Debug().Msgf("StorageAddress: %s", storageAddress)
 	if storageAddress == "" {
 		loadedStorageAddress, err := controller.GetStorageAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		storageAddress = loadedStorageAddress.String()
 		log.Debug().
@@ -130,17 +178,12 @@ func NewContracts(
 			Msgf("")
 	}
 
-	storage, err := storage.NewStorage(common.HexToAddress(storageAddress), client)
-	if err != nil {
-		return nil, err
-	}
-
 	usersAddress := options.UsersAddress
 	log.Debug().Msgf("UsersAddress: %s", usersAddress)
 	if usersAddress == "" {
 		loadedUsersAddress, err := controller.GetUsersAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		usersAddress = loadedUsersAddress.String()
 		log.Debug().
@@ -148,17 +191,12 @@ func NewContracts(
 			Msgf("")
 	}
 
-	users, err := users.NewUsers(common.HexToAddress(usersAddress), client)
-	if err != nil {
-		return nil, err
-	}
-
 	jobcreatorAddress := options.JobCreatorAddress
 	log.Debug().Msgf("JobCreatorAddress: %s", jobcreatorAddress)
 	if jobcreatorAddress == "" {
 		loadedJobCreatorAddress, err := controller.GetJobCreatorAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		jobcreatorAddress = loadedJobCreatorAddress.String()
 		log.Debug().
@@ -166,17 +204,12 @@ func NewContracts(
 			Msgf("")
 	}
 
-	jobCreator, err := jobcreator.NewJobcreator(common.HexToAddress(jobcreatorAddress), client)
-	if err != nil {
-		return nil, err
-	}
-
 	mediationAddress := options.MediationAddress
 	log.Debug().Msgf("MediationAddress: %s", mediationAddress)
 	if mediationAddress == "" {
 		loadedMediationAddress, err := controller.GetMediationAddress(callOpts)
 		if err != nil {
-			return nil, err
+			return ContractAddresses{}, err
 		}
 		mediationAddress = loadedMediationAddress.String()
 		log.Debug().
@@ -184,11 +217,51 @@ func NewContracts(
 			Msgf("")
 	}
 
-	mediation, err := mediation.NewMediation(common.HexToAddress(mediationAddress), client)
+	return ContractAddresses{
+		Token:      common.HexToAddress(tokenAddress),
+		Payments:   common.HexToAddress(paymentsAddress),
+		Storage:    common.HexToAddress(storageAddress),
+		Users:      common.HexToAddress(usersAddress),
+		JobCreator: common.HexToAddress(jobcreatorAddress),
+		Mediation:  common.HexToAddress(mediationAddress),
+		Controller: common.HexToAddress(options.ControllerAddress),
+		Pow:        common.HexToAddress(powAddress),
+	}, nil
+}
+
+func bindContracts(addresses ContractAddresses, client bind.ContractBackend) (*Contracts, error) {
+	controller, err := controller.NewController(addresses.Controller, client)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := payments.NewPayments(addresses.Payments, client)
+	if err != nil {
+		return nil, err
+	}
+	pow, err := pow.NewPow(addresses.Pow, client)
+	if err != nil {
+		return nil, err
+	}
+	token, err := token.NewToken(addresses.Token, client)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := storage.NewStorage(addresses.Storage, client)
+	if err != nil {
+		return nil, err
+	}
+	users, err := users.NewUsers(addresses.Users, client)
+	if err != nil {
+		return nil, err
+	}
+	jobCreator, err := jobcreator.NewJobcreator(addresses.JobCreator, client)
+	if err != nil {
+		return nil, err
+	}
+	mediation, err := mediation.NewMediation(addresses.Mediation, client)
 	if err != nil {
 		return nil, err
 	}
-
 	return &Contracts{
 		Token:      token,
 		Payments:   payments,
@@ -198,10 +271,20 @@ func NewContracts(
 		Mediation:  mediation,
 		Controller: controller,
 		Pow:        pow,
+		Addresses:  addresses,
 	}, nil
 }
 
 func NewContractSDK(ctx context.Context, options Web3Options, tracer trace.Tracer) (*Web3SDK, error) {
+	wallet, err := NewWallet(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewContractSDKWithWallet(ctx, options, wallet, tracer)
+}
+
+// the sdk with a wallet made somewhere other than from WEB3_SIGNER
+func NewContractSDKWithWallet(ctx context.Context, options Web3Options, wallet Wallet, tracer trace.Tracer) (*Web3SDK, error) {
 	displayOpts := options
 	displayOpts.PrivateKey = "*********"
 	log.Debug().Msgf("NewContractSDK: %+v", displayOpts)
@@ -210,11 +293,7 @@ func NewContractSDK(ctx context.Context, options Web3Options, tracer trace.Trace
 	if err != nil {
 		return nil, err
 	}
-
-	privateKey, err := ParsePrivateKey(options.PrivateKey)
-	if err != nil {
-		return nil, err
-	}
+	go client.checkHealth(ctx, time.Duration(options.RpcHealthCheckInterval)*time.Second)
 
 	callOpts := &bind.CallOpts{
 		Pending:     false,
@@ -223,81 +302,101 @@ func NewContractSDK(ctx context.Context, options Web3Options, tracer trace.Trace
 		Context:     nil,
 	}
 
-	transactOpts, err := bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(int64(options.ChainID)))
-	if err != nil {
-		return nil, err
-	}
-	contracts, err := NewContracts(options, client, callOpts)
+	transactOpts := NewWalletTransactor(wallet, big.NewInt(int64(options.ChainID)))
+	applyFeeOptions(transactOpts, options)
+	backend := newFeeBackend(client, options)
+	book := newAddressBook(options)
+	contracts, err := loadContracts(options, book, backend, callOpts)
 	if err != nil {
 		return nil, err
 	}
+	gasOracle := NewGasOracle(backend, options)
+	go gasOracle.Run(ctx)
 
 	web3SDK := &Web3SDK{
-		PrivateKey:   privateKey,
-		Options:      options,
-		Client:       client,
-		CallOpts:     callOpts,
-		TransactOpts: transactOpts,
-		Contracts:    contracts,
+		PrivateKey:       walletPrivateKey(wallet),
+		Wallet:           wallet,
+		Signer:           wallet,
+		Options:          options,
+		Client:           client,
+		CallOpts:         callOpts,
+		TransactOpts:     transactOpts,
+		contracts:        contracts,
+		contractsChanged: make(chan struct{}),
+		backend:          backend,
+		addressBook:      book,
+		GasOracle:        gasOracle,
 	}
+	go web3SDK.runContractRefresh(ctx)
+	web3SDK.Nonces = NewNonceManager(client, web3SDK.GetAddress(), options)
+	go web3SDK.Nonces.Run(ctx)
+	web3SDK.checkpoints = newEventCheckpoints(options, web3SDK.GetAddress().Hex())
+	if options.TxJournal {
+		web3SDK.journal = NewTransactionJournal(options, web3SDK.GetAddress().Hex())
+	}
+	web3SDK.Balances = newBalanceWatcher(web3SDK)
+	go web3SDK.Balances.Run(ctx)
 	log.Info().Msgf("Public Address: %s", web3SDK.GetAddress())
 
 	return web3SDK, nil
 }
 
-func getEthClient(ctx context.Context, options Web3Options, tracer trace.Tracer) (*ethclient.Client, error) {
+func getEthClient(ctx context.Context, options Web3Options, tracer trace.Tracer) (*FailoverClient, error) {
 	ctx, span := tracer.Start(ctx, "get_ethclient", trace.WithAttributes(attribute.Int("web3.chain_id", options.ChainID)))
 	defer span.End()
 
-	rpcs := strings.Split(options.RpcURL, ",")
-	var err error
-	var parsedURL *url.URL
-	var client *ethclient.Client
-	for _, u := range rpcs {
-		parsedURL, err = url.Parse(u)
-		if err != nil {
-			log.Warn().Msgf("Unable to parse web3 RPC URL: %v", err)
-			span.RecordError(errors.New("Unable to parse web3 RPC URL"))
-			continue
-		}
-
-		span.AddEvent("ethclient.dial", trace.WithAttributes(attribute.String("web3.rpc_url", parsedURL.Host)))
-		client, err = ethclient.Dial(u)
-		if err != nil {
-			log.Warn().Msgf("Failed to connect to %s: %v", parsedURL.Host, err)
-			span.RecordError(fmt.Errorf("Failed to connect to %s", parsedURL.Host))
-			continue
-		} else {
-			log.Info().Msgf("Connected to %s", parsedURL.Host)
-			span.AddEvent("ethclient.connected")
-			break
-		}
-	}
-	if client == nil {
+	client, err := newFailoverClient(ctx, options.RpcURLList(), options, span)
+	if err != nil {
 		span.SetStatus(codes.Error, "Failed to connect with web3 RPC URL")
-		return nil, errors.New("Failed to connect to a web3 RPC provider")
+		return nil, err
 	}
-
 	return client, nil
 }
 
 func (sdk *Web3SDK) getBlockNumber() (uint64, error) {
-	var blockNumberHex string
-	err := sdk.Client.Client().Call(&blockNumberHex, "eth_blockNumber")
+	blockNumber, err := sdk.Client.BlockNumber(context.Background())
 	if err != nil {
 		log.Error().Msgf("error for getBlockNumber: %s", err.Error())
 		return 0, err
 	}
-	blockNumberHex = strings.TrimPrefix(blockNumberHex, "0x")
-	return strconv.ParseUint(blockNumberHex, 16, 64)
+	return blockNumber, nil
 }
 
+// errors when the rpc node cannot be reached
+func (sdk *Web3SDK) Ping(ctx context.Context) error {
+	_, err := sdk.Client.BlockNumber(ctx)
+	return err
+}
+
+// sends a transaction built with the transact opts using the next nonce,
+// everything that sends from the sdk's key should go through this
+func (sdk *Web3SDK) Transact(
+	ctx context.Context,
+	send func(opts *bind.TransactOpts) (*types.Transaction, error),
+) (*types.Transaction, error) {
+	tx, err := sdk.Nonces.Send(ctx, sdk.TransactOpts, send)
+	if err != nil {
+		return nil, err
+	}
+	sdk.journalSent(tx)
+	return tx, nil
+}
+
+// the receipt is for whichever version of the transaction was mined so its
+// hash is not the one sent when a stuck transaction was replaced
 func (sdk *Web3SDK) WaitTx(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
-	return bind.WaitMined(ctx, sdk.Client, tx)
+	receipt, err := sdk.waitMined(ctx, tx)
+	sdk.journalDone(tx, receipt, err)
+	sdk.recordGasUsed(tx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	sdk.Nonces.Confirm(tx)
+	return receipt, nil
 }
 
 func (sdk *Web3SDK) GetAddress() common.Address {
-	return crypto.PubkeyToAddress(GetPublicKey(sdk.PrivateKey))
+	return sdk.Signer.Address()
 }
 
 func (sdk *Web3SDK) GetBalance(address string) (*big.Int, error) {
@@ -314,31 +413,5 @@ func (sdk *Web3SDK) GetBalance(address string) (*big.Int, error) {
 }
 
 func (sdk *Web3SDK) GetLPBalance(address string) (*big.Int, error) {
-	// Convert the string address to common.Address
-	client := sdk.Client
-	ethAddress := common.HexToAddress(address)
-	lpToken := common.HexToAddress(sdk.Options.TokenAddress) // LP Token Address
-
-	// Get the balance using the converted address
-	erc20ABIObj, err := abi.JSON(strings.NewReader(erc20ABI))
-	if err != nil {
-		log.Error().Msgf("error parsing ABI: %s", err)
-		return nil, err
-	}
-	tokenContract := bind.NewBoundContract(lpToken, erc20ABIObj, client, client, client)
-
-	var out []interface{}
-	err = tokenContract.Call(nil, &out, "balanceOf", ethAddress)
-	if err != nil {
-		log.Error().Msgf("error calling balanceOf: %s", err)
-		return nil, err
-	}
-
-	lpBalance := *abi.ConvertType(out[0], new(big.Int)).(*big.Int)
-
-	if err != nil {
-		log.Error().Msgf("error for GetBalance: %s", err.Error())
-		return nil, err
-	}
-	return &lpBalance, nil
+	return sdk.GetTokenBalance(sdk.Contracts().Addresses.Token, address)
 }
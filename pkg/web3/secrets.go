@@ -0,0 +1,84 @@
+package web3
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// the key a resource provider decrypts job secrets with, it is its own key
+// rather than the wallet's as the wallet can be a remote signer, it is
+// made the first time it is asked for
+func LoadSecretsKey(path string) (*ecdsa.PrivateKey, error) {
+	key, err := crypto.LoadECDSA(path)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error loading secrets key %s: %w", path, err)
+	}
+	key, err = crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil { //nolint:gomnd
+		return nil, err
+	}
+	// SaveECDSA writes the file as 0600
+	if err := crypto.SaveECDSA(path, key); err != nil {
+		return nil, fmt.Errorf("error saving secrets key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// what goes in the resource offer for job creators to encrypt to
+func SecretsPublicKey(key *ecdsa.PrivateKey) string {
+	return hexutil.Encode(crypto.CompressPubkey(&key.PublicKey))
+}
+
+// the secrets encrypted to the resource provider's secrets key, the deal
+// id is mixed into the key derivation so they only decrypt for that deal
+func EncryptSecrets(publicKey string, dealID string, secrets map[string]string) (string, error) {
+	compressed, err := hexutil.Decode(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("error decoding secrets key %s: %w", publicKey, err)
+	}
+	key, err := crypto.DecompressPubkey(compressed)
+	if err != nil {
+		return "", fmt.Errorf("error decoding secrets key %s: %w", publicKey, err)
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(key), plaintext, []byte(dealID), nil)
+	if err != nil {
+		return "", fmt.Errorf("error encrypting secrets: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func DecryptSecrets(key *ecdsa.PrivateKey, dealID string, encrypted string) (map[string]string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding secrets: %w", err)
+	}
+	plaintext, err := ecies.ImportECDSA(key).Decrypt(ciphertext, []byte(dealID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting secrets, they were not encrypted to our secrets key for this deal: %w", err)
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("error reading decrypted secrets: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package web3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSecretsKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "secrets.key")
+	key, err := LoadSecretsKey(path)
+	assert.NoError(t, err)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	again, err := LoadSecretsKey(path)
+	assert.NoError(t, err)
+	assert.Equal(t, SecretsPublicKey(key), SecretsPublicKey(again))
+}
+
+func TestEncryptSecrets(t *testing.T) {
+	key, err := LoadSecretsKey(filepath.Join(t.TempDir(), "secrets.key"))
+	assert.NoError(t, err)
+	secrets := map[string]string{"HF_TOKEN": "hf_abc", "OPENAI_API_KEY": "sk-123"}
+
+	encrypted, err := EncryptSecrets(SecretsPublicKey(key), "deal-1", secrets)
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "hf_abc")
+
+	decrypted, err := DecryptSecrets(key, "deal-1", encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, secrets, decrypted)
+
+	_, err = DecryptSecrets(key, "deal-2", encrypted)
+	assert.Error(t, err)
+
+	other, err := LoadSecretsKey(filepath.Join(t.TempDir(), "other.key"))
+	assert.NoError(t, err)
+	_, err = DecryptSecrets(other, "deal-1", encrypted)
+	assert.Error(t, err)
+
+	_, err = EncryptSecrets("0x1234", "deal-1", secrets)
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// signs with the private key from WEB3_PRIVATE_KEY, a keystore or a mnemonic
+	SignerKey = "key"
+	// the key is held by a Web3Signer server
+	SignerWeb3Signer = "web3signer"
+	// the key is an ECC_SECG_P256K1 key in AWS KMS
+	SignerAWSKMS = "aws-kms"
+	// the key is an EC_SIGN_SECP256K1_SHA256 key in GCP Cloud KMS
+	SignerGCPKMS = "gcp-kms"
+)
+
+// how long a call to a remote signer can take
+const remoteSignerTimeout = 30 * time.Second
+
+// signs for one address, either with a key held here or by asking a remote
+// service that holds it so the key never has to be on this host
+type Signer interface {
+	Address() common.Address
+	// signs the keccak256 hash of the data, the signature is [R || S || V]
+	// with V as 0 or 1 the same as crypto.Sign gives
+	Sign(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// the signer WEB3_SIGNER picks, the remote ones are asked for their public
+// key here so a bad key id is found at startup
+func NewSigner(ctx context.Context, options Web3Options) (Signer, error) {
+	switch options.Signer {
+	case SignerWeb3Signer:
+		return newWeb3Signer(ctx, options)
+	case SignerAWSKMS:
+		return newAWSKMSSigner(ctx, options)
+	case SignerGCPKMS:
+		return newGCPKMSSigner(ctx, options)
+	default:
+		privateKey, err := ParsePrivateKey(options.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySigner(privateKey), nil
+	}
+}
+
+type keySigner struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+func NewKeySigner(privateKey *ecdsa.PrivateKey) Signer {
+	return &keySigner{privateKey: privateKey}
+}
+
+func (signer *keySigner) Address() common.Address {
+	return GetAddress(signer.privateKey)
+}
+
+func (signer *keySigner) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	return SignMessage(signer.privateKey, data)
+}
+
+// transact opts that sign with the signer, the bindings only hand over the
+// transaction so the remote signers are given the payload its hash is of
+func NewSignerTransactor(signer Signer, chainID *big.Int) *bind.TransactOpts {
+	return NewWalletTransactor(NewSignerWallet(signer), chainID)
+}
+
+// what the signer hashes to get the hash a transaction is signed over,
+// only the types the sdk sends are handled
+func signingPayload(txSigner types.Signer, tx *types.Transaction) ([]byte, error) {
+	var payload []byte
+	var err error
+	switch tx.Type() {
+	case types.LegacyTxType:
+		payload, err = rlp.EncodeToBytes([]interface{}{
+			tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(),
+			txSigner.ChainID(), uint(0), uint(0),
+		})
+	case types.DynamicFeeTxType:
+		payload, err = rlp.EncodeToBytes([]interface{}{
+			txSigner.ChainID(), tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(),
+			tx.To(), tx.Value(), tx.Data(), tx.AccessList(),
+		})
+		payload = append([]byte{types.DynamicFeeTxType}, payload...)
+	default:
+		return nil, fmt.Errorf("cannot sign transactions of type %d", tx.Type())
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the signature would be for some other transaction if these differ
+	if crypto.Keccak256Hash(payload) != txSigner.Hash(tx) {
+		return nil, errors.New("the transaction payload does not match its signing hash")
+	}
+	return payload, nil
+}
+
+// the kms services give a DER signature without a recovery id, ethereum
+// only takes the low s form and needs the id that recovers the address
+func recoverableSignature(hash []byte, der []byte, address common.Address) ([]byte, error) {
+	var parsed struct {
+		R *big.Int
+		S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &parsed); err != nil {
+		return nil, fmt.Errorf("error parsing signature: %w", err)
+	}
+	order := crypto.S256().Params().N
+	if parsed.S.Cmp(new(big.Int).Rsh(order, 1)) > 0 {
+		parsed.S = new(big.Int).Sub(order, parsed.S)
+	}
+	signature := make([]byte, crypto.SignatureLength)
+	parsed.R.FillBytes(signature[:32])
+	parsed.S.FillBytes(signature[32:64])
+	for v := byte(0); v < 2; v++ {
+		signature[crypto.RecoveryIDOffset] = v
+		publicKey, err := crypto.SigToPub(hash, signature)
+		if err == nil && crypto.PubkeyToAddress(*publicKey) == address {
+			return signature, nil
+		}
+	}
+	return nil, fmt.Errorf("the signature is not from %s", address.Hex())
+}
+
+// the key from a DER SubjectPublicKeyInfo, the x509 package has no secp256k1
+func parseSubjectPublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("error parsing public key: %w", err)
+	}
+	return crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+}
+
+var remoteSignerClient = &http.Client{Timeout: remoteSignerTimeout}
+
+// sends the request and returns the body, any error status is an error
+// with whatever the service said about it
+func doSignerRequest(req *http.Request) ([]byte, error) {
+	resp, err := remoteSignerClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL.Redacted(), resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,177 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerTransactor(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := big.NewInt(1337)
+	opts := NewSignerTransactor(NewKeySigner(privateKey), chainID)
+	keyed, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(1)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(20), Gas: 50000, Data: []byte{1, 2, 3}}),
+	}
+	for _, tx := range txs {
+		signed, err := opts.Signer(opts.From, tx)
+		require.NoError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+		require.NoError(t, err)
+		assert.Equal(t, GetAddress(privateKey), sender)
+
+		// the key signs deterministically so both ways give the same transaction
+		expected, err := keyed.Signer(keyed.From, tx)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Hash(), signed.Hash())
+	}
+
+	_, err = opts.Signer(to, txs[0])
+	assert.ErrorIs(t, err, bind.ErrNotAuthorized)
+}
+
+// a kms style DER signature with the high s value ethereum does not accept
+func derSignature(t *testing.T, signature []byte) []byte {
+	s := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(signature[32:64]))
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(signature[:32]), s})
+	require.NoError(t, err)
+	return der
+}
+
+func subjectPublicKey(t *testing.T, privateKey []byte) []byte {
+	key, err := crypto.ToECDSA(privateKey)
+	require.NoError(t, err)
+	der, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}},
+		PublicKey: asn1.BitString{Bytes: crypto.FromECDSAPub(&key.PublicKey), BitLength: 65 * 8},
+	})
+	require.NoError(t, err)
+	return der
+}
+
+func TestRecoverableSignature(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hash := crypto.Keccak256([]byte("hello"))
+	expected, err := crypto.Sign(hash, privateKey)
+	require.NoError(t, err)
+
+	signature, err := recoverableSignature(hash, derSignature(t, expected), GetAddress(privateKey))
+	require.NoError(t, err)
+	assert.Equal(t, expected, signature)
+
+	_, err = recoverableSignature(hash, derSignature(t, expected), common.Address{})
+	assert.Error(t, err)
+}
+
+// the get-vanilla case from the AWS signature version 4 test suite
+func TestSignAWSRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	credentials := awsCredentials{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	signAWSRequest(req, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"),
+	)
+}
+
+func TestWeb3Signer(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	publicKey := hexutil.Encode(crypto.FromECDSAPub(&privateKey.PublicKey)[1:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/eth1/publicKeys":
+			json.NewEncoder(w).Encode([]string{publicKey})
+		case "/api/v1/eth1/sign/" + publicKey:
+			var body struct{ Data string }
+			json.NewDecoder(r.Body).Decode(&body)
+			signature, _ := SignMessage(privateKey, hexutil.MustDecode(body.Data))
+			signature[crypto.RecoveryIDOffset] += 27
+			w.Write([]byte(hexutil.Encode(signature)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	signer, err := NewSigner(context.Background(), Web3Options{Signer: SignerWeb3Signer, SignerURL: server.URL})
+	require.NoError(t, err)
+	assert.Equal(t, GetAddress(privateKey), signer.Address())
+	signature, err := signer.Sign(context.Background(), []byte("hello"))
+	require.NoError(t, err)
+	address, err := GetAddressFromSignedMessage([]byte("hello"), signature)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), address)
+
+	_, err = NewSigner(context.Background(), Web3Options{Signer: SignerWeb3Signer, SignerURL: server.URL, SignerKeyID: "0x0000000000000000000000000000000000000001"})
+	assert.Error(t, err)
+}
+
+func TestAWSKMSSigner(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=AKIDEXAMPLE/")
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"KeySpec":   "ECC_SECG_P256K1",
+				"PublicKey": subjectPublicKey(t, crypto.FromECDSA(privateKey)),
+			})
+		case "TrentService.Sign":
+			var body struct{ Message []byte }
+			json.NewDecoder(r.Body).Decode(&body)
+			signature, _ := crypto.Sign(body.Message, privateKey)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Signature": derSignature(t, signature)})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	signer, err := NewSigner(context.Background(), Web3Options{
+		Signer:       SignerAWSKMS,
+		SignerURL:    server.URL,
+		SignerKeyID:  "alias/lilypad",
+		SignerRegion: "us-east-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, GetAddress(privateKey), signer.Address())
+	signature, err := signer.Sign(context.Background(), []byte("hello"))
+	require.NoError(t, err)
+	expected, err := SignMessage(privateKey, []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, signature)
+}
@@ -0,0 +1,96 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+// a transaction the eth_call run before sending it said would revert, no
+// gas was spent on it
+type RevertError struct {
+	// e.g. controller.agree
+	Method string
+	// the require message or the panic, empty when the contract gave none
+	Reason string
+	// the revert data as the node returned it
+	Data []byte
+}
+
+func (err *RevertError) Error() string {
+	if err.Reason == "" {
+		return fmt.Sprintf("%s would revert", err.Method)
+	}
+	return fmt.Sprintf("%s would revert: %s", err.Method, err.Reason)
+}
+
+// runs the method as an eth_call from our address against the latest block,
+// a revert comes back as a *RevertError and any other error is only logged
+// as the transaction will find out for itself if the node is down
+func (sdk *Web3SDK) simulate(ctx context.Context, contractName string, method string, args ...interface{}) error {
+	if !sdk.Options.SimulateTransactions {
+		return nil
+	}
+	for address, contract := range contractABIs(sdk.Contracts().Addresses) {
+		if contract.name != contractName {
+			continue
+		}
+		input, err := contract.abi.Pack(method, args...)
+		if err != nil {
+			return fmt.Errorf("error packing %s.%s: %w", contractName, method, err)
+		}
+		_, err = sdk.Client.CallContract(ctx, ethereum.CallMsg{
+			From: sdk.GetAddress(),
+			To:   &address,
+			Data: input,
+		}, nil)
+		if revert := asRevert(contractName+"."+method, err); revert != nil {
+			return revert
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("error simulating %s.%s, sending it anyway", contractName, method)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown contract %s", contractName)
+}
+
+// nil unless err is the node saying the call reverted
+func asRevert(method string, err error) *RevertError {
+	if err == nil {
+		return nil
+	}
+	var dataError rpc.DataError
+	if errors.As(err, &dataError) {
+		if encoded, ok := dataError.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(encoded); decodeErr == nil {
+				return decodeRevert(method, data)
+			}
+		}
+	}
+	// some nodes only put the reason in the message
+	message := err.Error()
+	if index := strings.Index(message, "execution reverted"); index >= 0 {
+		reason := strings.TrimPrefix(message[index+len("execution reverted"):], ":")
+		return &RevertError{Method: method, Reason: strings.TrimSpace(reason)}
+	}
+	return nil
+}
+
+func decodeRevert(method string, data []byte) *RevertError {
+	revert := &RevertError{Method: method, Data: common.CopyBytes(data)}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		revert.Reason = reason
+	} else if len(data) > 0 {
+		revert.Reason = "0x" + common.Bytes2Hex(data)
+	}
+	return revert
+}
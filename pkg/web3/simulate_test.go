@@ -0,0 +1,50 @@
+//go:build unit
+
+package web3
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDataError struct {
+	data string
+}
+
+func (err fakeDataError) Error() string          { return "execution reverted" }
+func (err fakeDataError) ErrorData() interface{} { return err.data }
+
+func TestAsRevert(t *testing.T) {
+	assert.Nil(t, asRevert("controller.agree", nil))
+	assert.Nil(t, asRevert("controller.agree", errors.New("connection refused")))
+
+	// Error(string) as a require with a message returns it
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("DealAgreed")
+	require.NoError(t, err)
+	data := append([]byte{0x08, 0xc3, 0x79, 0xa0}, reason...)
+	revert := asRevert("controller.addResult", fakeDataError{data: hexutil.Encode(data)})
+	require.NotNil(t, revert)
+	assert.Equal(t, "DealAgreed", revert.Reason)
+	assert.Equal(t, data, revert.Data)
+	assert.Equal(t, "controller.addResult would revert: DealAgreed", revert.Error())
+
+	// a wrapped error is still found
+	revert = asRevert("controller.acceptResult", errors.Join(errors.New("eth_call"), fakeDataError{data: "0x"}))
+	require.NotNil(t, revert)
+	assert.Equal(t, "controller.acceptResult would revert", revert.Error())
+
+	// nodes that leave the data out
+	revert = asRevert("controller.agree", errors.New("execution reverted: Only RP / JC"))
+	require.NotNil(t, revert)
+	assert.Equal(t, "Only RP / JC", revert.Reason)
+
+	var typed *RevertError
+	assert.True(t, errors.As(error(revert), &typed))
+}
@@ -0,0 +1,243 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/rs/zerolog/log"
+)
+
+// nodes drop a replacement that does not raise the fees by at least this
+const minFeeBump = 10
+
+// how often pending transactions are checked for being stuck
+const stuckCheckInterval = 5 * time.Second
+
+// a transaction that was still pending with no room left to raise its fee,
+// it can still be mined but nothing is waiting on it any more
+type AbandonedTransactionError struct {
+	Hash common.Hash
+}
+
+func (err *AbandonedTransactionError) Error() string {
+	return fmt.Sprintf("gave up on stuck transaction %s", err.Hash.Hex())
+}
+
+// resends the transactions that have been pending for longer than
+// WEB3_TX_REPLACE_TIMEOUT with the same nonce and higher fees
+func (manager *NonceManager) Run(ctx context.Context) {
+	if manager.options.TxReplaceTimeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(stuckCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			manager.replaceStuck(ctx)
+		}
+	}
+}
+
+func (manager *NonceManager) replaceStuck(ctx context.Context) {
+	timeout := time.Duration(manager.options.TxReplaceTimeout) * time.Second
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	for nonce, tracked := range manager.inFlight {
+		if tracked.abandoned || time.Since(tracked.sentAt) < timeout {
+			continue
+		}
+		if manager.mined(ctx, tracked) {
+			manager.finish(nonce)
+			continue
+		}
+		if err := manager.replace(ctx, tracked); err != nil {
+			log.Warn().Err(err).Uint64("nonce", nonce).Str("tx", tracked.latest().Hash().Hex()).Msgf("error replacing stuck transaction")
+		}
+	}
+}
+
+// called with the lock held
+func (manager *NonceManager) mined(ctx context.Context, tracked *trackedTx) bool {
+	for _, tx := range tracked.txs {
+		if _, err := manager.backend.TransactionReceipt(ctx, tx.Hash()); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// called with the lock held
+func (manager *NonceManager) replace(ctx context.Context, tracked *trackedTx) error {
+	latest := tracked.latest()
+	bumped, ok := bumpFees(latest, manager.options)
+	if !ok || len(tracked.txs) > manager.options.TxMaxReplacements {
+		tracked.abandoned = true
+		log.Warn().Uint64("nonce", latest.Nonce()).Str("tx", latest.Hash().Hex()).Msgf("transaction is stuck and its fee cannot go any higher, giving up on it")
+		return nil
+	}
+	signed, err := tracked.opts.Signer(tracked.opts.From, bumped)
+	if err != nil {
+		return err
+	}
+	err = manager.backend.SendTransaction(ctx, signed)
+	if err != nil {
+		if isNonceError(err) {
+			// one of the versions already went in, the next check finds its receipt
+			return nil
+		}
+		return err
+	}
+	tracked.txs = append(tracked.txs, signed)
+	tracked.sentAt = time.Now()
+	log.Info().Uint64("nonce", latest.Nonce()).Str("replaced", latest.Hash().Hex()).Str("tx", signed.Hash().Hex()).Msgf("replaced stuck transaction")
+	return nil
+}
+
+// x plus WEB3_TX_FEE_BUMP percent
+func bump(x *big.Int, percent int) *big.Int {
+	bumped := new(big.Int).Mul(x, big.NewInt(int64(100+percent)))
+	bumped.Div(bumped, big.NewInt(100))
+	// a fee of a few wei would not go up at all
+	if bumped.Cmp(x) <= 0 {
+		bumped.Add(x, big.NewInt(1))
+	}
+	return bumped
+}
+
+// a copy of the transaction with its fees raised enough for the node to
+// replace it, false when that would go over the role's fee cap
+func bumpFees(tx *types.Transaction, options Web3Options) (*types.Transaction, bool) {
+	percent := max(options.TxFeeBump, minFeeBump)
+	var limit *big.Int
+	if feeCap := options.feeCap(); feeCap > 0 {
+		limit = new(big.Int).SetUint64(feeCap)
+	}
+	// the fee has to go up by at least the minimum even when capped
+	capped := func(fee *big.Int) (*big.Int, bool) {
+		bumped := bump(fee, percent)
+		if limit != nil && bumped.Cmp(limit) > 0 {
+			bumped = new(big.Int).Set(limit)
+		}
+		return bumped, bumped.Cmp(bump(fee, minFeeBump)) >= 0
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		gasPrice, ok := capped(tx.GasPrice())
+		if !ok {
+			return nil, false
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), true
+	case types.DynamicFeeTxType:
+		feeCap, ok := capped(tx.GasFeeCap())
+		if !ok {
+			return nil, false
+		}
+		tipCap, ok := capped(tx.GasTipCap())
+		if !ok || tipCap.Cmp(feeCap) > 0 {
+			return nil, false
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tipCap,
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), true
+	default:
+		return nil, false
+	}
+}
+
+// waits for whichever version of the transaction gets mined
+func (sdk *Web3SDK) waitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		versions, abandoned := sdk.Nonces.versions(tx)
+		for _, version := range versions {
+			receipt, err := sdk.Client.TransactionReceipt(ctx, version.Hash())
+			if err == nil {
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				log.Trace().Err(err).Str("tx", version.Hash().Hex()).Msgf("error reading receipt")
+			}
+		}
+		if abandoned {
+			return nil, &AbandonedTransactionError{Hash: versions[len(versions)-1].Hash()}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// what happened to the versions of a deal transaction that did not get
+// mined, for the deal records on the solver. the hash is the one the call
+// returned, or the one it gave up on when err is an AbandonedTransactionError
+func (sdk *Web3SDK) StuckTransactions(action string, txHash string, err error) []data.DealTransactionStatus {
+	var abandoned *AbandonedTransactionError
+	if errors.As(err, &abandoned) {
+		txHash = abandoned.Hash.Hex()
+	}
+	if txHash == "" || sdk.Nonces == nil {
+		return nil
+	}
+	hash := common.HexToHash(txHash)
+	sdk.Nonces.mutex.Lock()
+	defer sdk.Nonces.mutex.Unlock()
+	var tracked *trackedTx
+	for _, candidates := range []map[uint64]*trackedTx{sdk.Nonces.inFlight, sdk.Nonces.finished} {
+		for _, candidate := range candidates {
+			if candidate.has(hash) {
+				tracked = candidate
+			}
+		}
+	}
+	if tracked == nil {
+		return nil
+	}
+	statuses := []data.DealTransactionStatus{}
+	for i, tx := range tracked.txs {
+		if tx.Hash() == hash {
+			break
+		}
+		statuses = append(statuses, data.DealTransactionStatus{
+			Action:     action,
+			Hash:       tx.Hash().Hex(),
+			Status:     data.DealTransactionReplaced,
+			ReplacedBy: tracked.txs[i+1].Hash().Hex(),
+		})
+	}
+	if abandoned != nil {
+		statuses = append(statuses, data.DealTransactionStatus{
+			Action: action,
+			Hash:   abandoned.Hash.Hex(),
+			Status: data.DealTransactionAbandoned,
+		})
+	}
+	return statuses
+}
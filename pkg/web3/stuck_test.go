@@ -0,0 +1,126 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remembers what was sent and mines whatever is in mined
+type testStuckBackend struct {
+	sent  []*types.Transaction
+	mined map[common.Hash]bool
+}
+
+func (backend *testStuckBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (backend *testStuckBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	backend.sent = append(backend.sent, tx)
+	return nil
+}
+
+func (backend *testStuckBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if backend.mined[txHash] {
+		return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func dynamicFeeTx(tip int64, feeCap int64) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(feeCap),
+		Gas:       21000,
+	})
+}
+
+func TestBumpFees(t *testing.T) {
+	bumped, ok := bumpFees(dynamicFeeTx(10, 100), Web3Options{TxFeeBump: 20})
+	require.True(t, ok)
+	assert.Equal(t, big.NewInt(12), bumped.GasTipCap())
+	assert.Equal(t, big.NewInt(120), bumped.GasFeeCap())
+
+	// never less than the bump nodes accept
+	bumped, ok = bumpFees(types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100)}), Web3Options{TxFeeBump: 1})
+	require.True(t, ok)
+	assert.Equal(t, big.NewInt(110), bumped.GasPrice())
+
+	// capped but still enough of a bump
+	bumped, ok = bumpFees(dynamicFeeTx(10, 100), Web3Options{TxFeeBump: 50, MaxFeePerGas: 115})
+	require.True(t, ok)
+	assert.Equal(t, big.NewInt(115), bumped.GasFeeCap())
+
+	_, ok = bumpFees(dynamicFeeTx(10, 100), Web3Options{
+		TxFeeBump: 20,
+		GasCaps:   map[string]int64{string(system.SolverService): 105},
+		Service:   system.SolverService,
+	})
+	assert.False(t, ok)
+}
+
+func TestReplaceStuck(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1))
+	require.NoError(t, err)
+
+	backend := &testStuckBackend{mined: map[common.Hash]bool{}}
+	manager := NewNonceManager(backend, opts.From, Web3Options{TxReplaceTimeout: 60, TxFeeBump: 20, TxMaxReplacements: 1})
+	original, err := manager.Send(context.Background(), opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return opts.Signer(opts.From, dynamicFeeTx(10, 100))
+	})
+	require.NoError(t, err)
+	sdk := &Web3SDK{Nonces: manager}
+
+	// not stuck for long enough yet
+	manager.replaceStuck(context.Background())
+	assert.Empty(t, backend.sent)
+
+	manager.inFlight[original.Nonce()].sentAt = time.Now().Add(-time.Minute)
+	manager.replaceStuck(context.Background())
+	require.Len(t, backend.sent, 1)
+	replacement := backend.sent[0]
+	assert.Equal(t, original.Nonce(), replacement.Nonce())
+	assert.Equal(t, big.NewInt(120), replacement.GasFeeCap())
+
+	// out of replacements
+	manager.inFlight[original.Nonce()].sentAt = time.Now().Add(-time.Minute)
+	manager.replaceStuck(context.Background())
+	assert.Len(t, backend.sent, 1)
+	versions, abandoned := manager.versions(original)
+	assert.True(t, abandoned)
+	assert.Len(t, versions, 2)
+
+	abandonedErr := &AbandonedTransactionError{Hash: replacement.Hash()}
+	assert.Equal(t, []data.DealTransactionStatus{
+		{Action: "agree", Hash: original.Hash().Hex(), Status: data.DealTransactionReplaced, ReplacedBy: replacement.Hash().Hex()},
+		{Action: "agree", Hash: replacement.Hash().Hex(), Status: data.DealTransactionAbandoned},
+	}, sdk.StuckTransactions("agree", "", abandonedErr))
+
+	// the replacement went in after all
+	backend.mined[replacement.Hash()] = true
+	manager.inFlight[original.Nonce()].abandoned = false
+	manager.replaceStuck(context.Background())
+	assert.Empty(t, manager.InFlight())
+	assert.Equal(t, []data.DealTransactionStatus{
+		{Action: "agree", Hash: original.Hash().Hex(), Status: data.DealTransactionReplaced, ReplacedBy: replacement.Hash().Hex()},
+	}, sdk.StuckTransactions("agree", replacement.Hash().Hex(), nil))
+	assert.Empty(t, sdk.StuckTransactions("agree", "", errors.New("execution reverted")))
+}
@@ -0,0 +1,161 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/lilypad-tech/lilypad/pkg/system"
+	"github.com/lilypad-tech/lilypad/pkg/web3/bindings/token"
+	"github.com/rs/zerolog/log"
+)
+
+// an ERC-20 token deals can be paid in
+type PaymentToken struct {
+	Address  common.Address
+	Name     string
+	Symbol   string
+	Decimals uint8
+}
+
+// the token at the address with its metadata read from the chain, an empty
+// address is the network's token, the metadata does not change so it is
+// only read once for each token
+func (sdk *Web3SDK) GetPaymentToken(ctx context.Context, address string) (PaymentToken, error) {
+	tokenAddress := sdk.Contracts().Addresses.Token
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return PaymentToken{}, fmt.Errorf("payment token %s is not an address", address)
+		}
+		tokenAddress = common.HexToAddress(address)
+	}
+
+	sdk.paymentTokensMutex.Lock()
+	defer sdk.paymentTokensMutex.Unlock()
+	if paymentToken, ok := sdk.paymentTokens[tokenAddress]; ok {
+		return paymentToken, nil
+	}
+
+	tokenABI, err := token.TokenMetaData.GetAbi()
+	if err != nil {
+		return PaymentToken{}, err
+	}
+	calls := []*BatchCall{
+		{Contract: tokenAddress, ABI: tokenABI, Method: "name"},
+		{Contract: tokenAddress, ABI: tokenABI, Method: "symbol"},
+		{Contract: tokenAddress, ABI: tokenABI, Method: "decimals"},
+	}
+	if err := sdk.BatchRead(ctx, calls); err != nil {
+		return PaymentToken{}, err
+	}
+	for _, call := range calls {
+		if call.Err != nil {
+			return PaymentToken{}, fmt.Errorf("error reading payment token %s: %w", tokenAddress.Hex(), call.Err)
+		}
+	}
+	paymentToken := PaymentToken{
+		Address:  tokenAddress,
+		Name:     *abi.ConvertType(calls[0].Result[0], new(string)).(*string),
+		Symbol:   *abi.ConvertType(calls[1].Result[0], new(string)).(*string),
+		Decimals: *abi.ConvertType(calls[2].Result[0], new(uint8)).(*uint8),
+	}
+	if sdk.paymentTokens == nil {
+		sdk.paymentTokens = map[common.Address]PaymentToken{}
+	}
+	sdk.paymentTokens[tokenAddress] = paymentToken
+	return paymentToken, nil
+}
+
+// the payments contract escrows in the one token it was deployed with so
+// that is the only token a deal can settle in for now
+func (sdk *Web3SDK) SettlesIn(address string) bool {
+	return address == "" || data.SamePaymentToken(address, sdk.Contracts().Addresses.Token.Hex())
+}
+
+func (sdk *Web3SDK) GetTokenBalance(tokenAddress common.Address, address string) (*big.Int, error) {
+	tokenContract, err := token.NewToken(tokenAddress, sdk.Client)
+	if err != nil {
+		return nil, err
+	}
+	return tokenContract.BalanceOf(sdk.CallOpts, common.HexToAddress(address))
+}
+
+// approves the payments contract to take the amount of the token from us
+// when it has not been allowed to already, the network's token moves
+// escrow itself so it never needs this
+func (sdk *Web3SDK) EnsureAllowance(ctx context.Context, tokenAddress common.Address, amount *big.Int) error {
+	_, err := sdk.ensureAllowance(ctx, tokenAddress, amount, false)
+	return err
+}
+
+// like EnsureAllowance but when bundle is set and the token supports
+// EIP-2612 a signed permit is returned to send along with the spend rather
+// than an approve transaction being sent and waited for, nothing is
+// returned when the allowance is already enough or it was approved
+func (sdk *Web3SDK) ensureAllowance(ctx context.Context, tokenAddress common.Address, amount *big.Int, bundle bool) (*multicallCall, error) {
+	spender := sdk.Contracts().Addresses.Payments
+	tokenContract, err := token.NewToken(tokenAddress, sdk.Client)
+	if err != nil {
+		return nil, err
+	}
+	allowance, err := tokenContract.Allowance(sdk.CallOpts, sdk.GetAddress(), spender)
+	if err != nil {
+		return nil, fmt.Errorf("error reading allowance for %s: %w", tokenAddress.Hex(), err)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil, nil
+	}
+	if bundle {
+		permit, err := sdk.signPermit(ctx, tokenAddress, spender, amount)
+		if err != nil {
+			log.Warn().Err(err).Str("token", tokenAddress.Hex()).Msgf("error signing permit, approving instead")
+		} else if permit != nil {
+			return permit, nil
+		}
+	}
+	tx, err := sdk.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return tokenContract.Approve(opts, spender, amount)
+	})
+	if err != nil {
+		system.Error(sdk.Options.Service, "error submitting token.Approve() tx", err)
+		return nil, err
+	}
+	_, err = sdk.WaitTx(ctx, tx)
+	return nil, err
+}
+
+// the multicall contract to bundle permits through, permits are only
+// signed when there is one
+func (sdk *Web3SDK) permitMulticall(ctx context.Context) (common.Address, bool) {
+	if !sdk.Options.UsePermit {
+		return common.Address{}, false
+	}
+	multicall, ok, err := sdk.multicall(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error looking up multicall contract, approving rather than sending permits")
+		return common.Address{}, false
+	}
+	return multicall, ok
+}
+
+// what agreeing escrows from us, the resource provider puts up the submit
+// results collateral and the job creator the payment and judge results
+// collateral
+func agreeCollateral(deal data.Deal, address common.Address, decimals uint8) *big.Int {
+	switch {
+	case common.HexToAddress(deal.Members.ResourceProvider) == address:
+		return data.TokenUnits(deal.Timeouts.SubmitResults.Collateral, decimals)
+	case common.HexToAddress(deal.Members.JobCreator) == address:
+		return new(big.Int).Add(
+			data.TokenUnits(deal.Pricing.PaymentCollateral, decimals),
+			data.TokenUnits(deal.Timeouts.JudgeResults.Collateral, decimals),
+		)
+	default:
+		return big.NewInt(0)
+	}
+}
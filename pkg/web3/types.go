@@ -2,6 +2,7 @@ package web3
 
 import (
 	"context"
+	"strings"
 
 	"github.com/lilypad-tech/lilypad/pkg/system"
 )
@@ -9,10 +10,108 @@ import (
 type Web3Options struct {
 
 	// core settings
+	// a single url or a comma separated list of them, RpcURLs is added after these
 	RpcURL     string `json:"rpc_url" toml:"rpc_url"`
 	PrivateKey string `json:"private_key" toml:"private_key"`
 	ChainID    int    `json:"chain_id" toml:"chain_id"`
 
+	// where the private key comes from when it is not given as hex
+	// an encrypted UTC JSON keystore, unlocked with WEB3_KEYSTORE_PASSPHRASE or a prompt
+	KeystoreFile string `json:"keystore_file" toml:"keystore_file"`
+	// a BIP-39 mnemonic and the path of the key to derive from it
+	Mnemonic       string `json:"-" toml:"-"`
+	DerivationPath string `json:"derivation_path" toml:"derivation_path"`
+
+	// what signs the transactions and api requests, key uses the private key
+	// and the others ask a service that holds the key so none is on this host
+	Signer string `json:"signer" toml:"signer"`
+	// the Web3Signer url, or another endpoint for the kms api than the default
+	SignerURL string `json:"signer_url" toml:"signer_url"`
+	// the Web3Signer public key or address, the AWS KMS key id or arn, or the
+	// GCP KMS key version name
+	SignerKeyID string `json:"signer_key_id" toml:"signer_key_id"`
+	// the AWS region of the key, AWS_REGION is used when this is empty
+	SignerRegion string `json:"signer_region" toml:"signer_region"`
+
+	// more RPC urls to fail over to, in the order they are tried
+	RpcURLs []string `json:"rpc_urls" toml:"rpc_urls"`
+	// seconds one attempt at an RPC call can take before the next url is tried
+	RpcTimeout int `json:"rpc_timeout" toml:"rpc_timeout"`
+	// seconds between checks on the failed urls so we can go back to them
+	RpcHealthCheckInterval int `json:"rpc_health_check_interval" toml:"rpc_health_check_interval"`
+	// the most blocks the event listeners read back through after a reconnect
+	// or restart, 0 reads back to the last block they saw however far that is
+	EventBackfillLimit int `json:"event_backfill_limit" toml:"event_backfill_limit"`
+	// how many blocks back the handled events are checked for having been
+	// dropped by a reorg, 0 turns the check off
+	ReorgDepth int `json:"reorg_depth" toml:"reorg_depth"`
+	// seconds between those checks
+	ReorgCheckInterval int `json:"reorg_check_interval" toml:"reorg_check_interval"`
+	// how many blocks deep an event has to be before it is acted on, the
+	// network's own setting is used when this is below 0
+	Confirmations int `json:"confirmations" toml:"confirmations"`
+
+	// the Multicall3 contract contract reads are batched through, empty makes
+	// each read its own eth_call
+	MulticallAddress string `json:"multicall_address" toml:"multicall_address"`
+	// the most reads put in one multicall
+	MulticallBatchSize int `json:"multicall_batch_size" toml:"multicall_batch_size"`
+
+	// seconds between checks of the contracts the controller links, so an
+	// upgraded contract is used without a restart, 0 turns the checks off
+	ContractRefreshInterval int `json:"contract_refresh_interval" toml:"contract_refresh_interval"`
+
+	// transaction fees, all in wei
+	// auto takes the priority fee from recent blocks, fixed always uses MaxPriorityFeePerGas
+	FeeMode string `json:"fee_mode" toml:"fee_mode"`
+	// the most a transaction pays per gas, 0 leaves it at twice the base fee plus the priority fee
+	MaxFeePerGas uint64 `json:"max_fee_per_gas" toml:"max_fee_per_gas"`
+	// the priority fee in fixed mode and the most the auto mode will pick, 0 for no limit
+	MaxPriorityFeePerGas uint64 `json:"max_priority_fee_per_gas" toml:"max_priority_fee_per_gas"`
+	// how many recent blocks the auto mode looks at
+	FeeHistoryBlocks int `json:"fee_history_blocks" toml:"fee_history_blocks"`
+	// which percentile of the priority fees paid in those blocks it takes
+	FeeHistoryPercentile int `json:"fee_history_percentile" toml:"fee_history_percentile"`
+
+	// gas oracle
+	// seconds between fee checks, 0 turns the oracle off
+	GasOracleInterval int `json:"gas_oracle_interval" toml:"gas_oracle_interval"`
+	// the most wei per gas each role pays, keyed by service e.g. solver=50000000000
+	GasCaps map[string]int64 `json:"gas_caps" toml:"gas_caps"`
+	// the base fee as a percentage of its recent median that counts as a spike, 0 to never defer
+	GasSpikeThreshold int `json:"gas_spike_threshold" toml:"gas_spike_threshold"`
+	// the most seconds a transaction that can wait is held back for
+	GasMaxDefer int `json:"gas_max_defer" toml:"gas_max_defer"`
+
+	// stuck transactions
+	// seconds a transaction can be pending before it is resent with a higher fee, 0 never resends
+	TxReplaceTimeout int `json:"tx_replace_timeout" toml:"tx_replace_timeout"`
+	// the percentage each resend raises the fees by, nodes want at least 10
+	TxFeeBump int `json:"tx_fee_bump" toml:"tx_fee_bump"`
+	// how many times a transaction is resent before it is given up on
+	TxMaxReplacements int `json:"tx_max_replacements" toml:"tx_max_replacements"`
+	// keep a journal of every transaction sent and what it cost, for lilypad transactions
+	TxJournal bool `json:"tx_journal" toml:"tx_journal"`
+	// eth_call agree, addResult and acceptResult first and do not send the
+	// ones that would revert
+	SimulateTransactions bool `json:"simulate_transactions" toml:"simulate_transactions"`
+	// the most deals agreed to in one transaction through the multicall
+	// contract, 1 sends each deal on its own
+	TxBatchSize int `json:"tx_batch_size" toml:"tx_batch_size"`
+	// sign an EIP-2612 permit and send it with the agree through the
+	// multicall contract rather than approving payment tokens on their own
+	UsePermit bool `json:"use_permit" toml:"use_permit"`
+
+	// balance alerts
+	// seconds between checks of our balances, 0 turns the checks off
+	BalanceCheckInterval int `json:"balance_check_interval" toml:"balance_check_interval"`
+	// the balances to warn under, in whole coins for gas and whole tokens
+	// for collateral, 0 never warns
+	MinNativeBalance float64 `json:"min_native_balance" toml:"min_native_balance"`
+	MinTokenBalance  float64 `json:"min_token_balance" toml:"min_token_balance"`
+	// posted a BalanceAlert when a balance goes under or back over its threshold
+	BalanceAlertWebhook string `json:"balance_alert_webhook" toml:"balance_alert_webhook"`
+
 	// contract addresses
 	ControllerAddress string `json:"controller_address" toml:"controller_address"`
 	PaymentsAddress   string `json:"payments_address" toml:"payments_address"`
@@ -27,6 +126,30 @@ type Web3Options struct {
 	Service system.Service `json:"-" toml:"-"`
 }
 
+// every configured RPC url in the order they are tried, without repeats
+func (options Web3Options) RpcURLList() []string {
+	urls := []string{}
+	seen := map[string]bool{}
+	for _, u := range append(strings.Split(options.RpcURL, ","), options.RpcURLs...) {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// the most this role pays per gas, the lower of the max fee and its gas cap
+func (options Web3Options) feeCap() uint64 {
+	feeCap := options.MaxFeePerGas
+	if roleCap := options.GasCaps[string(options.Service)]; roleCap > 0 && (feeCap == 0 || uint64(roleCap) < feeCap) {
+		feeCap = uint64(roleCap)
+	}
+	return feeCap
+}
+
 type EventChannelCollection interface {
 	Start(
 		ctx context.Context,
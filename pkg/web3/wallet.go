@@ -0,0 +1,94 @@
+package web3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// whatever holds the sdk's key, a keystore, a hardware wallet, a remote
+// service or a test wallet only has to implement this
+//
+// Sign is kept for the request headers the solver checks, a wallet that
+// can only sign transactions and typed data can return an error from it
+// and still send transactions
+type Wallet interface {
+	Signer
+	// the transaction signed for the chain
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// the same signature eth_signTypedData_v4 would give
+	SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error)
+}
+
+// a wallet for a Signer, the transaction and the typed data are handed
+// over as the payload their hash is of
+type signerWallet struct {
+	Signer
+}
+
+func NewSignerWallet(signer Signer) Wallet {
+	if wallet, ok := signer.(Wallet); ok {
+		return wallet
+	}
+	return &signerWallet{Signer: signer}
+}
+
+// the wallet WEB3_SIGNER picks
+func NewWallet(ctx context.Context, options Web3Options) (Wallet, error) {
+	signer, err := NewSigner(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerWallet(signer), nil
+}
+
+func (wallet *signerWallet) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	txSigner := types.LatestSignerForChainID(chainID)
+	payload, err := signingPayload(txSigner, tx)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.Sign(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(txSigner, signature)
+}
+
+func (wallet *signerWallet) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	return signTypedDataPayload(ctx, wallet.Signer, typedData)
+}
+
+// transact opts that sign with the wallet
+func NewWalletTransactor(wallet Wallet, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: wallet.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != wallet.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			signed, err := wallet.SignTx(context.Background(), tx, chainID)
+			if err != nil {
+				return nil, fmt.Errorf("error signing transaction: %w", err)
+			}
+			return signed, nil
+		},
+		Context: context.Background(),
+	}
+}
+
+// the private key when the wallet is one held in memory here
+func walletPrivateKey(wallet Wallet) *ecdsa.PrivateKey {
+	if signerWallet, ok := wallet.(*signerWallet); ok {
+		if keySigner, ok := signerWallet.Signer.(*keySigner); ok {
+			return keySigner.privateKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build unit
+
+package web3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/lilypad-tech/lilypad/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signs transactions and typed data the way a hardware wallet would,
+// without ever signing a raw message
+type testWallet struct {
+	privateKey *ecdsa.PrivateKey
+	typedData  int
+}
+
+func (wallet *testWallet) Address() common.Address {
+	return crypto.PubkeyToAddress(wallet.privateKey.PublicKey)
+}
+
+func (wallet *testWallet) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	return nil, errors.New("only transactions and typed data are signed")
+}
+
+func (wallet *testWallet) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), wallet.privateKey)
+}
+
+func (wallet *testWallet) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	wallet.typedData++
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash, wallet.privateKey)
+}
+
+func TestWallet(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := big.NewInt(1337)
+	wallet := &testWallet{privateKey: privateKey}
+	assert.Same(t, wallet, NewSignerWallet(wallet))
+
+	opts := NewWalletTransactor(wallet, chainID)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(20), Gas: 50000, To: &to})
+	signed, err := opts.Signer(opts.From, tx)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.Address(), sender)
+
+	// a key signer made into a wallet gives the same transaction
+	fromSigner, err := NewSignerWallet(NewKeySigner(privateKey)).SignTx(context.Background(), tx, chainID)
+	require.NoError(t, err)
+	assert.Equal(t, signed.Hash(), fromSigner.Hash())
+
+	// typed data goes to the wallet rather than through Sign
+	typedData := ResultTypedData(NewTypedDataDomain(1337, testController), data.Result{DealID: "deal"})
+	signature, err := SignTypedData(context.Background(), wallet, typedData)
+	require.NoError(t, err)
+	assert.Equal(t, 1, wallet.typedData)
+	address, err := RecoverTypedData(typedData, signature)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.Address(), address)
+}
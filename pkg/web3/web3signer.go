@@ -0,0 +1,105 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signs with a key loaded into a Web3Signer server using its eth1 api,
+// Web3Signer hashes the data itself before signing it
+type web3Signer struct {
+	url string
+	// the public key as Web3Signer lists it, which is how the api names it
+	identifier string
+	address    common.Address
+}
+
+// finds the key to use out of the ones the server has, WEB3_SIGNER_KEY_ID
+// can be its public key or its address and can be left out when there is
+// only one key loaded
+func newWeb3Signer(ctx context.Context, options Web3Options) (*web3Signer, error) {
+	url := strings.TrimSuffix(options.SignerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/v1/eth1/publicKeys", nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := doSignerRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error listing Web3Signer keys: %w", err)
+	}
+	var publicKeys []string
+	if err := json.Unmarshal(body, &publicKeys); err != nil {
+		return nil, fmt.Errorf("error reading Web3Signer keys: %w", err)
+	}
+
+	keyID := strings.ToLower(options.SignerKeyID)
+	if keyID == "" && len(publicKeys) != 1 {
+		return nil, fmt.Errorf("Web3Signer has %d keys, WEB3_SIGNER_KEY_ID has to say which one to use", len(publicKeys))
+	}
+	for _, publicKey := range publicKeys {
+		address, err := web3SignerAddress(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading Web3Signer key %s: %w", publicKey, err)
+		}
+		if keyID == "" || keyID == strings.ToLower(publicKey) || keyID == strings.ToLower(address.Hex()) {
+			return &web3Signer{url: url, identifier: publicKey, address: address}, nil
+		}
+	}
+	return nil, fmt.Errorf("Web3Signer has no key %s", options.SignerKeyID)
+}
+
+// the public keys are listed as hex with or without the 0x04 prefix
+func web3SignerAddress(publicKey string) (common.Address, error) {
+	raw, err := hexutil.Decode(publicKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(raw) == 64 {
+		raw = append([]byte{4}, raw...)
+	}
+	key, err := crypto.UnmarshalPubkey(raw)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
+
+func (signer *web3Signer) Address() common.Address {
+	return signer.address
+}
+
+func (signer *web3Signer) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	payload, err := json.Marshal(map[string]string{"data": hexutil.Encode(data)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, signer.url+"/api/v1/eth1/sign/"+signer.identifier, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	body, err := doSignerRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := hexutil.Decode(strings.Trim(string(bytes.TrimSpace(body)), `"`))
+	if err != nil {
+		return nil, fmt.Errorf("error reading Web3Signer signature: %w", err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("Web3Signer gave a %d byte signature", len(signature))
+	}
+	// it gives V as 27 or 28
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	return signature, nil
+}